@@ -0,0 +1,75 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/security"
+)
+
+const catUsage = "usage: stui [flags] cat [-range SPEC] s3://bucket/key"
+
+// rangeFlagUsage documents the -range flag of cat and cp
+const rangeFlagUsage = "Only fetch part of the object: first:SIZE, last:SIZE or START-END in bytes"
+
+// runCat writes an object, or part of it, to stdout without starting the
+// TUI, e.g. `stui cat -range last:1MB s3://logs/huge.csv | tail`
+func runCat(args []string, profile, region string, settings *config.Config) error {
+	fs := flag.NewFlagSet("cat", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	spec := fs.String("range", "", rangeFlagUsage)
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%v; %s", err, catUsage)
+	}
+	if fs.NArg() != 1 {
+		return errors.New(catUsage)
+	}
+
+	bucket, key, r, err := parseRangeSource(fs.Arg(0), *spec)
+	if err != nil {
+		return err
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := newBucketClient(ctx, profile, region, bucket, settings)
+	if err != nil {
+		return err
+	}
+	body, _, err := client.GetObjectRange(ctx, bucket, key, r)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	_, err = io.Copy(os.Stdout, body)
+	return err
+}
+
+// parseRangeSource validates an s3:// object URI and an optional -range spec
+func parseRangeSource(uri, spec string) (bucket, key string, r aws.ByteRange, err error) {
+	bucket, key, err = aws.ParseS3URI(uri)
+	if err != nil {
+		return "", "", r, err
+	}
+	if err := security.ValidBucketName(bucket); err != nil {
+		return "", "", r, err
+	}
+	if err := security.ValidObjectKey(key); err != nil {
+		return "", "", r, err
+	}
+	if spec != "" {
+		if r, err = aws.ParseByteRange(spec); err != nil {
+			return "", "", r, err
+		}
+	}
+	return bucket, key, r, nil
+}
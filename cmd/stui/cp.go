@@ -0,0 +1,154 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"path"
+	"path/filepath"
+
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/security"
+)
+
+const cpUsage = "usage: stui [flags] cp [-range SPEC] s3://bucket/key <local-path>, or cp --from-manifest FILE [-bucket NAME] <local-dir>"
+
+// runCp downloads an object, or part of it, to a local file without
+// starting the TUI, e.g. `stui cp -range first:10MB s3://data/huge.csv .`
+func runCp(args []string, profile, region string, settings *config.Config) error {
+	fs := flag.NewFlagSet("cp", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	spec := fs.String("range", "", rangeFlagUsage)
+	manifest := fs.String("from-manifest", "", "file listing the keys or s3:// URIs to download, one per line")
+	manifestBucket := fs.String("bucket", "", "bucket of the manifest's plain keys")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%v; %s", err, cpUsage)
+	}
+	if *manifest != "" {
+		if *spec != "" || fs.NArg() != 1 {
+			return errors.New(cpUsage)
+		}
+		return runManifestCp(*manifest, *manifestBucket, fs.Arg(0), profile, region, settings)
+	}
+	if fs.NArg() != 2 {
+		return errors.New(cpUsage)
+	}
+
+	bucket, key, r, err := parseRangeSource(fs.Arg(0), *spec)
+	if err != nil {
+		return err
+	}
+	localPath := fs.Arg(1)
+	if info, err := os.Stat(localPath); err == nil && info.IsDir() {
+		localPath = filepath.Join(localPath, path.Base(key))
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := newBucketClient(ctx, profile, region, bucket, settings)
+	if err != nil {
+		return err
+	}
+	if r.IsZero() {
+		err = client.DownloadFile(ctx, bucket, key, localPath, aws.DownloadOptions{}, nil)
+	} else {
+		err = client.DownloadFileRange(ctx, bucket, key, localPath, r, nil)
+	}
+	if err != nil {
+		return err
+	}
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "Downloaded %s (%s) of s3://%s/%s to %s\n",
+		humanize.Bytes(uint64(info.Size())), r, bucket, key, localPath)
+	return nil
+}
+
+// runManifestCp downloads the keys a manifest file lists to their key
+// paths under localDir, reporting how each one went, e.g.
+// `stui cp --from-manifest keys.txt -bucket data ./fetched`
+func runManifestCp(manifestPath, bucket, localDir, profile, region string, settings *config.Config) error {
+	if err := security.ValidBucketName(bucket); err != nil {
+		return err
+	}
+	f, err := os.Open(manifestPath)
+	if err != nil {
+		return err
+	}
+	entries, err := download.ParseManifest(f, bucket)
+	f.Close()
+	if err != nil {
+		return fmt.Errorf("%s: %w", manifestPath, err)
+	}
+
+	// One transfer per bucket, in the order buckets first appear
+	var buckets []string
+	keys := make(map[string][]string)
+	for _, e := range entries {
+		if _, ok := keys[e.Bucket]; !ok {
+			buckets = append(buckets, e.Bucket)
+		}
+		keys[e.Bucket] = append(keys[e.Bucket], e.Key)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	var done, failed int
+	var total int64
+	for _, b := range buckets {
+		fmt.Fprintf(os.Stderr, "Downloading %d keys from s3://%s\n", len(keys[b]), b)
+		client, err := newBucketClient(ctx, profile, region, b, settings)
+		if err != nil {
+			return err
+		}
+		mgr, _, err := newTransferManagers(client, settings, 5)
+		if err != nil {
+			return err
+		}
+		if err := setMirrors(ctx, mgr, settings, profile, region, b); err != nil {
+			return err
+		}
+		err = mgr.DownloadKeys(ctx, b, keys[b], localDir)
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err != nil && mgr.GetProgress().TotalFiles == 0 {
+			return err
+		}
+
+		progress := mgr.GetProgress()
+		for _, key := range keys[b] {
+			fp, ok := progress.Files[key]
+			switch {
+			case !ok:
+				failed++
+				fmt.Printf("failed  s3://%s/%s: not downloaded\n", b, key)
+			case fp.Status == download.StatusCompleted:
+				done++
+				total += fp.Size
+				fmt.Printf("ok      s3://%s/%s -> %s (%s)\n", b, key, fp.LocalPath, humanize.Bytes(uint64(fp.Size)))
+			default:
+				failed++
+				fmt.Printf("failed  s3://%s/%s: %s\n", b, key, security.SanitizeError(fp.Error))
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d of %d keys failed", failed, done+failed)
+	}
+	fmt.Fprintf(os.Stderr, "Downloaded %d keys (%s) to %s\n", done, humanize.Bytes(uint64(total)), localDir)
+	return nil
+}
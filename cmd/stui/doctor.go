@@ -0,0 +1,59 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/doctor"
+	"github.com/natevick/stui/internal/views/preview"
+)
+
+const doctorUsage = "usage: stui [flags] doctor [-offline]"
+
+// errChecksFailed is returned when the report shows a failed check, so the
+// command exits non-zero
+var errChecksFailed = errors.New("some checks failed")
+
+// runDoctor prints what a bug report needs: versions, config files,
+// profiles, the terminal, and whether the profile can reach S3. A settings
+// file that doesn't load is reported rather than stopping it.
+func runDoctor(args []string, profile, region string) error {
+	fs := flag.NewFlagSet("doctor", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	offline := fs.Bool("offline", false, "Skip the checks that talk to AWS")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%v; %s", err, doctorUsage)
+	}
+	if fs.NArg() != 0 {
+		return errors.New(doctorUsage)
+	}
+
+	settings, err := config.Load()
+	if err != nil {
+		settings = config.Default()
+	}
+	sections := doctor.Local(doctor.Options{
+		Version:  version,
+		Profile:  profile,
+		Graphics: preview.DetectGraphics(settings.ImageGraphics).String(),
+	})
+	if !*offline {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+		sections = append(sections, doctor.Connect(ctx, profile, region, settings))
+	}
+
+	if err := doctor.Write(os.Stdout, sections); err != nil {
+		return err
+	}
+	if doctor.Failed(sections) {
+		return errChecksFailed
+	}
+	return nil
+}
@@ -4,8 +4,12 @@ import (
 	"flag"
 	"fmt"
 	"os"
+	"strings"
 
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/doctor"
 	"github.com/natevick/stui/internal/security"
 	"github.com/natevick/stui/internal/tui"
 )
@@ -20,11 +24,14 @@ func main() {
 	region := flag.String("region", os.Getenv("AWS_REGION"), "AWS region (can also use AWS_REGION env var)")
 	bucket := flag.String("bucket", "", "Start directly in this S3 bucket")
 	demo := flag.Bool("demo", false, "Run with mock data (no AWS credentials needed)")
+	partSize := flag.Int("part-size", 0, "Multipart upload part size in MB (overrides config, min 5)")
+	uploadConcurrency := flag.Int("upload-concurrency", 0, "Parts uploaded in parallel per file (overrides config)")
+	fips := flag.Bool("fips", false, "Use FIPS endpoints for S3 and STS where the region has them (overrides config)")
 	showVersion := flag.Bool("version", false, "Show version and exit")
 	flag.Parse()
 
 	if *showVersion {
-		fmt.Printf("stui version %s\n", version)
+		fmt.Println(doctor.Version(version))
 		os.Exit(0)
 	}
 
@@ -37,6 +44,72 @@ func main() {
 		fmt.Fprintf(os.Stderr, "Invalid bucket: %v\n", err)
 		os.Exit(1)
 	}
+	if *region != "" && !aws.KnownRegion(*region) {
+		// New regions work before stui knows them, so this only warns
+		p := aws.PartitionForRegion(*region)
+		fmt.Fprintf(os.Stderr, "Warning: unknown region %q; %s regions are %s\n", *region, p.Name, strings.Join(p.Regions, ", "))
+	}
+
+	// doctor reports a broken config file instead of stopping at it
+	if flag.Arg(0) == "doctor" {
+		if err := runDoctor(flag.Args()[1:], *profile, *region); err != nil {
+			fmt.Fprintf(os.Stderr, "stui doctor: %s\n", security.SanitizeError(err))
+			os.Exit(1)
+		}
+		return
+	}
+
+	// Load user settings
+	settings, err := config.Load()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid config: %v\n", err)
+		os.Exit(1)
+	}
+	if *partSize != 0 {
+		settings.Transfers.UploadPartSizeMB = *partSize
+	}
+	if *uploadConcurrency != 0 {
+		settings.Transfers.UploadConcurrency = *uploadConcurrency
+	}
+	if *fips {
+		settings.FIPS = true
+	}
+	if err := settings.Transfers.Validate(); err != nil {
+		fmt.Fprintf(os.Stderr, "Invalid upload settings: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Headless subcommands
+	switch flag.Arg(0) {
+	case "":
+	case "put":
+		if err := runPut(flag.Args()[1:], *profile, *region, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "stui put: %s\n", security.SanitizeError(err))
+			os.Exit(1)
+		}
+		return
+	case "cat":
+		if err := runCat(flag.Args()[1:], *profile, *region, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "stui cat: %s\n", security.SanitizeError(err))
+			os.Exit(1)
+		}
+		return
+	case "cp":
+		if err := runCp(flag.Args()[1:], *profile, *region, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "stui cp: %s\n", security.SanitizeError(err))
+			os.Exit(1)
+		}
+		return
+	case "run-template":
+		if err := runTemplate(flag.Args()[1:], *profile, *region, settings); err != nil {
+			fmt.Fprintf(os.Stderr, "stui run-template: %s\n", security.SanitizeError(err))
+			os.Exit(1)
+		}
+		return
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n", flag.Arg(0))
+		os.Exit(2)
+	}
 
 	// Create TUI model
 	cfg := tui.Config{
@@ -44,6 +117,8 @@ func main() {
 		Region:   *region,
 		Bucket:   *bucket,
 		DemoMode: *demo,
+		Settings: settings,
+		Version:  version,
 	}
 
 	model := tui.New(cfg)
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+
+	"github.com/charmbracelet/x/term"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/security"
+)
+
+const putUsage = "usage: stui [flags] put s3://bucket/key -"
+
+// runPut streams stdin to an S3 object without starting the TUI, e.g.
+// `pg_dump mydb | stui put s3://backups/mydb.sql -`
+func runPut(args []string, profile, region string, settings *config.Config) error {
+	if len(args) != 2 || args[1] != "-" {
+		return errors.New(putUsage)
+	}
+
+	bucket, key, err := aws.ParseS3URI(args[0])
+	if err != nil {
+		return err
+	}
+	if err := security.ValidBucketName(bucket); err != nil {
+		return err
+	}
+	if err := security.ValidObjectKey(key); err != nil {
+		return err
+	}
+	var violation *config.PolicyViolation
+	if err := settings.CheckKey(bucket, key); errors.As(err, &violation) {
+		if violation.Blocking() {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Warning: %v\n", err)
+	}
+
+	if term.IsTerminal(os.Stdin.Fd()) {
+		return errors.New("stdin is a terminal; pipe the data to upload into stui put")
+	}
+
+	enc := settings.ForProfile(profile).DefaultEncryption()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := newBucketClient(ctx, profile, region, bucket, settings)
+	if err != nil {
+		return err
+	}
+
+	opts := aws.UploadOptions{
+		PartSize:    settings.Transfers.UploadPartSize(),
+		Concurrency: settings.Transfers.UploadConcurrency,
+	}
+	n, err := client.UploadStream(ctx, bucket, key, os.Stdin, opts, aws.ObjectAttributes{Encryption: enc})
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "Uploaded %s to s3://%s/%s\n", humanize.Bytes(uint64(n)), bucket, key)
+	return nil
+}
+
+// newBucketClient connects to S3 in bucket's own region unless region was
+// given explicitly; multipart uploads and ranged reads must go there
+func newBucketClient(ctx context.Context, profile, region, bucket string, settings *config.Config) (*aws.Client, error) {
+	client, err := aws.NewClient(ctx, profile, region, aws.EndpointOptions{FIPS: settings.UseFIPS(profile)})
+	if err != nil {
+		return nil, err
+	}
+	if region == "" {
+		if bucketRegion, err := client.GetBucketRegion(ctx, bucket); err == nil && bucketRegion != client.Region {
+			return client.WithRegion(ctx, bucketRegion)
+		}
+	}
+	return client, nil
+}
@@ -0,0 +1,194 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/daemon"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/jobs"
+)
+
+const runTemplateUsage = "usage: stui [flags] run-template [-detach] <name>"
+
+// runTemplate runs a job saved from the TUI without starting it, e.g.
+// `stui run-template nightly-models` from cron. With -detach it runs in a
+// background process that stui shows in its Transfers view.
+func runTemplate(args []string, profile, region string, settings *config.Config) error {
+	fs := flag.NewFlagSet("run-template", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+	detach := fs.Bool("detach", false, "Run in the background, carrying on after the terminal closes")
+	detachedID := fs.String(daemon.IDFlag, "", "Record progress as this background job (set by -detach)")
+	if err := fs.Parse(args); err != nil {
+		return fmt.Errorf("%v; %s", err, runTemplateUsage)
+	}
+	if fs.NArg() != 1 {
+		return errors.New(runTemplateUsage)
+	}
+
+	store, err := jobs.NewStore()
+	if err != nil {
+		return err
+	}
+	job, ok := store.Find(fs.Arg(0))
+	if !ok {
+		return fmt.Errorf("no saved job named %q", fs.Arg(0))
+	}
+	if err := job.Validate(); err != nil {
+		return err
+	}
+
+	if *detach {
+		background, err := daemon.NewStore()
+		if err != nil {
+			return err
+		}
+		// The same global flags, e.g. -profile, as this run
+		state, err := background.Start(job, os.Args[1:len(os.Args)-len(flag.Args())])
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(os.Stderr, "Running %s in the background (pid %d); stui shows its progress in Transfers\nLog: %s\n",
+			job.Name, state.PID, state.Log)
+		return nil
+	}
+	if *detachedID != "" {
+		return runDetached(*detachedID, job, profile, region, settings)
+	}
+
+	if job.Profile != "" {
+		profile = job.Profile
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	client, err := newBucketClient(ctx, profile, region, job.Bucket, settings)
+	if err != nil {
+		return err
+	}
+	mgr, syncMgr, err := newTransferManagers(client, settings, job.Workers())
+	if err != nil {
+		return err
+	}
+	if err := setMirrors(ctx, mgr, settings, profile, region, job.Bucket); err != nil {
+		return err
+	}
+	enc := settings.ForProfile(profile).DefaultEncryption()
+
+	fmt.Fprintf(os.Stderr, "Running %s: %s\n", job.Name, job.Describe())
+	err = job.Run(ctx, syncMgr, mgr, aws.ObjectAttributes{Encryption: enc}, settings)
+	if errors.Is(err, jobs.ErrNothingToDo) {
+		fmt.Fprintln(os.Stderr, "Already up to date")
+		return nil
+	}
+	progress := mgr.GetProgress()
+	if err != nil {
+		return err
+	}
+	if progress.FailedFiles > 0 {
+		return fmt.Errorf("%d of %d files failed", progress.FailedFiles, progress.TotalFiles)
+	}
+	fmt.Fprintf(os.Stderr, "Transferred %d files (%s)\n",
+		progress.CompletedFiles, humanize.Bytes(uint64(progress.DownloadedBytes)))
+	return nil
+}
+
+// runDetached runs a saved job in a background process started by
+// -detach, recording its progress for stui to show. It ignores the
+// terminal closing and stops on SIGTERM.
+func runDetached(id string, job jobs.Job, profile, region string, settings *config.Config) error {
+	signal.Ignore(syscall.SIGHUP)
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	background, err := daemon.NewStore()
+	if err != nil {
+		return err
+	}
+	state, err := background.Begin(id, job)
+	if err != nil {
+		return err
+	}
+	if job.Profile != "" {
+		profile = job.Profile
+	}
+
+	run := func() (*download.Manager, error) {
+		client, err := newBucketClient(ctx, profile, region, job.Bucket, settings)
+		if err != nil {
+			return nil, err
+		}
+		mgr, syncMgr, err := newTransferManagers(client, settings, job.Workers())
+		if err != nil {
+			return nil, err
+		}
+		if err := setMirrors(ctx, mgr, settings, profile, region, job.Bucket); err != nil {
+			return mgr, err
+		}
+		enc := settings.ForProfile(profile).DefaultEncryption()
+		mgr.SetProgressCallback(background.Reporter(state))
+		fmt.Fprintf(os.Stderr, "%s Running %s: %s\n", time.Now().Format(time.DateTime), job.Name, job.Describe())
+		return mgr, job.Run(ctx, syncMgr, mgr, aws.ObjectAttributes{Encryption: enc}, settings)
+	}
+	mgr, err := run()
+	if errors.Is(err, jobs.ErrNothingToDo) {
+		err = nil
+	}
+
+	var progress download.Progress
+	if mgr != nil {
+		progress = mgr.GetProgress()
+	}
+	if finishErr := background.Finish(state, progress, err); finishErr != nil && err == nil {
+		err = finishErr
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintf(os.Stderr, "%s Transferred %d of %d files (%s)\n", time.Now().Format(time.DateTime),
+		progress.CompletedFiles, progress.TotalFiles, humanize.Bytes(uint64(progress.DownloadedBytes)))
+	return nil
+}
+
+// setMirrors stripes mgr's downloads from bucket across the bucket's
+// configured mirrors
+func setMirrors(ctx context.Context, mgr *download.Manager, settings *config.Config, profile, region, bucket string) error {
+	mirrors, err := settings.ConnectMirrors(ctx, profile, region, bucket)
+	if err != nil {
+		return err
+	}
+	mgr.SetMirrors(mirrors)
+	return nil
+}
+
+// newTransferManagers creates the transfer and sync managers with the
+// transfer settings the TUI's queue uses, transferring workers files at once
+func newTransferManagers(client *aws.Client, settings *config.Config, workers int) (*download.Manager, *download.SyncManager, error) {
+	transfers := settings.Transfers
+	mgr := download.NewManager(client, workers)
+	mgr.SetStallPolicy(transfers.StallTimeout(), transfers.RetryStalled)
+	mgr.SetUploadOptions(aws.UploadOptions{
+		PartSize:    transfers.UploadPartSize(),
+		Concurrency: transfers.UploadConcurrency,
+	})
+	mgr.SetDownloadOptions(transfers.DownloadOptions())
+	mgr.SetScheduleOrder(transfers.Order())
+	mgr.SetCollisionPolicy(transfers.Collisions())
+	mgr.SetPathPolicy(transfers.LocalPaths())
+
+	syncMgr := download.NewSyncManager(client)
+	syncMgr.SetNormalization(transfers.NameNormalization())
+	syncMgr.SetSymlinkPolicy(transfers.Symlinks())
+	return mgr, syncMgr, nil
+}
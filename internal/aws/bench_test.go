@@ -0,0 +1,63 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// listingServer serves ListObjectsV2 pages of 1000 keys, pages deep
+func listingServer(b *testing.B, pages int) *Client {
+	body := make([]string, pages)
+	for p := range pages {
+		var sb strings.Builder
+		sb.WriteString(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>bucket</Name><Prefix>data/</Prefix><KeyCount>1000</KeyCount><MaxKeys>1000</MaxKeys>`)
+		if p < pages-1 {
+			fmt.Fprintf(&sb, `<IsTruncated>true</IsTruncated><NextContinuationToken>%d</NextContinuationToken>`, p+1)
+		} else {
+			sb.WriteString(`<IsTruncated>false</IsTruncated>`)
+		}
+		for i := range 1000 {
+			fmt.Fprintf(&sb, `<Contents><Key>data/2024/05/%03d/part-%06d.parquet</Key><LastModified>2024-05-01T12:00:00.000Z</LastModified><ETag>&quot;5d41402abc4b2a76b9719d911017c592&quot;</ETag><Size>%d</Size><StorageClass>STANDARD</StorageClass></Contents>`, p, p*1000+i, 1000+i)
+		}
+		sb.WriteString(`</ListBucketResult>`)
+		body[p] = sb.String()
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		page, _ := strconv.Atoi(r.URL.Query().Get("continuation-token"))
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(body[page]))
+	}))
+	b.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	return &Client{S3: client, Region: "us-east-1"}
+}
+
+// BenchmarkListAllObjects lists 100k objects in 100 pages
+func BenchmarkListAllObjects(b *testing.B) {
+	client := listingServer(b, 100)
+	b.ReportAllocs()
+	for b.Loop() {
+		objects, err := client.ListAllObjects(context.Background(), "bucket", "data/")
+		if err != nil {
+			b.Fatal(err)
+		}
+		if len(objects) != 100_000 {
+			b.Fatalf("listed %d objects, want 100000", len(objects))
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// Checksum is one of the additional checksums an object can be uploaded
+// with, which unlike a multipart or KMS ETag can always be recomputed
+type Checksum struct {
+	Algorithm string // "SHA-256", "SHA-1", "CRC64NVME", "CRC32C" or "CRC32"
+	Value     string // base64; a composite multipart checksum ends in "-<parts>"
+}
+
+// checksumStrength is every Checksum algorithm, strongest first
+var checksumStrength = []string{"SHA-256", "SHA-1", "CRC64NVME", "CRC32C", "CRC32"}
+
+// ObjectChecksums are the integrity values S3 holds for an object, for
+// checking a local copy against it
+type ObjectChecksums struct {
+	Size      int64
+	ETag      string
+	Checksums []Checksum // strongest first; none unless uploaded with one
+	Parts     int        // parts of a multipart object, 0 otherwise
+	PartSize  int64      // size of every part but the last
+	MD5ETag   bool       // the ETag is an MD5 of the content, or of its parts
+}
+
+// GetObjectChecksums fetches the size, ETag and any additional checksums of
+// an object, and for multipart objects the part size needed to recompute
+// them
+func (c *Client) GetObjectChecksums(ctx context.Context, bucket, key string) (*ObjectChecksums, error) {
+	output, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+
+	sums := &ObjectChecksums{
+		Size: aws.ToInt64(output.ContentLength),
+		ETag: strings.Trim(aws.ToString(output.ETag), "\""),
+		// KMS and customer-key ETags are opaque
+		MD5ETag: output.ServerSideEncryption != types.ServerSideEncryptionAwsKms &&
+			output.ServerSideEncryption != types.ServerSideEncryptionAwsKmsDsse &&
+			output.SSECustomerAlgorithm == nil,
+	}
+	if _, count, ok := strings.Cut(sums.ETag, "-"); ok {
+		if sums.Parts, err = strconv.Atoi(count); err != nil {
+			return nil, fmt.Errorf("unexpected ETag %q", sums.ETag)
+		}
+		// The first part is as big as every part but the last
+		part, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+			Bucket:     aws.String(bucket),
+			Key:        aws.String(key),
+			PartNumber: aws.Int32(1),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get part size: %w", err)
+		}
+		sums.PartSize = aws.ToInt64(part.ContentLength)
+	}
+	sums.Checksums = c.additionalChecksums(ctx, bucket, key, headChecksum(output), sums.Parts)
+	return sums, nil
+}
+
+// additionalChecksums returns the checksums GetObjectAttributes reports for
+// an object, strongest first. Where it isn't allowed or, on some
+// S3-compatible stores, supported, it falls back to the ones a HeadObject
+// with checksum mode on reported.
+func (c *Client) additionalChecksums(ctx context.Context, bucket, key string, head types.Checksum, parts int) []Checksum {
+	checksum := head
+	attrs, err := c.S3.GetObjectAttributes(ctx, &s3.GetObjectAttributesInput{
+		Bucket:           aws.String(bucket),
+		Key:              aws.String(key),
+		ObjectAttributes: []types.ObjectAttributes{types.ObjectAttributesChecksum, types.ObjectAttributesObjectParts},
+	})
+	if err == nil && attrs.Checksum != nil {
+		checksum = *attrs.Checksum
+		if attrs.ObjectParts != nil && aws.ToInt32(attrs.ObjectParts.TotalPartsCount) > 0 {
+			parts = int(aws.ToInt32(attrs.ObjectParts.TotalPartsCount))
+		}
+	}
+
+	var checksums []Checksum
+	for _, sum := range []Checksum{
+		{"SHA-256", aws.ToString(checksum.ChecksumSHA256)},
+		{"SHA-1", aws.ToString(checksum.ChecksumSHA1)},
+		{"CRC64NVME", aws.ToString(checksum.ChecksumCRC64NVME)},
+		{"CRC32C", aws.ToString(checksum.ChecksumCRC32C)},
+		{"CRC32", aws.ToString(checksum.ChecksumCRC32)},
+	} {
+		if sum.Value == "" {
+			continue
+		}
+		// GetObjectAttributes leaves the part count off a composite
+		// checksum that HeadObject adds
+		if checksum.ChecksumType == types.ChecksumTypeComposite && parts > 0 && !strings.Contains(sum.Value, "-") {
+			sum.Value += "-" + strconv.Itoa(parts)
+		}
+		checksums = append(checksums, sum)
+	}
+	return checksums
+}
+
+// headChecksum collects the checksums a HeadObject reported
+func headChecksum(head *s3.HeadObjectOutput) types.Checksum {
+	return types.Checksum{
+		ChecksumCRC32:     head.ChecksumCRC32,
+		ChecksumCRC32C:    head.ChecksumCRC32C,
+		ChecksumCRC64NVME: head.ChecksumCRC64NVME,
+		ChecksumSHA1:      head.ChecksumSHA1,
+		ChecksumSHA256:    head.ChecksumSHA256,
+		ChecksumType:      head.ChecksumType,
+	}
+}
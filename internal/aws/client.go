@@ -1,12 +1,9 @@
 package aws
 
 import (
-	"bufio"
 	"context"
 	"fmt"
-	"os"
-	"path/filepath"
-	"strings"
+	"sync"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/config"
@@ -15,15 +12,66 @@ import (
 
 // Client wraps the AWS S3 client with configuration
 type Client struct {
-	S3      *s3.Client
-	Config  aws.Config
-	Profile string
-	Region  string
+	S3        *s3.Client
+	Config    aws.Config
+	Profile   string
+	Region    string
+	Endpoints EndpointOptions // as asked for; see UsesFIPS for what's in effect
+
+	bucketMu sync.Mutex
+	buckets  map[string]*Client // ForBucket's client for each bucket
+}
+
+// EndpointOptions selects which AWS endpoints a client talks to
+type EndpointOptions struct {
+	// FIPS uses FIPS 140 validated endpoints for S3 and STS in the regions
+	// that have them, and the standard ones elsewhere
+	FIPS bool
+
+	// URL sends S3 requests to this endpoint instead of AWS's, e.g. a
+	// replica gateway or an S3-compatible store
+	URL string
+
+	// PathStyle puts the bucket in the path rather than the host name, as
+	// most S3-compatible stores need
+	PathStyle bool
 }
 
 // NewClient creates a new AWS client with the specified profile
 // Supports SSO profiles - user must run `aws sso login --profile <profile>` first
-func NewClient(ctx context.Context, profile, region string) (*Client, error) {
+func NewClient(ctx context.Context, profile, region string, endpoints EndpointOptions) (*Client, error) {
+	cfg, err := loadConfig(ctx, profile, region, false)
+	if err != nil {
+		return nil, err
+	}
+	// The region is only known once the profile is loaded. Credentials are
+	// fetched through STS with the same setting, so it's loaded again.
+	if endpoints.FIPS && FIPSAvailable(cfg.Region) {
+		if cfg, err = loadConfig(ctx, profile, region, true); err != nil {
+			return nil, err
+		}
+	}
+
+	s3Client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		if endpoints.URL != "" {
+			o.BaseEndpoint = aws.String(endpoints.URL)
+		}
+		if endpoints.PathStyle {
+			o.UsePathStyle = true
+		}
+	})
+
+	return &Client{
+		S3:        s3Client,
+		Config:    cfg,
+		Profile:   profile,
+		Region:    cfg.Region,
+		Endpoints: endpoints,
+	}, nil
+}
+
+// loadConfig loads the shared configuration of a profile
+func loadConfig(ctx context.Context, profile, region string, fips bool) (aws.Config, error) {
 	var opts []func(*config.LoadOptions) error
 
 	if profile != "" {
@@ -34,108 +82,81 @@ func NewClient(ctx context.Context, profile, region string) (*Client, error) {
 		opts = append(opts, config.WithRegion(region))
 	}
 
-	cfg, err := config.LoadDefaultConfig(ctx, opts...)
-	if err != nil {
-		return nil, fmt.Errorf("failed to load AWS config: %w", err)
+	// The SDK doesn't follow includes, so it's given the included files too
+	if files, _, err := loadSharedConfig(); err == nil && len(files) > 1 {
+		opts = append(opts, config.WithSharedConfigFiles(files))
 	}
 
-	s3Client := s3.NewFromConfig(cfg)
+	if fips {
+		opts = append(opts, config.WithUseFIPSEndpoint(aws.FIPSEndpointStateEnabled))
+	}
 
-	return &Client{
-		S3:      s3Client,
-		Config:  cfg,
-		Profile: profile,
-		Region:  cfg.Region,
-	}, nil
+	cfg, err := config.LoadDefaultConfig(ctx, opts...)
+	if err != nil {
+		return cfg, fmt.Errorf("failed to load AWS config: %w", err)
+	}
+	return cfg, nil
 }
 
-// WithRegion creates a new client with a different region
-func (c *Client) WithRegion(ctx context.Context, region string) (*Client, error) {
-	return NewClient(ctx, c.Profile, region)
+// UsesFIPS reports whether the client talks to FIPS endpoints, whether
+// because it was asked to or because the profile or environment says so
+// (use_fips_endpoint, AWS_USE_FIPS_ENDPOINT)
+func (c *Client) UsesFIPS() bool {
+	for _, source := range c.Config.ConfigSources {
+		if s, ok := source.(interface {
+			GetUseFIPSEndpoint(context.Context) (aws.FIPSEndpointState, bool, error)
+		}); ok {
+			if state, found, err := s.GetUseFIPSEndpoint(context.Background()); err == nil && found {
+				return state == aws.FIPSEndpointStateEnabled
+			}
+		}
+	}
+	return false
 }
 
-// ProfileInfo contains information about an AWS profile
-type ProfileInfo struct {
-	Name       string
-	Region     string
-	SSOSession string
-	AccountID  string
+// WithRegion creates a new client with a different region and the same
+// endpoint options
+func (c *Client) WithRegion(ctx context.Context, region string) (*Client, error) {
+	return NewClient(ctx, c.Profile, region, c.Endpoints)
 }
 
-// ListProfiles returns a list of available AWS profiles from ~/.aws/config
-func ListProfiles() ([]ProfileInfo, error) {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return nil, fmt.Errorf("failed to get home directory: %w", err)
+// ForBucket returns a client for the region bucket lives in, or c itself if
+// it's already there. The client is kept, so each bucket's region is only
+// looked up once and buckets in the same region share a client.
+func (c *Client) ForBucket(ctx context.Context, bucket string) (*Client, error) {
+	c.bucketMu.Lock()
+	client, ok := c.buckets[bucket]
+	c.bucketMu.Unlock()
+	if ok {
+		return client, nil
 	}
 
-	configPath := filepath.Join(homeDir, ".aws", "config")
-	file, err := os.Open(configPath)
+	region, err := c.GetBucketRegion(ctx, bucket)
 	if err != nil {
-		return nil, fmt.Errorf("failed to open AWS config: %w", err)
+		return nil, err
 	}
-	defer file.Close()
-
-	var profiles []ProfileInfo
-	var currentProfile *ProfileInfo
-
-	scanner := bufio.NewScanner(file)
-	for scanner.Scan() {
-		line := strings.TrimSpace(scanner.Text())
-
-		// Skip empty lines and comments
-		if line == "" || strings.HasPrefix(line, "#") {
-			continue
-		}
-
-		// Check for section header
-		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
-			// Save previous profile if it exists and has SSO config
-			if currentProfile != nil && currentProfile.SSOSession != "" {
-				profiles = append(profiles, *currentProfile)
-			}
-
-			section := strings.TrimPrefix(strings.TrimSuffix(line, "]"), "[")
-
-			// Skip sso-session sections, only get profiles
-			if strings.HasPrefix(section, "sso-session ") {
-				currentProfile = nil
-				continue
+	client = c
+	if region != c.Region {
+		c.bucketMu.Lock()
+		for _, other := range c.buckets {
+			if other.Region == region {
+				client = other
+				break
 			}
-
-			// Extract profile name
-			name := section
-			if strings.HasPrefix(section, "profile ") {
-				name = strings.TrimPrefix(section, "profile ")
-			}
-
-			currentProfile = &ProfileInfo{Name: name}
-			continue
 		}
-
-		// Parse key-value pairs for current profile
-		if currentProfile != nil && strings.Contains(line, "=") {
-			parts := strings.SplitN(line, "=", 2)
-			if len(parts) == 2 {
-				key := strings.TrimSpace(parts[0])
-				value := strings.TrimSpace(parts[1])
-
-				switch key {
-				case "region":
-					currentProfile.Region = value
-				case "sso_session":
-					currentProfile.SSOSession = value
-				case "sso_account_id":
-					currentProfile.AccountID = value
-				}
+		c.bucketMu.Unlock()
+		if client == c {
+			if client, err = c.WithRegion(ctx, region); err != nil {
+				return nil, err
 			}
 		}
 	}
 
-	// Don't forget the last profile
-	if currentProfile != nil && currentProfile.SSOSession != "" {
-		profiles = append(profiles, *currentProfile)
+	c.bucketMu.Lock()
+	defer c.bucketMu.Unlock()
+	if c.buckets == nil {
+		c.buckets = make(map[string]*Client)
 	}
-
-	return profiles, scanner.Err()
+	c.buckets[bucket] = client
+	return client, nil
 }
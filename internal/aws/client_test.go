@@ -0,0 +1,43 @@
+package aws
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestForBucketKeepsClients(t *testing.T) {
+	var lookups atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lookups.Add(1)
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><LocationConstraint xmlns="http://s3.amazonaws.com/doc/2006-03-01/"></LocationConstraint>`))
+	}))
+	defer server.Close()
+	client := &Client{S3: s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	}), Region: "us-east-1"}
+
+	for range 3 {
+		for _, bucket := range []string{"logs", "exports"} {
+			got, err := client.ForBucket(context.Background(), bucket)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != client {
+				t.Errorf("ForBucket(%s) returned another client for a bucket in its own region", bucket)
+			}
+		}
+	}
+	if n := lookups.Load(); n != 2 {
+		t.Errorf("looked up bucket regions %d times, want once per bucket", n)
+	}
+}
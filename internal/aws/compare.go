@@ -0,0 +1,131 @@
+package aws
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ComparedField is one property of two objects side by side
+type ComparedField struct {
+	Name  string
+	Left  string // "" when the left object doesn't have it
+	Right string
+}
+
+// Same reports whether both objects have the same value
+func (f ComparedField) Same() bool {
+	return f.Left == f.Right
+}
+
+// CompareObjects lines up two objects' details field by field: size, ETag,
+// checksums, storage class, encryption, content headers, then metadata and
+// tags by key. Fields neither object has are left out, and last modified
+// and version ID aren't compared since copies always differ in them.
+func CompareObjects(left, right *ObjectDetails) []ComparedField {
+	var fields []ComparedField
+	add := func(name, l, r string) {
+		if l != "" || r != "" {
+			fields = append(fields, ComparedField{Name: name, Left: l, Right: r})
+		}
+	}
+
+	add("Size", fmt.Sprint(left.Size), fmt.Sprint(right.Size))
+	add("ETag", left.ETag, right.ETag)
+	for _, algorithm := range checksumAlgorithms(left, right) {
+		add(algorithm, checksumValue(left, algorithm), checksumValue(right, algorithm))
+	}
+	add("Storage class", left.StorageClass, right.StorageClass)
+	add("Encryption", left.Encryption, right.Encryption)
+	add("Content-Type", left.ContentType, right.ContentType)
+	add("Content-Encoding", left.ContentEncoding, right.ContentEncoding)
+	add("Content-Disposition", left.ContentDisposition, right.ContentDisposition)
+	add("Content-Language", left.ContentLanguage, right.ContentLanguage)
+	add("Cache-Control", left.CacheControl, right.CacheControl)
+	add("Expires", left.Expires, right.Expires)
+	for _, k := range unionKeys(left.Metadata, right.Metadata) {
+		add("meta: "+k, left.Metadata[k], right.Metadata[k])
+	}
+	for _, k := range unionKeys(left.Tags, right.Tags) {
+		add("tag: "+k, left.Tags[k], right.Tags[k])
+	}
+	return fields
+}
+
+// SameContent reports whether two objects hold the same bytes, as far as S3
+// can tell without downloading them: by size, then a checksum both were
+// uploaded with, then their ETags. known is false when none of those
+// settles it, e.g. for copies uploaded with different part sizes or KMS
+// encryption and no checksum in common. by names what decided it.
+func SameContent(left, right *ObjectDetails) (same, known bool, by string) {
+	if left.Size != right.Size {
+		return false, true, "size"
+	}
+	for _, algorithm := range checksumAlgorithms(left, right) {
+		l, r := checksumValue(left, algorithm), checksumValue(right, algorithm)
+		if l == "" || r == "" {
+			continue
+		}
+		if l == r {
+			return true, true, algorithm
+		}
+		// Composite checksums of different part sizes differ for the same bytes
+		if !strings.Contains(l, "-") && !strings.Contains(r, "-") {
+			return false, true, algorithm
+		}
+	}
+	if left.ETag == right.ETag {
+		return true, true, "ETag"
+	}
+	if md5ETag(left) && md5ETag(right) {
+		return false, true, "ETag"
+	}
+	return false, false, ""
+}
+
+// md5ETag reports whether an object's ETag is the MD5 of its content, so
+// different ETags mean different content
+func md5ETag(d *ObjectDetails) bool {
+	return !strings.Contains(d.ETag, "-") &&
+		!strings.HasPrefix(d.Encryption, "SSE-KMS") &&
+		!strings.HasPrefix(d.Encryption, "DSSE-KMS") &&
+		!strings.HasPrefix(d.Encryption, "SSE-C")
+}
+
+// checksumAlgorithms returns the algorithms either object has a checksum
+// for, strongest first
+func checksumAlgorithms(left, right *ObjectDetails) []string {
+	var algorithms []string
+	for _, c := range slices.Concat(left.Checksums, right.Checksums) {
+		if !slices.Contains(algorithms, c.Algorithm) {
+			algorithms = append(algorithms, c.Algorithm)
+		}
+	}
+	slices.SortStableFunc(algorithms, func(a, b string) int {
+		return slices.Index(checksumStrength, a) - slices.Index(checksumStrength, b)
+	})
+	return algorithms
+}
+
+// checksumValue returns an object's checksum for algorithm, or ""
+func checksumValue(d *ObjectDetails, algorithm string) string {
+	for _, c := range d.Checksums {
+		if c.Algorithm == algorithm {
+			return c.Value
+		}
+	}
+	return ""
+}
+
+// unionKeys returns the keys of a and b, sorted
+func unionKeys(a, b map[string]string) []string {
+	keys := slices.Collect(maps.Keys(a))
+	for k := range b {
+		if _, ok := a[k]; !ok {
+			keys = append(keys, k)
+		}
+	}
+	slices.Sort(keys)
+	return keys
+}
@@ -0,0 +1,103 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCompareObjects(t *testing.T) {
+	left := &ObjectDetails{
+		Size:         11,
+		ETag:         "5eb63bbbe01eeed093cb22bb8f5acdc3",
+		Checksums:    []Checksum{{Algorithm: "CRC32", Value: "DUoRhQ=="}},
+		StorageClass: "STANDARD",
+		Encryption:   "SSE-S3",
+		ContentType:  "text/plain",
+		Metadata:     map[string]string{"owner": "data"},
+		Tags:         map[string]string{"env": "prod"},
+	}
+	right := &ObjectDetails{
+		Size:         11,
+		ETag:         "5eb63bbbe01eeed093cb22bb8f5acdc3",
+		Checksums:    []Checksum{{Algorithm: "SHA-256", Value: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="}},
+		StorageClass: "STANDARD_IA",
+		Encryption:   "SSE-S3",
+		ContentType:  "text/plain",
+		Metadata:     map[string]string{"owner": "data", "replica": "true"},
+	}
+
+	got := CompareObjects(left, right)
+	want := []ComparedField{
+		{Name: "Size", Left: "11", Right: "11"},
+		{Name: "ETag", Left: "5eb63bbbe01eeed093cb22bb8f5acdc3", Right: "5eb63bbbe01eeed093cb22bb8f5acdc3"},
+		{Name: "SHA-256", Right: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="},
+		{Name: "CRC32", Left: "DUoRhQ=="},
+		{Name: "Storage class", Left: "STANDARD", Right: "STANDARD_IA"},
+		{Name: "Encryption", Left: "SSE-S3", Right: "SSE-S3"},
+		{Name: "Content-Type", Left: "text/plain", Right: "text/plain"},
+		{Name: "meta: owner", Left: "data", Right: "data"},
+		{Name: "meta: replica", Right: "true"},
+		{Name: "tag: env", Left: "prod"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("CompareObjects() =\n%v\nwant\n%v", got, want)
+	}
+}
+
+func TestSameContent(t *testing.T) {
+	tests := []struct {
+		name        string
+		left, right ObjectDetails
+		same, known bool
+		by          string
+	}{
+		{
+			name:  "sizes differ",
+			left:  ObjectDetails{Size: 1, ETag: "a"},
+			right: ObjectDetails{Size: 2, ETag: "a"},
+			known: true, by: "size",
+		},
+		{
+			name:  "shared checksum matches despite multipart etags",
+			left:  ObjectDetails{Size: 1, ETag: "a-2", Checksums: []Checksum{{Algorithm: "CRC32", Value: "x"}}},
+			right: ObjectDetails{Size: 1, ETag: "b-3", Checksums: []Checksum{{Algorithm: "CRC32", Value: "x"}}},
+			same:  true, known: true, by: "CRC32",
+		},
+		{
+			name:  "full-object checksum differs",
+			left:  ObjectDetails{Size: 1, ETag: "a", Checksums: []Checksum{{Algorithm: "CRC32C", Value: "x"}}},
+			right: ObjectDetails{Size: 1, ETag: "a", Checksums: []Checksum{{Algorithm: "CRC32C", Value: "y"}}},
+			known: true, by: "CRC32C",
+		},
+		{
+			name:  "composite checksums of other part sizes fall back to etag",
+			left:  ObjectDetails{Size: 1, ETag: "a-2", Checksums: []Checksum{{Algorithm: "SHA-256", Value: "x-2"}}},
+			right: ObjectDetails{Size: 1, ETag: "a-2", Checksums: []Checksum{{Algorithm: "SHA-256", Value: "y-3"}}},
+			same:  true, known: true, by: "ETag",
+		},
+		{
+			name:  "md5 etags differ",
+			left:  ObjectDetails{Size: 1, ETag: "a", Encryption: "SSE-S3"},
+			right: ObjectDetails{Size: 1, ETag: "b", Encryption: "none"},
+			known: true, by: "ETag",
+		},
+		{
+			name:  "kms etags can't tell",
+			left:  ObjectDetails{Size: 1, ETag: "a", Encryption: "SSE-KMS (key)"},
+			right: ObjectDetails{Size: 1, ETag: "b", Encryption: "SSE-S3"},
+		},
+		{
+			name:  "multipart etags can't tell",
+			left:  ObjectDetails{Size: 1, ETag: "a-2"},
+			right: ObjectDetails{Size: 1, ETag: "b"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			same, known, by := SameContent(&tt.left, &tt.right)
+			if same != tt.same || known != tt.known || by != tt.by {
+				t.Errorf("SameContent() = %v, %v, %q; want %v, %v, %q", same, known, by, tt.same, tt.known, tt.by)
+			}
+		})
+	}
+}
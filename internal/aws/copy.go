@@ -0,0 +1,256 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/natevick/stui/internal/pool"
+)
+
+// MinCopyPartSize is the part size for multipart copies. Parts are copied
+// inside S3, so they can be much larger than upload parts.
+const MinCopyPartSize = 512 * 1024 * 1024
+
+// CopyObjectTo copies an object to a key in any bucket in c's region without
+// downloading it, keeping its content type, metadata, tags, storage class
+// and KMS encryption. Objects over 5GB are copied in parts with
+// opts.Concurrency parts in flight.
+func (c *Client) CopyObjectTo(ctx context.Context, srcBucket, srcKey, dstBucket, dstKey string, opts UploadOptions, onProgress func(DownloadProgress)) error {
+	return c.CopyObjectBetween(ctx, c, srcBucket, srcKey, dstBucket, dstKey, opts, onProgress)
+}
+
+// CopyObjectBetween is CopyObjectTo for a destination bucket in another
+// region: c reads the source and dst, a client for the destination
+// bucket's region from ForBucket, writes the copy; a nil dst writes it
+// with c. S3 answers copy requests sent to any other region with a
+// redirect error.
+func (c *Client) CopyObjectBetween(ctx context.Context, dst *Client, srcBucket, srcKey, dstBucket, dstKey string, opts UploadOptions, onProgress func(DownloadProgress)) error {
+	if dst == nil {
+		dst = c
+	}
+	head, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(srcBucket),
+		Key:    aws.String(srcKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	size := aws.ToInt64(head.ContentLength)
+	if size > MaxCopySize {
+		return c.copyMultipart(ctx, dst, head, srcBucket, srcKey, dstBucket, dstKey, opts, onProgress)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:     aws.String(dstBucket),
+		Key:        aws.String(dstKey),
+		CopySource: aws.String(copySource(srcBucket, srcKey)),
+	}
+	// CopyObject otherwise resets these to the defaults
+	if head.StorageClass != "" {
+		input.StorageClass = head.StorageClass
+	}
+	if head.ServerSideEncryption == types.ServerSideEncryptionAwsKms {
+		input.ServerSideEncryption = head.ServerSideEncryption
+		input.SSEKMSKeyId = head.SSEKMSKeyId
+	}
+
+	if _, err := dst.S3.CopyObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to copy object: %w", err)
+	}
+	if onProgress != nil {
+		onProgress(DownloadProgress{BytesDownloaded: size, TotalBytes: size, Key: dstKey})
+	}
+	return nil
+}
+
+// copyMultipart copies an object too large for CopyObject with
+// UploadPartCopy. Multipart copies don't carry the source's attributes over,
+// so they're read and set on the new upload. dst writes the parts.
+func (c *Client) copyMultipart(ctx context.Context, dst *Client, head *s3.HeadObjectOutput, srcBucket, srcKey, dstBucket, dstKey string, opts UploadOptions, onProgress func(DownloadProgress)) error {
+	size := aws.ToInt64(head.ContentLength)
+
+	tagging, err := c.S3.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(srcBucket),
+		Key:    aws.String(srcKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object tags: %w", err)
+	}
+
+	create := &s3.CreateMultipartUploadInput{
+		Bucket:             aws.String(dstBucket),
+		Key:                aws.String(dstKey),
+		ContentType:        head.ContentType,
+		ContentEncoding:    head.ContentEncoding,
+		ContentDisposition: head.ContentDisposition,
+		ContentLanguage:    head.ContentLanguage,
+		CacheControl:       head.CacheControl,
+		Metadata:           head.Metadata,
+		StorageClass:       head.StorageClass,
+	}
+	if head.ServerSideEncryption == types.ServerSideEncryptionAwsKms {
+		create.ServerSideEncryption = head.ServerSideEncryption
+		create.SSEKMSKeyId = head.SSEKMSKeyId
+	}
+	if len(tagging.TagSet) > 0 {
+		tags := url.Values{}
+		for _, tag := range tagging.TagSet {
+			tags.Set(aws.ToString(tag.Key), aws.ToString(tag.Value))
+		}
+		create.Tagging = aws.String(tags.Encode())
+	}
+
+	upload, err := dst.S3.CreateMultipartUpload(ctx, create)
+	if err != nil {
+		return fmt.Errorf("failed to start multipart copy: %w", err)
+	}
+	uploadID := upload.UploadId
+
+	// Don't leave paid-for parts behind when the copy fails or is cancelled
+	completed := false
+	defer func() {
+		if !completed {
+			dst.S3.AbortMultipartUpload(context.Background(), &s3.AbortMultipartUploadInput{
+				Bucket:   aws.String(dstBucket),
+				Key:      aws.String(dstKey),
+				UploadId: uploadID,
+			})
+		}
+	}()
+
+	partSize := max(int64(MinCopyPartSize), size/int64(manager.MaxUploadParts)+1)
+	numParts := int32((size + partSize - 1) / partSize)
+
+	var mu sync.Mutex
+	var parts []types.CompletedPart
+	var copied int64
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	numbers := make([]int32, numParts)
+	for i := range numbers {
+		numbers[i] = int32(i + 1)
+	}
+	var copyErr error
+	pool.Each(ctx, opts.concurrency(), numbers, func(_ int, n int32) {
+		if ctx.Err() != nil {
+			return
+		}
+		start := int64(n-1) * partSize
+		end := min(start+partSize, size) - 1
+		output, err := dst.S3.UploadPartCopy(ctx, &s3.UploadPartCopyInput{
+			Bucket:          aws.String(dstBucket),
+			Key:             aws.String(dstKey),
+			UploadId:        uploadID,
+			PartNumber:      aws.Int32(n),
+			CopySource:      aws.String(copySource(srcBucket, srcKey)),
+			CopySourceRange: aws.String(fmt.Sprintf("bytes=%d-%d", start, end)),
+		})
+
+		mu.Lock()
+		defer mu.Unlock()
+		if err != nil {
+			if copyErr == nil {
+				copyErr = fmt.Errorf("failed to copy part %d: %w", n, err)
+			}
+			cancel()
+			return
+		}
+		parts = append(parts, types.CompletedPart{
+			PartNumber: aws.Int32(n),
+			ETag:       output.CopyPartResult.ETag,
+		})
+		copied += end - start + 1
+		if onProgress != nil {
+			onProgress(DownloadProgress{
+				BytesDownloaded: copied,
+				TotalBytes:      size,
+				Key:             dstKey,
+				PartsTotal:      int(numParts),
+				PartsDone:       len(parts),
+			})
+		}
+	})
+
+	if copyErr != nil {
+		return copyErr
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	sort.Slice(parts, func(i, j int) bool {
+		return aws.ToInt32(parts[i].PartNumber) < aws.ToInt32(parts[j].PartNumber)
+	})
+	_, err = dst.S3.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(dstBucket),
+		Key:             aws.String(dstKey),
+		UploadId:        uploadID,
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: parts},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart copy: %w", err)
+	}
+	completed = true
+	return nil
+}
+
+// StreamCopy copies an object to a bucket that dst has credentials for,
+// e.g. one in another account, by reading it with c and uploading it with
+// dst, so neither account needs access to the other's bucket. Content
+// type, metadata and storage class are carried over, and tags if c may read
+// them; the copy is encrypted with the destination bucket's default, since
+// a KMS key in the source account can't be used from the destination.
+func (c *Client) StreamCopy(ctx context.Context, dst *Client, srcBucket, srcKey, dstBucket, dstKey string, opts UploadOptions, onProgress func(DownloadProgress)) error {
+	output, err := c.S3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(srcBucket),
+		Key:    aws.String(srcKey),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object: %w", err)
+	}
+	defer output.Body.Close()
+
+	size := aws.ToInt64(output.ContentLength)
+	attrs := ObjectAttributes{
+		StorageClass: string(output.StorageClass),
+		ContentType:  aws.ToString(output.ContentType),
+		Metadata:     output.Metadata,
+	}
+	// Reading tags takes s3:GetObjectTagging, which read-only roles often
+	// lack; the copy goes ahead without them
+	tagging, err := c.S3.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(srcBucket),
+		Key:    aws.String(srcKey),
+	})
+	if err == nil && len(tagging.TagSet) > 0 {
+		attrs.Tags = make(map[string]string, len(tagging.TagSet))
+		for _, tag := range tagging.TagSet {
+			attrs.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+		}
+	}
+
+	if _, err := dst.uploadStream(ctx, dstBucket, dstKey, output.Body, size, opts, attrs, onProgress); err != nil {
+		return fmt.Errorf("failed to upload copy: %w", err)
+	}
+	return nil
+}
+
+// CopyDestination returns the key src is copied to under dst. A dst ending
+// in "/" (or empty, the bucket root) is a folder that keeps src's name;
+// anything else is the new key itself.
+func CopyDestination(src S3Object, dst string) string {
+	if dst == "" || strings.HasSuffix(dst, "/") {
+		return dst + src.DisplayName()
+	}
+	return dst
+}
@@ -0,0 +1,265 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// copyServer serves src/report.csv, with tags unless denyTags is set, and
+// records the request that uploads it
+type copyServer struct {
+	denyTags bool
+
+	mu     sync.Mutex
+	header http.Header
+	body   []byte
+}
+
+func (s *copyServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	switch {
+	case r.Method == http.MethodGet && r.URL.Query().Has("tagging"):
+		if s.denyTags {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Tagging><TagSet><Tag><Key>team</Key><Value>data</Value></Tag></TagSet></Tagging>`))
+	case r.Method == http.MethodGet:
+		w.Header().Set("Content-Type", "text/csv")
+		w.Header().Set("x-amz-meta-source", "export")
+		w.Header().Set("x-amz-storage-class", "STANDARD_IA")
+		w.Write([]byte("id,name\n1,a\n"))
+	case r.Method == http.MethodPut:
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.header, s.body = r.Header.Clone(), body
+		s.mu.Unlock()
+		w.Header().Set("ETag", `"abc"`)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestStreamCopy(t *testing.T) {
+	for _, denyTags := range []bool{false, true} {
+		srv := &copyServer{denyTags: denyTags}
+		server := httptest.NewServer(srv)
+		client := &Client{S3: s3.New(s3.Options{
+			Region:       "us-east-1",
+			BaseEndpoint: aws.String(server.URL),
+			UsePathStyle: true,
+			Credentials:  aws.AnonymousCredentials{},
+		}), Region: "us-east-1"}
+
+		var last DownloadProgress
+		err := client.StreamCopy(context.Background(), client, "src", "report.csv", "dst", "report.csv", UploadOptions{}, func(p DownloadProgress) {
+			last = p
+		})
+		server.Close()
+		if err != nil {
+			t.Fatalf("StreamCopy() with tags denied = %v: %v", denyTags, err)
+		}
+
+		if got := string(srv.body); got != "id,name\n1,a\n" {
+			t.Errorf("tags denied = %v: uploaded %q", denyTags, got)
+		}
+		h := srv.header
+		if h.Get("Content-Type") != "text/csv" || h.Get("X-Amz-Meta-Source") != "export" || h.Get("X-Amz-Storage-Class") != "STANDARD_IA" {
+			t.Errorf("tags denied = %v: uploaded with headers %v", denyTags, h)
+		}
+		wantTags := "team=data"
+		if denyTags {
+			wantTags = ""
+		}
+		if got := h.Get("X-Amz-Tagging"); got != wantTags {
+			t.Errorf("tags denied = %v: uploaded with tags %q, want %q", denyTags, got, wantTags)
+		}
+		if last.BytesDownloaded != 12 || last.TotalBytes != 12 || last.Key != "report.csv" {
+			t.Errorf("tags denied = %v: last progress = %+v", denyTags, last)
+		}
+	}
+}
+
+func TestUploadStreamCounts(t *testing.T) {
+	srv := &copyServer{}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := &Client{S3: s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	}), Region: "us-east-1"}
+
+	data := testData(1000)
+	n, err := client.UploadStream(context.Background(), "dst", "stream.bin", struct{ io.Reader }{bytes.NewReader(data)}, UploadOptions{}, ObjectAttributes{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n != int64(len(data)) || len(srv.body) != len(data) {
+		t.Errorf("UploadStream() = %d bytes, server got %d, want %d", n, len(srv.body), len(data))
+	}
+}
+
+// multipartServer serves a HEAD of a size-byte object and records a
+// multipart copy of it, denying the copy of part failPart if it's set
+type multipartServer struct {
+	size     int64
+	failPart string
+
+	mu       sync.Mutex
+	created  http.Header
+	ranges   map[string]string // part number to the source range copied
+	complete string            // the CompleteMultipartUpload body
+	aborted  bool
+}
+
+func (s *multipartServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	query := r.URL.Query()
+	switch {
+	case r.Method == http.MethodHead:
+		w.Header().Set("Content-Length", strconv.FormatInt(s.size, 10))
+		w.Header().Set("Content-Type", "video/mp4")
+		w.Header().Set("x-amz-meta-camera", "a7")
+	case r.Method == http.MethodGet && query.Has("tagging"):
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Tagging><TagSet/></Tagging>`))
+	case r.Method == http.MethodPost && query.Has("uploads"):
+		s.created = r.Header.Clone()
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><InitiateMultipartUploadResult><Bucket>dst</Bucket><Key>raw.mp4</Key><UploadId>u1</UploadId></InitiateMultipartUploadResult>`))
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		part := query.Get("partNumber")
+		if part == s.failPart {
+			w.WriteHeader(http.StatusForbidden)
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+			return
+		}
+		s.ranges[part] = r.Header.Get("X-Amz-Copy-Source-Range")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><CopyPartResult><ETag>"e` + part + `"</ETag></CopyPartResult>`))
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		body, _ := io.ReadAll(r.Body)
+		s.complete = string(body)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><CompleteMultipartUploadResult><ETag>"done"</ETag></CompleteMultipartUploadResult>`))
+	case r.Method == http.MethodDelete && query.Has("uploadId"):
+		s.aborted = true
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func (s *multipartServer) client(t *testing.T) *Client {
+	s.ranges = map[string]string{}
+	server := httptest.NewServer(s)
+	t.Cleanup(server.Close)
+	return &Client{S3: s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	}), Region: "us-east-1"}
+}
+
+func TestCopyMultipart(t *testing.T) {
+	const size = MaxCopySize + MinCopyPartSize + 10
+	srv := &multipartServer{size: size}
+	client := srv.client(t)
+
+	var mu sync.Mutex
+	var last DownloadProgress
+	err := client.CopyObjectTo(context.Background(), "src", "raw.mp4", "dst", "raw.mp4", UploadOptions{Concurrency: 3}, func(p DownloadProgress) {
+		mu.Lock()
+		defer mu.Unlock()
+		last = p
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 5GB is ten parts, leaving one more and 10 bytes for a twelfth
+	if len(srv.ranges) != 12 {
+		t.Fatalf("copied %d parts, want 12", len(srv.ranges))
+	}
+	for n := int64(1); n <= 12; n++ {
+		start := (n - 1) * MinCopyPartSize
+		end := min(start+MinCopyPartSize, size) - 1
+		want := fmt.Sprintf("bytes=%d-%d", start, end)
+		if got := srv.ranges[strconv.FormatInt(n, 10)]; got != want {
+			t.Errorf("part %d copied %q, want %q", n, got, want)
+		}
+	}
+	if srv.created.Get("Content-Type") != "video/mp4" || srv.created.Get("X-Amz-Meta-Camera") != "a7" {
+		t.Errorf("multipart copy created with headers %v", srv.created)
+	}
+	var complete struct {
+		Parts []struct {
+			ETag       string
+			PartNumber int
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal([]byte(srv.complete), &complete); err != nil {
+		t.Fatal(err)
+	}
+	if len(complete.Parts) != 12 {
+		t.Fatalf("completed with %d parts, want 12", len(complete.Parts))
+	}
+	for i, part := range complete.Parts {
+		if part.PartNumber != i+1 || part.ETag != fmt.Sprintf(`"e%d"`, i+1) {
+			t.Errorf("completed part %d is %+v", i+1, part)
+		}
+	}
+	if srv.aborted {
+		t.Error("a completed multipart copy was aborted")
+	}
+	if last.BytesDownloaded != size || last.PartsDone != 12 || last.PartsTotal != 12 {
+		t.Errorf("last progress = %+v", last)
+	}
+}
+
+func TestCopyMultipartAbortsOnFailure(t *testing.T) {
+	srv := &multipartServer{size: 2 * MaxCopySize, failPart: "7"}
+	client := srv.client(t)
+
+	err := client.CopyObjectTo(context.Background(), "src", "raw.mp4", "dst", "raw.mp4", UploadOptions{Concurrency: 4}, nil)
+	if err == nil || !strings.Contains(err.Error(), "part 7") {
+		t.Fatalf("CopyObjectTo() with a failing part = %v", err)
+	}
+	if !srv.aborted {
+		t.Error("the failed multipart copy wasn't aborted")
+	}
+	if srv.complete != "" {
+		t.Error("the failed multipart copy was completed")
+	}
+}
+
+func TestCopyDestination(t *testing.T) {
+	tests := []struct {
+		src  S3Object
+		dst  string
+		want string
+	}{
+		{S3Object{Key: "logs/app.log"}, "", "app.log"},
+		{S3Object{Key: "logs/app.log"}, "archive/", "archive/app.log"},
+		{S3Object{Key: "logs/app.log"}, "archive/old.log", "archive/old.log"},
+		{S3Object{Key: "app.log"}, "archive/2024/", "archive/2024/app.log"},
+	}
+	for _, tt := range tests {
+		if got := CopyDestination(tt.src, tt.dst); got != tt.want {
+			t.Errorf("CopyDestination(%q, %q) = %q, want %q", tt.src.Key, tt.dst, got, tt.want)
+		}
+	}
+}
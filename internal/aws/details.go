@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ObjectDetails is everything S3 reports about one object, for inspecting it
+type ObjectDetails struct {
+	Bucket             string
+	Key                string
+	Size               int64
+	LastModified       time.Time
+	StorageClass       string // "STANDARD" when S3 leaves it out
+	Encryption         string // e.g. "SSE-S3" or "SSE-KMS (key ARN)"
+	ETag               string
+	Checksums          []Checksum // additional checksums, strongest first
+	VersionID          string     // "" when versioning is off
+	ContentType        string
+	ContentEncoding    string
+	ContentDisposition string
+	ContentLanguage    string
+	CacheControl       string
+	Expires            string
+	Metadata           map[string]string // x-amz-meta-* headers, without the prefix
+	Tags               map[string]string
+	TagsErr            error // why the tags couldn't be read, e.g. no s3:GetObjectTagging
+}
+
+// GetObjectDetails fetches an object's headers, metadata and tags. Missing
+// permission to read tags is reported in TagsErr rather than failing.
+func (c *Client) GetObjectDetails(ctx context.Context, bucket, key string) (*ObjectDetails, error) {
+	head, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		ChecksumMode: types.ChecksumModeEnabled,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+
+	details := &ObjectDetails{
+		Bucket:             bucket,
+		Key:                key,
+		Size:               aws.ToInt64(head.ContentLength),
+		LastModified:       aws.ToTime(head.LastModified),
+		StorageClass:       string(head.StorageClass),
+		Encryption:         describeEncryption(head.ServerSideEncryption, aws.ToString(head.SSEKMSKeyId), aws.ToString(head.SSECustomerAlgorithm)),
+		ETag:               strings.Trim(aws.ToString(head.ETag), "\""),
+		VersionID:          aws.ToString(head.VersionId),
+		ContentType:        aws.ToString(head.ContentType),
+		ContentEncoding:    aws.ToString(head.ContentEncoding),
+		ContentDisposition: aws.ToString(head.ContentDisposition),
+		ContentLanguage:    aws.ToString(head.ContentLanguage),
+		CacheControl:       aws.ToString(head.CacheControl),
+		Expires:            aws.ToString(head.ExpiresString),
+		Metadata:           head.Metadata,
+	}
+	if details.StorageClass == "" {
+		details.StorageClass = "STANDARD"
+	}
+	_, count, _ := strings.Cut(details.ETag, "-")
+	parts, _ := strconv.Atoi(count) // 0 unless multipart
+	details.Checksums = c.additionalChecksums(ctx, bucket, key, headChecksum(head), parts)
+
+	tagging, err := c.S3.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		details.TagsErr = err
+		return details, nil
+	}
+	details.Tags = make(map[string]string, len(tagging.TagSet))
+	for _, tag := range tagging.TagSet {
+		details.Tags[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	return details, nil
+}
+
+// describeEncryption names an object's server-side encryption
+func describeEncryption(algorithm types.ServerSideEncryption, kmsKeyID, customerAlgorithm string) string {
+	var name string
+	switch {
+	case customerAlgorithm != "":
+		return "SSE-C (" + customerAlgorithm + ")"
+	case algorithm == types.ServerSideEncryptionAes256:
+		return "SSE-S3"
+	case algorithm == types.ServerSideEncryptionAwsKms:
+		name = "SSE-KMS"
+	case algorithm == types.ServerSideEncryptionAwsKmsDsse:
+		name = "DSSE-KMS"
+	case algorithm != "":
+		return string(algorithm)
+	default:
+		return "none"
+	}
+	if kmsKeyID != "" {
+		name += " (" + kmsKeyID + ")"
+	}
+	return name
+}
@@ -0,0 +1,25 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/sts"
+)
+
+// Identity is who a client's credentials belong to
+type Identity struct {
+	Account string
+	ARN     string // the user or assumed role
+}
+
+// CallerIdentity asks STS who the client's credentials belong to. It needs
+// no permissions, so it fails only when the credentials do.
+func (c *Client) CallerIdentity(ctx context.Context) (Identity, error) {
+	out, err := sts.NewFromConfig(c.Config).GetCallerIdentity(ctx, &sts.GetCallerIdentityInput{})
+	if err != nil {
+		return Identity{}, fmt.Errorf("failed to get caller identity: %w", err)
+	}
+	return Identity{Account: aws.ToString(out.Account), ARN: aws.ToString(out.Arn)}, nil
+}
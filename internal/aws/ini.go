@@ -0,0 +1,110 @@
+package aws
+
+import (
+	"strings"
+)
+
+// iniSection is one [section] of an INI file. Section names have their
+// inner whitespace collapsed and keys are lowercased; keys nested under a
+// key with no value, like max_concurrent_requests under s3, are stored as
+// "s3.max_concurrent_requests".
+type iniSection struct {
+	Name string
+	Keys map[string]string
+}
+
+// parseINI parses the INI dialect of the AWS shared config file. It's
+// lenient rather than strict: lines it can't make sense of, and keys
+// outside any section, are skipped, so a malformed file still yields the
+// sections it has.
+func parseINI(data string) []iniSection {
+	data = strings.TrimPrefix(data, "\ufeff")
+
+	var sections []iniSection
+	var current map[string]string // keys of the section being read
+	var parent, last string       // the key nested keys belong to, and the last key set
+
+	for _, raw := range strings.Split(data, "\n") {
+		raw = strings.TrimRight(raw, "\r")
+		line := strings.TrimSpace(raw)
+		if line == "" || line[0] == '#' || line[0] == ';' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				// Don't let the keys of a broken header land in the section before
+				current = nil
+				continue
+			}
+			name := strings.Join(strings.Fields(line[1:end]), " ")
+			if name == "" {
+				current = nil
+				continue
+			}
+			current = make(map[string]string)
+			sections = append(sections, iniSection{Name: name, Keys: current})
+			parent, last = "", ""
+			continue
+		}
+		if current == nil {
+			continue
+		}
+
+		indented := raw[0] == ' ' || raw[0] == '\t'
+		key, value, ok := strings.Cut(line, "=")
+		switch {
+		case indented && parent != "" && ok:
+			key = strings.ToLower(strings.TrimSpace(key))
+			if key != "" {
+				current[parent+"."+key] = iniValue(value)
+			}
+			continue
+		case indented && last != "" && parent == "":
+			// A continuation of the last key's value
+			current[last] += "\n" + iniValue(line)
+			continue
+		case !ok:
+			continue
+		}
+
+		key = strings.ToLower(strings.TrimSpace(key))
+		if key == "" {
+			continue
+		}
+		value = iniValue(value)
+		current[key] = value
+		last = key
+		parent = ""
+		if value == "" {
+			parent = key
+		}
+	}
+	return sections
+}
+
+// iniValue trims a value and drops an inline comment, a # or ; after
+// whitespace outside quotes. A value wholly in matching quotes loses them;
+// other quotes, e.g. around a credential_process path, are kept.
+func iniValue(v string) string {
+	var quote byte
+	for i := 0; i < len(v); i++ {
+		c := v[i]
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			}
+		case c == '"' || c == '\'':
+			quote = c
+		case (c == '#' || c == ';') && i > 0 && (v[i-1] == ' ' || v[i-1] == '\t'):
+			v = v[:i]
+		}
+	}
+	v = strings.TrimSpace(v)
+	if n := len(v); n >= 2 && (v[0] == '"' || v[0] == '\'') && v[n-1] == v[0] {
+		v = v[1 : n-1]
+	}
+	return v
+}
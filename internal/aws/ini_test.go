@@ -0,0 +1,157 @@
+package aws
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseINI(t *testing.T) {
+	input := "\ufeff# comment\n" +
+		"orphan = ignored\n" +
+		"[ profile   dev ]  # trailing comment\r\n" +
+		"Region = us-west-2 ; inline comment\n" +
+		"sso_session = \"my sso\"\n" +
+		"credential_process = \"/opt/my tool/creds\" --profile dev # run it\n" +
+		"s3 =\n" +
+		"  max_concurrent_requests = 20\n" +
+		"  region = eu-west-1\n" +
+		"output = json\n" +
+		"[broken\n" +
+		"region = lost\n" +
+		"[]\n" +
+		"region = lost too\n" +
+		"[sso-session my sso]\n" +
+		"sso_start_url = 'https://example.awsapps.com/start#/'\n"
+
+	got := parseINI(input)
+	want := []iniSection{
+		{Name: "profile dev", Keys: map[string]string{
+			"region":                     "us-west-2",
+			"sso_session":                "my sso",
+			"credential_process":         "\"/opt/my tool/creds\" --profile dev",
+			"s3":                         "",
+			"s3.max_concurrent_requests": "20",
+			"s3.region":                  "eu-west-1",
+			"output":                     "json",
+		}},
+		{Name: "sso-session my sso", Keys: map[string]string{
+			"sso_start_url": "https://example.awsapps.com/start#/",
+		}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parseINI() =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestProfilesFrom(t *testing.T) {
+	sections := parseINI(`
+[default]
+region = us-east-1
+sso_session = corp
+sso_account_id = 111111111111
+
+[sso-session corp]
+sso_start_url = https://corp.awsapps.com/start
+sso_region = us-east-1
+
+[profile legacy]
+sso_start_url = https://old.awsapps.com/start
+sso_account_id = 222222222222
+
+[profile admin]
+role_arn = arn:aws:iam::333333333333:role/Admin
+source_profile = default
+
+[profile tool]
+credential_process = /usr/local/bin/creds
+
+[profile static]
+region = eu-west-1
+
+[services local]
+s3 =
+  endpoint_url = http://localhost:9000
+
+[profile tool]
+region = ap-south-1
+`)
+
+	got := profilesFrom(sections)
+	want := []ProfileInfo{
+		{Name: "default", Kind: ProfileSSO, Region: "us-east-1", SSOSession: "corp", AccountID: "111111111111"},
+		{Name: "legacy", Kind: ProfileSSO, AccountID: "222222222222"},
+		{Name: "admin", Kind: ProfileRole, AccountID: "333333333333", SourceProfile: "default"},
+		{Name: "tool", Kind: ProfileProcess, Region: "ap-south-1"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("profilesFrom() =\n%+v\nwant\n%+v", got, want)
+	}
+}
+
+func TestListProfilesIncludes(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	config := write("config", "[profile main]\nsso_session = corp\n\n[include]\npath = conf.d/work\n")
+	write("conf.d/work", "[profile work]\ncredential_process = creds\n\n[include]\npath = ../config\n")
+	t.Setenv("AWS_CONFIG_FILE", config)
+
+	profiles, err := ListProfiles()
+	if err != nil {
+		t.Fatalf("ListProfiles() error = %v", err)
+	}
+	var names []string
+	for _, p := range profiles {
+		names = append(names, p.Name)
+	}
+	if strings.Join(names, ",") != "main,work" {
+		t.Errorf("ListProfiles() names = %v, want [main work]", names)
+	}
+
+	files, _, err := loadSharedConfig()
+	if err != nil || len(files) != 2 {
+		t.Errorf("loadSharedConfig() files = %v, %v; want the config and its include", files, err)
+	}
+
+	write("config", "[include]\npath = missing\n")
+	if _, err := ListProfiles(); err == nil {
+		t.Error("ListProfiles() with a missing include succeeded")
+	}
+}
+
+func FuzzParseINI(f *testing.F) {
+	f.Add("[profile a]\nregion = us-east-1\n")
+	f.Add("[default]\nsso_session = x\n[sso-session x]\nsso_start_url = 'u'\n")
+	f.Add("[profile b]\ncredential_process = \"/bin/creds\" # c\ns3 =\n  region = x\n")
+	f.Add("[profile c]\nrole_arn = arn:aws:iam::123:role/r\nsource_profile = a\n")
+	f.Add("[\n]\n=\n[ ]\n\t= x\n\"'\n[include]\npath=\n")
+
+	f.Fuzz(func(t *testing.T, data string) {
+		for _, section := range parseINI(data) {
+			if section.Name == "" || section.Name != strings.TrimSpace(section.Name) {
+				t.Fatalf("section name %q isn't trimmed and non-empty", section.Name)
+			}
+			for key := range section.Keys {
+				if key == "" || key != strings.ToLower(key) {
+					t.Fatalf("key %q isn't lowercased and non-empty", key)
+				}
+			}
+		}
+		for _, p := range profilesFrom(parseINI(data)) {
+			if p.Name == "" || p.Kind == "" {
+				t.Fatalf("profile %+v has no name or kind", p)
+			}
+		}
+	})
+}
@@ -0,0 +1,287 @@
+//go:build integration
+
+package aws_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/itest"
+)
+
+func TestMain(m *testing.M) {
+	itest.Main(m)
+}
+
+func TestListPagination(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+
+	// One more page than the 1000 keys S3 returns at a time
+	const count = 1005
+	var wg sync.WaitGroup
+	keys := make(chan string)
+	for range 16 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for key := range keys {
+				if err := client.PutObject(context.Background(), bucket, key, []byte(key), "", aws.Encryption{}); err != nil {
+					t.Error(err)
+				}
+			}
+		}()
+	}
+	for i := range count {
+		keys <- fmt.Sprintf("logs/%04d.txt", i)
+	}
+	close(keys)
+	wg.Wait()
+
+	ctx := context.Background()
+	all, err := client.ListAllObjects(ctx, bucket, "logs/")
+	if err != nil {
+		t.Fatalf("ListAllObjects() error = %v", err)
+	}
+	if len(all) != count {
+		t.Errorf("ListAllObjects() returned %d objects, want %d", len(all), count)
+	}
+
+	first, token, err := client.ListObjectsPage(ctx, bucket, "logs/", "/", "")
+	if err != nil {
+		t.Fatalf("ListObjectsPage() error = %v", err)
+	}
+	if len(first) != 1000 || token == "" {
+		t.Fatalf("first page = %d objects, token %q; want 1000 and a token", len(first), token)
+	}
+	second, token, err := client.ListObjectsPage(ctx, bucket, "logs/", "/", token)
+	if err != nil {
+		t.Fatalf("ListObjectsPage() second page error = %v", err)
+	}
+	if len(second) != count-1000 || token != "" {
+		t.Errorf("second page = %d objects, token %q; want %d and no token", len(second), token, count-1000)
+	}
+}
+
+func TestMultipartRoundTrip(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+	dir := t.TempDir()
+
+	// Three upload parts, and two parts of the downloader's 10MB
+	data := make([]byte, 2*aws.MinUploadPartSize+1024)
+	rand.Read(data)
+	src := filepath.Join(dir, "src.bin")
+	if err := os.WriteFile(src, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	opts := aws.UploadOptions{PartSize: aws.MinUploadPartSize}
+	if err := client.UploadFile(ctx, bucket, "big.bin", src, opts, aws.ObjectAttributes{}, nil); err != nil {
+		t.Fatalf("UploadFile() error = %v", err)
+	}
+
+	obj, err := client.GetObjectMetadata(ctx, bucket, "big.bin")
+	if err != nil {
+		t.Fatalf("GetObjectMetadata() error = %v", err)
+	}
+	if obj.Size != int64(len(data)) {
+		t.Errorf("Size = %d, want %d", obj.Size, len(data))
+	}
+	if !strings.HasSuffix(obj.ETag, "-3") {
+		t.Errorf("ETag = %q, want a 3-part multipart ETag", obj.ETag)
+	}
+
+	// Parts arrive concurrently, so progress is only known to peak at the end
+	var mu sync.Mutex
+	var downloaded int64
+	dst := filepath.Join(dir, "out", "big.bin")
+	err = client.DownloadFile(ctx, bucket, "big.bin", dst, aws.DownloadOptions{PartSize: 5 * 1024 * 1024, Concurrency: 3}, func(p aws.DownloadProgress) {
+		mu.Lock()
+		downloaded = max(downloaded, p.BytesDownloaded)
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatalf("DownloadFile() error = %v", err)
+	}
+	got, err := os.ReadFile(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("downloaded file differs from the uploaded one")
+	}
+	if downloaded != int64(len(data)) {
+		t.Errorf("progress peaked at %d bytes, want %d", downloaded, len(data))
+	}
+}
+
+func TestErrorPaths(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+	itest.Put(t, client, bucket, "present.txt", []byte("hello"))
+	ctx := context.Background()
+
+	if _, err := client.GetObjectMetadata(ctx, bucket, "missing.txt"); err == nil {
+		t.Error("GetObjectMetadata() of a missing key succeeded")
+	}
+
+	exists, err := client.ObjectExists(ctx, bucket, "missing.txt")
+	if err != nil || exists {
+		t.Errorf("ObjectExists() = %v, %v; want false, nil", exists, err)
+	}
+
+	dst := filepath.Join(t.TempDir(), "missing.txt")
+	if err := client.DownloadFile(ctx, bucket, "missing.txt", dst, aws.DownloadOptions{}, nil); err == nil {
+		t.Error("DownloadFile() of a missing key succeeded")
+	}
+	if _, err := os.Stat(dst); !os.IsNotExist(err) {
+		t.Errorf("DownloadFile() left %s behind", dst)
+	}
+
+	if err := client.CheckBucketAccess(ctx, bucket+"-missing"); err == nil {
+		t.Error("CheckBucketAccess() of a missing bucket succeeded")
+	}
+	if _, err := client.ListAllObjects(ctx, bucket+"-missing", ""); err == nil {
+		t.Error("ListAllObjects() of a missing bucket succeeded")
+	}
+
+	// The object changed since its ETag was read
+	headers := aws.ObjectHeaders{ContentType: "text/plain"}
+	if err := client.UpdateObjectHeaders(ctx, bucket, "present.txt", "00000000000000000000000000000000", headers); err == nil {
+		t.Error("UpdateObjectHeaders() with a stale ETag succeeded")
+	}
+
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if _, err := client.ListAllObjects(cancelled, bucket, ""); err == nil {
+		t.Error("ListAllObjects() with a cancelled context succeeded")
+	}
+}
+
+func TestUndelete(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+	ctx := context.Background()
+	_, err := client.S3.PutBucketVersioning(ctx, &s3.PutBucketVersioningInput{
+		Bucket:                  awssdk.String(bucket),
+		VersioningConfiguration: &types.VersioningConfiguration{Status: types.BucketVersioningStatusEnabled},
+	})
+	if err != nil {
+		t.Skipf("the server doesn't support versioning: %v", err)
+	}
+	// Versions have to go before the bucket can
+	t.Cleanup(func() {
+		versions, _, _ := client.ListVersions(ctx, bucket, "docs/")
+		for _, v := range versions {
+			client.DeleteVersion(ctx, bucket, v.Key, v.VersionID)
+		}
+	})
+
+	itest.Put(t, client, bucket, "docs/a.txt", []byte("one"))
+	itest.Put(t, client, bucket, "docs/a.txt", []byte("second"))
+	if err := client.DeleteObject(ctx, bucket, "docs/a.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if exists, _ := client.ObjectExists(ctx, bucket, "docs/a.txt"); exists {
+		t.Fatal("the object is still there after DeleteObject()")
+	}
+
+	versions, truncated, err := client.ListVersions(ctx, bucket, "docs/")
+	if err != nil || truncated {
+		t.Fatalf("ListVersions() = %v, %v", truncated, err)
+	}
+	if len(versions) != 3 {
+		t.Fatalf("ListVersions() returned %d versions, want 3: %+v", len(versions), versions)
+	}
+	marker := versions[0]
+	if !marker.DeleteMarker || !marker.IsLatest {
+		t.Fatalf("newest version = %+v, want the current delete marker", marker)
+	}
+	if versions[1].DeleteMarker || versions[1].IsLatest || versions[1].Size != int64(len("second")) {
+		t.Errorf("second version = %+v, want the last upload", versions[1])
+	}
+
+	if err := client.DeleteVersion(ctx, bucket, marker.Key, marker.VersionID); err != nil {
+		t.Fatalf("DeleteVersion() error = %v", err)
+	}
+	obj, err := client.GetObjectMetadata(ctx, bucket, "docs/a.txt")
+	if err != nil || obj.Size != int64(len("second")) {
+		t.Errorf("after removing the delete marker GetObjectMetadata() = %+v, %v; want the last upload", obj, err)
+	}
+}
+
+func TestTagObject(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+	ctx := context.Background()
+
+	itest.Put(t, client, bucket, "exports/a.csv", []byte("a,b\n"))
+	if err := client.TagObject(ctx, bucket, "exports/a.csv", map[string]string{"team": "data"}); err != nil {
+		t.Skipf("the server doesn't support tagging: %v", err)
+	}
+	if err := client.TagObject(ctx, bucket, "exports/a.csv", map[string]string{"cost-center": "1234", "team": "etl"}); err != nil {
+		t.Fatalf("TagObject() error = %v", err)
+	}
+	details, err := client.GetObjectDetails(ctx, bucket, "exports/a.csv")
+	if err != nil {
+		t.Fatalf("GetObjectDetails() error = %v", err)
+	}
+	if want := map[string]string{"cost-center": "1234", "team": "etl"}; !maps.Equal(details.Tags, want) {
+		t.Errorf("tags = %v, want %v", details.Tags, want)
+	}
+	if err := client.TagObject(ctx, bucket, "exports/a.csv", map[string]string{"team": "etl"}); !errors.Is(err, aws.ErrAlreadyTagged) {
+		t.Errorf("TagObject() with the same tags error = %v, want ErrAlreadyTagged", err)
+	}
+}
+
+func TestRangeReader(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+	ctx := context.Background()
+
+	body := make([]byte, 600<<10)
+	if _, err := rand.Read(body); err != nil {
+		t.Fatal(err)
+	}
+	itest.Put(t, client, bucket, "data/part-0.parquet", body)
+
+	r := client.NewRangeReader(ctx, bucket, "data/part-0.parquet", int64(len(body)), 400<<10)
+	tail := make([]byte, 8)
+	if _, err := r.ReadAt(tail, int64(len(body)-8)); err != nil {
+		t.Fatalf("ReadAt() of the tail error = %v", err)
+	}
+	if !bytes.Equal(tail, body[len(body)-8:]) {
+		t.Error("ReadAt() of the tail returned the wrong bytes")
+	}
+	head := make([]byte, 100)
+	for range 3 {
+		if _, err := r.ReadAt(head, 10); err != nil {
+			t.Fatalf("ReadAt() error = %v", err)
+		}
+	}
+	if !bytes.Equal(head, body[10:110]) {
+		t.Error("ReadAt() returned the wrong bytes")
+	}
+	if fetched, requests := r.Fetched(); requests != 2 || fetched > 400<<10 {
+		t.Errorf("Fetched() = %d bytes in %d requests, want 2 requests within the budget", fetched, requests)
+	}
+	if _, err := r.ReadAt(make([]byte, 300<<10), 300<<10); !errors.Is(err, aws.ErrRangeBudget) {
+		t.Errorf("ReadAt() past the budget error = %v, want ErrRangeBudget", err)
+	}
+}
@@ -0,0 +1,145 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/natevick/stui/internal/security"
+)
+
+// ObjectHeaders are the headers UpdateObjectHeaders sets on an object
+type ObjectHeaders struct {
+	ContentType  string
+	CacheControl string            // "" removes it
+	Metadata     map[string]string // x-amz-meta-* headers, without the prefix
+}
+
+// UpdateObjectHeaders replaces an object's Content-Type, Cache-Control and
+// user metadata. S3 can't change metadata in place, so the object is copied
+// onto itself with the REPLACE metadata directive, which changes its last
+// modified time and, in versioned buckets, adds a version. Its other
+// headers, tags, storage class and KMS encryption are kept. If etag isn't
+// empty the copy only goes ahead while the object still has that ETag.
+func (c *Client) UpdateObjectHeaders(ctx context.Context, bucket, key, etag string, headers ObjectHeaders) error {
+	if err := security.ValidMetadata(headers.Metadata); err != nil {
+		return err
+	}
+
+	head, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	if etag != "" && strings.Trim(aws.ToString(head.ETag), "\"") != etag {
+		return errors.New("the object changed since it was inspected; reopen it and try again")
+	}
+	if aws.ToInt64(head.ContentLength) > MaxCopySize {
+		return errors.New("objects over 5GB can't be copied onto themselves to change metadata")
+	}
+	if head.SSECustomerAlgorithm != nil {
+		return errors.New("objects encrypted with a customer-provided key (SSE-C) can't be edited")
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:             aws.String(bucket),
+		Key:                aws.String(key),
+		CopySource:         aws.String(copySource(bucket, key)),
+		MetadataDirective:  types.MetadataDirectiveReplace,
+		Metadata:           headers.Metadata,
+		ContentEncoding:    head.ContentEncoding,
+		ContentDisposition: head.ContentDisposition,
+		ContentLanguage:    head.ContentLanguage,
+		Expires:            head.Expires,
+	}
+	if etag != "" {
+		input.CopySourceIfMatch = head.ETag
+	}
+	if headers.ContentType != "" {
+		input.ContentType = aws.String(headers.ContentType)
+	}
+	if headers.CacheControl != "" {
+		input.CacheControl = aws.String(headers.CacheControl)
+	}
+	// CopyObject otherwise resets these to the defaults
+	if head.StorageClass != "" {
+		input.StorageClass = head.StorageClass
+	}
+	switch head.ServerSideEncryption {
+	case types.ServerSideEncryptionAwsKms, types.ServerSideEncryptionAwsKmsDsse:
+		input.ServerSideEncryption = head.ServerSideEncryption
+		input.SSEKMSKeyId = head.SSEKMSKeyId
+		input.BucketKeyEnabled = head.BucketKeyEnabled
+	}
+
+	if _, err := c.S3.CopyObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to update metadata: %w", err)
+	}
+	return nil
+}
+
+// ParseMetadata parses user metadata written as "key=value, key2=value2".
+// A comma or backslash inside a value is escaped with a backslash, as
+// FormatMetadata writes it. Keys are lowercased, as S3 stores them.
+func ParseMetadata(s string) (map[string]string, error) {
+	metadata := make(map[string]string)
+	for _, pair := range splitMetadata(s) {
+		pair = strings.TrimSpace(pair)
+		if pair == "" {
+			continue
+		}
+		k, v, ok := strings.Cut(pair, "=")
+		k = strings.ToLower(strings.TrimSpace(k))
+		if !ok || k == "" {
+			return nil, fmt.Errorf("invalid metadata %q (use key=value)", pair)
+		}
+		metadata[k] = strings.TrimSpace(v)
+	}
+	if err := security.ValidMetadata(metadata); err != nil {
+		return nil, err
+	}
+	return metadata, nil
+}
+
+// splitMetadata splits s at its unescaped commas and unescapes each part
+func splitMetadata(s string) []string {
+	var parts []string
+	var part strings.Builder
+	escaped := false
+	for _, r := range s {
+		switch {
+		case escaped:
+			part.WriteRune(r)
+			escaped = false
+		case r == '\\':
+			escaped = true
+		case r == ',':
+			parts = append(parts, part.String())
+			part.Reset()
+		default:
+			part.WriteRune(r)
+		}
+	}
+	return append(parts, part.String())
+}
+
+// metadataEscaper escapes what splitMetadata would otherwise split on
+var metadataEscaper = strings.NewReplacer(`\`, `\\`, ",", `\,`)
+
+// FormatMetadata writes user metadata the way ParseMetadata reads it,
+// sorted by key
+func FormatMetadata(metadata map[string]string) string {
+	pairs := make([]string, 0, len(metadata))
+	for _, k := range slices.Sorted(maps.Keys(metadata)) {
+		pairs = append(pairs, k+"="+metadataEscaper.Replace(metadata[k]))
+	}
+	return strings.Join(pairs, ", ")
+}
@@ -0,0 +1,104 @@
+package aws
+
+import (
+	"context"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+func TestMetadataRoundTrip(t *testing.T) {
+	tests := []map[string]string{
+		{},
+		{"owner": "data-team"},
+		{"owner": "data-team", "source": "a, b, c"},
+		{"path": `C:\logs\`, "list": `x\,y`},
+		{"empty": ""},
+	}
+	for _, metadata := range tests {
+		s := FormatMetadata(metadata)
+		got, err := ParseMetadata(s)
+		if err != nil {
+			t.Errorf("ParseMetadata(%q): %v", s, err)
+			continue
+		}
+		if !maps.Equal(got, metadata) {
+			t.Errorf("ParseMetadata(FormatMetadata(%v)) = %v", metadata, got)
+		}
+	}
+}
+
+func TestParseMetadata(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    map[string]string
+		wantErr bool
+	}{
+		{"Owner=me, team = data ", map[string]string{"owner": "me", "team": "data"}, false},
+		{"note=a\\, b", map[string]string{"note": "a, b"}, false},
+		{"", map[string]string{}, false},
+		{"owner", nil, true},
+		{"=value", nil, true},
+		{"bad key=value", nil, true},
+		{"owner=café", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseMetadata(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParseMetadata(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && !maps.Equal(got, tt.want) {
+			t.Errorf("ParseMetadata(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+// TestUpdateObjectHeadersKeepsEncryption checks that the self-copy asks for
+// the object's KMS encryption again, DSSE included
+func TestUpdateObjectHeadersKeepsEncryption(t *testing.T) {
+	for _, algorithm := range []string{"aws:kms", "aws:kms:dsse"} {
+		var copied http.Header
+		server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.Method {
+			case http.MethodHead:
+				w.Header().Set("ETag", `"abc"`)
+				w.Header().Set("Content-Length", "5")
+				w.Header().Set("x-amz-server-side-encryption", algorithm)
+				w.Header().Set("x-amz-server-side-encryption-aws-kms-key-id", "key-1")
+			case http.MethodPut:
+				copied = r.Header.Clone()
+				w.Header().Set("Content-Type", "application/xml")
+				w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><CopyObjectResult><ETag>&quot;def&quot;</ETag></CopyObjectResult>`))
+			}
+		}))
+		client := &Client{S3: s3.New(s3.Options{
+			Region:       "us-east-1",
+			BaseEndpoint: aws.String(server.URL),
+			UsePathStyle: true,
+			Credentials:  aws.AnonymousCredentials{},
+		}), Region: "us-east-1"}
+
+		err := client.UpdateObjectHeaders(context.Background(), "bucket", "a.txt", "abc", ObjectHeaders{
+			ContentType: "text/plain",
+			Metadata:    map[string]string{"owner": "me"},
+		})
+		server.Close()
+		if err != nil {
+			t.Fatalf("%s: %v", algorithm, err)
+		}
+		if got := copied.Get("x-amz-server-side-encryption"); got != algorithm {
+			t.Errorf("%s: copy asked for encryption %q", algorithm, got)
+		}
+		if got := copied.Get("x-amz-server-side-encryption-aws-kms-key-id"); got != "key-1" {
+			t.Errorf("%s: copy asked for KMS key %q", algorithm, got)
+		}
+		if got := copied.Get("x-amz-meta-owner"); got != "me" {
+			t.Errorf("%s: copy set owner metadata %q", algorithm, got)
+		}
+	}
+}
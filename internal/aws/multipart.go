@@ -0,0 +1,350 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/feature/s3/manager"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// UploadedPart is a part S3 already holds for an incomplete multipart upload
+type UploadedPart struct {
+	Number   int32
+	Size     int64
+	ETag     string
+	Checksum string // base64, in the upload's checksum algorithm; "" without one
+}
+
+// IncompleteUpload is a multipart upload that was started (by stui or
+// anything else) and never completed or aborted, e.g. after a cancel or crash
+type IncompleteUpload struct {
+	Key       string
+	UploadID  string
+	Initiated time.Time
+	Parts     []UploadedPart
+
+	// The additional checksum the upload was started with, if any. S3
+	// refuses to complete it unless every part carries one.
+	ChecksumAlgorithm types.ChecksumAlgorithm
+	ChecksumType      types.ChecksumType
+}
+
+// Bytes returns the number of bytes already uploaded
+func (u IncompleteUpload) Bytes() int64 {
+	var n int64
+	for _, p := range u.Parts {
+		n += p.Size
+	}
+	return n
+}
+
+// PartSize returns the part size the upload was started with, or 0 if no
+// parts have been uploaded. Every part but the last has this size.
+func (u IncompleteUpload) PartSize() int64 {
+	var size int64
+	for _, p := range u.Parts {
+		if p.Size > size {
+			size = p.Size
+		}
+	}
+	return size
+}
+
+// FindIncompleteUpload returns the most recently started incomplete multipart
+// upload for key, with the parts uploaded so far, or nil if there is none
+func (c *Client) FindIncompleteUpload(ctx context.Context, bucket, key string) (*IncompleteUpload, error) {
+	var latest *IncompleteUpload
+
+	paginator := s3.NewListMultipartUploadsPaginator(c.S3, &s3.ListMultipartUploadsInput{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(key),
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list multipart uploads: %w", err)
+		}
+		for _, u := range output.Uploads {
+			if aws.ToString(u.Key) != key {
+				continue
+			}
+			initiated := aws.ToTime(u.Initiated)
+			if latest == nil || initiated.After(latest.Initiated) {
+				latest = &IncompleteUpload{
+					Key:               key,
+					UploadID:          aws.ToString(u.UploadId),
+					Initiated:         initiated,
+					ChecksumAlgorithm: u.ChecksumAlgorithm,
+					ChecksumType:      u.ChecksumType,
+				}
+			}
+		}
+	}
+	if latest == nil {
+		return nil, nil
+	}
+
+	parts := s3.NewListPartsPaginator(c.S3, &s3.ListPartsInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(latest.UploadID),
+	})
+	for parts.HasMorePages() {
+		output, err := parts.NextPage(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("failed to list uploaded parts: %w", err)
+		}
+		if latest.ChecksumAlgorithm == "" {
+			latest.ChecksumAlgorithm = output.ChecksumAlgorithm
+		}
+		for _, p := range output.Parts {
+			latest.Parts = append(latest.Parts, UploadedPart{
+				Number: aws.ToInt32(p.PartNumber),
+				Size:   aws.ToInt64(p.Size),
+				ETag:   strings.Trim(aws.ToString(p.ETag), "\""),
+				Checksum: partChecksum(latest.ChecksumAlgorithm, types.Checksum{
+					ChecksumCRC32:     p.ChecksumCRC32,
+					ChecksumCRC32C:    p.ChecksumCRC32C,
+					ChecksumCRC64NVME: p.ChecksumCRC64NVME,
+					ChecksumSHA1:      p.ChecksumSHA1,
+					ChecksumSHA256:    p.ChecksumSHA256,
+				}),
+			})
+		}
+	}
+	return latest, nil
+}
+
+// AbortUpload abandons an incomplete multipart upload, deleting its parts
+func (c *Client) AbortUpload(ctx context.Context, bucket, key, uploadID string) error {
+	_, err := c.S3.AbortMultipartUpload(ctx, &s3.AbortMultipartUploadInput{
+		Bucket:   aws.String(bucket),
+		Key:      aws.String(key),
+		UploadId: aws.String(uploadID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to abort multipart upload: %w", err)
+	}
+	return nil
+}
+
+// ResumeUpload finishes an incomplete multipart upload from localPath. Parts
+// S3 already has are kept if their size and MD5 match the same range of the
+// local file; every other part is uploaded again. The object keeps the
+// attributes and checksum algorithm the upload was started with.
+func (c *Client) ResumeUpload(ctx context.Context, bucket, key, localPath string, upload *IncompleteUpload, opts UploadOptions, onProgress func(DownloadProgress)) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %w", err)
+	}
+	size := info.Size()
+
+	// A lone short part is the file's tail, not a guide to the part size
+	partSize := upload.PartSize()
+	if partSize < MinUploadPartSize {
+		partSize = opts.partSize(size)
+	}
+	numParts := int32((size + partSize - 1) / partSize)
+	if numParts == 0 {
+		numParts = 1
+	}
+	if numParts > int32(manager.MaxUploadParts) {
+		return fmt.Errorf("%s no longer fits in %d parts of %d bytes", localPath, manager.MaxUploadParts, partSize)
+	}
+	partLength := func(n int32) int64 {
+		return min(partSize, size-int64(n-1)*partSize)
+	}
+
+	// Keep the parts that still match the local file
+	done := make(map[int32]UploadedPart)
+	var doneBytes int64
+	for _, p := range upload.Parts {
+		if p.Number > numParts {
+			return fmt.Errorf("%s is smaller than the interrupted upload; start over instead", localPath)
+		}
+		if p.Size != partLength(p.Number) {
+			continue
+		}
+		sum, err := sectionMD5(file, int64(p.Number-1)*partSize, p.Size)
+		if err != nil {
+			return fmt.Errorf("failed to read local file: %w", err)
+		}
+		if sum == p.ETag && (upload.ChecksumAlgorithm == "" || p.Checksum != "") {
+			done[p.Number] = p
+			doneBytes += p.Size
+		}
+	}
+
+	var todo []int32
+	for n := int32(1); n <= numParts; n++ {
+		if _, ok := done[n]; !ok {
+			todo = append(todo, n)
+		}
+	}
+
+	var mu sync.Mutex
+	report := func() {
+		if onProgress != nil {
+			onProgress(DownloadProgress{
+				BytesDownloaded: doneBytes,
+				TotalBytes:      size,
+				Key:             key,
+				PartsTotal:      int(numParts),
+				PartsDone:       len(done),
+			})
+		}
+	}
+	report()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	jobs := make(chan int32)
+	errs := make(chan error, opts.concurrency())
+	var wg sync.WaitGroup
+	for i := 0; i < opts.concurrency(); i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := range jobs {
+				output, err := c.S3.UploadPart(ctx, &s3.UploadPartInput{
+					Bucket:     aws.String(bucket),
+					Key:        aws.String(key),
+					UploadId:   aws.String(upload.UploadID),
+					PartNumber: aws.Int32(n),
+					Body:       io.NewSectionReader(file, int64(n-1)*partSize, partLength(n)),
+					// The SDK computes the checksum as it sends the part
+					ChecksumAlgorithm: upload.ChecksumAlgorithm,
+				})
+				if err != nil {
+					errs <- fmt.Errorf("failed to upload part %d: %w", n, err)
+					cancel()
+					return
+				}
+				mu.Lock()
+				done[n] = UploadedPart{
+					Number: n,
+					ETag:   strings.Trim(aws.ToString(output.ETag), "\""),
+					Checksum: partChecksum(upload.ChecksumAlgorithm, types.Checksum{
+						ChecksumCRC32:     output.ChecksumCRC32,
+						ChecksumCRC32C:    output.ChecksumCRC32C,
+						ChecksumCRC64NVME: output.ChecksumCRC64NVME,
+						ChecksumSHA1:      output.ChecksumSHA1,
+						ChecksumSHA256:    output.ChecksumSHA256,
+					}),
+				}
+				doneBytes += partLength(n)
+				report()
+				mu.Unlock()
+			}
+		}()
+	}
+
+feed:
+	for _, n := range todo {
+		select {
+		case jobs <- n:
+		case <-ctx.Done():
+			break feed
+		}
+	}
+	close(jobs)
+	wg.Wait()
+
+	select {
+	case err := <-errs:
+		return err
+	default:
+	}
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	completed := make([]types.CompletedPart, 0, len(done))
+	for _, p := range done {
+		completed = append(completed, completedPart(upload.ChecksumAlgorithm, p))
+	}
+	sort.Slice(completed, func(i, j int) bool {
+		return aws.ToInt32(completed[i].PartNumber) < aws.ToInt32(completed[j].PartNumber)
+	})
+
+	_, err = c.S3.CompleteMultipartUpload(ctx, &s3.CompleteMultipartUploadInput{
+		Bucket:          aws.String(bucket),
+		Key:             aws.String(key),
+		UploadId:        aws.String(upload.UploadID),
+		MultipartUpload: &types.CompletedMultipartUpload{Parts: completed},
+		ChecksumType:    upload.ChecksumType,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to complete multipart upload: %w", err)
+	}
+	return nil
+}
+
+// partChecksum returns the checksum in algorithm out of those S3 reported
+// for a part
+func partChecksum(algorithm types.ChecksumAlgorithm, sum types.Checksum) string {
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		return aws.ToString(sum.ChecksumCRC32)
+	case types.ChecksumAlgorithmCrc32c:
+		return aws.ToString(sum.ChecksumCRC32C)
+	case types.ChecksumAlgorithmCrc64nvme:
+		return aws.ToString(sum.ChecksumCRC64NVME)
+	case types.ChecksumAlgorithmSha1:
+		return aws.ToString(sum.ChecksumSHA1)
+	case types.ChecksumAlgorithmSha256:
+		return aws.ToString(sum.ChecksumSHA256)
+	}
+	return ""
+}
+
+// completedPart lists a part for CompleteMultipartUpload, with its checksum
+// in the upload's algorithm
+func completedPart(algorithm types.ChecksumAlgorithm, p UploadedPart) types.CompletedPart {
+	part := types.CompletedPart{PartNumber: aws.Int32(p.Number), ETag: aws.String(p.ETag)}
+	if p.Checksum == "" {
+		return part
+	}
+	sum := aws.String(p.Checksum)
+	switch algorithm {
+	case types.ChecksumAlgorithmCrc32:
+		part.ChecksumCRC32 = sum
+	case types.ChecksumAlgorithmCrc32c:
+		part.ChecksumCRC32C = sum
+	case types.ChecksumAlgorithmCrc64nvme:
+		part.ChecksumCRC64NVME = sum
+	case types.ChecksumAlgorithmSha1:
+		part.ChecksumSHA1 = sum
+	case types.ChecksumAlgorithmSha256:
+		part.ChecksumSHA256 = sum
+	}
+	return part
+}
+
+// sectionMD5 returns the hex MD5 of length bytes of r starting at offset,
+// which is the ETag S3 gives an unencrypted or SSE-S3 part
+func sectionMD5(r io.ReaderAt, offset, length int64) (string, error) {
+	hash := md5.New()
+	if _, err := io.Copy(hash, io.NewSectionReader(r, offset, length)); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hash.Sum(nil)), nil
+}
@@ -0,0 +1,146 @@
+package aws
+
+import (
+	"context"
+	"crypto/md5"
+	"encoding/hex"
+	"encoding/xml"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// resumeServer holds an interrupted CRC32 multipart upload of key "big.bin"
+// with its first part uploaded, and records the parts and completion the
+// resume sends
+type resumeServer struct {
+	first []byte // the part S3 already has
+
+	mu       sync.Mutex
+	parts    map[string]http.Header // part number → UploadPart headers
+	complete string
+	header   http.Header // CompleteMultipartUpload headers
+}
+
+func (s *resumeServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	w.Header().Set("Content-Type", "application/xml")
+	switch {
+	case r.Method == http.MethodGet && query.Has("uploads"):
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><ListMultipartUploadsResult><Bucket>bucket</Bucket><IsTruncated>false</IsTruncated>` +
+			`<Upload><Key>big.bin</Key><UploadId>u1</UploadId><Initiated>2024-01-01T00:00:00.000Z</Initiated>` +
+			`<ChecksumAlgorithm>CRC32</ChecksumAlgorithm><ChecksumType>COMPOSITE</ChecksumType></Upload></ListMultipartUploadsResult>`))
+	case r.Method == http.MethodGet && query.Has("uploadId"):
+		sum := md5.Sum(s.first)
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><ListPartsResult><Bucket>bucket</Bucket><Key>big.bin</Key><UploadId>u1</UploadId>` +
+			`<IsTruncated>false</IsTruncated><ChecksumAlgorithm>CRC32</ChecksumAlgorithm>` +
+			`<Part><PartNumber>1</PartNumber><ETag>"` + hex.EncodeToString(sum[:]) + `"</ETag><Size>` + strconv.Itoa(len(s.first)) + `</Size>` +
+			`<ChecksumCRC32>c1</ChecksumCRC32></Part></ListPartsResult>`))
+	case r.Method == http.MethodPut && query.Has("partNumber"):
+		io.Copy(io.Discard, r.Body)
+		part := query.Get("partNumber")
+		s.mu.Lock()
+		s.parts[part] = r.Header.Clone()
+		s.mu.Unlock()
+		w.Header().Set("ETag", `"e`+part+`"`)
+		w.Header().Set("x-amz-checksum-crc32", "c"+part)
+	case r.Method == http.MethodPost && query.Has("uploadId"):
+		body, _ := io.ReadAll(r.Body)
+		s.mu.Lock()
+		s.complete, s.header = string(body), r.Header.Clone()
+		s.mu.Unlock()
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><CompleteMultipartUploadResult><ETag>"done"</ETag></CompleteMultipartUploadResult>`))
+	default:
+		w.WriteHeader(http.StatusNotImplemented)
+	}
+}
+
+func TestResumeUploadKeepsChecksums(t *testing.T) {
+	data := testData(int(2*MinUploadPartSize + 10))
+	path := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	srv := &resumeServer{first: data[:MinUploadPartSize], parts: map[string]http.Header{}}
+	server := httptest.NewServer(srv)
+	defer server.Close()
+	client := &Client{S3: s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	}), Region: "us-east-1"}
+
+	upload, err := client.FindIncompleteUpload(context.Background(), "bucket", "big.bin")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if upload == nil || upload.ChecksumAlgorithm != types.ChecksumAlgorithmCrc32 || upload.ChecksumType != types.ChecksumTypeComposite {
+		t.Fatalf("FindIncompleteUpload() = %+v, want the CRC32 upload", upload)
+	}
+	if len(upload.Parts) != 1 || upload.Parts[0].Checksum != "c1" {
+		t.Fatalf("uploaded parts = %+v, want part 1 with its checksum", upload.Parts)
+	}
+
+	if err := client.ResumeUpload(context.Background(), "bucket", "big.bin", path, upload, UploadOptions{}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// Only the missing parts are sent, each with a CRC32 of its own
+	if len(srv.parts) != 2 || srv.parts["1"] != nil {
+		t.Fatalf("uploaded parts %v, want 2 and 3", srv.parts)
+	}
+	for n, header := range srv.parts {
+		if header.Get("X-Amz-Checksum-Crc32") == "" && !strings.EqualFold(header.Get("X-Amz-Trailer"), "x-amz-checksum-crc32") {
+			t.Errorf("part %s was sent without a CRC32 checksum", n)
+		}
+	}
+
+	var complete struct {
+		Parts []struct {
+			PartNumber    int
+			ETag          string
+			ChecksumCRC32 string
+		} `xml:"Part"`
+	}
+	if err := xml.Unmarshal([]byte(srv.complete), &complete); err != nil {
+		t.Fatal(err)
+	}
+	if len(complete.Parts) != 3 {
+		t.Fatalf("completed with %d parts, want 3", len(complete.Parts))
+	}
+	for i, part := range complete.Parts {
+		want := "c" + strconv.Itoa(i+1)
+		if part.PartNumber != i+1 || part.ChecksumCRC32 != want {
+			t.Errorf("completed part %d is %+v, want checksum %s", i+1, part, want)
+		}
+	}
+	if got := srv.header.Get("X-Amz-Checksum-Type"); got != "COMPOSITE" {
+		t.Errorf("completed with checksum type %q, want COMPOSITE", got)
+	}
+}
+
+func TestCompletedPart(t *testing.T) {
+	part := UploadedPart{Number: 2, ETag: "e2", Checksum: "abc="}
+	if got := completedPart(types.ChecksumAlgorithmSha256, part); aws.ToString(got.ChecksumSHA256) != "abc=" || got.ChecksumCRC32 != nil {
+		t.Errorf("completedPart(SHA256) = %+v", got)
+	}
+
+	// Uploads started without a checksum complete with ETags alone
+	part.Checksum = ""
+	got := completedPart("", part)
+	if aws.ToInt32(got.PartNumber) != 2 || aws.ToString(got.ETag) != "e2" || got.ChecksumCRC32 != nil {
+		t.Errorf("completedPart() without a checksum = %+v", got)
+	}
+}
@@ -0,0 +1,138 @@
+package aws
+
+import (
+	"fmt"
+	"net/url"
+	"slices"
+	"strings"
+)
+
+// Partition is a group of AWS regions with its own credentials, ARNs and
+// console: the standard one, China and GovCloud. A profile only works in
+// its own partition.
+type Partition struct {
+	ID            string // as in ARNs, e.g. "aws-us-gov"
+	Name          string
+	DefaultRegion string // where buckets without a location constraint live
+	Regions       []string
+	FIPSRegions   []string // regions with FIPS endpoints for S3 and STS
+	ConsoleHost   string
+}
+
+// Partitions are the partitions stui knows, standard first
+var Partitions = []Partition{
+	{
+		ID:            "aws",
+		Name:          "AWS",
+		DefaultRegion: "us-east-1",
+		Regions: []string{
+			"us-east-1", "us-east-2", "us-west-1", "us-west-2",
+			"af-south-1",
+			"ap-east-1", "ap-south-1", "ap-south-2", "ap-southeast-1", "ap-southeast-2",
+			"ap-southeast-3", "ap-southeast-4", "ap-southeast-5", "ap-southeast-7",
+			"ap-northeast-1", "ap-northeast-2", "ap-northeast-3",
+			"ca-central-1", "ca-west-1",
+			"eu-central-1", "eu-central-2", "eu-west-1", "eu-west-2", "eu-west-3",
+			"eu-north-1", "eu-south-1", "eu-south-2",
+			"il-central-1", "me-south-1", "me-central-1", "mx-central-1",
+			"sa-east-1",
+		},
+		FIPSRegions: []string{"us-east-1", "us-east-2", "us-west-1", "us-west-2", "ca-central-1", "ca-west-1"},
+		ConsoleHost: "console.aws.amazon.com",
+	},
+	{
+		ID:            "aws-cn",
+		Name:          "AWS China",
+		DefaultRegion: "cn-north-1",
+		Regions:       []string{"cn-north-1", "cn-northwest-1"},
+		ConsoleHost:   "console.amazonaws.cn",
+	},
+	{
+		ID:            "aws-us-gov",
+		Name:          "AWS GovCloud (US)",
+		DefaultRegion: "us-gov-west-1",
+		Regions:       []string{"us-gov-west-1", "us-gov-east-1"},
+		FIPSRegions:   []string{"us-gov-west-1", "us-gov-east-1"},
+		ConsoleHost:   "console.amazonaws-us-gov.com",
+	},
+}
+
+// PartitionForRegion returns the partition a region belongs to. Regions
+// stui doesn't know yet are placed by their prefix, so a new standard
+// region still maps to "aws".
+func PartitionForRegion(region string) Partition {
+	for _, p := range Partitions {
+		if slices.Contains(p.Regions, region) {
+			return p
+		}
+	}
+	switch {
+	case strings.HasPrefix(region, "cn-"):
+		return Partitions[1]
+	case strings.HasPrefix(region, "us-gov-"):
+		return Partitions[2]
+	}
+	return Partitions[0]
+}
+
+// KnownRegion reports whether region is in one of the partitions' lists
+func KnownRegion(region string) bool {
+	for _, p := range Partitions {
+		if slices.Contains(p.Regions, region) {
+			return true
+		}
+	}
+	return false
+}
+
+// FIPSAvailable reports whether region has FIPS endpoints for S3 and STS
+func FIPSAvailable(region string) bool {
+	return slices.Contains(PartitionForRegion(region).FIPSRegions, region)
+}
+
+// Partition returns the partition of the client's region
+func (c *Client) Partition() Partition {
+	return PartitionForRegion(c.Region)
+}
+
+// BucketARN returns a bucket's ARN in the partition, e.g.
+// arn:aws-cn:s3:::logs
+func (p Partition) BucketARN(bucket string) string {
+	return "arn:" + p.ID + ":s3:::" + bucket
+}
+
+// ConsoleURL links to a bucket, prefix or object in the partition's S3
+// console. key is "" for the bucket itself and ends in "/" for a prefix.
+func (p Partition) ConsoleURL(region, bucket, key string) string {
+	query := url.Values{"region": {region}}
+	page := "buckets"
+	if key != "" {
+		query.Set("prefix", key)
+		if !strings.HasSuffix(key, "/") {
+			page = "object"
+		}
+	}
+	return fmt.Sprintf("https://%s/s3/%s/%s?%s", p.ConsoleHost, page, url.PathEscape(bucket), query.Encode())
+}
+
+// ParseBucketARN splits an S3 ARN like arn:aws-us-gov:s3:::bucket/key into
+// its partition, bucket and key. Access point and other resource ARNs are
+// refused.
+func ParseBucketARN(arn string) (p Partition, bucket, key string, err error) {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) != 6 || parts[0] != "arn" {
+		return p, "", "", fmt.Errorf("not an ARN: %q", arn)
+	}
+	i := slices.IndexFunc(Partitions, func(p Partition) bool { return p.ID == parts[1] })
+	if i < 0 {
+		return p, "", "", fmt.Errorf("unknown partition %q in %q", parts[1], arn)
+	}
+	if parts[2] != "s3" || parts[3] != "" || parts[4] != "" {
+		return p, "", "", fmt.Errorf("not an S3 bucket ARN: %q", arn)
+	}
+	bucket, key, _ = strings.Cut(parts[5], "/")
+	if bucket == "" {
+		return p, "", "", fmt.Errorf("missing bucket in %q", arn)
+	}
+	return Partitions[i], bucket, key, nil
+}
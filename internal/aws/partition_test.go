@@ -0,0 +1,96 @@
+package aws
+
+import (
+	"context"
+	"testing"
+)
+
+func TestPartitionForRegion(t *testing.T) {
+	tests := []struct {
+		region, want string
+	}{
+		{"us-east-1", "aws"},
+		{"eu-west-3", "aws"},
+		{"xx-future-9", "aws"},
+		{"cn-northwest-1", "aws-cn"},
+		{"cn-south-7", "aws-cn"},
+		{"us-gov-east-1", "aws-us-gov"},
+		{"us-gov-central-1", "aws-us-gov"},
+	}
+	for _, tt := range tests {
+		if got := PartitionForRegion(tt.region).ID; got != tt.want {
+			t.Errorf("PartitionForRegion(%q) = %s, want %s", tt.region, got, tt.want)
+		}
+	}
+}
+
+func TestFIPSAvailable(t *testing.T) {
+	for region, want := range map[string]bool{
+		"us-east-1":     true,
+		"ca-central-1":  true,
+		"us-gov-west-1": true,
+		"eu-west-1":     false,
+		"cn-north-1":    false,
+	} {
+		if got := FIPSAvailable(region); got != want {
+			t.Errorf("FIPSAvailable(%q) = %v, want %v", region, got, want)
+		}
+	}
+}
+
+func TestParseBucketARN(t *testing.T) {
+	p, bucket, key, err := ParseBucketARN("arn:aws-us-gov:s3:::records/2024/a.csv")
+	if err != nil || p.ID != "aws-us-gov" || bucket != "records" || key != "2024/a.csv" {
+		t.Errorf("ParseBucketARN() = %s, %q, %q, %v", p.ID, bucket, key, err)
+	}
+	if got := p.BucketARN(bucket); got != "arn:aws-us-gov:s3:::records" {
+		t.Errorf("BucketARN() = %q", got)
+	}
+
+	for _, arn := range []string{
+		"arn:aws-iso:s3:::records",                              // unknown partition
+		"arn:aws:s3:us-east-1:123456789012:accesspoint/reports", // access point
+		"arn:aws:iam::123456789012:role/Admin",
+		"arn:aws:s3:::",
+		"s3://records",
+	} {
+		if _, _, _, err := ParseBucketARN(arn); err == nil {
+			t.Errorf("ParseBucketARN(%q) succeeded", arn)
+		}
+	}
+}
+
+func TestConsoleURL(t *testing.T) {
+	tests := []struct {
+		region, bucket, key, want string
+	}{
+		{"us-east-1", "logs", "", "https://console.aws.amazon.com/s3/buckets/logs?region=us-east-1"},
+		{"cn-north-1", "logs", "2024/", "https://console.amazonaws.cn/s3/buckets/logs?prefix=2024%2F&region=cn-north-1"},
+		{"us-gov-west-1", "logs", "a b.txt", "https://console.amazonaws-us-gov.com/s3/object/logs?prefix=a+b.txt&region=us-gov-west-1"},
+	}
+	for _, tt := range tests {
+		if got := PartitionForRegion(tt.region).ConsoleURL(tt.region, tt.bucket, tt.key); got != tt.want {
+			t.Errorf("ConsoleURL(%q, %q) = %s, want %s", tt.bucket, tt.key, got, tt.want)
+		}
+	}
+}
+
+func TestNewClientFIPS(t *testing.T) {
+	dir := t.TempDir()
+	t.Setenv("AWS_CONFIG_FILE", dir+"/config")
+	t.Setenv("AWS_SHARED_CREDENTIALS_FILE", dir+"/credentials")
+	t.Setenv("AWS_USE_FIPS_ENDPOINT", "")
+
+	for region, want := range map[string]bool{"us-gov-west-1": true, "eu-west-1": false} {
+		client, err := NewClient(context.Background(), "", region, EndpointOptions{FIPS: true})
+		if err != nil {
+			t.Fatalf("NewClient(%s) error = %v", region, err)
+		}
+		if got := client.UsesFIPS(); got != want {
+			t.Errorf("UsesFIPS() in %s = %v, want %v", region, got, want)
+		}
+		if regional, err := client.WithRegion(context.Background(), "us-east-1"); err != nil || !regional.UsesFIPS() {
+			t.Errorf("WithRegion(us-east-1) from %s lost FIPS: %v", region, err)
+		}
+	}
+}
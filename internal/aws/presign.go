@@ -0,0 +1,109 @@
+package aws
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MaxPresignTTL is the longest a SigV4 presigned URL can be valid for.
+// URLs signed with temporary (e.g. SSO) credentials stop working when the
+// credentials expire, whichever comes first.
+const MaxPresignTTL = 7 * 24 * time.Hour
+
+// ParsePresignTTL parses how long a presigned URL stays valid, as a Go
+// duration ("90m", "36h") or a number of days ("3d")
+func ParsePresignTTL(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var ttl time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry %q", s)
+		}
+		ttl = time.Duration(n) * 24 * time.Hour
+	} else {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, fmt.Errorf("invalid expiry %q (use e.g. 30m, 12h or 3d)", s)
+		}
+		ttl = d
+	}
+	if ttl < time.Second || ttl > MaxPresignTTL {
+		return 0, fmt.Errorf("expiry must be between 1s and 7d, got %q", s)
+	}
+	return ttl, nil
+}
+
+// PresignGetObject returns a URL anyone can download the object from until
+// ttl has passed, without AWS credentials
+func (c *Client) PresignGetObject(ctx context.Context, bucket, key string, ttl time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(c.S3)
+	req, err := presigner.PresignGetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign URL: %w", err)
+	}
+	return req.URL, nil
+}
+
+// PresignPutObject returns a URL anyone can upload one object to with an
+// HTTP PUT until ttl has passed, without AWS credentials. The object gets
+// the bucket's default encryption.
+func (c *Client) PresignPutObject(ctx context.Context, bucket, key string, ttl time.Duration) (string, error) {
+	presigner := s3.NewPresignClient(c.S3)
+	req, err := presigner.PresignPutObject(ctx, &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}, s3.WithPresignExpires(ttl))
+	if err != nil {
+		return "", fmt.Errorf("failed to presign URL: %w", err)
+	}
+	return req.URL, nil
+}
+
+// CurlCommand returns a shell command that downloads a presigned URL to
+// file, or uploads file to it if upload is set
+func CurlCommand(url, file string, upload bool) string {
+	if upload {
+		return "curl -T " + shellQuote(file) + " " + shellQuote(url)
+	}
+	return "curl -o " + shellQuote(file) + " " + shellQuote(url)
+}
+
+// PowerShellCommand is CurlCommand for Windows PowerShell, where curl may
+// be an alias for Invoke-WebRequest with different flags
+func PowerShellCommand(url, file string, upload bool) string {
+	if upload {
+		return "Invoke-WebRequest -Method Put -InFile " + powerShellQuote(file) + " -Uri " + powerShellQuote(url)
+	}
+	return "Invoke-WebRequest -OutFile " + powerShellQuote(file) + " -Uri " + powerShellQuote(url)
+}
+
+// shellQuote quotes s for a POSIX shell; presigned URLs contain & and %
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// powerShellQuote quotes s as a PowerShell literal string. PowerShell also
+// ends such strings at the typographic single quotes, so those are doubled too
+func powerShellQuote(s string) string {
+	var b strings.Builder
+	b.WriteByte('\'')
+	for _, r := range s {
+		switch r {
+		case '\'', '‘', '’', '‚', '‛':
+			b.WriteRune(r)
+		}
+		b.WriteRune(r)
+	}
+	b.WriteByte('\'')
+	return b.String()
+}
@@ -0,0 +1,56 @@
+package aws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestShareCommands(t *testing.T) {
+	url := "https://logs.s3.amazonaws.com/a.csv?X-Amz-Expires=3600&X-Amz-Signature=abc"
+
+	tests := []struct {
+		name, got, want string
+	}{
+		{"curl", CurlCommand(url, "a.csv", false), "curl -o 'a.csv' '" + url + "'"},
+		{"curl upload", CurlCommand(url, "a.csv", true), "curl -T 'a.csv' '" + url + "'"},
+		{"curl quoting", CurlCommand(url, "it's.csv", false), `curl -o 'it'\''s.csv' '` + url + "'"},
+		{"powershell", PowerShellCommand(url, "a.csv", false), "Invoke-WebRequest -OutFile 'a.csv' -Uri '" + url + "'"},
+		{"powershell upload", PowerShellCommand(url, "a.csv", true), "Invoke-WebRequest -Method Put -InFile 'a.csv' -Uri '" + url + "'"},
+		{"powershell quoting", PowerShellCommand(url, "it's.csv", false), "Invoke-WebRequest -OutFile 'it''s.csv' -Uri '" + url + "'"},
+		{"powershell smart quotes", PowerShellCommand(url, "it’s ‘q’.csv", false), "Invoke-WebRequest -OutFile 'it’’s ‘‘q’’.csv' -Uri '" + url + "'"},
+	}
+	for _, tt := range tests {
+		if tt.got != tt.want {
+			t.Errorf("%s = %s, want %s", tt.name, tt.got, tt.want)
+		}
+	}
+}
+
+func TestParsePresignTTL(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"30m", 30 * time.Minute, false},
+		{" 12h ", 12 * time.Hour, false},
+		{"1s", time.Second, false},
+		{"3d", 3 * 24 * time.Hour, false},
+		{"7d", MaxPresignTTL, false},
+		{"168h", MaxPresignTTL, false},
+		{"8d", 0, true},
+		{"169h", 0, true},
+		{"500ms", 0, true},
+		{"0d", 0, true},
+		{"-1h", 0, true},
+		{"xd", 0, true},
+		{"soon", 0, true},
+		{"", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePresignTTL(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParsePresignTTL(%q) = %v, %v, want %v", tt.in, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,192 @@
+package aws
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// Kinds of profile ListProfiles returns
+const (
+	ProfileSSO     = "sso"     // IAM Identity Center, with an sso-session or a legacy sso_start_url
+	ProfileRole    = "role"    // assumes role_arn with another profile's credentials
+	ProfileProcess = "process" // gets credentials from credential_process
+)
+
+// maxIncludeDepth bounds how deeply config files can include each other
+const maxIncludeDepth = 8
+
+// ProfileInfo contains information about an AWS profile
+type ProfileInfo struct {
+	Name          string
+	Kind          string
+	Region        string
+	SSOSession    string
+	AccountID     string // sso_account_id, or the account in role_arn
+	SourceProfile string // the profile a role is assumed with
+}
+
+// ListProfiles returns the profiles in the AWS config file that stui can
+// get credentials for on its own: SSO, assumed role and credential_process
+// profiles. The file is ~/.aws/config unless AWS_CONFIG_FILE says
+// otherwise; files it names in [include] sections' path keys are read too,
+// relative to it.
+func ListProfiles() ([]ProfileInfo, error) {
+	_, sections, err := loadSharedConfig()
+	if err != nil {
+		return nil, err
+	}
+	return profilesFrom(sections), nil
+}
+
+// SharedConfigPath returns the path of the AWS config file, which
+// AWS_CONFIG_FILE can change
+func SharedConfigPath() (string, error) {
+	if path := os.Getenv("AWS_CONFIG_FILE"); path != "" {
+		return path, nil
+	}
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+	return filepath.Join(homeDir, ".aws", "config"), nil
+}
+
+// loadSharedConfig reads the AWS config file and the files it includes. It
+// returns the files in the order the SDK should load them, and their
+// sections with each include's sections in its place.
+func loadSharedConfig() ([]string, []iniSection, error) {
+	path, err := SharedConfigPath()
+	if err != nil {
+		return nil, nil, err
+	}
+	var files []string
+	sections, err := readConfigFile(path, 0, &files)
+	if err != nil {
+		return nil, nil, err
+	}
+	return files, sections, nil
+}
+
+// readConfigFile parses path, following its includes. files collects every
+// file read so far, which also stops include cycles.
+func readConfigFile(path string, depth int, files *[]string) ([]iniSection, error) {
+	if depth > maxIncludeDepth {
+		return nil, fmt.Errorf("AWS config includes are nested more than %d deep at %s", maxIncludeDepth, path)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve AWS config path: %w", err)
+	}
+	for _, seen := range *files {
+		if seen == abs {
+			return nil, nil
+		}
+	}
+
+	data, err := os.ReadFile(abs)
+	if err != nil {
+		if depth == 0 {
+			return nil, fmt.Errorf("failed to open AWS config: %w", err)
+		}
+		return nil, fmt.Errorf("failed to open included AWS config: %w", err)
+	}
+	*files = append(*files, abs)
+
+	var sections []iniSection
+	for _, section := range parseINI(string(data)) {
+		if section.Name != "include" {
+			sections = append(sections, section)
+			continue
+		}
+		include := section.Keys["path"]
+		if include == "" {
+			continue
+		}
+		if rest, ok := strings.CutPrefix(include, "~/"); ok {
+			if home, err := os.UserHomeDir(); err == nil {
+				include = filepath.Join(home, rest)
+			}
+		}
+		if !filepath.IsAbs(include) {
+			include = filepath.Join(filepath.Dir(abs), include)
+		}
+		included, err := readConfigFile(include, depth+1, files)
+		if err != nil {
+			return nil, err
+		}
+		sections = append(sections, included...)
+	}
+	return sections, nil
+}
+
+// profilesFrom collects the usable profiles in sections. A profile that's
+// defined more than once is merged, later keys winning, as the SDK does.
+func profilesFrom(sections []iniSection) []ProfileInfo {
+	var names []string
+	merged := make(map[string]map[string]string)
+	for _, section := range sections {
+		name, ok := profileName(section.Name)
+		if !ok {
+			continue
+		}
+		keys, seen := merged[name]
+		if !seen {
+			keys = make(map[string]string)
+			merged[name] = keys
+			names = append(names, name)
+		}
+		for k, v := range section.Keys {
+			keys[k] = v
+		}
+	}
+
+	var profiles []ProfileInfo
+	for _, name := range names {
+		keys := merged[name]
+		p := ProfileInfo{
+			Name:          name,
+			Region:        keys["region"],
+			SSOSession:    keys["sso_session"],
+			AccountID:     keys["sso_account_id"],
+			SourceProfile: keys["source_profile"],
+		}
+		switch {
+		case keys["role_arn"] != "":
+			p.Kind = ProfileRole
+			if p.AccountID == "" {
+				p.AccountID = arnAccount(keys["role_arn"])
+			}
+		case p.SSOSession != "" || keys["sso_start_url"] != "":
+			p.Kind = ProfileSSO
+		case keys["credential_process"] != "":
+			p.Kind = ProfileProcess
+		default:
+			continue
+		}
+		profiles = append(profiles, p)
+	}
+	return profiles
+}
+
+// profileName returns the profile a config file section defines, if any.
+// Only [default] and [profile name] sections are profiles; sso-session,
+// services and other sections aren't.
+func profileName(section string) (string, bool) {
+	if section == "default" {
+		return section, true
+	}
+	name, ok := strings.CutPrefix(section, "profile ")
+	return name, ok && name != ""
+}
+
+// arnAccount returns the account ID in an ARN like
+// arn:aws:iam::123456789012:role/Name, or "" if it has none
+func arnAccount(arn string) string {
+	parts := strings.SplitN(arn, ":", 6)
+	if len(parts) < 6 || parts[0] != "arn" {
+		return ""
+	}
+	return parts[4]
+}
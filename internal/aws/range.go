@@ -0,0 +1,274 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/dustin/go-humanize"
+)
+
+// ByteRange selects part of an object, e.g. to sample the head or tail of
+// a huge CSV. The zero value selects the whole object.
+type ByteRange struct {
+	Offset  int64 // first byte to read
+	Length  int64 // bytes to read; 0 reads to the end
+	FromEnd bool  // read the last Length bytes; Offset is ignored
+}
+
+// ParseByteRange parses "first:SIZE", "last:SIZE", "START-END" or "START-"
+// into a ByteRange. Sizes take units such as 10MB or 1GiB; START and END are
+// inclusive byte offsets as in an HTTP Range header.
+func ParseByteRange(s string) (ByteRange, error) {
+	s = strings.TrimSpace(s)
+	if mode, size, ok := strings.Cut(s, ":"); ok {
+		n, err := humanize.ParseBytes(size)
+		if err != nil || n == 0 {
+			return ByteRange{}, fmt.Errorf("invalid size %q in range %q", size, s)
+		}
+		switch strings.ToLower(mode) {
+		case "first":
+			return ByteRange{Length: int64(n)}, nil
+		case "last":
+			return ByteRange{Length: int64(n), FromEnd: true}, nil
+		}
+		return ByteRange{}, fmt.Errorf("unknown range %q (use first:SIZE or last:SIZE)", s)
+	}
+
+	start, end, ok := strings.Cut(s, "-")
+	if !ok {
+		return ByteRange{}, fmt.Errorf("invalid range %q (use first:SIZE, last:SIZE or START-END)", s)
+	}
+	offset, err := strconv.ParseInt(start, 10, 64)
+	if err != nil || offset < 0 {
+		return ByteRange{}, fmt.Errorf("invalid start offset in range %q", s)
+	}
+	if end == "" {
+		return ByteRange{Offset: offset}, nil
+	}
+	last, err := strconv.ParseInt(end, 10, 64)
+	if err != nil || last < offset {
+		return ByteRange{}, fmt.Errorf("invalid end offset in range %q", s)
+	}
+	return ByteRange{Offset: offset, Length: last - offset + 1}, nil
+}
+
+// IsZero returns true if the range selects the whole object
+func (r ByteRange) IsZero() bool {
+	return r == ByteRange{}
+}
+
+// Size returns how many bytes the range covers in an object of size bytes
+func (r ByteRange) Size(size int64) int64 {
+	if r.FromEnd {
+		return min(r.Length, size)
+	}
+	n := max(size-r.Offset, 0)
+	if r.Length > 0 {
+		n = min(n, r.Length)
+	}
+	return n
+}
+
+// String describes the range, e.g. "first 10 MB"
+func (r ByteRange) String() string {
+	switch {
+	case r.IsZero():
+		return "whole object"
+	case r.FromEnd:
+		return "last " + humanize.Bytes(uint64(r.Length))
+	case r.Offset == 0:
+		return "first " + humanize.Bytes(uint64(r.Length))
+	case r.Length == 0:
+		return fmt.Sprintf("bytes %d-", r.Offset)
+	}
+	return fmt.Sprintf("bytes %d-%d", r.Offset, r.Offset+r.Length-1)
+}
+
+// header returns the HTTP Range header value, or "" for the whole object
+func (r ByteRange) header() string {
+	switch {
+	case r.IsZero():
+		return ""
+	case r.FromEnd:
+		return fmt.Sprintf("bytes=-%d", r.Length)
+	case r.Length == 0:
+		return fmt.Sprintf("bytes=%d-", r.Offset)
+	}
+	return fmt.Sprintf("bytes=%d-%d", r.Offset, r.Offset+r.Length-1)
+}
+
+// GetObjectRange retrieves part of an object's content, or all of it for
+// the zero ByteRange. It also returns how many bytes the body holds.
+func (c *Client) GetObjectRange(ctx context.Context, bucket, key string, r ByteRange) (io.ReadCloser, int64, error) {
+	output, err := c.getObjectRange(ctx, bucket, key, r)
+	if err != nil {
+		return nil, 0, err
+	}
+	return output.Body, aws.ToInt64(output.ContentLength), nil
+}
+
+// ReadObjectRange reads part of an object, at most max bytes of it, into
+// memory, e.g. for a preview. It also returns the object's Content-Type.
+func (c *Client) ReadObjectRange(ctx context.Context, bucket, key string, r ByteRange, max int64) ([]byte, string, error) {
+	output, err := c.getObjectRange(ctx, bucket, key, r)
+	if err != nil {
+		return nil, "", err
+	}
+	defer output.Body.Close()
+	data, err := io.ReadAll(io.LimitReader(output.Body, max))
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to read object: %w", err)
+	}
+	return data, aws.ToString(output.ContentType), nil
+}
+
+// getObjectRange sends a GetObject request for r
+func (c *Client) getObjectRange(ctx context.Context, bucket, key string, r ByteRange) (*s3.GetObjectOutput, error) {
+	input := &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	}
+	if h := r.header(); h != "" {
+		input.Range = aws.String(h)
+	}
+	output, err := c.S3.GetObject(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object range: %w", err)
+	}
+	return output, nil
+}
+
+// DownloadFileRange downloads part of an object to localPath, which holds
+// just those bytes afterwards
+func (c *Client) DownloadFileRange(ctx context.Context, bucket, key, localPath string, r ByteRange, onProgress func(DownloadProgress)) error {
+	if err := os.MkdirAll(filepath.Dir(localPath), 0750); err != nil {
+		return fmt.Errorf("failed to create directory: %w", err)
+	}
+
+	body, length, err := c.GetObjectRange(ctx, bucket, key, r)
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	file, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("failed to create local file: %w", err)
+	}
+	defer file.Close()
+
+	pw := &ProgressWriter{
+		writer:     file,
+		total:      length,
+		key:        key,
+		onProgress: onProgress,
+	}
+	if _, err := io.Copy(io.NewOffsetWriter(pw, 0), body); err != nil {
+		os.Remove(localPath) // Clean up on failure
+		return fmt.Errorf("failed to download range: %w", err)
+	}
+	return nil
+}
+
+// minRangeRead is the least a RangeReader fetches at a time, so that
+// readers making many small reads, like file format parsers, don't send a
+// request for each
+const minRangeRead = 256 << 10
+
+// ErrRangeBudget is returned by a RangeReader asked for more than its
+// budget allows
+var ErrRangeBudget = errors.New("read more of the object than allowed")
+
+// RangeReader reads parts of an object on demand with ranged GETs, e.g. to
+// parse a Parquet footer without downloading the whole file
+type RangeReader struct {
+	ctx    context.Context
+	client *Client
+	bucket string
+	key    string
+	size   int64
+	budget int64 // most bytes to fetch; 0 for no limit
+
+	mu       sync.Mutex
+	spans    []rangeSpan // fetched so far
+	fetched  int64
+	requests int
+}
+
+// rangeSpan is a fetched part of an object
+type rangeSpan struct {
+	offset int64
+	data   []byte
+}
+
+// NewRangeReader creates a reader over a size-byte object that fetches at
+// most budget bytes of it in all, or any amount for a budget of 0
+func (c *Client) NewRangeReader(ctx context.Context, bucket, key string, size, budget int64) *RangeReader {
+	return &RangeReader{ctx: ctx, client: c, bucket: bucket, key: key, size: size, budget: budget}
+}
+
+// Size returns the object's size
+func (r *RangeReader) Size() int64 {
+	return r.size
+}
+
+// Fetched returns how many bytes were fetched in how many requests
+func (r *RangeReader) Fetched() (int64, int) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.fetched, r.requests
+}
+
+// ReadAt implements io.ReaderAt, answering from parts already fetched
+// where it can
+func (r *RangeReader) ReadAt(p []byte, off int64) (int, error) {
+	if off < 0 {
+		return 0, fmt.Errorf("negative offset %d", off)
+	}
+	if off >= r.size {
+		return 0, io.EOF
+	}
+	want := min(int64(len(p)), r.size-off)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, s := range r.spans {
+		if off >= s.offset && off+want <= s.offset+int64(len(s.data)) {
+			n := copy(p, s.data[off-s.offset:])
+			if int64(n) < int64(len(p)) {
+				return n, io.EOF
+			}
+			return n, nil
+		}
+	}
+
+	length := min(max(want, minRangeRead), r.size-off)
+	if r.budget > 0 && r.fetched+length > r.budget {
+		length = want
+		if r.fetched+length > r.budget {
+			return 0, ErrRangeBudget
+		}
+	}
+	data, _, err := r.client.ReadObjectRange(r.ctx, r.bucket, r.key, ByteRange{Offset: off, Length: length}, length)
+	if err != nil {
+		return 0, err
+	}
+	r.requests++
+	r.fetched += int64(len(data))
+	r.spans = append(r.spans, rangeSpan{offset: off, data: data})
+
+	n := copy(p, data)
+	if n < len(p) {
+		return n, io.EOF
+	}
+	return n, nil
+}
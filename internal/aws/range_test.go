@@ -0,0 +1,172 @@
+package aws
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// rangeServer serves data for every key, honoring Range headers, and
+// counts the requests
+func rangeServer(t *testing.T, data []byte) (*Client, *atomic.Int32) {
+	var requests atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Header().Set("ETag", `"abc"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader(data))
+	}))
+	t.Cleanup(server.Close)
+
+	client := s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: aws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  aws.AnonymousCredentials{},
+	})
+	return &Client{S3: client, Region: "us-east-1"}, &requests
+}
+
+func testData(n int) []byte {
+	data := make([]byte, n)
+	for i := range data {
+		data[i] = byte(i % 251)
+	}
+	return data
+}
+
+func TestRangeReaderReusesSpans(t *testing.T) {
+	data := testData(1 << 20)
+	client, requests := rangeServer(t, data)
+	r := client.NewRangeReader(context.Background(), "bucket", "key", int64(len(data)), 0)
+
+	reads := []struct {
+		off, n       int64
+		wantRequests int32
+	}{
+		{0, 10, 1},                 // fetches minRangeRead from the start
+		{100, 1000, 1},             // within the first span
+		{minRangeRead - 10, 10, 1}, // its last bytes
+		{minRangeRead - 5, 10, 2},  // straddles its end, so fetches again
+		{600 << 10, 10, 3},
+		{600<<10 + 1, 20, 3},
+	}
+	for _, read := range reads {
+		p := make([]byte, read.n)
+		n, err := r.ReadAt(p, read.off)
+		if err != nil || int64(n) != read.n {
+			t.Fatalf("ReadAt(%d bytes at %d) = %d, %v", read.n, read.off, n, err)
+		}
+		if !bytes.Equal(p, data[read.off:read.off+read.n]) {
+			t.Errorf("ReadAt(%d bytes at %d) returned the wrong bytes", read.n, read.off)
+		}
+		if got := requests.Load(); got != read.wantRequests {
+			t.Errorf("after reading %d bytes at %d: %d requests, want %d", read.n, read.off, got, read.wantRequests)
+		}
+	}
+	if _, n := r.Fetched(); n != 3 {
+		t.Errorf("Fetched() requests = %d, want 3", n)
+	}
+}
+
+func TestRangeReaderEnd(t *testing.T) {
+	data := testData(1000)
+	client, _ := rangeServer(t, data)
+	r := client.NewRangeReader(context.Background(), "bucket", "key", int64(len(data)), 0)
+
+	p := make([]byte, 10)
+	n, err := r.ReadAt(p, 995)
+	if n != 5 || err != io.EOF || !bytes.Equal(p[:n], data[995:]) {
+		t.Errorf("ReadAt() past the end = %d, %v", n, err)
+	}
+	if _, err := r.ReadAt(p, 1000); err != io.EOF {
+		t.Errorf("ReadAt() at the end = %v, want EOF", err)
+	}
+	if _, err := r.ReadAt(p, -1); err == nil {
+		t.Error("ReadAt() at a negative offset succeeded")
+	}
+}
+
+func TestRangeReaderBudget(t *testing.T) {
+	data := testData(1 << 20)
+	client, _ := rangeServer(t, data)
+	r := client.NewRangeReader(context.Background(), "bucket", "key", int64(len(data)), 300<<10)
+
+	// The first read fetches minRangeRead; the next only what it asks for,
+	// as another minRangeRead would go over the budget
+	for _, off := range []int64{0, 600 << 10} {
+		if _, err := r.ReadAt(make([]byte, 10), off); err != nil {
+			t.Fatalf("ReadAt() at %d: %v", off, err)
+		}
+	}
+	if fetched, _ := r.Fetched(); fetched != minRangeRead+10 {
+		t.Errorf("Fetched() = %d bytes, want %d", fetched, minRangeRead+10)
+	}
+
+	if _, err := r.ReadAt(make([]byte, 100<<10), 800<<10); !errors.Is(err, ErrRangeBudget) {
+		t.Errorf("ReadAt() over the budget = %v, want ErrRangeBudget", err)
+	}
+	// What was fetched can still be read
+	if _, err := r.ReadAt(make([]byte, 10), 50); err != nil {
+		t.Errorf("ReadAt() of a fetched span after running out of budget: %v", err)
+	}
+}
+
+func TestParseByteRange(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    ByteRange
+		wantErr bool
+	}{
+		{"first:10MB", ByteRange{Length: 10_000_000}, false},
+		{"last:1KiB", ByteRange{Length: 1024, FromEnd: true}, false},
+		{" LAST:512 ", ByteRange{Length: 512, FromEnd: true}, false},
+		{"100-199", ByteRange{Offset: 100, Length: 100}, false},
+		{"0-0", ByteRange{Length: 1}, false},
+		{"4096-", ByteRange{Offset: 4096}, false},
+		{"middle:10MB", ByteRange{}, true},
+		{"first:0", ByteRange{}, true},
+		{"first:lots", ByteRange{}, true},
+		{"200-100", ByteRange{}, true},
+		{"-100", ByteRange{}, true},
+		{"100", ByteRange{}, true},
+		{"", ByteRange{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseByteRange(tt.in)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseByteRange(%q) = %+v, %v, want %+v", tt.in, got, err, tt.want)
+		}
+	}
+}
+
+func TestByteRangeSize(t *testing.T) {
+	tests := []struct {
+		r    ByteRange
+		size int64
+		want int64
+	}{
+		{ByteRange{}, 1000, 1000},
+		{ByteRange{Length: 100}, 1000, 100},
+		{ByteRange{Length: 5000}, 1000, 1000},
+		{ByteRange{Length: 100, FromEnd: true}, 1000, 100},
+		{ByteRange{Length: 5000, FromEnd: true}, 1000, 1000},
+		{ByteRange{Offset: 900}, 1000, 100},
+		{ByteRange{Offset: 900, Length: 50}, 1000, 50},
+		{ByteRange{Offset: 950, Length: 100}, 1000, 50},
+		{ByteRange{Offset: 2000}, 1000, 0},
+	}
+	for _, tt := range tests {
+		if got := tt.r.Size(tt.size); got != tt.want {
+			t.Errorf("%+v.Size(%d) = %d, want %d", tt.r, tt.size, got, tt.want)
+		}
+	}
+}
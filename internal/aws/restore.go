@@ -0,0 +1,143 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/aws/smithy-go"
+)
+
+// Restore tiers, fastest and most expensive first. DEEP_ARCHIVE objects
+// can't be restored with the expedited tier.
+const (
+	RestoreExpedited = string(types.TierExpedited) // 1-5 minutes
+	RestoreStandard  = string(types.TierStandard)  // 3-5 hours; 12 for DEEP_ARCHIVE
+	RestoreBulk      = string(types.TierBulk)      // 5-12 hours; 48 for DEEP_ARCHIVE
+)
+
+// ErrRestoreInProgress is returned by RestoreObject when the object is
+// already being restored
+var ErrRestoreInProgress = errors.New("restore already in progress")
+
+// MaxRestoreDays is the longest a restored copy can be kept
+const MaxRestoreDays = 30000
+
+// Archived reports whether the object is in an archive storage class and
+// has to be restored before it can be downloaded
+func (o S3Object) Archived() bool {
+	return archived(types.StorageClass(o.StorageClass))
+}
+
+// RestoreObject asks S3 to make a temporary copy of an archived object
+// available for days, retrieved with tier
+func (c *Client) RestoreObject(ctx context.Context, bucket, key, tier string, days int32) error {
+	_, err := c.S3.RestoreObject(ctx, &s3.RestoreObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		RestoreRequest: &types.RestoreRequest{
+			Days:                 aws.Int32(days),
+			GlacierJobParameters: &types.GlacierJobParameters{Tier: types.Tier(tier)},
+		},
+	})
+	var apiErr smithy.APIError
+	if errors.As(err, &apiErr) && apiErr.ErrorCode() == "RestoreAlreadyInProgress" {
+		return ErrRestoreInProgress
+	}
+	if err != nil {
+		return fmt.Errorf("failed to restore object: %w", err)
+	}
+	return nil
+}
+
+// RestoreTime returns how long a restore with tier usually takes for an
+// object in class
+func RestoreTime(tier, class string) time.Duration {
+	deep := class == string(types.StorageClassDeepArchive)
+	switch {
+	case tier == RestoreExpedited:
+		return 5 * time.Minute
+	case tier == RestoreBulk && deep:
+		return 48 * time.Hour
+	case tier == RestoreBulk:
+		return 12 * time.Hour
+	case deep:
+		return 12 * time.Hour
+	default:
+		return 5 * time.Hour
+	}
+}
+
+// RestoreStatus is where an object's restore stands
+type RestoreStatus struct {
+	Archived  bool // the object is in GLACIER or DEEP_ARCHIVE
+	Restoring bool
+	Until     time.Time // when the restored copy expires; zero until it's ready
+}
+
+// Ready reports whether the object can be downloaded
+func (s RestoreStatus) Ready() bool {
+	return !s.Archived || (!s.Restoring && !s.Until.IsZero())
+}
+
+// GetRestoreStatus reads an object's restore status from its metadata, or
+// returns nil if the object doesn't exist
+func (c *Client) GetRestoreStatus(ctx context.Context, bucket, key string) (*RestoreStatus, error) {
+	head, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	status := parseRestore(aws.ToString(head.Restore))
+	status.Archived = archived(head.StorageClass)
+	return &status, nil
+}
+
+// parseRestore parses an x-amz-restore header, e.g.
+// ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"
+func parseRestore(header string) RestoreStatus {
+	var status RestoreStatus
+	status.Restoring = strings.Contains(header, `ongoing-request="true"`)
+	if _, rest, ok := strings.Cut(header, `expiry-date="`); ok {
+		if date, _, ok := strings.Cut(rest, `"`); ok {
+			status.Until, _ = time.Parse(time.RFC1123, date)
+		}
+	}
+	return status
+}
+
+// restoreState reads a listing's restore status into obj
+func restoreState(obj *S3Object, status *types.RestoreStatus) {
+	if status == nil {
+		return
+	}
+	obj.Restoring = aws.ToBool(status.IsRestoreInProgress)
+	obj.RestoredUntil = aws.ToTime(status.RestoreExpiryDate)
+}
+
+// RestoreLabel describes where an archived object's restore stands, e.g.
+// "restoring" or "restored until 2024-03-05 14:00", or "archived" if it
+// hasn't been restored. It returns "" for objects that aren't archived.
+func (o S3Object) RestoreLabel() string {
+	switch {
+	case !o.Archived():
+		return ""
+	case o.Restoring:
+		return "restoring"
+	case o.RestoredUntil.After(time.Now()):
+		return "restored until " + o.RestoredUntil.Local().Format("2006-01-02 15:04")
+	default:
+		return "archived"
+	}
+}
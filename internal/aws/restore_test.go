@@ -0,0 +1,26 @@
+package aws
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseRestore(t *testing.T) {
+	tests := []struct {
+		header string
+		want   RestoreStatus
+	}{
+		{``, RestoreStatus{}},
+		{`ongoing-request="true"`, RestoreStatus{Restoring: true}},
+		{`ongoing-request="false", expiry-date="Fri, 21 Dec 2012 00:00:00 GMT"`,
+			RestoreStatus{Until: time.Date(2012, 12, 21, 0, 0, 0, 0, time.UTC)}},
+		{`ongoing-request="false", expiry-date="someday"`, RestoreStatus{}},
+		{`ongoing-request="false", expiry-date="Fri, 21 Dec 2012`, RestoreStatus{}},
+	}
+	for _, tt := range tests {
+		got := parseRestore(tt.header)
+		if got.Restoring != tt.want.Restoring || !got.Until.Equal(tt.want.Until) {
+			t.Errorf("parseRestore(%q) = %+v, want %+v", tt.header, got, tt.want)
+		}
+	}
+}
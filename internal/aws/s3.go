@@ -1,12 +1,19 @@
 package aws
 
 import (
+	"bytes"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"mime"
+	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/aws/aws-sdk-go-v2/aws"
@@ -24,25 +31,38 @@ type Bucket struct {
 
 // S3Object represents an object or prefix in S3
 type S3Object struct {
-	Key          string
-	Size         int64
-	LastModified time.Time
-	ETag         string
-	IsPrefix     bool // true if this is a "folder" (common prefix)
+	Key           string
+	Size          int64
+	LastModified  time.Time
+	ETag          string
+	StorageClass  string    // as listed; "" for folders
+	Restoring     bool      // an archived object is being restored
+	RestoredUntil time.Time // when the restored copy of an archived object expires
+	IsPrefix      bool      // true if this is a "folder" (common prefix)
+	Owner         string    // owner's display name, where S3 still reports one
+	OwnerID       string    // owner's canonical user ID; "" if the listing had no owners
+}
+
+// OwnerLabel returns the owner's display name, or an abbreviated canonical
+// ID when there is none, or "" if the owner isn't known
+func (o S3Object) OwnerLabel() string {
+	if o.Owner != "" {
+		return o.Owner
+	}
+	if len(o.OwnerID) > 12 {
+		return o.OwnerID[:12] + "…"
+	}
+	return o.OwnerID
 }
 
 // DisplayName returns the object's display name (last part of key)
 func (o S3Object) DisplayName() string {
 	key := strings.TrimSuffix(o.Key, "/")
-	parts := strings.Split(key, "/")
-	if len(parts) > 0 {
-		name := parts[len(parts)-1]
-		if o.IsPrefix {
-			return name + "/"
-		}
-		return name
+	name := key[strings.LastIndexByte(key, '/')+1:]
+	if o.IsPrefix {
+		return name + "/"
 	}
-	return o.Key
+	return name
 }
 
 // ListBuckets returns all S3 buckets accessible to the current credentials
@@ -57,6 +77,7 @@ func (c *Client) ListBuckets(ctx context.Context) ([]Bucket, error) {
 		buckets[i] = Bucket{
 			Name:         aws.ToString(b.Name),
 			CreationDate: aws.ToTime(b.CreationDate),
+			Region:       aws.ToString(b.BucketRegion),
 		}
 	}
 
@@ -73,8 +94,13 @@ func (c *Client) GetBucketRegion(ctx context.Context, bucket string) (string, er
 	}
 
 	region := string(output.LocationConstraint)
-	if region == "" {
-		region = "us-east-1" // Default region for buckets without explicit location
+	switch region {
+	case "":
+		// Buckets without a location constraint are in the partition's
+		// first region, e.g. us-east-1, or cn-north-1 in China
+		region = c.Partition().DefaultRegion
+	case "EU":
+		region = "eu-west-1" // legacy constraint of old buckets
 	}
 
 	return region, nil
@@ -82,49 +108,89 @@ func (c *Client) GetBucketRegion(ctx context.Context, bucket string) (string, er
 
 // ListObjects lists objects and common prefixes at the given prefix
 func (c *Client) ListObjects(ctx context.Context, bucket, prefix string) ([]S3Object, error) {
-	var objects []S3Object
-
 	// Use delimiter to get "folder-like" behavior
-	delimiter := "/"
-
-	paginator := s3.NewListObjectsV2Paginator(c.S3, &s3.ListObjectsV2Input{
-		Bucket:    aws.String(bucket),
-		Prefix:    aws.String(prefix),
-		Delimiter: aws.String(delimiter),
-	})
+	return c.ListObjectsDelimited(ctx, bucket, prefix, "/")
+}
 
-	for paginator.HasMorePages() {
-		output, err := paginator.NextPage(ctx)
+// ListObjectsDelimited lists prefix like ListObjects, grouping keys into
+// common prefixes on delimiter instead of "/". An empty delimiter lists
+// every key under prefix with no grouping.
+func (c *Client) ListObjectsDelimited(ctx context.Context, bucket, prefix, delimiter string) ([]S3Object, error) {
+	var objects []S3Object
+	token := ""
+	for {
+		page, next, err := c.ListObjectsPage(ctx, bucket, prefix, delimiter, token)
 		if err != nil {
-			return nil, fmt.Errorf("failed to list objects: %w", err)
+			return nil, err
 		}
-
-		// Add common prefixes (folders)
-		for _, cp := range output.CommonPrefixes {
-			objects = append(objects, S3Object{
-				Key:      aws.ToString(cp.Prefix),
-				IsPrefix: true,
-			})
+		objects = append(objects, page...)
+		if next == "" {
+			return objects, nil
 		}
+		token = next
+	}
+}
 
-		// Add objects (files)
-		for _, obj := range output.Contents {
-			key := aws.ToString(obj.Key)
-			// Skip the prefix itself if it appears as an object
-			if key == prefix {
-				continue
-			}
-			objects = append(objects, S3Object{
-				Key:          key,
-				Size:         aws.ToInt64(obj.Size),
-				LastModified: aws.ToTime(obj.LastModified),
-				ETag:         strings.Trim(aws.ToString(obj.ETag), "\""),
-				IsPrefix:     false,
-			})
+// ListObjectsPage lists one page (up to 1000 keys) of prefix, starting at
+// token, which is "" for the first page. It returns the token for the next
+// page, or "" after the last one.
+func (c *Client) ListObjectsPage(ctx context.Context, bucket, prefix, delimiter, token string) ([]S3Object, string, error) {
+	input := &s3.ListObjectsV2Input{
+		Bucket: aws.String(bucket),
+		Prefix: aws.String(prefix),
+	}
+	if delimiter != "" {
+		input.Delimiter = aws.String(delimiter)
+	}
+	if token != "" {
+		input.ContinuationToken = aws.String(token)
+	}
+	// Owners let shared buckets be narrowed to one uploader's files
+	input.FetchOwner = aws.Bool(true)
+	// Archived objects show whether they've been restored
+	input.OptionalObjectAttributes = []types.OptionalObjectAttributes{types.OptionalObjectAttributesRestoreStatus}
+	output, err := c.S3.ListObjectsV2(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to list objects: %w", err)
+	}
+
+	var objects []S3Object
+
+	// Add common prefixes (folders)
+	for _, cp := range output.CommonPrefixes {
+		objects = append(objects, S3Object{
+			Key:      aws.ToString(cp.Prefix),
+			IsPrefix: true,
+		})
+	}
+
+	// Add objects (files)
+	for _, obj := range output.Contents {
+		key := aws.ToString(obj.Key)
+		// Skip the prefix itself if it appears as an object
+		if key == prefix {
+			continue
 		}
+		o := S3Object{
+			Key:          key,
+			Size:         aws.ToInt64(obj.Size),
+			LastModified: aws.ToTime(obj.LastModified),
+			ETag:         strings.Trim(aws.ToString(obj.ETag), "\""),
+			StorageClass: string(obj.StorageClass),
+			IsPrefix:     false,
+		}
+		if obj.Owner != nil {
+			o.Owner = aws.ToString(obj.Owner.DisplayName)
+			o.OwnerID = aws.ToString(obj.Owner.ID)
+		}
+		restoreState(&o, obj.RestoreStatus)
+		objects = append(objects, o)
 	}
 
-	return objects, nil
+	if !aws.ToBool(output.IsTruncated) {
+		return objects, "", nil
+	}
+	return objects, aws.ToString(output.NextContinuationToken), nil
 }
 
 // ListAllObjects lists all objects recursively under a prefix (no delimiter)
@@ -142,6 +208,7 @@ func (c *Client) ListAllObjects(ctx context.Context, bucket, prefix string) ([]S
 			return nil, fmt.Errorf("failed to list objects: %w", err)
 		}
 
+		objects = slices.Grow(objects, len(output.Contents))
 		for _, obj := range output.Contents {
 			key := aws.ToString(obj.Key)
 			// Skip if it ends with / (folder marker)
@@ -153,6 +220,7 @@ func (c *Client) ListAllObjects(ctx context.Context, bucket, prefix string) ([]S
 				Size:         aws.ToInt64(obj.Size),
 				LastModified: aws.ToTime(obj.LastModified),
 				ETag:         strings.Trim(aws.ToString(obj.ETag), "\""),
+				StorageClass: string(obj.StorageClass),
 				IsPrefix:     false,
 			})
 		}
@@ -180,11 +248,25 @@ func (c *Client) GetObjectMetadata(ctx context.Context, bucket, key string) (*S3
 	}, nil
 }
 
+// ContentType returns an object's Content-Type
+func (c *Client) ContentType(ctx context.Context, bucket, key string) (string, error) {
+	output, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get object metadata: %w", err)
+	}
+	return aws.ToString(output.ContentType), nil
+}
+
 // DownloadProgress tracks download progress
 type DownloadProgress struct {
 	BytesDownloaded int64
 	TotalBytes      int64
 	Key             string
+	PartsTotal      int // parts in a multipart upload, 0 otherwise
+	PartsDone       int
 }
 
 // ProgressWriter wraps an io.WriterAt to track download progress
@@ -211,8 +293,66 @@ func (pw *ProgressWriter) WriteAt(p []byte, off int64) (int, error) {
 	return n, err
 }
 
-// DownloadFile downloads a single file from S3 to the local filesystem
-func (c *Client) DownloadFile(ctx context.Context, bucket, key, localPath string, onProgress func(DownloadProgress)) error {
+// Ranged download defaults
+const (
+	DefaultDownloadPartSize    = 10 * 1024 * 1024 // 10MB parts
+	DefaultDownloadConcurrency = 5
+)
+
+// DownloadOptions tunes how a file is fetched in ranged parts
+type DownloadOptions struct {
+	PartSize    int64 // bytes per ranged GET; 0 uses DefaultDownloadPartSize
+	Concurrency int   // parts fetched in parallel; 0 uses DefaultDownloadConcurrency
+}
+
+// DownloadPreset is a named DownloadOptions for a kind of connection
+type DownloadPreset struct {
+	Name string
+	DownloadOptions
+}
+
+// DownloadPresets are offered when starting a download: small parts a few
+// at a time for a flaky or slow link, big parts many at a time for the
+// same region
+var DownloadPresets = []DownloadPreset{
+	{"conservative", DownloadOptions{PartSize: 5 * 1024 * 1024, Concurrency: 2}},
+	{"balanced", DownloadOptions{PartSize: DefaultDownloadPartSize, Concurrency: DefaultDownloadConcurrency}},
+	{"aggressive", DownloadOptions{PartSize: 64 * 1024 * 1024, Concurrency: 16}},
+}
+
+// ParseDownloadPreset returns the options of a preset by name; "" is balanced
+func ParseDownloadPreset(name string) (DownloadOptions, error) {
+	if name == "" {
+		name = "balanced"
+	}
+	for _, p := range DownloadPresets {
+		if p.Name == name {
+			return p.DownloadOptions, nil
+		}
+	}
+	return DownloadOptions{}, fmt.Errorf("unknown download preset %q (use conservative, balanced or aggressive)", name)
+}
+
+// WithDefaults fills in the defaults for unset options
+func (o DownloadOptions) WithDefaults() DownloadOptions {
+	if o.PartSize <= 0 {
+		o.PartSize = DefaultDownloadPartSize
+	}
+	if o.Concurrency <= 0 {
+		o.Concurrency = DefaultDownloadConcurrency
+	}
+	return o
+}
+
+// String describes the options, e.g. "10 MB parts, 5 at a time"
+func (o DownloadOptions) String() string {
+	o = o.WithDefaults()
+	return fmt.Sprintf("%d MB parts, %d at a time", o.PartSize/(1024*1024), o.Concurrency)
+}
+
+// DownloadFile downloads a single file from S3 to the local filesystem,
+// fetching it in ranged parts as opts say
+func (c *Client) DownloadFile(ctx context.Context, bucket, key, localPath string, opts DownloadOptions, onProgress func(DownloadProgress)) error {
 	// Ensure directory exists with secure permissions
 	dir := filepath.Dir(localPath)
 	if err := os.MkdirAll(dir, 0750); err != nil {
@@ -233,9 +373,10 @@ func (c *Client) DownloadFile(ctx context.Context, bucket, key, localPath string
 	defer file.Close()
 
 	// Create download manager
+	opts = opts.WithDefaults()
 	downloader := manager.NewDownloader(c.S3, func(d *manager.Downloader) {
-		d.PartSize = 10 * 1024 * 1024 // 10MB parts
-		d.Concurrency = 5
+		d.PartSize = opts.PartSize
+		d.Concurrency = opts.Concurrency
 	})
 
 	// Wrap writer for progress tracking
@@ -258,6 +399,371 @@ func (c *Client) DownloadFile(ctx context.Context, bucket, key, localPath string
 	return nil
 }
 
+// Multipart upload defaults
+const (
+	DefaultUploadPartSize    = 10 * 1024 * 1024 // 10MB parts
+	DefaultUploadConcurrency = 5
+	MinUploadPartSize        = manager.MinUploadPartSize
+)
+
+// UploadOptions tunes multipart uploads
+type UploadOptions struct {
+	PartSize    int64 // bytes per part; 0 uses DefaultUploadPartSize
+	Concurrency int   // parts uploaded in parallel; 0 uses DefaultUploadConcurrency
+}
+
+// ObjectAttributes are set on the objects an upload creates
+type ObjectAttributes struct {
+	StorageClass string            // "" uses the bucket default
+	ContentType  string            // "" detects the type from the file
+	Metadata     map[string]string // user-defined x-amz-meta-* headers
+	Tags         map[string]string // object tags, e.g. for cost allocation
+	Encryption   Encryption        // zero value uses the bucket default
+}
+
+// Encryption selects server-side encryption for uploaded objects
+type Encryption struct {
+	Algorithm types.ServerSideEncryption // "" uses the bucket default
+	KMSKeyID  string                     // key ID or ARN for SSE-KMS; "" uses the aws/s3 key
+}
+
+// ParseEncryption parses "default", "sse-s3", "sse-kms" or
+// "sse-kms:<key-arn>" into an Encryption
+func ParseEncryption(s string) (Encryption, error) {
+	mode, keyID, hasKey := strings.Cut(strings.TrimSpace(s), ":")
+	switch strings.ToLower(mode) {
+	case "", "default":
+		if !hasKey {
+			return Encryption{}, nil
+		}
+	case "sse-s3":
+		if !hasKey {
+			return Encryption{Algorithm: types.ServerSideEncryptionAes256}, nil
+		}
+	case "sse-kms":
+		if hasKey && (keyID == "" || strings.ContainsAny(keyID, " \t,")) {
+			return Encryption{}, fmt.Errorf("invalid KMS key %q", keyID)
+		}
+		return Encryption{Algorithm: types.ServerSideEncryptionAwsKms, KMSKeyID: keyID}, nil
+	}
+	return Encryption{}, fmt.Errorf("unknown encryption %q (use default, sse-s3 or sse-kms[:key-arn])", s)
+}
+
+// String returns the encryption in the form ParseEncryption accepts
+func (e Encryption) String() string {
+	switch e.Algorithm {
+	case types.ServerSideEncryptionAes256:
+		return "sse-s3"
+	case types.ServerSideEncryptionAwsKms:
+		if e.KMSKeyID != "" {
+			return "sse-kms:" + e.KMSKeyID
+		}
+		return "sse-kms"
+	default:
+		return "default"
+	}
+}
+
+// apply sets the encryption headers on a PutObject request
+func (e Encryption) apply(input *s3.PutObjectInput) {
+	if e.Algorithm == "" {
+		return
+	}
+	input.ServerSideEncryption = e.Algorithm
+	if e.KMSKeyID != "" {
+		input.SSEKMSKeyId = aws.String(e.KMSKeyID)
+	}
+}
+
+// DetectContentType guesses a file's MIME type from its extension, falling
+// back to sniffing the first 512 bytes
+func DetectContentType(localPath string) string {
+	if t := mime.TypeByExtension(filepath.Ext(localPath)); t != "" {
+		return t
+	}
+
+	file, err := os.Open(localPath)
+	if err != nil {
+		return "application/octet-stream"
+	}
+	defer file.Close()
+
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(file, buf)
+	return http.DetectContentType(buf[:n])
+}
+
+// UploadStorageClasses are the storage classes offered when uploading
+var UploadStorageClasses = []string{
+	string(types.StorageClassStandard),
+	string(types.StorageClassIntelligentTiering),
+	string(types.StorageClassStandardIa),
+	string(types.StorageClassOnezoneIa),
+	string(types.StorageClassGlacierIr),
+	string(types.StorageClassGlacier),
+	string(types.StorageClassDeepArchive),
+}
+
+// partSize returns the part size used for a file of the given size. Like the
+// SDK uploader, it grows the part size if the file would exceed the part limit.
+func (o UploadOptions) partSize(size int64) int64 {
+	partSize := o.PartSize
+	if partSize <= 0 {
+		partSize = DefaultUploadPartSize
+	}
+	if size/partSize >= int64(manager.MaxUploadParts) {
+		partSize = size/int64(manager.MaxUploadParts) + 1
+	}
+	return partSize
+}
+
+func (o UploadOptions) concurrency() int {
+	if o.Concurrency <= 0 {
+		return DefaultUploadConcurrency
+	}
+	return o.Concurrency
+}
+
+// partReader exposes a local file to the SDK uploader and reports progress
+// per part as each part's section is read for sending. Re-reads of a part
+// after a retry aren't counted twice.
+type partReader struct {
+	file       *os.File
+	size       int64
+	partSize   int64
+	key        string
+	onProgress func(DownloadProgress)
+
+	mu        sync.Mutex
+	sent      []int64 // bytes read per part (high-water mark)
+	total     int64
+	partsDone int
+}
+
+func newPartReader(file *os.File, size, partSize int64, key string, onProgress func(DownloadProgress)) *partReader {
+	parts := size / partSize
+	if size%partSize != 0 || parts == 0 {
+		parts++
+	}
+	return &partReader{
+		file:       file,
+		size:       size,
+		partSize:   partSize,
+		key:        key,
+		onProgress: onProgress,
+		sent:       make([]int64, parts),
+	}
+}
+
+func (r *partReader) Read(p []byte) (int, error) {
+	return r.file.Read(p)
+}
+
+func (r *partReader) Seek(offset int64, whence int) (int64, error) {
+	return r.file.Seek(offset, whence)
+}
+
+// ReadAt is used by the uploader to read each part's section of the file
+func (r *partReader) ReadAt(p []byte, off int64) (int, error) {
+	n, err := r.file.ReadAt(p, off)
+	if n > 0 {
+		r.record(off, int64(n))
+	}
+	return n, err
+}
+
+func (r *partReader) record(off, n int64) {
+	part := off / r.partSize
+	if part >= int64(len(r.sent)) {
+		return
+	}
+	partStart := part * r.partSize
+	partLen := r.partSize
+	if partStart+partLen > r.size {
+		partLen = r.size - partStart
+	}
+
+	r.mu.Lock()
+	end := off + n - partStart
+	if end > partLen {
+		end = partLen
+	}
+	if end > r.sent[part] {
+		r.total += end - r.sent[part]
+		r.sent[part] = end
+		if end == partLen {
+			r.partsDone++
+		}
+	}
+	progress := DownloadProgress{
+		BytesDownloaded: r.total,
+		TotalBytes:      r.size,
+		Key:             r.key,
+		PartsTotal:      len(r.sent),
+		PartsDone:       r.partsDone,
+	}
+	r.mu.Unlock()
+
+	if r.onProgress != nil {
+		r.onProgress(progress)
+	}
+}
+
+// UploadFile uploads a local file to S3, using a multipart upload for files
+// larger than one part. Progress is reported per part through the same
+// DownloadProgress type used for downloads.
+func (c *Client) UploadFile(ctx context.Context, bucket, key, localPath string, opts UploadOptions, attrs ObjectAttributes, onProgress func(DownloadProgress)) error {
+	file, err := os.Open(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to open local file: %w", err)
+	}
+	defer file.Close()
+
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %w", err)
+	}
+
+	partSize := opts.partSize(info.Size())
+	uploader := manager.NewUploader(c.S3, func(u *manager.Uploader) {
+		u.PartSize = partSize
+		u.Concurrency = opts.concurrency()
+	})
+
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   newPartReader(file, info.Size(), partSize, key, onProgress),
+	}
+	if attrs.ContentType == "" {
+		attrs.ContentType = DetectContentType(localPath)
+	}
+	attrs.apply(input)
+
+	_, err = uploader.Upload(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to upload file: %w", err)
+	}
+
+	return nil
+}
+
+// UploadStream uploads everything read from r to key, one part at a time, so
+// the total size doesn't need to be known up front. It returns the number of
+// bytes uploaded. With the default 10MB parts a stream can be up to ~100GB.
+func (c *Client) UploadStream(ctx context.Context, bucket, key string, r io.Reader, opts UploadOptions, attrs ObjectAttributes) (int64, error) {
+	n, err := c.uploadStream(ctx, bucket, key, r, 0, opts, attrs, nil)
+	if err != nil {
+		return n, fmt.Errorf("failed to upload stream: %w", err)
+	}
+	return n, nil
+}
+
+// uploadStream uploads r to key with parts sized for size bytes, or the
+// default parts for a size of 0 (unknown), reporting the bytes read from r
+// as progress
+func (c *Client) uploadStream(ctx context.Context, bucket, key string, r io.Reader, size int64, opts UploadOptions, attrs ObjectAttributes, onProgress func(DownloadProgress)) (int64, error) {
+	uploader := manager.NewUploader(c.S3, func(u *manager.Uploader) {
+		u.PartSize = opts.partSize(size)
+		u.Concurrency = opts.concurrency()
+	})
+
+	counter := &countingReader{reader: r, total: size, key: key, onProgress: onProgress}
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   counter,
+	}
+	attrs.apply(input)
+
+	_, err := uploader.Upload(ctx, input)
+	return counter.n, err
+}
+
+// countingReader counts the bytes read through it, reporting them as
+// progress toward total if onProgress is set
+type countingReader struct {
+	reader     io.Reader
+	n          int64
+	total      int64
+	key        string
+	onProgress func(DownloadProgress)
+}
+
+func (r *countingReader) Read(p []byte) (int, error) {
+	n, err := r.reader.Read(p)
+	r.n += int64(n)
+	if n > 0 && r.onProgress != nil {
+		r.onProgress(DownloadProgress{BytesDownloaded: r.n, TotalBytes: r.total, Key: r.key})
+	}
+	return n, err
+}
+
+// apply sets the attributes on a PutObject request
+func (a ObjectAttributes) apply(input *s3.PutObjectInput) {
+	if a.StorageClass != "" {
+		input.StorageClass = types.StorageClass(a.StorageClass)
+	}
+	if a.ContentType != "" {
+		input.ContentType = aws.String(a.ContentType)
+	}
+	if len(a.Metadata) > 0 {
+		input.Metadata = a.Metadata
+	}
+	if len(a.Tags) > 0 {
+		tags := url.Values{}
+		for k, v := range a.Tags {
+			tags.Set(k, v)
+		}
+		input.Tagging = aws.String(tags.Encode())
+	}
+	a.Encryption.apply(input)
+}
+
+// ParseS3URI splits "s3://bucket/key", or an S3 ARN such as
+// arn:aws-cn:s3:::bucket/key, into its bucket and key
+func ParseS3URI(uri string) (bucket, key string, err error) {
+	if strings.HasPrefix(uri, "arn:") {
+		_, bucket, key, err = ParseBucketARN(uri)
+		return bucket, key, err
+	}
+	rest, ok := strings.CutPrefix(uri, "s3://")
+	if !ok {
+		return "", "", fmt.Errorf("not an s3:// URI: %q", uri)
+	}
+	bucket, key, _ = strings.Cut(rest, "/")
+	if bucket == "" {
+		return "", "", fmt.Errorf("missing bucket in %q", uri)
+	}
+	return bucket, key, nil
+}
+
+// DownloadRange downloads length bytes of an object starting at offset,
+// writing them to w at the same offset
+func (c *Client) DownloadRange(ctx context.Context, bucket, key string, w io.WriterAt, offset, length int64, onProgress func(DownloadProgress)) error {
+	output, err := c.S3.GetObject(ctx, &s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Range:  aws.String(fmt.Sprintf("bytes=%d-%d", offset, offset+length-1)),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object range: %w", err)
+	}
+	defer output.Body.Close()
+
+	pw := &ProgressWriter{
+		writer:     w,
+		total:      length,
+		key:        key,
+		onProgress: onProgress,
+	}
+
+	if _, err := io.Copy(io.NewOffsetWriter(pw, offset), output.Body); err != nil {
+		return fmt.Errorf("failed to download range: %w", err)
+	}
+	return nil
+}
+
 // GetObject retrieves an object's content
 func (c *Client) GetObject(ctx context.Context, bucket, key string) (io.ReadCloser, error) {
 	output, err := c.S3.GetObject(ctx, &s3.GetObjectInput{
@@ -271,6 +777,96 @@ func (c *Client) GetObject(ctx context.Context, bucket, key string) (io.ReadClos
 	return output.Body, nil
 }
 
+// PutObject uploads a small in-memory object
+func (c *Client) PutObject(ctx context.Context, bucket, key string, body []byte, contentType string, enc Encryption) error {
+	input := &s3.PutObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+		Body:   bytes.NewReader(body),
+	}
+	if contentType != "" {
+		input.ContentType = aws.String(contentType)
+	}
+	enc.apply(input)
+
+	if _, err := c.S3.PutObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to put object: %w", err)
+	}
+	return nil
+}
+
+// MaxCopySize is the largest object a single CopyObject request can copy
+const MaxCopySize = 5 * 1024 * 1024 * 1024
+
+// CopyObject copies an object to another key in the same bucket, keeping its
+// content type, metadata, tags, storage class and KMS encryption
+func (c *Client) CopyObject(ctx context.Context, bucket, srcKey, dstKey string) error {
+	return c.CopyObjectTo(ctx, bucket, srcKey, bucket, dstKey, UploadOptions{}, nil)
+}
+
+// copySource builds the URL-encoded CopySource header value
+func copySource(bucket, key string) string {
+	parts := strings.Split(key, "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return bucket + "/" + strings.Join(parts, "/")
+}
+
+// DeleteObject deletes an object
+func (c *Client) DeleteObject(ctx context.Context, bucket, key string) error {
+	_, err := c.S3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete object: %w", err)
+	}
+	return nil
+}
+
+// ObjectExists returns true if key exists in bucket
+func (c *Client) ObjectExists(ctx context.Context, bucket, key string) (bool, error) {
+	obj, err := c.LookupObject(ctx, bucket, key)
+	return obj != nil, err
+}
+
+// LookupObject returns the metadata of key, or nil if it doesn't exist
+func (c *Client) LookupObject(ctx context.Context, bucket, key string) (*S3Object, error) {
+	obj, err := c.GetObjectMetadata(ctx, bucket, key)
+	var notFound *types.NotFound
+	if errors.As(err, &notFound) {
+		return nil, nil
+	}
+	return obj, err
+}
+
+// RenameObject moves an object to a new key in the same bucket by copying
+// it and deleting the original. S3 has no rename, so this isn't atomic: if
+// the original can't be deleted the copy is removed again, and only if that
+// fails too are both keys left behind, which the error says.
+func (c *Client) RenameObject(ctx context.Context, bucket, oldKey, newKey string) error {
+	exists, err := c.ObjectExists(ctx, bucket, newKey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%s already exists", newKey)
+	}
+
+	if err := c.CopyObject(ctx, bucket, oldKey, newKey); err != nil {
+		return err
+	}
+	if err := c.DeleteObject(ctx, bucket, oldKey); err != nil {
+		// Roll back even if ctx was cancelled between the two steps
+		if rbErr := c.DeleteObject(context.WithoutCancel(ctx), bucket, newKey); rbErr != nil {
+			return fmt.Errorf("copied to %s but the original remains: %w", newKey, err)
+		}
+		return fmt.Errorf("original couldn't be removed, so the copy was undone: %w", err)
+	}
+	return nil
+}
+
 // CheckBucketAccess verifies if we have access to a bucket
 func (c *Client) CheckBucketAccess(ctx context.Context, bucket string) error {
 	_, err := c.S3.HeadBucket(ctx, &s3.HeadBucketInput{
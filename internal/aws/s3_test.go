@@ -0,0 +1,24 @@
+package aws
+
+import "testing"
+
+func TestParseDownloadPreset(t *testing.T) {
+	tests := []struct {
+		name    string
+		want    DownloadOptions
+		wantErr bool
+	}{
+		{"", DownloadOptions{PartSize: DefaultDownloadPartSize, Concurrency: DefaultDownloadConcurrency}, false},
+		{"balanced", DownloadOptions{PartSize: DefaultDownloadPartSize, Concurrency: DefaultDownloadConcurrency}, false},
+		{"conservative", DownloadOptions{PartSize: 5 * 1024 * 1024, Concurrency: 2}, false},
+		{"aggressive", DownloadOptions{PartSize: 64 * 1024 * 1024, Concurrency: 16}, false},
+		{"Aggressive", DownloadOptions{}, true},
+		{"turbo", DownloadOptions{}, true},
+	}
+	for _, tt := range tests {
+		got, err := ParseDownloadPreset(tt.name)
+		if (err != nil) != tt.wantErr || got != tt.want {
+			t.Errorf("ParseDownloadPreset(%q) = %+v, %v, want %+v", tt.name, got, err, tt.want)
+		}
+	}
+}
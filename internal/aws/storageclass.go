@@ -0,0 +1,83 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrSameStorageClass is returned by ChangeStorageClass when the object
+// already has the storage class
+var ErrSameStorageClass = errors.New("already in that storage class")
+
+// ChangeStorageClass moves an object to another storage class by copying it
+// onto itself with the new class. Its content type, metadata, tags and KMS
+// encryption are kept; its last modified time changes and versioned buckets
+// get a new version. Objects over 5GB are copied in parts. Objects in
+// GLACIER or DEEP_ARCHIVE have to be restored first.
+func (c *Client) ChangeStorageClass(ctx context.Context, bucket, key, class string, opts UploadOptions, onProgress func(DownloadProgress)) error {
+	head, err := c.S3.HeadObject(ctx, &s3.HeadObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object metadata: %w", err)
+	}
+
+	current := string(head.StorageClass)
+	if current == "" {
+		current = string(types.StorageClassStandard)
+	}
+	if current == class {
+		return ErrSameStorageClass
+	}
+	if head.SSECustomerAlgorithm != nil {
+		return errors.New("objects encrypted with a customer-provided key (SSE-C) can't be copied")
+	}
+	if archived(head.StorageClass) && !restored(aws.ToString(head.Restore)) {
+		return fmt.Errorf("%s is in %s; restore it before changing its storage class", key, current)
+	}
+
+	size := aws.ToInt64(head.ContentLength)
+	head.StorageClass = types.StorageClass(class)
+	if size > MaxCopySize {
+		return c.copyMultipart(ctx, c, head, bucket, key, bucket, key, opts, onProgress)
+	}
+
+	input := &s3.CopyObjectInput{
+		Bucket:       aws.String(bucket),
+		Key:          aws.String(key),
+		CopySource:   aws.String(copySource(bucket, key)),
+		StorageClass: head.StorageClass,
+	}
+	// CopyObject otherwise resets the encryption to the bucket default
+	if head.ServerSideEncryption == types.ServerSideEncryptionAwsKms {
+		input.ServerSideEncryption = head.ServerSideEncryption
+		input.SSEKMSKeyId = head.SSEKMSKeyId
+	}
+
+	if _, err := c.S3.CopyObject(ctx, input); err != nil {
+		return fmt.Errorf("failed to change storage class: %w", err)
+	}
+	if onProgress != nil {
+		onProgress(DownloadProgress{BytesDownloaded: size, TotalBytes: size, Key: key})
+	}
+	return nil
+}
+
+// archived reports whether objects in class must be restored before they
+// can be read or copied
+func archived(class types.StorageClass) bool {
+	return class == types.StorageClassGlacier || class == types.StorageClassDeepArchive
+}
+
+// restored reports whether an x-amz-restore header says a temporary copy of
+// an archived object is available
+func restored(restore string) bool {
+	return strings.Contains(restore, `ongoing-request="false"`)
+}
@@ -0,0 +1,226 @@
+package aws
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/cloudwatch"
+	cwtypes "github.com/aws/aws-sdk-go-v2/service/cloudwatch/types"
+)
+
+// metricsWindow is how far back CloudWatch is asked for bucket storage
+// metrics, which S3 reports once a day
+const metricsWindow = 3 * 24 * time.Hour
+
+// maxMetricQueries is the most metrics one GetMetricData call can fetch
+const maxMetricQueries = 500
+
+// regionLookups is how many bucket regions are looked up at once
+const regionLookups = 8
+
+// BucketStats is a bucket with its storage metrics from CloudWatch
+type BucketStats struct {
+	Bucket
+	Size       int64     // bytes in all storage classes
+	Objects    int64     // objects in all storage classes
+	MeasuredAt time.Time // zero if CloudWatch has no metrics for the bucket
+}
+
+// AccountSummary is an overview of the buckets a profile can see
+type AccountSummary struct {
+	Buckets    []BucketStats
+	MetricsErr error // why some regions have no metrics, e.g. no cloudwatch:GetMetricData permission
+}
+
+// RegionCount is how many buckets a region holds
+type RegionCount struct {
+	Region  string
+	Buckets int
+	Size    int64 // of the buckets with metrics
+}
+
+// Regions returns the regions with buckets, most buckets first
+func (s AccountSummary) Regions() []RegionCount {
+	byRegion := make(map[string]*RegionCount)
+	var regions []*RegionCount
+	for _, b := range s.Buckets {
+		r, ok := byRegion[b.Region]
+		if !ok {
+			r = &RegionCount{Region: b.Region}
+			byRegion[b.Region] = r
+			regions = append(regions, r)
+		}
+		r.Buckets++
+		r.Size += b.Size
+	}
+	counts := make([]RegionCount, len(regions))
+	for i, r := range regions {
+		counts[i] = *r
+	}
+	slices.SortStableFunc(counts, func(a, b RegionCount) int {
+		return cmp.Or(cmp.Compare(b.Buckets, a.Buckets), cmp.Compare(a.Region, b.Region))
+	})
+	return counts
+}
+
+// Largest returns up to n of the buckets with metrics, biggest first
+func (s AccountSummary) Largest(n int) []BucketStats {
+	var measured []BucketStats
+	for _, b := range s.Buckets {
+		if !b.MeasuredAt.IsZero() {
+			measured = append(measured, b)
+		}
+	}
+	slices.SortStableFunc(measured, func(a, b BucketStats) int { return cmp.Compare(b.Size, a.Size) })
+	return measured[:min(n, len(measured))]
+}
+
+// Newest returns up to n buckets, the most recently created first
+func (s AccountSummary) Newest(n int) []BucketStats {
+	newest := slices.Clone(s.Buckets)
+	slices.SortStableFunc(newest, func(a, b BucketStats) int { return b.CreationDate.Compare(a.CreationDate) })
+	return newest[:min(n, len(newest))]
+}
+
+// Total returns the size and object count of the buckets with metrics
+func (s AccountSummary) Total() (size, objects int64) {
+	for _, b := range s.Buckets {
+		size += b.Size
+		objects += b.Objects
+	}
+	return size, objects
+}
+
+// SummarizeBuckets looks up each bucket's region, where ListBuckets didn't
+// say, and its size and object count from the daily storage metrics S3
+// sends CloudWatch, region by region. Buckets whose region can't be looked
+// up are summarized under "unknown".
+func (c *Client) SummarizeBuckets(ctx context.Context, buckets []Bucket) *AccountSummary {
+	summary := &AccountSummary{Buckets: make([]BucketStats, len(buckets))}
+	var wg sync.WaitGroup
+	sem := make(chan struct{}, regionLookups)
+	for i, b := range buckets {
+		summary.Buckets[i].Bucket = b
+		if b.Region != "" {
+			continue
+		}
+		wg.Add(1)
+		go func(s *BucketStats) {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+			region, err := c.GetBucketRegion(ctx, s.Name)
+			if err != nil {
+				region = "unknown"
+			}
+			s.Region = region
+		}(&summary.Buckets[i])
+	}
+	wg.Wait()
+
+	var errs []error
+	for _, r := range summary.Regions() {
+		if r.Region == "unknown" {
+			continue
+		}
+		metrics, err := c.bucketMetrics(ctx, r.Region)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", r.Region, err))
+			continue
+		}
+		for i := range summary.Buckets {
+			s := &summary.Buckets[i]
+			if m, ok := metrics[s.Name]; ok && s.Region == r.Region {
+				s.Size, s.Objects, s.MeasuredAt = m.Size, m.Objects, m.MeasuredAt
+			}
+		}
+	}
+	summary.MetricsErr = errors.Join(errs...)
+	return summary
+}
+
+// bucketMetrics returns the latest storage metrics of every bucket in a
+// region, by name. Sizes are summed over storage classes.
+func (c *Client) bucketMetrics(ctx context.Context, region string) (map[string]BucketStats, error) {
+	cw := cloudwatch.NewFromConfig(c.Config, func(o *cloudwatch.Options) {
+		o.Region = region
+	})
+
+	var metrics []cwtypes.Metric
+	for _, name := range []string{"BucketSizeBytes", "NumberOfObjects"} {
+		paginator := cloudwatch.NewListMetricsPaginator(cw, &cloudwatch.ListMetricsInput{
+			Namespace:  aws.String("AWS/S3"),
+			MetricName: aws.String(name),
+		})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				return nil, fmt.Errorf("failed to list bucket metrics: %w", err)
+			}
+			metrics = append(metrics, page.Metrics...)
+		}
+	}
+
+	stats := make(map[string]BucketStats)
+	end := time.Now()
+	for batch := range slices.Chunk(metrics, maxMetricQueries) {
+		queries := make([]cwtypes.MetricDataQuery, len(batch))
+		for i := range batch {
+			queries[i] = cwtypes.MetricDataQuery{
+				Id: aws.String(fmt.Sprintf("m%d", i)),
+				MetricStat: &cwtypes.MetricStat{
+					Metric: &batch[i],
+					Period: aws.Int32(86400),
+					Stat:   aws.String("Average"),
+				},
+			}
+		}
+		output, err := cw.GetMetricData(ctx, &cloudwatch.GetMetricDataInput{
+			MetricDataQueries: queries,
+			StartTime:         aws.Time(end.Add(-metricsWindow)),
+			EndTime:           aws.Time(end),
+			ScanBy:            cwtypes.ScanByTimestampDescending,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get bucket metrics: %w", err)
+		}
+		for _, result := range output.MetricDataResults {
+			var i int
+			if _, err := fmt.Sscanf(aws.ToString(result.Id), "m%d", &i); err != nil || i >= len(batch) {
+				continue
+			}
+			if len(result.Values) == 0 || len(result.Timestamps) == 0 {
+				continue
+			}
+			bucket := dimension(batch[i], "BucketName")
+			s := stats[bucket]
+			switch aws.ToString(batch[i].MetricName) {
+			case "BucketSizeBytes":
+				s.Size += int64(result.Values[0])
+			case "NumberOfObjects":
+				s.Objects += int64(result.Values[0])
+			}
+			if result.Timestamps[0].After(s.MeasuredAt) {
+				s.MeasuredAt = result.Timestamps[0]
+			}
+			stats[bucket] = s
+		}
+	}
+	return stats, nil
+}
+
+// dimension returns the value of a metric's dimension
+func dimension(m cwtypes.Metric, name string) string {
+	for _, d := range m.Dimensions {
+		if aws.ToString(d.Name) == name {
+			return aws.ToString(d.Value)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,47 @@
+package aws
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestAccountSummary(t *testing.T) {
+	day := func(d int) time.Time { return time.Date(2024, 1, d, 0, 0, 0, 0, time.UTC) }
+	measured := day(20)
+	summary := AccountSummary{Buckets: []BucketStats{
+		{Bucket: Bucket{Name: "logs", CreationDate: day(1), Region: "us-east-1"}, Size: 500, Objects: 10, MeasuredAt: measured},
+		{Bucket: Bucket{Name: "media", CreationDate: day(3), Region: "eu-west-1"}, Size: 900, Objects: 2, MeasuredAt: measured},
+		{Bucket: Bucket{Name: "new", CreationDate: day(9), Region: "us-east-1"}},
+		{Bucket: Bucket{Name: "tmp", CreationDate: day(5), Region: "us-east-1"}, MeasuredAt: measured},
+	}}
+
+	wantRegions := []RegionCount{
+		{Region: "us-east-1", Buckets: 3, Size: 500},
+		{Region: "eu-west-1", Buckets: 1, Size: 900},
+	}
+	if got := summary.Regions(); !reflect.DeepEqual(got, wantRegions) {
+		t.Errorf("Regions() = %+v, want %+v", got, wantRegions)
+	}
+
+	var names []string
+	for _, b := range summary.Largest(5) {
+		names = append(names, b.Name)
+	}
+	// A bucket without metrics has no size to rank
+	if want := []string{"media", "logs", "tmp"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Largest(5) = %v, want %v", names, want)
+	}
+
+	names = nil
+	for _, b := range summary.Newest(2) {
+		names = append(names, b.Name)
+	}
+	if want := []string{"new", "tmp"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("Newest(2) = %v, want %v", names, want)
+	}
+
+	if size, objects := summary.Total(); size != 1400 || objects != 12 {
+		t.Errorf("Total() = %d, %d, want 1400, 12", size, objects)
+	}
+}
@@ -0,0 +1,60 @@
+package aws
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+)
+
+// ErrAlreadyTagged is returned by TagObject when the object already has
+// every tag with the same value
+var ErrAlreadyTagged = errors.New("already has those tags")
+
+// maxObjectTags is how many tags S3 allows on one object
+const maxObjectTags = 10
+
+// TagObject adds tags to an object, replacing the values of any it already
+// has and keeping the rest. Its content and last modified time don't
+// change. Needs s3:GetObjectTagging and s3:PutObjectTagging.
+func (c *Client) TagObject(ctx context.Context, bucket, key string, tags map[string]string) error {
+	current, err := c.S3.GetObjectTagging(ctx, &s3.GetObjectTaggingInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to get object tags: %w", err)
+	}
+
+	merged := make(map[string]string, len(current.TagSet)+len(tags))
+	for _, tag := range current.TagSet {
+		merged[aws.ToString(tag.Key)] = aws.ToString(tag.Value)
+	}
+	before := maps.Clone(merged)
+	maps.Copy(merged, tags)
+	if maps.Equal(before, merged) {
+		return ErrAlreadyTagged
+	}
+	if len(merged) > maxObjectTags {
+		return fmt.Errorf("%s would have %d tags (max %d)", key, len(merged), maxObjectTags)
+	}
+
+	tagSet := make([]types.Tag, 0, len(merged))
+	for _, k := range slices.Sorted(maps.Keys(merged)) {
+		tagSet = append(tagSet, types.Tag{Key: aws.String(k), Value: aws.String(merged[k])})
+	}
+	_, err = c.S3.PutObjectTagging(ctx, &s3.PutObjectTaggingInput{
+		Bucket:  aws.String(bucket),
+		Key:     aws.String(key),
+		Tagging: &types.Tagging{TagSet: tagSet},
+	})
+	if err != nil {
+		return fmt.Errorf("failed to tag object: %w", err)
+	}
+	return nil
+}
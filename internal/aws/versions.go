@@ -0,0 +1,106 @@
+package aws
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+)
+
+// MaxVersions bounds how many versions and delete markers ListVersions
+// returns
+const MaxVersions = 1000
+
+// ObjectVersion is one version of an object in a versioned bucket, or a
+// delete marker hiding the versions before it
+type ObjectVersion struct {
+	Key          string
+	VersionID    string // "null" for objects written before versioning was on
+	IsLatest     bool
+	DeleteMarker bool
+	Size         int64
+	LastModified time.Time
+	StorageClass string
+	ETag         string
+}
+
+// ListVersions returns the versions and delete markers of the keys directly
+// under prefix, keys in order and each key's newest first. Keys whose newest
+// version is a delete marker are the ones missing from a normal listing.
+// truncated reports that there were more than MaxVersions.
+func (c *Client) ListVersions(ctx context.Context, bucket, prefix string) (versions []ObjectVersion, truncated bool, err error) {
+	paginator := s3.NewListObjectVersionsPaginator(c.S3, &s3.ListObjectVersionsInput{
+		Bucket:    aws.String(bucket),
+		Prefix:    aws.String(prefix),
+		Delimiter: aws.String("/"),
+	})
+
+	for paginator.HasMorePages() {
+		if len(versions) >= MaxVersions {
+			truncated = true
+			break
+		}
+		page, err := paginator.NextPage(ctx)
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to list versions: %w", err)
+		}
+		for _, v := range page.Versions {
+			versions = append(versions, ObjectVersion{
+				Key:          aws.ToString(v.Key),
+				VersionID:    aws.ToString(v.VersionId),
+				IsLatest:     aws.ToBool(v.IsLatest),
+				Size:         aws.ToInt64(v.Size),
+				LastModified: aws.ToTime(v.LastModified),
+				StorageClass: string(v.StorageClass),
+				ETag:         strings.Trim(aws.ToString(v.ETag), "\""),
+			})
+		}
+		for _, d := range page.DeleteMarkers {
+			versions = append(versions, ObjectVersion{
+				Key:          aws.ToString(d.Key),
+				VersionID:    aws.ToString(d.VersionId),
+				IsLatest:     aws.ToBool(d.IsLatest),
+				DeleteMarker: true,
+				LastModified: aws.ToTime(d.LastModified),
+			})
+		}
+	}
+
+	// S3 returns versions and delete markers in separate lists
+	slices.SortStableFunc(versions, func(a, b ObjectVersion) int {
+		if c := cmp.Compare(a.Key, b.Key); c != 0 {
+			return c
+		}
+		if a.IsLatest != b.IsLatest {
+			if a.IsLatest {
+				return -1
+			}
+			return 1
+		}
+		return b.LastModified.Compare(a.LastModified)
+	})
+	if len(versions) > MaxVersions {
+		versions, truncated = versions[:MaxVersions], true
+	}
+	return versions, truncated, nil
+}
+
+// DeleteVersion removes one version of key for good. Removing the delete
+// marker that's the newest version undeletes the object: the version
+// before it becomes current again.
+func (c *Client) DeleteVersion(ctx context.Context, bucket, key, versionID string) error {
+	_, err := c.S3.DeleteObject(ctx, &s3.DeleteObjectInput{
+		Bucket:    aws.String(bucket),
+		Key:       aws.String(key),
+		VersionId: aws.String(versionID),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to delete version: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,39 @@
+package bookmarks
+
+import (
+	"cmp"
+	"slices"
+	"time"
+)
+
+// Arrival is a recently modified object under a bookmark
+type Arrival struct {
+	Bookmark     Bookmark
+	Key          string
+	Size         int64
+	LastModified time.Time
+}
+
+// MergeArrivals returns the n most recently modified of arrivals gathered
+// from several bookmarks, newest first. An object under more than one
+// bookmark is kept once, under the bookmark closest to it.
+func MergeArrivals(arrivals []Arrival, n int) []Arrival {
+	type object struct{ bucket, key string }
+	closest := make(map[object]int)
+	var merged []Arrival
+	for _, a := range arrivals {
+		o := object{a.Bookmark.Bucket, a.Key}
+		if i, ok := closest[o]; ok {
+			if len(a.Bookmark.Prefix) > len(merged[i].Bookmark.Prefix) {
+				merged[i].Bookmark = a.Bookmark
+			}
+			continue
+		}
+		closest[o] = len(merged)
+		merged = append(merged, a)
+	}
+	slices.SortStableFunc(merged, func(a, b Arrival) int {
+		return cmp.Or(b.LastModified.Compare(a.LastModified), cmp.Compare(a.Key, b.Key))
+	})
+	return merged[:min(n, len(merged))]
+}
@@ -0,0 +1,42 @@
+package bookmarks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMergeArrivals(t *testing.T) {
+	at := func(hour int) time.Time { return time.Date(2024, time.March, 5, hour, 0, 0, 0, time.UTC) }
+	bucket := Bookmark{ID: "1", Bucket: "data"}
+	events := Bookmark{ID: "2", Bucket: "data", Prefix: "events/"}
+	logs := Bookmark{ID: "3", Bucket: "logs"}
+
+	arrivals := []Arrival{
+		{Bookmark: bucket, Key: "events/a.json", LastModified: at(9)},
+		{Bookmark: bucket, Key: "readme.txt", LastModified: at(1)},
+		{Bookmark: logs, Key: "app.log", LastModified: at(11)},
+		{Bookmark: events, Key: "events/a.json", LastModified: at(9)},
+		{Bookmark: events, Key: "events/b.json", LastModified: at(10)},
+		// The same key in another bucket is another object
+		{Bookmark: logs, Key: "readme.txt", LastModified: at(2)},
+	}
+
+	got := MergeArrivals(arrivals, 4)
+	want := []struct {
+		bookmark string
+		key      string
+	}{
+		{"3", "app.log"},
+		{"2", "events/b.json"},
+		{"2", "events/a.json"}, // under the closer bookmark
+		{"3", "readme.txt"},
+	}
+	if len(got) != len(want) {
+		t.Fatalf("MergeArrivals() returned %d arrivals, want %d: %+v", len(got), len(want), got)
+	}
+	for i, w := range want {
+		if got[i].Bookmark.ID != w.bookmark || got[i].Key != w.key {
+			t.Errorf("arrival %d = bookmark %s %s, want bookmark %s %s", i, got[i].Bookmark.ID, got[i].Key, w.bookmark, w.key)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package bookmarks
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultDatePattern is the date layout used where a bookmark has none
+const DefaultDatePattern = "YYYY/MM/DD/"
+
+// dateTokens are the placeholders a date pattern can hold
+var dateTokens = []struct {
+	token  string
+	layout string // Go time layout
+	re     string // what the token matches in a key
+}{
+	{"YYYY", "2006", `\d{4}`},
+	{"MM", "01", `\d{2}`},
+	{"DD", "02", `\d{2}`},
+}
+
+// ValidDatePattern checks a date pattern such as "YYYY/MM/DD/" or
+// "dt=YYYY-MM-DD/"
+func ValidDatePattern(pattern string) error {
+	if !strings.Contains(pattern, "YYYY") {
+		return fmt.Errorf("date pattern %q needs a YYYY", pattern)
+	}
+	if !strings.HasSuffix(pattern, "/") {
+		return fmt.Errorf("date pattern %q must end with /", pattern)
+	}
+	if strings.HasPrefix(pattern, "/") || strings.Contains(pattern, "//") {
+		return fmt.Errorf("date pattern %q has an empty folder", pattern)
+	}
+	return nil
+}
+
+// DatePrefix fills in pattern for t, e.g. "2024/03/05/"
+func DatePrefix(pattern string, t time.Time) string {
+	for _, tok := range dateTokens {
+		pattern = strings.ReplaceAll(pattern, tok.token, t.Format(tok.layout))
+	}
+	return pattern
+}
+
+// DateBase strips a date partition laid out by pattern from the end of
+// prefix, so jumping to another date from inside one replaces it:
+// "logs/2024/03/" gives "logs/" for "YYYY/MM/DD/". Prefixes that don't end
+// in any part of the pattern are returned as they are.
+func DateBase(prefix, pattern string) string {
+	for _, re := range dateBaseRegexps(pattern) {
+		if loc := re.FindStringSubmatchIndex(prefix); loc != nil {
+			return prefix[:loc[2]]
+		}
+	}
+	return prefix
+}
+
+// dateBases caches the expressions DateBase tries for each pattern
+var (
+	dateBasesMu sync.Mutex
+	dateBases   = make(map[string][]*regexp.Regexp)
+)
+
+// dateBaseRegexps returns expressions matching a prefix that ends in the
+// whole of pattern, then in each shorter run of its leading folders
+func dateBaseRegexps(pattern string) []*regexp.Regexp {
+	dateBasesMu.Lock()
+	defer dateBasesMu.Unlock()
+	if res, ok := dateBases[pattern]; ok {
+		return res
+	}
+	folders := strings.SplitAfter(pattern, "/")
+	var res []*regexp.Regexp
+	for n := len(folders); n > 0; n-- {
+		partial := strings.Join(folders[:n], "")
+		res = append(res, regexp.MustCompile("(?:^|/)("+datePatternRegexp(partial)+")$"))
+	}
+	dateBases[pattern] = res
+	return res
+}
+
+// datePatternRegexp turns a date pattern into a regular expression
+// matching the keys it lays out
+func datePatternRegexp(pattern string) string {
+	var sb strings.Builder
+	for len(pattern) > 0 {
+		matched := false
+		for _, tok := range dateTokens {
+			if strings.HasPrefix(pattern, tok.token) {
+				sb.WriteString(tok.re)
+				pattern = pattern[len(tok.token):]
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			sb.WriteString(regexp.QuoteMeta(pattern[:1]))
+			pattern = pattern[1:]
+		}
+	}
+	return sb.String()
+}
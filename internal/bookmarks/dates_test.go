@@ -0,0 +1,70 @@
+package bookmarks
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDatePrefix(t *testing.T) {
+	day := time.Date(2024, time.March, 5, 0, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		pattern  string
+		expected string
+	}{
+		{"YYYY/MM/DD/", "2024/03/05/"},
+		{"year=YYYY/month=MM/day=DD/", "year=2024/month=03/day=05/"},
+		{"dt=YYYY-MM-DD/", "dt=2024-03-05/"},
+		{"YYYY/MM/", "2024/03/"},
+	}
+
+	for _, tt := range tests {
+		if got := DatePrefix(tt.pattern, day); got != tt.expected {
+			t.Errorf("DatePrefix(%q) = %s, want %s", tt.pattern, got, tt.expected)
+		}
+	}
+}
+
+func TestDateBase(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		pattern  string
+		expected string
+	}{
+		{"logs/", "YYYY/MM/DD/", "logs/"},
+		{"logs/2024/03/05/", "YYYY/MM/DD/", "logs/"},
+		{"logs/2024/03/", "YYYY/MM/DD/", "logs/"},
+		{"logs/2024/", "YYYY/MM/DD/", "logs/"},
+		{"2024/03/05/", "YYYY/MM/DD/", ""},
+		{"logs/v2024/", "YYYY/MM/DD/", "logs/v2024/"},
+		{"events/year=2024/month=03/", "year=YYYY/month=MM/day=DD/", "events/"},
+		{"events/dt=2024-03-05/", "dt=YYYY-MM-DD/", "events/"},
+		{"events/dt=2024-03-05/hour=01/", "dt=YYYY-MM-DD/", "events/dt=2024-03-05/hour=01/"},
+	}
+
+	for _, tt := range tests {
+		if got := DateBase(tt.prefix, tt.pattern); got != tt.expected {
+			t.Errorf("DateBase(%q, %q) = %q, want %q", tt.prefix, tt.pattern, got, tt.expected)
+		}
+	}
+}
+
+func TestValidDatePattern(t *testing.T) {
+	tests := []struct {
+		pattern string
+		wantErr bool
+	}{
+		{"YYYY/MM/DD/", false},
+		{"dt=YYYY-MM-DD/", false},
+		{"MM/DD/", true},
+		{"YYYY/MM/DD", true},
+		{"/YYYY/", true},
+		{"YYYY//MM/", true},
+	}
+
+	for _, tt := range tests {
+		if err := ValidDatePattern(tt.pattern); (err != nil) != tt.wantErr {
+			t.Errorf("ValidDatePattern(%q) error = %v, wantErr %v", tt.pattern, err, tt.wantErr)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package bookmarks
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ParseInterval parses how often new objects should arrive, as a Go
+// duration ("90m", "36h") or a number of days ("2d")
+func ParseInterval(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	var d time.Duration
+	if days, ok := strings.CutSuffix(s, "d"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil {
+			return 0, fmt.Errorf("invalid interval %q (use e.g. 30m, 24h or 2d)", s)
+		}
+		d = time.Duration(n) * 24 * time.Hour
+	} else {
+		var err error
+		if d, err = time.ParseDuration(s); err != nil {
+			return 0, fmt.Errorf("invalid interval %q (use e.g. 30m, 24h or 2d)", s)
+		}
+	}
+	if d < time.Minute {
+		return 0, fmt.Errorf("interval %q is shorter than a minute", s)
+	}
+	return d, nil
+}
+
+// ExpectInterval returns how often new objects should arrive under the
+// bookmark, or 0 if it has no freshness rule
+func (b Bookmark) ExpectInterval() time.Duration {
+	if b.ExpectEvery == "" {
+		return 0
+	}
+	d, err := ParseInterval(b.ExpectEvery)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// Freshness is the result of checking when objects last arrived under a
+// bookmark
+type Freshness struct {
+	Newest  time.Time // last modified time of the newest object; zero if there are none
+	Checked time.Time
+	Err     error
+}
+
+// Stale reports whether nothing has arrived within every of the check
+func (f Freshness) Stale(every time.Duration) bool {
+	if f.Err != nil || every <= 0 || f.Checked.IsZero() {
+		return false
+	}
+	return f.Newest.IsZero() || f.Checked.Sub(f.Newest) > every
+}
@@ -0,0 +1,55 @@
+package bookmarks
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseInterval(t *testing.T) {
+	tests := []struct {
+		input    string
+		expected time.Duration
+		wantErr  bool
+	}{
+		{"24h", 24 * time.Hour, false},
+		{"90m", 90 * time.Minute, false},
+		{"2d", 48 * time.Hour, false},
+		{"30s", 0, true},
+		{"daily", 0, true},
+		{"xd", 0, true},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseInterval(tt.input)
+		if (err != nil) != tt.wantErr || got != tt.expected {
+			t.Errorf("ParseInterval(%q) = %v, %v, want %v, wantErr %v", tt.input, got, err, tt.expected, tt.wantErr)
+		}
+	}
+}
+
+func TestFreshnessStale(t *testing.T) {
+	checked := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name      string
+		freshness Freshness
+		every     time.Duration
+		expected  bool
+	}{
+		{"recent", Freshness{Newest: checked.Add(-2 * time.Hour), Checked: checked}, 24 * time.Hour, false},
+		{"overdue", Freshness{Newest: checked.Add(-25 * time.Hour), Checked: checked}, 24 * time.Hour, true},
+		{"empty prefix", Freshness{Checked: checked}, 24 * time.Hour, true},
+		{"check failed", Freshness{Checked: checked, Err: errors.New("access denied")}, 24 * time.Hour, false},
+		{"no rule", Freshness{Newest: checked.Add(-48 * time.Hour), Checked: checked}, 0, false},
+		{"not checked", Freshness{}, 24 * time.Hour, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.freshness.Stale(tt.every); got != tt.expected {
+				t.Errorf("Stale() = %v, want %v", got, tt.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,79 @@
+package bookmarks
+
+import (
+	"path"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// partitionValue parses a partition folder name such as "2024", "03",
+// "dt=2024-03-05" or "run-0042" into its numbers, for ordering partitions.
+// It returns false for names that aren't partitions.
+func partitionValue(name string) ([]int, bool) {
+	name = strings.TrimSuffix(name, "/")
+	if _, value, ok := strings.Cut(name, "="); ok {
+		name = value
+	}
+	fields := strings.FieldsFunc(name, func(r rune) bool {
+		return r == '-' || r == '_' || r == '.' || r == ':' || r == 'T'
+	})
+	if len(fields) == 0 {
+		return nil, false
+	}
+
+	var values []int
+	for i, f := range fields {
+		n, err := strconv.Atoi(f)
+		if err != nil {
+			// Allow a word before the number, as in run-0042
+			if i == 0 && len(fields) > 1 {
+				continue
+			}
+			return nil, false
+		}
+		values = append(values, n)
+	}
+	return values, true
+}
+
+// IsPartition reports whether a folder name looks like a date or numbered
+// partition
+func IsPartition(name string) bool {
+	_, ok := partitionValue(name)
+	return ok
+}
+
+// LatestPartition returns the newest of the partition folders among
+// prefixes, comparing their numbers rather than their text so "10" comes
+// after "9". Prefixes that aren't partitions are ignored.
+func LatestPartition(prefixes []string) (string, bool) {
+	var latest string
+	var latestValue []int
+	for _, p := range prefixes {
+		value, ok := partitionValue(path.Base(p))
+		if !ok {
+			continue
+		}
+		if latestValue == nil || slices.Compare(value, latestValue) > 0 {
+			latest, latestValue = p, value
+		}
+	}
+	return latest, latestValue != nil
+}
+
+// PartitionRoot strips trailing partition folders from prefix, so
+// "logs/2024/03/05/" gives "logs/"
+func PartitionRoot(prefix string) string {
+	for prefix != "" {
+		parent := path.Dir(strings.TrimSuffix(prefix, "/"))
+		if !IsPartition(path.Base(prefix)) {
+			break
+		}
+		if parent == "." {
+			return ""
+		}
+		prefix = parent + "/"
+	}
+	return prefix
+}
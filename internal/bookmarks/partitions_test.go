@@ -0,0 +1,48 @@
+package bookmarks
+
+import "testing"
+
+func TestLatestPartition(t *testing.T) {
+	tests := []struct {
+		name     string
+		prefixes []string
+		expected string
+		found    bool
+	}{
+		{"years", []string{"logs/2023/", "logs/2024/", "logs/2022/"}, "logs/2024/", true},
+		{"numeric order", []string{"runs/9/", "runs/10/", "runs/2/"}, "runs/10/", true},
+		{"hive", []string{"t/dt=2024-03-05/", "t/dt=2024-03-14/", "t/dt=2024-02-28/"}, "t/dt=2024-03-14/", true},
+		{"named runs", []string{"r/run-0041/", "r/run-0042/"}, "r/run-0042/", true},
+		{"ignores other folders", []string{"logs/2024/", "logs/_tmp/", "logs/archive/"}, "logs/2024/", true},
+		{"no partitions", []string{"a/docs/", "a/images/"}, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := LatestPartition(tt.prefixes)
+			if got != tt.expected || ok != tt.found {
+				t.Errorf("LatestPartition() = %q, %v, want %q, %v", got, ok, tt.expected, tt.found)
+			}
+		})
+	}
+}
+
+func TestPartitionRoot(t *testing.T) {
+	tests := []struct {
+		prefix   string
+		expected string
+	}{
+		{"logs/", "logs/"},
+		{"logs/2024/03/05/", "logs/"},
+		{"events/year=2024/month=03/", "events/"},
+		{"2024/03/", ""},
+		{"", ""},
+		{"logs/2024/raw/", "logs/2024/raw/"},
+	}
+
+	for _, tt := range tests {
+		if got := PartitionRoot(tt.prefix); got != tt.expected {
+			t.Errorf("PartitionRoot(%q) = %q, want %q", tt.prefix, got, tt.expected)
+		}
+	}
+}
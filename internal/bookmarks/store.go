@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"time"
 
 	"github.com/google/uuid"
@@ -13,11 +14,22 @@ import (
 
 // Bookmark represents a saved S3 location
 type Bookmark struct {
-	ID        string    `json:"id"`
-	Name      string    `json:"name"`
-	Bucket    string    `json:"bucket"`
-	Prefix    string    `json:"prefix"`
-	CreatedAt time.Time `json:"created_at"`
+	ID          string    `json:"id"`
+	Name        string    `json:"name"`
+	Bucket      string    `json:"bucket"`
+	Prefix      string    `json:"prefix"`
+	DatePattern string    `json:"date_pattern,omitempty"` // layout of date partitions below Prefix, e.g. "YYYY/MM/DD/"
+	ExpectEvery string    `json:"expect_every,omitempty"` // how often new objects should arrive, e.g. "24h"
+	Archived    bool      `json:"archived,omitempty"`     // kept, but left out of the list and checks unless archived bookmarks are shown
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// DateLayout returns the bookmark's date pattern, or the default
+func (b Bookmark) DateLayout() string {
+	if b.DatePattern != "" {
+		return b.DatePattern
+	}
+	return DefaultDatePattern
 }
 
 // DisplayName returns the bookmark display name
@@ -151,6 +163,17 @@ func (s *Store) List() []Bookmark {
 	return s.bookmarks
 }
 
+// Active returns the bookmarks that aren't archived
+func (s *Store) Active() []Bookmark {
+	var active []Bookmark
+	for _, b := range s.bookmarks {
+		if !b.Archived {
+			active = append(active, b)
+		}
+	}
+	return active
+}
+
 // Get returns a bookmark by ID
 func (s *Store) Get(id string) (Bookmark, bool) {
 	for _, b := range s.bookmarks {
@@ -172,6 +195,61 @@ func (s *Store) Update(id, name string) error {
 	return fmt.Errorf("bookmark not found: %s", id)
 }
 
+// SetArchived archives a bookmark, or brings it back
+func (s *Store) SetArchived(id string, archived bool) error {
+	for i, b := range s.bookmarks {
+		if b.ID == id {
+			s.bookmarks[i].Archived = archived
+			return s.Save()
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", id)
+}
+
+// SetDatePattern sets the date layout of a bookmark's partitions
+func (s *Store) SetDatePattern(id, pattern string) error {
+	if err := ValidDatePattern(pattern); err != nil {
+		return err
+	}
+	for i, b := range s.bookmarks {
+		if b.ID == id {
+			s.bookmarks[i].DatePattern = pattern
+			return s.Save()
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", id)
+}
+
+// SetExpectEvery sets how often new objects should arrive under a
+// bookmark; "" turns the freshness check off
+func (s *Store) SetExpectEvery(id, every string) error {
+	if every != "" {
+		if _, err := ParseInterval(every); err != nil {
+			return err
+		}
+	}
+	for i, b := range s.bookmarks {
+		if b.ID == id {
+			s.bookmarks[i].ExpectEvery = every
+			return s.Save()
+		}
+	}
+	return fmt.Errorf("bookmark not found: %s", id)
+}
+
+// Covering finds the bookmark with the longest prefix that bucket and
+// prefix are under
+func (s *Store) Covering(bucket, prefix string) (Bookmark, bool) {
+	var found Bookmark
+	ok := false
+	for _, b := range s.bookmarks {
+		if b.Bucket == bucket && strings.HasPrefix(prefix, b.Prefix) && (!ok || len(b.Prefix) > len(found.Prefix)) {
+			found, ok = b, true
+		}
+	}
+	return found, ok
+}
+
 // FindByPath finds a bookmark by bucket and prefix
 func (s *Store) FindByPath(bucket, prefix string) (Bookmark, bool) {
 	for _, b := range s.bookmarks {
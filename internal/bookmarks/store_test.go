@@ -100,3 +100,78 @@ func TestBookmarkDisplayName(t *testing.T) {
 		})
 	}
 }
+
+func TestBookmarkCovering(t *testing.T) {
+	store := &Store{
+		path:      filepath.Join(t.TempDir(), "bookmarks.json"),
+		bookmarks: []Bookmark{},
+	}
+	if _, err := store.Add("bucket", "my-bucket", ""); err != nil {
+		t.Fatalf("failed to add bookmark: %v", err)
+	}
+	logs, err := store.Add("logs", "my-bucket", "logs/")
+	if err != nil {
+		t.Fatalf("failed to add bookmark: %v", err)
+	}
+
+	found, ok := store.Covering("my-bucket", "logs/2024/03/")
+	if !ok || found.ID != logs.ID {
+		t.Errorf("expected the logs bookmark to cover logs/2024/03/, got %+v", found)
+	}
+	if found, ok := store.Covering("my-bucket", "other/"); !ok || found.Prefix != "" {
+		t.Errorf("expected the bucket bookmark to cover other/, got %+v", found)
+	}
+	if _, ok := store.Covering("other-bucket", "logs/"); ok {
+		t.Error("expected no bookmark to cover another bucket")
+	}
+
+	if found.DateLayout() != DefaultDatePattern {
+		t.Errorf("expected default date layout, got %s", found.DateLayout())
+	}
+	if err := store.SetDatePattern(logs.ID, "dt=YYYY-MM-DD/"); err != nil {
+		t.Fatalf("failed to set date pattern: %v", err)
+	}
+	if err := store.SetDatePattern(logs.ID, "DD/"); err == nil {
+		t.Error("expected a pattern without YYYY to be rejected")
+	}
+	if got, _ := store.Get(logs.ID); got.DateLayout() != "dt=YYYY-MM-DD/" {
+		t.Errorf("expected date layout dt=YYYY-MM-DD/, got %s", got.DateLayout())
+	}
+}
+
+func TestBookmarkArchive(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "bookmarks.json")
+	store := &Store{path: path, bookmarks: []Bookmark{}}
+	old, err := store.Add("old", "my-bucket", "2019/")
+	if err != nil {
+		t.Fatalf("failed to add bookmark: %v", err)
+	}
+	if _, err := store.Add("current", "my-bucket", "2024/"); err != nil {
+		t.Fatalf("failed to add bookmark: %v", err)
+	}
+
+	if err := store.SetArchived(old.ID, true); err != nil {
+		t.Fatalf("failed to archive bookmark: %v", err)
+	}
+	if err := store.SetArchived("missing", true); err == nil {
+		t.Error("expected archiving a missing bookmark to fail")
+	}
+
+	reloaded := &Store{path: path}
+	if err := reloaded.Load(); err != nil {
+		t.Fatalf("failed to load bookmarks: %v", err)
+	}
+	if len(reloaded.List()) != 2 {
+		t.Errorf("expected archived bookmarks to be kept, got %d", len(reloaded.List()))
+	}
+	if active := reloaded.Active(); len(active) != 1 || active[0].Name != "current" {
+		t.Errorf("expected only the current bookmark to be active, got %+v", active)
+	}
+
+	if err := reloaded.SetArchived(old.ID, false); err != nil {
+		t.Fatalf("failed to unarchive bookmark: %v", err)
+	}
+	if len(reloaded.Active()) != 2 {
+		t.Errorf("expected 2 active bookmarks after unarchiving, got %d", len(reloaded.Active()))
+	}
+}
@@ -0,0 +1,108 @@
+package config
+
+import (
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// Layouts the browser's columns can be configured for
+const (
+	LayoutBrowser   = "browser"   // the Browser tab
+	LayoutCommander = "commander" // the S3 pane beside the local filesystem
+)
+
+// ColumnFields are the fields the browser can show for each object
+var ColumnFields = []string{"size", "modified", "class", "etag", "owner", "type"}
+
+// Column is one field of the browser's object details line
+type Column struct {
+	Field string
+	Width int // pads or truncates the value to this many cells; 0 fits it
+}
+
+// String writes the column the way ParseColumns reads it
+func (c Column) String() string {
+	if c.Width > 0 {
+		return fmt.Sprintf("%s:%d", c.Field, c.Width)
+	}
+	return c.Field
+}
+
+// ParseColumns parses column specs such as "size", "modified:16" or "etag:10"
+func ParseColumns(specs []string) ([]Column, error) {
+	columns := make([]Column, 0, len(specs))
+	for _, spec := range specs {
+		field, width, hasWidth := strings.Cut(strings.TrimSpace(spec), ":")
+		col := Column{Field: strings.ToLower(field)}
+		if !slices.Contains(ColumnFields, col.Field) {
+			return nil, fmt.Errorf("unknown column %q (use %s)", field, strings.Join(ColumnFields, ", "))
+		}
+		if hasWidth {
+			n, err := strconv.Atoi(width)
+			if err != nil || n < 1 {
+				return nil, fmt.Errorf("invalid width in column %q", spec)
+			}
+			col.Width = n
+		}
+		columns = append(columns, col)
+	}
+	return columns, nil
+}
+
+// ColumnsFor returns the columns configured for a layout, or nil to use the
+// browser's defaults
+func (c *Config) ColumnsFor(layout string) []Column {
+	return c.columns[layout]
+}
+
+// SetColumns sets a layout's columns from specs, or goes back to the
+// defaults if there are none, and saves them to the config file
+func (c *Config) SetColumns(layout string, specs []string) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return c.setColumns(path, layout, specs)
+}
+
+// setColumns is SetColumns for the config file at path. Only the file's
+// columns setting is rewritten; everything else is kept as it is.
+func (c *Config) setColumns(path, layout string, specs []string) error {
+	if layout != LayoutBrowser && layout != LayoutCommander {
+		return fmt.Errorf("unknown layout %q", layout)
+	}
+	columns, err := ParseColumns(specs)
+	if err != nil {
+		return err
+	}
+
+	configured := make(map[string][]string, len(c.Columns)+1)
+	for l, s := range c.Columns {
+		configured[l] = s
+	}
+	if len(specs) == 0 {
+		delete(configured, layout)
+	} else {
+		configured[layout] = specs
+	}
+	var value any
+	if len(configured) > 0 {
+		value = configured
+	}
+	if err := saveSetting(path, "columns", value); err != nil {
+		return err
+	}
+
+	c.Columns = configured
+	if c.columns == nil {
+		c.columns = make(map[string][]Column)
+	}
+	if len(specs) == 0 {
+		delete(c.columns, layout)
+	} else {
+		c.columns[layout] = columns
+	}
+	return nil
+}
@@ -0,0 +1,653 @@
+package config
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/security"
+)
+
+// Config holds user settings persisted at ~/.config/stui/config.json
+type Config struct {
+	Templates      []Template                 `json:"templates,omitempty"`
+	NamingPolicies []NamingPolicy             `json:"naming_policies,omitempty"`
+	Transfers      TransferSettings           `json:"transfers"`
+	Profiles       map[string]ProfileSettings `json:"profiles,omitempty"` // keyed by AWS profile name
+	Buckets        map[string]BucketSettings  `json:"buckets,omitempty"`  // keyed by bucket name
+	Guardrails     Guardrails                 `json:"guardrails"`
+	NotifyStale    bool                       `json:"notify_stale,omitempty"`    // desktop notification when a bookmark goes stale
+	NotifyRestored bool                       `json:"notify_restored,omitempty"` // desktop notification when a requested restore is ready
+	Columns        map[string][]string        `json:"columns,omitempty"`         // browser columns by layout, e.g. "size", "modified:16"
+	FIPS           bool                       `json:"fips,omitempty"`            // FIPS endpoints for S3 and STS where the region has them
+	ImageGraphics  string                     `json:"image_graphics,omitempty"`  // how image previews are drawn: auto (default), kitty, sixel or off
+	HiddenBuckets  []string                   `json:"hidden_buckets,omitempty"`  // left out of the bucket list unless hidden buckets are shown
+
+	columns map[string][]Column // Columns, parsed
+}
+
+// ProfileSettings are settings that apply only while using one AWS profile
+type ProfileSettings struct {
+	Encryption string `json:"encryption,omitempty"` // default, sse-s3, sse-kms or sse-kms:<key-arn>
+	FIPS       bool   `json:"fips,omitempty"`       // FIPS endpoints for this profile alone
+
+	encryption aws.Encryption // Encryption, parsed
+}
+
+// DefaultEncryption returns the profile's parsed Encryption
+func (p ProfileSettings) DefaultEncryption() aws.Encryption {
+	return p.encryption
+}
+
+// BucketSettings are settings that apply only while browsing one bucket
+type BucketSettings struct {
+	Delimiter string   `json:"delimiter,omitempty"` // groups keys into folders: "/" (default), another string, or "none"
+	Mirrors   []Mirror `json:"mirrors,omitempty"`   // other sources of the same objects; downloads are striped across them
+}
+
+// Mirror is another way to reach a bucket's objects, e.g. a replica bucket
+// in another region, or the same bucket through another profile or endpoint
+type Mirror struct {
+	Profile   string `json:"profile,omitempty"`    // AWS profile; "" uses the one stui runs with
+	Region    string `json:"region,omitempty"`     // "" uses the profile's
+	Bucket    string `json:"bucket,omitempty"`     // "" for the same bucket name
+	Endpoint  string `json:"endpoint,omitempty"`   // S3 endpoint URL; "" for AWS's
+	PathStyle bool   `json:"path_style,omitempty"` // path-style requests, which most non-AWS endpoints need
+}
+
+// Name describes the mirror in a few words, e.g. "replica-eu eu-west-1"
+func (m Mirror) Name() string {
+	var parts []string
+	if m.Endpoint != "" {
+		if u, err := url.Parse(m.Endpoint); err == nil && u.Host != "" {
+			parts = append(parts, u.Host)
+		}
+	}
+	for _, part := range []string{m.Profile, m.Region, m.Bucket} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// Validate checks the mirror names a source
+func (m Mirror) Validate() error {
+	if m.Profile == "" && m.Region == "" && m.Bucket == "" && m.Endpoint == "" {
+		return fmt.Errorf("mirror needs a profile, region, bucket or endpoint")
+	}
+	if m.Endpoint != "" {
+		u, err := url.Parse(m.Endpoint)
+		if err != nil || (u.Scheme != "https" && u.Scheme != "http") || u.Host == "" {
+			return fmt.Errorf("mirror endpoint %q is not an http(s) URL", m.Endpoint)
+		}
+	}
+	return nil
+}
+
+// How image previews are drawn, for Config.ImageGraphics
+const (
+	GraphicsAuto  = "auto"  // with whichever protocol the terminal is known to support
+	GraphicsKitty = "kitty" // with the kitty graphics protocol
+	GraphicsSixel = "sixel" // as sixels
+	GraphicsOff   = "off"   // not at all; only their size and EXIF data are shown
+)
+
+// DelimiterNone disables folder grouping, listing every key under a prefix
+const DelimiterNone = "none"
+
+// ListDelimiter returns the delimiter to list the bucket with, or "" for a
+// flat listing
+func (b BucketSettings) ListDelimiter() string {
+	switch b.Delimiter {
+	case "":
+		return "/"
+	case DelimiterNone:
+		return ""
+	}
+	return b.Delimiter
+}
+
+// TransferSettings tunes the download manager
+type TransferSettings struct {
+	StallTimeoutSeconds int    `json:"stall_timeout_seconds,omitempty"` // flag files idle this long (default 30)
+	RetryStalled        bool   `json:"retry_stalled,omitempty"`         // restart stalled files automatically
+	ScheduleOrder       string `json:"schedule_order,omitempty"`        // listing, largest-first or smallest-first
+	CollisionPolicy     string `json:"collision_policy,omitempty"`      // namespace or fail
+	PathPolicy          string `json:"path_policy,omitempty"`           // escape or strict, for keys invalid as local file names
+	Normalization       string `json:"unicode_normalization,omitempty"` // nfc (default), nfd or none, for sync name comparison
+	SymlinkPolicy       string `json:"symlink_policy,omitempty"`        // skip (default), follow or error, for sync scans and uploads
+	PreserveKeyPaths    bool   `json:"preserve_key_paths,omitempty"`    // default multi-downloads to the full key path
+	UploadPartSizeMB    int    `json:"upload_part_size_mb,omitempty"`   // multipart upload part size (default 10, min 5)
+	UploadConcurrency   int    `json:"upload_concurrency,omitempty"`    // parts uploaded in parallel per file (default 5)
+	MaxJobs             int    `json:"max_jobs,omitempty"`              // transfer jobs run at once; later ones queue (default 2)
+	UploadConflict      string `json:"upload_conflict,omitempty"`       // ask (default) or overwrite, when an upload's key exists
+	DownloadPreset      string `json:"download_preset,omitempty"`       // conservative, balanced (default) or aggressive part size and concurrency
+
+	UploadTags map[string]string `json:"upload_tags,omitempty"` // tags offered for uploads and applied to sync-up
+
+	// The settings above, parsed
+	order         download.ScheduleOrder
+	collisions    download.CollisionPolicy
+	pathPolicy    security.PathPolicy
+	normalization download.Normalization
+	symlinks      download.SymlinkPolicy
+	downloadOpts  aws.DownloadOptions
+}
+
+// Upload conflict policies, for uploads to a key that already exists
+const (
+	UploadConflictAsk       = "ask"       // offer to overwrite, skip or rename
+	UploadConflictOverwrite = "overwrite" // replace it without asking
+)
+
+// Multipart upload limits
+const (
+	MinUploadPartSizeMB = 5    // S3 minimum part size
+	MaxUploadPartSizeMB = 5120 // S3 maximum part size (5 GiB)
+)
+
+// StallTimeout returns the configured stall timeout, or 0 for the default
+func (t TransferSettings) StallTimeout() time.Duration {
+	return time.Duration(t.StallTimeoutSeconds) * time.Second
+}
+
+// UploadPartSize returns the configured part size in bytes, or 0 for the default
+func (t TransferSettings) UploadPartSize() int64 {
+	return int64(t.UploadPartSizeMB) * 1024 * 1024
+}
+
+// Order returns the parsed ScheduleOrder
+func (t TransferSettings) Order() download.ScheduleOrder {
+	return t.order
+}
+
+// Collisions returns the parsed CollisionPolicy
+func (t TransferSettings) Collisions() download.CollisionPolicy {
+	return t.collisions
+}
+
+// LocalPaths returns the parsed PathPolicy
+func (t TransferSettings) LocalPaths() security.PathPolicy {
+	return t.pathPolicy
+}
+
+// NameNormalization returns the parsed Normalization
+func (t TransferSettings) NameNormalization() download.Normalization {
+	return t.normalization
+}
+
+// Symlinks returns the parsed SymlinkPolicy
+func (t TransferSettings) Symlinks() download.SymlinkPolicy {
+	return t.symlinks
+}
+
+// DownloadOptions returns the part size and concurrency of DownloadPreset
+func (t TransferSettings) DownloadOptions() aws.DownloadOptions {
+	return t.downloadOpts
+}
+
+// Validate checks the transfer settings are within S3 limits
+func (t TransferSettings) Validate() error {
+	if t.UploadPartSizeMB != 0 && (t.UploadPartSizeMB < MinUploadPartSizeMB || t.UploadPartSizeMB > MaxUploadPartSizeMB) {
+		return fmt.Errorf("upload part size must be between %d and %d MB, got %d", MinUploadPartSizeMB, MaxUploadPartSizeMB, t.UploadPartSizeMB)
+	}
+	if t.UploadConcurrency < 0 {
+		return fmt.Errorf("upload concurrency must be positive, got %d", t.UploadConcurrency)
+	}
+	if t.MaxJobs < 0 {
+		return fmt.Errorf("max jobs must be positive, got %d", t.MaxJobs)
+	}
+	switch t.UploadConflict {
+	case "", UploadConflictAsk, UploadConflictOverwrite:
+	default:
+		return fmt.Errorf("unknown upload conflict policy %q (use ask or overwrite)", t.UploadConflict)
+	}
+	return nil
+}
+
+// parse parses the named settings into the values the accessors return
+func (t *TransferSettings) parse() error {
+	var err error
+	if t.order, err = download.ParseScheduleOrder(t.ScheduleOrder); err != nil {
+		return err
+	}
+	if t.collisions, err = download.ParseCollisionPolicy(t.CollisionPolicy); err != nil {
+		return err
+	}
+	if t.pathPolicy, err = security.ParsePathPolicy(t.PathPolicy); err != nil {
+		return err
+	}
+	if t.normalization, err = download.ParseNormalization(t.Normalization); err != nil {
+		return err
+	}
+	if t.symlinks, err = download.ParseSymlinkPolicy(t.SymlinkPolicy); err != nil {
+		return err
+	}
+	if t.downloadOpts, err = aws.ParseDownloadPreset(t.DownloadPreset); err != nil {
+		return err
+	}
+	if err := security.ValidTags(t.UploadTags); err != nil {
+		return fmt.Errorf("upload_tags: %w", err)
+	}
+	return nil
+}
+
+// Guardrails hold back operations that would touch more objects or data
+// than expected. They're checked once the objects an operation covers have
+// been listed, before anything is transferred or deleted.
+type Guardrails struct {
+	MaxObjects int     `json:"max_objects,omitempty"` // objects one operation may touch; 0 for no limit
+	MaxGB      float64 `json:"max_gb,omitempty"`      // gigabytes one operation may touch; 0 for no limit
+	Mode       string  `json:"mode,omitempty"`        // warn (default): confirm with y; confirm: type the object count
+}
+
+// Guardrail modes
+const (
+	GuardrailWarn    = "warn"
+	GuardrailConfirm = "confirm"
+)
+
+// Enabled returns true if any limit is set
+func (g Guardrails) Enabled() bool {
+	return g.MaxObjects > 0 || g.MaxGB > 0
+}
+
+// TypedConfirmation returns true if going over a limit needs the object
+// count typed rather than a y
+func (g Guardrails) TypedConfirmation() bool {
+	return g.Mode == GuardrailConfirm
+}
+
+// Check returns a *LimitExceeded if an operation touching objects objects
+// totalling bytes goes over a limit
+func (g Guardrails) Check(objects int, bytes int64) error {
+	overObjects := g.MaxObjects > 0 && objects > g.MaxObjects
+	overBytes := g.MaxGB > 0 && float64(bytes) > g.MaxGB*1e9
+	if !overObjects && !overBytes {
+		return nil
+	}
+	return &LimitExceeded{Objects: objects, Bytes: bytes, Limits: g}
+}
+
+// Validate checks the limits and mode are usable
+func (g Guardrails) Validate() error {
+	if g.MaxObjects < 0 || g.MaxGB < 0 {
+		return fmt.Errorf("guardrail limits must be positive")
+	}
+	switch g.Mode {
+	case "", GuardrailWarn, GuardrailConfirm:
+	default:
+		return fmt.Errorf("unknown guardrail mode %q (use warn or confirm)", g.Mode)
+	}
+	return nil
+}
+
+// LimitExceeded is returned when an operation goes over a guardrail
+type LimitExceeded struct {
+	Objects int
+	Bytes   int64
+	Limits  Guardrails
+}
+
+func (e *LimitExceeded) Error() string {
+	var limits []string
+	if e.Limits.MaxObjects > 0 {
+		limits = append(limits, fmt.Sprintf("%d objects", e.Limits.MaxObjects))
+	}
+	if e.Limits.MaxGB > 0 {
+		limits = append(limits, fmt.Sprintf("%g GB", e.Limits.MaxGB))
+	}
+	return fmt.Sprintf("%d objects, %.1f GB is over the limit of %s",
+		e.Objects, float64(e.Bytes)/1e9, strings.Join(limits, " or "))
+}
+
+// Template describes a skeleton object that can be created in a bucket
+type Template struct {
+	Name        string `json:"name"`
+	Key         string `json:"key"`  // default key name, relative to the current prefix
+	Body        string `json:"body"` // object content
+	ContentType string `json:"content_type,omitempty"`
+}
+
+// NamingPolicy constrains the keys that may be written to a bucket
+type NamingPolicy struct {
+	Bucket      string `json:"bucket"`                // bucket name, or "*" for every bucket
+	Pattern     string `json:"pattern"`               // regular expression the full key must match
+	Mode        string `json:"mode,omitempty"`        // "warn" (default) or "block"
+	Description string `json:"description,omitempty"` // shown when a key violates the policy
+
+	re *regexp.Regexp
+}
+
+// Policy modes
+const (
+	PolicyWarn  = "warn"
+	PolicyBlock = "block"
+)
+
+// PolicyViolation is returned when a key doesn't match a naming policy
+type PolicyViolation struct {
+	Policy NamingPolicy
+	Key    string
+}
+
+func (v *PolicyViolation) Error() string {
+	if v.Policy.Description != "" {
+		return fmt.Sprintf("key %q violates naming policy: %s", v.Key, v.Policy.Description)
+	}
+	return fmt.Sprintf("key %q does not match naming policy %s", v.Key, v.Policy.Pattern)
+}
+
+// Blocking returns true if the violation should prevent the write
+func (v *PolicyViolation) Blocking() bool {
+	return v.Policy.Mode == PolicyBlock
+}
+
+// DefaultTemplates are offered when the config file defines none
+var DefaultTemplates = []Template{
+	{
+		Name:        "manifest",
+		Key:         "manifest.json",
+		ContentType: "application/json",
+		Body: `{
+  "bucket": "{bucket}",
+  "prefix": "{prefix}",
+  "created": "{date}",
+  "owner": "",
+  "description": "",
+  "files": []
+}
+`,
+	},
+}
+
+// Default returns the default configuration
+func Default() *Config {
+	cfg := &Config{}
+	cfg.compile() // the zero settings are all valid
+	return cfg
+}
+
+// Dir returns the stui config directory, creating it if needed
+func Dir() (string, error) {
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", fmt.Errorf("failed to get home directory: %w", err)
+	}
+
+	configDir := filepath.Join(homeDir, ".config", "stui")
+	if err := os.MkdirAll(configDir, 0700); err != nil {
+		return "", fmt.Errorf("failed to create config directory: %w", err)
+	}
+
+	return configDir, nil
+}
+
+// Path returns the path of the config file
+func Path() (string, error) {
+	dir, err := Dir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(dir, "config.json"), nil
+}
+
+// Load reads the config file, returning defaults if it doesn't exist
+func Load() (*Config, error) {
+	path, err := Path()
+	if err != nil {
+		return nil, err
+	}
+	return LoadFile(path)
+}
+
+// saveSetting rewrites one setting of the config file at path, or removes
+// it if value is nil. Everything else in the file is kept as it is.
+func saveSetting(path, name string, value any) error {
+	raw := make(map[string]json.RawMessage)
+	data, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read config: %w", err)
+	}
+	if err == nil {
+		if err := json.Unmarshal(data, &raw); err != nil {
+			return fmt.Errorf("failed to parse config %s: %w", path, err)
+		}
+	}
+
+	if value == nil {
+		delete(raw, name)
+	} else {
+		encoded, err := json.Marshal(value)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s: %w", name, err)
+		}
+		raw[name] = encoded
+	}
+
+	data, err = json.MarshalIndent(raw, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal config: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write config: %w", err)
+	}
+	return nil
+}
+
+// LoadFile reads the config from a specific path
+func LoadFile(path string) (*Config, error) {
+	cfg := Default()
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return cfg, nil
+		}
+		return nil, fmt.Errorf("failed to read config: %w", err)
+	}
+
+	if err := json.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse config %s: %w", path, err)
+	}
+
+	if err := cfg.compile(); err != nil {
+		return nil, fmt.Errorf("invalid config %s: %w", path, err)
+	}
+
+	return cfg, nil
+}
+
+// compile validates and prepares settings that need preprocessing
+func (c *Config) compile() error {
+	if err := c.Transfers.Validate(); err != nil {
+		return err
+	}
+	if err := c.Transfers.parse(); err != nil {
+		return fmt.Errorf("transfers: %w", err)
+	}
+	for name, profile := range c.Profiles {
+		enc, err := aws.ParseEncryption(profile.Encryption)
+		if err != nil {
+			return fmt.Errorf("profile %s: %w", name, err)
+		}
+		profile.encryption = enc
+		c.Profiles[name] = profile
+	}
+	if err := c.Guardrails.Validate(); err != nil {
+		return err
+	}
+	switch c.ImageGraphics {
+	case "", GraphicsAuto, GraphicsKitty, GraphicsSixel, GraphicsOff:
+	default:
+		return fmt.Errorf("unknown image_graphics %q (use auto, kitty, sixel or off)", c.ImageGraphics)
+	}
+
+	for bucket, settings := range c.Buckets {
+		for i, mirror := range settings.Mirrors {
+			if err := mirror.Validate(); err != nil {
+				return fmt.Errorf("mirror %d of %s: %w", i+1, bucket, err)
+			}
+		}
+	}
+
+	c.columns = make(map[string][]Column, len(c.Columns))
+	for layout, specs := range c.Columns {
+		if layout != LayoutBrowser && layout != LayoutCommander {
+			return fmt.Errorf("columns: unknown layout %q (use %s or %s)", layout, LayoutBrowser, LayoutCommander)
+		}
+		columns, err := ParseColumns(specs)
+		if err != nil {
+			return fmt.Errorf("columns for %s: %w", layout, err)
+		}
+		c.columns[layout] = columns
+	}
+
+	for i := range c.NamingPolicies {
+		p := &c.NamingPolicies[i]
+		switch p.Mode {
+		case "":
+			p.Mode = PolicyWarn
+		case PolicyWarn, PolicyBlock:
+		default:
+			return fmt.Errorf("naming policy for %q: unknown mode %q", p.Bucket, p.Mode)
+		}
+
+		re, err := regexp.Compile(p.Pattern)
+		if err != nil {
+			return fmt.Errorf("naming policy for %q: %w", p.Bucket, err)
+		}
+		p.re = re
+	}
+	return nil
+}
+
+// CheckKey validates a key against the naming policies for a bucket.
+// It returns a *PolicyViolation for the first policy the key fails.
+func (c *Config) CheckKey(bucket, key string) error {
+	for _, p := range c.NamingPolicies {
+		if p.Bucket != "*" && p.Bucket != bucket {
+			continue
+		}
+		if p.re == nil || !p.re.MatchString(key) {
+			return &PolicyViolation{Policy: p, Key: key}
+		}
+	}
+	return nil
+}
+
+// CheckKeys checks every key a transfer would write to bucket against the
+// naming policies. The first blocking violation is returned as err; keys that
+// are only warned about are described in note, or note is "" if none are.
+func (c *Config) CheckKeys(bucket string, keys []string) (note string, err error) {
+	var warnings []error
+	for _, key := range keys {
+		var v *PolicyViolation
+		if err := c.CheckKey(bucket, key); errors.As(err, &v) {
+			if v.Blocking() {
+				return "", err
+			}
+			warnings = append(warnings, err)
+		}
+	}
+	switch len(warnings) {
+	case 0:
+		return "", nil
+	case 1:
+		return "Naming: " + warnings[0].Error(), nil
+	default:
+		return fmt.Sprintf("Naming: %d keys break naming policies, e.g. %s", len(warnings), warnings[0]), nil
+	}
+}
+
+// BucketHidden reports whether a bucket is left out of the bucket list
+func (c *Config) BucketHidden(bucket string) bool {
+	return slices.Contains(c.HiddenBuckets, bucket)
+}
+
+// SetBucketHidden hides a bucket from the bucket list, or shows it again,
+// and saves the hidden buckets to the config file
+func (c *Config) SetBucketHidden(bucket string, hidden bool) error {
+	path, err := Path()
+	if err != nil {
+		return err
+	}
+	return c.setBucketHidden(path, bucket, hidden)
+}
+
+// setBucketHidden is SetBucketHidden for the config file at path
+func (c *Config) setBucketHidden(path, bucket string, hidden bool) error {
+	names := slices.DeleteFunc(slices.Clone(c.HiddenBuckets), func(b string) bool { return b == bucket })
+	if hidden {
+		names = append(names, bucket)
+		slices.Sort(names)
+	}
+	var value any
+	if len(names) > 0 {
+		value = names
+	}
+	if err := saveSetting(path, "hidden_buckets", value); err != nil {
+		return err
+	}
+	c.HiddenBuckets = names
+	return nil
+}
+
+// ForProfile returns the settings for an AWS profile. The empty
+// profile name means the SDK's default profile.
+func (c *Config) ForProfile(profile string) ProfileSettings {
+	if profile == "" {
+		profile = "default"
+	}
+	return c.Profiles[profile]
+}
+
+// UseFIPS reports whether a profile should use FIPS endpoints, because
+// they're on for every profile or for that one
+func (c *Config) UseFIPS(profile string) bool {
+	return c.FIPS || c.ForProfile(profile).FIPS
+}
+
+// ForBucket returns the settings for a bucket
+func (c *Config) ForBucket(bucket string) BucketSettings {
+	return c.Buckets[bucket]
+}
+
+// ObjectTemplates returns the configured templates, or the defaults if none are set
+func (c *Config) ObjectTemplates() []Template {
+	if len(c.Templates) > 0 {
+		return c.Templates
+	}
+	return DefaultTemplates
+}
+
+// FindTemplate returns the template with the given name
+func (c *Config) FindTemplate(name string) (Template, bool) {
+	for _, t := range c.ObjectTemplates() {
+		if t.Name == name {
+			return t, true
+		}
+	}
+	return Template{}, false
+}
+
+// Render substitutes {bucket}, {prefix} and {date} placeholders in the
+// template key and body
+func (t Template) Render(bucket, prefix string, now time.Time) (key, body string) {
+	r := strings.NewReplacer(
+		"{bucket}", bucket,
+		"{prefix}", prefix,
+		"{date}", now.Format("2006-01-02"),
+	)
+	return r.Replace(t.Key), r.Replace(t.Body)
+}
@@ -0,0 +1,455 @@
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/security"
+)
+
+func TestLoadFileMissing(t *testing.T) {
+	cfg, err := LoadFile(filepath.Join(t.TempDir(), "missing.json"))
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	if len(cfg.ObjectTemplates()) != len(DefaultTemplates) {
+		t.Errorf("expected default templates, got %d", len(cfg.ObjectTemplates()))
+	}
+}
+
+func TestLoadFileTemplates(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"templates": [{"name": "readme", "key": "README-{date}.md", "body": "# {bucket}/{prefix}"}]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tmpl, ok := cfg.FindTemplate("readme")
+	if !ok {
+		t.Fatal("template 'readme' not found")
+	}
+	if _, ok := cfg.FindTemplate("manifest"); ok {
+		t.Error("default templates should not be offered when templates are configured")
+	}
+
+	key, body := tmpl.Render("my-bucket", "data/", time.Date(2024, 3, 9, 0, 0, 0, 0, time.UTC))
+	if key != "README-2024-03-09.md" {
+		t.Errorf("Render() key = %q", key)
+	}
+	if body != "# my-bucket/data/" {
+		t.Errorf("Render() body = %q", body)
+	}
+}
+
+func TestLoadFileInvalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte("{not json"), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for invalid config")
+	}
+}
+
+func TestCheckKey(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"naming_policies": [
+		{"bucket": "lake", "pattern": "^(raw|curated)/dt=\\d{4}-\\d{2}-\\d{2}/", "mode": "block"},
+		{"bucket": "*", "pattern": "^[a-z0-9/._=-]+$"}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		bucket   string
+		key      string
+		wantErr  bool
+		blocking bool
+	}{
+		{"matches both", "lake", "raw/dt=2024-01-02/part-0.parquet", false, false},
+		{"violates block policy", "lake", "tmp/file.csv", true, true},
+		{"violates warn policy", "other", "Upper.CSV", true, false},
+		{"other bucket ok", "other", "tmp/file.csv", false, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := cfg.CheckKey(tt.bucket, tt.key)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("CheckKey(%q, %q) error = %v, wantErr %v", tt.bucket, tt.key, err, tt.wantErr)
+			}
+			var v *PolicyViolation
+			if err != nil && errors.As(err, &v) && v.Blocking() != tt.blocking {
+				t.Errorf("Blocking() = %v, want %v", v.Blocking(), tt.blocking)
+			}
+		})
+	}
+}
+
+func TestCheckKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"naming_policies": [
+		{"bucket": "lake", "pattern": "^raw/", "mode": "block"},
+		{"bucket": "*", "pattern": "^[a-z/.]+$"}
+	]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if note, err := cfg.CheckKeys("lake", []string{"raw/a.csv", "raw/b.csv"}); note != "" || err != nil {
+		t.Errorf("CheckKeys() of good keys = %q, %v", note, err)
+	}
+	if _, err := cfg.CheckKeys("lake", []string{"raw/a.csv", "tmp/b.csv"}); err == nil {
+		t.Error("CheckKeys() let a blocked key through")
+	}
+	note, err := cfg.CheckKeys("other", []string{"A.csv", "B.csv", "c.csv"})
+	if err != nil || !strings.HasPrefix(note, "Naming: 2 keys break naming policies") {
+		t.Errorf("CheckKeys() of keys only warned about = %q, %v", note, err)
+	}
+}
+
+func TestInvalidNamingPolicy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"naming_policies": [{"bucket": "*", "pattern": "("}]}`), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for invalid pattern")
+	}
+}
+
+func TestInvalidImageGraphics(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"image_graphics": "iterm"}`), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	if _, err := LoadFile(path); err == nil {
+		t.Error("expected error for unknown image graphics")
+	}
+}
+
+func TestTransferSettingsValidate(t *testing.T) {
+	tests := []struct {
+		name     string
+		settings TransferSettings
+		wantErr  bool
+	}{
+		{"defaults", TransferSettings{}, false},
+		{"valid part size", TransferSettings{UploadPartSizeMB: 64, UploadConcurrency: 8}, false},
+		{"part size too small", TransferSettings{UploadPartSizeMB: 1}, true},
+		{"part size too large", TransferSettings{UploadPartSizeMB: 6000}, true},
+		{"negative concurrency", TransferSettings{UploadConcurrency: -1}, true},
+		{"overwrite conflicts", TransferSettings{UploadConflict: UploadConflictOverwrite}, false},
+		{"unknown conflict policy", TransferSettings{UploadConflict: "rename"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.settings.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoadFileParsesTransfers(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{
+		"transfers": {"schedule_order": "largest-first", "collision_policy": "fail", "path_policy": "strict",
+			"unicode_normalization": "none", "symlink_policy": "follow", "download_preset": "aggressive"},
+		"profiles": {"prod": {"encryption": "sse-kms:alias/prod"}}
+	}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	transfers := cfg.Transfers
+	if transfers.Order() != download.OrderLargestFirst || transfers.Collisions() != download.CollisionFail ||
+		transfers.LocalPaths() != security.PathStrict || transfers.NameNormalization() != download.NormalizeNone ||
+		transfers.Symlinks() != download.SymlinkFollow {
+		t.Errorf("parsed transfers = %+v", transfers)
+	}
+	if opts := transfers.DownloadOptions(); opts != aws.DownloadPresets[2].DownloadOptions {
+		t.Errorf("DownloadOptions() = %+v, want the aggressive preset", opts)
+	}
+	if enc := cfg.ForProfile("prod").DefaultEncryption(); enc.KMSKeyID != "alias/prod" {
+		t.Errorf("DefaultEncryption() = %+v", enc)
+	}
+
+	// The defaults when nothing is set
+	if opts := Default().Transfers.DownloadOptions(); opts != aws.DownloadPresets[1].DownloadOptions {
+		t.Errorf("default DownloadOptions() = %+v, want the balanced preset", opts)
+	}
+}
+
+func TestLoadFileInvalidTransfers(t *testing.T) {
+	for _, data := range []string{
+		`{"transfers": {"schedule_order": "newest-first"}}`,
+		`{"transfers": {"collision_policy": "overwrite"}}`,
+		`{"transfers": {"path_policy": "lax"}}`,
+		`{"transfers": {"unicode_normalization": "nfkc"}}`,
+		`{"transfers": {"symlink_policy": "copy"}}`,
+		`{"transfers": {"download_preset": "turbo"}}`,
+		`{"transfers": {"upload_tags": {"": "x"}}}`,
+		`{"profiles": {"prod": {"encryption": "sse-c"}}}`,
+	} {
+		path := filepath.Join(t.TempDir(), "config.json")
+		if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+			t.Fatalf("failed to write config: %v", err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("LoadFile(%s) succeeded", data)
+		}
+	}
+}
+
+func TestBucketListDelimiter(t *testing.T) {
+	cfg := &Config{Buckets: map[string]BucketSettings{
+		"logs":  {Delimiter: "_"},
+		"flat":  {Delimiter: DelimiterNone},
+		"plain": {},
+	}}
+
+	tests := []struct {
+		bucket string
+		want   string
+	}{
+		{"logs", "_"},
+		{"flat", ""},
+		{"plain", "/"},
+		{"unconfigured", "/"},
+	}
+
+	for _, tt := range tests {
+		if got := cfg.ForBucket(tt.bucket).ListDelimiter(); got != tt.want {
+			t.Errorf("ForBucket(%q).ListDelimiter() = %q, want %q", tt.bucket, got, tt.want)
+		}
+	}
+}
+
+func TestGuardrailsCheck(t *testing.T) {
+	g := Guardrails{MaxObjects: 100, MaxGB: 1.5}
+
+	tests := []struct {
+		name    string
+		objects int
+		bytes   int64
+		wantErr bool
+	}{
+		{"under both", 100, 1_500_000_000, false},
+		{"too many objects", 101, 1, true},
+		{"too many bytes", 1, 1_500_000_001, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := g.Check(tt.objects, tt.bytes)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Check() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			var exceeded *LimitExceeded
+			if tt.wantErr && !errors.As(err, &exceeded) {
+				t.Errorf("Check() error = %T, want *LimitExceeded", err)
+			}
+		})
+	}
+
+	if err := (Guardrails{}).Check(1_000_000, 1<<50); err != nil {
+		t.Errorf("Check() without limits = %v, want nil", err)
+	}
+}
+
+func TestGuardrailsValidate(t *testing.T) {
+	tests := []struct {
+		name       string
+		guardrails Guardrails
+		wantErr    bool
+	}{
+		{"none", Guardrails{}, false},
+		{"confirm", Guardrails{MaxObjects: 10, Mode: GuardrailConfirm}, false},
+		{"negative limit", Guardrails{MaxGB: -1}, true},
+		{"unknown mode", Guardrails{MaxObjects: 10, Mode: "block"}, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.guardrails.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestParseColumns(t *testing.T) {
+	columns, err := ParseColumns([]string{"size", " Modified:16", "etag:8"})
+	if err != nil {
+		t.Fatalf("ParseColumns() error = %v", err)
+	}
+	want := []Column{{Field: "size"}, {Field: "modified", Width: 16}, {Field: "etag", Width: 8}}
+	if len(columns) != len(want) {
+		t.Fatalf("ParseColumns() = %v, want %v", columns, want)
+	}
+	for i := range want {
+		if columns[i] != want[i] {
+			t.Errorf("column %d = %v, want %v", i, columns[i], want[i])
+		}
+	}
+
+	for _, bad := range []string{"checksum", "size:0", "size:wide"} {
+		if _, err := ParseColumns([]string{bad}); err == nil {
+			t.Errorf("ParseColumns(%q) should fail", bad)
+		}
+	}
+}
+
+func TestSetColumns(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"guardrails": {"max_objects": 10}, "columns": {"browser": ["size"]}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	if err := cfg.setColumns(path, LayoutCommander, []string{"size:8", "class"}); err != nil {
+		t.Fatalf("setColumns() error = %v", err)
+	}
+	if err := cfg.setColumns(path, LayoutCommander, []string{"bogus"}); err == nil {
+		t.Error("expected an unknown column to be rejected")
+	}
+	if got := cfg.ColumnsFor(LayoutCommander); len(got) != 2 || got[0].Width != 8 {
+		t.Errorf("ColumnsFor(commander) = %v", got)
+	}
+
+	reloaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() after save error = %v", err)
+	}
+	if reloaded.Guardrails.MaxObjects != 10 {
+		t.Error("other settings should be kept")
+	}
+	if got := reloaded.ColumnsFor(LayoutBrowser); len(got) != 1 || got[0].Field != "size" {
+		t.Errorf("ColumnsFor(browser) after reload = %v", got)
+	}
+	if got := reloaded.ColumnsFor(LayoutCommander); len(got) != 2 || got[1].Field != "class" {
+		t.Errorf("ColumnsFor(commander) after reload = %v", got)
+	}
+
+	// No columns go back to the defaults
+	if err := reloaded.setColumns(path, LayoutBrowser, nil); err != nil {
+		t.Fatalf("setColumns() error = %v", err)
+	}
+	if reloaded.ColumnsFor(LayoutBrowser) != nil {
+		t.Error("expected browser columns to be reset")
+	}
+}
+
+func TestSetBucketHidden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	if err := os.WriteFile(path, []byte(`{"notify_stale": true}`), 0600); err != nil {
+		t.Fatalf("failed to write config: %v", err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+
+	for _, bucket := range []string{"old-logs", "archive", "old-logs"} {
+		if err := cfg.setBucketHidden(path, bucket, true); err != nil {
+			t.Fatalf("setBucketHidden(%q) error = %v", bucket, err)
+		}
+	}
+	reloaded, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() after save error = %v", err)
+	}
+	if !reloaded.NotifyStale {
+		t.Error("other settings should be kept")
+	}
+	if want := []string{"archive", "old-logs"}; !slices.Equal(reloaded.HiddenBuckets, want) {
+		t.Errorf("HiddenBuckets = %v, want %v", reloaded.HiddenBuckets, want)
+	}
+	if !reloaded.BucketHidden("archive") || reloaded.BucketHidden("data") {
+		t.Error("BucketHidden() should report only hidden buckets")
+	}
+
+	// Showing every bucket again drops the setting
+	for _, bucket := range []string{"archive", "old-logs"} {
+		if err := reloaded.setBucketHidden(path, bucket, false); err != nil {
+			t.Fatalf("setBucketHidden(%q) error = %v", bucket, err)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("failed to read config: %v", err)
+	}
+	if strings.Contains(string(data), "hidden_buckets") {
+		t.Errorf("config should have no hidden buckets left:\n%s", data)
+	}
+}
+
+func TestMirrors(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"buckets": {"datasets": {"mirrors": [
+		{"profile": "replica", "region": "eu-west-1", "bucket": "datasets-eu"},
+		{"endpoint": "https://cache.internal:9000", "path_style": true}
+	]}}}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := LoadFile(path)
+	if err != nil {
+		t.Fatalf("LoadFile() error = %v", err)
+	}
+	mirrors := cfg.ForBucket("datasets").Mirrors
+	if len(mirrors) != 2 {
+		t.Fatalf("got %d mirrors, want 2", len(mirrors))
+	}
+	if got := mirrors[0].Name(); got != "replica eu-west-1 datasets-eu" {
+		t.Errorf("Name() = %q", got)
+	}
+	if got := mirrors[1].Name(); got != "cache.internal:9000" {
+		t.Errorf("Name() = %q", got)
+	}
+
+	for _, bad := range []string{
+		`{"buckets": {"datasets": {"mirrors": [{}]}}}`,
+		`{"buckets": {"datasets": {"mirrors": [{"endpoint": "cache.internal"}]}}}`,
+	} {
+		if err := os.WriteFile(path, []byte(bad), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := LoadFile(path); err == nil {
+			t.Errorf("LoadFile(%s) succeeded", bad)
+		}
+	}
+}
@@ -0,0 +1,51 @@
+package config
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/download"
+)
+
+// ConnectMirrors creates clients for the mirrors configured for the given
+// buckets, or every bucket if none are given. Mirrors without a profile or
+// region of their own use profile and region.
+func (c *Config) ConnectMirrors(ctx context.Context, profile, region string, buckets ...string) (download.Mirrors, error) {
+	mirrors := make(download.Mirrors)
+	for bucket, settings := range c.Buckets {
+		if len(buckets) > 0 && !slices.Contains(buckets, bucket) {
+			continue
+		}
+		for _, mc := range settings.Mirrors {
+			p, r := profile, region
+			if mc.Profile != "" {
+				p, r = mc.Profile, ""
+			}
+			if mc.Region != "" {
+				r = mc.Region
+			}
+			client, err := aws.NewClient(ctx, p, r, aws.EndpointOptions{
+				FIPS:      c.UseFIPS(p),
+				URL:       mc.Endpoint,
+				PathStyle: mc.PathStyle,
+			})
+			if err != nil {
+				return nil, fmt.Errorf("mirror %s of %s: %w", mc.Name(), bucket, err)
+			}
+			name := bucket
+			if mc.Bucket != "" {
+				name = mc.Bucket
+			}
+			if r == "" && mc.Endpoint == "" {
+				// A replica is usually in another region than the profile's
+				if regional, err := client.ForBucket(ctx, name); err == nil {
+					client = regional
+				}
+			}
+			mirrors[bucket] = append(mirrors[bucket], download.Mirror{Name: mc.Name(), Client: client, Bucket: name})
+		}
+	}
+	return mirrors, nil
+}
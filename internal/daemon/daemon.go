@@ -0,0 +1,304 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/jobs"
+	"github.com/natevick/stui/internal/security"
+)
+
+// IDFlag is the run-template flag that makes a run record its progress
+// as the background job with the given ID
+const IDFlag = "detached-id"
+
+// reportInterval is how often a background job writes its progress
+const reportInterval = time.Second
+
+// pendingTimeout is how long a started job has to begin before it's taken
+// to have failed, e.g. over a bad flag, even if its process lingers
+const pendingTimeout = time.Minute
+
+// State is the progress of a saved job running in the background, as the
+// process running it last recorded it
+type State struct {
+	ID             string             `json:"id"`
+	PID            int                `json:"pid"`
+	Name           string             `json:"name"` // the saved job's
+	Description    string             `json:"description"`
+	Direction      download.Direction `json:"direction"`
+	Sync           bool               `json:"sync,omitempty"`
+	Status         download.Status    `json:"status"`
+	TotalFiles     int                `json:"total_files"`
+	CompletedFiles int                `json:"completed_files"`
+	FailedFiles    int                `json:"failed_files"`
+	TotalBytes     int64              `json:"total_bytes"`
+	Bytes          int64              `json:"bytes"`
+	Throughput     float64            `json:"throughput,omitempty"` // bytes per second
+	Err            string             `json:"error,omitempty"`
+	Log            string             `json:"log"` // the process's output
+	StartedAt      time.Time          `json:"started_at"`
+	UpdatedAt      time.Time          `json:"updated_at"`
+}
+
+// Running returns true until the job finishes
+func (s State) Running() bool {
+	return s.Status == download.StatusPending || s.Status == download.StatusInProgress
+}
+
+// Store keeps the state of background jobs, one file each, in the config
+// directory, where a later stui can pick them up
+type Store struct {
+	dir string
+}
+
+// NewStore creates a store in the config directory
+func NewStore() (*Store, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(configDir, "background")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create background job directory: %w", err)
+	}
+	return &Store{dir: dir}, nil
+}
+
+// Start runs a saved job in a new stui process that outlives this one.
+// flags are the global flags to run it with, e.g. -profile. The job is
+// listed as pending until the new process begins it, so one that fails
+// before then is still listed, with its log.
+func (s *Store) Start(job jobs.Job, flags []string) (State, error) {
+	exe, err := os.Executable()
+	if err != nil {
+		return State{}, fmt.Errorf("failed to find the stui executable: %w", err)
+	}
+
+	id := strconv.FormatInt(time.Now().UnixNano(), 36)
+	logPath := filepath.Join(s.dir, id+".log")
+	logFile, err := os.OpenFile(logPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return State{}, fmt.Errorf("failed to create log file: %w", err)
+	}
+	defer logFile.Close()
+
+	args := append(slices.Clone(flags), "run-template", "-"+IDFlag, id, job.Name)
+	cmd := exec.Command(exe, args...)
+	cmd.Stdout = logFile
+	cmd.Stderr = logFile
+	detach(cmd)
+	if err := cmd.Start(); err != nil {
+		return State{}, fmt.Errorf("failed to start background job: %w", err)
+	}
+	state := State{
+		ID:          id,
+		PID:         cmd.Process.Pid,
+		Name:        job.Name,
+		Description: job.Describe(),
+		Direction:   job.Direction(),
+		Sync:        job.IsSync(),
+		Status:      download.StatusPending,
+		Log:         logPath,
+		StartedAt:   time.Now(),
+	}
+	if err := s.savePending(state); err != nil {
+		return state, err
+	}
+	// Not waited for: it carries on after this process exits
+	if err := cmd.Process.Release(); err != nil {
+		return state, fmt.Errorf("failed to detach background job: %w", err)
+	}
+	return state, nil
+}
+
+// savePending records a job Start launched, unless its process has already
+// recorded that it began
+func (s *Store) savePending(state State) error {
+	state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal background job: %w", err)
+	}
+	path := filepath.Join(s.dir, state.ID+".json")
+	tmp := path + ".pending"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write background job: %w", err)
+	}
+	defer os.Remove(tmp)
+	// Unlike a rename, a link never replaces the state the job recorded
+	if err := os.Link(tmp, path); err != nil && !os.IsExist(err) {
+		return fmt.Errorf("failed to write background job: %w", err)
+	}
+	return nil
+}
+
+// Begin records that this process is running a saved job as the
+// background job with id, and returns its state to report progress on
+func (s *Store) Begin(id string, job jobs.Job) (State, error) {
+	if !validID(id) {
+		return State{}, fmt.Errorf("invalid background job ID %q", id)
+	}
+	state := State{
+		ID:          id,
+		PID:         os.Getpid(),
+		Name:        job.Name,
+		Description: job.Describe(),
+		Direction:   job.Direction(),
+		Sync:        job.IsSync(),
+		Status:      download.StatusInProgress,
+		Log:         filepath.Join(s.dir, id+".log"),
+		StartedAt:   time.Now(),
+	}
+	return state, s.Save(state)
+}
+
+// Reporter returns a progress callback that records a background job's
+// progress, at most once every reportInterval
+func (s *Store) Reporter(state State) func(download.Progress) {
+	var mu sync.Mutex
+	var last time.Time
+	return func(p download.Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		if time.Since(last) < reportInterval {
+			return
+		}
+		last = time.Now()
+		// Progress is best effort; the final state is what matters
+		_ = s.Save(withProgress(state, p))
+	}
+}
+
+// Finish records how a background job ended
+func (s *Store) Finish(state State, p download.Progress, err error) error {
+	state = withProgress(state, p)
+	state.Throughput = 0
+	switch {
+	case errors.Is(err, context.Canceled):
+		state.Status = download.StatusCancelled
+	case err != nil:
+		state.Status = download.StatusFailed
+		state.Err = security.SanitizeError(err)
+	case p.FailedFiles > 0:
+		state.Status = download.StatusFailed
+		state.Err = fmt.Sprintf("%d of %d files failed", p.FailedFiles, p.TotalFiles)
+	default:
+		state.Status = download.StatusCompleted
+	}
+	return s.Save(state)
+}
+
+// withProgress copies a manager's progress into state
+func withProgress(state State, p download.Progress) State {
+	state.TotalFiles = p.TotalFiles
+	state.CompletedFiles = p.CompletedFiles
+	state.FailedFiles = p.FailedFiles
+	state.TotalBytes = p.TotalBytes
+	state.Bytes = p.DownloadedBytes
+	state.Throughput = p.Throughput
+	return state
+}
+
+// Save writes a job's state, replacing the previous one in one step so a
+// reader never sees half of it
+func (s *Store) Save(state State) error {
+	state.UpdatedAt = time.Now()
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal background job: %w", err)
+	}
+	path := filepath.Join(s.dir, state.ID+".json")
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write background job: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write background job: %w", err)
+	}
+	return nil
+}
+
+// List returns every background job, oldest first. A job whose process
+// died without recording the end of it, or that never began, is reported
+// as failed.
+func (s *Store) List() ([]State, error) {
+	paths, err := filepath.Glob(filepath.Join(s.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+	var states []State
+	for _, path := range paths {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			if os.IsNotExist(err) {
+				continue // removed since the glob
+			}
+			return nil, fmt.Errorf("failed to read background job: %w", err)
+		}
+		var state State
+		if err := json.Unmarshal(data, &state); err != nil {
+			return nil, fmt.Errorf("failed to parse %s: %w", filepath.Base(path), err)
+		}
+		switch {
+		case state.Running() && !alive(state.PID):
+			state.Status = download.StatusFailed
+			state.Err = "stopped unexpectedly; see " + state.Log
+		case state.Status == download.StatusPending && time.Since(state.UpdatedAt) > pendingTimeout:
+			state.Status = download.StatusFailed
+			state.Err = "never started; see " + state.Log
+		}
+		states = append(states, state)
+	}
+	slices.SortFunc(states, func(a, b State) int { return a.StartedAt.Compare(b.StartedAt) })
+	return states, nil
+}
+
+// Remove deletes a finished job's state and log
+func (s *Store) Remove(id string) error {
+	if !validID(id) {
+		return fmt.Errorf("invalid background job ID %q", id)
+	}
+	for _, name := range []string{id + ".json", id + ".log"} {
+		if err := os.Remove(filepath.Join(s.dir, name)); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove background job: %w", err)
+		}
+	}
+	return nil
+}
+
+// Stop asks a background job's process to cancel the job. It isn't
+// supported on Windows.
+func Stop(state State) error {
+	if runtime.GOOS == "windows" {
+		return fmt.Errorf("stopping background jobs isn't supported on %s; end process %d instead", runtime.GOOS, state.PID)
+	}
+	p, err := os.FindProcess(state.PID)
+	if err != nil {
+		return err
+	}
+	if err := p.Signal(syscall.SIGTERM); err != nil {
+		return fmt.Errorf("failed to stop background job: %w", err)
+	}
+	return nil
+}
+
+// validID reports whether id could have come from Start, so it's safe to
+// use in a file name
+func validID(id string) bool {
+	return id != "" && strings.Trim(id, "0123456789abcdefghijklmnopqrstuvwxyz") == ""
+}
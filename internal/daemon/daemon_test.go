@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/jobs"
+)
+
+func TestStoreLifecycle(t *testing.T) {
+	store := &Store{dir: t.TempDir()}
+	job := jobs.Job{Name: "nightly", Kind: jobs.KindSync, Bucket: "logs", Prefix: "app/", LocalDir: "/data/logs"}
+
+	state, err := store.Begin("abc123", job)
+	if err != nil {
+		t.Fatalf("Begin() error = %v", err)
+	}
+	if _, err := store.Begin("../escape", job); err == nil {
+		t.Error("Begin() accepted an ID that isn't a file name")
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatalf("List() error = %v", err)
+	}
+	if len(list) != 1 || list[0].Name != "nightly" || !list[0].Running() {
+		t.Fatalf("List() = %+v, want the running job", list)
+	}
+
+	p := download.Progress{TotalFiles: 4, CompletedFiles: 3, FailedFiles: 1, TotalBytes: 400, DownloadedBytes: 300}
+	if err := store.Finish(state, p, nil); err != nil {
+		t.Fatalf("Finish() error = %v", err)
+	}
+	list, _ = store.List()
+	if got := list[0]; got.Status != download.StatusFailed || got.Err == "" || got.CompletedFiles != 3 || got.Bytes != 300 {
+		t.Errorf("finished job = %+v, want failed with 3 files done", got)
+	}
+
+	if err := store.Finish(state, p, context.Canceled); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ = store.List(); list[0].Status != download.StatusCancelled {
+		t.Errorf("cancelled job status = %v", list[0].Status)
+	}
+
+	if err := store.Remove(state.ID); err != nil {
+		t.Fatalf("Remove() error = %v", err)
+	}
+	if list, _ = store.List(); len(list) != 0 {
+		t.Errorf("List() after Remove = %+v", list)
+	}
+}
+
+func TestStoreReportsDeadProcesses(t *testing.T) {
+	store := &Store{dir: t.TempDir()}
+
+	// A process that has already exited
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	if err := cmd.Run(); err != nil {
+		var exitErr *exec.ExitError
+		if !errors.As(err, &exitErr) {
+			t.Fatal(err)
+		}
+	}
+	dead := State{ID: "dead", PID: cmd.Process.Pid, Status: download.StatusInProgress}
+	alive := State{ID: "alive", PID: os.Getpid(), Status: download.StatusInProgress}
+	for _, s := range []State{dead, alive} {
+		if err := store.Save(s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range list {
+		switch s.ID {
+		case "dead":
+			if s.Running() || s.Err == "" {
+				t.Errorf("job whose process exited = %+v, want failed", s)
+			}
+		case "alive":
+			if !s.Running() {
+				t.Errorf("job whose process is running = %+v, want running", s)
+			}
+		}
+	}
+}
+
+func TestStorePendingJobs(t *testing.T) {
+	store := &Store{dir: t.TempDir()}
+	job := jobs.Job{Name: "nightly", Kind: jobs.KindSync, Bucket: "logs", LocalDir: "/data/logs"}
+
+	// A job its process has already begun keeps the state it recorded
+	if _, err := store.Begin("begun", job); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.savePending(State{ID: "begun", PID: os.Getpid(), Status: download.StatusPending}); err != nil {
+		t.Fatalf("savePending() error = %v", err)
+	}
+
+	// A job that hasn't begun yet is listed as pending, until it's been
+	// pending too long
+	if err := store.savePending(State{ID: "waiting", PID: os.Getpid(), Status: download.StatusPending}); err != nil {
+		t.Fatal(err)
+	}
+	stale, err := json.Marshal(State{ID: "stale", PID: os.Getpid(), Status: download.StatusPending, UpdatedAt: time.Now().Add(-2 * pendingTimeout)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(store.dir, "stale.json"), stale, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	list, err := store.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]download.Status{"begun": download.StatusInProgress, "waiting": download.StatusPending, "stale": download.StatusFailed}
+	if len(list) != len(want) {
+		t.Fatalf("List() = %+v, want %d jobs", list, len(want))
+	}
+	for _, s := range list {
+		if s.Status != want[s.ID] {
+			t.Errorf("job %s is %s, want %s", s.ID, s.Status, want[s.ID])
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package daemon
+
+import (
+	"os/exec"
+	"syscall"
+)
+
+// stillActive is the exit code Windows reports for a running process
+const stillActive = 259
+
+// detach does nothing on Windows, where a child already outlives the
+// console that started it
+func detach(cmd *exec.Cmd) {}
+
+// alive reports whether the process with pid is still running
+func alive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	h, err := syscall.OpenProcess(syscall.PROCESS_QUERY_INFORMATION, false, uint32(pid))
+	if err != nil {
+		return false
+	}
+	defer syscall.CloseHandle(h)
+	var code uint32
+	if err := syscall.GetExitCodeProcess(h, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,450 @@
+package doctor
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"runtime"
+	"runtime/debug"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/term"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/bookmarks"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/security"
+)
+
+// checkTimeout bounds each connectivity check
+const checkTimeout = 15 * time.Second
+
+// maxProfiles is how many profiles are listed one by one
+const maxProfiles = 20
+
+// Status is how a check went
+type Status int
+
+const (
+	StatusInfo Status = iota // nothing to judge, e.g. a version
+	StatusOK
+	StatusWarn
+	StatusFail
+)
+
+// Check is one line of a report
+type Check struct {
+	Name   string
+	Detail string
+	Status Status
+}
+
+// Section is a titled group of checks
+type Section struct {
+	Title  string
+	Checks []Check
+}
+
+func (s *Section) add(status Status, name, detail string) {
+	s.Checks = append(s.Checks, Check{Name: name, Detail: detail, Status: status})
+}
+
+// Options are what the local checks need to know about how stui runs
+type Options struct {
+	Version  string
+	Profile  string // "" for the default
+	Graphics string // how previews draw images, as detected
+}
+
+// Version describes the build: stui's version, then the commit it was
+// built from when Go recorded one, and the Go version and platform
+func Version(version string) string {
+	details := []string{runtime.Version(), runtime.GOOS + "/" + runtime.GOARCH}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		var revision, modified string
+		for _, s := range info.Settings {
+			switch s.Key {
+			case "vcs.revision":
+				revision = s.Value[:min(len(s.Value), 12)]
+			case "vcs.modified":
+				if s.Value == "true" {
+					modified = ", modified"
+				}
+			}
+		}
+		if revision != "" {
+			details = append([]string{revision + modified}, details...)
+		}
+	}
+	return fmt.Sprintf("stui version %s (%s)", version, strings.Join(details, ", "))
+}
+
+// Local runs the checks that need no network: the build, stui's and AWS's
+// config files, the profiles found and the terminal
+func Local(opts Options) []Section {
+	return []Section{build(opts.Version), files(), profiles(opts.Profile), terminal(opts.Graphics)}
+}
+
+// build reports the versions stui was built with
+func build(version string) Section {
+	s := Section{Title: "Build"}
+	s.add(StatusInfo, "stui", strings.TrimPrefix(Version(version), "stui version "))
+	modules := map[string]string{
+		"github.com/aws/aws-sdk-go-v2":            "AWS SDK",
+		"github.com/aws/aws-sdk-go-v2/service/s3": "S3 client",
+		"github.com/charmbracelet/bubbletea":      "Bubble Tea",
+	}
+	if info, ok := debug.ReadBuildInfo(); ok {
+		for _, dep := range info.Deps {
+			if name, ok := modules[dep.Path]; ok {
+				s.add(StatusInfo, name, dep.Version)
+			}
+		}
+	}
+	return s
+}
+
+// files reports where stui and the AWS SDK read their settings, and whether
+// those files load
+func files() Section {
+	s := Section{Title: "Files"}
+
+	if path, err := config.Path(); err != nil {
+		s.add(StatusFail, "Settings", security.SanitizeError(err))
+	} else if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		s.add(StatusInfo, "Settings", tildePath(path)+" (none; defaults in use)")
+	} else if _, err := config.LoadFile(path); err != nil {
+		s.add(StatusFail, "Settings", security.SanitizeError(err))
+	} else {
+		s.add(StatusOK, "Settings", tildePath(path))
+	}
+
+	if store, err := bookmarks.NewStore(); err != nil {
+		s.add(StatusFail, "Bookmarks", security.SanitizeError(err))
+	} else {
+		all, active := len(store.List()), len(store.Active())
+		detail := fmt.Sprintf("%d bookmarks", all)
+		if all > active {
+			detail += fmt.Sprintf(", %d archived", all-active)
+		}
+		s.add(StatusOK, "Bookmarks", detail)
+	}
+
+	if path, err := aws.SharedConfigPath(); err != nil {
+		s.add(StatusFail, "AWS config", security.SanitizeError(err))
+	} else if _, err := os.Stat(path); errors.Is(err, fs.ErrNotExist) {
+		s.add(StatusWarn, "AWS config", tildePath(path)+" is missing; run aws configure sso to add a profile")
+	} else {
+		s.add(StatusOK, "AWS config", tildePath(path))
+	}
+
+	path := os.Getenv("AWS_SHARED_CREDENTIALS_FILE")
+	if path == "" {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, ".aws", "credentials")
+		}
+	}
+	if _, err := os.Stat(path); path != "" && err == nil {
+		s.add(StatusInfo, "AWS credentials", tildePath(path))
+	} else {
+		s.add(StatusInfo, "AWS credentials", "no credentials file")
+	}
+	return s
+}
+
+// profiles reports the profiles stui can list, and where the one in use
+// gets its credentials
+func profiles(profile string) Section {
+	s := Section{Title: "Profiles"}
+
+	list, err := aws.ListProfiles()
+	switch {
+	case errors.Is(err, fs.ErrNotExist):
+	case err != nil:
+		s.add(StatusFail, "Profiles", security.SanitizeError(err))
+	}
+	for _, p := range list[:min(len(list), maxProfiles)] {
+		detail := []string{p.Kind}
+		if p.Region != "" {
+			detail = append(detail, p.Region)
+		}
+		if p.AccountID != "" {
+			detail = append(detail, "account "+maskAccount(p.AccountID))
+		}
+		s.add(StatusInfo, p.Name, strings.Join(detail, " • "))
+	}
+	if len(list) > maxProfiles {
+		s.add(StatusInfo, "", fmt.Sprintf("… and %d more", len(list)-maxProfiles))
+	}
+	if len(list) == 0 && err == nil {
+		s.add(StatusInfo, "Profiles", "no SSO, role or credential_process profiles")
+	}
+
+	switch {
+	case profile == "":
+		s.add(StatusInfo, "In use", "the default profile")
+	case slices.ContainsFunc(list, func(p aws.ProfileInfo) bool { return p.Name == profile }):
+		s.add(StatusOK, "In use", profile)
+	default:
+		s.add(StatusInfo, "In use", profile+" (credentials from the credentials file or environment)")
+	}
+	// A profile named on the command line or in AWS_PROFILE wins over
+	// access keys in the environment
+	if os.Getenv("AWS_ACCESS_KEY_ID") != "" {
+		if profile == "" {
+			s.add(StatusInfo, "Environment", "credentials from AWS_ACCESS_KEY_ID")
+		} else {
+			s.add(StatusWarn, "Environment", "AWS_ACCESS_KEY_ID is set but unused; the profile's credentials are used")
+		}
+	}
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL_S3"); endpoint != "" {
+		s.add(StatusInfo, "Endpoint", "S3 requests go to "+endpoint+" (AWS_ENDPOINT_URL_S3)")
+	} else if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		s.add(StatusInfo, "Endpoint", "requests go to "+endpoint+" (AWS_ENDPOINT_URL)")
+	}
+	return s
+}
+
+// terminal reports what stui can tell about the terminal it runs in
+func terminal(graphics string) Section {
+	s := Section{Title: "Terminal"}
+
+	name := os.Getenv("TERM")
+	if name == "" {
+		name = "TERM not set"
+	}
+	if program := os.Getenv("TERM_PROGRAM"); program != "" {
+		name += ", " + strings.TrimSpace(program+" "+os.Getenv("TERM_PROGRAM_VERSION"))
+	}
+	switch {
+	case os.Getenv("TMUX") != "":
+		name += ", inside tmux"
+	case strings.HasPrefix(os.Getenv("TERM"), "screen"):
+		name += ", inside screen"
+	}
+	s.add(StatusInfo, "Terminal", name)
+
+	if w, h, err := term.GetSize(os.Stdout.Fd()); err == nil {
+		status := StatusOK
+		detail := fmt.Sprintf("%d × %d", w, h)
+		if w < 80 || h < 24 {
+			status = StatusWarn
+			detail += " (smaller than 80 × 24; some views are cramped)"
+		}
+		s.add(status, "Size", detail)
+	} else {
+		s.add(StatusInfo, "Size", "not a terminal")
+	}
+
+	colors := lipgloss.ColorProfile().Name()
+	switch {
+	case !term.IsTerminal(os.Stdout.Fd()):
+		s.add(StatusInfo, "Colors", "not checked; output isn't a terminal")
+	case strings.EqualFold(colors, "Ascii"):
+		s.add(StatusWarn, "Colors", "none (NO_COLOR or an unknown terminal)")
+	default:
+		s.add(StatusOK, "Colors", colors)
+	}
+
+	locale := cmp.Or(os.Getenv("LC_ALL"), os.Getenv("LC_CTYPE"), os.Getenv("LANG"))
+	if l := strings.ToLower(locale); strings.Contains(l, "utf-8") || strings.Contains(l, "utf8") {
+		s.add(StatusOK, "Locale", locale)
+	} else {
+		s.add(StatusWarn, "Locale", cmp.Or(locale, "not set")+" (not UTF-8; icons and borders may not show)")
+	}
+
+	if graphics != "" {
+		if graphics == "off" {
+			s.add(StatusInfo, "Images", "not drawn (set image_graphics to kitty or sixel to try)")
+		} else {
+			s.add(StatusOK, "Images", graphics)
+		}
+	}
+
+	if clipboard.Unsupported {
+		s.add(StatusWarn, "Clipboard", "no clipboard tool found (xclip, xsel or wl-clipboard on Linux)")
+	} else {
+		s.add(StatusOK, "Clipboard", "available")
+	}
+	return s
+}
+
+// Connect creates a client for profile and region and checks it, as
+// Connectivity does
+func Connect(ctx context.Context, profile, region string, settings *config.Config) Section {
+	ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+	defer cancel()
+	client, err := aws.NewClient(ctx, profile, region, aws.EndpointOptions{FIPS: settings.UseFIPS(profile)})
+	if err != nil {
+		s := Section{Title: "Connectivity"}
+		s.add(StatusFail, "Config", security.SanitizeError(err))
+		return s
+	}
+	return Connectivity(ctx, client)
+}
+
+// Connectivity checks that client has a region, its credentials load and
+// are accepted by STS, and S3 lists buckets with them
+func Connectivity(ctx context.Context, client *aws.Client) Section {
+	s := Section{Title: "Connectivity"}
+
+	if client.Region == "" {
+		s.add(StatusFail, "Region", "none; set one in the profile or with --region")
+	} else {
+		detail := client.Region + " (" + aws.PartitionForRegion(client.Region).Name
+		if client.UsesFIPS() {
+			detail += ", FIPS"
+		}
+		s.add(StatusOK, "Region", detail+")")
+	}
+
+	check := func(f func(context.Context) error) error {
+		ctx, cancel := context.WithTimeout(ctx, checkTimeout)
+		defer cancel()
+		return f(ctx)
+	}
+
+	err := check(func(ctx context.Context) error {
+		creds, err := client.Config.Credentials.Retrieve(ctx)
+		if err != nil {
+			return err
+		}
+		detail := tildePath(creds.Source)
+		if creds.CanExpire {
+			detail += ", expires " + humanize.Time(creds.Expires)
+		}
+		s.add(StatusOK, "Credentials", detail)
+		return nil
+	})
+	if err != nil {
+		hint := ""
+		if client.Profile != "" {
+			hint = fmt.Sprintf(" (for SSO profiles, run aws sso login --profile %s)", client.Profile)
+		}
+		s.add(StatusFail, "Credentials", security.SanitizeError(err)+hint)
+		return s
+	}
+
+	err = check(func(ctx context.Context) error {
+		id, err := client.CallerIdentity(ctx)
+		if err != nil {
+			return err
+		}
+		s.add(StatusOK, "Identity", describeIdentity(id))
+		return nil
+	})
+	if err != nil {
+		s.add(StatusFail, "Identity", security.SanitizeError(err))
+	}
+
+	err = check(func(ctx context.Context) error {
+		start := time.Now()
+		buckets, err := client.ListBuckets(ctx)
+		if err != nil {
+			return err
+		}
+		s.add(StatusOK, "S3", fmt.Sprintf("%d buckets listed in %s", len(buckets), time.Since(start).Round(time.Millisecond)))
+		return nil
+	})
+	if err != nil {
+		s.add(StatusFail, "S3", security.SanitizeError(err))
+	}
+	return s
+}
+
+// Failed reports whether any check failed
+func Failed(sections []Section) bool {
+	for _, s := range sections {
+		for _, c := range s.Checks {
+			if c.Status == StatusFail {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Marker returns the symbol a check is shown with
+func (s Status) Marker() string {
+	switch s {
+	case StatusOK:
+		return "✓"
+	case StatusWarn:
+		return "!"
+	case StatusFail:
+		return "✗"
+	}
+	return "·"
+}
+
+// Write writes the sections as plain text, one check per line
+func Write(w io.Writer, sections []Section) error {
+	nameWidth := 0
+	for _, s := range sections {
+		for _, c := range s.Checks {
+			nameWidth = max(nameWidth, len(c.Name))
+		}
+	}
+	for i, s := range sections {
+		if i > 0 {
+			if _, err := fmt.Fprintln(w); err != nil {
+				return err
+			}
+		}
+		if _, err := fmt.Fprintln(w, s.Title); err != nil {
+			return err
+		}
+		for _, c := range s.Checks {
+			if _, err := fmt.Fprintf(w, "  %s %-*s  %s\n", c.Status.Marker(), nameWidth, c.Name, c.Detail); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// describeIdentity names the user or role of an identity, with the account
+// masked so a report can be shared, e.g. "assumed-role ReadOnly in account
+// ••••••••4321"
+func describeIdentity(id aws.Identity) string {
+	// arn:partition:iam::account:user/name or
+	// arn:partition:sts::account:assumed-role/role/session
+	resource := id.ARN
+	if parts := strings.SplitN(id.ARN, ":", 6); len(parts) == 6 {
+		resource = parts[5]
+	}
+	kind, rest, _ := strings.Cut(resource, "/")
+	name, _, _ := strings.Cut(rest, "/")
+	who := strings.TrimSpace(kind + " " + name)
+	if id.Account == "" {
+		return who
+	}
+	return who + " in account " + maskAccount(id.Account)
+}
+
+// maskAccount hides all but the last four digits of an account ID
+func maskAccount(account string) string {
+	if len(account) <= 4 {
+		return account
+	}
+	return strings.Repeat("•", len(account)-4) + account[len(account)-4:]
+}
+
+// tildePath shortens paths in the home directory to start with ~, so a
+// report doesn't give away the user name
+func tildePath(s string) string {
+	home, err := os.UserHomeDir()
+	if err != nil || home == "" {
+		return s
+	}
+	return strings.ReplaceAll(s, home+string(filepath.Separator), "~"+string(filepath.Separator))
+}
@@ -0,0 +1,55 @@
+package doctor
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+func TestWrite(t *testing.T) {
+	sections := []Section{
+		{Title: "Build", Checks: []Check{{Name: "stui", Detail: "dev"}}},
+		{Title: "Connectivity", Checks: []Check{
+			{Name: "Region", Detail: "us-east-1", Status: StatusOK},
+			{Name: "Credentials", Detail: "token expired", Status: StatusFail},
+		}},
+	}
+	var sb strings.Builder
+	if err := Write(&sb, sections); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	want := `Build
+  · stui         dev
+
+Connectivity
+  ✓ Region       us-east-1
+  ✗ Credentials  token expired
+`
+	if sb.String() != want {
+		t.Errorf("Write() =\n%s\nwant\n%s", sb.String(), want)
+	}
+
+	if !Failed(sections) {
+		t.Error("Failed() should report the failed check")
+	}
+	if Failed(sections[:1]) {
+		t.Error("Failed() should ignore checks that didn't fail")
+	}
+}
+
+func TestDescribeIdentity(t *testing.T) {
+	tests := []struct {
+		id   aws.Identity
+		want string
+	}{
+		{aws.Identity{Account: "123456789012", ARN: "arn:aws:sts::123456789012:assumed-role/ReadOnly/jane"}, "assumed-role ReadOnly in account ••••••••9012"},
+		{aws.Identity{Account: "123456789012", ARN: "arn:aws-us-gov:iam::123456789012:user/ci"}, "user ci in account ••••••••9012"},
+		{aws.Identity{Account: "123456789012", ARN: "arn:aws:iam::123456789012:root"}, "root in account ••••••••9012"},
+	}
+	for _, tt := range tests {
+		if got := describeIdentity(tt.id); got != tt.want {
+			t.Errorf("describeIdentity(%s) = %q, want %q", tt.id.ARN, got, tt.want)
+		}
+	}
+}
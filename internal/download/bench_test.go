@@ -0,0 +1,31 @@
+package download
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// BenchmarkProgressAggregation measures one progress report from a
+// transfer in a job of 100k files
+func BenchmarkProgressAggregation(b *testing.B) {
+	const files = 100_000
+	m := NewManager(nil, 1)
+	m.progress = Progress{Files: make(map[string]*FileProgress, files), Workers: make([]WorkerStatus, 1)}
+	for i := range files {
+		key := fmt.Sprintf("data/%06d.bin", i)
+		m.progress.Files[key] = &FileProgress{Key: key, Size: 1 << 20, Status: StatusPending}
+		m.progress.TotalBytes += 1 << 20
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	m.transferObject(context.Background(), "data/000000.bin", 0, func(ctx context.Context, onProgress func(aws.DownloadProgress)) error {
+		for i := range b.N {
+			onProgress(aws.DownloadProgress{BytesDownloaded: int64(i % (1 << 20))})
+		}
+		return nil
+	})
+}
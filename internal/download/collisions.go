@@ -0,0 +1,123 @@
+package download
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/security"
+)
+
+// CollisionPolicy decides what happens when two keys map to the same local path
+type CollisionPolicy int
+
+const (
+	CollisionNamespace CollisionPolicy = iota // place the later file under its full key path
+	CollisionFail                             // refuse to start the download
+)
+
+// ParseCollisionPolicy parses a collision policy name from config
+func ParseCollisionPolicy(s string) (CollisionPolicy, error) {
+	switch s {
+	case "", "namespace":
+		return CollisionNamespace, nil
+	case "fail":
+		return CollisionFail, nil
+	default:
+		return CollisionNamespace, fmt.Errorf("unknown collision policy %q (use namespace or fail)", s)
+	}
+}
+
+// localPlan maps keys to validated local paths
+type localPlan struct {
+	objects    []aws.S3Object    // deduplicated objects in input order
+	paths      map[string]string // key -> local path
+	collisions map[string]string // renamed key -> key it collided with
+	escaped    map[string]bool   // keys whose local names were escaped
+}
+
+// planLocalPaths computes a destination for every object relative to prefix
+// under localDir. Paths are compared case-insensitively so that keys differing
+// only in case don't overwrite each other on macOS/Windows filesystems. Keys
+// with characters the local filesystem can't store are escaped or rejected
+// according to pathPolicy.
+func planLocalPaths(objects []aws.S3Object, prefix, localDir string, policy CollisionPolicy, pathPolicy security.PathPolicy) (*localPlan, error) {
+	plan := &localPlan{
+		paths:      make(map[string]string, len(objects)),
+		collisions: make(map[string]string),
+		escaped:    make(map[string]bool),
+	}
+	taken := make(map[string]string, len(objects)) // folded path -> key
+
+	for _, obj := range objects {
+		if _, dup := plan.paths[obj.Key]; dup {
+			continue // same key selected twice
+		}
+
+		localPath, escaped, err := keyPath(localDir, strings.TrimPrefix(obj.Key, prefix), pathPolicy)
+		if err != nil {
+			return nil, fmt.Errorf("key %s: %w", obj.Key, err)
+		}
+
+		if other, clash := taken[foldPath(localPath)]; clash {
+			if policy == CollisionFail {
+				return nil, fmt.Errorf("keys %s and %s would both be written to %s", other, obj.Key, localPath)
+			}
+			localPath, escaped, err = namespacedPath(localDir, obj.Key, taken, pathPolicy)
+			if err != nil {
+				return nil, fmt.Errorf("key %s: %w", obj.Key, err)
+			}
+			plan.collisions[obj.Key] = other
+		}
+
+		if escaped {
+			plan.escaped[obj.Key] = true
+		}
+		taken[foldPath(localPath)] = obj.Key
+		plan.paths[obj.Key] = localPath
+		plan.objects = append(plan.objects, obj)
+	}
+
+	return plan, nil
+}
+
+// keyPath returns where the key path rel is written under localDir, with
+// path traversal protection. Characters the local filesystem can't store are
+// escaped, reported by escaped, or rejected according to pathPolicy.
+func keyPath(localDir, rel string, pathPolicy security.PathPolicy) (path string, escaped bool, err error) {
+	localRel, err := security.LocalRelPath(rel, pathPolicy)
+	if err != nil {
+		return "", false, err
+	}
+	path, err = security.SafeJoin(localDir, localRel)
+	if err != nil {
+		return "", false, fmt.Errorf("unsafe path: %w", err)
+	}
+	return path, localRel != rel, nil
+}
+
+// namespacedPath places key under its full key path, adding a numeric suffix
+// if that is taken as well. The full path is subject to pathPolicy like any
+// other.
+func namespacedPath(localDir, key string, taken map[string]string, pathPolicy security.PathPolicy) (path string, escaped bool, err error) {
+	base, escaped, err := keyPath(localDir, key, pathPolicy)
+	if err != nil {
+		return "", false, err
+	}
+
+	candidate := base
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for i := 2; ; i++ {
+		if _, clash := taken[foldPath(candidate)]; !clash {
+			return candidate, escaped, nil
+		}
+		candidate = fmt.Sprintf("%s~%d%s", stem, i, ext)
+	}
+}
+
+// foldPath normalizes a path for collision comparison
+func foldPath(path string) string {
+	return strings.ToLower(filepath.Clean(path))
+}
@@ -0,0 +1,106 @@
+package download
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/security"
+)
+
+func TestPlanLocalPathsNamespace(t *testing.T) {
+	dir := t.TempDir()
+	objects := []aws.S3Object{
+		{Key: "logs/README.md"},
+		{Key: "logs/readme.md"},
+		{Key: "logs/a.txt"},
+		{Key: "logs/a.txt"},
+	}
+
+	plan, err := planLocalPaths(objects, "logs/", dir, CollisionNamespace, security.PathEscape)
+	if err != nil {
+		t.Fatalf("planLocalPaths() error = %v", err)
+	}
+
+	if len(plan.objects) != 3 {
+		t.Errorf("expected duplicates to be dropped, got %d objects", len(plan.objects))
+	}
+	if plan.collisions["logs/readme.md"] != "logs/README.md" {
+		t.Errorf("expected collision to be recorded, got %v", plan.collisions)
+	}
+
+	first := plan.paths["logs/README.md"]
+	second := plan.paths["logs/readme.md"]
+	if strings.EqualFold(first, second) {
+		t.Errorf("colliding keys mapped to the same path %s", first)
+	}
+	if want := filepath.Join(dir, "logs", "readme.md"); second != want {
+		t.Errorf("namespaced path = %s, want %s", second, want)
+	}
+}
+
+func TestPlanLocalPathsFail(t *testing.T) {
+	objects := []aws.S3Object{
+		{Key: "x/Data.csv"},
+		{Key: "x/data.csv"},
+	}
+	if _, err := planLocalPaths(objects, "x/", t.TempDir(), CollisionFail, security.PathEscape); err == nil {
+		t.Error("expected collision error")
+	}
+}
+
+func TestPlanLocalPathsTraversal(t *testing.T) {
+	objects := []aws.S3Object{{Key: "p/../../etc/passwd"}}
+	if _, err := planLocalPaths(objects, "p/", t.TempDir(), CollisionNamespace, security.PathEscape); err == nil {
+		t.Error("expected traversal error")
+	}
+}
+
+func TestPlanLocalPathsEscaping(t *testing.T) {
+	dir := t.TempDir()
+	objects := []aws.S3Object{
+		{Key: "logs/line\nbreak.txt"},
+		{Key: "logs/plain.txt"},
+	}
+
+	plan, err := planLocalPaths(objects, "logs/", dir, CollisionNamespace, security.PathEscape)
+	if err != nil {
+		t.Fatalf("planLocalPaths() error = %v", err)
+	}
+	if want := filepath.Join(dir, "line%0Abreak.txt"); plan.paths["logs/line\nbreak.txt"] != want {
+		t.Errorf("escaped path = %s, want %s", plan.paths["logs/line\nbreak.txt"], want)
+	}
+	if len(plan.escaped) != 1 {
+		t.Errorf("expected one escaped key, got %v", plan.escaped)
+	}
+
+	if _, err := planLocalPaths(objects, "logs/", dir, CollisionNamespace, security.PathStrict); err == nil {
+		t.Error("expected strict policy to reject key with a newline")
+	}
+}
+
+// TestPlanLocalPathsNamespaceEscaping checks that a collision's full key path
+// is subject to the path policy too
+func TestPlanLocalPathsNamespaceEscaping(t *testing.T) {
+	dir := t.TempDir()
+	objects := []aws.S3Object{
+		{Key: "in\x01/Data.csv"},
+		{Key: "in\x01/data.csv"},
+	}
+
+	plan, err := planLocalPaths(objects, "in\x01/", dir, CollisionNamespace, security.PathEscape)
+	if err != nil {
+		t.Fatalf("planLocalPaths() error = %v", err)
+	}
+	if want := filepath.Join(dir, "in%01", "data.csv"); plan.paths["in\x01/data.csv"] != want {
+		t.Errorf("namespaced path = %s, want %s", plan.paths["in\x01/data.csv"], want)
+	}
+	if !plan.escaped["in\x01/data.csv"] || len(plan.escaped) != 1 {
+		t.Errorf("expected the namespaced key to be escaped, got %v", plan.escaped)
+	}
+
+	if _, err := planLocalPaths(objects, "in\x01/", dir, CollisionNamespace, security.PathStrict); err == nil {
+		t.Error("expected strict policy to reject the namespaced key")
+	}
+}
@@ -0,0 +1,226 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/pool"
+)
+
+// CopyFile is an object to copy and the key it's copied to
+type CopyFile struct {
+	Source aws.S3Object
+	Key    string // destination key
+}
+
+// copyFunc copies one object, reporting progress
+type copyFunc func(ctx context.Context, f CopyFile, onProgress func(aws.DownloadProgress)) error
+
+// CopyObjects copies objects from srcBucket to their keys in dstBucket
+// inside S3, without downloading them, using the worker pool. Progress is
+// tracked by destination key.
+func (m *Manager) CopyObjects(ctx context.Context, srcBucket string, files []CopyFile, dstBucket string) error {
+	// Copies have to be sent to the destination bucket's region. Without
+	// permission to look it up, assume it's ours.
+	var dst *aws.Client // nil copies with m.client
+	if dstBucket != srcBucket {
+		if regional, err := m.client.ForBucket(ctx, dstBucket); err == nil {
+			dst = regional
+		}
+	}
+	return m.copyObjects(ctx, srcBucket, files, dstBucket, DirectionCopy, func(ctx context.Context, f CopyFile, onProgress func(aws.DownloadProgress)) error {
+		return m.client.CopyObjectBetween(ctx, dst, srcBucket, f.Source.Key, dstBucket, f.Key, m.uploadOpts, onProgress)
+	})
+}
+
+// ListPrefixCopy lists what copying everything under srcPrefix in
+// srcBucket to dstPrefix in dstBucket involves: each object with the key
+// it's copied to, and the objects already under dstPrefix by key
+func (m *Manager) ListPrefixCopy(ctx context.Context, srcBucket, srcPrefix, dstBucket, dstPrefix string) ([]CopyFile, map[string]aws.S3Object, error) {
+	objects, err := m.client.ListAllObjects(ctx, srcBucket, srcPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	files := make([]CopyFile, 0, len(objects))
+	for _, obj := range objects {
+		if obj.IsPrefix {
+			continue
+		}
+		files = append(files, CopyFile{Source: obj, Key: dstPrefix + strings.TrimPrefix(obj.Key, srcPrefix)})
+	}
+
+	// Listing, like copying, has to go to the destination's region
+	var dst Client = m.client
+	if dstBucket != srcBucket {
+		if regional, err := m.client.ForBucket(ctx, dstBucket); err == nil {
+			dst = regional
+		}
+	}
+	listed, err := dst.ListAllObjects(ctx, dstBucket, dstPrefix)
+	if err != nil {
+		return nil, nil, err
+	}
+	existing := make(map[string]aws.S3Object, len(listed))
+	for _, obj := range listed {
+		existing[obj.Key] = obj
+	}
+	return files, existing, nil
+}
+
+// CopyObjectsAs copies objects to dstBucket using dst's credentials, e.g. a
+// profile for another account. The objects are streamed through stui
+// rather than copied inside S3, so neither account needs access to the
+// other's bucket. dst should be in dstBucket's region; see
+// aws.Client.ForBucket.
+func (m *Manager) CopyObjectsAs(ctx context.Context, dst *aws.Client, srcBucket string, files []CopyFile, dstBucket string) error {
+	return m.copyObjects(ctx, srcBucket, files, dstBucket, DirectionCopy, func(ctx context.Context, f CopyFile, onProgress func(aws.DownloadProgress)) error {
+		return m.client.StreamCopy(ctx, dst, srcBucket, f.Source.Key, dstBucket, f.Key, m.uploadOpts, onProgress)
+	})
+}
+
+// ChangeStorageClass moves objects in bucket to another storage class by
+// copying each onto itself, using the worker pool. Objects already in the
+// class are skipped, and counted in the progress summary.
+func (m *Manager) ChangeStorageClass(ctx context.Context, bucket string, objects []aws.S3Object, class string) error {
+	files := make([]CopyFile, len(objects))
+	for i, obj := range objects {
+		files[i] = CopyFile{Source: obj, Key: obj.Key}
+	}
+	skipped := 0
+	return m.copyObjects(ctx, bucket, files, bucket, DirectionCopy, func(ctx context.Context, f CopyFile, onProgress func(aws.DownloadProgress)) error {
+		err := m.client.ChangeStorageClass(ctx, bucket, f.Key, class, m.uploadOpts, onProgress)
+		if errors.Is(err, aws.ErrSameStorageClass) {
+			m.progressMu.Lock()
+			skipped++
+			m.progress.Summary = fmt.Sprintf("%d already in %s", skipped, class)
+			m.progressMu.Unlock()
+			return nil
+		}
+		return err
+	})
+}
+
+// TagObjects adds tags to objects in bucket, keeping the tags they already
+// have, using the worker pool. Each object succeeds or fails on its own;
+// objects that already have the tags are counted in the progress summary.
+func (m *Manager) TagObjects(ctx context.Context, bucket string, objects []aws.S3Object, tags map[string]string) error {
+	files := make([]CopyFile, len(objects))
+	for i, obj := range objects {
+		// No bytes move, so progress is counted in objects
+		files[i] = CopyFile{Source: aws.S3Object{Key: obj.Key}, Key: obj.Key}
+	}
+	skipped := 0
+	return m.copyObjects(ctx, bucket, files, bucket, DirectionTag, func(ctx context.Context, f CopyFile, onProgress func(aws.DownloadProgress)) error {
+		err := m.client.TagObject(ctx, bucket, f.Key, tags)
+		if errors.Is(err, aws.ErrAlreadyTagged) {
+			m.progressMu.Lock()
+			skipped++
+			m.progress.Summary = fmt.Sprintf("%d already tagged", skipped)
+			m.progressMu.Unlock()
+			return nil
+		}
+		return err
+	})
+}
+
+// MoveObjects moves objects to new keys in bucket, e.g. to rename a
+// folder, by copying each inside S3 and then deleting the original, using
+// the worker pool. An original that can't be deleted fails its file and
+// is left next to the copy.
+func (m *Manager) MoveObjects(ctx context.Context, bucket string, files []CopyFile) error {
+	return m.copyObjects(ctx, bucket, files, bucket, DirectionMove, func(ctx context.Context, f CopyFile, onProgress func(aws.DownloadProgress)) error {
+		if err := m.client.CopyObjectBetween(ctx, nil, bucket, f.Source.Key, bucket, f.Key, m.uploadOpts, onProgress); err != nil {
+			return err
+		}
+		if err := m.client.DeleteObject(ctx, bucket, f.Source.Key); err != nil {
+			return fmt.Errorf("copied to %s but the original wasn't deleted: %w", f.Key, err)
+		}
+		return nil
+	})
+}
+
+// copyObjects copies files with copyFile and tracks their progress as a
+// transfer in direction dir
+func (m *Manager) copyObjects(ctx context.Context, srcBucket string, files []CopyFile, dstBucket string, dir Direction, copyFile copyFunc) error {
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if len(files) == 0 {
+		return fmt.Errorf("no objects to copy")
+	}
+
+	var totalBytes int64
+	progressFiles := make(map[string]*FileProgress, len(files))
+	for _, f := range files {
+		totalBytes += f.Source.Size
+		progressFiles[f.Key] = &FileProgress{
+			Key:       f.Key,
+			LocalPath: fmt.Sprintf("s3://%s/%s", srcBucket, f.Source.Key), // the source, in place of a local file
+			Size:      f.Source.Size,
+			Status:    StatusPending,
+		}
+	}
+
+	m.progressMu.Lock()
+	m.progress = Progress{
+		Bucket:     dstBucket,
+		TotalFiles: len(files),
+		TotalBytes: totalBytes,
+		Files:      progressFiles,
+		StartedAt:  time.Now(),
+		Status:     StatusInProgress,
+		Direction:  dir,
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+
+	err = m.copyWithWorkers(ctx, files, copyFile)
+
+	m.progressMu.Lock()
+	if err != nil && ctx.Err() != nil {
+		m.progress.Status = StatusCancelled
+	} else if m.progress.FailedFiles > 0 {
+		m.progress.Status = StatusFailed
+	} else {
+		m.progress.Status = StatusCompleted
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+	m.notifyComplete()
+
+	return err
+}
+
+// copyWithWorkers copies objects using a worker pool
+func (m *Manager) copyWithWorkers(ctx context.Context, files []CopyFile, copyFile copyFunc) error {
+	var counts fileCounts
+
+	stopWatch := m.watchStalls(ctx)
+	defer stopWatch()
+
+	m.progressMu.Lock()
+	m.startWorkers("")
+	m.progressMu.Unlock()
+
+	return pool.Each(ctx, m.workers, files, func(worker int, f CopyFile) {
+		if ctx.Err() != nil {
+			return
+		}
+		m.startFile(f.Key, worker)
+		m.notifyProgress()
+
+		err := m.transferObject(ctx, f.Key, worker, func(ctx context.Context, onProgress func(aws.DownloadProgress)) error {
+			return copyFile(ctx, f, onProgress)
+		})
+		m.endFile(ctx, aws.S3Object{Key: f.Key, Size: f.Source.Size}, worker, err, &counts)
+	})
+}
@@ -0,0 +1,120 @@
+//go:build integration
+
+package download_test
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/itest"
+)
+
+func TestMain(m *testing.M) {
+	itest.Main(m)
+}
+
+func TestDownloadPrefix(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+
+	// Big enough for the downloader to fetch it in three ranged parts
+	big := make([]byte, 25*1024*1024)
+	rand.Read(big)
+	itest.Put(t, client, bucket, "data/big.bin", big)
+	itest.Put(t, client, bucket, "data/nested/small.txt", []byte("small"))
+	itest.Put(t, client, bucket, "other/skipped.txt", []byte("skipped"))
+
+	dir := t.TempDir()
+	m := download.NewManager(client, 3)
+	if err := m.DownloadPrefix(context.Background(), bucket, "data/", dir); err != nil {
+		t.Fatalf("DownloadPrefix() error = %v", err)
+	}
+
+	progress := m.GetProgress()
+	if progress.Status != download.StatusCompleted || progress.CompletedFiles != 2 {
+		t.Errorf("progress = %s with %d files done, want completed with 2", progress.Status, progress.CompletedFiles)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "big.bin"))
+	if err != nil || !bytes.Equal(got, big) {
+		t.Errorf("big.bin wasn't downloaded intact (err %v)", err)
+	}
+	got, err = os.ReadFile(filepath.Join(dir, "nested", "small.txt"))
+	if err != nil || string(got) != "small" {
+		t.Errorf("nested/small.txt = %q, %v; want \"small\"", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "skipped.txt")); !os.IsNotExist(err) {
+		t.Error("an object outside the prefix was downloaded")
+	}
+}
+
+func TestDownloadMissingObject(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+	itest.Put(t, client, bucket, "present.txt", []byte("hello"))
+
+	objects := []aws.S3Object{
+		{Key: "present.txt", Size: 5},
+		{Key: "gone.txt", Size: 5}, // deleted since it was listed
+	}
+	m := download.NewManager(client, 2)
+	m.DownloadMultiple(context.Background(), bucket, objects, "", t.TempDir())
+
+	progress := m.GetProgress()
+	if progress.Status != download.StatusFailed {
+		t.Errorf("Status = %s, want failed", progress.Status)
+	}
+	if progress.CompletedFiles != 1 || progress.FailedFiles != 1 {
+		t.Errorf("completed %d and failed %d files, want 1 and 1", progress.CompletedFiles, progress.FailedFiles)
+	}
+	if f := progress.Files["gone.txt"]; f == nil || f.Error == nil {
+		t.Error("the missing object has no error")
+	}
+}
+
+func TestSync(t *testing.T) {
+	client := itest.Client(t)
+	bucket := itest.Bucket(t, client)
+	itest.Put(t, client, bucket, "site/same.txt", []byte("hello"))
+	itest.Put(t, client, bucket, "site/changed.txt", []byte("new content"))
+	itest.Put(t, client, bucket, "site/css/new.css", []byte("body {}"))
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "same.txt"), []byte("hello"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "changed.txt"), []byte("old content"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx := context.Background()
+	s := download.NewSyncManager(client)
+	result, err := s.CompareFiles(ctx, bucket, "site/", dir)
+	if err != nil {
+		t.Fatalf("CompareFiles() error = %v", err)
+	}
+	if len(result.ToDownload) != 2 || len(result.Unchanged) != 1 {
+		t.Fatalf("CompareFiles() = %d to download and %d unchanged, want 2 and 1", len(result.ToDownload), len(result.Unchanged))
+	}
+
+	if err := s.Sync(ctx, bucket, "site/", dir, download.NewManager(client, 2)); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "changed.txt"))
+	if err != nil || string(got) != "new content" {
+		t.Errorf("changed.txt = %q, %v; want the new content", got, err)
+	}
+
+	result, err = s.CompareFiles(ctx, bucket, "site/", dir)
+	if err != nil {
+		t.Fatalf("CompareFiles() after sync error = %v", err)
+	}
+	if len(result.ToDownload) != 0 {
+		t.Errorf("%d files still differ after sync", len(result.ToDownload))
+	}
+}
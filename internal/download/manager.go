@@ -2,13 +2,20 @@ package download
 
 import (
 	"context"
+	"errors"
 	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
 	"strings"
 	"sync"
 	"sync/atomic"
 	"time"
 
 	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/pool"
 	"github.com/natevick/stui/internal/security"
 )
 
@@ -40,20 +47,78 @@ func (s Status) String() string {
 	}
 }
 
+// Direction is the direction of a transfer
+type Direction int
+
+const (
+	DirectionDownload Direction = iota
+	DirectionUpload
+	DirectionCopy // S3 to S3
+	DirectionTag  // tags applied to objects in place
+	DirectionMove // S3 to S3, deleting the originals
+)
+
+func (d Direction) String() string {
+	switch d {
+	case DirectionUpload:
+		return "upload"
+	case DirectionCopy:
+		return "copy"
+	case DirectionTag:
+		return "tag"
+	case DirectionMove:
+		return "move"
+	}
+	return "download"
+}
+
 // FileProgress tracks progress for a single file
 type FileProgress struct {
-	Key             string
-	LocalPath       string
-	Size            int64
-	Downloaded      int64
-	Status          Status
-	Error           error
-	StartedAt       time.Time
-	CompletedAt     time.Time
+	Key            string
+	LocalPath      string
+	Size           int64
+	Downloaded     int64 // bytes transferred, in either direction
+	Status         Status
+	Error          error
+	StartedAt      time.Time
+	CompletedAt    time.Time
+	LastProgressAt time.Time // last time bytes were received
+	Stalled        bool      // no bytes received within the stall timeout
+	Retries        int       // number of automatic retries after stalls
+	CollidedWith   string    // key whose local path this file was moved away from
+	Escaped        bool      // local name was escaped for the local filesystem
+	Parts          int       // parts in a multipart upload, 0 otherwise
+	PartsDone      int       // parts fully handed to the uploader
+}
+
+// WorkerStatus describes what a single download worker is doing
+type WorkerStatus struct {
+	ID         int
+	Key        string    // key being downloaded, empty when idle
+	Downloaded int64     // bytes received for the current key
+	StartedAt  time.Time // when the current key was started
+	FilesDone  int
+	LastError  error
+	Source     string // the mirror the worker downloads through; "" for the bucket itself
+}
+
+// Idle returns true if the worker has no file assigned
+func (w WorkerStatus) Idle() bool {
+	return w.Key == ""
+}
+
+// BytesPerSec returns the average rate for the worker's current file
+func (w WorkerStatus) BytesPerSec(now time.Time) float64 {
+	elapsed := now.Sub(w.StartedAt).Seconds()
+	if w.Idle() || elapsed <= 0 {
+		return 0
+	}
+	return float64(w.Downloaded) / elapsed
 }
 
 // Progress tracks overall download progress
 type Progress struct {
+	Bucket          string // bucket the job reads from or writes to
 	TotalFiles      int
 	CompletedFiles  int
 	FailedFiles     int
@@ -63,41 +128,205 @@ type Progress struct {
 	Files           map[string]*FileProgress
 	StartedAt       time.Time
 	Status          Status
+	Direction       Direction
+	Throughput      float64 // bytes/sec, exponential moving average
+	StalledFiles    int
+	Collisions      int    // files written to a namespaced path to avoid overwriting another
+	EscapedFiles    int    // files whose local names were escaped
+	Summary         string // notes about how the job was planned, e.g. symlink handling
+	MirrorFallbacks int    // downloads a mirror couldn't serve, fetched from the bucket instead
+	Workers         []WorkerStatus
+
+	sampledAt    time.Time // time of the last throughput sample
+	sampledBytes int64     // DownloadedBytes at the last sample
 }
 
-// PercentComplete returns the overall percentage
+// PercentComplete returns the overall percentage, by bytes, or by files
+// for transfers that move none, like tagging
 func (p Progress) PercentComplete() float64 {
 	if p.TotalBytes == 0 {
-		return 0
+		if p.TotalFiles == 0 {
+			return 0
+		}
+		return float64(p.CompletedFiles+p.FailedFiles) / float64(p.TotalFiles) * 100
 	}
 	return float64(p.DownloadedBytes) / float64(p.TotalBytes) * 100
 }
 
+// ETA estimates the remaining time from the smoothed throughput.
+// It returns 0 if no estimate is available yet.
+func (p Progress) ETA() time.Duration {
+	if p.Throughput <= 0 || p.TotalBytes <= p.DownloadedBytes {
+		return 0
+	}
+	remaining := float64(p.TotalBytes - p.DownloadedBytes)
+	return time.Duration(remaining / p.Throughput * float64(time.Second))
+}
+
+// Throughput smoothing parameters
+const (
+	throughputSampleInterval = 500 * time.Millisecond
+	throughputAlpha          = 0.2 // weight of the newest sample
+)
+
+// sampleThroughput folds the bytes received since the last sample into the
+// moving average. Samples closer together than throughputSampleInterval are
+// skipped so bursts of small writes don't dominate the estimate.
+func (p *Progress) sampleThroughput(now time.Time) {
+	if p.sampledAt.IsZero() {
+		p.sampledAt = now
+		p.sampledBytes = p.DownloadedBytes
+		return
+	}
+
+	elapsed := now.Sub(p.sampledAt)
+	if elapsed < throughputSampleInterval {
+		return
+	}
+
+	rate := float64(p.DownloadedBytes-p.sampledBytes) / elapsed.Seconds()
+	if rate < 0 {
+		rate = 0
+	}
+	if p.Throughput == 0 {
+		p.Throughput = rate
+	} else {
+		p.Throughput = throughputAlpha*rate + (1-throughputAlpha)*p.Throughput
+	}
+	p.sampledAt = now
+	p.sampledBytes = p.DownloadedBytes
+}
+
+// ScheduleOrder controls the order in which files are handed to workers
+type ScheduleOrder int
+
+const (
+	OrderListing       ScheduleOrder = iota // S3 listing order
+	OrderLargestFirst                       // start big files early so they overlap with small ones
+	OrderSmallestFirst                      // finish many small files quickly
+)
+
+// ParseScheduleOrder parses a schedule order name from config
+func ParseScheduleOrder(s string) (ScheduleOrder, error) {
+	switch s {
+	case "", "listing":
+		return OrderListing, nil
+	case "largest-first":
+		return OrderLargestFirst, nil
+	case "smallest-first":
+		return OrderSmallestFirst, nil
+	default:
+		return OrderListing, fmt.Errorf("unknown schedule order %q (use listing, largest-first or smallest-first)", s)
+	}
+}
+
+// apply returns the objects in scheduling order without modifying the input
+func (o ScheduleOrder) apply(objects []aws.S3Object) []aws.S3Object {
+	if o == OrderListing {
+		return objects
+	}
+	sorted := make([]aws.S3Object, len(objects))
+	copy(sorted, objects)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if o == OrderLargestFirst {
+			return sorted[i].Size > sorted[j].Size
+		}
+		return sorted[i].Size < sorted[j].Size
+	})
+	return sorted
+}
+
+// DefaultStallTimeout is how long a file may go without receiving bytes
+// before it is flagged as stalled
+const DefaultStallTimeout = 30 * time.Second
+
+// maxStallRetries bounds automatic retries of a stalled file
+const maxStallRetries = 3
+
+// Client is the S3 client a Manager transfers with. *aws.Client is the
+// real one; tests substitute clients that fail, stall or cancel on cue.
+type Client interface {
+	ListAllObjects(ctx context.Context, bucket, prefix string) ([]aws.S3Object, error)
+	GetObjectMetadata(ctx context.Context, bucket, key string) (*aws.S3Object, error)
+	GetObjectChecksums(ctx context.Context, bucket, key string) (*aws.ObjectChecksums, error)
+	DownloadFile(ctx context.Context, bucket, key, localPath string, opts aws.DownloadOptions, onProgress func(aws.DownloadProgress)) error
+	DownloadFileRange(ctx context.Context, bucket, key, localPath string, r aws.ByteRange, onProgress func(aws.DownloadProgress)) error
+	DownloadRange(ctx context.Context, bucket, key string, w io.WriterAt, offset, length int64, onProgress func(aws.DownloadProgress)) error
+	UploadFile(ctx context.Context, bucket, key, localPath string, opts aws.UploadOptions, attrs aws.ObjectAttributes, onProgress func(aws.DownloadProgress)) error
+	ResumeUpload(ctx context.Context, bucket, key, localPath string, upload *aws.IncompleteUpload, opts aws.UploadOptions, onProgress func(aws.DownloadProgress)) error
+	ForBucket(ctx context.Context, bucket string) (*aws.Client, error)
+	CopyObjectBetween(ctx context.Context, dst *aws.Client, srcBucket, srcKey, dstBucket, dstKey string, opts aws.UploadOptions, onProgress func(aws.DownloadProgress)) error
+	StreamCopy(ctx context.Context, dst *aws.Client, srcBucket, srcKey, dstBucket, dstKey string, opts aws.UploadOptions, onProgress func(aws.DownloadProgress)) error
+	ChangeStorageClass(ctx context.Context, bucket, key, class string, opts aws.UploadOptions, onProgress func(aws.DownloadProgress)) error
+	TagObject(ctx context.Context, bucket, key string, tags map[string]string) error
+	DeleteObject(ctx context.Context, bucket, key string) error
+}
+
+// ErrBusy is returned when a transfer is started on a Manager that's still
+// running another. Give each concurrent transfer a Manager of its own, as
+// a Queue does.
+var ErrBusy = errors.New("transfer manager is busy with another transfer")
+
 // Manager orchestrates downloads
 type Manager struct {
-	client      *aws.Client
-	workers     int
-	progress    Progress
-	progressMu  sync.RWMutex
-	cancelFunc  context.CancelFunc
-	onProgress  func(Progress)
-	onComplete  func(Progress)
+	client       Client
+	busy         atomic.Bool // a transfer is running
+	workers      int
+	progress     Progress
+	progressMu   sync.RWMutex
+	cancelFunc   context.CancelFunc
+	onProgress   func(Progress)
+	onComplete   func(Progress)
+	stallTimeout time.Duration
+	retryStalled bool
+	fileCancels  map[string]map[int]*fileAttempt // each worker's attempt at a file, by key, for stall retries
+	order        ScheduleOrder
+	collisions   CollisionPolicy
+	pathPolicy   security.PathPolicy
+	uploadOpts   aws.UploadOptions
+	downloadOpts aws.DownloadOptions
+	mirrors      Mirrors // other sources to stripe downloads across, by bucket
+	splitMin     int64   // files at least this large may be split across workers
+	splitChunk   int64   // the smallest chunk a split file is cut into
+	mirrorMu     sync.Mutex
+	mirrorChecks map[mirrorCheck]bool // whether a mirror holds an object's version
 }
 
 // NewManager creates a new download manager
-func NewManager(client *aws.Client, workers int) *Manager {
+func NewManager(client Client, workers int) *Manager {
 	if workers <= 0 {
 		workers = 5
 	}
 	return &Manager{
-		client:  client,
-		workers: workers,
+		client:       client,
+		workers:      workers,
+		stallTimeout: DefaultStallTimeout,
+		fileCancels:  make(map[string]map[int]*fileAttempt),
+		splitMin:     splitMinSize,
+		splitChunk:   splitChunkSize,
 		progress: Progress{
 			Files: make(map[string]*FileProgress),
 		},
 	}
 }
 
+// SetWorkers sets how many files are transferred at once
+func (m *Manager) SetWorkers(n int) {
+	if n > 0 {
+		m.workers = n
+	}
+}
+
+// SetStallPolicy configures stall detection. Files receiving no bytes for
+// timeout are flagged; if retry is set they are restarted automatically.
+func (m *Manager) SetStallPolicy(timeout time.Duration, retry bool) {
+	if timeout <= 0 {
+		timeout = DefaultStallTimeout
+	}
+	m.stallTimeout = timeout
+	m.retryStalled = retry
+}
+
 // SetProgressCallback sets the progress callback
 func (m *Manager) SetProgressCallback(fn func(Progress)) {
 	m.onProgress = fn
@@ -108,11 +337,63 @@ func (m *Manager) SetCompleteCallback(fn func(Progress)) {
 	m.onComplete = fn
 }
 
+// SetScheduleOrder sets the order in which files are dispatched to workers
+func (m *Manager) SetScheduleOrder(order ScheduleOrder) {
+	m.order = order
+}
+
+// SetCollisionPolicy sets how multi-downloads handle keys that map to the same local path
+func (m *Manager) SetCollisionPolicy(policy CollisionPolicy) {
+	m.collisions = policy
+}
+
+// SetPathPolicy sets how downloads and syncs handle keys with characters the
+// local filesystem can't store
+func (m *Manager) SetPathPolicy(policy security.PathPolicy) {
+	m.pathPolicy = policy
+}
+
+// SetUploadOptions sets the multipart part size and concurrency for uploads
+func (m *Manager) SetUploadOptions(opts aws.UploadOptions) {
+	m.uploadOpts = opts
+}
+
+// SetDownloadOptions sets the part size and concurrency of each file's
+// ranged download
+func (m *Manager) SetDownloadOptions(opts aws.DownloadOptions) {
+	m.downloadOpts = opts
+}
+
 // GetProgress returns the current progress
 func (m *Manager) GetProgress() Progress {
 	m.progressMu.RLock()
 	defer m.progressMu.RUnlock()
-	return m.progress
+	return m.snapshot()
+}
+
+// snapshot copies the progress so slices can be read without the lock.
+// Caller must hold progressMu.
+func (m *Manager) snapshot() Progress {
+	p := m.progress
+	p.Workers = append([]WorkerStatus(nil), m.progress.Workers...)
+	return p
+}
+
+// begin claims the manager for one transfer, returning the context to run it
+// with and a func that releases the manager once the transfer returns. A
+// Manager tracks the progress of one transfer at a time, so starting a
+// second while one runs fails with ErrBusy rather than clobbering the first
+// one's progress and callbacks.
+func (m *Manager) begin(ctx context.Context) (context.Context, func(), error) {
+	if !m.busy.CompareAndSwap(false, true) {
+		return nil, nil, ErrBusy
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	m.cancelFunc = cancel
+	return ctx, func() {
+		cancel()
+		m.busy.Store(false)
+	}, nil
 }
 
 // Cancel cancels the current download
@@ -124,46 +405,83 @@ func (m *Manager) Cancel() {
 
 // DownloadFile downloads a single file
 func (m *Manager) DownloadFile(ctx context.Context, bucket, key, localPath string) error {
-	ctx, m.cancelFunc = context.WithCancel(ctx)
+	return m.DownloadFileRange(ctx, bucket, key, localPath, aws.ByteRange{})
+}
+
+// DownloadFileRange downloads part of a single file, e.g. its first or last
+// few MB, or the whole file for the zero ByteRange
+func (m *Manager) DownloadFileRange(ctx context.Context, bucket, key, localPath string, r aws.ByteRange) error {
+	// A file named after its key gets the same checks as a multi-download's
+	// files; a name the user chose is kept as it is
+	var escaped bool
+	if name := filepath.Base(localPath); name == path.Base(key) {
+		rel, err := security.LocalRelPath(name, m.pathPolicy)
+		if err != nil {
+			return fmt.Errorf("key %s: %w", key, err)
+		}
+		escaped = rel != name
+		localPath = filepath.Join(filepath.Dir(localPath), rel)
+	}
+
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
 
 	// Get file metadata
 	obj, err := m.client.GetObjectMetadata(ctx, bucket, key)
 	if err != nil {
 		return err
 	}
+	size := r.Size(obj.Size)
 
+	now := time.Now()
 	m.progressMu.Lock()
 	m.progress = Progress{
+		Bucket:      bucket,
 		TotalFiles:  1,
-		TotalBytes:  obj.Size,
+		TotalBytes:  size,
 		CurrentFile: key,
 		Files: map[string]*FileProgress{
 			key: {
-				Key:       key,
-				LocalPath: localPath,
-				Size:      obj.Size,
-				Status:    StatusInProgress,
-				StartedAt: time.Now(),
+				Key:            key,
+				LocalPath:      localPath,
+				Size:           size,
+				Status:         StatusInProgress,
+				StartedAt:      now,
+				LastProgressAt: now,
+				Escaped:        escaped,
 			},
 		},
-		StartedAt: time.Now(),
+		StartedAt: now,
 		Status:    StatusInProgress,
+		Workers:   []WorkerStatus{{ID: 1, Key: key, StartedAt: now}},
+	}
+	if escaped {
+		m.progress.EscapedFiles = 1
 	}
 	m.progressMu.Unlock()
 
 	m.notifyProgress()
 
-	err = m.client.DownloadFile(ctx, bucket, key, localPath, func(dp aws.DownloadProgress) {
-		m.progressMu.Lock()
-		m.progress.DownloadedBytes = dp.BytesDownloaded
-		if fp, ok := m.progress.Files[key]; ok {
-			fp.Downloaded = dp.BytesDownloaded
-		}
-		m.progressMu.Unlock()
-		m.notifyProgress()
-	})
+	stopWatch := m.watchStalls(ctx)
+	if r.IsZero() {
+		err = m.downloadObject(ctx, bucket, *obj, localPath, 0)
+	} else {
+		err = m.transferObject(ctx, key, 0, func(ctx context.Context, onProgress func(aws.DownloadProgress)) error {
+			return m.client.DownloadFileRange(ctx, bucket, key, localPath, r, onProgress)
+		})
+	}
+	stopWatch()
 
 	m.progressMu.Lock()
+	m.progress.Workers[0].Key = ""
+	if err != nil && ctx.Err() == nil {
+		m.progress.Workers[0].LastError = err
+	} else if err == nil {
+		m.progress.Workers[0].FilesDone = 1
+	}
 	if err != nil {
 		if ctx.Err() != nil {
 			m.progress.Status = StatusCancelled
@@ -188,9 +506,185 @@ func (m *Manager) DownloadFile(ctx context.Context, bucket, key, localPath strin
 	return err
 }
 
+// UploadFile uploads a single local file to bucket/key
+func (m *Manager) UploadFile(ctx context.Context, bucket, key, localPath string, attrs aws.ObjectAttributes) error {
+	return m.singleUpload(ctx, bucket, key, localPath, func(ctx context.Context) error {
+		return m.uploadObject(ctx, bucket, key, localPath, attrs, 0)
+	})
+}
+
+// ResumeUpload finishes an interrupted multipart upload of localPath to
+// bucket/key, uploading only the parts S3 doesn't already have
+func (m *Manager) ResumeUpload(ctx context.Context, bucket, key, localPath string, upload *aws.IncompleteUpload) error {
+	return m.singleUpload(ctx, bucket, key, localPath, func(ctx context.Context) error {
+		return m.transferObject(ctx, key, 0, func(ctx context.Context, onProgress func(aws.DownloadProgress)) error {
+			return m.client.ResumeUpload(ctx, bucket, key, localPath, upload, m.uploadOpts, onProgress)
+		})
+	})
+}
+
+// singleUpload tracks the progress of a one-file upload job
+func (m *Manager) singleUpload(ctx context.Context, bucket, key, localPath string, upload func(context.Context) error) error {
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return fmt.Errorf("failed to stat local file: %w", err)
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("not a regular file: %s", localPath)
+	}
+
+	now := time.Now()
+	m.progressMu.Lock()
+	m.progress = Progress{
+		Bucket:      bucket,
+		TotalFiles:  1,
+		TotalBytes:  info.Size(),
+		CurrentFile: key,
+		Files: map[string]*FileProgress{
+			key: {
+				Key:            key,
+				LocalPath:      localPath,
+				Size:           info.Size(),
+				Status:         StatusInProgress,
+				StartedAt:      now,
+				LastProgressAt: now,
+			},
+		},
+		StartedAt: now,
+		Status:    StatusInProgress,
+		Direction: DirectionUpload,
+		Workers:   []WorkerStatus{{ID: 1, Key: key, StartedAt: now}},
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+
+	err = upload(ctx)
+
+	m.progressMu.Lock()
+	m.progress.Workers[0].Key = ""
+	fp := m.progress.Files[key]
+	switch {
+	case err != nil && ctx.Err() != nil:
+		m.progress.Status = StatusCancelled
+		fp.Status = StatusCancelled
+	case err != nil:
+		m.progress.Status = StatusFailed
+		m.progress.FailedFiles = 1
+		m.progress.Workers[0].LastError = err
+		fp.Status = StatusFailed
+		fp.Error = err
+	default:
+		m.progress.Status = StatusCompleted
+		m.progress.CompletedFiles = 1
+		m.progress.Workers[0].FilesDone = 1
+		fp.Status = StatusCompleted
+		fp.CompletedAt = time.Now()
+		m.setDownloaded(fp, fp.Size)
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+	m.notifyComplete()
+
+	return err
+}
+
+// UploadMultiple uploads local files to their keys using the worker pool.
+// attrs apply to every object; an empty content type is detected per file.
+// summary is shown alongside the job's progress.
+func (m *Manager) UploadMultiple(ctx context.Context, bucket string, files []LocalFile, attrs aws.ObjectAttributes, summary string) error {
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if len(files) == 0 {
+		return fmt.Errorf("no files to upload")
+	}
+
+	var totalBytes int64
+	progressFiles := make(map[string]*FileProgress, len(files))
+	for _, f := range files {
+		totalBytes += f.Size
+		progressFiles[f.Key] = &FileProgress{
+			Key:       f.Key,
+			LocalPath: f.Path,
+			Size:      f.Size,
+			Status:    StatusPending,
+		}
+	}
+
+	m.progressMu.Lock()
+	m.progress = Progress{
+		Bucket:     bucket,
+		TotalFiles: len(files),
+		TotalBytes: totalBytes,
+		Files:      progressFiles,
+		StartedAt:  time.Now(),
+		Status:     StatusInProgress,
+		Direction:  DirectionUpload,
+		Summary:    summary,
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+
+	err = m.uploadWithWorkers(ctx, bucket, files, attrs)
+
+	m.progressMu.Lock()
+	if err != nil && ctx.Err() != nil {
+		m.progress.Status = StatusCancelled
+	} else if m.progress.FailedFiles > 0 {
+		m.progress.Status = StatusFailed
+	} else {
+		m.progress.Status = StatusCompleted
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+	m.notifyComplete()
+
+	return err
+}
+
+// uploadWithWorkers uploads files using a worker pool
+func (m *Manager) uploadWithWorkers(ctx context.Context, bucket string, files []LocalFile, attrs aws.ObjectAttributes) error {
+	stopWatch := m.watchStalls(ctx)
+	defer stopWatch()
+
+	m.progressMu.Lock()
+	m.startWorkers("")
+	// Count on from files settled before any transfer, like missing keys
+	counts := fileCounts{completed: m.progress.CompletedFiles, failed: m.progress.FailedFiles}
+	m.progressMu.Unlock()
+
+	return pool.Each(ctx, m.workers, files, func(worker int, f LocalFile) {
+		if ctx.Err() != nil {
+			return
+		}
+		m.startFile(f.Key, worker)
+		m.notifyProgress()
+
+		err := m.uploadObject(ctx, bucket, f.Key, f.Path, attrs, worker)
+		m.endFile(ctx, aws.S3Object{Key: f.Key, Size: f.Size}, worker, err, &counts)
+	})
+}
+
 // DownloadPrefix downloads all files under a prefix
 func (m *Manager) DownloadPrefix(ctx context.Context, bucket, prefix, localDir string) error {
-	ctx, m.cancelFunc = context.WithCancel(ctx)
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
 
 	// List all objects under the prefix
 	objects, err := m.client.ListAllObjects(ctx, bucket, prefix)
@@ -202,32 +696,37 @@ func (m *Manager) DownloadPrefix(ctx context.Context, bucket, prefix, localDir s
 		return fmt.Errorf("no files found under prefix: %s", prefix)
 	}
 
+	plan, err := planLocalPaths(objects, prefix, localDir, m.collisions, m.pathPolicy)
+	if err != nil {
+		return err
+	}
+	objects = plan.objects
+
 	// Initialize progress
 	var totalBytes int64
 	files := make(map[string]*FileProgress)
 	for _, obj := range objects {
 		totalBytes += obj.Size
-		// Calculate local path relative to prefix with path traversal protection
-		relPath := strings.TrimPrefix(obj.Key, prefix)
-		localPath, err := security.SafePath(localDir, relPath)
-		if err != nil {
-			return fmt.Errorf("unsafe path for key %s: %w", obj.Key, err)
-		}
 		files[obj.Key] = &FileProgress{
-			Key:       obj.Key,
-			LocalPath: localPath,
-			Size:      obj.Size,
-			Status:    StatusPending,
+			Key:          obj.Key,
+			LocalPath:    plan.paths[obj.Key],
+			Size:         obj.Size,
+			Status:       StatusPending,
+			CollidedWith: plan.collisions[obj.Key],
+			Escaped:      plan.escaped[obj.Key],
 		}
 	}
 
 	m.progressMu.Lock()
 	m.progress = Progress{
-		TotalFiles: len(objects),
-		TotalBytes: totalBytes,
-		Files:      files,
-		StartedAt:  time.Now(),
-		Status:     StatusInProgress,
+		Bucket:       bucket,
+		TotalFiles:   len(objects),
+		TotalBytes:   totalBytes,
+		Files:        files,
+		StartedAt:    time.Now(),
+		Status:       StatusInProgress,
+		Collisions:   len(plan.collisions),
+		EscapedFiles: len(plan.escaped),
 	}
 	m.progressMu.Unlock()
 
@@ -254,7 +753,11 @@ func (m *Manager) DownloadPrefix(ctx context.Context, bucket, prefix, localDir s
 
 // DownloadMultiple downloads multiple selected objects
 func (m *Manager) DownloadMultiple(ctx context.Context, bucket string, objects []aws.S3Object, prefix, localDir string) error {
-	ctx, m.cancelFunc = context.WithCancel(ctx)
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
 
 	if len(objects) == 0 {
 		return fmt.Errorf("no files to download")
@@ -279,36 +782,41 @@ func (m *Manager) DownloadMultiple(ctx context.Context, bucket string, objects [
 		}
 	}
 
+	plan, err := planLocalPaths(allObjects, prefix, localDir, m.collisions, m.pathPolicy)
+	if err != nil {
+		return err
+	}
+	allObjects = plan.objects
+
 	for _, obj := range allObjects {
 		totalBytes += obj.Size
-		// Calculate local path relative to prefix with path traversal protection
-		relPath := strings.TrimPrefix(obj.Key, prefix)
-		localPath, err := security.SafePath(localDir, relPath)
-		if err != nil {
-			return fmt.Errorf("unsafe path for key %s: %w", obj.Key, err)
-		}
 		files[obj.Key] = &FileProgress{
-			Key:       obj.Key,
-			LocalPath: localPath,
-			Size:      obj.Size,
-			Status:    StatusPending,
+			Key:          obj.Key,
+			LocalPath:    plan.paths[obj.Key],
+			Size:         obj.Size,
+			Status:       StatusPending,
+			CollidedWith: plan.collisions[obj.Key],
+			Escaped:      plan.escaped[obj.Key],
 		}
 	}
 
 	m.progressMu.Lock()
 	m.progress = Progress{
-		TotalFiles: len(allObjects),
-		TotalBytes: totalBytes,
-		Files:      files,
-		StartedAt:  time.Now(),
-		Status:     StatusInProgress,
+		Bucket:       bucket,
+		TotalFiles:   len(allObjects),
+		TotalBytes:   totalBytes,
+		Files:        files,
+		StartedAt:    time.Now(),
+		Status:       StatusInProgress,
+		Collisions:   len(plan.collisions),
+		EscapedFiles: len(plan.escaped),
 	}
 	m.progressMu.Unlock()
 
 	m.notifyProgress()
 
 	// Download files using worker pool
-	err := m.downloadWithWorkers(ctx, bucket, allObjects, prefix, localDir)
+	err = m.downloadWithWorkers(ctx, bucket, allObjects, prefix, localDir)
 
 	m.progressMu.Lock()
 	if err != nil && ctx.Err() != nil {
@@ -328,118 +836,543 @@ func (m *Manager) DownloadMultiple(ctx context.Context, bucket string, objects [
 
 // downloadWithWorkers downloads files using a worker pool
 func (m *Manager) downloadWithWorkers(ctx context.Context, bucket string, objects []aws.S3Object, prefix, localDir string) error {
-	jobs := make(chan aws.S3Object, len(objects))
-	var wg sync.WaitGroup
-	var downloadedBytes int64
-	var completedFiles int32
-	var failedFiles int32
-
-	// Start workers
-	for i := 0; i < m.workers; i++ {
-		wg.Add(1)
-		go func() {
-			defer wg.Done()
-			for obj := range jobs {
-				select {
-				case <-ctx.Done():
+	ordered := m.order.apply(objects)
+	var counts fileCounts
+
+	stopWatch := m.watchStalls(ctx)
+	defer stopWatch()
+
+	m.progressMu.Lock()
+	m.startWorkers(bucket)
+	m.progressMu.Unlock()
+
+	// Send jobs, splitting a dominant file into ranges so idle workers can
+	// share it instead of leaving one worker with a long sequential tail
+	var totalBytes int64
+	for _, obj := range ordered {
+		totalBytes += obj.Size
+	}
+
+	var splits []downloadJob // the first chunk of each split file
+	feed := func(send func(downloadJob) bool) {
+		for _, obj := range ordered {
+			planned := m.planJobs(obj, totalBytes, len(ordered))
+			if planned[0].split != nil {
+				splits = append(splits, planned[0])
+			}
+			for _, job := range planned {
+				if !send(job) {
 					return
-				default:
 				}
+			}
+		}
+	}
+	err := pool.Run(ctx, m.workers, feed, func(worker int, job downloadJob) {
+		if ctx.Err() != nil {
+			return
+		}
+		if job.split != nil {
+			m.runChunk(ctx, bucket, job, worker, &counts)
+			return
+		}
+		obj := job.obj
 
-				// Get the pre-validated local path from FileProgress
-				m.progressMu.Lock()
-				m.progress.CurrentFile = obj.Key
-				var localPath string
-				if fp, ok := m.progress.Files[obj.Key]; ok {
-					localPath = fp.LocalPath
-					fp.Status = StatusInProgress
-					fp.StartedAt = time.Now()
-				}
-				m.progressMu.Unlock()
-
-				if localPath == "" {
-					// Fallback with validation if not in progress map
-					relPath := strings.TrimPrefix(obj.Key, prefix)
-					var err error
-					localPath, err = security.SafePath(localDir, relPath)
-					if err != nil {
-						atomic.AddInt32(&failedFiles, 1)
-						m.progressMu.Lock()
-						if fp, ok := m.progress.Files[obj.Key]; ok {
-							fp.Status = StatusFailed
-							fp.Error = err
-						}
-						m.progress.FailedFiles = int(atomic.LoadInt32(&failedFiles))
-						m.progressMu.Unlock()
-						continue
-					}
-				}
+		// Get the pre-validated local path from FileProgress
+		localPath := m.startFile(obj.Key, worker)
+		if localPath == "" {
+			// Fallback with validation if not in progress map
+			var err error
+			localPath, _, err = keyPath(localDir, strings.TrimPrefix(obj.Key, prefix), m.pathPolicy)
+			if err != nil {
+				m.endFile(ctx, obj, worker, err, &counts)
+				return
+			}
+		}
+		m.notifyProgress()
 
-				m.notifyProgress()
+		err := m.downloadObject(ctx, bucket, obj, localPath, worker)
+		m.endFile(ctx, obj, worker, err, &counts)
+	})
+
+	// Chunks skipped after a cancel leave their file open and partial
+	for _, job := range splits {
+		if !job.split.abort() {
+			continue
+		}
+		m.progressMu.Lock()
+		if fp, ok := m.progress.Files[job.obj.Key]; ok && fp.Status == StatusInProgress && ctx.Err() != nil {
+			m.finishFile(ctx, job.obj, ctx.Err(), &counts)
+		}
+		m.progressMu.Unlock()
+	}
+	return err
+}
+
+// startWorkers gives each worker a status line, noting the mirror it reads
+// bucket from. Caller must hold progressMu.
+func (m *Manager) startWorkers(bucket string) {
+	m.progress.Workers = make([]WorkerStatus, m.workers)
+	for i := range m.progress.Workers {
+		m.progress.Workers[i].ID = i + 1
+		if _, _, mirror := m.source(bucket, i); mirror != nil {
+			m.progress.Workers[i].Source = mirror.Name
+		}
+	}
+}
+
+// startFile marks key in progress on worker and returns its local path, if
+// it has one
+func (m *Manager) startFile(key string, worker int) string {
+	m.progressMu.Lock()
+	defer m.progressMu.Unlock()
+	m.progress.CurrentFile = key
+	var localPath string
+	if fp, ok := m.progress.Files[key]; ok {
+		localPath = fp.LocalPath
+		fp.Status = StatusInProgress
+		fp.StartedAt = time.Now()
+		fp.LastProgressAt = fp.StartedAt
+	}
+	w := &m.progress.Workers[worker]
+	w.Key = key
+	w.Downloaded = 0
+	w.StartedAt = time.Now()
+	return localPath
+}
+
+// endFile frees worker and records the outcome of its transfer of obj
+func (m *Manager) endFile(ctx context.Context, obj aws.S3Object, worker int, err error, counts *fileCounts) {
+	m.progressMu.Lock()
+	w := &m.progress.Workers[worker]
+	w.Key = ""
+	w.Downloaded = 0
+	if err != nil && ctx.Err() == nil {
+		w.LastError = err
+	} else if err == nil {
+		w.FilesDone++
+	}
+	m.finishFile(ctx, obj, err, counts)
+	m.progressMu.Unlock()
+	m.notifyProgress()
+}
+
+// fileCounts tracks finished files across workers
+type fileCounts struct {
+	completed int
+	failed    int
+}
+
+// finishFile records the outcome of a whole file.
+// Caller must hold progressMu.
+func (m *Manager) finishFile(ctx context.Context, obj aws.S3Object, err error, counts *fileCounts) {
+	fp, ok := m.progress.Files[obj.Key]
+	if err != nil {
+		counts.failed++
+		if ok {
+			if ctx.Err() != nil {
+				fp.Status = StatusCancelled
+			} else {
+				fp.Status = StatusFailed
+				fp.Error = err
+			}
+		}
+		m.progress.FailedFiles = counts.failed
+		return
+	}
+
+	counts.completed++
+	if ok {
+		fp.Status = StatusCompleted
+		fp.CompletedAt = time.Now()
+		m.setDownloaded(fp, obj.Size)
+	}
+	m.progress.CompletedFiles = counts.completed
+}
+
+// Large file splitting thresholds
+const (
+	splitMinSize   = 256 * 1024 * 1024 // only split files at least this large
+	splitChunkSize = 64 * 1024 * 1024  // minimum size of each ranged chunk
+)
+
+// downloadJob is a unit of work for a worker: a whole object, or one byte
+// range of an object that has been split across workers
+type downloadJob struct {
+	obj    aws.S3Object
+	split  *splitDownload // nil for whole-object jobs
+	offset int64
+	length int64
+	chunk  int
+}
+
+// splitDownload coordinates the ranged chunks of a single file
+type splitDownload struct {
+	mu        sync.Mutex
+	localPath string
+	file      *os.File
+	created   bool    // the file was opened, so a failure should remove it
+	chunks    []int64 // bytes received per chunk
+	remaining int
+	err       error
+}
+
+// shouldSplit returns true if obj dominates a batch of count files totalling
+// totalBytes: it is large and makes up more than half of the bytes to transfer
+func (m *Manager) shouldSplit(obj aws.S3Object, totalBytes int64, count int) bool {
+	if m.workers < 2 || count < 2 || obj.Size < m.splitMin {
+		return false
+	}
+	return obj.Size*2 > totalBytes
+}
+
+// planJobs returns the jobs for one object of the batch
+func (m *Manager) planJobs(obj aws.S3Object, totalBytes int64, count int) []downloadJob {
+	if !m.shouldSplit(obj, totalBytes, count) {
+		return []downloadJob{{obj: obj}}
+	}
+
+	chunkSize := (obj.Size + int64(m.workers) - 1) / int64(m.workers)
+	if chunkSize < m.splitChunk {
+		chunkSize = m.splitChunk
+	}
+	n := int((obj.Size + chunkSize - 1) / chunkSize)
+
+	m.progressMu.RLock()
+	var localPath string
+	if fp, ok := m.progress.Files[obj.Key]; ok {
+		localPath = fp.LocalPath
+	}
+	m.progressMu.RUnlock()
+	if localPath == "" {
+		return []downloadJob{{obj: obj}}
+	}
+
+	split := &splitDownload{
+		localPath: localPath,
+		chunks:    make([]int64, n),
+		remaining: n,
+	}
+	jobs := make([]downloadJob, n)
+	for i := range jobs {
+		offset := int64(i) * chunkSize
+		length := chunkSize
+		if offset+length > obj.Size {
+			length = obj.Size - offset
+		}
+		jobs[i] = downloadJob{obj: obj, split: split, offset: offset, length: length, chunk: i}
+	}
+	return jobs
+}
+
+// open creates the destination file on first use, sized to the object
+func (s *splitDownload) open(size int64) (*os.File, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.file != nil || s.err != nil {
+		return s.file, s.err
+	}
+	if err := os.MkdirAll(filepath.Dir(s.localPath), 0750); err != nil {
+		s.err = fmt.Errorf("failed to create directory: %w", err)
+		return nil, s.err
+	}
+	file, err := os.OpenFile(s.localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		s.err = fmt.Errorf("failed to create local file: %w", err)
+		return nil, s.err
+	}
+	s.created = true
+	if err := file.Truncate(size); err != nil {
+		file.Close()
+		s.err = fmt.Errorf("failed to allocate local file: %w", err)
+		return nil, s.err
+	}
+	s.file = file
+	return file, nil
+}
+
+// record sets how many bytes of a chunk have arrived and returns the total
+// for the file
+func (s *splitDownload) record(chunk int, n int64) int64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.chunks[chunk] = n
+	var received int64
+	for _, c := range s.chunks {
+		received += c
+	}
+	return received
+}
+
+// abort closes and removes the file of a split download whose chunks didn't
+// all run, e.g. after a cancel. A file no chunk got as far as opening is left
+// alone, as it may be one the download was about to replace. It returns
+// false if every chunk finished.
+func (s *splitDownload) abort() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.remaining == 0 {
+		return false
+	}
+	s.remaining = 0
+	if s.file != nil {
+		s.file.Close()
+		s.file = nil
+	}
+	if s.created {
+		os.Remove(s.localPath)
+	}
+	return true
+}
+
+// finish records a chunk result. It returns true when the last chunk is done,
+// along with the first error any chunk reported.
+func (s *splitDownload) finish(err error) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err != nil && s.err == nil {
+		s.err = err
+	}
+	s.remaining--
+	if s.remaining > 0 {
+		return false, s.err
+	}
+	if s.file != nil {
+		if cerr := s.file.Close(); cerr != nil && s.err == nil {
+			s.err = cerr
+		}
+	}
+	if s.err != nil && s.created {
+		os.Remove(s.localPath) // Clean up on failure
+	}
+	return true, s.err
+}
+
+// runChunk downloads one byte range of a split file
+func (m *Manager) runChunk(ctx context.Context, bucket string, job downloadJob, worker int, counts *fileCounts) {
+	key := job.obj.Key
+
+	m.progressMu.Lock()
+	m.progress.CurrentFile = key
+	if fp, ok := m.progress.Files[key]; ok && fp.Status == StatusPending {
+		fp.Status = StatusInProgress
+		fp.StartedAt = time.Now()
+		fp.LastProgressAt = fp.StartedAt
+	}
+	w := &m.progress.Workers[worker]
+	w.Key = fmt.Sprintf("%s [part %d/%d]", key, job.chunk+1, len(job.split.chunks))
+	w.Downloaded = 0
+	w.StartedAt = time.Now()
+	m.progressMu.Unlock()
+	m.notifyProgress()
+
+	file, err := job.split.open(job.obj.Size)
+	if err == nil {
+		err = m.retryStalls(ctx, key, worker, func(ctx context.Context) error {
+			return m.fromSource(ctx, bucket, job.obj, worker, func(client Client, bucket string) error {
+				return client.DownloadRange(ctx, bucket, key, file, job.offset, job.length, func(dp aws.DownloadProgress) {
+					received := job.split.record(job.chunk, dp.BytesDownloaded)
 
-				err := m.client.DownloadFile(ctx, bucket, obj.Key, localPath, func(dp aws.DownloadProgress) {
 					m.progressMu.Lock()
-					if fp, ok := m.progress.Files[obj.Key]; ok {
-						fp.Downloaded = dp.BytesDownloaded
+					if fp, ok := m.progress.Files[key]; ok {
+						fp.LastProgressAt = time.Now()
+						m.unstall(fp)
+						m.setDownloaded(fp, received)
 					}
-					// Update total downloaded
-					var total int64
-					for _, fp := range m.progress.Files {
-						total += fp.Downloaded
-					}
-					m.progress.DownloadedBytes = total
+					m.progress.Workers[worker].Downloaded = dp.BytesDownloaded
 					m.progressMu.Unlock()
 					m.notifyProgress()
 				})
+			})
+		}, func(fp *FileProgress) {
+			m.setDownloaded(fp, job.split.record(job.chunk, 0))
+		})
+	}
 
-				m.progressMu.Lock()
-				if err != nil {
-					atomic.AddInt32(&failedFiles, 1)
-					if fp, ok := m.progress.Files[obj.Key]; ok {
-						if ctx.Err() != nil {
-							fp.Status = StatusCancelled
-						} else {
-							fp.Status = StatusFailed
-							fp.Error = err
-						}
-					}
-					m.progress.FailedFiles = int(atomic.LoadInt32(&failedFiles))
-				} else {
-					atomic.AddInt64(&downloadedBytes, obj.Size)
-					atomic.AddInt32(&completedFiles, 1)
-					if fp, ok := m.progress.Files[obj.Key]; ok {
-						fp.Status = StatusCompleted
-						fp.Downloaded = obj.Size
-						fp.CompletedAt = time.Now()
-					}
-					m.progress.CompletedFiles = int(atomic.LoadInt32(&completedFiles))
-				}
-				m.progressMu.Unlock()
-				m.notifyProgress()
-			}
-		}()
+	done, fileErr := job.split.finish(err)
+
+	m.progressMu.Lock()
+	w = &m.progress.Workers[worker]
+	w.Key = ""
+	w.Downloaded = 0
+	if err != nil && ctx.Err() == nil {
+		w.LastError = err
 	}
+	if done {
+		if fileErr == nil {
+			w.FilesDone++
+		}
+		m.finishFile(ctx, job.obj, fileErr, counts)
+	}
+	m.progressMu.Unlock()
+	m.notifyProgress()
+}
 
-	// Send jobs
-	for _, obj := range objects {
-		select {
-		case <-ctx.Done():
-			close(jobs)
-			wg.Wait()
-			return ctx.Err()
-		case jobs <- obj:
+// downloadObject downloads a single object on behalf of the given worker
+func (m *Manager) downloadObject(ctx context.Context, bucket string, obj aws.S3Object, localPath string, worker int) error {
+	return m.transferObject(ctx, obj.Key, worker, func(ctx context.Context, onProgress func(aws.DownloadProgress)) error {
+		return m.fromSource(ctx, bucket, obj, worker, func(client Client, bucket string) error {
+			return client.DownloadFile(ctx, bucket, obj.Key, localPath, m.downloadOpts, onProgress)
+		})
+	})
+}
+
+// uploadObject uploads a single local file on behalf of the given worker
+func (m *Manager) uploadObject(ctx context.Context, bucket, key, localPath string, attrs aws.ObjectAttributes, worker int) error {
+	return m.transferObject(ctx, key, worker, func(ctx context.Context, onProgress func(aws.DownloadProgress)) error {
+		return m.client.UploadFile(ctx, bucket, key, localPath, m.uploadOpts, attrs, onProgress)
+	})
+}
+
+// transferObject runs a single-file transfer, feeding byte counts into the
+// progress model and the status of the given worker. Stalled files are
+// restarted up to maxStallRetries times when automatic retry is enabled.
+func (m *Manager) transferObject(ctx context.Context, key string, worker int, transfer func(context.Context, func(aws.DownloadProgress)) error) error {
+	return m.retryStalls(ctx, key, worker, func(ctx context.Context) error {
+		return transfer(ctx, func(dp aws.DownloadProgress) {
+			m.progressMu.Lock()
+			if fp, ok := m.progress.Files[key]; ok {
+				fp.LastProgressAt = time.Now()
+				fp.Parts = dp.PartsTotal
+				fp.PartsDone = dp.PartsDone
+				m.unstall(fp)
+				m.setDownloaded(fp, dp.BytesDownloaded)
+			}
+			if worker < len(m.progress.Workers) {
+				m.progress.Workers[worker].Downloaded = dp.BytesDownloaded
+			}
+			m.progressMu.Unlock()
+			m.notifyProgress()
+		})
+	}, func(fp *FileProgress) {
+		m.setDownloaded(fp, 0)
+	})
+}
+
+// fileAttempt is a worker's attempt at transferring a file, or a chunk of
+// one, which the stall watcher can cancel
+type fileAttempt struct {
+	cancel  context.CancelFunc
+	stalled bool // cancelled by the stall watcher, to be retried
+}
+
+// retryStalls runs attempt for the given worker's share of key, restarting
+// it up to maxStallRetries times when the stall watcher cancels it. reset
+// takes back the bytes a cancelled attempt had counted; it's called with
+// progressMu held.
+func (m *Manager) retryStalls(ctx context.Context, key string, worker int, attempt func(context.Context) error, reset func(*FileProgress)) error {
+	for tries := 0; ; tries++ {
+		attemptCtx, cancel := context.WithCancel(ctx)
+		a := &fileAttempt{cancel: cancel}
+		m.progressMu.Lock()
+		if m.fileCancels[key] == nil {
+			m.fileCancels[key] = make(map[int]*fileAttempt)
+		}
+		m.fileCancels[key][worker] = a
+		m.progressMu.Unlock()
+
+		err := attempt(attemptCtx)
+		cancel()
+
+		m.progressMu.Lock()
+		delete(m.fileCancels[key], worker)
+		if len(m.fileCancels[key]) == 0 {
+			delete(m.fileCancels, key)
 		}
+		fp := m.progress.Files[key]
+		retry := err != nil && ctx.Err() == nil && fp != nil && a.stalled && tries < maxStallRetries
+		if retry {
+			// The first chunk of a file to restart counts the retry
+			if fp.Stalled {
+				fp.Retries++
+			}
+			m.unstall(fp)
+			fp.LastProgressAt = time.Now()
+			if worker < len(m.progress.Workers) {
+				m.progress.Workers[worker].Downloaded = 0
+				m.progress.Workers[worker].StartedAt = time.Now()
+			}
+			reset(fp)
+		}
+		m.progressMu.Unlock()
+
+		if !retry {
+			return err
+		}
+		m.notifyProgress()
+	}
+}
+
+// unstall clears a file's stalled flag once bytes arrive again or it's
+// restarted. Caller must hold progressMu.
+func (m *Manager) unstall(fp *FileProgress) {
+	if fp.Stalled {
+		fp.Stalled = false
+		m.progress.StalledFiles--
 	}
-	close(jobs)
+}
 
-	wg.Wait()
-	return nil
+// setDownloaded records how many bytes of fp have been transferred,
+// adjusting the job's total by the difference rather than summing every
+// file, and samples the throughput. Caller must hold progressMu.
+func (m *Manager) setDownloaded(fp *FileProgress, n int64) {
+	m.progress.DownloadedBytes += n - fp.Downloaded
+	fp.Downloaded = n
+	m.progress.sampleThroughput(time.Now())
+}
+
+// watchStalls periodically flags in-progress files that haven't received
+// bytes within the stall timeout. The returned function stops the watcher.
+func (m *Manager) watchStalls(ctx context.Context) func() {
+	done := make(chan struct{})
+	go func() {
+		ticker := time.NewTicker(time.Second)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-done:
+				return
+			case now := <-ticker.C:
+				if m.checkStalls(now) {
+					m.notifyProgress()
+				}
+			}
+		}
+	}()
+	return func() { close(done) }
+}
+
+// checkStalls flags newly stalled files and, if enabled, cancels them so
+// downloadObject restarts them. It returns true if anything changed.
+func (m *Manager) checkStalls(now time.Time) bool {
+	m.progressMu.Lock()
+	defer m.progressMu.Unlock()
+
+	changed := false
+	for key, fp := range m.progress.Files {
+		if fp.Status != StatusInProgress || fp.Stalled || now.Sub(fp.LastProgressAt) < m.stallTimeout {
+			continue
+		}
+		fp.Stalled = true
+		m.progress.StalledFiles++
+		changed = true
+		if m.retryStalled && fp.Retries < maxStallRetries {
+			for _, a := range m.fileCancels[key] {
+				a.stalled = true
+				a.cancel()
+			}
+		}
+	}
+	// Keep the throughput estimate decaying while nothing arrives
+	m.progress.sampleThroughput(now)
+	return changed
 }
 
 func (m *Manager) notifyProgress() {
 	if m.onProgress != nil {
 		m.progressMu.RLock()
-		p := m.progress
+		p := m.snapshot()
 		m.progressMu.RUnlock()
 		m.onProgress(p)
 	}
@@ -448,7 +1381,7 @@ func (m *Manager) notifyProgress() {
 func (m *Manager) notifyComplete() {
 	if m.onComplete != nil {
 		m.progressMu.RLock()
-		p := m.progress
+		p := m.snapshot()
 		m.progressMu.RUnlock()
 		m.onComplete(p)
 	}
@@ -0,0 +1,889 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"crypto/md5"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/security"
+)
+
+var errInjected = errors.New("injected failure")
+
+// chaosClient is an in-memory Client whose downloads can fail, stall or
+// call back at chosen points. Methods the tests don't use panic through
+// the nil embedded Client.
+type chaosClient struct {
+	Client
+
+	objects map[string][]byte
+	chunk   int64                        // bytes per progress report
+	delay   time.Duration                // pause before each chunk
+	failAt  map[string]int64             // key → bytes sent before errInjected
+	stalls  map[string]int               // key → attempts that hang until cancelled
+	onChunk func(key string, sent int64) // called after each chunk is written
+	onStart func(ctx context.Context, key string)
+
+	mu       sync.Mutex
+	attempts map[string]int
+	heads    map[string]int // key → GetObjectMetadata calls
+}
+
+func newChaosClient(objects map[string][]byte) *chaosClient {
+	return &chaosClient{
+		objects:  objects,
+		chunk:    4,
+		failAt:   make(map[string]int64),
+		stalls:   make(map[string]int),
+		attempts: make(map[string]int),
+		heads:    make(map[string]int),
+	}
+}
+
+// etag is the ETag S3 gives an object uploaded in one part
+func etag(data []byte) string {
+	return fmt.Sprintf("%x", md5.Sum(data))
+}
+
+func (c *chaosClient) ListAllObjects(ctx context.Context, bucket, prefix string) ([]aws.S3Object, error) {
+	var objects []aws.S3Object
+	for key, data := range c.objects {
+		if strings.HasPrefix(key, prefix) {
+			objects = append(objects, aws.S3Object{Key: key, Size: int64(len(data)), ETag: etag(data)})
+		}
+	}
+	sort.Slice(objects, func(i, j int) bool { return objects[i].Key < objects[j].Key })
+	return objects, nil
+}
+
+func (c *chaosClient) GetObjectMetadata(ctx context.Context, bucket, key string) (*aws.S3Object, error) {
+	c.mu.Lock()
+	c.heads[key]++
+	c.mu.Unlock()
+	data, ok := c.objects[key]
+	if !ok {
+		return nil, fmt.Errorf("no such key %s", key)
+	}
+	return &aws.S3Object{Key: key, Size: int64(len(data)), ETag: etag(data)}, nil
+}
+
+func (c *chaosClient) DownloadFile(ctx context.Context, bucket, key, localPath string, opts aws.DownloadOptions, onProgress func(aws.DownloadProgress)) error {
+	c.mu.Lock()
+	c.attempts[key]++
+	attempt := c.attempts[key]
+	c.mu.Unlock()
+	if c.onStart != nil {
+		c.onStart(ctx, key)
+	}
+
+	data, ok := c.objects[key]
+	if !ok {
+		return fmt.Errorf("no such key %s", key)
+	}
+	if attempt <= c.stalls[key] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	if err := os.MkdirAll(filepath.Dir(localPath), 0750); err != nil {
+		return err
+	}
+	file, err := os.OpenFile(localPath, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	size := int64(len(data))
+	failAt, fails := c.failAt[key]
+	for sent := int64(0); sent < size; {
+		if fails && sent >= failAt {
+			os.Remove(localPath)
+			return errInjected
+		}
+		select {
+		case <-ctx.Done():
+			os.Remove(localPath)
+			return ctx.Err()
+		case <-time.After(c.delay):
+		}
+		n := min(c.chunk, size-sent)
+		if _, err := file.Write(data[sent : sent+n]); err != nil {
+			return err
+		}
+		sent += n
+		if onProgress != nil {
+			onProgress(aws.DownloadProgress{BytesDownloaded: sent, TotalBytes: size, Key: key})
+		}
+		if c.onChunk != nil {
+			c.onChunk(key, sent)
+		}
+	}
+	return nil
+}
+
+func (c *chaosClient) DownloadRange(ctx context.Context, bucket, key string, w io.WriterAt, offset, length int64, onProgress func(aws.DownloadProgress)) error {
+	c.mu.Lock()
+	c.attempts[key]++
+	attempt := c.attempts[key]
+	c.mu.Unlock()
+	if c.onStart != nil {
+		c.onStart(ctx, key)
+	}
+
+	data, ok := c.objects[key]
+	if !ok {
+		return fmt.Errorf("no such key %s", key)
+	}
+	if attempt <= c.stalls[key] {
+		<-ctx.Done()
+		return ctx.Err()
+	}
+
+	failAt, fails := c.failAt[key]
+	for sent := int64(0); sent < length; {
+		if fails && offset+sent >= failAt {
+			return errInjected
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(c.delay):
+		}
+		n := min(c.chunk, length-sent)
+		if _, err := w.WriteAt(data[offset+sent:offset+sent+n], offset+sent); err != nil {
+			return err
+		}
+		sent += n
+		if onProgress != nil {
+			onProgress(aws.DownloadProgress{BytesDownloaded: sent, TotalBytes: length, Key: key})
+		}
+		if c.onChunk != nil {
+			c.onChunk(key, offset+sent)
+		}
+	}
+	return nil
+}
+
+// testObjects returns n objects under data/ of different sizes
+func testObjects(n int) map[string][]byte {
+	objects := make(map[string][]byte, n)
+	for i := range n {
+		objects[fmt.Sprintf("data/%02d.txt", i)] = bytes.Repeat([]byte{byte('a' + i%26)}, 10+i*3)
+	}
+	return objects
+}
+
+// recordProgress checks every progress report the manager sends for
+// consistency and counts them
+func recordProgress(t *testing.T, m *Manager) *int {
+	var mu sync.Mutex
+	reports := new(int)
+	m.SetProgressCallback(func(p Progress) {
+		mu.Lock()
+		defer mu.Unlock()
+		*reports++
+		if p.DownloadedBytes < 0 || p.DownloadedBytes > p.TotalBytes {
+			t.Errorf("DownloadedBytes = %d of %d", p.DownloadedBytes, p.TotalBytes)
+		}
+		if p.CompletedFiles+p.FailedFiles > p.TotalFiles {
+			t.Errorf("%d completed + %d failed of %d files", p.CompletedFiles, p.FailedFiles, p.TotalFiles)
+		}
+		if p.StalledFiles < 0 {
+			t.Errorf("StalledFiles = %d", p.StalledFiles)
+		}
+		for _, w := range p.Workers {
+			if w.Downloaded < 0 {
+				t.Errorf("worker %d Downloaded = %d", w.ID, w.Downloaded)
+			}
+		}
+	})
+	return reports
+}
+
+// checkFinished fails the test if a finished job left work in progress
+func checkFinished(t *testing.T, p Progress) {
+	t.Helper()
+	for _, w := range p.Workers {
+		if !w.Idle() {
+			t.Errorf("worker %d still has %s", w.ID, w.Key)
+		}
+	}
+	completed, failed := 0, 0
+	for key, fp := range p.Files {
+		switch fp.Status {
+		case StatusInProgress:
+			t.Errorf("%s is still in progress", key)
+		case StatusCompleted:
+			completed++
+		case StatusFailed, StatusCancelled:
+			failed++
+		}
+	}
+	if completed != p.CompletedFiles || failed != p.FailedFiles {
+		t.Errorf("files say %d completed and %d failed, progress says %d and %d", completed, failed, p.CompletedFiles, p.FailedFiles)
+	}
+}
+
+func TestManagerDownloadsAll(t *testing.T) {
+	objects := testObjects(12)
+	client := newChaosClient(objects)
+	client.delay = time.Millisecond
+
+	m := NewManager(client, 4)
+	reports := recordProgress(t, m)
+	dir := t.TempDir()
+	if err := m.DownloadPrefix(context.Background(), "bucket", "data/", dir); err != nil {
+		t.Fatalf("DownloadPrefix() error = %v", err)
+	}
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusCompleted || p.CompletedFiles != len(objects) {
+		t.Errorf("Status = %s with %d files done, want completed with %d", p.Status, p.CompletedFiles, len(objects))
+	}
+	if p.DownloadedBytes != p.TotalBytes {
+		t.Errorf("DownloadedBytes = %d, want %d", p.DownloadedBytes, p.TotalBytes)
+	}
+	if *reports == 0 {
+		t.Error("no progress was reported")
+	}
+	for key, data := range objects {
+		got, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(key, "data/")))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s wasn't downloaded intact (err %v)", key, err)
+		}
+	}
+}
+
+func TestManagerInjectedFailures(t *testing.T) {
+	objects := testObjects(8)
+	client := newChaosClient(objects)
+	client.failAt["data/02.txt"] = 0
+	client.failAt["data/05.txt"] = 8 // after some bytes arrived
+
+	m := NewManager(client, 3)
+	recordProgress(t, m)
+	dir := t.TempDir()
+	m.DownloadPrefix(context.Background(), "bucket", "data/", dir)
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusFailed || p.FailedFiles != 2 || p.CompletedFiles != len(objects)-2 {
+		t.Errorf("Status = %s, %d failed, %d completed; want failed, 2, %d", p.Status, p.FailedFiles, p.CompletedFiles, len(objects)-2)
+	}
+	for _, key := range []string{"data/02.txt", "data/05.txt"} {
+		fp := p.Files[key]
+		if fp.Status != StatusFailed || !errors.Is(fp.Error, errInjected) {
+			t.Errorf("%s = %s, %v; want failed with the injected error", key, fp.Status, fp.Error)
+		}
+		if _, err := os.Stat(fp.LocalPath); !os.IsNotExist(err) {
+			t.Errorf("%s left a partial file behind", key)
+		}
+	}
+	for _, w := range p.Workers {
+		if w.LastError != nil && !errors.Is(w.LastError, errInjected) {
+			t.Errorf("worker %d LastError = %v", w.ID, w.LastError)
+		}
+	}
+}
+
+func TestManagerCancelMidTransfer(t *testing.T) {
+	objects := testObjects(10)
+	client := newChaosClient(objects)
+	client.delay = time.Millisecond
+
+	m := NewManager(client, 2)
+	recordProgress(t, m)
+	client.onChunk = func(key string, sent int64) {
+		if key == "data/04.txt" && sent >= 8 {
+			m.Cancel()
+		}
+	}
+
+	err := m.DownloadPrefix(context.Background(), "bucket", "data/", t.TempDir())
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DownloadPrefix() error = %v, want context.Canceled", err)
+	}
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusCancelled {
+		t.Errorf("Status = %s, want cancelled", p.Status)
+	}
+	if fp := p.Files["data/04.txt"]; fp.Status != StatusCancelled || fp.Error != nil {
+		t.Errorf("cancelled file = %s with error %v, want cancelled without an error", fp.Status, fp.Error)
+	}
+	if p.CompletedFiles == len(objects) {
+		t.Error("every file completed despite the cancel")
+	}
+}
+
+func TestManagerCancelBeforeStart(t *testing.T) {
+	objects := testObjects(6)
+	client := newChaosClient(objects)
+	started := make(chan struct{}, len(objects))
+	client.onStart = func(ctx context.Context, key string) { started <- struct{}{} }
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	m := NewManager(client, 2)
+	m.DownloadMultiple(ctx, "bucket", []aws.S3Object{{Key: "data/00.txt", Size: 10}}, "data/", t.TempDir())
+
+	if len(started) != 0 {
+		t.Errorf("%d downloads started after the context was cancelled", len(started))
+	}
+	if p := m.GetProgress(); p.Status != StatusCancelled || p.CompletedFiles != 0 {
+		t.Errorf("Status = %s with %d completed, want cancelled with none", p.Status, p.CompletedFiles)
+	}
+}
+
+func TestManagerRefusesConcurrentTransfers(t *testing.T) {
+	objects := testObjects(3)
+	client := newChaosClient(objects)
+	client.stalls["data/00.txt"] = 1
+	started := make(chan struct{}, len(objects))
+	client.onStart = func(ctx context.Context, key string) { started <- struct{}{} }
+
+	m := NewManager(client, 1)
+	first := make(chan error)
+	go func() { first <- m.DownloadPrefix(context.Background(), "bucket", "data/", t.TempDir()) }()
+	<-started
+
+	dir := t.TempDir()
+	if err := m.DownloadFile(context.Background(), "bucket", "data/01.txt", filepath.Join(dir, "01.txt")); !errors.Is(err, ErrBusy) {
+		t.Errorf("DownloadFile() during another transfer error = %v, want ErrBusy", err)
+	}
+	if p := m.GetProgress(); p.TotalFiles != len(objects) {
+		t.Errorf("TotalFiles = %d, want the running transfer's %d", p.TotalFiles, len(objects))
+	}
+
+	m.Cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("DownloadPrefix() error = %v, want context.Canceled", err)
+	}
+	if err := m.DownloadFile(context.Background(), "bucket", "data/01.txt", filepath.Join(dir, "01.txt")); err != nil {
+		t.Errorf("DownloadFile() after the transfer finished error = %v", err)
+	}
+}
+
+// classClient changes storage classes, reporting the keys in same as
+// already in the class
+type classClient struct {
+	Client
+	same map[string]bool
+}
+
+func (c *classClient) ChangeStorageClass(ctx context.Context, bucket, key, class string, opts aws.UploadOptions, onProgress func(aws.DownloadProgress)) error {
+	if c.same[key] {
+		return aws.ErrSameStorageClass
+	}
+	return nil
+}
+
+func TestManagerChangeStorageClassSkips(t *testing.T) {
+	client := &classClient{same: map[string]bool{"logs/a.gz": true, "logs/c.gz": true}}
+	objects := []aws.S3Object{{Key: "logs/a.gz", Size: 1}, {Key: "logs/b.gz", Size: 2}, {Key: "logs/c.gz", Size: 3}}
+
+	m := NewManager(client, 2)
+	if err := m.ChangeStorageClass(context.Background(), "bucket", objects, "GLACIER"); err != nil {
+		t.Fatalf("ChangeStorageClass() error = %v", err)
+	}
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.CompletedFiles != 3 || p.Summary != "2 already in GLACIER" {
+		t.Errorf("%d completed with summary %q, want 3 with 2 already in GLACIER", p.CompletedFiles, p.Summary)
+	}
+}
+
+// tagClient tags objects, failing the keys in fail and reporting the keys
+// in same as already tagged
+type tagClient struct {
+	Client
+	fail, same map[string]bool
+}
+
+func (c *tagClient) TagObject(ctx context.Context, bucket, key string, tags map[string]string) error {
+	switch {
+	case c.fail[key]:
+		return errors.New("AccessDenied")
+	case c.same[key]:
+		return aws.ErrAlreadyTagged
+	}
+	return nil
+}
+
+func TestManagerTagObjects(t *testing.T) {
+	client := &tagClient{fail: map[string]bool{"logs/b.gz": true}, same: map[string]bool{"logs/c.gz": true}}
+	objects := []aws.S3Object{{Key: "logs/a.gz", Size: 1}, {Key: "logs/b.gz", Size: 2}, {Key: "logs/c.gz", Size: 3}}
+
+	m := NewManager(client, 2)
+	if err := m.TagObjects(context.Background(), "bucket", objects, map[string]string{"team": "data"}); err != nil {
+		t.Fatalf("TagObjects() error = %v", err)
+	}
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.CompletedFiles != 2 || p.FailedFiles != 1 || p.Status != StatusFailed {
+		t.Errorf("%d completed, %d failed, status %v; want 2, 1, failed", p.CompletedFiles, p.FailedFiles, p.Status)
+	}
+	if p.Files["logs/b.gz"].Error == nil {
+		t.Error("logs/b.gz failed without an error")
+	}
+	if p.Summary != "1 already tagged" || p.Direction != DirectionTag {
+		t.Errorf("summary %q, direction %v; want 1 already tagged, tag", p.Summary, p.Direction)
+	}
+	if pct := p.PercentComplete(); pct != 100 {
+		t.Errorf("PercentComplete() = %v, want 100", pct)
+	}
+}
+
+// moveClient keeps keys in memory, refusing to delete the keys in locked
+type moveClient struct {
+	Client
+	mu     sync.Mutex
+	keys   map[string]bool
+	locked map[string]bool
+}
+
+func (c *moveClient) CopyObjectBetween(ctx context.Context, dst *aws.Client, srcBucket, srcKey, dstBucket, dstKey string, opts aws.UploadOptions, onProgress func(aws.DownloadProgress)) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.keys[srcKey] {
+		return fmt.Errorf("no such key %s", srcKey)
+	}
+	c.keys[dstKey] = true
+	return nil
+}
+
+func (c *moveClient) DeleteObject(ctx context.Context, bucket, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.locked[key] {
+		return errors.New("AccessDenied")
+	}
+	delete(c.keys, key)
+	return nil
+}
+
+func TestManagerMoveObjects(t *testing.T) {
+	client := &moveClient{
+		keys:   map[string]bool{"logs/a.gz": true, "logs/b.gz": true, "logs/2024/c.gz": true},
+		locked: map[string]bool{"logs/b.gz": true},
+	}
+	var files []CopyFile
+	for _, key := range []string{"logs/a.gz", "logs/b.gz", "logs/2024/c.gz"} {
+		files = append(files, CopyFile{Source: aws.S3Object{Key: key, Size: 1}, Key: "archive/" + key})
+	}
+
+	m := NewManager(client, 2)
+	if err := m.MoveObjects(context.Background(), "bucket", files); err != nil {
+		t.Fatalf("MoveObjects() error = %v", err)
+	}
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.CompletedFiles != 2 || p.FailedFiles != 1 || p.Direction != DirectionMove {
+		t.Errorf("%d completed, %d failed, direction %v; want 2, 1, move", p.CompletedFiles, p.FailedFiles, p.Direction)
+	}
+	want := map[string]bool{"logs/b.gz": true, "archive/logs/a.gz": true, "archive/logs/b.gz": true, "archive/logs/2024/c.gz": true}
+	if !reflect.DeepEqual(client.keys, want) {
+		t.Errorf("keys after move = %v, want %v", client.keys, want)
+	}
+}
+
+func TestManagerListPrefixCopy(t *testing.T) {
+	client := newChaosClient(map[string][]byte{
+		"logs/a.gz":        []byte("a"),
+		"logs/2024/b.gz":   []byte("bb"),
+		"backup/logs/a.gz": []byte("a"),
+	})
+
+	m := NewManager(client, 2)
+	files, existing, err := m.ListPrefixCopy(context.Background(), "bucket", "logs/", "bucket", "backup/logs/")
+	if err != nil {
+		t.Fatalf("ListPrefixCopy() error = %v", err)
+	}
+	var keys []string
+	for _, f := range files {
+		keys = append(keys, f.Source.Key+" → "+f.Key)
+	}
+	want := []string{"logs/2024/b.gz → backup/logs/2024/b.gz", "logs/a.gz → backup/logs/a.gz"}
+	if !reflect.DeepEqual(keys, want) {
+		t.Errorf("copies = %q, want %q", keys, want)
+	}
+	if _, ok := existing["backup/logs/a.gz"]; !ok || len(existing) != 1 {
+		t.Errorf("existing = %v, want backup/logs/a.gz", existing)
+	}
+}
+
+func TestManagerRetriesStalls(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the stall watcher")
+	}
+	objects := testObjects(3)
+	client := newChaosClient(objects)
+	client.stalls["data/01.txt"] = 1
+
+	m := NewManager(client, 2)
+	m.SetStallPolicy(50*time.Millisecond, true)
+	recordProgress(t, m)
+	if err := m.DownloadPrefix(context.Background(), "bucket", "data/", t.TempDir()); err != nil {
+		t.Fatalf("DownloadPrefix() error = %v", err)
+	}
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusCompleted || p.StalledFiles != 0 {
+		t.Errorf("Status = %s with %d stalled, want completed with none", p.Status, p.StalledFiles)
+	}
+	if fp := p.Files["data/01.txt"]; fp.Retries != 1 || fp.Stalled {
+		t.Errorf("stalled file retried %d times (stalled %v), want once", fp.Retries, fp.Stalled)
+	}
+}
+
+func TestManagerSingleFileMissing(t *testing.T) {
+	client := newChaosClient(testObjects(1))
+	m := NewManager(client, 1)
+	if err := m.DownloadFile(context.Background(), "bucket", "data/missing.txt", filepath.Join(t.TempDir(), "x")); err == nil {
+		t.Error("DownloadFile() of a missing key succeeded")
+	}
+
+	client.failAt["data/00.txt"] = 4
+	err := m.DownloadFile(context.Background(), "bucket", "data/00.txt", filepath.Join(t.TempDir(), "00.txt"))
+	if !errors.Is(err, errInjected) {
+		t.Errorf("DownloadFile() error = %v, want the injected error", err)
+	}
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusFailed || p.Files["data/00.txt"].Status != StatusFailed {
+		t.Errorf("Status = %s, want failed", p.Status)
+	}
+}
+
+// TestManagerPathPolicy checks that every download path escapes, or under
+// the strict policy refuses, keys that can't be local file names
+func TestManagerPathPolicy(t *testing.T) {
+	objects := map[string][]byte{"data/bad\x01.txt": []byte("bad"), "data/ok.txt": []byte("ok")}
+	downloads := map[string]func(m *Manager, dir string) error{
+		"DownloadFile": func(m *Manager, dir string) error {
+			return m.DownloadFile(context.Background(), "bucket", "data/bad\x01.txt", filepath.Join(dir, "bad\x01.txt"))
+		},
+		"DownloadPrefix": func(m *Manager, dir string) error {
+			return m.DownloadPrefix(context.Background(), "bucket", "data/", dir)
+		},
+		"DownloadMultiple": func(m *Manager, dir string) error {
+			return m.DownloadMultiple(context.Background(), "bucket", []aws.S3Object{{Key: "data/", IsPrefix: true}}, "data/", dir)
+		},
+		"Sync": func(m *Manager, dir string) error {
+			return NewSyncManager(m.client).Sync(context.Background(), "bucket", "data/", dir, m)
+		},
+	}
+
+	for name, download := range downloads {
+		m := NewManager(newChaosClient(objects), 2)
+		m.SetPathPolicy(security.PathStrict)
+		dir := t.TempDir()
+		if err := download(m, dir); err == nil {
+			t.Errorf("%s under the strict policy succeeded", name)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Errorf("%s under the strict policy wrote %d files", name, len(entries))
+		}
+
+		m = NewManager(newChaosClient(objects), 2)
+		dir = t.TempDir()
+		if err := download(m, dir); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if got, err := os.ReadFile(filepath.Join(dir, "bad%01.txt")); err != nil || string(got) != "bad" {
+			t.Errorf("%s didn't write the escaped file (err %v)", name, err)
+		}
+		if p := m.GetProgress(); p.EscapedFiles != 1 {
+			t.Errorf("%s: EscapedFiles = %d, want 1", name, p.EscapedFiles)
+		}
+	}
+
+	// A second sync finds the escaped file instead of fetching it again
+	dir := t.TempDir()
+	m := NewManager(newChaosClient(objects), 2)
+	sm := NewSyncManager(m.client)
+	if err := sm.Sync(context.Background(), "bucket", "data/", dir, m); err != nil {
+		t.Fatal(err)
+	}
+	result, err := sm.CompareFiles(context.Background(), "bucket", "data/", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ToDownload) != 0 {
+		t.Errorf("second sync would download %d files again", len(result.ToDownload))
+	}
+}
+
+// TestDownloadFileEscapesOnce checks that a file named after its key is
+// escaped once, and a name the user chose isn't escaped at all
+func TestDownloadFileEscapesOnce(t *testing.T) {
+	objects := map[string][]byte{"data/50%.txt": []byte("half")}
+	tests := []struct {
+		name, file, want string
+		escaped          int
+	}{
+		{"named after the key", "50%.txt", "50%25.txt", 1},
+		{"named by the user", "half%.txt", "half%.txt", 0},
+	}
+	for _, tt := range tests {
+		m := NewManager(newChaosClient(objects), 2)
+		dir := t.TempDir()
+		if err := m.DownloadFile(context.Background(), "bucket", "data/50%.txt", filepath.Join(dir, tt.file)); err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if got, err := os.ReadFile(filepath.Join(dir, tt.want)); err != nil || string(got) != "half" {
+			t.Errorf("%s: didn't write %s (err %v)", tt.name, tt.want, err)
+		}
+		if p := m.GetProgress(); p.EscapedFiles != tt.escaped {
+			t.Errorf("%s: EscapedFiles = %d, want %d", tt.name, p.EscapedFiles, tt.escaped)
+		}
+	}
+}
+
+// splitObjects returns a large object that dominates the batch, so it's
+// split across workers, and a small one
+func splitObjects() map[string][]byte {
+	big := make([]byte, 1000)
+	for i := range big {
+		big[i] = byte('a' + i%26)
+	}
+	return map[string][]byte{"data/big.bin": big, "data/small.txt": []byte("small")}
+}
+
+// splitManager returns a Manager that splits files of 100 bytes or more
+// into chunks of at least 64
+func splitManager(client Client) *Manager {
+	m := NewManager(client, 4)
+	m.splitMin, m.splitChunk = 100, 64
+	return m
+}
+
+func TestManagerSplitDownload(t *testing.T) {
+	objects := splitObjects()
+	client := newChaosClient(objects)
+	client.chunk = 16
+
+	m := splitManager(client)
+	recordProgress(t, m)
+	dir := t.TempDir()
+	if err := m.DownloadPrefix(context.Background(), "bucket", "data/", dir); err != nil {
+		t.Fatalf("DownloadPrefix() error = %v", err)
+	}
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusCompleted || p.DownloadedBytes != p.TotalBytes {
+		t.Errorf("Status = %s with %d of %d bytes, want completed with all", p.Status, p.DownloadedBytes, p.TotalBytes)
+	}
+	if client.attempts["data/big.bin"] != 4 {
+		t.Errorf("big.bin fetched in %d ranges, want 4", client.attempts["data/big.bin"])
+	}
+	for key, data := range objects {
+		got, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(key, "data/")))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s wasn't downloaded intact (err %v)", key, err)
+		}
+	}
+}
+
+func TestManagerSplitDownloadCancel(t *testing.T) {
+	// The first file listed hangs, so one of the two workers is busy and
+	// the second chunk is still queued when the cancel comes
+	objects := splitObjects()
+	objects["data/a.txt"] = []byte("hangs")
+	client := newChaosClient(objects)
+	client.stalls["data/a.txt"] = 1
+	client.delay = time.Millisecond
+
+	m := NewManager(client, 2)
+	m.splitMin, m.splitChunk = 100, 64
+	recordProgress(t, m)
+	client.onChunk = func(key string, sent int64) {
+		if key == "data/big.bin" && sent >= 16 {
+			m.Cancel()
+		}
+	}
+
+	dir := t.TempDir()
+	err := m.DownloadPrefix(context.Background(), "bucket", "data/", dir)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("DownloadPrefix() error = %v, want context.Canceled", err)
+	}
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if fp := p.Files["data/big.bin"]; fp.Status != StatusCancelled {
+		t.Errorf("big.bin = %s, want cancelled", fp.Status)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "big.bin")); !os.IsNotExist(err) {
+		t.Errorf("the cancelled split download left a partial file behind (stat error %v)", err)
+	}
+}
+
+func TestSplitDownloadAbortKeepsUnopenedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "big.bin")
+	if err := os.WriteFile(path, []byte("the user's copy"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// Cancelled before any chunk started, so the file was never touched
+	split := &splitDownload{localPath: path, chunks: make([]int64, 2), remaining: 2}
+	if !split.abort() {
+		t.Fatal("abort() = false with no chunks finished")
+	}
+	if got, err := os.ReadFile(path); err != nil || string(got) != "the user's copy" {
+		t.Errorf("aborting an unstarted split download changed the existing file: %q, %v", got, err)
+	}
+
+	split = &splitDownload{localPath: path, chunks: make([]int64, 2), remaining: 2}
+	if _, err := split.open(10); err != nil {
+		t.Fatal(err)
+	}
+	split.abort()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("aborting an opened split download left its partial file (stat error %v)", err)
+	}
+}
+
+func TestManagerSplitDownloadFailure(t *testing.T) {
+	client := newChaosClient(splitObjects())
+	client.failAt["data/big.bin"] = 600 // in the third chunk
+
+	m := splitManager(client)
+	recordProgress(t, m)
+	dir := t.TempDir()
+	m.DownloadPrefix(context.Background(), "bucket", "data/", dir)
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if fp := p.Files["data/big.bin"]; fp.Status != StatusFailed || !errors.Is(fp.Error, errInjected) {
+		t.Errorf("big.bin = %s, %v; want failed with the injected error", fp.Status, fp.Error)
+	}
+	if _, err := os.Stat(filepath.Join(dir, "big.bin")); !os.IsNotExist(err) {
+		t.Errorf("the failed split download left a partial file behind (stat error %v)", err)
+	}
+}
+
+func TestManagerSplitDownloadRetriesStalls(t *testing.T) {
+	if testing.Short() {
+		t.Skip("waits for the stall watcher")
+	}
+	objects := splitObjects()
+	client := newChaosClient(objects)
+	client.stalls["data/big.bin"] = 1 // the first chunk to start hangs
+
+	m := splitManager(client)
+	m.SetStallPolicy(50*time.Millisecond, true)
+	recordProgress(t, m)
+	dir := t.TempDir()
+	if err := m.DownloadPrefix(context.Background(), "bucket", "data/", dir); err != nil {
+		t.Fatalf("DownloadPrefix() error = %v", err)
+	}
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusCompleted || p.StalledFiles != 0 || p.DownloadedBytes != p.TotalBytes {
+		t.Errorf("Status = %s with %d stalled and %d of %d bytes, want completed", p.Status, p.StalledFiles, p.DownloadedBytes, p.TotalBytes)
+	}
+	if fp := p.Files["data/big.bin"]; fp.Retries != 1 {
+		t.Errorf("stalled chunk retried %d times, want once", fp.Retries)
+	}
+	got, err := os.ReadFile(filepath.Join(dir, "big.bin"))
+	if err != nil || !bytes.Equal(got, objects["data/big.bin"]) {
+		t.Errorf("big.bin wasn't downloaded intact (err %v)", err)
+	}
+}
+
+func TestProgressETA(t *testing.T) {
+	tests := []struct {
+		name string
+		p    Progress
+		want time.Duration
+	}{
+		{"no throughput yet", Progress{TotalBytes: 100}, 0},
+		{"finished", Progress{TotalBytes: 100, DownloadedBytes: 100, Throughput: 10}, 0},
+		{"halfway", Progress{TotalBytes: 100, DownloadedBytes: 50, Throughput: 10}, 5 * time.Second},
+		{"nothing yet", Progress{TotalBytes: 1000, Throughput: 250}, 4 * time.Second},
+	}
+	for _, tt := range tests {
+		if got := tt.p.ETA(); got != tt.want {
+			t.Errorf("%s: ETA() = %v, want %v", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestSampleThroughput(t *testing.T) {
+	start := time.Now()
+	var p Progress
+	tests := []struct {
+		after      time.Duration
+		downloaded int64
+		want       float64
+	}{
+		{0, 0, 0},                 // the first sample only sets the baseline
+		{time.Second, 1000, 1000}, // the first rate is taken as it is
+		{time.Second + 100*time.Millisecond, 1500, 1000}, // too soon after the last sample
+		{2 * time.Second, 2000, 1000},                    // 1000 bytes/sec again
+		{3 * time.Second, 7000, 1800},                    // 5000 bytes/sec, weighted by throughputAlpha
+		{4 * time.Second, 7000, 1440},                    // nothing arrived, so it decays
+	}
+	for i, tt := range tests {
+		p.DownloadedBytes = tt.downloaded
+		p.sampleThroughput(start.Add(tt.after))
+		if diff := p.Throughput - tt.want; diff > 0.001 || diff < -0.001 {
+			t.Errorf("sample %d: Throughput = %v, want %v", i, p.Throughput, tt.want)
+		}
+	}
+}
+
+func TestCheckStalls(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name        string
+		fp          FileProgress
+		retry       bool
+		wantStalled bool
+		wantCancel  bool
+	}{
+		{"receiving", FileProgress{Status: StatusInProgress, LastProgressAt: now.Add(-time.Second)}, true, false, false},
+		{"idle too long", FileProgress{Status: StatusInProgress, LastProgressAt: now.Add(-time.Minute)}, false, true, false},
+		{"idle too long, retrying", FileProgress{Status: StatusInProgress, LastProgressAt: now.Add(-time.Minute)}, true, true, true},
+		{"out of retries", FileProgress{Status: StatusInProgress, LastProgressAt: now.Add(-time.Minute), Retries: maxStallRetries}, true, true, false},
+		{"finished", FileProgress{Status: StatusCompleted, LastProgressAt: now.Add(-time.Minute)}, true, false, false},
+	}
+	for _, tt := range tests {
+		m := NewManager(newChaosClient(nil), 1)
+		m.SetStallPolicy(30*time.Second, tt.retry)
+		fp := tt.fp
+		m.progress.Files["a"] = &fp
+		cancelled := false
+		attempt := &fileAttempt{cancel: func() { cancelled = true }}
+		m.fileCancels["a"] = map[int]*fileAttempt{0: attempt}
+
+		changed := m.checkStalls(now)
+		if fp.Stalled != tt.wantStalled || changed != tt.wantStalled {
+			t.Errorf("%s: stalled = %v, changed = %v, want %v", tt.name, fp.Stalled, changed, tt.wantStalled)
+		}
+		if tt.wantStalled && m.progress.StalledFiles != 1 {
+			t.Errorf("%s: %d stalled files, want 1", tt.name, m.progress.StalledFiles)
+		}
+		if cancelled != tt.wantCancel || attempt.stalled != tt.wantCancel {
+			t.Errorf("%s: cancelled = %v, want %v", tt.name, cancelled, tt.wantCancel)
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package download
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/natevick/stui/internal/aws"
+)
+
+// ErrKeyNotFound is a manifest key that isn't in the bucket
+var ErrKeyNotFound = errors.New("not found")
+
+// ManifestEntry is one object listed in a manifest
+type ManifestEntry struct {
+	Bucket string
+	Key    string
+	Line   int // line of the manifest it came from
+}
+
+// ParseManifest reads a list of objects to fetch, one per line: an
+// s3:// URI, or a key in bucket. Blank lines and lines starting with # are
+// skipped, as are repeats. bucket may be "" if every line is a URI.
+func ParseManifest(r io.Reader, bucket string) ([]ManifestEntry, error) {
+	var entries []ManifestEntry
+	seen := make(map[ManifestEntry]bool)
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		text := strings.TrimSpace(scanner.Text())
+		if text == "" || strings.HasPrefix(text, "#") {
+			continue
+		}
+
+		entry := ManifestEntry{Bucket: bucket, Key: text, Line: line}
+		if strings.HasPrefix(text, "s3://") || strings.HasPrefix(text, "arn:") {
+			b, key, err := aws.ParseS3URI(text)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %w", line, err)
+			}
+			entry.Bucket, entry.Key = b, key
+		} else if bucket == "" {
+			return nil, fmt.Errorf("line %d: %q has no bucket; use an s3:// URI", line, text)
+		}
+		if entry.Key == "" || strings.HasSuffix(entry.Key, "/") {
+			return nil, fmt.Errorf("line %d: %q is not an object key", line, text)
+		}
+
+		dedupe := ManifestEntry{Bucket: entry.Bucket, Key: entry.Key}
+		if seen[dedupe] {
+			continue
+		}
+		seen[dedupe] = true
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("manifest lists no keys")
+	}
+	return entries, nil
+}
+
+// DownloadKeys downloads an explicit list of keys, e.g. from a manifest,
+// to their full key paths under localDir. Keys that can't be found are
+// reported as failed files rather than stopping the others.
+func (m *Manager) DownloadKeys(ctx context.Context, bucket string, keys []string, localDir string) error {
+	ctx, done, err := m.begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer done()
+
+	if len(keys) == 0 {
+		return fmt.Errorf("no files to download")
+	}
+
+	// Look up every key first, for sizes and to find missing ones
+	found, missing := m.headKeys(ctx, bucket, keys)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+
+	plan, err := planLocalPaths(found, "", localDir, m.collisions, m.pathPolicy)
+	if err != nil {
+		return err
+	}
+
+	var totalBytes int64
+	files := make(map[string]*FileProgress, len(keys))
+	for _, obj := range plan.objects {
+		totalBytes += obj.Size
+		files[obj.Key] = &FileProgress{
+			Key:          obj.Key,
+			LocalPath:    plan.paths[obj.Key],
+			Size:         obj.Size,
+			Status:       StatusPending,
+			CollidedWith: plan.collisions[obj.Key],
+			Escaped:      plan.escaped[obj.Key],
+		}
+	}
+	for key, err := range missing {
+		files[key] = &FileProgress{Key: key, Status: StatusFailed, Error: err, CompletedAt: time.Now()}
+	}
+
+	m.progressMu.Lock()
+	m.progress = Progress{
+		Bucket:       bucket,
+		TotalFiles:   len(files),
+		FailedFiles:  len(missing),
+		TotalBytes:   totalBytes,
+		Files:        files,
+		StartedAt:    time.Now(),
+		Status:       StatusInProgress,
+		Collisions:   len(plan.collisions),
+		EscapedFiles: len(plan.escaped),
+	}
+	if len(missing) > 0 {
+		m.progress.Summary = fmt.Sprintf("%d of %d keys not found", len(missing), len(files))
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+
+	if len(plan.objects) > 0 {
+		err = m.downloadWithWorkers(ctx, bucket, plan.objects, "", localDir)
+	}
+
+	m.progressMu.Lock()
+	if err != nil && ctx.Err() != nil {
+		m.progress.Status = StatusCancelled
+	} else if m.progress.FailedFiles > 0 {
+		m.progress.Status = StatusFailed
+	} else {
+		m.progress.Status = StatusCompleted
+	}
+	m.progressMu.Unlock()
+
+	m.notifyProgress()
+	m.notifyComplete()
+
+	return err
+}
+
+// headKeys looks up keys with as many requests at once as the manager has
+// workers, returning the objects found, in order, and why the rest weren't
+func (m *Manager) headKeys(ctx context.Context, bucket string, keys []string) ([]aws.S3Object, map[string]error) {
+	objects := make([]*aws.S3Object, len(keys))
+	errs := make([]error, len(keys))
+	sem := NewSemaphore(m.workers)
+	var wg sync.WaitGroup
+	for i, key := range keys {
+		sem.Acquire()
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer sem.Release()
+			objects[i], errs[i] = m.client.GetObjectMetadata(ctx, bucket, key)
+		}()
+	}
+	wg.Wait()
+
+	var found []aws.S3Object
+	missing := make(map[string]error)
+	for i, obj := range objects {
+		var notFound *types.NotFound
+		if errors.As(errs[i], &notFound) {
+			errs[i] = ErrKeyNotFound
+		}
+		if errs[i] != nil {
+			missing[keys[i]] = errs[i]
+			continue
+		}
+		found = append(found, *obj)
+	}
+	return found, missing
+}
@@ -0,0 +1,72 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseManifest(t *testing.T) {
+	manifest := `# exported by the nightly job
+data/01.txt
+
+  data/02.txt
+s3://other/reports/q1.csv
+data/01.txt
+`
+	entries, err := ParseManifest(strings.NewReader(manifest), "logs")
+	if err != nil {
+		t.Fatalf("ParseManifest() error = %v", err)
+	}
+	want := []ManifestEntry{
+		{Bucket: "logs", Key: "data/01.txt", Line: 2},
+		{Bucket: "logs", Key: "data/02.txt", Line: 4},
+		{Bucket: "other", Key: "reports/q1.csv", Line: 5},
+	}
+	if !reflect.DeepEqual(entries, want) {
+		t.Errorf("ParseManifest() = %v, want %v", entries, want)
+	}
+
+	for name, manifest := range map[string]string{
+		"no bucket":     "data/01.txt\n",
+		"folder":        "s3://logs/data/\n",
+		"bucket only":   "s3://logs\n",
+		"only comments": "# nothing yet\n\n",
+	} {
+		if _, err := ParseManifest(strings.NewReader(manifest), ""); err == nil {
+			t.Errorf("ParseManifest(%s) succeeded", name)
+		}
+	}
+}
+
+func TestManagerDownloadKeys(t *testing.T) {
+	objects := testObjects(6)
+	client := newChaosClient(objects)
+
+	m := NewManager(client, 3)
+	recordProgress(t, m)
+	dir := t.TempDir()
+	keys := []string{"data/01.txt", "data/missing.txt", "data/04.txt"}
+	if err := m.DownloadKeys(context.Background(), "bucket", keys, dir); err != nil {
+		t.Fatalf("DownloadKeys() error = %v", err)
+	}
+
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusFailed || p.CompletedFiles != 2 || p.FailedFiles != 1 {
+		t.Errorf("Status = %s with %d done and %d failed, want failed with 2 and 1", p.Status, p.CompletedFiles, p.FailedFiles)
+	}
+	if fp := p.Files["data/missing.txt"]; fp == nil || fp.Error == nil {
+		t.Error("missing key wasn't reported")
+	}
+	for _, key := range []string{"data/01.txt", "data/04.txt"} {
+		got, err := os.ReadFile(filepath.Join(dir, key))
+		if err != nil || !bytes.Equal(got, objects[key]) {
+			t.Errorf("%s wasn't downloaded to its key path (err %v)", key, err)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package download
+
+import (
+	"context"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// Mirror is another source of the objects in a bucket, e.g. a replica in
+// another region or the same bucket through another profile or endpoint
+type Mirror struct {
+	Name   string // shown in worker diagnostics
+	Client Client
+	Bucket string // the mirror's bucket name
+}
+
+// Mirrors are the configured mirrors by the bucket they mirror
+type Mirrors map[string][]Mirror
+
+// SetMirrors stripes downloads from the given buckets across their
+// mirrors: each worker fetches through the bucket's own client or one of
+// its mirrors in turn
+func (m *Manager) SetMirrors(mirrors Mirrors) {
+	m.mirrors = mirrors
+}
+
+// source returns the client and bucket name a worker downloads from
+// bucket through, and the mirror if it's not the bucket itself
+func (m *Manager) source(bucket string, worker int) (Client, string, *Mirror) {
+	mirrors := m.mirrors[bucket]
+	if len(mirrors) == 0 {
+		return m.client, bucket, nil
+	}
+	i := worker % (len(mirrors) + 1)
+	if i == 0 {
+		return m.client, bucket, nil
+	}
+	return mirrors[i-1].Client, mirrors[i-1].Bucket, &mirrors[i-1]
+}
+
+// fromSource runs fetch through the worker's source for obj, in bucket. A
+// mirror is only used once it's known to hold the same version of obj as
+// the bucket, so a file split across workers is never stitched together
+// from two versions. If it holds another version, or fails, e.g. with an
+// object not replicated yet, obj is fetched from the bucket itself instead.
+func (m *Manager) fromSource(ctx context.Context, bucket string, obj aws.S3Object, worker int, fetch func(client Client, bucket string) error) error {
+	client, srcBucket, mirror := m.source(bucket, worker)
+	if mirror == nil {
+		return fetch(client, srcBucket)
+	}
+	if m.mirrorHolds(ctx, mirror, obj) {
+		err := fetch(client, srcBucket)
+		if err == nil || ctx.Err() != nil {
+			return err
+		}
+	}
+	m.progressMu.Lock()
+	m.progress.MirrorFallbacks++
+	m.progressMu.Unlock()
+	return fetch(m.client, bucket)
+}
+
+// mirrorHolds reports whether mirror has the version of obj listed in the
+// bucket, by its ETag and size. The answer is kept for the other chunks of
+// a split file. Without an ETag to compare, the mirror isn't trusted.
+func (m *Manager) mirrorHolds(ctx context.Context, mirror *Mirror, obj aws.S3Object) bool {
+	if obj.ETag == "" {
+		return false
+	}
+	check := mirrorCheck{mirror: mirror.Name, key: obj.Key, etag: obj.ETag}
+	m.mirrorMu.Lock()
+	ok, checked := m.mirrorChecks[check]
+	m.mirrorMu.Unlock()
+	if checked {
+		return ok
+	}
+	head, err := mirror.Client.GetObjectMetadata(ctx, mirror.Bucket, obj.Key)
+	if err != nil && ctx.Err() != nil {
+		return false // not known either way
+	}
+	ok = err == nil && head.ETag == obj.ETag && head.Size == obj.Size
+	m.mirrorMu.Lock()
+	if m.mirrorChecks == nil {
+		m.mirrorChecks = make(map[mirrorCheck]bool)
+	}
+	m.mirrorChecks[check] = ok
+	m.mirrorMu.Unlock()
+	return ok
+}
+
+// mirrorCheck identifies a version of an object looked for in a mirror
+type mirrorCheck struct {
+	mirror string
+	key    string
+	etag   string
+}
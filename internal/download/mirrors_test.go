@@ -0,0 +1,88 @@
+package download
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestManagerMirrors(t *testing.T) {
+	objects := testObjects(8)
+	primary := newChaosClient(objects)
+
+	// The mirror is missing one object, as if it hadn't replicated yet
+	replica := make(map[string][]byte)
+	for key, data := range objects {
+		replica[key] = data
+	}
+	delete(replica, "data/00.txt")
+	mirror := newChaosClient(replica)
+	primary.delay, mirror.delay = time.Millisecond, time.Millisecond
+
+	m := NewManager(primary, 2)
+	m.SetMirrors(Mirrors{"bucket": {{Name: "replica", Client: mirror, Bucket: "bucket-replica"}}})
+	dir := t.TempDir()
+	if err := m.DownloadPrefix(context.Background(), "bucket", "data/", dir); err != nil {
+		t.Fatalf("DownloadPrefix() error = %v", err)
+	}
+
+	p := m.GetProgress()
+	if p.Status != StatusCompleted || p.CompletedFiles != len(objects) {
+		t.Errorf("Status = %s with %d files done, want completed with %d", p.Status, p.CompletedFiles, len(objects))
+	}
+	if p.Workers[0].Source != "" || p.Workers[1].Source != "replica" {
+		t.Errorf("worker sources = %q, %q, want the bucket and the replica", p.Workers[0].Source, p.Workers[1].Source)
+	}
+	if len(primary.attempts) == 0 || len(mirror.attempts) == 0 {
+		t.Errorf("downloads from the bucket = %d and the mirror = %d, want both used", len(primary.attempts), len(mirror.attempts))
+	}
+	if mirror.attempts["data/00.txt"] != 0 {
+		t.Errorf("the object missing from the mirror was fetched from it %d times, want it looked up only", mirror.attempts["data/00.txt"])
+	}
+	if want := mirror.heads["data/00.txt"]; p.MirrorFallbacks != want || primary.attempts["data/00.txt"] != 1 {
+		t.Errorf("MirrorFallbacks = %d, want %d with the missing object fetched from the bucket", p.MirrorFallbacks, want)
+	}
+	for key, data := range objects {
+		got, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(key, "data/")))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s wasn't downloaded intact (err %v)", key, err)
+		}
+	}
+}
+
+func TestManagerMirrorsStaleVersion(t *testing.T) {
+	objects := testObjects(6)
+	primary := newChaosClient(objects)
+
+	// The mirror still has an older version of every object, the same size
+	replica := make(map[string][]byte)
+	for key, data := range objects {
+		replica[key] = bytes.ToUpper(data)
+	}
+	mirror := newChaosClient(replica)
+	primary.delay, mirror.delay = time.Millisecond, time.Millisecond
+
+	m := NewManager(primary, 2)
+	m.SetMirrors(Mirrors{"bucket": {{Name: "replica", Client: mirror, Bucket: "bucket-replica"}}})
+	dir := t.TempDir()
+	if err := m.DownloadPrefix(context.Background(), "bucket", "data/", dir); err != nil {
+		t.Fatalf("DownloadPrefix() error = %v", err)
+	}
+
+	if n := len(mirror.attempts); n != 0 {
+		t.Errorf("%d objects were downloaded from a mirror with other versions, want none", n)
+	}
+	if p := m.GetProgress(); len(mirror.heads) == 0 || p.MirrorFallbacks != len(mirror.heads) {
+		t.Errorf("MirrorFallbacks = %d, want one for each of the %d objects looked up in the mirror", p.MirrorFallbacks, len(mirror.heads))
+	}
+	for key, data := range objects {
+		got, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(key, "data/")))
+		if err != nil || !bytes.Equal(got, data) {
+			t.Errorf("%s = %q (err %v), want the bucket's version %q", key, got, err, data)
+		}
+	}
+}
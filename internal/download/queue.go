@@ -0,0 +1,289 @@
+package download
+
+import (
+	"context"
+	"slices"
+	"sync"
+)
+
+// DefaultMaxJobs is how many queued jobs run at once unless configured
+const DefaultMaxJobs = 2
+
+// maxFinishedJobs is how many finished jobs a queue keeps for Jobs to
+// report; older ones are forgotten
+const maxFinishedJobs = 50
+
+// JobFunc runs one transfer with a Manager of its own. It should return
+// when ctx is cancelled.
+type JobFunc func(ctx context.Context, mgr *Manager) error
+
+// QueuedJob is a snapshot of a transfer submitted to a Queue
+type QueuedJob struct {
+	ID        int
+	Name      string // what was transferred, e.g. the source and destination
+	Label     string // the user's name for the job, if they gave it one
+	Note      string
+	Direction Direction
+	Sync      bool   // brings a local directory and a prefix in line, in Direction
+	Status    Status // pending while queued, then the transfer's own status
+	Progress  Progress
+	Err       error
+}
+
+// Title returns the job's label, or its generated name if it has none
+func (j QueuedJob) Title() string {
+	if j.Label != "" {
+		return j.Label
+	}
+	return j.Name
+}
+
+// JobEvent reports progress of a job, or that it finished
+type JobEvent struct {
+	Job  QueuedJob
+	Done bool
+}
+
+// queueEntry is the queue's record of a submitted transfer
+type queueEntry struct {
+	QueuedJob
+	run    JobFunc
+	ctx    context.Context
+	cancel context.CancelFunc
+}
+
+// Queue runs transfer jobs concurrently, up to a limit, each with its own
+// Manager, progress and cancel handle. Jobs over the limit wait their turn
+// in submission order.
+type Queue struct {
+	mu         sync.Mutex
+	jobs       []*queueEntry
+	nextID     int
+	running    int
+	maxActive  int
+	newManager func() *Manager
+	events     chan JobEvent
+}
+
+// NewQueue creates a queue that runs up to maxActive jobs at once, giving
+// each a Manager from newManager
+func NewQueue(maxActive int, newManager func() *Manager) *Queue {
+	if maxActive <= 0 {
+		maxActive = DefaultMaxJobs
+	}
+	return &Queue{
+		maxActive:  maxActive,
+		newManager: newManager,
+		events:     make(chan JobEvent, 64),
+	}
+}
+
+// Events returns the channel job events are delivered on. Progress events
+// are dropped if the reader falls behind; completion events never are.
+func (q *Queue) Events() <-chan JobEvent {
+	return q.events
+}
+
+// Submit queues a job and returns its ID. The job starts as soon as fewer
+// than the maximum number of jobs are running.
+func (q *Queue) Submit(ctx context.Context, name string, dir Direction, run JobFunc) int {
+	return q.SubmitJob(ctx, QueuedJob{Name: name, Direction: dir}, run)
+}
+
+// SubmitSync queues a sync job, which transfers in dir, and returns its ID
+func (q *Queue) SubmitSync(ctx context.Context, name string, dir Direction, run JobFunc) int {
+	return q.SubmitJob(ctx, QueuedJob{Name: name, Direction: dir, Sync: true}, run)
+}
+
+// SubmitJob queues a job with job's name, label, note, direction and sync
+// flag, and returns its ID
+func (q *Queue) SubmitJob(ctx context.Context, job QueuedJob, run JobFunc) int {
+	q.mu.Lock()
+	q.nextID++
+	j := &queueEntry{
+		QueuedJob: QueuedJob{
+			ID:        q.nextID,
+			Name:      job.Name,
+			Label:     job.Label,
+			Note:      job.Note,
+			Direction: job.Direction,
+			Sync:      job.Sync,
+			Status:    StatusPending,
+			Progress:  Progress{Direction: job.Direction},
+		},
+		run: run,
+	}
+	j.ctx, j.cancel = context.WithCancel(ctx)
+	q.jobs = append(q.jobs, j)
+	q.mu.Unlock()
+
+	q.dispatch()
+	return j.ID
+}
+
+// Cancel stops a running job, or drops a queued one before it starts
+func (q *Queue) Cancel(id int) {
+	q.mu.Lock()
+	j := q.find(id)
+	if j == nil {
+		q.mu.Unlock()
+		return
+	}
+	j.cancel()
+	if j.Status != StatusPending {
+		q.mu.Unlock()
+		return
+	}
+	// Never started; nothing else will report it. Send from a goroutine
+	// so a caller that also reads Events can't block itself.
+	j.Status = StatusCancelled
+	j.Progress.Status = StatusCancelled
+	snapshot := j.QueuedJob
+	q.prune()
+	q.mu.Unlock()
+
+	go func() { q.events <- JobEvent{Job: snapshot, Done: true} }()
+}
+
+// Annotate sets a job's label and note, returning false if the ID is unknown
+func (q *Queue) Annotate(id int, label, note string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	j := q.find(id)
+	if j == nil {
+		return false
+	}
+	j.Label = label
+	j.Note = note
+	return true
+}
+
+// Job returns a snapshot of a job, or false if the ID is unknown
+func (q *Queue) Job(id int) (QueuedJob, bool) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if j := q.find(id); j != nil {
+		return j.QueuedJob, true
+	}
+	return QueuedJob{}, false
+}
+
+// Jobs returns a snapshot of every job in submission order
+func (q *Queue) Jobs() []QueuedJob {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	jobs := make([]QueuedJob, len(q.jobs))
+	for i, j := range q.jobs {
+		jobs[i] = j.QueuedJob
+	}
+	return jobs
+}
+
+// Counts returns how many jobs are running and how many are waiting to start
+func (q *Queue) Counts() (running, queued int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if j.Status == StatusPending {
+			queued++
+		}
+	}
+	return q.running, queued
+}
+
+// prune forgets the oldest finished jobs over maxFinishedJobs. Caller must
+// hold mu.
+func (q *Queue) prune() {
+	finished := 0
+	for _, j := range q.jobs {
+		if j.Status != StatusPending && j.Status != StatusInProgress {
+			finished++
+		}
+	}
+	if finished <= maxFinishedJobs {
+		return
+	}
+	excess := finished - maxFinishedJobs
+	q.jobs = slices.DeleteFunc(q.jobs, func(j *queueEntry) bool {
+		if excess > 0 && j.Status != StatusPending && j.Status != StatusInProgress {
+			excess--
+			return true
+		}
+		return false
+	})
+}
+
+// find returns the job with id. Caller must hold mu.
+func (q *Queue) find(id int) *queueEntry {
+	for _, j := range q.jobs {
+		if j.ID == id {
+			return j
+		}
+	}
+	return nil
+}
+
+// dispatch starts queued jobs while there is room
+func (q *Queue) dispatch() {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	for _, j := range q.jobs {
+		if q.running >= q.maxActive {
+			return
+		}
+		if j.Status != StatusPending {
+			continue
+		}
+		j.Status = StatusInProgress
+		j.Progress.Status = StatusInProgress
+		q.running++
+		go q.run(j)
+	}
+}
+
+// run executes a job and reports its progress and outcome
+func (q *Queue) run(j *queueEntry) {
+	mgr := q.newManager()
+	mgr.SetProgressCallback(func(p Progress) {
+		q.mu.Lock()
+		j.Progress = p
+		snapshot := j.QueuedJob
+		q.mu.Unlock()
+
+		select {
+		case q.events <- JobEvent{Job: snapshot}:
+		default:
+		}
+	})
+
+	err := j.run(j.ctx, mgr)
+
+	final := mgr.GetProgress()
+	// A job that failed before it started transferring has no progress yet
+	final.Direction = j.Direction
+	switch {
+	case err == nil && final.FailedFiles > 0:
+		// Managers report files that failed in the progress, not the error
+		final.Status = StatusFailed
+	case err == nil:
+		final.Status = StatusCompleted
+	case j.ctx.Err() != nil:
+		final.Status = StatusCancelled
+	default:
+		final.Status = StatusFailed
+	}
+
+	q.mu.Lock()
+	j.Progress = final
+	j.Status = final.Status
+	j.Err = err
+	j.cancel()
+	q.running--
+	snapshot := j.QueuedJob
+	q.prune()
+	q.mu.Unlock()
+
+	// The next job starts whether or not anyone is reading events yet
+	q.dispatch()
+	q.events <- JobEvent{Job: snapshot, Done: true}
+}
@@ -0,0 +1,192 @@
+package download
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// waitDone reads queue events until a job finishes
+func waitDone(t *testing.T, q *Queue) QueuedJob {
+	t.Helper()
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case ev := <-q.Events():
+			if ev.Done {
+				return ev.Job
+			}
+		case <-timeout:
+			t.Fatal("timed out waiting for a job to finish")
+		}
+	}
+}
+
+func TestQueueLimitsConcurrency(t *testing.T) {
+	q := NewQueue(1, func() *Manager { return NewManager(nil, 1) })
+
+	release := make(chan struct{})
+	first := q.Submit(context.Background(), "first", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+		<-release
+		return nil
+	})
+	second := q.Submit(context.Background(), "second", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+		return errors.New("boom")
+	})
+
+	if running, queued := q.Counts(); running != 1 || queued != 1 {
+		t.Fatalf("Counts() = %d running, %d queued, want 1, 1", running, queued)
+	}
+	if job, _ := q.Job(second); job.Status != StatusPending {
+		t.Errorf("second job status = %v, want pending", job.Status)
+	}
+
+	close(release)
+	done := waitDone(t, q)
+	if done.ID != first || done.Status != StatusCompleted {
+		t.Errorf("first finished job = %d (%v), want %d (completed)", done.ID, done.Status, first)
+	}
+	done = waitDone(t, q)
+	if done.ID != second || done.Status != StatusFailed || done.Err == nil {
+		t.Errorf("second finished job = %d (%v, %v), want %d (failed)", done.ID, done.Status, done.Err, second)
+	}
+}
+
+func TestQueueCancel(t *testing.T) {
+	q := NewQueue(1, func() *Manager { return NewManager(nil, 1) })
+
+	running := q.Submit(context.Background(), "running", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	queued := q.Submit(context.Background(), "queued", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+		t.Error("cancelled job should never run")
+		return nil
+	})
+
+	q.Cancel(queued)
+	done := waitDone(t, q)
+	if done.ID != queued || done.Status != StatusCancelled {
+		t.Errorf("finished job = %d (%v), want %d (cancelled)", done.ID, done.Status, queued)
+	}
+
+	q.Cancel(running)
+	done = waitDone(t, q)
+	if done.ID != running || done.Status != StatusCancelled {
+		t.Errorf("finished job = %d (%v), want %d (cancelled)", done.ID, done.Status, running)
+	}
+	if running, queued := q.Counts(); running != 0 || queued != 0 {
+		t.Errorf("Counts() = %d running, %d queued, want 0, 0", running, queued)
+	}
+}
+
+func TestQueueAnnotate(t *testing.T) {
+	q := NewQueue(1, func() *Manager { return NewManager(nil, 1) })
+
+	release := make(chan struct{})
+	id := q.Submit(context.Background(), "s3://models/weights/ → ./weights", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+		<-release
+		return nil
+	})
+	if job, _ := q.Job(id); job.Title() != job.Name {
+		t.Errorf("Title() = %q before labelling, want the name", job.Title())
+	}
+
+	if !q.Annotate(id, "model weights v3", "for the eval run") {
+		t.Fatal("Annotate() didn't find the job")
+	}
+	if q.Annotate(id+1, "nope", "") {
+		t.Error("Annotate() succeeded for an unknown job")
+	}
+
+	// The label outlives the job finishing
+	close(release)
+	done := waitDone(t, q)
+	if done.Title() != "model weights v3" || done.Note != "for the eval run" {
+		t.Errorf("finished job = %q (%q), want the label and note", done.Title(), done.Note)
+	}
+}
+
+func TestQueueSubmitJobNamed(t *testing.T) {
+	q := NewQueue(1, func() *Manager { return NewManager(nil, 1) })
+	id := q.SubmitJob(context.Background(), QueuedJob{
+		Name:      "Sync up weights",
+		Label:     "model weights v3",
+		Note:      "for the eval run",
+		Direction: DirectionUpload,
+		Sync:      true,
+	}, func(ctx context.Context, mgr *Manager) error {
+		return nil
+	})
+
+	// Named at submission, so even a job that finishes at once reports it
+	done := waitDone(t, q)
+	if done.ID != id || done.Title() != "model weights v3" || done.Note != "for the eval run" {
+		t.Errorf("finished job %d = %q (%q), want job %d with its label and note", done.ID, done.Title(), done.Note, id)
+	}
+	if !done.Sync || done.Direction != DirectionUpload {
+		t.Errorf("finished job sync = %v, direction = %v", done.Sync, done.Direction)
+	}
+}
+
+func TestQueueStartsWithoutReader(t *testing.T) {
+	q := NewQueue(1, func() *Manager { return NewManager(nil, 1) })
+	// Fill the event buffer so a finishing job can't report until read
+	for range cap(q.events) {
+		q.events <- JobEvent{}
+	}
+
+	started := make(chan struct{})
+	q.Submit(context.Background(), "first", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+		return nil
+	})
+	q.Submit(context.Background(), "second", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+		close(started)
+		return nil
+	})
+
+	select {
+	case <-started:
+	case <-time.After(5 * time.Second):
+		t.Fatal("the queued job didn't start while its predecessor's completion went unread")
+	}
+}
+
+func TestQueuePrunesFinishedJobs(t *testing.T) {
+	q := NewQueue(1, func() *Manager { return NewManager(nil, 1) })
+	for range maxFinishedJobs + 5 {
+		q.Submit(context.Background(), "job", DirectionDownload, func(ctx context.Context, mgr *Manager) error {
+			return nil
+		})
+		waitDone(t, q)
+	}
+
+	jobs := q.Jobs()
+	if len(jobs) != maxFinishedJobs {
+		t.Fatalf("Jobs() has %d jobs, want the last %d", len(jobs), maxFinishedJobs)
+	}
+	if jobs[0].ID != 6 {
+		t.Errorf("oldest job kept = %d, want 6", jobs[0].ID)
+	}
+}
+
+func TestQueuePartlyFailedJob(t *testing.T) {
+	client := &tagClient{fail: map[string]bool{"logs/b.gz": true}}
+	q := NewQueue(1, func() *Manager { return NewManager(client, 1) })
+	objects := []aws.S3Object{{Key: "logs/a.gz", Size: 1}, {Key: "logs/b.gz", Size: 2}}
+	q.Submit(context.Background(), "tag", DirectionTag, func(ctx context.Context, mgr *Manager) error {
+		return mgr.TagObjects(ctx, "bucket", objects, map[string]string{"team": "data"})
+	})
+
+	// The manager returns nil with a file failed, and the job must say so
+	done := waitDone(t, q)
+	if done.Status != StatusFailed || done.Progress.Status != StatusFailed {
+		t.Errorf("job status = %v (progress %v), want failed", done.Status, done.Progress.Status)
+	}
+	if done.Progress.CompletedFiles != 1 || done.Progress.FailedFiles != 1 {
+		t.Errorf("%d completed, %d failed; want 1, 1", done.Progress.CompletedFiles, done.Progress.FailedFiles)
+	}
+}
@@ -0,0 +1,157 @@
+package download
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// SymlinkPolicy decides how sync scans treat symbolic links
+type SymlinkPolicy int
+
+const (
+	SymlinkSkip   SymlinkPolicy = iota // leave links alone (default)
+	SymlinkFollow                      // treat links as the file or directory they point to
+	SymlinkError                       // refuse to sync a tree containing links
+)
+
+// ParseSymlinkPolicy parses a symlink policy name from config
+func ParseSymlinkPolicy(s string) (SymlinkPolicy, error) {
+	switch s {
+	case "", "skip":
+		return SymlinkSkip, nil
+	case "follow":
+		return SymlinkFollow, nil
+	case "error":
+		return SymlinkError, nil
+	default:
+		return SymlinkSkip, fmt.Errorf("unknown symlink policy %q (use skip, follow or error)", s)
+	}
+}
+
+func (p SymlinkPolicy) String() string {
+	switch p {
+	case SymlinkFollow:
+		return "follow"
+	case SymlinkError:
+		return "error"
+	default:
+		return "skip"
+	}
+}
+
+// SymlinkSummary records what a scan did with symbolic links
+type SymlinkSummary struct {
+	Policy   SymlinkPolicy
+	Skipped  int // links left alone
+	Followed int // links resolved and scanned
+	Broken   int // links whose target doesn't exist
+	Cycles   int // directory links pointing back at an ancestor
+}
+
+// String returns a one-line summary, or "" if the scan found no links
+func (s SymlinkSummary) String() string {
+	var parts []string
+	if s.Skipped > 0 {
+		parts = append(parts, fmt.Sprintf("%d skipped", s.Skipped))
+	}
+	if s.Followed > 0 {
+		parts = append(parts, fmt.Sprintf("%d followed", s.Followed))
+	}
+	if s.Broken > 0 {
+		parts = append(parts, fmt.Sprintf("%d broken", s.Broken))
+	}
+	if s.Cycles > 0 {
+		parts = append(parts, fmt.Sprintf("%d cycles", s.Cycles))
+	}
+	if len(parts) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("Symlinks (%s): %s", s.Policy, strings.Join(parts, ", "))
+}
+
+// localScan is the result of scanning a local directory for sync
+type localScan struct {
+	files    map[string]localFileInfo // normalized relative path -> file
+	links    map[string]bool          // normalized relative paths of skipped links
+	symlinks SymlinkSummary
+}
+
+// skipped reports whether rel is a link the scan left alone, or lies under a
+// directory link it left alone
+func (scan *localScan) skipped(rel string) bool {
+	for ; rel != "." && rel != "/"; rel = path.Dir(rel) {
+		if scan.links[rel] {
+			return true
+		}
+	}
+	return false
+}
+
+// scanDir walks dir, recording regular files under their normalized path
+// relative to root. ancestors holds the resolved directories on the current
+// path so that followed links can't loop forever.
+func (s *SyncManager) scanDir(root, dir string, ancestors map[string]bool, scan *localScan) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		relPath, err := filepath.Rel(root, path)
+		if err != nil {
+			return err
+		}
+		// Normalize path separators and Unicode form
+		relPath = s.norm.apply(filepath.ToSlash(relPath))
+
+		info, err := os.Lstat(path)
+		if err != nil {
+			return err
+		}
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			switch s.symlinks {
+			case SymlinkError:
+				return fmt.Errorf("symbolic link found: %s", path)
+			case SymlinkSkip:
+				scan.symlinks.Skipped++
+				scan.links[relPath] = true
+				continue
+			}
+
+			info, err = os.Stat(path)
+			if err != nil {
+				scan.symlinks.Broken++
+				scan.links[relPath] = true
+				continue
+			}
+			scan.symlinks.Followed++
+		}
+
+		if info.IsDir() {
+			resolved, err := filepath.EvalSymlinks(path)
+			if err != nil {
+				return err
+			}
+			if ancestors[resolved] {
+				scan.symlinks.Cycles++
+				continue
+			}
+			ancestors[resolved] = true
+			err = s.scanDir(root, path, ancestors, scan)
+			delete(ancestors, resolved)
+			if err != nil {
+				return err
+			}
+			continue
+		}
+
+		scan.files[relPath] = localFileInfo{FileInfo: info, path: path}
+	}
+
+	return nil
+}
@@ -8,9 +8,13 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/security"
+	"golang.org/x/text/unicode/norm"
 )
 
 // SyncResult contains the result of a sync operation
@@ -18,18 +22,93 @@ type SyncResult struct {
 	ToDownload []aws.S3Object // Files that need to be downloaded
 	Unchanged  []aws.S3Object // Files that are already up to date
 	TotalBytes int64          // Total bytes to download
+	Symlinks   SymlinkSummary // Links found while scanning the local directory
+
+	localPaths map[string]string // key -> existing local file it was matched with
+}
+
+// LocalPath returns the existing local file the object key was matched
+// with, which may be under a normalized or escaped form of its name
+func (r *SyncResult) LocalPath(key string) (string, bool) {
+	path, ok := r.localPaths[key]
+	return path, ok
+}
+
+// Normalization decides how Unicode key and file names are compared during
+// sync. macOS file systems often hand back names in NFD while keys written
+// from Linux or the console are NFC, so "é" can be two different byte strings.
+type Normalization int
+
+const (
+	NormalizeNFC  Normalization = iota // compare (and upload) names in NFC
+	NormalizeNFD                       // compare (and upload) names in NFD
+	NormalizeNone                      // compare names byte for byte
+)
+
+// ParseNormalization parses a normalization policy name from config
+func ParseNormalization(s string) (Normalization, error) {
+	switch strings.ToLower(s) {
+	case "", "nfc":
+		return NormalizeNFC, nil
+	case "nfd":
+		return NormalizeNFD, nil
+	case "none":
+		return NormalizeNone, nil
+	default:
+		return NormalizeNFC, fmt.Errorf("unknown unicode normalization %q (use nfc, nfd or none)", s)
+	}
+}
+
+func (n Normalization) apply(name string) string {
+	switch n {
+	case NormalizeNFC:
+		return norm.NFC.String(name)
+	case NormalizeNFD:
+		return norm.NFD.String(name)
+	default:
+		return name
+	}
+}
+
+// LocalFile is a local file and the key it maps to in S3
+type LocalFile struct {
+	Path   string // absolute or relative local path
+	Key    string // full S3 key
+	Size   int64
+	Exists bool // an object already has Key; set by CompareLocal
+}
+
+// UploadSyncResult contains the result of comparing a local directory
+// against a prefix in the upload direction
+type UploadSyncResult struct {
+	ToUpload   []LocalFile    // Files that are new or changed locally
+	Unchanged  []LocalFile    // Files that already match S3
+	TotalBytes int64          // Total bytes to upload
+	Symlinks   SymlinkSummary // Links found while scanning the local directory
 }
 
 // SyncManager handles sync operations
 type SyncManager struct {
-	client *aws.Client
+	client   Client
+	norm     Normalization
+	symlinks SymlinkPolicy
 }
 
 // NewSyncManager creates a new sync manager
-func NewSyncManager(client *aws.Client) *SyncManager {
+func NewSyncManager(client Client) *SyncManager {
 	return &SyncManager{client: client}
 }
 
+// SetSymlinkPolicy sets how local scans treat symbolic links
+func (s *SyncManager) SetSymlinkPolicy(p SymlinkPolicy) {
+	s.symlinks = p
+}
+
+// SetNormalization sets how Unicode names are compared
+func (s *SyncManager) SetNormalization(n Normalization) {
+	s.norm = n
+}
+
 // CompareFiles compares S3 objects with local files and returns sync plan
 func (s *SyncManager) CompareFiles(ctx context.Context, bucket, prefix, localDir string) (*SyncResult, error) {
 	// List all S3 objects
@@ -39,48 +118,53 @@ func (s *SyncManager) CompareFiles(ctx context.Context, bucket, prefix, localDir
 	}
 
 	// Build local file map
-	localFiles, err := s.buildLocalFileMap(localDir, prefix)
+	scan, err := s.buildLocalFileMap(localDir, prefix)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan local directory: %w", err)
 	}
 
-	result := &SyncResult{}
+	result := &SyncResult{localPaths: make(map[string]string), Symlinks: scan.symlinks}
 
 	for _, obj := range objects {
-		relPath := strings.TrimPrefix(obj.Key, prefix)
-		localPath := filepath.Join(localDir, relPath)
+		relPath := s.norm.apply(strings.TrimPrefix(obj.Key, prefix))
 
-		localInfo, exists := localFiles[relPath]
+		// Never write through a link the policy left alone, whether it's
+		// the file itself or a directory above it, under either name
+		escaped, _ := security.LocalRelPath(relPath, security.PathEscape)
+		if scan.skipped(relPath) || scan.skipped(escaped) {
+			continue
+		}
+
+		local, exists := scan.files[relPath]
+		if !exists && escaped != relPath {
+			// An earlier sync may have stored it under an escaped name
+			local, exists = scan.files[escaped]
+		}
 		if !exists {
 			// File doesn't exist locally
 			result.ToDownload = append(result.ToDownload, obj)
 			result.TotalBytes += obj.Size
 			continue
 		}
+		result.localPaths[obj.Key] = local.path
 
 		// Quick check: size comparison
-		if localInfo.Size() != obj.Size {
+		if local.Size() != obj.Size {
 			result.ToDownload = append(result.ToDownload, obj)
 			result.TotalBytes += obj.Size
 			continue
 		}
 
-		// Detailed check: ETag comparison
-		// Note: For multipart uploads, ETag is not MD5, so we skip hash check for those
-		if !strings.Contains(obj.ETag, "-") {
-			localHash, err := computeFileMD5(localPath)
-			if err != nil {
-				// If we can't compute hash, download to be safe
-				result.ToDownload = append(result.ToDownload, obj)
-				result.TotalBytes += obj.Size
-				continue
-			}
-
-			if localHash != obj.ETag {
-				result.ToDownload = append(result.ToDownload, obj)
-				result.TotalBytes += obj.Size
-				continue
-			}
+		// Detailed check: content comparison, or failing that, whether the
+		// object changed after the local copy was written
+		match, known := s.sameContent(ctx, bucket, local.path, obj)
+		if !known {
+			match = !obj.LastModified.After(local.ModTime())
+		}
+		if !match {
+			result.ToDownload = append(result.ToDownload, obj)
+			result.TotalBytes += obj.Size
+			continue
 		}
 
 		// File matches
@@ -93,41 +177,30 @@ func (s *SyncManager) CompareFiles(ctx context.Context, bucket, prefix, localDir
 // localFileInfo wraps os.FileInfo for our needs
 type localFileInfo struct {
 	os.FileInfo
-	path string
+	path string // path on disk, which may differ from the normalized name
 }
 
-// buildLocalFileMap builds a map of relative path -> file info
-func (s *SyncManager) buildLocalFileMap(localDir, prefix string) (map[string]os.FileInfo, error) {
-	files := make(map[string]os.FileInfo)
+// buildLocalFileMap scans localDir, applying the symlink policy
+func (s *SyncManager) buildLocalFileMap(localDir, prefix string) (*localScan, error) {
+	scan := &localScan{
+		files:    make(map[string]localFileInfo),
+		links:    make(map[string]bool),
+		symlinks: SymlinkSummary{Policy: s.symlinks},
+	}
 
 	// If directory doesn't exist, return empty map
 	if _, err := os.Stat(localDir); os.IsNotExist(err) {
-		return files, nil
+		return scan, nil
 	}
 
-	err := filepath.Walk(localDir, func(path string, info os.FileInfo, err error) error {
-		if err != nil {
-			return err
-		}
-
-		if info.IsDir() {
-			return nil
-		}
-
-		// Get relative path
-		relPath, err := filepath.Rel(localDir, path)
-		if err != nil {
-			return err
-		}
-
-		// Normalize path separators
-		relPath = filepath.ToSlash(relPath)
-		files[relPath] = info
-
-		return nil
-	})
-
-	return files, err
+	root, err := filepath.EvalSymlinks(localDir)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.scanDir(localDir, localDir, map[string]bool{root: true}, scan); err != nil {
+		return nil, err
+	}
+	return scan, nil
 }
 
 // computeFileMD5 computes the MD5 hash of a file
@@ -146,40 +219,230 @@ func computeFileMD5(path string) (string, error) {
 	return hex.EncodeToString(hash.Sum(nil)), nil
 }
 
-// Sync performs a sync operation, downloading only changed/new files
+// Sync performs a sync operation, downloading only changed/new files. A
+// sync with nothing to download completes with no files.
 func (s *SyncManager) Sync(ctx context.Context, bucket, prefix, localDir string, manager *Manager) error {
-	// Compare files
-	result, err := s.CompareFiles(ctx, bucket, prefix, localDir)
+	return s.run(ctx, bucket, manager, func(ctx context.Context) error {
+		result, err := s.CompareFiles(ctx, bucket, prefix, localDir)
+		if err != nil {
+			return err
+		}
+		return s.download(ctx, bucket, prefix, localDir, result, manager)
+	})
+}
+
+// Apply downloads the files in a plan CompareFiles made, which the caller
+// may have narrowed down, e.g. with filters. Like Sync, it writes each file
+// over the local file it was matched with, whatever form its name is in.
+func (s *SyncManager) Apply(ctx context.Context, bucket, prefix, localDir string, result *SyncResult, manager *Manager) error {
+	return s.run(ctx, bucket, manager, func(ctx context.Context) error {
+		return s.download(ctx, bucket, prefix, localDir, result, manager)
+	})
+}
+
+// run claims manager for a sync of bucket and gives the sync its final
+// status
+func (s *SyncManager) run(ctx context.Context, bucket string, manager *Manager, sync func(ctx context.Context) error) error {
+	ctx, done, err := manager.begin(ctx)
 	if err != nil {
 		return err
 	}
+	defer done()
 
-	if len(result.ToDownload) == 0 {
-		return nil // Nothing to download
+	manager.progressMu.Lock()
+	manager.progress = Progress{
+		Bucket:    bucket,
+		Files:     make(map[string]*FileProgress),
+		StartedAt: time.Now(),
+		Status:    StatusInProgress,
+	}
+	manager.progressMu.Unlock()
+
+	err = sync(ctx)
+
+	manager.progressMu.Lock()
+	if err != nil && ctx.Err() != nil {
+		manager.progress.Status = StatusCancelled
+	} else if err != nil || manager.progress.FailedFiles > 0 {
+		manager.progress.Status = StatusFailed
+	} else {
+		manager.progress.Status = StatusCompleted
 	}
+	manager.progressMu.Unlock()
+
+	manager.notifyProgress()
+	manager.notifyComplete()
 
+	return err
+}
+
+// download fetches a sync plan's files on the manager run has claimed
+func (s *SyncManager) download(ctx context.Context, bucket, prefix, localDir string, result *SyncResult, manager *Manager) error {
 	// Initialize progress for sync
 	files := make(map[string]*FileProgress)
+	escapedFiles := 0
+	var totalBytes int64
 	for _, obj := range result.ToDownload {
-		relPath := strings.TrimPrefix(obj.Key, prefix)
-		localPath := filepath.Join(localDir, relPath)
+		// Overwrite the file that matched, even if its name is in another form
+		localPath, ok := result.localPaths[obj.Key]
+		escaped := false
+		if !ok {
+			var err error
+			localPath, escaped, err = keyPath(localDir, strings.TrimPrefix(obj.Key, prefix), manager.pathPolicy)
+			if err != nil {
+				return fmt.Errorf("key %s: %w", obj.Key, err)
+			}
+		}
+		if escaped {
+			escapedFiles++
+		}
+		totalBytes += obj.Size
 		files[obj.Key] = &FileProgress{
 			Key:       obj.Key,
 			LocalPath: localPath,
 			Size:      obj.Size,
 			Status:    StatusPending,
+			Escaped:   escaped,
 		}
 	}
 
 	manager.progressMu.Lock()
-	manager.progress = Progress{
-		TotalFiles: len(result.ToDownload),
-		TotalBytes: result.TotalBytes,
-		Files:      files,
-		Status:     StatusInProgress,
-	}
+	manager.progress.TotalFiles = len(result.ToDownload)
+	manager.progress.TotalBytes = totalBytes
+	manager.progress.Files = files
+	manager.progress.Summary = result.Symlinks.String()
+	manager.progress.EscapedFiles = escapedFiles
 	manager.progressMu.Unlock()
 
+	if len(result.ToDownload) == 0 {
+		return nil // Nothing to download
+	}
+	manager.notifyProgress()
+
 	// Download the files
 	return manager.downloadWithWorkers(ctx, bucket, result.ToDownload, prefix, localDir)
 }
+
+// CompareLocal compares a local directory with the objects under prefix and
+// returns the files that need uploading. It is the upload-direction
+// counterpart of CompareFiles.
+func (s *SyncManager) CompareLocal(ctx context.Context, localDir, bucket, prefix string) (*UploadSyncResult, error) {
+	info, err := os.Stat(localDir)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan local directory: %w", err)
+	}
+	if !info.IsDir() {
+		return nil, fmt.Errorf("not a directory: %s", localDir)
+	}
+
+	scan, err := s.buildLocalFileMap(localDir, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan local directory: %w", err)
+	}
+
+	objects, err := s.client.ListAllObjects(ctx, bucket, prefix)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list S3 objects: %w", err)
+	}
+
+	result, err := s.compareLocal(ctx, bucket, prefix, scan.files, objects)
+	if err != nil {
+		return nil, err
+	}
+	result.Symlinks = scan.symlinks
+	return result, nil
+}
+
+// compareLocal builds the upload plan from a local file map and the objects
+// under prefix
+func (s *SyncManager) compareLocal(ctx context.Context, bucket, prefix string, localFiles map[string]localFileInfo, objects []aws.S3Object) (*UploadSyncResult, error) {
+	remote := make(map[string]aws.S3Object, len(objects))
+	for _, obj := range objects {
+		remote[s.norm.apply(obj.Key)] = obj
+	}
+
+	result := &UploadSyncResult{}
+
+	relPaths := make([]string, 0, len(localFiles))
+	for relPath := range localFiles {
+		relPaths = append(relPaths, relPath)
+	}
+	sort.Strings(relPaths)
+
+	for _, relPath := range relPaths {
+		local := localFiles[relPath]
+		if !local.Mode().IsRegular() {
+			continue
+		}
+
+		file := LocalFile{
+			Path: local.path,
+			Key:  prefix + relPath,
+			Size: local.Size(),
+		}
+		if err := security.ValidObjectKey(file.Key); err != nil {
+			return nil, fmt.Errorf("%s: %w", relPath, err)
+		}
+
+		obj, exists := remote[s.norm.apply(file.Key)]
+		if exists {
+			// Keep the existing key rather than adding a second spelling
+			file.Key = obj.Key
+			file.Exists = true
+		}
+		if !exists || !s.localMatchesObject(ctx, bucket, file, local.ModTime(), obj) {
+			result.ToUpload = append(result.ToUpload, file)
+			result.TotalBytes += file.Size
+			continue
+		}
+		result.Unchanged = append(result.Unchanged, file)
+	}
+
+	return result, nil
+}
+
+// localMatchesObject returns true if obj has the same size and content as
+// the local file, or if its content can't be compared, wasn't uploaded
+// before the file last changed
+func (s *SyncManager) localMatchesObject(ctx context.Context, bucket string, file LocalFile, modTime time.Time, obj aws.S3Object) bool {
+	if obj.Size != file.Size {
+		return false
+	}
+	match, known := s.sameContent(ctx, bucket, file.Path, obj)
+	if !known {
+		return !modTime.After(obj.LastModified)
+	}
+	return match
+}
+
+// sameContent compares a local file of obj's size with obj. A listed ETag
+// that's the file's MD5 settles it; otherwise the object's checksums are
+// fetched, as multipart, SSE-KMS and SSE-C ETags aren't an MD5 of the
+// content. known is false if the object has nothing the file can be
+// checked against. Files that can't be read don't match.
+func (s *SyncManager) sameContent(ctx context.Context, bucket, path string, obj aws.S3Object) (match, known bool) {
+	md5Checked := false
+	if !strings.Contains(obj.ETag, "-") {
+		localHash, err := computeFileMD5(path)
+		if err != nil {
+			return false, true
+		}
+		if localHash == obj.ETag {
+			return true, true
+		}
+		md5Checked = true
+	}
+
+	sums, err := s.client.GetObjectChecksums(ctx, bucket, obj.Key)
+	if err != nil {
+		return false, true // transfer to be safe
+	}
+	switch {
+	case len(sums.Checksums) == 0 && !sums.MD5ETag:
+		return false, false
+	case len(sums.Checksums) == 0 && sums.Parts == 0 && md5Checked:
+		return false, true // the ETag is an MD5 and it didn't match
+	}
+	result, err := verifyFile(path, sums)
+	return err == nil && result.Match, true
+}
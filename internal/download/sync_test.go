@@ -0,0 +1,312 @@
+package download
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+	"golang.org/x/text/unicode/norm"
+)
+
+func TestCompareLocal(t *testing.T) {
+	dir := t.TempDir()
+	write := func(rel, content string) {
+		path := filepath.Join(dir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	write("same.txt", "hello")
+	write("changed.txt", "new content")
+	write("sub/new.txt", "fresh")
+
+	write("kms-same.txt", "hello")
+	write("kms-edited.txt", "hello")
+	write("multipart-same.txt", "hello")
+	write("multipart-edited.txt", "jello")
+
+	now := time.Now()
+	client := &checksumClient{sums: map[string]*aws.ObjectChecksums{
+		"data/changed.txt": {Size: 11, ETag: "00000000000000000000000000000000", MD5ETag: true},
+		// KMS ETags aren't an MD5, so only the upload time can tell
+		"data/kms-same.txt":   {Size: 5, ETag: "opaque"},
+		"data/kms-edited.txt": {Size: 5, ETag: "opaque"},
+		"data/multipart-same.txt": {Size: 5, ETag: "opaque-2", Parts: 2, PartSize: 3,
+			Checksums: []aws.Checksum{{Algorithm: "CRC32", Value: crc32Sum("hello")}}},
+		"data/multipart-edited.txt": {Size: 5, ETag: "opaque-2", Parts: 2, PartSize: 3,
+			Checksums: []aws.Checksum{{Algorithm: "CRC32", Value: crc32Sum("hello")}}},
+	}}
+	s := &SyncManager{client: client}
+	scan, err := s.buildLocalFileMap(dir, "data/")
+	if err != nil {
+		t.Fatalf("buildLocalFileMap() error = %v", err)
+	}
+
+	remote := []aws.S3Object{
+		// md5("hello")
+		{Key: "data/same.txt", Size: 5, ETag: "5d41402abc4b2a76b9719d911017c592"},
+		{Key: "data/changed.txt", Size: 11, ETag: "00000000000000000000000000000000"},
+		{Key: "data/kms-same.txt", Size: 5, ETag: "opaque", LastModified: now.Add(time.Hour)},
+		{Key: "data/kms-edited.txt", Size: 5, ETag: "opaque", LastModified: now.Add(-time.Hour)},
+		{Key: "data/multipart-same.txt", Size: 5, ETag: "opaque-2", LastModified: now.Add(-time.Hour)},
+		{Key: "data/multipart-edited.txt", Size: 5, ETag: "opaque-2", LastModified: now.Add(time.Hour)},
+	}
+
+	result, err := s.compareLocal(context.Background(), "bucket", "data/", scan.files, remote)
+	if err != nil {
+		t.Fatalf("compareLocal() error = %v", err)
+	}
+
+	var keys []string
+	for _, f := range result.ToUpload {
+		keys = append(keys, f.Key)
+	}
+	want := []string{"data/changed.txt", "data/kms-edited.txt", "data/multipart-edited.txt", "data/sub/new.txt"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("ToUpload = %v, want %v", keys, want)
+	}
+	keys = nil
+	for _, f := range result.Unchanged {
+		keys = append(keys, f.Key)
+	}
+	want = []string{"data/kms-same.txt", "data/multipart-same.txt", "data/same.txt"}
+	if !slices.Equal(keys, want) {
+		t.Errorf("Unchanged = %v, want %v", keys, want)
+	}
+	if result.TotalBytes != 26 {
+		t.Errorf("TotalBytes = %d, want 26", result.TotalBytes)
+	}
+}
+
+// checksumClient reports the checksums in sums for each key
+type checksumClient struct {
+	Client
+	sums map[string]*aws.ObjectChecksums
+}
+
+func (c *checksumClient) GetObjectChecksums(ctx context.Context, bucket, key string) (*aws.ObjectChecksums, error) {
+	if sums, ok := c.sums[key]; ok {
+		return sums, nil
+	}
+	return nil, fmt.Errorf("no such key %s", key)
+}
+
+// crc32Sum is the base64 CRC32 checksum S3 gives content
+func crc32Sum(content string) string {
+	sum := crc32.ChecksumIEEE([]byte(content))
+	return base64.StdEncoding.EncodeToString(binary.BigEndian.AppendUint32(nil, sum))
+}
+
+func TestCompareLocalNormalization(t *testing.T) {
+	dir := t.TempDir()
+	nfd := "cafe\u0301.txt" // "café" as macOS stores it
+	nfc := "caf\u00e9.txt"
+	if err := os.WriteFile(filepath.Join(dir, nfd), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	remote := []aws.S3Object{
+		{Key: nfc, Size: 5, ETag: "5d41402abc4b2a76b9719d911017c592"},
+	}
+
+	tests := []struct {
+		name      string
+		norm      Normalization
+		unchanged int
+	}{
+		{"nfc matches", NormalizeNFC, 1},
+		{"nfd matches", NormalizeNFD, 1},
+		{"none differs", NormalizeNone, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := &SyncManager{norm: tt.norm}
+			scan, err := s.buildLocalFileMap(dir, "")
+			if err != nil {
+				t.Fatalf("buildLocalFileMap() error = %v", err)
+			}
+			result, err := s.compareLocal(context.Background(), "bucket", "", scan.files, remote)
+			if err != nil {
+				t.Fatalf("compareLocal() error = %v", err)
+			}
+			if len(result.Unchanged) != tt.unchanged {
+				t.Errorf("Unchanged = %d, want %d", len(result.Unchanged), tt.unchanged)
+			}
+		})
+	}
+}
+
+func TestBuildLocalFileMapSymlinks(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "sub"), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "sub", "file.txt"), []byte("x"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(dir, "sub", "file.txt"), filepath.Join(dir, "link.txt")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+	// A link back to the root would loop forever if followed naively
+	if err := os.Symlink(dir, filepath.Join(dir, "sub", "loop")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		policy    SymlinkPolicy
+		wantFiles int
+		wantErr   bool
+	}{
+		{SymlinkSkip, 1, false},
+		{SymlinkFollow, 2, false},
+		{SymlinkError, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.policy.String(), func(t *testing.T) {
+			s := &SyncManager{symlinks: tt.policy}
+			scan, err := s.buildLocalFileMap(dir, "")
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("buildLocalFileMap() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil {
+				return
+			}
+			if len(scan.files) != tt.wantFiles {
+				t.Errorf("files = %d, want %d", len(scan.files), tt.wantFiles)
+			}
+			if tt.policy == SymlinkFollow && scan.symlinks.Cycles != 1 {
+				t.Errorf("Cycles = %d, want 1", scan.symlinks.Cycles)
+			}
+		})
+	}
+}
+
+// TestCompareFilesSkipsLinkedDirs checks that a sync never writes through a
+// directory link the policy left alone
+func TestCompareFilesSkipsLinkedDirs(t *testing.T) {
+	dir, outside := t.TempDir(), t.TempDir()
+	if err := os.Symlink(outside, filepath.Join(dir, "linkdir")); err != nil {
+		t.Skipf("symlinks not supported: %v", err)
+	}
+
+	objects := map[string][]byte{"data/linkdir/file.txt": []byte("x"), "data/plain.txt": []byte("y")}
+	sm := NewSyncManager(newChaosClient(objects))
+	result, err := sm.CompareFiles(context.Background(), "bucket", "data/", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(result.ToDownload) != 1 || result.ToDownload[0].Key != "data/plain.txt" {
+		t.Errorf("ToDownload = %v, want only data/plain.txt", result.ToDownload)
+	}
+
+	m := NewManager(sm.client, 2)
+	if err := sm.Sync(context.Background(), "bucket", "data/", dir, m); err != nil {
+		t.Fatal(err)
+	}
+	if entries, _ := os.ReadDir(outside); len(entries) != 0 {
+		t.Errorf("sync wrote %d files through the skipped link", len(entries))
+	}
+}
+
+// TestApplyWritesOverMatchedFile checks that a narrowed sync plan replaces
+// the local copy a key was matched with, even under another Unicode form
+func TestApplyWritesOverMatchedFile(t *testing.T) {
+	dir := t.TempDir()
+	nfd := norm.NFD.String("café.txt")
+	if err := os.WriteFile(filepath.Join(dir, nfd), []byte("old"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	objects := map[string][]byte{"data/café.txt": []byte("new!"), "data/other.txt": []byte("x")}
+	sm := NewSyncManager(newChaosClient(objects))
+	result, err := sm.CompareFiles(context.Background(), "bucket", "data/", dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result.LocalPath("data/café.txt"); !ok {
+		t.Fatal("café.txt wasn't matched with its NFD copy")
+	}
+
+	// Leave other.txt out, as a job's filters would
+	result.ToDownload = slices.DeleteFunc(result.ToDownload, func(obj aws.S3Object) bool { return obj.Key == "data/other.txt" })
+	m := NewManager(sm.client, 2)
+	if err := sm.Apply(context.Background(), "bucket", "data/", dir, result, m); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, _ := os.ReadDir(dir)
+	if len(entries) != 1 {
+		t.Fatalf("directory has %d files, want the one copy", len(entries))
+	}
+	if got, _ := os.ReadFile(filepath.Join(dir, nfd)); string(got) != "new!" {
+		t.Errorf("matched copy = %q, want the new content", got)
+	}
+	if p := m.GetProgress(); p.Status != StatusCompleted || p.TotalFiles != 1 || p.TotalBytes != 4 {
+		t.Errorf("progress = %s, %d files, %d bytes; want completed, 1 file, 4 bytes", p.Status, p.TotalFiles, p.TotalBytes)
+	}
+}
+
+func TestSyncStatus(t *testing.T) {
+	objects := testObjects(3)
+	client := newChaosClient(objects)
+	client.failAt["data/01.txt"] = 4
+	dir := t.TempDir()
+
+	m := NewManager(client, 2)
+	var completed []Progress
+	m.SetCompleteCallback(func(p Progress) { completed = append(completed, p) })
+	if err := NewSyncManager(client).Sync(context.Background(), "bucket", "data/", dir, m); err != nil {
+		t.Fatalf("Sync() error = %v", err)
+	}
+	p := m.GetProgress()
+	checkFinished(t, p)
+	if p.Status != StatusFailed || p.CompletedFiles != 2 || p.FailedFiles != 1 {
+		t.Errorf("Status = %s with %d completed, %d failed; want failed with 2, 1", p.Status, p.CompletedFiles, p.FailedFiles)
+	}
+
+	// The second sync only fetches the file that failed, and then nothing
+	delete(client.failAt, "data/01.txt")
+	for _, want := range []int{1, 0} {
+		if err := NewSyncManager(client).Sync(context.Background(), "bucket", "data/", dir, m); err != nil {
+			t.Fatalf("Sync() error = %v", err)
+		}
+		if p := m.GetProgress(); p.Status != StatusCompleted || p.TotalFiles != want || p.CompletedFiles != want {
+			t.Errorf("Status = %s with %d of %d files, want completed with %d", p.Status, p.CompletedFiles, p.TotalFiles, want)
+		}
+	}
+	if len(completed) != 3 {
+		t.Errorf("completion reported %d times for 3 syncs", len(completed))
+	}
+}
+
+func TestSyncRefusesBusyManager(t *testing.T) {
+	objects := testObjects(2)
+	client := newChaosClient(objects)
+	client.stalls["data/00.txt"] = 1
+	started := make(chan struct{}, len(objects))
+	client.onStart = func(ctx context.Context, key string) { started <- struct{}{} }
+
+	m := NewManager(client, 1)
+	first := make(chan error)
+	go func() { first <- m.DownloadPrefix(context.Background(), "bucket", "data/", t.TempDir()) }()
+	<-started
+
+	if err := NewSyncManager(client).Sync(context.Background(), "bucket", "data/", t.TempDir(), m); !errors.Is(err, ErrBusy) {
+		t.Errorf("Sync() during another transfer error = %v, want ErrBusy", err)
+	}
+	m.Cancel()
+	<-first
+}
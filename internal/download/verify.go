@@ -0,0 +1,135 @@
+package download
+
+import (
+	"context"
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"hash"
+	"hash/crc32"
+	"hash/crc64"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// VerifyResult reports how a local file compares to an S3 object
+type VerifyResult struct {
+	Match  bool
+	Method string // what was compared: "size", "MD5", "multipart ETag" or a checksum algorithm
+	Local  string // the local file's value
+	Remote string // the object's value
+}
+
+// Verify compares a local file to an object, using the strongest check the
+// object allows: its strongest additional checksum, then its MD5 or
+// multipart ETag, and only its size when neither can be recomputed locally
+func Verify(ctx context.Context, client *aws.Client, bucket, key, localPath string) (VerifyResult, error) {
+	sums, err := client.GetObjectChecksums(ctx, bucket, key)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	return verifyFile(localPath, sums)
+}
+
+// verifyFile compares a local file to an object's checksums
+func verifyFile(localPath string, sums *aws.ObjectChecksums) (VerifyResult, error) {
+	info, err := os.Stat(localPath)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	if info.IsDir() {
+		return VerifyResult{}, fmt.Errorf("%s is a directory", localPath)
+	}
+	if info.Size() != sums.Size {
+		return VerifyResult{
+			Method: "size",
+			Local:  fmt.Sprintf("%d bytes", info.Size()),
+			Remote: fmt.Sprintf("%d bytes", sums.Size),
+		}, nil
+	}
+
+	var (
+		method string
+		remote string
+		encode = base64.StdEncoding.EncodeToString
+		newSum func() hash.Hash
+	)
+	switch {
+	case len(sums.Checksums) > 0:
+		checksum := sums.Checksums[0]
+		method, remote, newSum = checksum.Algorithm, checksum.Value, checksumHashes[checksum.Algorithm]
+	case sums.MD5ETag && sums.Parts > 0:
+		method, remote, encode, newSum = "multipart ETag", sums.ETag, hex.EncodeToString, md5.New
+	case sums.MD5ETag:
+		method, remote, encode, newSum = "MD5", sums.ETag, hex.EncodeToString, md5.New
+	default:
+		size := fmt.Sprintf("%d bytes", sums.Size)
+		return VerifyResult{Match: true, Method: "size", Local: size, Remote: size}, nil
+	}
+
+	// Composite checksums and multipart ETags hash the part hashes
+	var partSize int64
+	if _, _, composite := strings.Cut(remote, "-"); composite {
+		partSize = sums.PartSize
+	}
+	local, err := fileChecksum(localPath, partSize, newSum, encode)
+	if err != nil {
+		return VerifyResult{}, err
+	}
+	return VerifyResult{Match: local == remote, Method: method, Local: local, Remote: remote}, nil
+}
+
+// crc64NVME is the CRC-64/NVME table, in the reversed form hash/crc64 wants
+var crc64NVME = crc64.MakeTable(0x9a6c9329ac4bc9b5)
+
+// checksumHashes computes each of S3's additional checksum algorithms
+var checksumHashes = map[string]func() hash.Hash{
+	"SHA-256":   sha256.New,
+	"SHA-1":     sha1.New,
+	"CRC64NVME": func() hash.Hash { return crc64.New(crc64NVME) },
+	"CRC32C":    func() hash.Hash { return crc32.New(crc32.MakeTable(crc32.Castagnoli)) },
+	"CRC32":     func() hash.Hash { return crc32.NewIEEE() },
+}
+
+// fileChecksum hashes a file the way S3 does. With a part size it hashes
+// each part, then the concatenated part hashes, and appends "-<parts>".
+func fileChecksum(path string, partSize int64, newSum func() hash.Hash, encode func([]byte) string) (string, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	if partSize <= 0 {
+		h := newSum()
+		if _, err := io.Copy(h, file); err != nil {
+			return "", err
+		}
+		return encode(h.Sum(nil)), nil
+	}
+
+	combined := newSum()
+	parts := 0
+	for {
+		h := newSum()
+		n, err := io.CopyN(h, file, partSize)
+		if err != nil && err != io.EOF {
+			return "", err
+		}
+		if n == 0 && parts > 0 {
+			break
+		}
+		combined.Write(h.Sum(nil))
+		parts++
+		if n < partSize {
+			break
+		}
+	}
+	return fmt.Sprintf("%s-%d", encode(combined.Sum(nil)), parts), nil
+}
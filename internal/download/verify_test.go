@@ -0,0 +1,105 @@
+package download
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+func TestVerifyFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "hello.txt")
+	if err := os.WriteFile(path, []byte("hello world"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name       string
+		sums       aws.ObjectChecksums
+		wantMatch  bool
+		wantMethod string
+	}{
+		{
+			name:       "size differs",
+			sums:       aws.ObjectChecksums{Size: 12, ETag: "5eb63bbbe01eeed093cb22bb8f5acdc3", MD5ETag: true},
+			wantMethod: "size",
+		},
+		{
+			name:       "md5 matches",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "5eb63bbbe01eeed093cb22bb8f5acdc3", MD5ETag: true},
+			wantMatch:  true,
+			wantMethod: "MD5",
+		},
+		{
+			name:       "md5 differs",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "00000000000000000000000000000000", MD5ETag: true},
+			wantMethod: "MD5",
+		},
+		{
+			name:       "multipart etag matches",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "df349a9519959b17a605009540f4b31d-3", Parts: 3, PartSize: 5, MD5ETag: true},
+			wantMatch:  true,
+			wantMethod: "multipart ETag",
+		},
+		{
+			name:       "multipart etag with other part size",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "df349a9519959b17a605009540f4b31d-3", Parts: 3, PartSize: 4, MD5ETag: true},
+			wantMethod: "multipart ETag",
+		},
+		{
+			name:       "sha256 preferred over etag",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "00000000000000000000000000000000", Checksums: []aws.Checksum{{Algorithm: "SHA-256", Value: "uU0nuZNNPgilLlLX2n2r+sSE7+N6U4DukIj3rOLvzek="}}, MD5ETag: true},
+			wantMatch:  true,
+			wantMethod: "SHA-256",
+		},
+		{
+			name:       "composite sha256",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "opaque-3", Checksums: []aws.Checksum{{Algorithm: "SHA-256", Value: "pzSGO5U+k/TnIIwV9oZNNyf1DQbT37kZpa4hVAUTJ/A=-3"}}, Parts: 3, PartSize: 5},
+			wantMatch:  true,
+			wantMethod: "SHA-256",
+		},
+		{
+			name:       "full-object crc64nvme of a multipart object",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "opaque-3", Checksums: []aws.Checksum{{Algorithm: "CRC64NVME", Value: "jSnVw/bqjr4="}}, Parts: 3, PartSize: 5},
+			wantMatch:  true,
+			wantMethod: "CRC64NVME",
+		},
+		{
+			name:       "composite crc32",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "opaque-3", Checksums: []aws.Checksum{{Algorithm: "CRC32", Value: "NyyG8Q==-3"}}, Parts: 3, PartSize: 5},
+			wantMatch:  true,
+			wantMethod: "CRC32",
+		},
+		{
+			name:       "crc32c differs",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "5eb63bbbe01eeed093cb22bb8f5acdc3", Checksums: []aws.Checksum{{Algorithm: "CRC32C", Value: "AAAAAA=="}}, MD5ETag: true},
+			wantMethod: "CRC32C",
+		},
+		{
+			name:       "crc32c matches",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "opaque", Checksums: []aws.Checksum{{Algorithm: "CRC32C", Value: "yZRlqg=="}}},
+			wantMatch:  true,
+			wantMethod: "CRC32C",
+		},
+		{
+			name:       "kms etag falls back to size",
+			sums:       aws.ObjectChecksums{Size: 11, ETag: "00000000000000000000000000000000"},
+			wantMatch:  true,
+			wantMethod: "size",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := verifyFile(path, &tt.sums)
+			if err != nil {
+				t.Fatalf("verifyFile() error = %v", err)
+			}
+			if got.Match != tt.wantMatch || got.Method != tt.wantMethod {
+				t.Errorf("verifyFile() = %v (%s), want %v (%s); local %s, remote %s",
+					got.Match, got.Method, tt.wantMatch, tt.wantMethod, got.Local, got.Remote)
+			}
+		})
+	}
+}
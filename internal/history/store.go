@@ -0,0 +1,190 @@
+package history
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/download"
+)
+
+// MaxEntries is how many answers are kept for each kind of prompt
+const MaxEntries = 50
+
+// Store keeps earlier answers to prompts, e.g. download destinations, by
+// kind of prompt, newest first, so they can be recalled in later sessions.
+// It also remembers where downloads from each location went, and the
+// transfers the user named or noted. It's safe for concurrent use, so it
+// can be written from a tea.Cmd.
+type Store struct {
+	mu   sync.Mutex
+	path string
+	data storeData
+}
+
+// storeData is the history file's contents
+type storeData struct {
+	Answers      map[string][]string `json:"answers,omitempty"`      // by kind of prompt, newest first
+	Destinations map[string]string   `json:"destinations,omitempty"` // local directory by "bucket/prefix"
+	Jobs         []Job               `json:"jobs,omitempty"`         // newest first
+}
+
+// Job is a finished transfer the user gave a name or a note, kept so they
+// outlive the session
+type Job struct {
+	Label          string             `json:"label,omitempty"`
+	Note           string             `json:"note,omitempty"`
+	Name           string             `json:"name"` // what was transferred
+	Direction      download.Direction `json:"direction"`
+	Sync           bool               `json:"sync,omitempty"`
+	Status         download.Status    `json:"status"`
+	CompletedFiles int                `json:"completed_files"`
+	TotalFiles     int                `json:"total_files"`
+	Bytes          int64              `json:"bytes"`
+	StartedAt      time.Time          `json:"started_at"`
+	FinishedAt     time.Time          `json:"finished_at"`
+}
+
+// NewStore creates a new prompt history store
+func NewStore() (*Store, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		path: filepath.Join(configDir, "history.json"),
+	}
+
+	// Try to load the existing history
+	if err := store.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Load reads the history from disk
+func (s *Store) Load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return json.Unmarshal(data, &s.data)
+}
+
+// Save writes the history to disk
+func (s *Store) Save() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.save()
+}
+
+// save writes the history to disk. Caller must hold mu.
+func (s *Store) save() error {
+	data, err := json.MarshalIndent(s.data, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal history: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write history: %w", err)
+	}
+
+	return nil
+}
+
+// Add records an answer to a kind of prompt as the newest, moving it up if
+// it was given before and dropping the oldest past MaxEntries
+func (s *Store) Add(kind, answer string) error {
+	if answer == "" {
+		return nil
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Answers == nil {
+		s.data.Answers = map[string][]string{}
+	}
+	list := slices.DeleteFunc(slices.Clone(s.data.Answers[kind]), func(a string) bool { return a == answer })
+	list = append([]string{answer}, list...)
+	if len(list) > MaxEntries {
+		list = list[:MaxEntries]
+	}
+	s.data.Answers[kind] = list
+	return s.save()
+}
+
+// List returns the answers to a kind of prompt, newest first
+func (s *Store) List(kind string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Answers[kind]
+}
+
+// AddJob records a named or noted transfer as the newest, replacing the
+// record of the same transfer, e.g. when it's renamed after finishing, and
+// dropping the oldest past MaxEntries
+func (s *Store) AddJob(job Job) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	list := slices.DeleteFunc(slices.Clone(s.data.Jobs), func(j Job) bool {
+		return j.Name == job.Name && j.StartedAt.Equal(job.StartedAt)
+	})
+	list = append([]Job{job}, list...)
+	if len(list) > MaxEntries {
+		list = list[:MaxEntries]
+	}
+	s.data.Jobs = list
+	return s.save()
+}
+
+// Jobs returns the named and noted transfers, newest first
+func (s *Store) Jobs() []Job {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.data.Jobs
+}
+
+// SetDestination records the local directory a download from a bucket and
+// prefix went to
+func (s *Store) SetDestination(bucket, prefix, dir string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.data.Destinations == nil {
+		s.data.Destinations = map[string]string{}
+	}
+	s.data.Destinations[bucket+"/"+prefix] = dir
+	return s.save()
+}
+
+// Destination returns the local directory the last download from a bucket
+// and prefix went to, or else from the nearest folder above it that has
+// one, e.g. logs/ for logs/2024/03/
+func (s *Store) Destination(bucket, prefix string) (string, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for {
+		if dir, ok := s.data.Destinations[bucket+"/"+prefix]; ok {
+			return dir, true
+		}
+		if prefix == "" {
+			return "", false
+		}
+		// Up a folder: "logs/2024/" becomes "logs/"
+		prefix = strings.TrimSuffix(prefix, "/")
+		if i := strings.LastIndex(prefix, "/"); i >= 0 {
+			prefix = prefix[:i+1]
+		} else {
+			prefix = ""
+		}
+	}
+}
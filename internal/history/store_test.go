@@ -0,0 +1,171 @@
+package history
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/natevick/stui/internal/download"
+)
+
+func TestHistoryStore(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "history.json")}
+
+	for _, answer := range []string{"./logs", "/data/exports", "", "./logs"} {
+		if err := store.Add("download", answer); err != nil {
+			t.Fatalf("Add(%q) error = %v", answer, err)
+		}
+	}
+	if err := store.Add("bookmark", "Nightly exports"); err != nil {
+		t.Fatal(err)
+	}
+
+	if got, want := store.List("download"), []string{"./logs", "/data/exports"}; !reflect.DeepEqual(got, want) {
+		t.Errorf("List(download) = %v, want %v", got, want)
+	}
+	if got := store.List("sync"); len(got) != 0 {
+		t.Errorf("List(sync) = %v, want nothing", got)
+	}
+
+	// Reload from disk
+	loaded := &Store{path: store.path}
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("failed to load history: %v", err)
+	}
+	if got := loaded.List("bookmark"); !reflect.DeepEqual(got, []string{"Nightly exports"}) {
+		t.Errorf("List(bookmark) after reload = %v", got)
+	}
+
+	if info, err := os.Stat(store.path); err != nil {
+		t.Fatalf("failed to stat history file: %v", err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("history file permissions = %o, want 600", info.Mode().Perm())
+	}
+}
+
+func TestHistoryStoreKeepsNewest(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "history.json")}
+	for i := range MaxEntries + 5 {
+		if err := store.Add("download", fmt.Sprintf("./dir-%d", i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	list := store.List("download")
+	if len(list) != MaxEntries {
+		t.Fatalf("kept %d answers, want %d", len(list), MaxEntries)
+	}
+	if list[0] != fmt.Sprintf("./dir-%d", MaxEntries+4) || list[MaxEntries-1] != "./dir-5" {
+		t.Errorf("kept %s to %s, want the newest %d", list[0], list[MaxEntries-1], MaxEntries)
+	}
+}
+
+func TestHistoryDestination(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "history.json")}
+	if _, ok := store.Destination("logs", "app/"); ok {
+		t.Error("Destination() found one before any download")
+	}
+	if err := store.SetDestination("logs", "app/", "/data/app-logs"); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.SetDestination("logs", "app/2024/03/", "/data/march"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		bucket, prefix, want string
+	}{
+		{"logs", "app/2024/03/", "/data/march"},
+		{"logs", "app/2024/04/", "/data/app-logs"},
+		{"logs", "app/", "/data/app-logs"},
+		{"logs", "web/", ""},
+		{"other", "app/", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := store.Destination(tt.bucket, tt.prefix); got != tt.want {
+			t.Errorf("Destination(%s, %s) = %q, want %q", tt.bucket, tt.prefix, got, tt.want)
+		}
+	}
+
+	loaded := &Store{path: store.path}
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := loaded.Destination("logs", "app/2024/03/"); got != "/data/march" {
+		t.Errorf("Destination() after reload = %q", got)
+	}
+}
+
+func TestHistoryStoreConcurrent(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "history.json")}
+
+	// Answers are saved from commands while the update loop reads them
+	var wg sync.WaitGroup
+	for i := range 10 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := store.Add("download", fmt.Sprintf("./dir-%d", i)); err != nil {
+				t.Error(err)
+			}
+			if err := store.SetDestination("logs", fmt.Sprintf("app-%d/", i), "/data"); err != nil {
+				t.Error(err)
+			}
+		}()
+		store.List("download")
+		store.Destination("logs", "app-0/")
+	}
+	wg.Wait()
+
+	loaded := &Store{path: store.path}
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("failed to load history saved concurrently: %v", err)
+	}
+	if got := len(loaded.List("download")); got != 10 {
+		t.Errorf("saved %d answers, want 10", got)
+	}
+}
+
+func TestHistoryJobs(t *testing.T) {
+	store := &Store{path: filepath.Join(t.TempDir(), "history.json")}
+	started := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	weights := Job{Label: "model weights v3", Name: "Download weights/", Direction: download.DirectionDownload, Status: download.StatusCompleted, StartedAt: started}
+	if err := store.AddJob(weights); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.AddJob(Job{Note: "nightly", Name: "Sync up exports", Sync: true, StartedAt: started.Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	// Renaming a finished job replaces its record
+	weights.Label, weights.Note = "model weights v4", "for the eval run"
+	if err := store.AddJob(weights); err != nil {
+		t.Fatal(err)
+	}
+
+	loaded := &Store{path: store.path}
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	jobs := loaded.Jobs()
+	if len(jobs) != 2 {
+		t.Fatalf("kept %d jobs, want 2", len(jobs))
+	}
+	if jobs[0].Label != "model weights v4" || jobs[0].Note != "for the eval run" || !jobs[0].StartedAt.Equal(started) {
+		t.Errorf("newest job = %+v, want the renamed one", jobs[0])
+	}
+	if jobs[0].Direction != download.DirectionDownload || jobs[0].Status != download.StatusCompleted || !jobs[1].Sync {
+		t.Errorf("jobs after reload = %+v", jobs)
+	}
+
+	for i := range MaxEntries + 5 {
+		store.AddJob(Job{Label: fmt.Sprintf("job %d", i), StartedAt: started.Add(time.Duration(i) * time.Minute)})
+	}
+	if got := len(store.Jobs()); got != MaxEntries {
+		t.Errorf("kept %d jobs, want %d", got, MaxEntries)
+	}
+}
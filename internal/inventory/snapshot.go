@@ -0,0 +1,106 @@
+package inventory
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// Entry is one object in a snapshot
+type Entry struct {
+	Key          string    `json:"key"`
+	Size         int64     `json:"size"`
+	ETag         string    `json:"etag"`
+	LastModified time.Time `json:"last_modified"`
+}
+
+// Info describes a saved snapshot without its objects
+type Info struct {
+	ID      string    `json:"id"`
+	Bucket  string    `json:"bucket"`
+	Prefix  string    `json:"prefix"`
+	TakenAt time.Time `json:"taken_at"`
+	Objects int       `json:"objects"`
+	Size    int64     `json:"size"`
+}
+
+// Path returns the snapshot's S3 location
+func (i Info) Path() string {
+	return fmt.Sprintf("s3://%s/%s", i.Bucket, i.Prefix)
+}
+
+// Snapshot is a recursive listing of a prefix at one point in time
+type Snapshot struct {
+	Info
+	Entries []Entry `json:"entries"`
+}
+
+// NewSnapshot records a listing of bucket/prefix taken now. Folder markers
+// are left out.
+func NewSnapshot(bucket, prefix string, objects []aws.S3Object) Snapshot {
+	s := Snapshot{Info: Info{Bucket: bucket, Prefix: prefix, TakenAt: time.Now()}}
+	for _, obj := range objects {
+		if obj.IsPrefix || strings.HasSuffix(obj.Key, "/") {
+			continue
+		}
+		s.Entries = append(s.Entries, Entry{Key: obj.Key, Size: obj.Size, ETag: obj.ETag, LastModified: obj.LastModified})
+		s.Size += obj.Size
+	}
+	slices.SortFunc(s.Entries, func(a, b Entry) int { return strings.Compare(a.Key, b.Key) })
+	s.Objects = len(s.Entries)
+	return s
+}
+
+// Change is an object present in both listings whose content differs
+type Change struct {
+	Old Entry
+	New Entry
+}
+
+// Diff is what changed under a prefix between a snapshot and a later listing
+type Diff struct {
+	Added   []Entry
+	Removed []Entry
+	Changed []Change
+}
+
+// Empty reports whether nothing changed
+func (d Diff) Empty() bool {
+	return len(d.Added) == 0 && len(d.Removed) == 0 && len(d.Changed) == 0
+}
+
+// Compare diffs a snapshot against a later snapshot of the same prefix. An
+// object counts as changed when its size or ETag differs; a new last
+// modified time alone (e.g. an identical re-upload) doesn't. All lists are
+// sorted by key.
+func Compare(old, current Snapshot) Diff {
+	before := make(map[string]Entry, len(old.Entries))
+	for _, e := range old.Entries {
+		before[e.Key] = e
+	}
+
+	var d Diff
+	for _, e := range current.Entries {
+		prev, ok := before[e.Key]
+		if !ok {
+			d.Added = append(d.Added, e)
+			continue
+		}
+		delete(before, e.Key)
+		if prev.Size != e.Size || prev.ETag != e.ETag {
+			d.Changed = append(d.Changed, Change{Old: prev, New: e})
+		}
+	}
+	for _, e := range before {
+		d.Removed = append(d.Removed, e)
+	}
+
+	byKey := func(a, b Entry) int { return strings.Compare(a.Key, b.Key) }
+	slices.SortFunc(d.Added, byKey)
+	slices.SortFunc(d.Removed, byKey)
+	slices.SortFunc(d.Changed, func(a, b Change) int { return strings.Compare(a.New.Key, b.New.Key) })
+	return d
+}
@@ -0,0 +1,58 @@
+package inventory
+
+import (
+	"testing"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	snap := NewSnapshot("b", "logs/", []aws.S3Object{
+		{Key: "logs/b.txt", Size: 20, ETag: "bb"},
+		{Key: "logs/2024/", IsPrefix: true},
+		{Key: "logs/a.txt", Size: 10, ETag: "aa"},
+		{Key: "logs/empty/", Size: 0},
+	})
+	if snap.Objects != 2 || snap.Size != 30 {
+		t.Errorf("expected 2 objects and 30 bytes, got %d and %d", snap.Objects, snap.Size)
+	}
+	if len(snap.Entries) != 2 || snap.Entries[0].Key != "logs/a.txt" {
+		t.Errorf("expected entries sorted by key without folders, got %+v", snap.Entries)
+	}
+}
+
+func TestCompare(t *testing.T) {
+	then := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	now := then.Add(24 * time.Hour)
+	old := Snapshot{Entries: []Entry{
+		{Key: "a", Size: 1, ETag: "1", LastModified: then},
+		{Key: "b", Size: 2, ETag: "2", LastModified: then},
+		{Key: "c", Size: 3, ETag: "3", LastModified: then},
+		{Key: "d", Size: 4, ETag: "4", LastModified: then},
+	}}
+	current := Snapshot{Entries: []Entry{
+		{Key: "a", Size: 1, ETag: "1", LastModified: then},
+		{Key: "b", Size: 2, ETag: "2b", LastModified: now}, // same size, new content
+		{Key: "c", Size: 3, ETag: "3", LastModified: now},  // re-uploaded unchanged
+		{Key: "e", Size: 5, ETag: "5", LastModified: now},
+		{Key: "0", Size: 6, ETag: "6", LastModified: now},
+	}}
+
+	d := Compare(old, current)
+	if len(d.Added) != 2 || d.Added[0].Key != "0" || d.Added[1].Key != "e" {
+		t.Errorf("unexpected added: %+v", d.Added)
+	}
+	if len(d.Removed) != 1 || d.Removed[0].Key != "d" {
+		t.Errorf("unexpected removed: %+v", d.Removed)
+	}
+	if len(d.Changed) != 1 || d.Changed[0].New.Key != "b" || d.Changed[0].Old.ETag != "2" {
+		t.Errorf("unexpected changed: %+v", d.Changed)
+	}
+	if d.Empty() {
+		t.Error("expected diff not to be empty")
+	}
+	if !Compare(old, old).Empty() {
+		t.Error("expected a snapshot compared with itself to be empty")
+	}
+}
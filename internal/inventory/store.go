@@ -0,0 +1,146 @@
+package inventory
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+	"sync"
+
+	"github.com/google/uuid"
+	"github.com/natevick/stui/internal/config"
+)
+
+// Store keeps snapshots on disk: an index of them in index.json and each
+// snapshot's listing in a file of its own, since listings can be large. It
+// can be used from several goroutines, so snapshots can be saved in the
+// background.
+type Store struct {
+	mu    sync.Mutex
+	dir   string
+	index []Info
+}
+
+// NewStore creates a new snapshot store
+func NewStore() (*Store, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(configDir, "snapshots")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create snapshot directory: %w", err)
+	}
+
+	store := &Store{
+		dir:   dir,
+		index: []Info{},
+	}
+
+	// Try to load the existing index
+	if err := store.loadIndex(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+func (s *Store) indexPath() string {
+	return filepath.Join(s.dir, "index.json")
+}
+
+func (s *Store) snapshotPath(id string) string {
+	return filepath.Join(s.dir, id+".json")
+}
+
+func (s *Store) loadIndex() error {
+	data, err := os.ReadFile(s.indexPath())
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, &s.index)
+}
+
+func (s *Store) saveIndex() error {
+	data, err := json.MarshalIndent(s.index, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal snapshot index: %w", err)
+	}
+	if err := os.WriteFile(s.indexPath(), data, 0600); err != nil {
+		return fmt.Errorf("failed to write snapshot index: %w", err)
+	}
+	return nil
+}
+
+// Add saves a snapshot and returns its info
+func (s *Store) Add(snap Snapshot) (Info, error) {
+	snap.ID = uuid.New().String()
+
+	data, err := json.Marshal(snap)
+	if err != nil {
+		return Info{}, fmt.Errorf("failed to marshal snapshot: %w", err)
+	}
+	if err := os.WriteFile(s.snapshotPath(snap.ID), data, 0600); err != nil {
+		return Info{}, fmt.Errorf("failed to write snapshot: %w", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.index = append(s.index, snap.Info)
+	if err := s.saveIndex(); err != nil {
+		// Remove the snapshot if the index couldn't be saved
+		s.index = s.index[:len(s.index)-1]
+		os.Remove(s.snapshotPath(snap.ID))
+		return Info{}, err
+	}
+
+	return snap.Info, nil
+}
+
+// Load reads a saved snapshot by ID
+func (s *Store) Load(id string) (Snapshot, error) {
+	data, err := os.ReadFile(s.snapshotPath(id))
+	if err != nil {
+		return Snapshot{}, fmt.Errorf("failed to read snapshot: %w", err)
+	}
+	var snap Snapshot
+	if err := json.Unmarshal(data, &snap); err != nil {
+		return Snapshot{}, fmt.Errorf("failed to parse snapshot: %w", err)
+	}
+	return snap, nil
+}
+
+// Remove deletes a snapshot by ID
+func (s *Store) Remove(id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, info := range s.index {
+		if info.ID == id {
+			s.index = append(s.index[:i], s.index[i+1:]...)
+			if err := s.saveIndex(); err != nil {
+				return err
+			}
+			if err := os.Remove(s.snapshotPath(id)); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove snapshot: %w", err)
+			}
+			return nil
+		}
+	}
+	return fmt.Errorf("snapshot not found: %s", id)
+}
+
+// For returns the snapshots of bucket/prefix, newest first
+func (s *Store) For(bucket, prefix string) []Info {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	var infos []Info
+	for _, info := range s.index {
+		if info.Bucket == bucket && info.Prefix == prefix {
+			infos = append(infos, info)
+		}
+	}
+	slices.SortFunc(infos, func(a, b Info) int { return b.TakenAt.Compare(a.TakenAt) })
+	return infos
+}
@@ -0,0 +1,63 @@
+package inventory
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestSnapshotStore(t *testing.T) {
+	store := &Store{dir: t.TempDir(), index: []Info{}}
+
+	older := Snapshot{
+		Info:    Info{Bucket: "b", Prefix: "logs/", TakenAt: time.Now().Add(-time.Hour), Objects: 1, Size: 10},
+		Entries: []Entry{{Key: "logs/a", Size: 10, ETag: "aa"}},
+	}
+	newer := older
+	newer.TakenAt = time.Now()
+	other := older
+	other.Prefix = "data/"
+
+	var infos []Info
+	for _, snap := range []Snapshot{older, newer, other} {
+		info, err := store.Add(snap)
+		if err != nil {
+			t.Fatalf("failed to add snapshot: %v", err)
+		}
+		if info.ID == "" {
+			t.Error("expected an ID to be set")
+		}
+		infos = append(infos, info)
+	}
+
+	// Reload from disk
+	loaded := &Store{dir: store.dir}
+	if err := loaded.loadIndex(); err != nil {
+		t.Fatalf("failed to load index: %v", err)
+	}
+	found := loaded.For("b", "logs/")
+	if len(found) != 2 || found[0].ID != infos[1].ID {
+		t.Fatalf("expected 2 snapshots newest first, got %+v", found)
+	}
+
+	snap, err := loaded.Load(found[1].ID)
+	if err != nil {
+		t.Fatalf("failed to load snapshot: %v", err)
+	}
+	if len(snap.Entries) != 1 || snap.Entries[0].ETag != "aa" || snap.Prefix != "logs/" {
+		t.Errorf("unexpected snapshot after reload: %+v", snap)
+	}
+
+	if err := loaded.Remove(found[1].ID); err != nil {
+		t.Fatalf("failed to remove snapshot: %v", err)
+	}
+	if len(loaded.For("b", "logs/")) != 1 {
+		t.Error("expected one snapshot left")
+	}
+	if _, err := os.Stat(loaded.snapshotPath(found[1].ID)); !os.IsNotExist(err) {
+		t.Errorf("expected snapshot file to be removed, got %v", err)
+	}
+	if err := loaded.Remove(found[1].ID); err == nil {
+		t.Error("expected removing a missing snapshot to fail")
+	}
+}
@@ -0,0 +1,185 @@
+package itest
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	awssdk "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/config"
+	"github.com/aws/aws-sdk-go-v2/credentials"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+	"github.com/aws/aws-sdk-go-v2/service/s3/types"
+	"github.com/google/uuid"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// Environment variables that point the suite at a running S3-compatible
+// server, e.g. http://localhost:4566 for LocalStack. Without an endpoint a
+// MinIO container is started with docker, and the tests are skipped if
+// that isn't possible.
+const (
+	EnvEndpoint  = "STUI_ITEST_ENDPOINT"
+	EnvAccessKey = "STUI_ITEST_ACCESS_KEY" // default minioadmin
+	EnvSecretKey = "STUI_ITEST_SECRET_KEY" // default minioadmin
+)
+
+// MinIOImage is the image started when no endpoint is set
+const MinIOImage = "minio/minio:latest"
+
+const (
+	defaultCredential = "minioadmin"
+	region            = "us-east-1"
+	startTimeout      = 60 * time.Second
+)
+
+var (
+	once      sync.Once
+	endpoint  string
+	container string // started by us and removed by Main
+	skipWhy   string
+)
+
+// Main runs a package's tests and removes the MinIO container if one was
+// started. Call it from TestMain.
+func Main(m *testing.M) {
+	code := m.Run()
+	if container != "" {
+		exec.Command("docker", "rm", "-f", container).Run()
+	}
+	os.Exit(code)
+}
+
+// Client returns a client for the test server, starting it on first use,
+// or skips the test if there's no server to test against
+func Client(t testing.TB) *aws.Client {
+	t.Helper()
+	once.Do(setup)
+	if endpoint == "" {
+		t.Skip(skipWhy)
+	}
+
+	cfg, err := config.LoadDefaultConfig(context.Background(),
+		config.WithRegion(region),
+		config.WithCredentialsProvider(credentials.NewStaticCredentialsProvider(
+			envOr(EnvAccessKey, defaultCredential), envOr(EnvSecretKey, defaultCredential), "")),
+	)
+	if err != nil {
+		t.Fatalf("failed to load AWS config: %v", err)
+	}
+	client := s3.NewFromConfig(cfg, func(o *s3.Options) {
+		o.BaseEndpoint = awssdk.String(endpoint)
+		o.UsePathStyle = true
+	})
+	return &aws.Client{S3: client, Config: cfg, Region: region}
+}
+
+// Bucket creates an empty bucket that's emptied and deleted when the test
+// ends
+func Bucket(t testing.TB, client *aws.Client) string {
+	t.Helper()
+	bucket := "stui-itest-" + uuid.NewString()[:8]
+	ctx := context.Background()
+	if _, err := client.S3.CreateBucket(ctx, &s3.CreateBucketInput{Bucket: awssdk.String(bucket)}); err != nil {
+		t.Fatalf("failed to create bucket: %v", err)
+	}
+	t.Cleanup(func() {
+		paginator := s3.NewListObjectsV2Paginator(client.S3, &s3.ListObjectsV2Input{Bucket: awssdk.String(bucket)})
+		for paginator.HasMorePages() {
+			page, err := paginator.NextPage(ctx)
+			if err != nil {
+				t.Logf("failed to empty bucket %s: %v", bucket, err)
+				return
+			}
+			var ids []types.ObjectIdentifier
+			for _, obj := range page.Contents {
+				ids = append(ids, types.ObjectIdentifier{Key: obj.Key})
+			}
+			if len(ids) > 0 {
+				client.S3.DeleteObjects(ctx, &s3.DeleteObjectsInput{
+					Bucket: awssdk.String(bucket),
+					Delete: &types.Delete{Objects: ids, Quiet: awssdk.Bool(true)},
+				})
+			}
+		}
+		client.S3.DeleteBucket(ctx, &s3.DeleteBucketInput{Bucket: awssdk.String(bucket)})
+	})
+	return bucket
+}
+
+// Put uploads body to key in one request
+func Put(t testing.TB, client *aws.Client, bucket, key string, body []byte) {
+	t.Helper()
+	if err := client.PutObject(context.Background(), bucket, key, body, "", aws.Encryption{}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// setup finds or starts the server the suite runs against
+func setup() {
+	if endpoint = os.Getenv(EnvEndpoint); endpoint != "" {
+		return
+	}
+	if _, err := exec.LookPath("docker"); err != nil {
+		skipWhy = fmt.Sprintf("no %s set and docker isn't installed", EnvEndpoint)
+		return
+	}
+
+	out, err := exec.Command("docker", "run", "-d", "--rm", "-p", "127.0.0.1::9000",
+		MinIOImage, "server", "/data").Output()
+	if err != nil {
+		skipWhy = fmt.Sprintf("failed to start MinIO: %v", err)
+		return
+	}
+	container = strings.TrimSpace(string(out))
+
+	out, err = exec.Command("docker", "port", container, "9000/tcp").Output()
+	if err != nil {
+		skipWhy = fmt.Sprintf("failed to find MinIO's port: %v", err)
+		return
+	}
+	// One line per address family; the first is the one we asked for
+	addr, _, _ := strings.Cut(strings.TrimSpace(string(out)), "\n")
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		skipWhy = fmt.Sprintf("unexpected port mapping %q", addr)
+		return
+	}
+
+	url := "http://" + addr
+	if err := waitReady(url); err != nil {
+		skipWhy = err.Error()
+		return
+	}
+	endpoint = url
+}
+
+// waitReady polls MinIO's health check until it answers
+func waitReady(url string) error {
+	deadline := time.Now().Add(startTimeout)
+	for time.Now().Before(deadline) {
+		resp, err := http.Get(url + "/minio/health/ready")
+		if err == nil {
+			resp.Body.Close()
+			if resp.StatusCode == http.StatusOK {
+				return nil
+			}
+		}
+		time.Sleep(500 * time.Millisecond)
+	}
+	return fmt.Errorf("MinIO wasn't ready after %s", startTimeout)
+}
+
+func envOr(key, fallback string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return fallback
+}
@@ -0,0 +1,332 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/security"
+)
+
+// Job kinds
+const (
+	KindSync   = "sync"    // S3 prefix to a local directory
+	KindSyncUp = "sync-up" // local directory to an S3 prefix
+	KindCopy   = "copy"    // S3 prefix to another S3 prefix, copied inside S3
+)
+
+// Overwrite policies decide which files that exist on both sides are sent
+const (
+	OverwriteChanged = "changed" // new and changed files (the default)
+	OverwriteAlways  = "always"  // every file, even unchanged ones
+	OverwriteNever   = "never"   // only files missing on the other side
+)
+
+// DefaultConcurrency is how many files a job without its own concurrency
+// transfers at once
+const DefaultConcurrency = 5
+
+// ErrNothingToDo is returned by Run when every file is already in place
+var ErrNothingToDo = errors.New("nothing to transfer")
+
+// Job is a saved, fully configured transfer that can be run again from the
+// TUI or with `stui run-template <name>`
+type Job struct {
+	ID           string    `json:"id"`
+	Name         string    `json:"name"`
+	Kind         string    `json:"kind"`
+	Profile      string    `json:"profile,omitempty"` // AWS profile; "" uses the one stui runs with
+	Bucket       string    `json:"bucket"`
+	Prefix       string    `json:"prefix,omitempty"`
+	LocalDir     string    `json:"local_dir,omitempty"`
+	DestBucket   string    `json:"dest_bucket,omitempty"`   // for copy jobs
+	DestPrefix   string    `json:"dest_prefix,omitempty"`   // for copy jobs
+	Include      []string  `json:"include,omitempty"`       // globs a file must match, e.g. "*.parquet"
+	Exclude      []string  `json:"exclude,omitempty"`       // globs that skip a file
+	Overwrite    string    `json:"overwrite,omitempty"`     // changed, always or never
+	Concurrency  int       `json:"concurrency,omitempty"`   // files in flight; 0 uses the default
+	StorageClass string    `json:"storage_class,omitempty"` // storage class for uploads; "" uses the bucket default
+	Encryption   string    `json:"encryption,omitempty"`    // encryption for uploads, as in the profile settings
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// Validate checks that the job can run
+func (j Job) Validate() error {
+	if err := security.ValidBookmarkName(j.Name); err != nil {
+		return err
+	}
+	switch j.Kind {
+	case KindSync, KindSyncUp, KindCopy:
+	default:
+		return fmt.Errorf("job %q: unknown kind %q (use sync, sync-up or copy)", j.Name, j.Kind)
+	}
+	if j.Bucket == "" {
+		return fmt.Errorf("job %q: no bucket", j.Name)
+	}
+	if err := security.ValidBucketName(j.Bucket); err != nil {
+		return err
+	}
+	if err := security.ValidProfileName(j.Profile); err != nil {
+		return err
+	}
+	if j.Kind == KindCopy {
+		if j.DestBucket == "" {
+			return fmt.Errorf("job %q: no destination bucket", j.Name)
+		}
+		if err := security.ValidBucketName(j.DestBucket); err != nil {
+			return err
+		}
+		// A destination under the source would be listed with it, so each
+		// run would copy the copies again
+		if j.DestBucket == j.Bucket && strings.HasPrefix(j.DestPrefix, j.Prefix) {
+			if j.DestPrefix == j.Prefix {
+				return fmt.Errorf("job %q: copies %s onto itself", j.Name, j.Remote())
+			}
+			return fmt.Errorf("job %q: copies %s into itself, at %s", j.Name, j.Remote(), j.Destination())
+		}
+	} else if j.LocalDir == "" {
+		return fmt.Errorf("job %q: no local directory", j.Name)
+	}
+	switch j.Overwrite {
+	case "", OverwriteChanged, OverwriteAlways, OverwriteNever:
+	default:
+		return fmt.Errorf("job %q: unknown overwrite policy %q (use changed, always or never)", j.Name, j.Overwrite)
+	}
+	if _, err := aws.ParseEncryption(j.Encryption); err != nil {
+		return fmt.Errorf("job %q: %w", j.Name, err)
+	}
+	if j.Concurrency < 0 {
+		return fmt.Errorf("job %q: concurrency can't be negative", j.Name)
+	}
+	for _, p := range append(append([]string{}, j.Include...), j.Exclude...) {
+		if _, err := path.Match(p, ""); err != nil {
+			return fmt.Errorf("job %q: bad pattern %q", j.Name, p)
+		}
+	}
+	return nil
+}
+
+// Remote returns the job's S3 location
+func (j Job) Remote() string {
+	return fmt.Sprintf("s3://%s/%s", j.Bucket, j.Prefix)
+}
+
+// Destination returns a copy job's destination
+func (j Job) Destination() string {
+	return fmt.Sprintf("s3://%s/%s", j.DestBucket, j.DestPrefix)
+}
+
+// Describe summarizes what the job does, e.g. "sync s3://b/p/ → /data"
+func (j Job) Describe() string {
+	switch j.Kind {
+	case KindSyncUp:
+		return fmt.Sprintf("sync %s → %s", j.LocalDir, j.Remote())
+	case KindCopy:
+		return fmt.Sprintf("copy %s → %s", j.Remote(), j.Destination())
+	}
+	return fmt.Sprintf("sync %s → %s", j.Remote(), j.LocalDir)
+}
+
+// IsSync reports whether the job syncs a local directory, in either
+// direction, rather than copying inside S3
+func (j Job) IsSync() bool {
+	return j.Kind == KindSync || j.Kind == KindSyncUp
+}
+
+// Direction returns the direction the job transfers in
+func (j Job) Direction() download.Direction {
+	switch j.Kind {
+	case KindSyncUp:
+		return download.DirectionUpload
+	case KindCopy:
+		return download.DirectionCopy
+	}
+	return download.DirectionDownload
+}
+
+// Workers returns how many files the job transfers at once
+func (j Job) Workers() int {
+	if j.Concurrency > 0 {
+		return j.Concurrency
+	}
+	return DefaultConcurrency
+}
+
+// ParsePatterns parses comma-separated globs for Include or Exclude, e.g.
+// "*.parquet, tmp/*"
+func ParsePatterns(s string) ([]string, error) {
+	var patterns []string
+	for _, p := range strings.Split(s, ",") {
+		p = strings.TrimSpace(p)
+		if p == "" {
+			continue
+		}
+		if _, err := path.Match(p, ""); err != nil {
+			return nil, fmt.Errorf("bad pattern %q", p)
+		}
+		patterns = append(patterns, p)
+	}
+	return patterns, nil
+}
+
+// Matches reports whether a file at rel, relative to the job's prefix or
+// directory, passes the include and exclude globs. Globs without a "/"
+// match the file's base name, others the whole relative path.
+func (j Job) Matches(rel string) bool {
+	match := func(patterns []string) bool {
+		for _, p := range patterns {
+			name := rel
+			if !strings.Contains(p, "/") {
+				name = path.Base(rel)
+			}
+			if ok, _ := path.Match(p, name); ok {
+				return true
+			}
+		}
+		return false
+	}
+	if len(j.Include) > 0 && !match(j.Include) {
+		return false
+	}
+	return !match(j.Exclude)
+}
+
+// Run compares both sides and transfers the files the job's filters and
+// overwrite policy select. Uploads get attrs, with the job's own storage
+// class and encryption in place of attrs' if it has them. It returns
+// ErrNothingToDo if there's nothing to send. Keys written to S3 are held to
+// settings' naming policies, as they would be interactively.
+func (j Job) Run(ctx context.Context, syncMgr *download.SyncManager, mgr *download.Manager, attrs aws.ObjectAttributes, settings *config.Config) error {
+	mgr.SetWorkers(j.Workers())
+	if j.Kind == KindCopy {
+		return j.runCopy(ctx, mgr, settings)
+	}
+	if j.Kind == KindSyncUp {
+		if j.StorageClass != "" {
+			attrs.StorageClass = j.StorageClass
+		}
+		if j.Encryption != "" {
+			enc, err := aws.ParseEncryption(j.Encryption)
+			if err != nil {
+				return err
+			}
+			attrs.Encryption = enc
+		}
+		return j.runUp(ctx, syncMgr, mgr, attrs, settings)
+	}
+	return j.runDown(ctx, syncMgr, mgr)
+}
+
+func (j Job) runDown(ctx context.Context, syncMgr *download.SyncManager, mgr *download.Manager) error {
+	result, err := syncMgr.CompareFiles(ctx, j.Bucket, j.Prefix, j.LocalDir)
+	if err != nil {
+		return err
+	}
+	candidates := result.ToDownload
+	if j.Overwrite == OverwriteAlways {
+		candidates = append(candidates, result.Unchanged...)
+	}
+
+	var objects []aws.S3Object
+	for _, obj := range candidates {
+		rel := strings.TrimPrefix(obj.Key, j.Prefix)
+		if obj.IsPrefix || strings.HasSuffix(rel, "/") || !j.Matches(rel) {
+			continue
+		}
+		// The sync matched the object with any local copy, whatever form
+		// its name is in
+		if _, exists := result.LocalPath(obj.Key); exists && j.Overwrite == OverwriteNever {
+			continue
+		}
+		objects = append(objects, obj)
+	}
+	if len(objects) == 0 {
+		return ErrNothingToDo
+	}
+	result.ToDownload = objects
+	return syncMgr.Apply(ctx, j.Bucket, j.Prefix, j.LocalDir, result, mgr)
+}
+
+func (j Job) runUp(ctx context.Context, syncMgr *download.SyncManager, mgr *download.Manager, attrs aws.ObjectAttributes, settings *config.Config) error {
+	result, err := syncMgr.CompareLocal(ctx, j.LocalDir, j.Bucket, j.Prefix)
+	if err != nil {
+		return err
+	}
+	candidates := result.ToUpload
+	if j.Overwrite == OverwriteAlways {
+		candidates = append(candidates, result.Unchanged...)
+	}
+
+	var files []download.LocalFile
+	for _, f := range candidates {
+		if !j.Matches(strings.TrimPrefix(f.Key, j.Prefix)) {
+			continue
+		}
+		if j.Overwrite == OverwriteNever && f.Exists {
+			continue
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return ErrNothingToDo
+	}
+
+	// Blocking naming policies stop the job; the rest are noted in its
+	// summary
+	keys := make([]string, len(files))
+	for i, f := range files {
+		keys[i] = f.Key
+	}
+	naming, err := settings.CheckKeys(j.Bucket, keys)
+	if err != nil {
+		return err
+	}
+	var notes []string
+	for _, note := range []string{result.Symlinks.String(), naming} {
+		if note != "" {
+			notes = append(notes, note)
+		}
+	}
+	return mgr.UploadMultiple(ctx, j.Bucket, files, attrs, strings.Join(notes, "; "))
+}
+
+// runCopy copies the prefix to the destination inside S3, so nothing
+// passes through this machine. An object counts as changed if its size
+// differs from the copy's or it was modified after the copy was made.
+func (j Job) runCopy(ctx context.Context, mgr *download.Manager, settings *config.Config) error {
+	candidates, existing, err := mgr.ListPrefixCopy(ctx, j.Bucket, j.Prefix, j.DestBucket, j.DestPrefix)
+	if err != nil {
+		return err
+	}
+
+	var files []download.CopyFile
+	for _, f := range candidates {
+		if !j.Matches(strings.TrimPrefix(f.Source.Key, j.Prefix)) {
+			continue
+		}
+		if dst, ok := existing[f.Key]; ok {
+			changed := dst.Size != f.Source.Size || f.Source.LastModified.After(dst.LastModified)
+			if j.Overwrite == OverwriteNever || (!changed && j.Overwrite != OverwriteAlways) {
+				continue
+			}
+		}
+		files = append(files, f)
+	}
+	if len(files) == 0 {
+		return ErrNothingToDo
+	}
+	keys := make([]string, len(files))
+	for i, f := range files {
+		keys[i] = f.Key
+	}
+	if _, err := settings.CheckKeys(j.DestBucket, keys); err != nil {
+		return err
+	}
+	return mgr.CopyObjects(ctx, j.Bucket, files, j.DestBucket)
+}
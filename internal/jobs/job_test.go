@@ -0,0 +1,68 @@
+package jobs
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/download"
+)
+
+// emptyBucket serves an empty listing for every bucket and counts the
+// writes it's sent
+func emptyBucket(t *testing.T) (*aws.Client, *atomic.Int32) {
+	var writes atomic.Int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			writes.Add(1)
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>bucket</Name><IsTruncated>false</IsTruncated></ListBucketResult>`))
+	}))
+	t.Cleanup(server.Close)
+
+	return &aws.Client{S3: s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: sdkaws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  sdkaws.AnonymousCredentials{},
+	}), Region: "us-east-1"}, &writes
+}
+
+func TestRunHoldsToNamingPolicies(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.json")
+	data := `{"naming_policies": [{"bucket": "*", "pattern": "^[a-z/.]+$", "mode": "block"}]}`
+	if err := os.WriteFile(path, []byte(data), 0600); err != nil {
+		t.Fatal(err)
+	}
+	settings, err := config.LoadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "Upper.txt"), []byte("x"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	client, writes := emptyBucket(t)
+	job := Job{Name: "up", Kind: KindSyncUp, Bucket: "bucket", Prefix: "data/", LocalDir: dir}
+
+	err = job.Run(context.Background(), download.NewSyncManager(client), download.NewManager(client, 1), aws.ObjectAttributes{}, settings)
+	if err == nil {
+		t.Error("Run() uploaded a key a blocking naming policy refuses")
+	}
+	if n := writes.Load(); n != 0 {
+		t.Errorf("Run() sent %d writes", n)
+	}
+}
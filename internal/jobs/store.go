@@ -0,0 +1,110 @@
+package jobs
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/natevick/stui/internal/config"
+)
+
+// Store manages saved job persistence
+type Store struct {
+	path string
+	jobs []Job
+}
+
+// NewStore creates a new saved job store
+func NewStore() (*Store, error) {
+	configDir, err := config.Dir()
+	if err != nil {
+		return nil, err
+	}
+
+	store := &Store{
+		path: filepath.Join(configDir, "jobs.json"),
+		jobs: []Job{},
+	}
+
+	// Try to load existing jobs
+	if err := store.Load(); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+
+	return store, nil
+}
+
+// Load reads saved jobs from disk
+func (s *Store) Load() error {
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(data, &s.jobs)
+}
+
+// Save writes saved jobs to disk
+func (s *Store) Save() error {
+	data, err := json.MarshalIndent(s.jobs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal jobs: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("failed to write jobs: %w", err)
+	}
+
+	return nil
+}
+
+// Add saves a new job under job.Name, which must not be taken
+func (s *Store) Add(job Job) (Job, error) {
+	if err := job.Validate(); err != nil {
+		return Job{}, err
+	}
+	if _, ok := s.Find(job.Name); ok {
+		return Job{}, fmt.Errorf("a saved job named %q already exists", job.Name)
+	}
+
+	job.ID = uuid.New().String()
+	job.CreatedAt = time.Now()
+	s.jobs = append(s.jobs, job)
+
+	if err := s.Save(); err != nil {
+		// Remove the job if save failed
+		s.jobs = s.jobs[:len(s.jobs)-1]
+		return Job{}, err
+	}
+
+	return job, nil
+}
+
+// Remove deletes a saved job by ID
+func (s *Store) Remove(id string) error {
+	for i, j := range s.jobs {
+		if j.ID == id {
+			s.jobs = append(s.jobs[:i], s.jobs[i+1:]...)
+			return s.Save()
+		}
+	}
+	return fmt.Errorf("saved job not found: %s", id)
+}
+
+// List returns all saved jobs
+func (s *Store) List() []Job {
+	return s.jobs
+}
+
+// Find returns a saved job by name
+func (s *Store) Find(name string) (Job, bool) {
+	for _, j := range s.jobs {
+		if j.Name == name {
+			return j, true
+		}
+	}
+	return Job{}, false
+}
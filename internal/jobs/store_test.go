@@ -0,0 +1,171 @@
+package jobs
+
+import (
+	"os"
+	"path/filepath"
+	"slices"
+	"testing"
+)
+
+func TestJobStore(t *testing.T) {
+	tmpDir := t.TempDir()
+
+	store := &Store{
+		path: filepath.Join(tmpDir, "jobs.json"),
+		jobs: []Job{},
+	}
+
+	job, err := store.Add(Job{
+		Name:     "nightly-models",
+		Kind:     KindSync,
+		Bucket:   "my-bucket",
+		Prefix:   "models/",
+		LocalDir: "/data/models",
+		Include:  []string{"*.onnx"},
+	})
+	if err != nil {
+		t.Fatalf("failed to add job: %v", err)
+	}
+	if job.ID == "" || job.CreatedAt.IsZero() {
+		t.Errorf("expected ID and creation time to be set, got %+v", job)
+	}
+
+	if _, err := store.Add(Job{Name: "nightly-models", Kind: KindSync, Bucket: "other", LocalDir: "/tmp"}); err == nil {
+		t.Error("expected duplicate name to be rejected")
+	}
+
+	// Reload from disk
+	loaded := &Store{path: store.path}
+	if err := loaded.Load(); err != nil {
+		t.Fatalf("failed to load jobs: %v", err)
+	}
+	found, ok := loaded.Find("nightly-models")
+	if !ok {
+		t.Fatal("saved job not found after reload")
+	}
+	if found.Prefix != "models/" || len(found.Include) != 1 {
+		t.Errorf("unexpected job after reload: %+v", found)
+	}
+
+	if err := loaded.Remove(found.ID); err != nil {
+		t.Fatalf("failed to remove job: %v", err)
+	}
+	if len(loaded.List()) != 0 {
+		t.Errorf("expected no jobs, got %d", len(loaded.List()))
+	}
+	if err := loaded.Remove(found.ID); err == nil {
+		t.Error("expected removing a missing job to fail")
+	}
+
+	if info, err := os.Stat(store.path); err != nil {
+		t.Fatalf("failed to stat jobs file: %v", err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("expected mode 0600, got %v", info.Mode().Perm())
+	}
+}
+
+func TestJobValidate(t *testing.T) {
+	valid := Job{Name: "backup", Kind: KindSyncUp, Bucket: "my-bucket", LocalDir: "/srv"}
+
+	tests := []struct {
+		name    string
+		modify  func(*Job)
+		wantErr bool
+	}{
+		{"valid", func(j *Job) {}, false},
+		{"unknown kind", func(j *Job) { j.Kind = "mirror" }, true},
+		{"no bucket", func(j *Job) { j.Bucket = "" }, true},
+		{"no local dir", func(j *Job) { j.LocalDir = "" }, true},
+		{"bad overwrite", func(j *Job) { j.Overwrite = "sometimes" }, true},
+		{"overwrite never", func(j *Job) { j.Overwrite = OverwriteNever }, false},
+		{"negative concurrency", func(j *Job) { j.Concurrency = -1 }, true},
+		{"bad glob", func(j *Job) { j.Exclude = []string{"[a-"} }, true},
+		{"copy", func(j *Job) { j.Kind, j.LocalDir, j.DestBucket = KindCopy, "", "replica" }, false},
+		{"copy without destination", func(j *Job) { j.Kind, j.LocalDir = KindCopy, "" }, true},
+		{"copy onto itself", func(j *Job) { j.Kind, j.DestBucket = KindCopy, j.Bucket }, true},
+		{"copy into itself", copyWithin("logs/", "logs/backup/"), true},
+		{"copy into a sibling", copyWithin("logs/", "backup/logs/"), false},
+		{"copy out of a subfolder", copyWithin("logs/old/", "logs/"), false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			job := valid
+			tt.modify(&job)
+			if err := job.Validate(); (err != nil) != tt.wantErr {
+				t.Errorf("Validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// copyWithin makes a job copy from one prefix to another in its own bucket
+func copyWithin(from, to string) func(*Job) {
+	return func(j *Job) {
+		j.Kind, j.LocalDir, j.DestBucket = KindCopy, "", j.Bucket
+		j.Prefix, j.DestPrefix = from, to
+	}
+}
+
+func TestJobMatches(t *testing.T) {
+	job := Job{
+		Include: []string{"*.parquet", "manifests/*"},
+		Exclude: []string{"_tmp*", "staging/*"},
+	}
+
+	tests := []struct {
+		rel  string
+		want bool
+	}{
+		{"part-0001.parquet", true},
+		{"2024/01/part-0001.parquet", true},
+		{"manifests/latest.json", true},
+		{"2024/manifests/latest.json", false},
+		{"notes.txt", false},
+		{"_tmp-part.parquet", false},
+		{"staging/part.parquet", false},
+	}
+
+	for _, tt := range tests {
+		if got := job.Matches(tt.rel); got != tt.want {
+			t.Errorf("Matches(%q) = %v, want %v", tt.rel, got, tt.want)
+		}
+	}
+
+	if !(Job{}).Matches("anything/at/all") {
+		t.Error("expected a job without filters to match everything")
+	}
+}
+
+func TestParsePatterns(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    []string
+		wantErr bool
+	}{
+		{"", nil, false},
+		{" , ", nil, false},
+		{"*.parquet", []string{"*.parquet"}, false},
+		{"*.parquet, tmp/* ,_SUCCESS", []string{"*.parquet", "tmp/*", "_SUCCESS"}, false},
+		{"*.csv, [", nil, true},
+	}
+	for _, tt := range tests {
+		got, err := ParsePatterns(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("ParsePatterns(%q) error = %v, want error %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if !slices.Equal(got, tt.want) {
+			t.Errorf("ParsePatterns(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestJobWorkers(t *testing.T) {
+	if got := (Job{}).Workers(); got != DefaultConcurrency {
+		t.Errorf("Workers() without a concurrency = %d, want %d", got, DefaultConcurrency)
+	}
+	if got := (Job{Concurrency: 12}).Workers(); got != 12 {
+		t.Errorf("Workers() = %d, want 12", got)
+	}
+}
@@ -0,0 +1,101 @@
+package keymap
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+)
+
+// Registry collects the scopes of key bindings that are live at the same
+// time, e.g. the global keys and the object browser's, so keys claimed by
+// more than one of them can be found before a user presses one
+type Registry struct {
+	scopes []scope
+}
+
+type scope struct {
+	name     string
+	bindings []key.Binding
+}
+
+// Conflict is a key claimed by more than one live binding
+type Conflict struct {
+	Key      string
+	Bindings []string // "scope: help text" of each binding claiming it
+}
+
+func (c Conflict) String() string {
+	return fmt.Sprintf("%q is bound to %s", c.Key, strings.Join(c.Bindings, " and "))
+}
+
+// Add registers bindings under a scope name
+func (r *Registry) Add(name string, bindings ...key.Binding) {
+	r.scopes = append(r.scopes, scope{name: name, bindings: bindings})
+}
+
+// Conflicts returns the keys claimed more than once, in key order.
+// Disabled bindings don't count, and a binding listing a key twice doesn't
+// conflict with itself.
+func (r *Registry) Conflicts() []Conflict {
+	claims := make(map[string][]string)
+	for _, s := range r.scopes {
+		for _, b := range s.bindings {
+			if !b.Enabled() {
+				continue
+			}
+			name := s.name + ": " + describe(b)
+			for _, k := range b.Keys() {
+				if !slices.Contains(claims[k], name) {
+					claims[k] = append(claims[k], name)
+				}
+			}
+		}
+	}
+
+	var conflicts []Conflict
+	for k, names := range claims {
+		if len(names) > 1 {
+			conflicts = append(conflicts, Conflict{Key: k, Bindings: names})
+		}
+	}
+	slices.SortFunc(conflicts, func(a, b Conflict) int { return strings.Compare(a.Key, b.Key) })
+	return conflicts
+}
+
+// Claimed returns every key the live bindings registered so far claim
+func (r *Registry) Claimed() []string {
+	var keys []string
+	for _, s := range r.scopes {
+		for _, b := range s.bindings {
+			if b.Enabled() {
+				keys = append(keys, b.Keys()...)
+			}
+		}
+	}
+	slices.Sort(keys)
+	return slices.Compact(keys)
+}
+
+// Without returns b without keys, keeping its help, so a binding from a
+// scope that yields, like a list's paging keys, stops shadowing or being
+// shadowed by the scope that owns them
+func Without(b key.Binding, keys ...string) key.Binding {
+	var kept []string
+	for _, k := range b.Keys() {
+		if !slices.Contains(keys, k) {
+			kept = append(kept, k)
+		}
+	}
+	b.SetKeys(kept...)
+	return b
+}
+
+// describe names a binding by its help text, or its keys without any
+func describe(b key.Binding) string {
+	if desc := b.Help().Desc; desc != "" {
+		return desc
+	}
+	return strings.Join(b.Keys(), "/")
+}
@@ -0,0 +1,61 @@
+package keymap_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+
+	"github.com/natevick/stui/internal/keymap"
+	"github.com/natevick/stui/internal/tui"
+)
+
+func binding(desc string, keys ...string) key.Binding {
+	return key.NewBinding(key.WithKeys(keys...), key.WithHelp(keys[0], desc))
+}
+
+func TestConflicts(t *testing.T) {
+	disabled := binding("hidden", "d")
+	disabled.SetEnabled(false)
+
+	var r keymap.Registry
+	r.Add("global", binding("quit", "q", "ctrl+c"), binding("refresh", "r"))
+	r.Add("browser", binding("download", "d"), binding("sort", "s"), binding("up", "k", "k"), disabled)
+	r.Add("actions", binding("sync", "s"), binding("reload", "r"), binding("delete", "x"))
+
+	got := r.Conflicts()
+	want := []keymap.Conflict{
+		{Key: "r", Bindings: []string{"global: refresh", "actions: reload"}},
+		{Key: "s", Bindings: []string{"browser: sort", "actions: sync"}},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Conflicts() = %v, want %v", got, want)
+	}
+	if s := got[1].String(); s != `"s" is bound to browser: sort and actions: sync` {
+		t.Errorf("String() = %q", s)
+	}
+
+	if claimed := r.Claimed(); !reflect.DeepEqual(claimed, []string{"ctrl+c", "d", "k", "q", "r", "s", "x"}) {
+		t.Errorf("Claimed() = %v", claimed)
+	}
+}
+
+func TestWithout(t *testing.T) {
+	next := keymap.Without(list.DefaultKeyMap().NextPage, "d", "f")
+	if got := next.Keys(); !reflect.DeepEqual(got, []string{"right", "l", "pgdown"}) {
+		t.Errorf("Keys() = %v", got)
+	}
+	if next.Help() != list.DefaultKeyMap().NextPage.Help() {
+		t.Error("Without() lost the binding's help")
+	}
+	if gone := keymap.Without(binding("download", "d"), "d"); gone.Enabled() {
+		t.Error("a binding without keys is still enabled")
+	}
+}
+
+func TestDefaultKeysDontConflict(t *testing.T) {
+	for _, c := range tui.DefaultKeyMap().Conflicts() {
+		t.Errorf("default keys conflict: %s", c)
+	}
+}
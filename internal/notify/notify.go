@@ -0,0 +1,46 @@
+package notify
+
+import (
+	"fmt"
+	"os/exec"
+	"runtime"
+	"strconv"
+)
+
+// Send shows a desktop notification with osascript on macOS or notify-send
+// elsewhere. It returns an error if neither is available.
+func Send(title, message string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		script := fmt.Sprintf("display notification %s with title %s", strconv.Quote(message), strconv.Quote(title))
+		cmd = exec.Command("osascript", "-e", script)
+	case "windows":
+		return fmt.Errorf("desktop notifications aren't supported on %s", runtime.GOOS)
+	default:
+		cmd = exec.Command("notify-send", "--app-name=stui", title, message)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to send notification: %w", err)
+	}
+	return nil
+}
+
+// OpenURL opens url in the default browser with open on macOS,
+// FileProtocolHandler on Windows or xdg-open elsewhere. It returns an error
+// if none of them is available, e.g. over SSH.
+func OpenURL(url string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", url)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", url)
+	default:
+		cmd = exec.Command("xdg-open", url)
+	}
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to open a browser: %w", err)
+	}
+	return nil
+}
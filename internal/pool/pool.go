@@ -0,0 +1,52 @@
+package pool
+
+import (
+	"context"
+	"sync"
+)
+
+// Run starts n workers that call work with each job feed sends, numbering
+// the workers from 0, and waits for them to finish. send blocks until a
+// worker takes the job and returns false once ctx is done, when feed should
+// stop; jobs already taken are left to work, which sees the cancelled ctx.
+// Run returns ctx's error, so a cancel after the last job was sent is
+// still reported.
+func Run[T any](ctx context.Context, n int, feed func(send func(T) bool), work func(worker int, job T)) error {
+	jobs := make(chan T)
+	var wg sync.WaitGroup
+	for i := range max(n, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for job := range jobs {
+				work(i, job)
+			}
+		}()
+	}
+
+	feed(func(job T) bool {
+		if ctx.Err() != nil {
+			return false
+		}
+		select {
+		case jobs <- job:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	})
+	close(jobs)
+	wg.Wait()
+	return ctx.Err()
+}
+
+// Each runs work on each of jobs with n workers, stopping when ctx is done
+func Each[T any](ctx context.Context, n int, jobs []T, work func(worker int, job T)) error {
+	return Run(ctx, n, func(send func(T) bool) {
+		for _, job := range jobs {
+			if !send(job) {
+				return
+			}
+		}
+	}, work)
+}
@@ -0,0 +1,78 @@
+package pool
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+func TestEach(t *testing.T) {
+	jobs := make([]int, 100)
+	for i := range jobs {
+		jobs[i] = i
+	}
+
+	var mu sync.Mutex
+	done := make(map[int]bool)
+	var running, most atomic.Int32
+	err := Each(context.Background(), 4, jobs, func(worker int, job int) {
+		if worker < 0 || worker >= 4 {
+			t.Errorf("job %d ran on worker %d", job, worker)
+		}
+		n := running.Add(1)
+		defer running.Add(-1)
+		for {
+			m := most.Load()
+			if n <= m || most.CompareAndSwap(m, n) {
+				break
+			}
+		}
+		mu.Lock()
+		done[job] = true
+		mu.Unlock()
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(done) != len(jobs) {
+		t.Errorf("ran %d of %d jobs", len(done), len(jobs))
+	}
+	if most.Load() > 4 {
+		t.Errorf("%d jobs ran at once on 4 workers", most.Load())
+	}
+}
+
+func TestRunStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var ran atomic.Int32
+	sent := 0
+	err := Run(ctx, 2, func(send func(int) bool) {
+		for i := range 100 {
+			if !send(i) {
+				return
+			}
+			sent++
+		}
+	}, func(_ int, job int) {
+		if ran.Add(1) == 10 {
+			cancel()
+		}
+	})
+	if err != context.Canceled {
+		t.Errorf("Run() after a cancel = %v, want context.Canceled", err)
+	}
+	// Only jobs already taken by the workers run after the cancel
+	if sent > 12 || ran.Load() != int32(sent) {
+		t.Errorf("sent %d jobs and ran %d after cancelling at the 10th", sent, ran.Load())
+	}
+}
+
+func TestRunNoWorkers(t *testing.T) {
+	var ran int
+	if err := Each(context.Background(), 0, []int{1, 2, 3}, func(int, int) { ran++ }); err != nil || ran != 3 {
+		t.Errorf("Each() with no workers = %v, ran %d jobs", err, ran)
+	}
+}
@@ -4,7 +4,10 @@ import (
 	"fmt"
 	"path/filepath"
 	"regexp"
+	"runtime"
 	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 // Input validation constants
@@ -12,6 +15,11 @@ const (
 	MaxBookmarkNameLen = 255
 	MaxProfileNameLen  = 128
 	MaxBucketNameLen   = 63
+	MaxObjectKeyLen    = 1024
+	MaxMetadataSize    = 2048 // S3 limit on user-defined metadata
+	MaxObjectTags      = 10   // S3 limit on tags per object
+	MaxTagKeyLen       = 128
+	MaxTagValueLen     = 256
 	MaxPathLen         = 4096
 )
 
@@ -60,9 +68,194 @@ func ValidBucketName(name string) error {
 	return nil
 }
 
+// ValidObjectKey validates an S3 object key
+func ValidObjectKey(key string) error {
+	if key == "" {
+		return fmt.Errorf("object key cannot be empty")
+	}
+	if len(key) > MaxObjectKeyLen {
+		return fmt.Errorf("object key too long (max %d bytes)", MaxObjectKeyLen)
+	}
+	if !utf8.ValidString(key) {
+		return fmt.Errorf("object key must be valid UTF-8")
+	}
+	for _, r := range key {
+		if unicode.IsControl(r) {
+			return fmt.Errorf("object key contains control characters")
+		}
+	}
+	return nil
+}
+
+// ValidFolderName validates a folder name typed relative to the current
+// prefix. It may contain "/" to create nested folders, but every segment
+// must be non-empty and not "." or "..", which other tools treat as paths.
+func ValidFolderName(name string) error {
+	name = strings.TrimSuffix(name, "/")
+	if name == "" {
+		return fmt.Errorf("folder name cannot be empty")
+	}
+	for _, segment := range strings.Split(name, "/") {
+		switch segment {
+		case "":
+			return fmt.Errorf("folder name cannot contain empty segments")
+		case ".", "..":
+			return fmt.Errorf("folder name cannot contain %q", segment)
+		}
+	}
+	return ValidObjectKey(name + "/")
+}
+
+// metadataName matches the metadata names ValidMetadata accepts
+var metadataName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// ValidMetadata validates user-defined object metadata. Names become
+// x-amz-meta-* headers, so both names and values must be header-safe.
+func ValidMetadata(meta map[string]string) error {
+	size := 0
+	for name, value := range meta {
+		if !metadataName.MatchString(name) {
+			return fmt.Errorf("invalid metadata name %q", name)
+		}
+		for _, r := range value {
+			if r > unicode.MaxASCII || unicode.IsControl(r) {
+				return fmt.Errorf("metadata %q must be printable ASCII", name)
+			}
+		}
+		size += len(name) + len(value)
+	}
+	if size > MaxMetadataSize {
+		return fmt.Errorf("metadata too large (max %d bytes)", MaxMetadataSize)
+	}
+	return nil
+}
+
+// tagChars are the characters S3 allows in tag keys and values
+var tagChars = regexp.MustCompile(`^[\p{L}\p{Z}\p{N}_.:/=+\-@]*$`)
+
+// ValidTags validates object tags against S3's limits. Keys starting with
+// "aws:" are reserved for AWS.
+func ValidTags(tags map[string]string) error {
+	if len(tags) > MaxObjectTags {
+		return fmt.Errorf("too many tags (max %d)", MaxObjectTags)
+	}
+	for key, value := range tags {
+		switch {
+		case key == "":
+			return fmt.Errorf("tag key cannot be empty")
+		case utf8.RuneCountInString(key) > MaxTagKeyLen:
+			return fmt.Errorf("tag key %q too long (max %d characters)", key, MaxTagKeyLen)
+		case utf8.RuneCountInString(value) > MaxTagValueLen:
+			return fmt.Errorf("tag %q value too long (max %d characters)", key, MaxTagValueLen)
+		case strings.HasPrefix(strings.ToLower(key), "aws:"):
+			return fmt.Errorf("tag key %q uses the reserved aws: prefix", key)
+		case !tagChars.MatchString(key):
+			return fmt.Errorf("invalid tag key %q", key)
+		case !tagChars.MatchString(value):
+			return fmt.Errorf("invalid value for tag %q", key)
+		}
+	}
+	return nil
+}
+
+// PathPolicy decides how key characters that aren't valid in local file
+// names are handled
+type PathPolicy int
+
+const (
+	PathEscape PathPolicy = iota // replace invalid characters with %XX escapes
+	PathStrict                   // refuse keys containing invalid characters
+)
+
+// ParsePathPolicy parses a path policy name from config
+func ParsePathPolicy(s string) (PathPolicy, error) {
+	switch s {
+	case "", "escape":
+		return PathEscape, nil
+	case "strict":
+		return PathStrict, nil
+	default:
+		return PathEscape, fmt.Errorf("unknown path policy %q (use escape or strict)", s)
+	}
+}
+
+// windowsReserved are device names Windows won't use as file names, with or
+// without an extension
+var windowsReserved = regexp.MustCompile(`(?i)^(con|prn|aux|nul|com[1-9]|lpt[1-9])(\..*)?$`)
+
+// LocalRelPath converts a slash-separated key path into one whose components
+// are valid file names on this OS. Under PathStrict a component with
+// characters that aren't valid is an error instead, and names are kept as
+// they are.
+func LocalRelPath(rel string, policy PathPolicy) (string, error) {
+	windows := runtime.GOOS == "windows"
+	parts := strings.Split(rel, "/")
+	for i, part := range parts {
+		escaped, invalid := escapeFileName(part, windows)
+		if policy == PathStrict {
+			if invalid {
+				return "", fmt.Errorf("%q contains characters not allowed in local file names", part)
+			}
+			continue
+		}
+		parts[i] = escaped
+	}
+	return strings.Join(parts, "/"), nil
+}
+
+// escapeFileName replaces bytes that can't appear in a file name with %XX,
+// and reports whether there were any. Control characters and invalid UTF-8
+// are escaped everywhere; on Windows the reserved characters, trailing dots
+// and spaces, and device names are as well. '%' itself is always escaped, so
+// no two names escape to the same one.
+func escapeFileName(name string, windows bool) (string, bool) {
+	if name == "." || name == ".." {
+		return name, false // left for SafePath's traversal check
+	}
+
+	var sb strings.Builder
+	var needed bool
+	for i := 0; i < len(name); {
+		r, size := utf8.DecodeRuneInString(name[i:])
+		invalid := r == utf8.RuneError && size <= 1 || unicode.IsControl(r)
+		if windows && strings.ContainsRune(`<>:"\|?*`, r) {
+			invalid = true
+		}
+		if windows && i+size == len(name) && (r == '.' || r == ' ') {
+			invalid = true
+		}
+		if windows && i == 0 && windowsReserved.MatchString(name) {
+			invalid = true
+		}
+		needed = needed || invalid
+
+		if invalid || r == '%' {
+			for _, b := range []byte(name[i : i+size]) {
+				fmt.Fprintf(&sb, "%%%02X", b)
+			}
+		} else {
+			sb.WriteString(name[i : i+size])
+		}
+		i += size
+	}
+	return sb.String(), needed
+}
+
 // SafePath validates that a path stays within the base directory
-// Returns the cleaned absolute path or an error if path traversal is detected
+// Returns the cleaned absolute path or an error if path traversal is detected.
+// Characters that aren't valid in local file names are escaped (see LocalRelPath).
 func SafePath(baseDir, relativePath string) (string, error) {
+	relativePath, err := LocalRelPath(relativePath, PathEscape)
+	if err != nil {
+		return "", err
+	}
+	return SafeJoin(baseDir, relativePath)
+}
+
+// SafeJoin is SafePath without the escaping, for a path LocalRelPath
+// already converted or that's converted where it's written, so it's never
+// escaped a second time
+func SafeJoin(baseDir, relativePath string) (string, error) {
 	// Clean and resolve the base directory
 	absBase, err := filepath.Abs(baseDir)
 	if err != nil {
@@ -109,8 +302,8 @@ func SanitizeError(err error) string {
 	// Remove potential AWS account IDs (12 digits)
 	msg = regexp.MustCompile(`\b\d{12}\b`).ReplaceAllString(msg, "[account-id]")
 
-	// Remove potential ARNs
-	msg = regexp.MustCompile(`arn:aws:[^:\s]+:[^:\s]*:[^:\s]*:[^\s]+`).ReplaceAllString(msg, "[arn]")
+	// Remove potential ARNs, in any partition (aws, aws-cn, aws-us-gov)
+	msg = regexp.MustCompile(`arn:aws[a-z-]*:[^:\s]+:[^:\s]*:[^:\s]*:[^\s]+`).ReplaceAllString(msg, "[arn]")
 
 	// Remove S3 bucket names in common error patterns
 	msg = regexp.MustCompile(`bucket[:\s]+['"]?([a-z0-9.-]+)['"]?`).ReplaceAllString(msg, "bucket: [bucket]")
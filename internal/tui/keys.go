@@ -2,6 +2,8 @@ package tui
 
 import (
 	"github.com/charmbracelet/bubbles/key"
+	"github.com/natevick/stui/internal/keymap"
+	"github.com/natevick/stui/pkg/s3browser"
 )
 
 // KeyMap defines all key bindings for the application
@@ -19,24 +21,27 @@ type KeyMap struct {
 	End      key.Binding
 
 	// View switching
-	Tab         key.Binding
-	ShiftTab    key.Binding
-	Buckets     key.Binding
-	Browser     key.Binding
-	Bookmarks   key.Binding
+	Tab       key.Binding
+	ShiftTab  key.Binding
+	Buckets   key.Binding
+	Browser   key.Binding
+	Bookmarks key.Binding
+	Local     key.Binding
+	Transfers key.Binding
 
 	// Actions
-	Select      key.Binding
-	Download    key.Binding
-	Sync        key.Binding
-	AddBookmark key.Binding
-	Delete      key.Binding
-	Refresh     key.Binding
-	Cancel      key.Binding
+	SavedJobs key.Binding
+	Settings  key.Binding
+	Refresh   key.Binding
+	Cancel    key.Binding
+
+	// Objects are the object browser's own keys
+	Objects s3browser.KeyMap
 
 	// App
-	Help key.Binding
-	Quit key.Binding
+	About key.Binding
+	Help  key.Binding
+	Quit  key.Binding
 }
 
 // DefaultKeyMap returns the default key bindings
@@ -102,25 +107,21 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("3"),
 			key.WithHelp("3", "bookmarks"),
 		),
-		Select: key.NewBinding(
-			key.WithKeys(" "),
-			key.WithHelp("space", "select"),
-		),
-		Download: key.NewBinding(
-			key.WithKeys("d"),
-			key.WithHelp("d", "download"),
+		Local: key.NewBinding(
+			key.WithKeys("4"),
+			key.WithHelp("4", "local"),
 		),
-		Sync: key.NewBinding(
-			key.WithKeys("s"),
-			key.WithHelp("s", "sync"),
+		Transfers: key.NewBinding(
+			key.WithKeys("5"),
+			key.WithHelp("5", "transfers"),
 		),
-		AddBookmark: key.NewBinding(
-			key.WithKeys("b"),
-			key.WithHelp("b", "add bookmark"),
+		SavedJobs: key.NewBinding(
+			key.WithKeys("J"),
+			key.WithHelp("J", "saved jobs"),
 		),
-		Delete: key.NewBinding(
-			key.WithKeys("x"),
-			key.WithHelp("x", "delete"),
+		Settings: key.NewBinding(
+			key.WithKeys(","),
+			key.WithHelp(",", "settings"),
 		),
 		Refresh: key.NewBinding(
 			key.WithKeys("r"),
@@ -130,6 +131,10 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("esc"),
 			key.WithHelp("esc", "cancel"),
 		),
+		About: key.NewBinding(
+			key.WithKeys("!"),
+			key.WithHelp("!", "about"),
+		),
 		Help: key.NewBinding(
 			key.WithKeys("?"),
 			key.WithHelp("?", "help"),
@@ -138,9 +143,32 @@ func DefaultKeyMap() KeyMap {
 			key.WithKeys("q", "ctrl+c"),
 			key.WithHelp("q", "quit"),
 		),
+		Objects: s3browser.DefaultKeyMap(),
+	}
+}
+
+// GlobalKeys returns the bindings the root model handles before the active
+// view sees a key
+func (k KeyMap) GlobalKeys() []key.Binding {
+	return []key.Binding{
+		k.Quit, k.Help, k.Tab, k.ShiftTab, k.Left, k.Right,
+		k.Buckets, k.Browser, k.Bookmarks, k.Local, k.Transfers,
+		k.Cancel, k.Refresh, k.SavedJobs, k.Settings,
 	}
 }
 
+// Conflicts returns the keys claimed twice among the bindings live in the
+// object browser: the global keys, then the browser's own with its actions
+// on. A global key shadows the browser's, so any conflict is a key that
+// can't be reached.
+func (k KeyMap) Conflicts() []keymap.Conflict {
+	var r keymap.Registry
+	r.Add("global", k.GlobalKeys()...)
+	r.Add("browser", k.Objects.NavigationKeys()...)
+	r.Add("browser actions", k.Objects.ActionKeys()...)
+	return r.Conflicts()
+}
+
 // ShortHelp returns keybindings for the short help view
 func (k KeyMap) ShortHelp() []key.Binding {
 	return []key.Binding{k.Help, k.Quit}
@@ -150,8 +178,8 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Enter, k.Back},
-		{k.Tab, k.Buckets, k.Browser, k.Bookmarks},
-		{k.Download, k.Sync, k.AddBookmark, k.Refresh},
+		{k.Tab, k.Buckets, k.Browser, k.Bookmarks, k.Local},
+		{k.Objects.Download, k.Objects.Sync, k.Objects.SyncUp, k.Objects.Bookmark, k.Refresh},
 		{k.Help, k.Quit},
 	}
 }
@@ -1,9 +1,15 @@
 package tui
 
 import (
+	"time"
+
 	"github.com/natevick/stui/internal/aws"
 	"github.com/natevick/stui/internal/bookmarks"
+	"github.com/natevick/stui/internal/daemon"
+	"github.com/natevick/stui/internal/doctor"
 	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/inventory"
+	"github.com/natevick/stui/internal/views/preview"
 )
 
 // ViewType represents the current active view
@@ -13,8 +19,9 @@ const (
 	ViewProfiles ViewType = iota
 	ViewBuckets
 	ViewBrowser
-	ViewDownload
+	ViewTransfers
 	ViewBookmarks
+	ViewLocal
 	ViewHelp
 )
 
@@ -26,27 +33,6 @@ type BucketsLoadedMsg struct {
 	Err     error
 }
 
-// BucketSelectedMsg is sent when a bucket is selected
-type BucketSelectedMsg struct {
-	Bucket string
-	Region string
-}
-
-// ObjectsLoadedMsg is sent when objects are loaded
-type ObjectsLoadedMsg struct {
-	Objects []aws.S3Object
-	Prefix  string
-	Err     error
-}
-
-// NavigatePrefixMsg is sent when navigating to a prefix
-type NavigatePrefixMsg struct {
-	Prefix string
-}
-
-// NavigateBackMsg is sent when navigating back
-type NavigateBackMsg struct{}
-
 // SwitchViewMsg is sent to switch to a different view
 type SwitchViewMsg struct {
 	View ViewType
@@ -87,33 +73,211 @@ type BookmarksLoadedMsg struct {
 	Err       error
 }
 
-// AddBookmarkMsg adds a bookmark
-type AddBookmarkMsg struct {
-	Bucket string
-	Prefix string
-	Name   string
-}
-
 // BookmarkAddedMsg confirms bookmark was added
 type BookmarkAddedMsg struct {
 	Bookmark bookmarks.Bookmark
 	Err      error
 }
 
-// RemoveBookmarkMsg removes a bookmark
-type RemoveBookmarkMsg struct {
-	ID string
-}
-
 // BookmarkRemovedMsg confirms bookmark was removed
 type BookmarkRemovedMsg struct {
 	ID  string
 	Err error
 }
 
-// SelectBookmarkMsg selects a bookmark to navigate to
-type SelectBookmarkMsg struct {
-	Bookmark bookmarks.Bookmark
+// ObjectCreatedMsg is sent when a new object has been created from a template
+type ObjectCreatedMsg struct {
+	Bucket string
+	Key    string
+	Err    error
+}
+
+// ObjectRenamedMsg is sent when an object rename finishes
+type ObjectRenamedMsg struct {
+	Bucket string
+	OldKey string
+	NewKey string
+	Err    error
+}
+
+// FolderMovePlannedMsg lists what moving a folder would move, for the
+// preview shown before it starts
+type FolderMovePlannedMsg struct {
+	Bucket  string
+	From    string // prefix being moved, ending in /
+	To      string // prefix it moves to, ending in /
+	Objects []aws.S3Object
+	Naming  string // naming policies the new keys break, if only warned about
+	Err     error
+}
+
+// ObjectDeletedMsg is sent when an object delete finishes
+type ObjectDeletedMsg struct {
+	Bucket string
+	Key    string
+	Err    error
+}
+
+// VerifiedMsg reports how a local file compares to an object
+type VerifiedMsg struct {
+	Key       string
+	LocalPath string
+	Result    download.VerifyResult
+	Err       error
+}
+
+// PresignedMsg carries a presigned URL for an object
+type PresignedMsg struct {
+	Key     string
+	URL     string
+	Expires time.Time
+	Upload  bool // the URL takes a PUT of the object rather than a GET
+	Copied  bool // the URL is on the clipboard
+	Err     error
+}
+
+// ConsoleOpenedMsg reports opening a bucket, folder or object in the AWS
+// console. When no browser could be opened, the link is shown instead.
+type ConsoleOpenedMsg struct {
+	Location string // s3:// URI of what was opened
+	URL      string
+	Copied   bool // the URL is on the clipboard, when it couldn't be opened
+	Err      error
+}
+
+// ObjectDetailsMsg carries an object's full metadata for the inspector
+type ObjectDetailsMsg struct {
+	Key     string
+	Details *aws.ObjectDetails
+	Err     error
+}
+
+// PreviewMsg carries the start of an object's content for the pager, or
+// another window of it when the pager is paged
+type PreviewMsg struct {
+	Bucket      string
+	Key         string
+	ContentType string
+	Size        int64 // of the whole object
+	Data        []byte
+	Offset      int64            // where Data starts in the object
+	Page        bool             // for a pager already open, which is dropped if it's closed
+	Tail        bool             // the last window, shown scrolled to the bottom
+	Parquet     *preview.Parquet // the footer and first rows of a Parquet file, instead of Data
+	Err         error
+}
+
+// SummaryMsg carries the summary of a profile's buckets for the dashboard
+type SummaryMsg struct {
+	Profile string
+	Summary *aws.AccountSummary
+	Err     error
+}
+
+// AboutMsg carries the connectivity checks for the about screen
+type AboutMsg struct {
+	Connectivity doctor.Section
+}
+
+// WhatsNewMsg carries the newest objects under every bookmark for the feed
+type WhatsNewMsg struct {
+	Arrivals []bookmarks.Arrival
+	Notes    []string // bookmarks listed in part or not at all, and why
+	Err      error
+}
+
+// ComparisonMsg carries the details of two objects for the comparison view
+type ComparisonMsg struct {
+	Left  *aws.ObjectDetails
+	Right *aws.ObjectDetails
+	Err   error
+}
+
+// MetadataUpdatedMsg carries an object's details after its headers and
+// metadata were rewritten from the inspector
+type MetadataUpdatedMsg struct {
+	Key     string
+	Details *aws.ObjectDetails
+	Err     error
+}
+
+// VersionsMsg carries the versions and delete markers under a prefix for
+// the version view
+type VersionsMsg struct {
+	Bucket    string
+	Prefix    string
+	Focus     string // key to put the cursor on, if any
+	Versions  []aws.ObjectVersion
+	Truncated bool // there were more than aws.MaxVersions
+	Err       error
+}
+
+// VersionDeletedMsg is sent when a version or delete marker is removed
+type VersionDeletedMsg struct {
+	Bucket  string
+	Version aws.ObjectVersion
+	Err     error
+}
+
+// SnapshotSavedMsg is sent when a prefix's listing has been saved as a
+// snapshot
+type SnapshotSavedMsg struct {
+	Info inventory.Info
+	Err  error
+}
+
+// SnapshotRemovedMsg is sent when a snapshot has been deleted
+type SnapshotRemovedMsg struct {
+	Info inventory.Info
+	Err  error
+}
+
+// InventoryDiffMsg carries what changed under a prefix since a snapshot
+type InventoryDiffMsg struct {
+	Snapshot inventory.Info
+	Diff     inventory.Diff
+	Err      error
+}
+
+// RestoreRequestedMsg is sent when restores of archived objects have been
+// requested
+type RestoreRequestedMsg struct {
+	Bucket     string
+	Tier       string
+	Requested  int            // restores started
+	InProgress int            // objects already being restored
+	Skipped    int            // objects that aren't archived
+	Restoring  []aws.S3Object // the requested and in progress objects, watched until they're ready
+	Err        error
+}
+
+// BackgroundJobsMsg carries the state of saved jobs run in background
+// processes
+type BackgroundJobsMsg struct {
+	Jobs []daemon.State
+	Err  error
+}
+
+// BackgroundStartedMsg is sent when a saved job was started in a
+// background process
+type BackgroundStartedMsg struct {
+	Job daemon.State
+	Err error
+}
+
+// backgroundTickMsg is sent when running background jobs are due to be
+// checked
+type backgroundTickMsg struct{}
+
+// restoreTickMsg is sent when watched restores are due to be polled
+type restoreTickMsg struct{}
+
+// restoreStatusMsg carries the polled restore status of a watched object
+type restoreStatusMsg struct {
+	bucket string
+	key    string
+	status *aws.RestoreStatus // nil if the object was deleted
+	err    error
 }
 
 // ErrorMsg reports an error
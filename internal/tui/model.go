@@ -2,17 +2,41 @@ package tui
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"path"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/atotto/clipboard"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
 	"github.com/natevick/stui/internal/aws"
 	"github.com/natevick/stui/internal/bookmarks"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/daemon"
+	"github.com/natevick/stui/internal/doctor"
 	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/history"
+	"github.com/natevick/stui/internal/inventory"
+	"github.com/natevick/stui/internal/jobs"
+	"github.com/natevick/stui/internal/notify"
+	"github.com/natevick/stui/internal/pool"
+	"github.com/natevick/stui/internal/security"
+	"github.com/natevick/stui/internal/views/about"
 	"github.com/natevick/stui/internal/views/bookmarksview"
-	"github.com/natevick/stui/internal/views/browser"
 	"github.com/natevick/stui/internal/views/buckets"
-	downloadview "github.com/natevick/stui/internal/views/download"
+	"github.com/natevick/stui/internal/views/dashboard"
+	"github.com/natevick/stui/internal/views/localfs"
+	"github.com/natevick/stui/internal/views/preview"
 	"github.com/natevick/stui/internal/views/profiles"
+	"github.com/natevick/stui/internal/views/transfers"
+	"github.com/natevick/stui/internal/views/whatsnew"
+	"github.com/natevick/stui/pkg/s3browser"
 )
 
 // Model is the root model for the TUI application
@@ -25,38 +49,119 @@ type Model struct {
 	demoMode      bool   // use mock data
 
 	// Views
-	activeView     ViewType
-	profilesView   profiles.Model
-	bucketsView    buckets.Model
-	browserView    browser.Model
-	downloadView   downloadview.Model
-	bookmarksView  bookmarksview.Model
-	showHelp       bool
+	activeView    ViewType
+	profilesView  profiles.Model
+	bucketsView   buckets.Model
+	browserView   s3browser.Model
+	transfersView transfers.Model
+	bookmarksView bookmarksview.Model
+	localView     localfs.Model
+	localFocus    bool // local pane has focus in the commander layout
+	showHelp      bool
 
 	// State
-	currentBucket string
-	currentPrefix string
-	bookmarkStore *bookmarks.Store
-	downloadMgr   *download.Manager
+	settings         *config.Config
+	currentBucket    string
+	currentPrefix    string
+	bookmarkStore    *bookmarks.Store
+	jobStore         *jobs.Store                  // saved job templates
+	backgroundStore  *daemon.Store                // saved jobs run in background processes
+	backgroundWatch  bool                         // background jobs are polled periodically
+	historyStore     *history.Store               // earlier prompt answers
+	historyWrites    []func(*history.Store) error // answers to save once a prompt's action has run
+	inventoryStore   *inventory.Store             // saved listing snapshots
+	staleBookmarks   map[string]bool              // bookmarks whose last freshness check found them stale
+	freshnessTicking bool                         // periodic freshness checks are scheduled
+	restoreWatches   map[string]*restoreWatch     // objects being restored, by bucket and key
+	restorePolling   bool                         // watched restores are polled periodically
+	lastJob          jobs.Job                     // most recent sync or folder copy, for saving as a template
+	queue            *download.Queue              // transfer jobs, run concurrently
+	normalization    download.Normalization       // Unicode name comparison for sync
+	symlinkPolicy    download.SymlinkPolicy       // how sync scans and uploads treat symbolic links
+	downloadOpts     aws.DownloadOptions          // configured download part size and concurrency
+	encryption       aws.Encryption               // default server-side encryption for the profile
+	uploadTags       map[string]string            // default tags for uploaded objects
 
 	// UI
-	styles       Styles
-	keys         KeyMap
-	width        int
-	height       int
-	statusMsg    string
-	errorMsg     string
-	errorTimeout time.Time
+	styles              Styles
+	keys                KeyMap
+	width               int
+	height              int
+	statusMsg           string
+	warningMsg          string
+	errorMsg            string
+	errorTimeout        time.Time
+	sharedURL           string             // presigned URL shown in an overlay until a key is pressed
+	sharedNote          string             // expiry and clipboard status of the shared URL
+	sharedCurl          string             // curl command for a presigned URL, copied with c
+	sharedPwsh          string             // PowerShell command for a presigned URL, copied with w
+	inspected           *aws.ObjectDetails // object shown in the inspector until it's closed
+	inventoryDiff       *InventoryDiffMsg  // snapshot diff shown in an overlay until it's closed
+	inventoryDiffOffset int                // first line of the diff shown
+	versions            *VersionsMsg       // versions under a prefix shown in an overlay until it's closed
+	versionCursor       int                // highlighted entry of the version view
+	comparison          *ComparisonMsg     // two objects shown side by side until it's closed
+	compareBucket       string             // bucket of the object marked to compare with the next
+	compareKey          string             // object marked to compare with the next
+	preview             *preview.Model     // object content shown in a pager until it's closed
+	dashboard           *dashboard.Model   // summary of every bucket shown in an overlay until it's closed
+	graphics            preview.Graphics   // how the terminal draws images in the preview
+	whatsNew            *whatsnew.Model    // newest objects under every bookmark shown in an overlay until it's closed
+	about               *about.Model       // version and diagnostics shown in an overlay until it's closed
+	version             string
 
 	// Prompt state
-	showPrompt             bool
-	promptType             string // "input" or "confirm"
-	promptText             string
-	promptInput            string
-	promptDefault          string
-	promptCursor           int
-	pendingDownloadObjects []aws.S3Object // for multi-select downloads
-	pendingBookmarkBucket  string         // for bucket bookmarks
+	showPrompt               bool
+	promptType               string // "input" or "confirm"
+	promptText               string
+	promptInput              string
+	promptDefault            string
+	promptCursor             int
+	promptOptions            []string                          // choices for select prompts
+	promptOption             int                               // highlighted choice
+	promptConfirm            bool                              // yes/no prompt answered with y or n
+	promptRecall             int                               // how many answers back the prompt shows; 0 for what was typed
+	promptDraft              string                            // what was typed before recalling an answer
+	pendingDownloadObjects   []aws.S3Object                    // for multi-select downloads
+	pendingDownloadDir       string                            // destination chosen for multi-select downloads
+	pendingDownloadObject    aws.S3Object                      // large object awaiting a choice of range
+	pendingDownloadPath      string                            // destination chosen for the large object
+	pendingDownloadStart     func(aws.DownloadOptions) tea.Cmd // download awaiting a speed preset
+	pendingManifestKeys      []string                          // keys listed in a manifest awaiting a destination
+	pendingManifestName      string                            // manifest file the pending keys came from
+	pendingFolderMove        *FolderMovePlannedMsg             // folder move awaiting confirmation of its preview
+	pendingTransfer          download.QueuedJob                // transfer job being named or given a note
+	pendingJobStart          *jobReadyMsg                      // transfer being named before it's queued
+	pendingBookmarkBucket    string                            // for bucket bookmarks
+	pendingTemplate          config.Template                   // for new-object creation
+	pendingUploadPath        string                            // local file or directory awaiting a storage class
+	pendingUploadKey         string                            // destination key for single-file uploads
+	pendingUploadClass       string                            // storage class chosen for the pending upload
+	pendingUploadSSE         aws.Encryption                    // encryption chosen for the pending upload
+	pendingResume            *aws.IncompleteUpload             // interrupted upload of the pending key
+	pendingDeleteKey         string                            // object awaiting delete confirmation
+	pendingCopyObjects       []aws.S3Object                    // objects awaiting a copy destination
+	pendingColumnsLayout     string                            // layout whose columns are being set
+	pendingRestoreObjects    []aws.S3Object                    // archived objects awaiting a restore tier and days
+	pendingRestoreTier       string                            // retrieval tier chosen for the pending restore
+	pendingClassObjects      []aws.S3Object                    // objects awaiting a new storage class
+	pendingTagObjects        []aws.S3Object                    // objects awaiting tags to add
+	pendingCopyFiles         []download.CopyFile               // copies awaiting a choice of profile
+	pendingCopyBucket        string                            // destination bucket of the pending copies
+	pendingVerifyKey         string                            // object awaiting a local file to compare with
+	pendingMoveObject        aws.S3Object                      // object awaiting a new key
+	pendingVersion           aws.ObjectVersion                 // version awaiting permanent deletion
+	pendingPresignKey        string                            // object awaiting a URL expiry
+	pendingPresignUpload     bool                              // the pending URL is for uploading to the key
+	pendingSnapshots         map[string]string                 // inventory prompt option → snapshot ID
+	pendingHeader            string                            // inspector header being edited
+	pendingFreshnessBookmark string                            // bookmark whose freshness alert is being set
+	pendingDateBase          string                            // prefix the date partitions are under
+	pendingDatePattern       string                            // layout of the date partitions
+	pendingDateBookmark      string                            // bookmark the date layout belongs to, if any
+	pendingGuarded           tea.Cmd                           // operation held back by a guardrail
+	pendingGuardAnswer       string                            // object count to type to go over a guardrail
+	pendingJob               jobs.Job                          // saved job awaiting confirmation to run
 
 	// Context for cancellation
 	ctx    context.Context
@@ -67,10 +172,43 @@ type Model struct {
 type Config struct {
 	Profile  string
 	Region   string
-	Bucket   string // Start directly in this bucket
-	DemoMode bool   // Use mock data instead of real AWS
+	Bucket   string         // Start directly in this bucket
+	DemoMode bool           // Use mock data instead of real AWS
+	Settings *config.Config // User settings from config.json
+	Version  string         // stui's version, for the about screen
 }
 
+// newBrowser creates the object browser with stui's own keys turned on
+func newBrowser() s3browser.Model {
+	b := s3browser.New()
+	b.SetActionsEnabled(true)
+	return b
+}
+
+// columnsFor returns the browser columns configured for a layout. The
+// commander layout falls back to the browser's, and both to the defaults.
+func columnsFor(settings *config.Config, layout string) []s3browser.Column {
+	configured := settings.ColumnsFor(layout)
+	if configured == nil && layout == config.LayoutCommander {
+		configured = settings.ColumnsFor(config.LayoutBrowser)
+	}
+	if configured == nil {
+		return s3browser.DefaultColumns
+	}
+	columns := make([]s3browser.Column, len(configured))
+	for i, col := range configured {
+		columns[i] = s3browser.Column{Field: col.Field, Width: col.Width}
+	}
+	return columns
+}
+
+func (m Model) columnsFor(layout string) []s3browser.Column {
+	return columnsFor(m.settings, layout)
+}
+
+// errNotConnected is returned by commands that need an AWS client
+var errNotConnected = errors.New("not connected to AWS")
+
 // New creates a new TUI model
 func New(cfg Config) Model {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -84,21 +222,40 @@ func New(cfg Config) Model {
 		activeView = ViewProfiles
 	}
 
+	settings := cfg.Settings
+	if settings == nil {
+		settings = config.Default()
+	}
+
+	keys := DefaultKeyMap()
+	browser := newBrowser()
+	browser.SetContext(ctx)
+	browser.SetKeyMap(keys.Objects)
+	browser.SetColumns(columnsFor(settings, config.LayoutBrowser))
+	bucketList := buckets.New()
+	bucketList.SetHidden(settings.HiddenBuckets)
+
 	return Model{
-		profile:       cfg.Profile,
-		region:        cfg.Region,
-		initialBucket: cfg.Bucket,
-		demoMode:      cfg.DemoMode,
-		activeView:    activeView,
-		profilesView:  profiles.New(),
-		bucketsView:   buckets.New(),
-		browserView:   browser.New(),
-		downloadView:  downloadview.New(),
-		bookmarksView: bookmarksview.New(),
-		styles:        DefaultStyles(),
-		keys:          DefaultKeyMap(),
-		ctx:           ctx,
-		cancel:        cancel,
+		settings:       settings,
+		version:        cfg.Version,
+		profile:        cfg.Profile,
+		region:         cfg.Region,
+		initialBucket:  cfg.Bucket,
+		demoMode:       cfg.DemoMode,
+		activeView:     activeView,
+		profilesView:   profiles.New(),
+		bucketsView:    bucketList,
+		browserView:    browser,
+		transfersView:  transfers.New(),
+		bookmarksView:  bookmarksview.New(),
+		localView:      localfs.New("."),
+		styles:         DefaultStyles(),
+		keys:           keys,
+		staleBookmarks: make(map[string]bool),
+		restoreWatches: make(map[string]*restoreWatch),
+		graphics:       preview.DetectGraphics(settings.ImageGraphics),
+		ctx:            ctx,
+		cancel:         cancel,
 	}
 }
 
@@ -108,6 +265,9 @@ func (m Model) Init() tea.Cmd {
 		return tea.Batch(
 			m.initDemo(),
 			m.initBookmarks(),
+			m.initJobs(),
+			m.initInventory(),
+			m.initHistory(),
 			tea.SetWindowTitle("S3 TUI (Demo)"),
 		)
 	}
@@ -117,6 +277,10 @@ func (m Model) Init() tea.Cmd {
 		return tea.Batch(
 			m.initProfiles(),
 			m.initBookmarks(),
+			m.initJobs(),
+			m.initBackground(),
+			m.initInventory(),
+			m.initHistory(),
 			tea.SetWindowTitle("S3 TUI"),
 		)
 	}
@@ -124,6 +288,10 @@ func (m Model) Init() tea.Cmd {
 	return tea.Batch(
 		m.initAWS(),
 		m.initBookmarks(),
+		m.initJobs(),
+		m.initBackground(),
+		m.initInventory(),
+		m.initHistory(),
 		tea.SetWindowTitle("S3 TUI"),
 	)
 }
@@ -151,17 +319,22 @@ type demoReadyMsg struct{}
 // initAWS initializes the AWS client
 func (m Model) initAWS() tea.Cmd {
 	return func() tea.Msg {
-		client, err := aws.NewClient(m.ctx, m.profile, m.region)
+		client, err := aws.NewClient(m.ctx, m.profile, m.region, aws.EndpointOptions{FIPS: m.settings.UseFIPS(m.profile)})
 		if err != nil {
 			return ErrorMsg{Err: err}
 		}
-		return awsClientReadyMsg{client: client}
+		// The bucket is still usable without its mirrors, so that error
+		// is reported once it's open
+		mirrors, err := m.settings.ConnectMirrors(m.ctx, m.profile, m.region)
+		return awsClientReadyMsg{client: client, mirrors: mirrors, mirrorsErr: err}
 	}
 }
 
 // awsClientReadyMsg is sent when AWS client is ready
 type awsClientReadyMsg struct {
-	client *aws.Client
+	client     *aws.Client
+	mirrors    download.Mirrors
+	mirrorsErr error
 }
 
 // initBookmarks initializes the bookmark store
@@ -180,6 +353,86 @@ type bookmarkStoreReadyMsg struct {
 	store *bookmarks.Store
 }
 
+// initJobs initializes the saved job store
+func (m Model) initJobs() tea.Cmd {
+	return func() tea.Msg {
+		store, err := jobs.NewStore()
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return jobStoreReadyMsg{store: store}
+	}
+}
+
+// jobStoreReadyMsg is sent when the saved job store is ready
+type jobStoreReadyMsg struct {
+	store *jobs.Store
+}
+
+// initBackground initializes the store of saved jobs run in background
+// processes, picking up any still running from an earlier session
+func (m Model) initBackground() tea.Cmd {
+	return func() tea.Msg {
+		store, err := daemon.NewStore()
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return backgroundStoreReadyMsg{store: store}
+	}
+}
+
+// backgroundStoreReadyMsg is sent when the background job store is ready
+type backgroundStoreReadyMsg struct {
+	store *daemon.Store
+}
+
+// initInventory initializes the snapshot store
+func (m Model) initInventory() tea.Cmd {
+	return func() tea.Msg {
+		store, err := inventory.NewStore()
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return inventoryStoreReadyMsg{store: store}
+	}
+}
+
+// inventoryStoreReadyMsg is sent when the snapshot store is ready
+type inventoryStoreReadyMsg struct {
+	store *inventory.Store
+}
+
+// initHistory initializes the prompt history store
+func (m Model) initHistory() tea.Cmd {
+	return func() tea.Msg {
+		store, err := history.NewStore()
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return historyStoreReadyMsg{store: store}
+	}
+}
+
+// historyStoreReadyMsg is sent when the prompt history store is ready
+type historyStoreReadyMsg struct {
+	store *history.Store
+}
+
+// saveHistory applies writes to the prompt history store, which saves it,
+// off the update loop. History is a convenience, so failing to save it is
+// ignored.
+func saveHistory(store *history.Store, writes []func(*history.Store) error) tea.Cmd {
+	if store == nil || len(writes) == 0 {
+		return nil
+	}
+	return func() tea.Msg {
+		for _, write := range writes {
+			_ = write(store)
+		}
+		return nil
+	}
+}
+
 // SetSize sets the terminal size
 func (m *Model) SetSize(width, height int) {
 	m.width = width
@@ -191,8 +444,26 @@ func (m *Model) SetSize(width, height int) {
 	m.profilesView.SetSize(width-2, contentHeight)
 	m.bucketsView.SetSize(width-2, contentHeight)
 	m.browserView.SetSize(width-2, contentHeight)
-	m.downloadView.SetSize(width-2, contentHeight)
+	m.transfersView.SetSize(width-2, contentHeight)
 	m.bookmarksView.SetSize(width-2, contentHeight)
+	m.localView.SetSize(commanderPaneWidth(width)-2, contentHeight-2)
+	if m.preview != nil {
+		m.preview.SetSize(width-6, height-4)
+	}
+	if m.dashboard != nil {
+		m.dashboard.SetSize(width-6, height-4)
+	}
+	if m.whatsNew != nil {
+		m.whatsNew.SetSize(width-6, height-4)
+	}
+	if m.about != nil {
+		m.about.SetSize(width-6, height-4)
+	}
+}
+
+// commanderPaneWidth returns the width of each pane in the commander layout
+func commanderPaneWidth(width int) int {
+	return (width - 2) / 2
 }
 
 // loadBuckets returns a command to load buckets
@@ -209,87 +480,1310 @@ func (m Model) loadBuckets() tea.Cmd {
 	}
 }
 
-// loadObjects returns a command to load objects at the current prefix
-func (m Model) loadObjects() tea.Cmd {
-	if m.demoMode {
-		return m.loadDemoObjects()
+// openAbout shows the about screen with the local checks, and returns a
+// command checking connectivity
+func (m *Model) openAbout() tea.Cmd {
+	a := about.New(doctor.Local(doctor.Options{
+		Version:  m.version,
+		Profile:  m.profile,
+		Graphics: m.graphics.String(),
+	}))
+	a.SetSize(m.width-6, m.height-4)
+	m.about = &a
+
+	client := m.client
+	return func() tea.Msg {
+		if client == nil {
+			s := doctor.Section{Title: "Connectivity", Checks: []doctor.Check{{Name: "Client", Detail: "not connected; choose a profile first"}}}
+			return AboutMsg{Connectivity: s}
+		}
+		return AboutMsg{Connectivity: doctor.Connectivity(m.ctx, client)}
 	}
+}
+
+// summarizeBuckets returns a command to summarize the profile's buckets for
+// the dashboard
+func (m Model) summarizeBuckets() tea.Cmd {
+	profile := m.profile
 	return func() tea.Msg {
-		if m.client == nil || m.currentBucket == "" {
-			return nil
+		if m.client == nil {
+			return SummaryMsg{Profile: profile, Err: errNotConnected}
 		}
-		objects, err := m.client.ListObjects(m.ctx, m.currentBucket, m.currentPrefix)
+		bucketList, err := m.client.ListBuckets(m.ctx)
 		if err != nil {
-			return ObjectsLoadedMsg{Err: err}
+			return SummaryMsg{Profile: profile, Err: err}
+		}
+		return SummaryMsg{Profile: profile, Summary: m.client.SummarizeBuckets(m.ctx, bucketList)}
+	}
+}
+
+// openLocation shows prefix in bucket in the browser, grouping keys on the
+// bucket's configured delimiter
+func (m *Model) openLocation(bucket, prefix string) tea.Cmd {
+	m.browserView.SetDelimiter(m.settings.ForBucket(bucket).ListDelimiter())
+	return m.browserView.SetLocation(bucket, prefix)
+}
+
+// refreshObjects reloads the current listing in place after stui writes or
+// removes keys in bucket. It returns nil if none of the keys show up at the
+// current prefix, either directly or under one of its folders.
+func (m Model) refreshObjects(bucket string, keys []string) tea.Cmd {
+	if bucket != m.currentBucket {
+		return nil
+	}
+	affected := false
+	for _, key := range keys {
+		if strings.HasPrefix(key, m.currentPrefix) {
+			affected = true
+			break
 		}
-		return ObjectsLoadedMsg{Objects: objects, Prefix: m.currentPrefix}
 	}
+	if !affected {
+		return nil
+	}
+
+	return m.browserView.Refresh()
+}
+
+// startDownload starts downloading an object, or everything under a
+// prefix, once it's within the guardrails, with opts' part size and
+// concurrency
+func (m Model) startDownload(obj aws.S3Object, localPath string, opts aws.DownloadOptions) tea.Cmd {
+	bucket, key := m.currentBucket, obj.Key
+	return m.guarded("Download "+key, []aws.S3Object{obj}, m.queueJob("Download "+key, download.DirectionDownload, func(ctx context.Context, mgr *download.Manager) error {
+		mgr.SetDownloadOptions(opts)
+		if obj.IsPrefix {
+			return mgr.DownloadPrefix(ctx, bucket, key, localPath)
+		}
+		return mgr.DownloadFile(ctx, bucket, key, localPath)
+	}))
+}
+
+// startRangeDownload starts downloading part of an object, e.g. to sample
+// the head or tail of a huge file, once the part is within the guardrails
+func (m Model) startRangeDownload(obj aws.S3Object, localPath string, r aws.ByteRange) tea.Cmd {
+	bucket, key := m.currentBucket, obj.Key
+	part := aws.S3Object{Key: key, Size: r.Size(obj.Size)}
+	name := fmt.Sprintf("Download %s of %s", r, key)
+	return m.guarded(name, []aws.S3Object{part}, m.queueJob(name, download.DirectionDownload, func(ctx context.Context, mgr *download.Manager) error {
+		return mgr.DownloadFileRange(ctx, bucket, key, localPath, r)
+	}))
+}
+
+// startUpload starts uploading a local file to the current bucket
+func (m Model) startUpload(localPath, key string, attrs aws.ObjectAttributes) tea.Cmd {
+	bucket := m.currentBucket
+	return m.queueJob("Upload "+key, download.DirectionUpload, func(ctx context.Context, mgr *download.Manager) error {
+		return mgr.UploadFile(ctx, bucket, key, localPath, attrs)
+	})
+}
+
+// startResumeUpload finishes an interrupted multipart upload of a local file
+func (m Model) startResumeUpload(localPath, key string, upload *aws.IncompleteUpload) tea.Cmd {
+	bucket := m.currentBucket
+	return m.queueJob("Resume upload "+key, download.DirectionUpload, func(ctx context.Context, mgr *download.Manager) error {
+		return mgr.ResumeUpload(ctx, bucket, key, localPath, upload)
+	})
+}
+
+// guardrailMsg holds back an operation that goes over a guardrail until
+// the user confirms it
+type guardrailMsg struct {
+	op       string
+	exceeded *config.LimitExceeded
+	run      tea.Cmd
+	err      error
 }
 
-// startDownload starts a download operation
-func (m Model) startDownload(key, localPath string, isPrefix bool) tea.Cmd {
+// guarded returns a command that lists everything objs cover, folders
+// recursively, and runs op's command if it's within the guardrails.
+// Otherwise it sends a guardrailMsg asking to go ahead.
+func (m Model) guarded(op string, objs []aws.S3Object, run tea.Cmd) tea.Cmd {
+	limits := m.settings.Guardrails
+	if !limits.Enabled() {
+		return run
+	}
+	bucket := m.currentBucket
 	return func() tea.Msg {
-		if m.downloadMgr == nil || m.client == nil {
-			return ErrorMsg{Err: nil}
+		if m.client == nil {
+			return run()
+		}
+		var count int
+		var bytes int64
+		for _, obj := range objs {
+			if !obj.IsPrefix {
+				count++
+				bytes += obj.Size
+				continue
+			}
+			listed, err := m.client.ListAllObjects(m.ctx, bucket, obj.Key)
+			if err != nil {
+				return guardrailMsg{op: op, err: err}
+			}
+			for _, o := range listed {
+				count++
+				bytes += o.Size
+			}
 		}
 
-		// Set up progress callback
-		progressChan := make(chan download.Progress, 10)
-		m.downloadMgr.SetProgressCallback(func(p download.Progress) {
-			select {
-			case progressChan <- p:
-			default:
+		return m.checkGuardrails(op, count, bytes, run)
+	}
+}
+
+// guardedKeys is guarded for an explicit list of keys, e.g. from a
+// manifest. Their sizes are looked up only if there's a limit on bytes.
+func (m Model) guardedKeys(op string, keys []string, run tea.Cmd) tea.Cmd {
+	limits := m.settings.Guardrails
+	if !limits.Enabled() {
+		return run
+	}
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return run()
+		}
+		var bytes atomic.Int64
+		if limits.MaxGB > 0 {
+			// Keys that can't be found fail when the download reaches them
+			pool.Each(m.ctx, guardrailLookups, keys, func(worker int, key string) {
+				if obj, err := m.client.GetObjectMetadata(m.ctx, bucket, key); err == nil {
+					bytes.Add(obj.Size)
+				}
+			})
+		}
+		return m.checkGuardrails(op, len(keys), bytes.Load(), run)
+	}
+}
+
+// guardrailLookups is how many keys guardedKeys looks up at once
+const guardrailLookups = 8
+
+// checkGuardrails runs op's command if count objects totalling bytes are
+// within the guardrails, or returns a guardrailMsg asking to go ahead
+func (m Model) checkGuardrails(op string, count int, bytes int64, run tea.Cmd) tea.Msg {
+	var exceeded *config.LimitExceeded
+	if errors.As(m.settings.Guardrails.Check(count, bytes), &exceeded) {
+		return guardrailMsg{op: op, exceeded: exceeded, run: run}
+	}
+	return run()
+}
+
+// queueJob returns a command that readies a transfer for the job queue,
+// which offers to name it before submitting it
+func (m Model) queueJob(name string, dir download.Direction, run download.JobFunc) tea.Cmd {
+	return func() tea.Msg {
+		if m.queue == nil || m.client == nil {
+			return ErrorMsg{Err: errNotConnected}
+		}
+		return jobReadyMsg{job: download.QueuedJob{Name: name, Direction: dir}, run: run}
+	}
+}
+
+// queueSync returns a command that readies a sync, which transfers in dir,
+// for the job queue
+func (m Model) queueSync(name string, dir download.Direction, run download.JobFunc) tea.Cmd {
+	return func() tea.Msg {
+		if m.queue == nil || m.client == nil {
+			return ErrorMsg{Err: errNotConnected}
+		}
+		return jobReadyMsg{job: download.QueuedJob{Name: name, Direction: dir, Sync: true}, run: run}
+	}
+}
+
+// jobReadyMsg is sent when a transfer is planned and can be named before
+// it's queued
+type jobReadyMsg struct {
+	job download.QueuedJob // its name, direction and, once given, label and note
+	run download.JobFunc
+}
+
+// submitJob returns a command that submits a readied transfer to the job
+// queue
+func (m Model) submitJob(ready jobReadyMsg) tea.Cmd {
+	return func() tea.Msg {
+		if m.queue == nil {
+			return ErrorMsg{Err: errNotConnected}
+		}
+		return jobSubmittedMsg{id: m.queue.SubmitJob(m.ctx, ready.job, ready.run)}
+	}
+}
+
+// recordJob returns a command that keeps a finished transfer the user
+// named or noted in the history, so its name and note outlive the session
+func (m Model) recordJob(job download.QueuedJob) tea.Cmd {
+	if job.Label == "" && job.Note == "" {
+		return nil
+	}
+	p := job.Progress
+	record := history.Job{
+		Label:          job.Label,
+		Note:           job.Note,
+		Name:           job.Name,
+		Direction:      job.Direction,
+		Sync:           job.Sync,
+		Status:         job.Status,
+		CompletedFiles: p.CompletedFiles,
+		TotalFiles:     p.TotalFiles,
+		Bytes:          p.DownloadedBytes,
+		StartedAt:      p.StartedAt,
+		FinishedAt:     time.Now(),
+	}
+	return saveHistory(m.historyStore, []func(*history.Store) error{
+		func(s *history.Store) error { return s.AddJob(record) },
+	})
+}
+
+// jobSubmittedMsg is sent when a transfer job has been queued
+type jobSubmittedMsg struct {
+	id int
+}
+
+// syncUpToDateMsg is sent when a sync finds nothing to transfer
+type syncUpToDateMsg struct {
+	unchanged int
+}
+
+// startSyncUp uploads new and changed files from localDir to the current prefix
+func (m Model) startSyncUp(localDir string, attrs aws.ObjectAttributes) tea.Cmd {
+	bucket, prefix := m.currentBucket, m.currentPrefix
+	return func() tea.Msg {
+		if m.queue == nil || m.client == nil {
+			return ErrorMsg{Err: errNotConnected}
+		}
+
+		syncMgr := download.NewSyncManager(m.client)
+		syncMgr.SetNormalization(m.normalization)
+		syncMgr.SetSymlinkPolicy(m.symlinkPolicy)
+		result, err := syncMgr.CompareLocal(m.ctx, localDir, bucket, prefix)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+		if len(result.ToUpload) == 0 {
+			return syncUpToDateMsg{unchanged: len(result.Unchanged)}
+		}
+
+		// Blocking naming policies apply to every key the sync would write;
+		// keys only warned about are noted in the job's summary
+		keys := make([]string, len(result.ToUpload))
+		for i, f := range result.ToUpload {
+			keys[i] = f.Key
+		}
+		naming, err := m.settings.CheckKeys(bucket, keys)
+		if err != nil {
+			return ErrorMsg{Err: err}
+		}
+
+		var notes []string
+		for _, note := range []string{result.Symlinks.String(), naming} {
+			if note != "" {
+				notes = append(notes, note)
 			}
-		})
-
-		go func() {
-			var err error
-			if isPrefix {
-				err = m.downloadMgr.DownloadPrefix(m.ctx, m.currentBucket, key, localPath)
-			} else {
-				err = m.downloadMgr.DownloadFile(m.ctx, m.currentBucket, key, localPath)
+		}
+		summary := strings.Join(notes, "; ")
+		return m.queueSync("Sync up "+filepath.Base(localDir), download.DirectionUpload, func(ctx context.Context, mgr *download.Manager) error {
+			return mgr.UploadMultiple(ctx, bucket, result.ToUpload, attrs, summary)
+		})()
+	}
+}
+
+// startMultiDownload starts downloading multiple objects once they're within
+// the guardrails. root is stripped from each key to form its local path; ""
+// mirrors the full key path.
+func (m Model) startMultiDownload(objects []aws.S3Object, localDir, root string, opts aws.DownloadOptions) tea.Cmd {
+	bucket := m.currentBucket
+	name := fmt.Sprintf("Download %d objects", len(objects))
+	return m.guarded(name, objects, m.queueJob(name, download.DirectionDownload, func(ctx context.Context, mgr *download.Manager) error {
+		mgr.SetDownloadOptions(opts)
+		return mgr.DownloadMultiple(ctx, bucket, objects, root, localDir)
+	}))
+}
+
+// startManifestDownload downloads the keys a manifest listed from the
+// current bucket to their key paths under localDir, once they're within
+// the guardrails
+func (m Model) startManifestDownload(keys []string, manifest, localDir string, opts aws.DownloadOptions) tea.Cmd {
+	bucket := m.currentBucket
+	name := fmt.Sprintf("Download %d keys from %s", len(keys), manifest)
+	return m.guardedKeys(name, keys, m.queueJob(name, download.DirectionDownload, func(ctx context.Context, mgr *download.Manager) error {
+		mgr.SetDownloadOptions(opts)
+		return mgr.DownloadKeys(ctx, bucket, keys, localDir)
+	}))
+}
+
+// planFolderMove returns a command that lists the objects under from, to
+// be moved under to. Moving onto a folder that already has objects is
+// refused, so nothing gets overwritten.
+func (m Model) planFolderMove(from, to string) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		msg := FolderMovePlannedMsg{Bucket: bucket, From: from, To: to}
+		if m.client == nil {
+			msg.Err = errNotConnected
+			return msg
+		}
+		existing, err := m.client.ListAllObjects(m.ctx, bucket, to)
+		if err != nil {
+			msg.Err = err
+			return msg
+		}
+		if len(existing) > 0 {
+			msg.Err = fmt.Errorf("s3://%s/%s already has %d objects; move to an empty folder", bucket, to, len(existing))
+			return msg
+		}
+		msg.Objects, msg.Err = m.client.ListAllObjects(m.ctx, bucket, from)
+		if msg.Err == nil && len(msg.Objects) == 0 {
+			msg.Err = fmt.Errorf("nothing under s3://%s/%s", bucket, from)
+		}
+		if msg.Err != nil {
+			return msg
+		}
+
+		// The new keys are held to the naming policies like any other write
+		keys := make([]string, len(msg.Objects))
+		for i, obj := range msg.Objects {
+			keys[i] = to + strings.TrimPrefix(obj.Key, from)
+		}
+		msg.Naming, msg.Err = m.settings.CheckKeys(bucket, keys)
+		return msg
+	}
+}
+
+// startFolderMove moves everything a folder move planned to its new keys
+func (m Model) startFolderMove(plan FolderMovePlannedMsg) tea.Cmd {
+	files := make([]download.CopyFile, len(plan.Objects))
+	for i, obj := range plan.Objects {
+		files[i] = download.CopyFile{Source: obj, Key: plan.To + strings.TrimPrefix(obj.Key, plan.From)}
+	}
+	name := fmt.Sprintf("Move %s to %s", plan.From, plan.To)
+	return m.queueJob(name, download.DirectionMove, func(ctx context.Context, mgr *download.Manager) error {
+		return mgr.MoveObjects(ctx, plan.Bucket, files)
+	})
+}
+
+// startCopy copies objects, and everything under any folders among them,
+// from the current bucket to dstBucket inside S3
+func (m Model) startCopy(files []download.CopyFile, dstBucket string) tea.Cmd {
+	bucket := m.currentBucket
+	name := fmt.Sprintf("Copy %d objects to s3://%s", len(files), dstBucket)
+	if len(files) == 1 {
+		name = fmt.Sprintf("Copy %s to s3://%s/%s", files[0].Source.Key, dstBucket, files[0].Key)
+	}
+	return m.queueJob(name, download.DirectionCopy, func(ctx context.Context, mgr *download.Manager) error {
+		files, err := m.expandFolderCopies(ctx, bucket, files, dstBucket)
+		if err != nil {
+			return err
+		}
+		return mgr.CopyObjects(ctx, bucket, files, dstBucket)
+	})
+}
+
+// expandFolderCopies replaces the folders among files with the objects
+// under them, each copied to the same place under the folder's destination.
+// The keys found under folders are held to dstBucket's naming policies,
+// which the copy prompt could only check for single objects.
+func (m Model) expandFolderCopies(ctx context.Context, bucket string, files []download.CopyFile, dstBucket string) ([]download.CopyFile, error) {
+	var expanded []download.CopyFile
+	var listedKeys []string
+	for _, f := range files {
+		if !f.Source.IsPrefix {
+			expanded = append(expanded, f)
+			continue
+		}
+		listed, err := m.client.ListAllObjects(ctx, bucket, f.Source.Key)
+		if err != nil {
+			return nil, err
+		}
+		for _, obj := range listed {
+			key := f.Key + strings.TrimPrefix(obj.Key, f.Source.Key)
+			if err := security.ValidObjectKey(key); err != nil {
+				return nil, err
 			}
+			expanded = append(expanded, download.CopyFile{Source: obj, Key: key})
+			listedKeys = append(listedKeys, key)
+		}
+	}
+	if len(expanded) == 0 {
+		return nil, errors.New("nothing to copy; the folders are empty")
+	}
+	if _, err := m.settings.CheckKeys(dstBucket, listedKeys); err != nil {
+		return nil, err
+	}
+	return expanded, nil
+}
+
+// startStorageClassChange moves objects, and everything under any folders
+// among them, in the current bucket to another storage class
+func (m Model) startStorageClassChange(objs []aws.S3Object, class string) tea.Cmd {
+	bucket := m.currentBucket
+	name := fmt.Sprintf("Move %d objects to %s", len(objs), class)
+	if len(objs) == 1 {
+		name = fmt.Sprintf("Move %s to %s", objs[0].Key, class)
+	}
+	return m.queueJob(name, download.DirectionCopy, func(ctx context.Context, mgr *download.Manager) error {
+		var objects []aws.S3Object
+		for _, obj := range objs {
+			if !obj.IsPrefix {
+				objects = append(objects, obj)
+				continue
+			}
+			listed, err := m.client.ListAllObjects(ctx, bucket, obj.Key)
 			if err != nil {
-				progressChan <- download.Progress{Status: download.StatusFailed}
+				return err
 			}
-			close(progressChan)
-		}()
+			objects = append(objects, listed...)
+		}
+		return mgr.ChangeStorageClass(ctx, bucket, objects, class)
+	})
+}
 
-		return downloadStartedMsg{progressChan: progressChan}
+// startTagging adds tags to objects, and everything under any folders
+// among them, in the current bucket
+func (m Model) startTagging(objs []aws.S3Object, tags map[string]string) tea.Cmd {
+	bucket := m.currentBucket
+	name := fmt.Sprintf("Tag %d objects", len(objs))
+	if len(objs) == 1 {
+		name = "Tag " + objs[0].Key
 	}
+	return m.queueJob(name, download.DirectionTag, func(ctx context.Context, mgr *download.Manager) error {
+		var objects []aws.S3Object
+		for _, obj := range objs {
+			if !obj.IsPrefix {
+				objects = append(objects, obj)
+				continue
+			}
+			listed, err := m.client.ListAllObjects(ctx, bucket, obj.Key)
+			if err != nil {
+				return err
+			}
+			objects = append(objects, listed...)
+		}
+		return mgr.TagObjects(ctx, bucket, objects, tags)
+	})
 }
 
-// downloadStartedMsg is sent when a download starts
-type downloadStartedMsg struct {
-	progressChan <-chan download.Progress
+// restoreObjects returns a command that requests restores of the archived
+// objects among objs, and of those under any folders among them
+func (m Model) restoreObjects(objs []aws.S3Object, tier string, days int32) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return RestoreRequestedMsg{Err: errNotConnected}
+		}
+		msg := RestoreRequestedMsg{Bucket: bucket, Tier: tier}
+		var objects []aws.S3Object
+		for _, obj := range objs {
+			if !obj.IsPrefix {
+				objects = append(objects, obj)
+				continue
+			}
+			listed, err := m.client.ListAllObjects(m.ctx, bucket, obj.Key)
+			if err != nil {
+				msg.Err = err
+				return msg
+			}
+			objects = append(objects, listed...)
+		}
+		for _, obj := range objects {
+			if !obj.Archived() {
+				msg.Skipped++
+				continue
+			}
+			err := m.client.RestoreObject(m.ctx, bucket, obj.Key, tier, days)
+			switch {
+			case errors.Is(err, aws.ErrRestoreInProgress):
+				msg.InProgress++
+				msg.Restoring = append(msg.Restoring, obj)
+			case err != nil:
+				msg.Err = err
+				return msg
+			default:
+				msg.Requested++
+				msg.Restoring = append(msg.Restoring, obj)
+			}
+		}
+		return msg
+	}
 }
 
-// startMultiDownload starts downloading multiple objects
-func (m Model) startMultiDownload(objects []aws.S3Object, localDir string) tea.Cmd {
+// startCopyAs copies objects, and everything under any folders among
+// them, from the current bucket to dstBucket with
+// another profile's credentials, streaming them through stui so the two
+// accounts don't need access to each other's buckets
+func (m Model) startCopyAs(files []download.CopyFile, dstBucket, profile string) tea.Cmd {
+	bucket := m.currentBucket
+	name := fmt.Sprintf("Copy %d objects to s3://%s as %s", len(files), dstBucket, profile)
+	if len(files) == 1 {
+		name = fmt.Sprintf("Copy %s to s3://%s/%s as %s", files[0].Source.Key, dstBucket, files[0].Key, profile)
+	}
+	return m.queueJob(name, download.DirectionCopy, func(ctx context.Context, mgr *download.Manager) error {
+		client, err := aws.NewClient(ctx, profile, "", aws.EndpointOptions{FIPS: m.settings.UseFIPS(profile)})
+		if err != nil {
+			return err
+		}
+		// Uploads have to go to the bucket's own region
+		dst, err := client.ForBucket(ctx, dstBucket)
+		if err != nil {
+			dst = client
+		}
+		files, err := m.expandFolderCopies(ctx, bucket, files, dstBucket)
+		if err != nil {
+			return err
+		}
+		return mgr.CopyObjectsAs(ctx, dst, bucket, files, dstBucket)
+	})
+}
+
+// runSavedJob queues a saved job. Jobs saved under another profile have to
+// be run with that profile's credentials, e.g. with stui run-template.
+func (m Model) runSavedJob(job jobs.Job) tea.Cmd {
+	if job.Profile != "" && job.Profile != m.profile {
+		return func() tea.Msg {
+			return ErrorMsg{Err: fmt.Errorf("%s uses profile %s; switch to it or run `stui run-template %s`", job.Name, job.Profile, job.Name)}
+		}
+	}
+	attrs := aws.ObjectAttributes{Tags: m.uploadTags, Encryption: m.encryption}
+	queue := m.queueJob
+	if job.IsSync() {
+		queue = m.queueSync
+	}
+	return queue(job.Name, job.Direction(), func(ctx context.Context, mgr *download.Manager) error {
+		syncMgr := download.NewSyncManager(m.client)
+		syncMgr.SetNormalization(m.normalization)
+		syncMgr.SetSymlinkPolicy(m.symlinkPolicy)
+		if err := job.Run(ctx, syncMgr, mgr, attrs, m.settings); !errors.Is(err, jobs.ErrNothingToDo) {
+			return err
+		}
+		return nil
+	})
+}
+
+// inspectObject returns a command that fetches an object's metadata and
+// tags for the inspector
+func (m Model) inspectObject(key string) tea.Cmd {
+	bucket := m.currentBucket
 	return func() tea.Msg {
-		if m.downloadMgr == nil || m.client == nil {
-			return ErrorMsg{Err: nil}
+		if m.client == nil {
+			return ObjectDetailsMsg{Key: key, Err: errNotConnected}
 		}
+		details, err := m.client.GetObjectDetails(m.ctx, bucket, key)
+		return ObjectDetailsMsg{Key: key, Details: details, Err: err}
+	}
+}
 
-		// Set up progress callback
-		progressChan := make(chan download.Progress, 10)
-		m.downloadMgr.SetProgressCallback(func(p download.Progress) {
-			select {
-			case progressChan <- p:
-			default:
+// previewObject returns a command that fetches the start of an object, up
+// to preview.MaxBytes, or a Parquet file's schema and first rows, for the
+// pager
+func (m Model) previewObject(bucket string, obj aws.S3Object) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		if client == nil {
+			return PreviewMsg{Key: obj.Key, Err: errNotConnected}
+		}
+		if bucket != m.currentBucket {
+			// e.g. from What's new, in a bucket the browser isn't in
+			if c, err := client.ForBucket(m.ctx, bucket); err == nil {
+				client = c
+			}
+		}
+		if preview.IsParquet(obj.Key) {
+			// Only the footer and the pages of the first rows are fetched
+			r := client.NewRangeReader(m.ctx, bucket, obj.Key, obj.Size, preview.ParquetBudget)
+			p, err := preview.ReadParquet(r, obj.Size)
+			if p != nil {
+				p.Fetched, p.Requests = r.Fetched()
+			}
+			return PreviewMsg{Bucket: bucket, Key: obj.Key, Size: obj.Size, Parquet: p, Err: err}
+		}
+		var r aws.ByteRange
+		limit := int64(preview.MaxBytes)
+		if preview.IsImage(obj.Key, "") && obj.Size <= preview.ImageMaxBytes {
+			limit = max(obj.Size, limit) // drawn whole
+		}
+		if obj.Size > limit {
+			r.Length = limit
+		}
+		data, contentType, err := client.ReadObjectRange(m.ctx, bucket, obj.Key, r, limit)
+		return PreviewMsg{Bucket: bucket, Key: obj.Key, ContentType: contentType, Size: obj.Size, Data: data, Err: err}
+	}
+}
+
+// previewPage returns a command fetching the window of an object starting
+// at offset, for a pager paged past the start
+func (m Model) previewPage(msg preview.PageMsg) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		if client == nil {
+			return PreviewMsg{Key: msg.Key, Page: true, Err: errNotConnected}
+		}
+		if msg.Bucket != m.currentBucket {
+			if c, err := client.ForBucket(m.ctx, msg.Bucket); err == nil {
+				client = c
 			}
-		})
+		}
+		r := aws.ByteRange{Offset: msg.Offset, Length: preview.MaxBytes}
+		data, contentType, err := client.ReadObjectRange(m.ctx, msg.Bucket, msg.Key, r, preview.MaxBytes)
+		return PreviewMsg{
+			Bucket: msg.Bucket, Key: msg.Key, ContentType: contentType, Size: msg.Size,
+			Data: data, Offset: msg.Offset, Page: true, Tail: msg.Tail, Err: err,
+		}
+	}
+}
+
+// compareObjects returns a command that fetches the details of two objects,
+// each through a client for its own bucket's region, for the comparison view
+func (m Model) compareObjects(leftBucket, leftKey, rightBucket, rightKey string) tea.Cmd {
+	return func() tea.Msg {
+		if m.client == nil {
+			return ComparisonMsg{Err: errNotConnected}
+		}
+		details := func(bucket, key string) (*aws.ObjectDetails, error) {
+			client, err := m.client.ForBucket(m.ctx, bucket)
+			if err != nil {
+				return nil, err
+			}
+			return client.GetObjectDetails(m.ctx, bucket, key)
+		}
+		left, err := details(leftBucket, leftKey)
+		if err != nil {
+			return ComparisonMsg{Err: err}
+		}
+		right, err := details(rightBucket, rightKey)
+		return ComparisonMsg{Left: left, Right: right, Err: err}
+	}
+}
+
+// listVersions returns a command that lists the versions under prefix for
+// the version view, which opens on focus's newest version
+func (m Model) listVersions(bucket, prefix, focus string) tea.Cmd {
+	return func() tea.Msg {
+		if m.client == nil {
+			return VersionsMsg{Bucket: bucket, Prefix: prefix, Err: errNotConnected}
+		}
+		versions, truncated, err := m.client.ListVersions(m.ctx, bucket, prefix)
+		return VersionsMsg{Bucket: bucket, Prefix: prefix, Focus: focus, Versions: versions, Truncated: truncated, Err: err}
+	}
+}
+
+// deleteVersion returns a command that removes one version or delete marker
+func (m Model) deleteVersion(bucket string, v aws.ObjectVersion) tea.Cmd {
+	return func() tea.Msg {
+		if m.client == nil {
+			return VersionDeletedMsg{Bucket: bucket, Version: v, Err: errNotConnected}
+		}
+		err := m.client.DeleteVersion(m.ctx, bucket, v.Key, v.VersionID)
+		return VersionDeletedMsg{Bucket: bucket, Version: v, Err: err}
+	}
+}
+
+// updateObjectHeaders returns a command that rewrites an inspected object's
+// headers and metadata, then reads its details back for the inspector
+func (m Model) updateObjectHeaders(d *aws.ObjectDetails, headers aws.ObjectHeaders) tea.Cmd {
+	bucket, key, etag := d.Bucket, d.Key, d.ETag
+	return func() tea.Msg {
+		if m.client == nil {
+			return MetadataUpdatedMsg{Key: key, Err: errNotConnected}
+		}
+		if err := m.client.UpdateObjectHeaders(m.ctx, bucket, key, etag, headers); err != nil {
+			return MetadataUpdatedMsg{Key: key, Err: err}
+		}
+		details, err := m.client.GetObjectDetails(m.ctx, bucket, key)
+		return MetadataUpdatedMsg{Key: key, Details: details, Err: err}
+	}
+}
+
+// saveSnapshot returns a command that lists everything under a prefix and
+// saves it as a snapshot
+func (m Model) saveSnapshot(bucket, prefix string) tea.Cmd {
+	store := m.inventoryStore
+	return func() tea.Msg {
+		objects, err := m.listInventory(bucket, prefix)
+		if err != nil {
+			return SnapshotSavedMsg{Err: err}
+		}
+		info, err := store.Add(inventory.NewSnapshot(bucket, prefix, objects))
+		if err != nil {
+			return SnapshotSavedMsg{Err: fmt.Errorf("failed to save snapshot: %w", err)}
+		}
+		return SnapshotSavedMsg{Info: info}
+	}
+}
+
+// removeSnapshot returns a command that deletes a saved snapshot
+func (m Model) removeSnapshot(info inventory.Info) tea.Cmd {
+	store := m.inventoryStore
+	return func() tea.Msg {
+		return SnapshotRemovedMsg{Info: info, Err: store.Remove(info.ID)}
+	}
+}
+
+// diffSnapshot returns a command that compares a saved snapshot with the
+// live listing of its prefix
+func (m Model) diffSnapshot(info inventory.Info) tea.Cmd {
+	store := m.inventoryStore
+	return func() tea.Msg {
+		old, err := store.Load(info.ID)
+		if err != nil {
+			return InventoryDiffMsg{Snapshot: info, Err: err}
+		}
+		objects, err := m.listInventory(info.Bucket, info.Prefix)
+		if err != nil {
+			return InventoryDiffMsg{Snapshot: info, Err: err}
+		}
+		current := inventory.NewSnapshot(info.Bucket, info.Prefix, objects)
+		return InventoryDiffMsg{Snapshot: info, Diff: inventory.Compare(old, current)}
+	}
+}
+
+// listInventory lists every object under a prefix, recursively
+func (m Model) listInventory(bucket, prefix string) ([]aws.S3Object, error) {
+	if m.client == nil {
+		return nil, errNotConnected
+	}
+	client := m.client
+	if c, err := client.ForBucket(m.ctx, bucket); err == nil {
+		client = c
+	}
+	return client.ListAllObjects(m.ctx, bucket, prefix)
+}
+
+// maxPartitionDepth bounds how many partition levels findLatestPartition
+// descends, e.g. 3 for YYYY/MM/DD/
+const maxPartitionDepth = 6
+
+// latestPartitionMsg carries the newest partition below a prefix
+type latestPartitionMsg struct {
+	bucket string
+	prefix string
+	err    error
+}
+
+// findLatestPartition returns a command that finds the newest date or
+// numbered partition at or above the current prefix, descending through
+// nested partition folders such as YYYY/MM/DD/
+func (m Model) findLatestPartition() tea.Cmd {
+	bucket, root := m.currentBucket, bookmarks.PartitionRoot(m.currentPrefix)
+	return func() tea.Msg {
+		if m.client == nil {
+			return latestPartitionMsg{err: errNotConnected}
+		}
+		prefix, err := latestPartition(m.ctx, m.client, bucket, root)
+		if err != nil {
+			return latestPartitionMsg{err: err}
+		}
+		if prefix == root {
+			return latestPartitionMsg{err: fmt.Errorf("no date or numbered partitions under s3://%s/%s", bucket, root)}
+		}
+		return latestPartitionMsg{bucket: bucket, prefix: prefix}
+	}
+}
 
-		go func() {
-			// Convert to aws.S3Object slice for the download manager
-			err := m.downloadMgr.DownloadMultiple(m.ctx, m.currentBucket, objects, m.currentPrefix, localDir)
+// latestPartition descends from root through the newest partition folder
+// at each level, returning root itself if it holds no partitions
+func latestPartition(ctx context.Context, client *aws.Client, bucket, root string) (string, error) {
+	prefix := root
+	for depth := 0; depth < maxPartitionDepth; depth++ {
+		// The first page shows whether prefix holds partitions at all, so a
+		// partition full of files isn't listed in full
+		page, next, err := client.ListObjectsPage(ctx, bucket, prefix, "/", "")
+		if err != nil {
+			return "", err
+		}
+		latest, ok := bookmarks.LatestPartition(folderKeys(page))
+		if !ok {
+			break
+		}
+		if next != "" {
+			all, err := client.ListObjects(ctx, bucket, prefix)
 			if err != nil {
-				progressChan <- download.Progress{Status: download.StatusFailed}
+				return "", err
+			}
+			latest, _ = bookmarks.LatestPartition(folderKeys(all))
+		}
+		prefix = latest
+	}
+	return prefix, nil
+}
+
+// folderKeys returns the keys of the folders among objects
+func folderKeys(objects []aws.S3Object) []string {
+	var keys []string
+	for _, obj := range objects {
+		if obj.IsPrefix {
+			keys = append(keys, obj.Key)
+		}
+	}
+	return keys
+}
+
+// freshnessInterval is how often bookmarks with a freshness rule are checked
+const freshnessInterval = 15 * time.Minute
+
+// freshnessTickMsg is sent when bookmark freshness is due to be checked
+type freshnessTickMsg struct{}
+
+// freshnessMsg carries the result of checking one bookmark's freshness
+type freshnessMsg struct {
+	id        string
+	name      string
+	expect    string        // the bookmark's rule, e.g. "24h"
+	every     time.Duration // the rule as a duration
+	freshness bookmarks.Freshness
+}
+
+func freshnessTick() tea.Cmd {
+	return tea.Tick(freshnessInterval, func(time.Time) tea.Msg {
+		return freshnessTickMsg{}
+	})
+}
+
+// startFreshnessChecks checks bookmark freshness now, and schedules the
+// periodic checks unless they're already running
+func (m *Model) startFreshnessChecks() tea.Cmd {
+	if m.freshnessTicking {
+		return m.checkFreshness()
+	}
+	m.freshnessTicking = true
+	return tea.Batch(m.checkFreshness(), freshnessTick())
+}
+
+// checkFreshness returns a command checking every bookmark with a
+// freshness rule
+func (m Model) checkFreshness() tea.Cmd {
+	if m.client == nil || m.bookmarkStore == nil {
+		return nil
+	}
+	var cmds []tea.Cmd
+	for _, b := range m.bookmarkStore.Active() {
+		if every := b.ExpectInterval(); every > 0 {
+			cmds = append(cmds, m.checkBookmarkFreshness(b, every))
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m Model) checkBookmarkFreshness(b bookmarks.Bookmark, every time.Duration) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		newest, err := newestObject(m.ctx, client, b)
+		return freshnessMsg{
+			id:        b.ID,
+			name:      b.DisplayName(),
+			expect:    b.ExpectEvery,
+			every:     every,
+			freshness: bookmarks.Freshness{Newest: newest, Checked: time.Now(), Err: err},
+		}
+	}
+}
+
+// newestObject returns when the newest object under a bookmark was last
+// modified, or the zero time if there are none. Like What's new, it lists
+// at most whatsNewPages pages of keys, and only the latest partition of a
+// partitioned prefix.
+func newestObject(ctx context.Context, client *aws.Client, b bookmarks.Bookmark) (time.Time, error) {
+	arrivals, _, err := recentArrivals(ctx, client, b)
+	if err != nil {
+		return time.Time{}, err
+	}
+	var newest time.Time
+	for _, a := range arrivals {
+		if a.LastModified.After(newest) {
+			newest = a.LastModified
+		}
+	}
+	return newest, nil
+}
+
+// What's new lists at most whatsNewPages pages of keys under each bookmark,
+// keeping its whatsNewPerBookmark newest objects, and shows the
+// whatsNewShown newest of all, listing whatsNewListings bookmarks at once
+const (
+	whatsNewPages       = 5
+	whatsNewPerBookmark = 50
+	whatsNewShown       = 200
+	whatsNewListings    = 4
+)
+
+// loadWhatsNew returns a command listing the newest objects under every
+// bookmark
+func (m Model) loadWhatsNew() tea.Cmd {
+	client := m.client
+	var marks []bookmarks.Bookmark
+	if m.bookmarkStore != nil {
+		marks = m.bookmarkStore.Active()
+	}
+	return func() tea.Msg {
+		if client == nil {
+			return WhatsNewMsg{Err: errNotConnected}
+		}
+		var (
+			mu       sync.Mutex
+			wg       sync.WaitGroup
+			arrivals []bookmarks.Arrival
+			notes    []string
+		)
+		sem := make(chan struct{}, whatsNewListings)
+		for _, b := range marks {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				sem <- struct{}{}
+				defer func() { <-sem }()
+				recent, partial, err := recentArrivals(m.ctx, client, b)
+				mu.Lock()
+				defer mu.Unlock()
+				switch {
+				case err != nil:
+					notes = append(notes, b.DisplayName()+": "+security.SanitizeError(err))
+				case partial:
+					notes = append(notes, fmt.Sprintf("%s: only the first %s keys listed", b.DisplayName(), humanize.Comma(whatsNewPages*1000)))
+				}
+				arrivals = append(arrivals, recent...)
+			}()
+		}
+		wg.Wait()
+		slices.Sort(notes)
+		return WhatsNewMsg{Arrivals: bookmarks.MergeArrivals(arrivals, whatsNewShown), Notes: notes}
+	}
+}
+
+// recentArrivals returns the newest objects under a bookmark, from its
+// first whatsNewPages pages of keys, and whether there were more.
+// Partitioned prefixes only have their latest partition listed.
+func recentArrivals(ctx context.Context, client *aws.Client, b bookmarks.Bookmark) ([]bookmarks.Arrival, bool, error) {
+	if c, err := client.ForBucket(ctx, b.Bucket); err == nil {
+		client = c
+	}
+	latest, err := latestPartition(ctx, client, b.Bucket, b.Prefix)
+	if err != nil {
+		return nil, false, err
+	}
+	var arrivals []bookmarks.Arrival
+	token := ""
+	for range whatsNewPages {
+		page, next, err := client.ListObjectsPage(ctx, b.Bucket, latest, "", token)
+		if err != nil {
+			return nil, false, err
+		}
+		for _, obj := range page {
+			if obj.IsPrefix || strings.HasSuffix(obj.Key, "/") {
+				continue // folder markers
 			}
-			close(progressChan)
-		}()
+			arrivals = append(arrivals, bookmarks.Arrival{Bookmark: b, Key: obj.Key, Size: obj.Size, LastModified: obj.LastModified})
+		}
+		arrivals = bookmarks.MergeArrivals(arrivals, whatsNewPerBookmark)
+		if next == "" {
+			return arrivals, false, nil
+		}
+		token = next
+	}
+	return arrivals, true, nil
+}
 
-		return downloadStartedMsg{progressChan: progressChan}
+// sendStaleNotification returns a command showing a desktop notification
+// for a bookmark that went stale
+func sendStaleNotification(name, expect string) tea.Cmd {
+	return func() tea.Msg {
+		if err := notify.Send("stui: "+name+" is stale", "No new objects for over "+expect); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return nil
+	}
+}
+
+// Restores are polled every restorePollInterval once they're due, at most
+// maxRestorePolls objects at a time; expedited ones are due soonest
+const (
+	restorePollInterval = time.Minute
+	maxRestorePolls     = 20
+)
+
+// restoreWatch is an archived object whose restore was requested
+type restoreWatch struct {
+	bucket string
+	key    string
+	ready  time.Time // when the restore should be done, going by its tier
+	next   time.Time // when to poll it next
+}
+
+// backgroundPollInterval is how often running background jobs are checked
+const backgroundPollInterval = 2 * time.Second
+
+func backgroundTick() tea.Cmd {
+	return tea.Tick(backgroundPollInterval, func(time.Time) tea.Msg {
+		return backgroundTickMsg{}
+	})
+}
+
+// loadBackgroundJobs returns a command that reads the state of background
+// jobs
+func (m Model) loadBackgroundJobs() tea.Cmd {
+	store := m.backgroundStore
+	if store == nil {
+		return nil
+	}
+	return func() tea.Msg {
+		jobs, err := store.List()
+		return BackgroundJobsMsg{Jobs: jobs, Err: err}
+	}
+}
+
+// startBackgroundJob runs a saved job in a new stui process, with this
+// session's profile and region, so it carries on after stui quits
+func (m Model) startBackgroundJob(job jobs.Job) tea.Cmd {
+	store := m.backgroundStore
+	var flags []string
+	if m.profile != "" {
+		flags = append(flags, "-profile", m.profile)
+	}
+	if m.region != "" {
+		flags = append(flags, "-region", m.region)
+	}
+	if m.settings != nil && m.settings.FIPS {
+		flags = append(flags, "-fips")
+	}
+	return func() tea.Msg {
+		if store == nil {
+			return ErrorMsg{Err: errors.New("background jobs aren't available")}
+		}
+		state, err := store.Start(job, flags)
+		return BackgroundStartedMsg{Job: state, Err: err}
+	}
+}
+
+func restoreTick() tea.Cmd {
+	return tea.Tick(restorePollInterval, func(time.Time) tea.Msg {
+		return restoreTickMsg{}
+	})
+}
+
+// watchRestores starts watching restored objects until they're ready. It
+// returns the command that starts polling, or nil if it's already running.
+func (m *Model) watchRestores(bucket, tier string, objs []aws.S3Object) tea.Cmd {
+	now := time.Now()
+	for _, obj := range objs {
+		ready := now.Add(aws.RestoreTime(tier, obj.StorageClass))
+		m.restoreWatches[bucket+"/"+obj.Key] = &restoreWatch{
+			bucket: bucket,
+			key:    obj.Key,
+			ready:  ready,
+			// Nothing's restored much before its tier says, so only poll near then
+			next: ready.Add(-aws.RestoreTime(tier, obj.StorageClass) / 5),
+		}
+	}
+	if m.restorePolling || len(m.restoreWatches) == 0 {
+		return nil
+	}
+	m.restorePolling = true
+	return restoreTick()
+}
+
+// pollRestores returns a command polling the watched restores that are due
+func (m *Model) pollRestores() tea.Cmd {
+	if m.client == nil {
+		return nil
+	}
+	now := time.Now()
+	var cmds []tea.Cmd
+	for _, w := range m.restoreWatches {
+		if now.Before(w.next) {
+			continue
+		}
+		w.next = now.Add(restorePollInterval)
+		cmds = append(cmds, m.pollRestore(w.bucket, w.key))
+		if len(cmds) == maxRestorePolls {
+			break
+		}
+	}
+	return tea.Batch(cmds...)
+}
+
+func (m Model) pollRestore(bucket, key string) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		if c, err := client.ForBucket(m.ctx, bucket); err == nil {
+			client = c
+		}
+		status, err := client.GetRestoreStatus(m.ctx, bucket, key)
+		return restoreStatusMsg{bucket: bucket, key: key, status: status, err: err}
+	}
+}
+
+// restoreBadge summarizes the watched restores for the status bar, e.g.
+// "⏳ 3 restoring · next ~2h10m", or returns "" if there are none
+func (m Model) restoreBadge() string {
+	if len(m.restoreWatches) == 0 {
+		return ""
+	}
+	var next time.Time
+	for _, w := range m.restoreWatches {
+		if next.IsZero() || w.ready.Before(next) {
+			next = w.ready
+		}
+	}
+	eta := "any moment"
+	if left := time.Until(next); left > 0 {
+		eta = "~" + strings.TrimSuffix(left.Round(time.Minute).String(), "0s")
+	}
+	return fmt.Sprintf("⏳ %d restoring · next %s", len(m.restoreWatches), eta)
+}
+
+// sendRestoredNotification returns a command showing a desktop
+// notification for an object that finished restoring
+func sendRestoredNotification(key string, until time.Time) tea.Cmd {
+	return func() tea.Msg {
+		if err := notify.Send("stui: "+path.Base(key)+" is restored", "Downloadable until "+until.Local().Format("Mon Jan 2 15:04")); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return nil
+	}
+}
+
+// presignObject returns a command that presigns a download URL for key, or
+// an upload URL if upload is set, and copies it to the clipboard
+func (m Model) presignObject(key string, upload bool, ttl time.Duration) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return PresignedMsg{Key: key, Err: errNotConnected}
+		}
+		expires := time.Now().Add(ttl)
+		presign := m.client.PresignGetObject
+		if upload {
+			presign = m.client.PresignPutObject
+		}
+		url, err := presign(m.ctx, bucket, key, ttl)
+		if err != nil {
+			return PresignedMsg{Key: key, Err: err}
+		}
+		return PresignedMsg{Key: key, URL: url, Expires: expires, Upload: upload, Copied: clipboard.WriteAll(url) == nil}
+	}
+}
+
+// openConsole returns a command that opens a bucket, or a folder or object
+// in it, in the AWS console of the client's partition, in the bucket's own
+// region
+func (m Model) openConsole(bucket, key string) tea.Cmd {
+	client := m.client
+	return func() tea.Msg {
+		location := "s3://" + bucket + "/" + key
+		if client == nil {
+			return ConsoleOpenedMsg{Location: location, Err: errNotConnected}
+		}
+		region, err := client.GetBucketRegion(m.ctx, bucket)
+		if err != nil {
+			region = client.Region
+		}
+		url := client.Partition().ConsoleURL(region, bucket, key)
+		if err := notify.OpenURL(url); err != nil {
+			return ConsoleOpenedMsg{Location: location, URL: url, Copied: clipboard.WriteAll(url) == nil, Err: err}
+		}
+		return ConsoleOpenedMsg{Location: location, URL: url}
+	}
+}
+
+// createObject returns a command that uploads a new object to the current bucket
+func (m Model) createObject(key string, body []byte, contentType string) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return ObjectCreatedMsg{Bucket: bucket, Key: key, Err: errNotConnected}
+		}
+		err := m.client.PutObject(m.ctx, bucket, key, body, contentType, m.encryption)
+		return ObjectCreatedMsg{Bucket: bucket, Key: key, Err: err}
+	}
+}
+
+// incompleteUploadMsg reports an interrupted multipart upload for a key
+// about to be uploaded, if there is one
+type incompleteUploadMsg struct {
+	key    string
+	upload *aws.IncompleteUpload
+}
+
+// findIncompleteUpload looks for an interrupted multipart upload of key that
+// could be resumed instead of starting over
+func (m Model) findIncompleteUpload(key string) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return incompleteUploadMsg{key: key}
+		}
+		// Listing uploads needs its own permission; without it, just upload
+		upload, _ := m.client.FindIncompleteUpload(m.ctx, bucket, key)
+		return incompleteUploadMsg{key: key, upload: upload}
+	}
+}
+
+// uploadConflictMsg reports the object an upload would replace, if any
+type uploadConflictMsg struct {
+	key      string
+	existing *aws.S3Object
+}
+
+// checkUploadConflict looks for an object already at key before uploading
+// over it, unless uploads are configured to always overwrite
+func (m Model) checkUploadConflict(key string) tea.Cmd {
+	if m.settings.Transfers.UploadConflict == config.UploadConflictOverwrite {
+		return m.findIncompleteUpload(key)
+	}
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return uploadConflictMsg{key: key}
+		}
+		// Without permission to check, upload as before
+		existing, _ := m.client.LookupObject(m.ctx, bucket, key)
+		return uploadConflictMsg{key: key, existing: existing}
+	}
+}
+
+// abortUpload discards the parts of an interrupted multipart upload
+func (m Model) abortUpload(upload *aws.IncompleteUpload) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return nil
+		}
+		if err := m.client.AbortUpload(m.ctx, bucket, upload.Key, upload.UploadID); err != nil {
+			return ErrorMsg{Err: err}
+		}
+		return nil
+	}
+}
+
+// renameObject returns a command that moves an object to a new key
+func (m Model) renameObject(oldKey, newKey string) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return ObjectRenamedMsg{Bucket: bucket, OldKey: oldKey, NewKey: newKey, Err: errNotConnected}
+		}
+		err := m.client.RenameObject(m.ctx, bucket, oldKey, newKey)
+		return ObjectRenamedMsg{Bucket: bucket, OldKey: oldKey, NewKey: newKey, Err: err}
+	}
+}
+
+// deleteObject returns a command that deletes an object from the current bucket
+func (m Model) deleteObject(key string) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return ObjectDeletedMsg{Bucket: bucket, Key: key, Err: errNotConnected}
+		}
+		err := m.client.DeleteObject(m.ctx, bucket, key)
+		return ObjectDeletedMsg{Bucket: bucket, Key: key, Err: err}
+	}
+}
+
+// verifyObject returns a command that compares a local file to an object
+func (m Model) verifyObject(key, localPath string) tea.Cmd {
+	bucket := m.currentBucket
+	return func() tea.Msg {
+		if m.client == nil {
+			return VerifiedMsg{Key: key, LocalPath: localPath, Err: errNotConnected}
+		}
+		result, err := download.Verify(m.ctx, m.client, bucket, key, localPath)
+		return VerifiedMsg{Key: key, LocalPath: localPath, Result: result, Err: err}
 	}
 }
 
@@ -315,30 +1809,29 @@ func (m Model) loadDemoBuckets() tea.Cmd {
 	}
 }
 
-func (m Model) loadDemoObjects() tea.Cmd {
-	return func() tea.Msg {
-		var objects []aws.S3Object
+// demoLister serves mock listings to the browser in demo mode
+type demoLister struct{}
 
-		if m.currentPrefix == "" {
-			// Root level - show folders
-			objects = []aws.S3Object{
-				{Key: "2024-01-01/", IsPrefix: true},
-				{Key: "2024-01-02/", IsPrefix: true},
-				{Key: "2024-01-03/", IsPrefix: true},
-				{Key: "config.json", Size: 1024, LastModified: time.Now().AddDate(0, 0, -1), ETag: "abc123"},
-				{Key: "readme.txt", Size: 256, LastModified: time.Now().AddDate(0, 0, -7), ETag: "def456"},
-			}
-		} else {
-			// Inside a folder - show files
-			objects = []aws.S3Object{
-				{Key: m.currentPrefix + "data-001.parquet", Size: 1024 * 1024 * 50, LastModified: time.Now().AddDate(0, 0, -1), ETag: "file1"},
-				{Key: m.currentPrefix + "data-002.parquet", Size: 1024 * 1024 * 75, LastModified: time.Now().AddDate(0, 0, -1), ETag: "file2"},
-				{Key: m.currentPrefix + "data-003.parquet", Size: 1024 * 1024 * 25, LastModified: time.Now().AddDate(0, 0, -1), ETag: "file3"},
-				{Key: m.currentPrefix + "metadata.json", Size: 2048, LastModified: time.Now().AddDate(0, 0, -1), ETag: "meta1"},
-			}
-		}
+func (demoLister) ListObjects(ctx context.Context, bucket, prefix string) ([]aws.S3Object, error) {
+	var objects []aws.S3Object
 
-		return ObjectsLoadedMsg{Objects: objects, Prefix: m.currentPrefix}
+	if prefix == "" {
+		// Root level - show folders
+		objects = []aws.S3Object{
+			{Key: "2024-01-01/", IsPrefix: true},
+			{Key: "2024-01-02/", IsPrefix: true},
+			{Key: "2024-01-03/", IsPrefix: true},
+			{Key: "config.json", Size: 1024, LastModified: time.Now().AddDate(0, 0, -1), ETag: "abc123", Owner: "alice"},
+			{Key: "readme.txt", Size: 256, LastModified: time.Now().AddDate(0, 0, -7), ETag: "def456", Owner: "bob"},
+		}
+	} else {
+		// Inside a folder - show files
+		objects = []aws.S3Object{
+			{Key: prefix + "data-001.parquet", Size: 1024 * 1024 * 50, LastModified: time.Now().AddDate(0, 0, -1), ETag: "file1", Owner: "etl"},
+			{Key: prefix + "data-002.parquet", Size: 1024 * 1024 * 75, LastModified: time.Now().AddDate(0, 0, -1), ETag: "file2", Owner: "etl"},
+			{Key: prefix + "data-003.parquet", Size: 1024 * 1024 * 25, LastModified: time.Now().AddDate(0, 0, -1), ETag: "file3", Owner: "etl"},
+			{Key: prefix + "metadata.json", Size: 2048, LastModified: time.Now().AddDate(0, 0, -1), ETag: "meta1", Owner: "alice"},
+		}
 	}
+	return objects, nil
 }
-
@@ -1,20 +1,38 @@
 package tui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path"
 	"path/filepath"
+	"sort"
+	"strconv"
 	"strings"
 	"time"
 
+	"github.com/atotto/clipboard"
 	"github.com/charmbracelet/bubbles/key"
 	tea "github.com/charmbracelet/bubbletea"
+	"github.com/dustin/go-humanize"
 	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/bookmarks"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/daemon"
+	"github.com/natevick/stui/internal/doctor"
 	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/history"
+	"github.com/natevick/stui/internal/jobs"
 	"github.com/natevick/stui/internal/security"
 	"github.com/natevick/stui/internal/views/bookmarksview"
-	"github.com/natevick/stui/internal/views/browser"
 	"github.com/natevick/stui/internal/views/buckets"
+	"github.com/natevick/stui/internal/views/dashboard"
+	"github.com/natevick/stui/internal/views/preview"
 	"github.com/natevick/stui/internal/views/profiles"
+	"github.com/natevick/stui/internal/views/transfers"
+	"github.com/natevick/stui/internal/views/whatsnew"
+	"github.com/natevick/stui/pkg/s3browser"
 )
 
 // Update handles all messages
@@ -24,6 +42,9 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
 		m.SetSize(msg.Width, msg.Height)
+		if m.preview != nil {
+			return m, m.preview.DrawImage()
+		}
 		return m, nil
 
 	case tea.KeyMsg:
@@ -32,8 +53,158 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handlePromptKey(msg)
 		}
 
+		// The inspector stays open until it's closed
+		if m.inspected != nil {
+			switch msg.String() {
+			case "esc", "enter", "i", "q", "backspace":
+				m.inspected = nil
+			case "e":
+				m.showEditHeaderPrompt()
+			}
+			return m, nil
+		}
+
+		// The preview stays open until it's closed
+		if m.preview != nil {
+			switch msg.String() {
+			case "esc", "v", "q", "backspace":
+				m.preview = nil
+				return m, nil
+			}
+			p, cmd := m.preview.Update(msg)
+			m.preview = &p
+			return m, cmd
+		}
+
+		// The dashboard stays open until it's closed
+		if m.dashboard != nil {
+			switch msg.String() {
+			case "esc", "s", "q", "backspace":
+				m.dashboard = nil
+				return m, nil
+			case "r":
+				if !m.dashboard.Loading() {
+					d := dashboard.New(m.profile)
+					d.SetSize(m.width-6, m.height-4)
+					m.dashboard = &d
+					return m, m.summarizeBuckets()
+				}
+				return m, nil
+			}
+			d, cmd := m.dashboard.Update(msg)
+			m.dashboard = &d
+			return m, cmd
+		}
+
+		// The about screen stays open until it's closed
+		if m.about != nil {
+			switch msg.String() {
+			case "esc", "!", "q", "backspace":
+				m.about = nil
+				return m, nil
+			case "r":
+				if !m.about.Checking() {
+					return m, m.openAbout()
+				}
+				return m, nil
+			case "y":
+				var report strings.Builder
+				report.WriteString(doctor.Version(m.version) + "\n\n")
+				if err := doctor.Write(&report, m.about.Sections()); err == nil {
+					if err := clipboard.WriteAll(report.String()); err != nil {
+						m.errorMsg = security.SanitizeErrorGeneric(err, "Copying")
+						m.errorTimeout = time.Now().Add(5 * time.Second)
+					} else {
+						m.statusMsg = "Copied the diagnostics report to the clipboard"
+					}
+				}
+				m.about = nil
+				return m, nil
+			}
+			a, cmd := m.about.Update(msg)
+			m.about = &a
+			return m, cmd
+		}
+
+		// What's new stays open until it's closed
+		if m.whatsNew != nil {
+			switch msg.String() {
+			case "esc", "q", "backspace":
+				m.whatsNew = nil
+				return m, nil
+			case "r":
+				if !m.whatsNew.Loading() {
+					w := whatsnew.New()
+					w.SetSize(m.width-6, m.height-4)
+					m.whatsNew = &w
+					return m, m.loadWhatsNew()
+				}
+				return m, nil
+			}
+			w, cmd := m.whatsNew.Update(msg)
+			m.whatsNew = &w
+			return m, cmd
+		}
+
+		// The snapshot diff stays open until it's closed
+		if m.inventoryDiff != nil {
+			switch msg.String() {
+			case "esc", "enter", "I", "q", "backspace":
+				m.inventoryDiff = nil
+			case "up", "k":
+				m.inventoryDiffOffset = max(m.inventoryDiffOffset-1, 0)
+			case "down", "j":
+				m.inventoryDiffOffset = min(m.inventoryDiffOffset+1, m.diffMaxOffset())
+			case "pgup", "b":
+				m.inventoryDiffOffset = max(m.inventoryDiffOffset-m.diffPageSize(), 0)
+			case "pgdown", " ":
+				m.inventoryDiffOffset = min(m.inventoryDiffOffset+m.diffPageSize(), m.diffMaxOffset())
+			case "home", "g":
+				m.inventoryDiffOffset = 0
+			}
+			return m, nil
+		}
+
+		// The comparison stays open until it's closed
+		if m.comparison != nil {
+			switch msg.String() {
+			case "esc", "enter", "M", "q", "backspace":
+				m.comparison = nil
+			}
+			return m, nil
+		}
+
+		// The version view stays open until it's closed
+		if m.versions != nil {
+			return m.handleVersionsKey(msg)
+		}
+
+		// c and w copy a presigned URL as a command; any key dismisses it
+		if m.sharedURL != "" {
+			command := ""
+			switch {
+			case msg.String() == "c" && m.sharedCurl != "":
+				command = m.sharedCurl
+			case msg.String() == "w" && m.sharedPwsh != "":
+				command = m.sharedPwsh
+			}
+			if command != "" {
+				if err := clipboard.WriteAll(command); err != nil {
+					m.errorMsg = security.SanitizeErrorGeneric(err, "Copying")
+					m.errorTimeout = time.Now().Add(5 * time.Second)
+				} else {
+					m.statusMsg = "Copied the command to the clipboard"
+				}
+			}
+			m.sharedURL, m.sharedNote, m.sharedCurl, m.sharedPwsh = "", "", "", ""
+			return m, nil
+		}
+
 		// Global key handling
 		switch {
+		case m.inlineEditing():
+			// Inline edits take every key until they finish
+
 		case key.Matches(msg, m.keys.Quit):
 			m.cancel()
 			return m, tea.Quit
@@ -42,6 +213,12 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.showHelp = !m.showHelp
 			return m, nil
 
+		case key.Matches(msg, m.keys.Tab) && m.activeView == ViewLocal:
+			// Tab switches panes in the commander layout
+			m.localFocus = !m.localFocus
+			m.localView.SetFocused(m.localFocus)
+			return m, nil
+
 		case key.Matches(msg, m.keys.Tab), key.Matches(msg, m.keys.Right):
 			m.nextView()
 			return m, nil
@@ -62,10 +239,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.activeView = ViewBookmarks
 			return m, nil
 
+		case key.Matches(msg, m.keys.Local):
+			m.activeView = ViewLocal
+			return m, nil
+
+		case key.Matches(msg, m.keys.Transfers):
+			m.activeView = ViewTransfers
+			return m, nil
+
 		case key.Matches(msg, m.keys.Cancel):
-			if m.activeView == ViewDownload && m.downloadView.IsActive() {
-				if m.downloadMgr != nil {
-					m.downloadMgr.Cancel()
+			if m.activeView == ViewTransfers && m.transfersView.SelectedActive() {
+				if id, ok := m.transfersView.SelectedJob(); ok && m.queue != nil {
+					m.queue.Cancel(id)
 				}
 				return m, nil
 			}
@@ -77,10 +262,22 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		case key.Matches(msg, m.keys.Refresh):
 			return m.handleRefresh()
+
+		case key.Matches(msg, m.keys.SavedJobs):
+			m.showSavedJobsPrompt()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Settings):
+			m.showSettingsPrompt()
+			return m, nil
+
+		case key.Matches(msg, m.keys.About):
+			return m, m.openAbout()
 		}
 
 	case demoReadyMsg:
 		// Load mock data for demo mode
+		m.browserView.SetClient(demoLister{})
 		return m, m.loadDemoBuckets()
 
 	case profilesReadyMsg:
@@ -100,450 +297,3637 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 	case awsClientReadyMsg:
 		m.client = msg.client
-		m.downloadMgr = download.NewManager(m.client, 5)
+		m.browserView.SetClient(m.client)
+		m.downloadOpts = m.settings.Transfers.DownloadOptions()
+		if msg.mirrorsErr != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.mirrorsErr, "Connecting to bucket mirrors")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+		client, transfers, downloadOpts, mirrors := m.client, m.settings.Transfers, m.downloadOpts, msg.mirrors
+		m.queue = download.NewQueue(transfers.MaxJobs, func() *download.Manager {
+			mgr := download.NewManager(client, 5)
+			mgr.SetStallPolicy(transfers.StallTimeout(), transfers.RetryStalled)
+			mgr.SetUploadOptions(aws.UploadOptions{
+				PartSize:    transfers.UploadPartSize(),
+				Concurrency: transfers.UploadConcurrency,
+			})
+			mgr.SetDownloadOptions(downloadOpts)
+			mgr.SetScheduleOrder(transfers.Order())
+			mgr.SetCollisionPolicy(transfers.Collisions())
+			mgr.SetPathPolicy(transfers.LocalPaths())
+			mgr.SetMirrors(mirrors)
+			return mgr
+		})
+		m.normalization = m.settings.Transfers.NameNormalization()
+		m.symlinkPolicy = m.settings.Transfers.Symlinks()
+		m.encryption = m.settings.ForProfile(m.profile).DefaultEncryption()
+		m.uploadTags = m.settings.Transfers.UploadTags
+
+		freshness := m.startFreshnessChecks()
 
 		// If a bucket was specified on command line, go directly to it
 		if m.initialBucket != "" {
 			m.currentBucket = m.initialBucket
-			m.browserView.SetBucket(m.initialBucket)
-			m.browserView.SetLoading(true)
-			return m, tea.Batch(m.loadBuckets(), m.loadObjects())
+			return m, tea.Batch(m.loadBuckets(), m.openLocation(m.initialBucket, ""), m.listenForJobs(m.queue), freshness)
 		}
-		return m, m.loadBuckets()
+		return m, tea.Batch(m.loadBuckets(), m.listenForJobs(m.queue), freshness)
 
 	case bookmarkStoreReadyMsg:
 		m.bookmarkStore = msg.store
 		m.bookmarksView.SetStore(m.bookmarkStore)
+		return m, m.checkFreshness()
+
+	case freshnessTickMsg:
+		return m, tea.Batch(m.checkFreshness(), freshnessTick())
+
+	case freshnessMsg:
+		m.bookmarksView.SetFreshness(msg.id, msg.freshness)
+		wasStale := m.staleBookmarks[msg.id]
+		stale := msg.freshness.Stale(msg.every)
+		m.staleBookmarks[msg.id] = stale
+		if stale && !wasStale {
+			m.warningMsg = fmt.Sprintf("No new objects under %s for over %s", msg.name, msg.expect)
+			m.errorTimeout = time.Now().Add(10 * time.Second)
+			if m.settings.NotifyStale {
+				return m, sendStaleNotification(msg.name, msg.expect)
+			}
+		}
 		return m, nil
 
-	case BucketsLoadedMsg:
+	case jobStoreReadyMsg:
+		m.jobStore = msg.store
+		return m, nil
+
+	case backgroundStoreReadyMsg:
+		m.backgroundStore = msg.store
+		return m, m.loadBackgroundJobs()
+
+	case backgroundTickMsg:
+		m.backgroundWatch = false
+		return m, m.loadBackgroundJobs()
+
+	case BackgroundJobsMsg:
 		if msg.Err != nil {
-			m.bucketsView.SetError(msg.Err)
-			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Loading buckets")
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Reading background jobs")
 			m.errorTimeout = time.Now().Add(5 * time.Second)
-		} else {
-			m.bucketsView.SetBuckets(msg.Buckets)
+			return m, nil
 		}
-		return m, nil
+		return m, m.updateBackgroundJobs(msg.Jobs)
 
-	case ObjectsLoadedMsg:
+	case BackgroundStartedMsg:
 		if msg.Err != nil {
-			m.browserView.SetError(msg.Err)
-			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Loading objects")
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Starting background job")
 			m.errorTimeout = time.Now().Add(5 * time.Second)
-		} else {
-			m.browserView.SetObjects(msg.Objects)
+			return m, nil
 		}
+		m.statusMsg = fmt.Sprintf("Running %s in the background (pid %d); it carries on after stui quits", msg.Job.Name, msg.Job.PID)
+		m.activeView = ViewTransfers
+		return m, m.loadBackgroundJobs()
+
+	case inventoryStoreReadyMsg:
+		m.inventoryStore = msg.store
 		return m, nil
 
-	case DownloadProgressMsg:
-		m.downloadView.SetProgress(msg.Progress)
+	case historyStoreReadyMsg:
+		m.historyStore = msg.store
+		m.transfersView.SetEarlier(msg.store.Jobs())
 		return m, nil
 
-	case downloadStartedMsg:
-		// Start listening for progress updates
-		return m, m.listenForProgress(msg.progressChan)
+	case SnapshotSavedMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Taking snapshot")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		info := msg.Info
+		m.statusMsg = fmt.Sprintf("Saved snapshot of %s: %d objects, %s", info.Path(), info.Objects, humanize.Bytes(uint64(info.Size)))
+		return m, nil
 
-	case downloadProgressTickMsg:
-		m.downloadView.SetProgress(msg.progress)
-		if msg.done {
-			if msg.progress.Status == download.StatusCompleted {
-				m.statusMsg = fmt.Sprintf("Downloaded %d files", msg.progress.CompletedFiles)
-			} else if msg.progress.Status == download.StatusFailed {
-				m.errorMsg = "Download failed"
-				m.errorTimeout = time.Now().Add(5 * time.Second)
-			}
+	case SnapshotRemovedMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Deleting snapshot")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
 			return m, nil
 		}
-		return m, m.listenForProgress(msg.progressChan)
+		m.statusMsg = fmt.Sprintf("Deleted the snapshot of %s from %s", msg.Info.Path(), msg.Info.TakenAt.Local().Format("2006-01-02 15:04"))
+		return m, nil
 
-	case ErrorMsg:
+	case InventoryDiffMsg:
 		if msg.Err != nil {
-			m.errorMsg = security.SanitizeError(msg.Err)
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Comparing with snapshot")
 			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
 		}
+		m.statusMsg = ""
+		m.inventoryDiff = &msg
+		m.inventoryDiffOffset = 0
 		return m, nil
 
-	case TickMsg:
-		// Clear error after timeout
-		if m.errorMsg != "" && time.Now().After(m.errorTimeout) {
-			m.errorMsg = ""
+	case VersionsMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Listing versions")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
 		}
-		return m, tickCmd()
-	}
+		m.statusMsg = ""
+		if m.versions != nil && m.versions.Bucket == msg.Bucket && m.versions.Prefix == msg.Prefix {
+			// Relisted after a change; stay where we were
+			m.versionCursor = max(min(m.versionCursor, len(msg.Versions)-1), 0)
+		} else {
+			m.versionCursor = 0
+			for i, v := range msg.Versions {
+				if v.Key == msg.Focus {
+					m.versionCursor = i
+					break
+				}
+			}
+		}
+		m.versions = &msg
+		return m, nil
 
-	// Route to active view
-	switch m.activeView {
-	case ViewProfiles:
-		var cmd tea.Cmd
-		m.profilesView, cmd = m.profilesView.Update(msg)
-		cmds = append(cmds, cmd)
+	case VersionDeletedMsg:
+		v := msg.Version
+		undelete := v.DeleteMarker && v.IsLatest
+		if msg.Err != nil {
+			op := "Deleting version"
+			if undelete {
+				op = "Undeleting"
+			}
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, op)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if undelete {
+			m.statusMsg = "Undeleted " + v.Key
+		} else {
+			m.statusMsg = fmt.Sprintf("Deleted version %s of %s for good", versionAnswer(v), path.Base(v.Key))
+		}
+		if m.versions != nil && m.versions.Bucket == msg.Bucket {
+			cmds = append(cmds, m.listVersions(msg.Bucket, m.versions.Prefix, ""))
+		}
+		cmds = append(cmds, m.refreshObjects(msg.Bucket, []string{v.Key}))
+		return m, tea.Batch(cmds...)
 
-	case ViewBuckets:
+	case BucketsLoadedMsg:
+		if msg.Err != nil {
+			m.bucketsView.SetError(msg.Err)
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Loading buckets")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		} else {
+			m.bucketsView.SetBuckets(msg.Buckets)
+		}
+		return m, nil
+
+	case s3browser.LoadedMsg:
+		// Listings for a prefix we've since navigated away from are dropped
+		if msg.Err != nil && m.browserView.IsCurrent(msg) {
+			doing := "Loading objects"
+			if msg.Refresh {
+				doing = "Refreshing objects"
+			}
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, doing)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+		// Flat listings return a command for their next page
 		var cmd tea.Cmd
-		m.bucketsView, cmd = m.bucketsView.Update(msg)
-		cmds = append(cmds, cmd)
+		m.browserView, cmd = m.browserView.Update(msg)
+		return m, cmd
 
-		// Check for actions
-		action, bucket := m.bucketsView.ConsumeAction()
-		switch action {
-		case buckets.ActionSelect:
-			m.currentBucket = bucket
-			m.currentPrefix = ""
-			m.browserView.SetBucket(bucket)
-			m.browserView.SetLoading(true)
-			m.activeView = ViewBrowser
-			cmds = append(cmds, m.loadObjects())
+	case s3browser.ContentTypesMsg:
+		m.browserView, _ = m.browserView.Update(msg)
+		return m, nil
 
-		case buckets.ActionBookmark:
-			m.showBucketBookmarkPrompt(bucket)
+	case uploadConflictMsg:
+		if msg.key != m.pendingUploadKey || m.showPrompt {
+			return m, nil
 		}
+		if msg.existing == nil {
+			return m, m.findIncompleteUpload(msg.key)
+		}
+		m.showUploadConflictPrompt(msg.existing)
+		return m, nil
 
-	case ViewBrowser:
-		var cmd tea.Cmd
-		m.browserView, cmd = m.browserView.Update(msg)
-		cmds = append(cmds, cmd)
+	case incompleteUploadMsg:
+		if msg.key != m.pendingUploadKey || m.showPrompt {
+			return m, nil
+		}
+		if msg.upload == nil || len(msg.upload.Parts) == 0 {
+			m.showStorageClassPrompt("upload-class", msg.key)
+			return m, nil
+		}
+		m.pendingResume = msg.upload
+		m.showResumePrompt(msg.upload)
+		return m, nil
 
-		// Check for actions
-		action, obj, objs := m.browserView.ConsumeAction()
-		switch action {
-		case browser.ActionNavigate, browser.ActionBack:
-			m.currentPrefix = m.browserView.Prefix()
-			m.browserView.SetLoading(true)
-			cmds = append(cmds, m.loadObjects())
+	case syncUpToDateMsg:
+		m.activeView = ViewBrowser
+		m.statusMsg = fmt.Sprintf("Already up to date (%d files)", msg.unchanged)
+		return m, nil
 
-		case browser.ActionDownload:
-			if len(objs) > 0 {
-				m.showMultiDownloadPrompt(objs)
-			} else {
-				m.showDownloadPrompt(obj)
-			}
+	case jobReadyMsg:
+		// A prompt opened while the transfer was planned keeps the screen;
+		// the transfer is queued unnamed and can be named from Transfers
+		if m.showPrompt {
+			return m, m.submitJob(msg)
+		}
+		m.showJobNamePrompt(msg)
+		return m, nil
 
-		case browser.ActionSync:
-			m.showSyncPrompt()
+	case jobSubmittedMsg:
+		// The transfers view follows the most recently started job
+		m.transfersView.SetJobs(m.queue.Jobs())
+		m.transfersView.Follow(msg.id)
+		return m, nil
 
-		case browser.ActionBookmark:
-			m.showBookmarkPrompt()
+	case jobEventMsg:
+		m.transfersView.SetJobs(msg.queue.Jobs())
+		if msg.event.Done {
+			return m, tea.Batch(m.listenForJobs(msg.queue), m.finishJob(msg.event.Job), m.recordJob(msg.event.Job))
 		}
+		return m, m.listenForJobs(msg.queue)
 
-	case ViewDownload:
-		var cmd tea.Cmd
-		m.downloadView, cmd = m.downloadView.Update(msg)
-		cmds = append(cmds, cmd)
+	case ObjectCreatedMsg:
+		if msg.Err != nil {
+			if msg.Bucket == m.currentBucket {
+				m.browserView.RollbackChange(msg.Key)
+			}
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Creating object")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if msg.Bucket == m.currentBucket {
+			m.browserView.ConfirmChange(msg.Key)
+		}
+		m.statusMsg = fmt.Sprintf("Created %s", msg.Key)
+		return m, m.refreshObjects(msg.Bucket, []string{msg.Key})
 
-	case ViewBookmarks:
-		var cmd tea.Cmd
-		m.bookmarksView, cmd = m.bookmarksView.Update(msg)
-		cmds = append(cmds, cmd)
+	case ObjectRenamedMsg:
+		// A rename is pending under the new key, a move out of the folder
+		// under the old one
+		if msg.Err != nil {
+			if msg.Bucket == m.currentBucket {
+				m.browserView.RollbackChange(msg.NewKey)
+				m.browserView.RollbackChange(msg.OldKey)
+			}
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Renaming object")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			// A half-finished rename may have left both keys
+			return m, m.refreshObjects(msg.Bucket, []string{msg.OldKey, msg.NewKey})
+		}
+		if msg.Bucket == m.currentBucket {
+			m.browserView.ConfirmChange(msg.NewKey)
+			m.browserView.ConfirmChange(msg.OldKey)
+		}
+		if path.Dir(msg.NewKey) == path.Dir(msg.OldKey) {
+			m.statusMsg = fmt.Sprintf("Renamed to %s", path.Base(msg.NewKey))
+		} else {
+			m.statusMsg = fmt.Sprintf("Moved to %s", msg.NewKey)
+		}
+		return m, m.refreshObjects(msg.Bucket, []string{msg.OldKey, msg.NewKey})
 
-		// Check for actions
-		action, id := m.bookmarksView.ConsumeAction()
-		switch action {
-		case bookmarksview.ActionSelect:
-			if bookmark, ok := m.bookmarkStore.Get(id); ok {
-				m.currentBucket = bookmark.Bucket
-				m.currentPrefix = bookmark.Prefix
-				m.browserView.SetBucket(bookmark.Bucket)
-				m.browserView.SetPrefix(bookmark.Prefix)
-				m.browserView.SetLoading(true)
-				m.activeView = ViewBrowser
-				cmds = append(cmds, m.loadObjects())
-			}
-
-		case bookmarksview.ActionDelete:
-			if m.bookmarkStore != nil {
-				if err := m.bookmarkStore.Remove(id); err != nil {
-					m.errorMsg = security.SanitizeErrorGeneric(err, "Removing bookmark")
-					m.errorTimeout = time.Now().Add(5 * time.Second)
-				} else {
-					m.bookmarksView.Refresh()
-					m.statusMsg = "Bookmark removed"
-				}
+	case ObjectDeletedMsg:
+		if msg.Err != nil {
+			if msg.Bucket == m.currentBucket {
+				m.browserView.RollbackChange(msg.Key)
 			}
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Deleting object")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
 		}
-	}
+		if msg.Bucket == m.currentBucket {
+			m.browserView.ConfirmChange(msg.Key)
+		}
+		m.statusMsg = fmt.Sprintf("Deleted %s", msg.Key)
+		return m, m.refreshObjects(msg.Bucket, []string{msg.Key})
 
-	return m, tea.Batch(cmds...)
-}
+	case guardrailMsg:
+		if msg.err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.err, "Checking guardrails")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.pendingGuarded = msg.run
+		m.showGuardrailPrompt("guardrail", msg.op, msg.exceeded)
+		return m, nil
 
-func (m *Model) nextView() {
-	switch m.activeView {
-	case ViewBuckets:
-		m.activeView = ViewBrowser
-	case ViewBrowser:
-		m.activeView = ViewBookmarks
-	case ViewBookmarks:
-		m.activeView = ViewBuckets
-	case ViewDownload:
-		m.activeView = ViewBuckets
-	}
-}
+	case VerifiedMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Verifying")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		name := path.Base(msg.Key)
+		r := msg.Result
+		switch {
+		case !r.Match:
+			m.errorMsg = fmt.Sprintf("%s differs from %s (%s: local %s, S3 %s)", name, msg.LocalPath, r.Method, r.Local, r.Remote)
+			m.errorTimeout = time.Now().Add(10 * time.Second)
+		case r.Method == "size":
+			m.statusMsg = fmt.Sprintf("%s is the same size as %s; its ETag can't be checked locally", name, msg.LocalPath)
+		default:
+			m.statusMsg = fmt.Sprintf("%s matches %s (%s)", name, msg.LocalPath, r.Method)
+		}
+		return m, nil
 
-func (m *Model) prevView() {
-	switch m.activeView {
-	case ViewBuckets:
-		m.activeView = ViewBookmarks
-	case ViewBrowser:
-		m.activeView = ViewBuckets
-	case ViewBookmarks:
-		m.activeView = ViewBrowser
-	case ViewDownload:
-		m.activeView = ViewBuckets
-	}
-}
+	case s3browser.SelectMsg:
+		// Enter on an object inspects the one under the cursor
+		if obj, ok := m.browserView.SelectedObject(); ok && !obj.IsPrefix {
+			return m, m.inspectObject(obj.Key)
+		}
+		return m, nil
 
-func (m Model) handleRefresh() (tea.Model, tea.Cmd) {
-	switch m.activeView {
-	case ViewBuckets:
-		m.bucketsView.SetLoading(true)
-		return m, m.loadBuckets()
-	case ViewBrowser:
-		m.browserView.SetLoading(true)
-		return m, m.loadObjects()
-	case ViewBookmarks:
-		m.bookmarksView.Refresh()
-	}
-	return m, nil
-}
+	case latestPartitionMsg:
+		if msg.err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.err, "Finding the latest partition")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if msg.bucket != m.currentBucket {
+			return m, nil
+		}
+		m.currentPrefix = msg.prefix
+		m.statusMsg = "Latest partition: " + msg.prefix
+		return m, m.openLocation(msg.bucket, msg.prefix)
 
-// Prompt handling
+	case s3browser.ActionMsg:
+		cmd := m.handleBrowserAction(msg)
+		return m, cmd
 
-func (m *Model) showDownloadPrompt(obj aws.S3Object) {
-	m.showPrompt = true
-	m.promptType = "download"
-	m.promptDefault = m.browserView.DefaultDownloadPath(obj)
-	m.promptInput = m.promptDefault
-	m.promptCursor = len(m.promptInput)
+	case buckets.ActionMsg:
+		cmd := m.handleBucketAction(msg)
+		return m, cmd
 
-	if obj.IsPrefix {
-		m.promptText = fmt.Sprintf("Download all files in '%s' to:", obj.DisplayName())
-	} else {
-		m.promptText = fmt.Sprintf("Download '%s' to:", obj.DisplayName())
-	}
-}
+	case dashboard.ActionMsg:
+		m.dashboard = nil
+		return m, m.handleBucketAction(buckets.ActionMsg{Action: buckets.ActionSelect, Bucket: msg.Bucket})
 
-func (m *Model) showMultiDownloadPrompt(objs []aws.S3Object) {
-	m.showPrompt = true
-	m.promptType = "multi-download"
-	m.promptDefault = "./download"
-	m.promptInput = m.promptDefault
-	m.promptCursor = len(m.promptInput)
-	m.promptText = fmt.Sprintf("Download %d selected items to:", len(objs))
-	m.pendingDownloadObjects = objs
-}
+	case SummaryMsg:
+		// A summary for a profile since switched from, or a closed dashboard, is dropped
+		if m.dashboard == nil || msg.Profile != m.profile {
+			return m, nil
+		}
+		m.dashboard.SetSummary(msg.Summary, msg.Err)
+		return m, nil
 
-func (m *Model) showSyncPrompt() {
-	m.showPrompt = true
-	m.promptType = "sync"
+	case AboutMsg:
+		if m.about == nil {
+			return m, nil
+		}
+		m.about.SetConnectivity(msg.Connectivity)
+		return m, nil
 
-	// Default to current prefix folder name
-	defaultPath := "./"
-	if m.currentPrefix != "" {
-		parts := strings.Split(strings.TrimSuffix(m.currentPrefix, "/"), "/")
-		if len(parts) > 0 {
-			defaultPath = "./" + parts[len(parts)-1]
+	case WhatsNewMsg:
+		if m.whatsNew == nil {
+			return m, nil
 		}
-	}
+		m.whatsNew.SetArrivals(msg.Arrivals, msg.Notes, msg.Err)
+		return m, nil
 
-	m.promptDefault = defaultPath
-	m.promptInput = m.promptDefault
-	m.promptCursor = len(m.promptInput)
-	m.promptText = fmt.Sprintf("Sync '%s' to local directory:", m.currentPrefix)
-}
+	case whatsnew.ActionMsg:
+		a := msg.Arrival
+		switch msg.Action {
+		case whatsnew.ActionOpen:
+			m.whatsNew = nil
+			m.currentBucket = a.Bookmark.Bucket
+			m.currentPrefix = a.Key[:strings.LastIndex(a.Key, "/")+1] // the object's folder
+			m.activeView = ViewBrowser
+			return m, m.openLocation(m.currentBucket, m.currentPrefix)
+		case whatsnew.ActionPreview:
+			obj := aws.S3Object{Key: a.Key, Size: a.Size, LastModified: a.LastModified}
+			m.statusMsg = "Loading " + obj.DisplayName() + "..."
+			return m, m.previewObject(a.Bookmark.Bucket, obj)
+		}
+		return m, nil
 
-func (m *Model) showBookmarkPrompt() {
-	m.showPrompt = true
-	m.promptType = "bookmark"
+	case bookmarksview.ActionMsg:
+		cmd := m.handleBookmarkAction(msg)
+		return m, cmd
 
-	// Default name based on prefix
-	defaultName := m.currentBucket
-	if m.currentPrefix != "" {
-		parts := strings.Split(strings.TrimSuffix(m.currentPrefix, "/"), "/")
-		if len(parts) > 0 {
-			defaultName = parts[len(parts)-1]
+	case transfers.ActionMsg:
+		if msg.Action == transfers.ActionClearBackground {
+			m.clearBackgroundJobs()
+			return m, nil
 		}
-	}
+		m.showJobAnnotationPrompt(msg)
+		return m, nil
 
-	m.promptDefault = defaultName
-	m.promptInput = m.promptDefault
-	m.promptCursor = len(m.promptInput)
-	m.promptText = "Bookmark name:"
-}
+	case preview.PageMsg:
+		return m, m.previewPage(msg)
 
-func (m *Model) showBucketBookmarkPrompt(bucket string) {
-	m.showPrompt = true
-	m.promptType = "bucket-bookmark"
-	m.promptDefault = bucket
-	m.promptInput = m.promptDefault
-	m.promptCursor = len(m.promptInput)
-	m.promptText = fmt.Sprintf("Bookmark bucket '%s' as:", bucket)
-	m.pendingBookmarkBucket = bucket
-}
+	case PreviewMsg:
+		m.statusMsg = ""
+		if msg.Page {
+			// A window for a pager since closed, or showing another object
+			if m.preview == nil || m.preview.Key() != msg.Key {
+				return m, nil
+			}
+			if msg.Err != nil {
+				m.preview.PageFailed(security.SanitizeErrorGeneric(msg.Err, "Fetching"))
+				return m, nil
+			}
+			p := preview.New(msg.Bucket, msg.Key, msg.ContentType, msg.Size, msg.Data)
+			p.SetOffset(msg.Offset)
+			p.SetSize(m.width-6, m.height-4)
+			if msg.Tail {
+				p.GotoBottom()
+			}
+			m.preview = &p
+			return m, nil
+		}
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Previewing")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		p := preview.New(msg.Bucket, msg.Key, msg.ContentType, msg.Size, msg.Data)
+		if preview.IsImage(msg.Key, msg.ContentType) {
+			p = preview.NewImage(msg.Bucket, msg.Key, msg.ContentType, msg.Size, msg.Data, m.graphics)
+		}
+		if msg.Parquet != nil {
+			p = preview.NewParquet(msg.Bucket, msg.Key, msg.Size, msg.Parquet)
+		}
+		p.SetSize(m.width-6, m.height-4)
+		m.preview = &p
+		return m, p.DrawImage()
 
-func (m Model) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
-	switch msg.Type {
-	case tea.KeyEsc:
-		m.showPrompt = false
-		m.promptInput = ""
+	case preview.ImageMsg:
+		if m.preview != nil {
+			m.preview.SetImage(msg)
+		}
 		return m, nil
 
-	case tea.KeyEnter:
-		return m.executePromptAction()
-
-	case tea.KeyBackspace:
-		if len(m.promptInput) > 0 && m.promptCursor > 0 {
-			m.promptInput = m.promptInput[:m.promptCursor-1] + m.promptInput[m.promptCursor:]
-			m.promptCursor--
+	case ObjectDetailsMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Inspecting")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
 		}
+		m.inspected = msg.Details
 		return m, nil
 
-	case tea.KeyDelete:
-		if m.promptCursor < len(m.promptInput) {
-			m.promptInput = m.promptInput[:m.promptCursor] + m.promptInput[m.promptCursor+1:]
+	case ComparisonMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Comparing")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
 		}
+		m.statusMsg = ""
+		m.comparison = &msg
 		return m, nil
 
-	case tea.KeyLeft:
-		if m.promptCursor > 0 {
-			m.promptCursor--
+	case MetadataUpdatedMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Updating metadata")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if m.inspected != nil && m.inspected.Key == msg.Key {
+			m.inspected = msg.Details
 		}
+		m.statusMsg = "Updated metadata of " + path.Base(msg.Key)
 		return m, nil
 
-	case tea.KeyRight:
-		if m.promptCursor < len(m.promptInput) {
-			m.promptCursor++
+	case RestoreRequestedMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Restoring")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+		if msg.Requested == 0 && msg.InProgress == 0 {
+			if msg.Err == nil {
+				m.warningMsg = "Nothing to restore: only GLACIER and DEEP_ARCHIVE objects need restoring"
+				m.errorTimeout = time.Now().Add(5 * time.Second)
+			}
+			return m, nil
+		}
+		m.statusMsg = fmt.Sprintf("Restore requested for %d objects", msg.Requested)
+		if msg.InProgress > 0 {
+			m.statusMsg += fmt.Sprintf(" (%d already restoring)", msg.InProgress)
+		}
+		return m, tea.Batch(m.browserView.Refresh(), m.watchRestores(msg.Bucket, msg.Tier, msg.Restoring))
+
+	case restoreTickMsg:
+		if len(m.restoreWatches) == 0 {
+			m.restorePolling = false
+			return m, nil
+		}
+		return m, tea.Batch(m.pollRestores(), restoreTick())
+
+	case restoreStatusMsg:
+		id := msg.bucket + "/" + msg.key
+		if _, ok := m.restoreWatches[id]; !ok {
+			return m, nil
+		}
+		if msg.err != nil {
+			return m, nil
+		}
+		if msg.status == nil {
+			// Deleted while it was being restored
+			delete(m.restoreWatches, id)
+			return m, nil
+		}
+		if !msg.status.Ready() {
+			return m, nil
+		}
+		delete(m.restoreWatches, id)
+		if !msg.status.Archived {
+			// Its storage class was changed meanwhile
+			return m, nil
+		}
+		m.statusMsg = fmt.Sprintf("✓ %s is restored until %s", path.Base(msg.key), msg.status.Until.Local().Format("Mon Jan 2 15:04"))
+		var cmds []tea.Cmd
+		if msg.bucket == m.currentBucket {
+			cmds = append(cmds, m.browserView.Refresh())
+		}
+		if m.settings.NotifyRestored {
+			cmds = append(cmds, sendRestoredNotification(msg.key, msg.status.Until))
+		}
+		return m, tea.Batch(cmds...)
+
+	case PresignedMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Presigning")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.sharedURL = msg.URL
+		m.sharedNote = fmt.Sprintf("Download link for %s, valid until %s.", path.Base(msg.Key), msg.Expires.Format("Mon Jan 2 15:04"))
+		if msg.Upload {
+			m.sharedNote = fmt.Sprintf("Upload link for %s, valid until %s.", msg.Key, msg.Expires.Format("Mon Jan 2 15:04"))
+		}
+		m.sharedCurl = aws.CurlCommand(msg.URL, path.Base(msg.Key), msg.Upload)
+		m.sharedPwsh = aws.PowerShellCommand(msg.URL, path.Base(msg.Key), msg.Upload)
+		if msg.Copied {
+			m.sharedNote += " Copied to the clipboard."
+		} else {
+			m.sharedNote += " The clipboard isn't available; select the link to copy it."
 		}
 		return m, nil
 
-	case tea.KeyHome, tea.KeyCtrlA:
-		m.promptCursor = 0
+	case FolderMovePlannedMsg:
+		m.statusMsg = ""
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Renaming folder")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.showFolderMovePreview(msg)
 		return m, nil
 
-	case tea.KeyEnd, tea.KeyCtrlE:
-		m.promptCursor = len(m.promptInput)
+	case ConsoleOpenedMsg:
+		switch {
+		case msg.URL == "":
+			m.errorMsg = security.SanitizeErrorGeneric(msg.Err, "Opening the console")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		case msg.Err != nil:
+			// No browser here, e.g. over SSH; the link can still be opened elsewhere
+			m.sharedURL = msg.URL
+			m.sharedNote = fmt.Sprintf("Console link for %s; no browser could be opened.", msg.Location)
+			if msg.Copied {
+				m.sharedNote += " Copied to the clipboard."
+			} else {
+				m.sharedNote += " Select the link to copy it."
+			}
+		default:
+			m.statusMsg = fmt.Sprintf("Opened %s in the AWS console", msg.Location)
+		}
 		return m, nil
 
-	case tea.KeyRunes:
-		// Insert characters
-		m.promptInput = m.promptInput[:m.promptCursor] + string(msg.Runes) + m.promptInput[m.promptCursor:]
-		m.promptCursor += len(msg.Runes)
+	case ErrorMsg:
+		if msg.Err != nil {
+			m.errorMsg = security.SanitizeError(msg.Err)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
 		return m, nil
+
+	case TickMsg:
+		// Clear error after timeout
+		if m.errorMsg != "" && time.Now().After(m.errorTimeout) {
+			m.errorMsg = ""
+		}
+		if m.warningMsg != "" && time.Now().After(m.errorTimeout) {
+			m.warningMsg = ""
+		}
+		return m, tickCmd()
 	}
 
-	return m, nil
-}
+	// Route to active view
+	switch m.activeView {
+	case ViewProfiles:
+		var cmd tea.Cmd
+		m.profilesView, cmd = m.profilesView.Update(msg)
+		cmds = append(cmds, cmd)
 
-func (m Model) executePromptAction() (tea.Model, tea.Cmd) {
-	m.showPrompt = false
-	input := m.promptInput
-	m.promptInput = ""
+	case ViewBuckets:
+		var cmd tea.Cmd
+		m.bucketsView, cmd = m.bucketsView.Update(msg)
+		cmds = append(cmds, cmd)
 
-	if input == "" {
-		return m, nil
-	}
+	case ViewBrowser:
+		var cmd tea.Cmd
+		m.browserView, cmd = m.browserView.Update(msg)
+		m.currentPrefix = m.browserView.Prefix() // follow navigation
+		cmds = append(cmds, cmd)
 
-	switch m.promptType {
-	case "download":
-		obj, _ := m.browserView.SelectedObject()
-		localPath := input
+	case ViewLocal:
+		if keyMsg, ok := msg.(tea.KeyMsg); ok && keyMsg.String() == "c" &&
+			!m.localView.Filtering() && !m.browserView.Filtering() && !m.browserView.Editing() {
+			return m.copyBetweenPanes()
+		}
 
-		// Make path absolute if relative
-		if !filepath.IsAbs(localPath) {
-			localPath = filepath.Clean(localPath)
+		var cmd tea.Cmd
+		if m.localFocus {
+			m.localView, cmd = m.localView.Update(msg)
+			cmds = append(cmds, cmd)
+		} else {
+			m.browserView, cmd = m.browserView.Update(msg)
+			m.currentPrefix = m.browserView.Prefix()
+			cmds = append(cmds, cmd)
 		}
 
-		m.activeView = ViewDownload
-		m.browserView.ClearSelection()
-		return m, m.startDownload(obj.Key, localPath, obj.IsPrefix)
+	case ViewTransfers:
+		var cmd tea.Cmd
+		m.transfersView, cmd = m.transfersView.Update(msg)
+		cmds = append(cmds, cmd)
 
-	case "multi-download":
-		localPath := input
-		if !filepath.IsAbs(localPath) {
-			localPath = filepath.Clean(localPath)
-		}
+	case ViewBookmarks:
+		var cmd tea.Cmd
+		m.bookmarksView, cmd = m.bookmarksView.Update(msg)
+		cmds = append(cmds, cmd)
+	}
 
-		objs := m.pendingDownloadObjects
-		m.pendingDownloadObjects = nil
-		m.activeView = ViewDownload
-		m.browserView.ClearSelection()
-		return m, m.startMultiDownload(objs, localPath)
+	return m, tea.Batch(cmds...)
+}
 
-	case "sync":
-		localPath := input
-		if !filepath.IsAbs(localPath) {
-			localPath = filepath.Clean(localPath)
-		}
+// handleBucketAction carries out an action the bucket list reported
+func (m *Model) handleBucketAction(msg buckets.ActionMsg) tea.Cmd {
+	switch msg.Action {
+	case buckets.ActionSelect:
+		m.currentBucket = msg.Bucket
+		m.currentPrefix = ""
+		m.activeView = ViewBrowser
+		return m.openLocation(msg.Bucket, "")
 
-		m.activeView = ViewDownload
+	case buckets.ActionBookmark:
+		m.showBucketBookmarkPrompt(msg.Bucket)
 
-		// Create sync manager and sync
-		return m, func() tea.Msg {
-			syncMgr := download.NewSyncManager(m.client)
+	case buckets.ActionConsole:
+		return m.openConsole(msg.Bucket, "")
 
-			// Set up progress callback
-			progressChan := make(chan download.Progress, 10)
-			m.downloadMgr.SetProgressCallback(func(p download.Progress) {
-				select {
-				case progressChan <- p:
-				default:
-				}
-			})
+	case buckets.ActionHide:
+		hide := !m.settings.BucketHidden(msg.Bucket)
+		if err := m.settings.SetBucketHidden(msg.Bucket, hide); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Saving hidden buckets")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return nil
+		}
+		m.bucketsView.SetHidden(m.settings.HiddenBuckets)
+		if hide {
+			m.statusMsg = "Hid " + msg.Bucket + " (H shows hidden buckets)"
+		} else {
+			m.statusMsg = "Unhid " + msg.Bucket
+		}
 
-			go func() {
-				err := syncMgr.Sync(m.ctx, m.currentBucket, m.currentPrefix, localPath, m.downloadMgr)
-				if err != nil {
-					progressChan <- download.Progress{Status: download.StatusFailed}
-				}
-				close(progressChan)
-			}()
+	case buckets.ActionSummary:
+		d := dashboard.New(m.profile)
+		d.SetSize(m.width-6, m.height-4)
+		m.dashboard = &d
+		return m.summarizeBuckets()
+	}
+	return nil
+}
 
-			return downloadStartedMsg{progressChan: progressChan}
+// handleBookmarkAction carries out an action the bookmarks view reported
+func (m *Model) handleBookmarkAction(msg bookmarksview.ActionMsg) tea.Cmd {
+	switch msg.Action {
+	case bookmarksview.ActionSelect:
+		if bookmark, ok := m.bookmarkStore.Get(msg.ID); ok {
+			m.currentBucket = bookmark.Bucket
+			m.currentPrefix = bookmark.Prefix
+			m.activeView = ViewBrowser
+			return m.openLocation(bookmark.Bucket, bookmark.Prefix)
 		}
 
-	case "bookmark":
+	case bookmarksview.ActionDelete:
 		if m.bookmarkStore != nil {
-			_, err := m.bookmarkStore.Add(input, m.currentBucket, m.currentPrefix)
-			if err != nil {
-				m.errorMsg = security.SanitizeErrorGeneric(err, "Adding bookmark")
+			if err := m.bookmarkStore.Remove(msg.ID); err != nil {
+				m.errorMsg = security.SanitizeErrorGeneric(err, "Removing bookmark")
 				m.errorTimeout = time.Now().Add(5 * time.Second)
 			} else {
-				m.statusMsg = "Bookmark added"
 				m.bookmarksView.Refresh()
+				m.statusMsg = "Bookmark removed"
 			}
 		}
 
-	case "bucket-bookmark":
-		if m.bookmarkStore != nil && m.pendingBookmarkBucket != "" {
-			_, err := m.bookmarkStore.Add(input, m.pendingBookmarkBucket, "")
+	case bookmarksview.ActionRename:
+		if m.bookmarkStore != nil {
+			err := security.ValidBookmarkName(msg.Name)
+			if err == nil {
+				err = m.bookmarkStore.Update(msg.ID, msg.Name)
+			}
 			if err != nil {
-				m.errorMsg = security.SanitizeErrorGeneric(err, "Adding bookmark")
+				m.errorMsg = security.SanitizeErrorGeneric(err, "Renaming bookmark")
 				m.errorTimeout = time.Now().Add(5 * time.Second)
 			} else {
-				m.statusMsg = "Bookmark added"
-				m.bookmarksView.Refresh()
+				m.statusMsg = "Bookmark renamed"
 			}
+			m.bookmarksView.Refresh()
 		}
-		m.pendingBookmarkBucket = ""
-	}
 
-	return m, nil
-}
+	case bookmarksview.ActionArchive:
+		if bookmark, ok := m.bookmarkStore.Get(msg.ID); ok {
+			if err := m.bookmarkStore.SetArchived(msg.ID, !bookmark.Archived); err != nil {
+				m.errorMsg = security.SanitizeErrorGeneric(err, "Archiving bookmark")
+				m.errorTimeout = time.Now().Add(5 * time.Second)
+				return nil
+			}
+			m.bookmarksView.Refresh()
+			if bookmark.Archived {
+				m.statusMsg = "Bookmark restored"
+			} else {
+				m.statusMsg = "Bookmark archived (H shows archived bookmarks)"
+			}
+		}
+
+	case bookmarksview.ActionSetFreshness:
+		if bookmark, ok := m.bookmarkStore.Get(msg.ID); ok {
+			m.showFreshnessPrompt(bookmark)
+		}
 
-// downloadProgressTickMsg is sent for progress updates
-type downloadProgressTickMsg struct {
-	progress     download.Progress
-	progressChan <-chan download.Progress
-	done         bool
+	case bookmarksview.ActionWhatsNew:
+		w := whatsnew.New()
+		w.SetSize(m.width-6, m.height-4)
+		m.whatsNew = &w
+		return m.loadWhatsNew()
+	}
+	return nil
 }
 
-// listenForProgress returns a command that listens for progress updates
-func (m Model) listenForProgress(ch <-chan download.Progress) tea.Cmd {
-	return func() tea.Msg {
-		progress, ok := <-ch
-		return downloadProgressTickMsg{
-			progress:     progress,
-			progressChan: ch,
-			done:         !ok,
+// copyKeys puts the keys of objs, or their s3:// URIs if uris is set, on
+// the clipboard one per line, for pasting into tickets or scripts
+func (m *Model) copyKeys(bucket string, objs []aws.S3Object, uris bool) {
+	lines := make([]string, len(objs))
+	for i, obj := range objs {
+		lines[i] = obj.Key
+		if uris {
+			lines[i] = "s3://" + bucket + "/" + obj.Key
+		}
+	}
+	if err := clipboard.WriteAll(strings.Join(lines, "\n")); err != nil {
+		m.errorMsg = security.SanitizeErrorGeneric(err, "Copying")
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return
+	}
+	what := "keys"
+	if uris {
+		what = "URIs"
+	}
+	if len(objs) == 1 {
+		m.statusMsg = "Copied " + lines[0] + " to the clipboard"
+	} else {
+		m.statusMsg = fmt.Sprintf("Copied %d %s to the clipboard", len(objs), what)
+	}
+	m.browserView.ClearSelection()
+}
+
+// handleBrowserAction carries out an action the browser reported, from
+// the browser tab or the S3 pane of the commander layout
+func (m *Model) handleBrowserAction(msg s3browser.ActionMsg) tea.Cmd {
+	if msg.Bucket != m.currentBucket || msg.Prefix != m.currentPrefix {
+		// The browser has moved on since
+		return nil
+	}
+	obj, objs := msg.Object, msg.Objects
+	switch msg.Action {
+	case s3browser.ActionDownload:
+		if len(objs) > 0 {
+			m.showMultiDownloadPrompt(objs)
+		} else {
+			m.showDownloadPrompt(obj)
+		}
+
+	case s3browser.ActionCopy:
+		if len(objs) == 0 {
+			objs = []aws.S3Object{obj}
+		}
+		m.showCopyPrompt(objs)
+
+	case s3browser.ActionMove:
+		m.showMovePrompt(obj)
+
+	case s3browser.ActionRestore:
+		if len(objs) == 0 {
+			objs = []aws.S3Object{obj}
+		}
+		m.showRestorePrompt(objs)
+
+	case s3browser.ActionStorageClass:
+		if len(objs) == 0 {
+			objs = []aws.S3Object{obj}
+		}
+		m.pendingClassObjects = objs
+		target := objs[0].Key
+		if len(objs) > 1 {
+			target = fmt.Sprintf("%d objects", len(objs))
+		}
+		m.showStorageClassPrompt("change-class", target)
+		m.promptText = fmt.Sprintf("Move %s to storage class:", target)
+
+	case s3browser.ActionTag:
+		if len(objs) == 0 {
+			objs = []aws.S3Object{obj}
+		}
+		m.showTagPrompt(objs)
+
+	case s3browser.ActionConsole:
+		key := msg.Prefix
+		if obj.Key != "" {
+			key = obj.Key
+		}
+		return m.openConsole(msg.Bucket, key)
+
+	case s3browser.ActionCopyKeys, s3browser.ActionCopyURIs:
+		if len(objs) == 0 {
+			objs = []aws.S3Object{obj}
+		}
+		m.copyKeys(msg.Bucket, objs, msg.Action == s3browser.ActionCopyURIs)
+
+	case s3browser.ActionCompare:
+		if len(objs) == 2 {
+			return m.compareObjects(msg.Bucket, objs[0].Key, msg.Bucket, objs[1].Key)
+		}
+		switch {
+		case m.compareKey == "":
+			m.compareBucket, m.compareKey = msg.Bucket, obj.Key
+			m.statusMsg = fmt.Sprintf("Marked %s; press M on another object to compare, or on it again to unmark", path.Base(obj.Key))
+		case m.compareBucket == msg.Bucket && m.compareKey == obj.Key:
+			m.compareBucket, m.compareKey = "", ""
+			m.statusMsg = "Unmarked " + path.Base(obj.Key)
+		default:
+			bucket, key := m.compareBucket, m.compareKey
+			m.compareBucket, m.compareKey = "", ""
+			m.statusMsg = fmt.Sprintf("Comparing %s with %s…", path.Base(key), path.Base(obj.Key))
+			return m.compareObjects(bucket, key, msg.Bucket, obj.Key)
+		}
+
+	case s3browser.ActionVerify:
+		m.showVerifyPrompt(obj)
+
+	case s3browser.ActionPresign:
+		m.showPresignPrompt(obj.Key, false)
+
+	case s3browser.ActionInspect:
+		return m.inspectObject(obj.Key)
+
+	case s3browser.ActionVersions:
+		m.statusMsg = "Listing versions..."
+		return m.listVersions(m.currentBucket, m.currentPrefix, obj.Key)
+
+	case s3browser.ActionPresignUpload:
+		m.showPresignUploadPrompt()
+
+	case s3browser.ActionJumpToDate:
+		m.showDateJumpPrompt(time.Now())
+
+	case s3browser.ActionInventory:
+		m.showInventoryPrompt()
+
+	case s3browser.ActionPreview:
+		m.statusMsg = "Loading " + obj.DisplayName() + "..."
+		return m.previewObject(m.currentBucket, obj)
+
+	case s3browser.ActionManifest:
+		m.showManifestPrompt()
+
+	case s3browser.ActionJumpToLatest:
+		m.statusMsg = "Looking for the latest partition..."
+		return m.findLatestPartition()
+
+	case s3browser.ActionSync:
+		m.showSyncPrompt()
+
+	case s3browser.ActionSyncUp:
+		m.showSyncUpPrompt()
+
+	case s3browser.ActionBookmark:
+		m.showBookmarkPrompt()
+
+	case s3browser.ActionNewObject:
+		m.showTemplatePrompt()
+
+	case s3browser.ActionNewFolder:
+		m.showNewFolderPrompt()
+
+	case s3browser.ActionUpload:
+		m.showUploadPrompt()
+
+	case s3browser.ActionRename:
+		return m.startRename(obj, msg.RenameTo)
+
+	case s3browser.ActionRenameFolder:
+		m.showRenameFolderPrompt(obj)
+
+	case s3browser.ActionDelete:
+		if obj.IsPrefix {
+			m.errorMsg = "Deleting: only single objects can be deleted, not folders"
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return nil
+		}
+		m.pendingDeleteKey = obj.Key
+		var exceeded *config.LimitExceeded
+		if errors.As(m.settings.Guardrails.Check(1, obj.Size), &exceeded) {
+			m.showGuardrailPrompt("delete", "Delete s3://"+m.currentBucket+"/"+obj.Key, exceeded)
+			return nil
+		}
+		m.showConfirmPrompt("delete", fmt.Sprintf("Delete s3://%s/%s? This can't be undone.", m.currentBucket, obj.Key))
+	}
+	return nil
+}
+
+// startMove moves obj to newKey anywhere in the bucket. A move within the
+// object's folder shows as a rename; otherwise the row is struck through
+// until the move finishes.
+func (m *Model) startMove(obj aws.S3Object, newKey string) tea.Cmd {
+	if newKey == obj.Key {
+		return nil
+	}
+	if err := security.ValidObjectKey(newKey); err != nil || strings.HasSuffix(newKey, "/") {
+		if err == nil {
+			err = errors.New("key can't end in /")
+		}
+		m.errorMsg = security.SanitizeErrorGeneric(err, "Moving object")
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return nil
+	}
+	if !m.checkNamingPolicy(m.currentBucket, newKey) {
+		return nil
+	}
+
+	if path.Dir(newKey) == path.Dir(obj.Key) {
+		m.browserView.MarkRenaming(obj.Key, newKey)
+	} else {
+		m.browserView.MarkDeleting(obj.Key)
+	}
+	return m.renameObject(obj.Key, newKey)
+}
+
+// startRename renames obj within its folder, showing the new name right away
+func (m *Model) startRename(obj aws.S3Object, name string) tea.Cmd {
+	if strings.Contains(name, "/") {
+		m.errorMsg = "Renaming object: names can't contain /"
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return nil
+	}
+
+	newKey := strings.TrimSuffix(obj.Key, obj.DisplayName()) + name
+	if err := security.ValidObjectKey(newKey); err != nil {
+		m.errorMsg = security.SanitizeErrorGeneric(err, "Renaming object")
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return nil
+	}
+	if !m.checkNamingPolicy(m.currentBucket, newKey) {
+		return nil
+	}
+
+	m.browserView.MarkRenaming(obj.Key, newKey)
+	return m.renameObject(obj.Key, newKey)
+}
+
+// inlineEditing returns true while the active view is editing a name in place
+func (m Model) inlineEditing() bool {
+	switch m.activeView {
+	case ViewBookmarks:
+		return m.bookmarksView.Editing()
+	case ViewBrowser:
+		return m.browserView.Editing()
+	case ViewLocal:
+		return !m.localFocus && m.browserView.Editing()
+	}
+	return false
+}
+
+// copyBetweenPanes copies the selection in the focused commander pane into
+// the location shown by the other pane
+func (m Model) copyBetweenPanes() (tea.Model, tea.Cmd) {
+	if m.currentBucket == "" {
+		m.errorMsg = "Select a bucket first"
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return m, nil
+	}
+
+	if m.localFocus {
+		entry, ok := m.localView.SelectedEntry()
+		if !ok || entry.Name == ".." {
+			return m, nil
+		}
+		if entry.IsDir {
+			m.errorMsg = "Uploading: directories are not supported yet"
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if !m.checkUploadFile(entry.Path) {
+			return m, nil
+		}
+
+		key := m.currentPrefix + entry.Name
+		if err := security.ValidObjectKey(key); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Uploading")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if !m.checkNamingPolicy(m.currentBucket, key) {
+			return m, nil
+		}
+
+		m.pendingUploadPath = entry.Path
+		m.pendingUploadKey = key
+		return m, m.checkUploadConflict(key)
+	}
+
+	localDir := m.localView.Dir()
+	if objs := m.browserView.GetSelectedObjects(); len(objs) > 0 {
+		m.browserView.ClearSelection()
+		m.statusMsg = fmt.Sprintf("Downloading %d items to %s", len(objs), localDir)
+		return m, m.startMultiDownload(objs, localDir, m.currentPrefix, m.downloadOpts)
+	}
+
+	obj, ok := m.browserView.SelectedObject()
+	if !ok {
+		return m, nil
+	}
+	// The manager escapes the name under the path policy
+	localPath, err := security.SafeJoin(localDir, strings.TrimSuffix(obj.DisplayName(), "/"))
+	if err != nil {
+		m.errorMsg = security.SanitizeErrorGeneric(err, "Downloading")
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return m, nil
+	}
+
+	m.statusMsg = fmt.Sprintf("Downloading %s to %s", obj.DisplayName(), localDir)
+	return m, m.startDownload(obj, localPath, m.downloadOpts)
+}
+
+func (m *Model) nextView() {
+	switch m.activeView {
+	case ViewBuckets:
+		m.activeView = ViewBrowser
+	case ViewBrowser:
+		m.activeView = ViewBookmarks
+	case ViewBookmarks:
+		m.activeView = ViewLocal
+	case ViewLocal:
+		m.activeView = ViewBuckets
+	case ViewTransfers:
+		m.activeView = ViewBuckets
+	}
+}
+
+func (m *Model) prevView() {
+	switch m.activeView {
+	case ViewBuckets:
+		m.activeView = ViewLocal
+	case ViewBrowser:
+		m.activeView = ViewBuckets
+	case ViewBookmarks:
+		m.activeView = ViewBrowser
+	case ViewLocal:
+		m.activeView = ViewBookmarks
+	case ViewTransfers:
+		m.activeView = ViewBuckets
+	}
+}
+
+func (m Model) handleRefresh() (tea.Model, tea.Cmd) {
+	switch m.activeView {
+	case ViewBuckets:
+		m.bucketsView.SetLoading(true)
+		return m, m.loadBuckets()
+	case ViewBrowser:
+		// In place, so the filter, cursor and selections survive
+		return m, m.browserView.Refresh()
+	case ViewBookmarks:
+		m.bookmarksView.Refresh()
+	case ViewLocal:
+		m.localView.Reload()
+		return m, m.browserView.Refresh()
+	}
+	return m, nil
+}
+
+// Prompt handling
+
+func (m *Model) showDownloadPrompt(obj aws.S3Object) {
+	m.showPrompt = true
+	m.promptType = "download"
+	m.promptDefault = m.browserView.DefaultDownloadPath(obj)
+	if dir, ok := m.lastDestination(); ok {
+		m.promptDefault = filepath.Join(dir, filepath.Base(m.promptDefault))
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+
+	if obj.IsPrefix {
+		m.promptText = fmt.Sprintf("Download all files in '%s' to:", obj.DisplayName())
+	} else {
+		m.promptText = fmt.Sprintf("Download '%s' to:", obj.DisplayName())
+	}
+}
+
+func (m *Model) showMovePrompt(obj aws.S3Object) {
+	m.showPrompt = true
+	m.promptType = "move"
+	m.promptDefault = obj.Key
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Move '%s' to key:", obj.DisplayName())
+	m.pendingMoveObject = obj
+}
+
+func (m *Model) showVerifyPrompt(obj aws.S3Object) {
+	m.showPrompt = true
+	m.promptType = "verify"
+	m.promptDefault = m.browserView.DefaultDownloadPath(obj)
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Compare '%s' with local file:", obj.DisplayName())
+	m.pendingVerifyKey = obj.Key
+}
+
+func (m *Model) showMultiDownloadPrompt(objs []aws.S3Object) {
+	m.showPrompt = true
+	m.promptType = "multi-download"
+	m.promptDefault = "./download"
+	if dir, ok := m.lastDestination(); ok {
+		m.promptDefault = dir
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Download %d selected items to:", len(objs))
+	m.pendingDownloadObjects = objs
+}
+
+// lastDestination returns the directory downloads from the current bucket
+// and prefix, or the nearest folder above it, last went to
+func (m *Model) lastDestination() (string, bool) {
+	if m.historyStore == nil {
+		return "", false
+	}
+	return m.historyStore.Destination(m.currentBucket, m.currentPrefix)
+}
+
+// rememberDestination records the directory a download from the current
+// bucket and prefix went to, so the next one there starts from it
+func (m *Model) rememberDestination(dir string) {
+	if m.historyStore == nil {
+		return
+	}
+	// Absolute, so it still points at the same place from another directory
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+	bucket, prefix := m.currentBucket, m.currentPrefix
+	m.historyWrites = append(m.historyWrites, func(s *history.Store) error {
+		return s.SetDestination(bucket, prefix, dir)
+	})
+}
+
+// Local layouts offered for multi-select downloads
+const (
+	layoutRelative = "relative to current prefix"
+	layoutFullKey  = "full key path"
+	layoutRoot     = "relative to a chosen root"
+)
+
+func (m *Model) showDownloadLayoutPrompt() {
+	options := []string{layoutRelative, layoutFullKey, layoutRoot}
+	selected := 0
+	if m.settings.Transfers.PreserveKeyPaths {
+		selected = 1
+	}
+
+	m.showPrompt = true
+	m.promptType = "download-layout"
+	m.promptText = fmt.Sprintf("Lay out files under '%s':", m.pendingDownloadDir)
+	m.promptOptions = options
+	m.promptOption = selected
+	m.promptInput = options[selected]
+	m.promptCursor = len(m.promptInput)
+}
+
+func (m *Model) showDownloadRootPrompt() {
+	m.showPrompt = true
+	m.promptType = "download-root"
+	m.promptDefault = m.currentPrefix
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Strip this key prefix from local paths:"
+}
+
+// showStorageClassPrompt asks for the storage class of an upload to target
+func (m *Model) showStorageClassPrompt(promptType, target string) {
+	options := aws.UploadStorageClasses
+
+	m.showPrompt = true
+	m.promptType = promptType
+	m.promptText = fmt.Sprintf("Storage class for s3://%s/%s:", m.currentBucket, target)
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// Objects at least this big offer to download just part of themselves
+const rangePromptMinSize = 100 * 1000 * 1000
+
+// How much of a huge object the head and tail choices fetch
+const rangeSampleSize = 10 * 1000 * 1000
+
+// Choices offered when downloading a huge object
+const (
+	wholeDownload = "Whole object"
+	headDownload  = "First 10 MB"
+	tailDownload  = "Last 10 MB"
+	rangeDownload = "A byte range…"
+)
+
+func (m *Model) showDownloadPartPrompt(obj aws.S3Object) {
+	options := []string{wholeDownload, headDownload, tailDownload, rangeDownload}
+
+	m.showPrompt = true
+	m.promptType = "download-part"
+	m.promptText = fmt.Sprintf("'%s' is %s. Download:", obj.DisplayName(), humanize.Bytes(uint64(obj.Size)))
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+func (m *Model) showDownloadRangePrompt() {
+	m.showPrompt = true
+	m.promptType = "download-range"
+	m.promptDefault = "first:10MB"
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Range (first:SIZE, last:SIZE or START-END in bytes):"
+}
+
+// startPendingRangeDownload downloads part of obj to localPath and shows
+// the transfer
+func (m *Model) startPendingRangeDownload(obj aws.S3Object, localPath string, r aws.ByteRange) (tea.Model, tea.Cmd) {
+	m.activeView = ViewTransfers
+	m.browserView.ClearSelection()
+	return m, m.startRangeDownload(obj, localPath, r)
+}
+
+// Choices offered when an upload's key already exists
+const (
+	overwriteUpload = "Overwrite it"
+	skipUpload      = "Skip this upload"
+	renameUpload    = "Upload under another name"
+)
+
+func (m *Model) showUploadConflictPrompt(existing *aws.S3Object) {
+	options := []string{overwriteUpload, skipUpload, renameUpload}
+
+	m.showPrompt = true
+	m.promptType = "upload-conflict"
+	m.promptText = fmt.Sprintf("%s already exists (%s, modified %s):",
+		path.Base(existing.Key), humanize.Bytes(uint64(existing.Size)),
+		existing.LastModified.Local().Format("2006-01-02 15:04"))
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+func (m *Model) showUploadRenamePrompt() {
+	m.showPrompt = true
+	m.promptType = "upload-rename"
+	m.promptDefault = numberedName(path.Base(m.pendingUploadKey))
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Upload as:"
+}
+
+// numberedName suggests another name for an object: "report.csv" becomes
+// "report-1.csv" and "report-1.csv" becomes "report-2.csv"
+func numberedName(name string) string {
+	ext := path.Ext(name)
+	stem := strings.TrimSuffix(name, ext)
+	n := 1
+	if i := strings.LastIndex(stem, "-"); i >= 0 {
+		if prev, err := strconv.Atoi(stem[i+1:]); err == nil && prev > 0 {
+			stem, n = stem[:i], prev+1
+		}
+	}
+	return fmt.Sprintf("%s-%d%s", stem, n, ext)
+}
+
+// Choices offered when an interrupted upload of the same key exists
+const (
+	resumeUpload  = "Resume from the parts already uploaded"
+	restartUpload = "Start over and discard them"
+)
+
+func (m *Model) showResumePrompt(upload *aws.IncompleteUpload) {
+	options := []string{resumeUpload, restartUpload}
+
+	m.showPrompt = true
+	m.promptType = "upload-resume"
+	m.promptText = fmt.Sprintf("An upload of %s started %s was interrupted after %d parts (%s):",
+		path.Base(upload.Key), upload.Initiated.Local().Format("2006-01-02 15:04"),
+		len(upload.Parts), humanize.Bytes(uint64(upload.Bytes())))
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// startedUploadView shows a single-file upload that just started. The
+// commander layout stays put; its panes show the result.
+func (m *Model) startedUploadView(localPath, key string) {
+	if m.activeView == ViewLocal {
+		m.statusMsg = fmt.Sprintf("Uploading %s to s3://%s/%s", filepath.Base(localPath), m.currentBucket, key)
+	} else {
+		m.activeView = ViewTransfers
+	}
+}
+
+// showEncryptionPrompt asks for server-side encryption, defaulting to the
+// profile's configured encryption
+func (m *Model) showEncryptionPrompt(promptType string) {
+	m.showPrompt = true
+	m.promptType = promptType
+	m.promptDefault = m.encryption.String()
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Server-side encryption (default, sse-s3 or sse-kms[:key-arn]):"
+}
+
+// showUploadMetadataPrompt lets the user review the detected Content-Type
+// and add user metadata before a single-file upload
+func (m *Model) showUploadMetadataPrompt() {
+	m.showPrompt = true
+	m.promptType = "upload-meta"
+	fields := []string{"content-type=" + aws.DetectContentType(m.pendingUploadPath)}
+	names := make([]string, 0, len(m.uploadTags))
+	for name := range m.uploadTags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fields = append(fields, "tag:"+name+"="+m.uploadTags[name])
+	}
+	m.promptDefault = strings.Join(fields, ", ")
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Content-Type, metadata and tag:name=value tags (comma separated):"
+}
+
+// parseObjectAttributes parses "content-type=text/csv, owner=data,
+// tag:team=data" into a Content-Type, user metadata and object tags
+func parseObjectAttributes(input string) (aws.ObjectAttributes, error) {
+	var attrs aws.ObjectAttributes
+	for _, field := range strings.Split(input, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return attrs, fmt.Errorf("expected name=value, got %q", field)
+		}
+		value = strings.TrimSpace(value)
+
+		// Tag keys are case-sensitive, unlike metadata names
+		if tag, isTag := strings.CutPrefix(strings.TrimSpace(name), "tag:"); isTag {
+			if attrs.Tags == nil {
+				attrs.Tags = make(map[string]string)
+			}
+			attrs.Tags[strings.TrimSpace(tag)] = value
+			continue
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+
+		if name == "content-type" {
+			attrs.ContentType = value
+			continue
+		}
+		if attrs.Metadata == nil {
+			attrs.Metadata = make(map[string]string)
+		}
+		attrs.Metadata[name] = value
+	}
+	if err := security.ValidMetadata(attrs.Metadata); err != nil {
+		return attrs, err
+	}
+	return attrs, security.ValidTags(attrs.Tags)
+}
+
+// showRenameFolderPrompt asks where to move a folder and everything in it
+func (m *Model) showRenameFolderPrompt(obj aws.S3Object) {
+	m.showPrompt = true
+	m.promptType = "rename-folder"
+	m.promptDefault = obj.Key
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Rename folder '%s' to:", obj.Key)
+	m.pendingFolderMove = &FolderMovePlannedMsg{Bucket: m.currentBucket, From: obj.Key}
+}
+
+// showFolderMovePreview shows what a planned folder move would do and asks
+// to go ahead
+func (m *Model) showFolderMovePreview(plan FolderMovePlannedMsg) {
+	var size int64
+	for _, obj := range plan.Objects {
+		size += obj.Size
+	}
+	lines := []string{fmt.Sprintf("Move %d objects (%s) from %s to %s? Each is copied, then the original deleted.",
+		len(plan.Objects), humanize.Bytes(uint64(size)), plan.From, plan.To), ""}
+	for _, obj := range plan.Objects[:min(3, len(plan.Objects))] {
+		lines = append(lines, obj.Key+" → "+plan.To+strings.TrimPrefix(obj.Key, plan.From))
+	}
+	if more := len(plan.Objects) - 3; more > 0 {
+		lines = append(lines, fmt.Sprintf("…and %d more", more))
+	}
+	if plan.Naming != "" {
+		lines = append(lines, "", plan.Naming)
+	}
+	m.pendingFolderMove = &plan
+	m.showConfirmPrompt("rename-folder-confirm", strings.Join(lines, "\n"))
+}
+
+// showJobNamePrompt offers to name a transfer, e.g. "model weights v3",
+// before it's queued. Entering nothing queues it under its generated name.
+func (m *Model) showJobNamePrompt(ready jobReadyMsg) {
+	m.pendingJobStart = &ready
+	m.showPrompt = true
+	m.promptType = "job-start-label"
+	m.promptText = fmt.Sprintf("Name for '%s' (optional):", ready.job.Name)
+	m.promptDefault = ""
+	m.promptInput = ""
+	m.promptCursor = 0
+}
+
+// showJobAnnotationPrompt asks for a name or a note for a transfer job
+func (m *Model) showJobAnnotationPrompt(msg transfers.ActionMsg) {
+	m.pendingTransfer = msg.Job
+	m.showPrompt = true
+	if msg.Action == transfers.ActionNote {
+		m.promptType = "job-note"
+		m.promptDefault = msg.Job.Note
+		m.promptText = fmt.Sprintf("Note for '%s' (empty to clear):", msg.Job.Title())
+	} else {
+		m.promptType = "job-label"
+		m.promptDefault = msg.Job.Label
+		m.promptText = fmt.Sprintf("Name for '%s' (empty to clear):", msg.Job.Name)
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+}
+
+// showManifestPrompt asks for a local file listing keys, or s3:// URIs,
+// to download from the current bucket
+func (m *Model) showManifestPrompt() {
+	m.showPrompt = true
+	m.promptType = "manifest"
+	m.promptDefault = ""
+	m.promptInput = ""
+	m.promptCursor = 0
+	m.promptText = fmt.Sprintf("Download the keys listed in file (one per line, from s3://%s):", m.currentBucket)
+}
+
+// showManifestDestPrompt asks where to download the manifest's keys to
+func (m *Model) showManifestDestPrompt() {
+	m.showPrompt = true
+	m.promptType = "manifest-dest"
+	m.promptDefault = "./download"
+	if dir, ok := m.lastDestination(); ok {
+		m.promptDefault = dir
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Download %d keys from %s to (under their key paths):", len(m.pendingManifestKeys), m.pendingManifestName)
+}
+
+// showTagPrompt asks for tags to add to objs, offering the default upload
+// tags
+func (m *Model) showTagPrompt(objs []aws.S3Object) {
+	target := objs[0].Key
+	if len(objs) > 1 {
+		target = fmt.Sprintf("%d objects", len(objs))
+	}
+	names := make([]string, 0, len(m.uploadTags))
+	for name := range m.uploadTags {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	fields := make([]string, len(names))
+	for i, name := range names {
+		fields[i] = name + "=" + m.uploadTags[name]
+	}
+
+	m.pendingTagObjects = objs
+	m.showPrompt = true
+	m.promptType = "tag-objects"
+	m.promptDefault = strings.Join(fields, ", ")
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Tags to add to %s (name=value, comma separated):", target)
+}
+
+// parseTags parses "team=data, cost-center=1234" into object tags
+func parseTags(input string) (map[string]string, error) {
+	tags := make(map[string]string)
+	for _, field := range strings.Split(input, ",") {
+		field = strings.TrimSpace(field)
+		if field == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(field, "=")
+		if !ok {
+			return nil, fmt.Errorf("expected name=value, got %q", field)
+		}
+		tags[strings.TrimSpace(name)] = strings.TrimSpace(value)
+	}
+	if len(tags) == 0 {
+		return nil, fmt.Errorf("no tags given")
+	}
+	return tags, security.ValidTags(tags)
+}
+
+func (m *Model) showSyncUpPrompt() {
+	if m.currentBucket == "" {
+		return
+	}
+
+	m.showPrompt = true
+	m.promptType = "sync-up"
+	m.promptDefault = "./"
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Sync local directory up to s3://%s/%s:", m.currentBucket, m.currentPrefix)
+}
+
+func (m *Model) showSyncPrompt() {
+	m.showPrompt = true
+	m.promptType = "sync"
+
+	// Default to current prefix folder name
+	defaultPath := "./"
+	if m.currentPrefix != "" {
+		parts := strings.Split(strings.TrimSuffix(m.currentPrefix, "/"), "/")
+		if len(parts) > 0 {
+			defaultPath = "./" + parts[len(parts)-1]
+		}
+	}
+
+	m.promptDefault = defaultPath
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Sync '%s' to local directory:", m.currentPrefix)
+}
+
+func (m *Model) showBookmarkPrompt() {
+	m.showPrompt = true
+	m.promptType = "bookmark"
+
+	// Default name based on prefix
+	defaultName := m.currentBucket
+	if m.currentPrefix != "" {
+		parts := strings.Split(strings.TrimSuffix(m.currentPrefix, "/"), "/")
+		if len(parts) > 0 {
+			defaultName = parts[len(parts)-1]
+		}
+	}
+
+	m.promptDefault = defaultName
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Bookmark name:"
+}
+
+func (m *Model) showBucketBookmarkPrompt(bucket string) {
+	m.showPrompt = true
+	m.promptType = "bucket-bookmark"
+	m.promptDefault = bucket
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Bookmark bucket '%s' as:", bucket)
+	m.pendingBookmarkBucket = bucket
+}
+
+func (m *Model) showUploadPrompt() {
+	if m.currentBucket == "" {
+		return
+	}
+	m.showPrompt = true
+	m.promptType = "upload"
+	m.promptDefault = "./"
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Upload local file to s3://%s/%s:", m.currentBucket, m.currentPrefix)
+}
+
+func (m *Model) showTemplatePrompt() {
+	if m.currentBucket == "" {
+		return
+	}
+
+	templates := m.settings.ObjectTemplates()
+	options := make([]string, len(templates))
+	for i, t := range templates {
+		options[i] = t.Name
+	}
+
+	m.showPrompt = true
+	m.promptType = "new-object-template"
+	m.promptText = "Create new object from template:"
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+func (m *Model) showNewObjectPrompt(tmpl config.Template) {
+	key, _ := tmpl.Render(m.currentBucket, m.currentPrefix, time.Now())
+
+	m.showPrompt = true
+	m.promptType = "new-object"
+	m.promptDefault = key
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Create '%s' in s3://%s/%s as:", tmpl.Name, m.currentBucket, m.currentPrefix)
+	m.pendingTemplate = tmpl
+}
+
+func (m *Model) showNewFolderPrompt() {
+	m.showPrompt = true
+	m.promptType = "new-folder"
+	m.promptDefault = ""
+	m.promptInput = ""
+	m.promptCursor = 0
+	m.promptText = fmt.Sprintf("New folder in s3://%s/%s (a/b for nested):", m.currentBucket, m.currentPrefix)
+}
+
+func (m *Model) showCopyPrompt(objs []aws.S3Object) {
+	m.pendingCopyObjects = objs
+
+	m.showPrompt = true
+	m.promptType = "copy"
+	m.promptDefault = fmt.Sprintf("s3://%s/%s", m.currentBucket, m.currentPrefix)
+	switch {
+	case len(objs) == 1 && objs[0].IsPrefix:
+		// The path is where the folder's contents go
+		m.promptDefault += numberedName(strings.TrimSuffix(objs[0].DisplayName(), "/")) + "/"
+		m.promptText = fmt.Sprintf("Copy everything in %s to:", objs[0].DisplayName())
+	case len(objs) == 1:
+		// Suggest a sibling copy; a path ending in / keeps the name
+		m.promptDefault += numberedName(objs[0].DisplayName())
+		m.promptText = fmt.Sprintf("Copy %s to:", objs[0].DisplayName())
+	default:
+		m.promptText = fmt.Sprintf("Copy %d objects into (s3://bucket/prefix/):", len(objs))
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+}
+
+// rememberFolderCopy keeps a copy of a single folder inside S3 as the last
+// job, so it can be saved and rerun like a sync
+func (m *Model) rememberFolderCopy(files []download.CopyFile, dstBucket string) {
+	if len(files) != 1 || !files[0].Source.IsPrefix {
+		return
+	}
+	m.lastJob = jobs.Job{
+		Kind:       jobs.KindCopy,
+		Profile:    m.profile,
+		Bucket:     m.currentBucket,
+		Prefix:     files[0].Source.Key,
+		DestBucket: dstBucket,
+		DestPrefix: files[0].Key,
+	}
+}
+
+// otherProfiles returns the configured profiles besides the current one,
+// which copies can be written with instead
+func (m Model) otherProfiles() []string {
+	profiles, err := aws.ListProfiles()
+	if err != nil {
+		return nil
+	}
+	var names []string
+	for _, p := range profiles {
+		if p.Name != m.profile {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+// currentProfileOption labels the current profile among copy credentials
+func (m Model) currentProfileOption() string {
+	if m.profile == "" {
+		return "Default credentials"
+	}
+	return m.profile + " (current)"
+}
+
+func (m *Model) showCopyProfilePrompt(profiles []string) {
+	options := append([]string{m.currentProfileOption()}, profiles...)
+
+	m.showPrompt = true
+	m.promptType = "copy-profile"
+	m.promptText = fmt.Sprintf("Write to s3://%s with profile:", m.pendingCopyBucket)
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// Expiry choices for presigned URLs
+const (
+	expiry15m    = "15 minutes"
+	expiry1h     = "1 hour"
+	expiry24h    = "24 hours"
+	customExpiry = "Custom…"
+)
+
+var presignExpiries = map[string]time.Duration{
+	expiry15m: 15 * time.Minute,
+	expiry1h:  time.Hour,
+	expiry24h: 24 * time.Hour,
+}
+
+func (m *Model) showPresignPrompt(key string, upload bool) {
+	options := []string{expiry15m, expiry1h, expiry24h, customExpiry}
+
+	m.showPrompt = true
+	m.promptType = "presign"
+	m.promptText = fmt.Sprintf("Share '%s' with a link valid for:", path.Base(key))
+	if upload {
+		m.promptText = fmt.Sprintf("Let others upload '%s' with a link valid for:", path.Base(key))
+	}
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+	m.pendingPresignKey = key
+	m.pendingPresignUpload = upload
+}
+
+func (m *Model) showPresignUploadPrompt() {
+	if m.currentBucket == "" {
+		return
+	}
+
+	m.showPrompt = true
+	m.promptType = "presign-upload"
+	m.promptDefault = m.currentPrefix
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Key to share an upload link for in s3://%s:", m.currentBucket)
+}
+
+func (m *Model) showPresignExpiryPrompt() {
+	m.showPrompt = true
+	m.promptType = "presign-expiry"
+	m.promptDefault = "3d"
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Link valid for (e.g. 30m, 12h or 3d; at most 7d):"
+}
+
+// takeSnapshot is the inventory prompt's option to save a new snapshot
+const takeSnapshot = "Save a snapshot of this listing"
+
+// deleteSnapshot is the inventory prompt's option to choose a snapshot to
+// delete
+const deleteSnapshot = "Delete a snapshot…"
+
+// maxSnapshotChoices bounds how many snapshots the inventory prompt offers
+const maxSnapshotChoices = 9
+
+// showInventoryPrompt offers to snapshot the current prefix or to diff it
+// against one of its earlier snapshots
+func (m *Model) showInventoryPrompt() {
+	if m.currentBucket == "" || m.inventoryStore == nil {
+		return
+	}
+
+	options := []string{takeSnapshot}
+	m.pendingSnapshots = make(map[string]string)
+	for i, info := range m.inventoryStore.For(m.currentBucket, m.currentPrefix) {
+		if i == maxSnapshotChoices {
+			break
+		}
+		label := fmt.Sprintf("Diff with %s (%d objects, %s)", info.TakenAt.Local().Format("2006-01-02 15:04"), info.Objects, humanize.Bytes(uint64(info.Size)))
+		options = append(options, label)
+		m.pendingSnapshots[label] = info.ID
+	}
+	if len(options) > 1 {
+		options = append(options, deleteSnapshot)
+	}
+
+	m.showPrompt = true
+	m.promptType = "inventory"
+	m.promptText = fmt.Sprintf("Inventory of s3://%s/%s:", m.currentBucket, m.currentPrefix)
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// showSnapshotDeletePrompt offers the current prefix's snapshots to delete
+func (m *Model) showSnapshotDeletePrompt() {
+	var options []string
+	m.pendingSnapshots = make(map[string]string)
+	for _, info := range m.inventoryStore.For(m.currentBucket, m.currentPrefix) {
+		label := fmt.Sprintf("Delete the snapshot from %s (%d objects, %s)", info.TakenAt.Local().Format("2006-01-02 15:04"), info.Objects, humanize.Bytes(uint64(info.Size)))
+		options = append(options, label)
+		m.pendingSnapshots[label] = info.ID
+	}
+	if len(options) == 0 {
+		return
+	}
+
+	m.showPrompt = true
+	m.promptType = "inventory-delete"
+	m.promptText = fmt.Sprintf("Delete a snapshot of s3://%s/%s:", m.currentBucket, m.currentPrefix)
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// Settings that can be changed from the settings prompt, and the column
+// layouts they edit
+const (
+	browserColumnsSetting   = "Browser tab columns…"
+	commanderColumnsSetting = "Local tab (S3 pane) columns…"
+)
+
+var columnSettings = map[string]string{
+	browserColumnsSetting:   config.LayoutBrowser,
+	commanderColumnsSetting: config.LayoutCommander,
+}
+
+func (m *Model) showSettingsPrompt() {
+	options := []string{browserColumnsSetting, commanderColumnsSetting}
+
+	m.showPrompt = true
+	m.promptType = "settings"
+	m.promptText = "Settings:"
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// showColumnsPrompt asks for the fields a layout's object listing shows
+func (m *Model) showColumnsPrompt(layout string) {
+	specs := make([]string, 0, len(s3browser.DefaultColumns))
+	for _, col := range m.columnsFor(layout) {
+		specs = append(specs, config.Column{Field: col.Field, Width: col.Width}.String())
+	}
+
+	m.pendingColumnsLayout = layout
+	m.showPrompt = true
+	m.promptType = "columns"
+	m.promptDefault = strings.Join(specs, ", ")
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Columns in order, as field or field:width (%s; default resets):", strings.Join(config.ColumnFields, ", "))
+}
+
+// Retrieval tiers offered for restores
+var restoreTiers = []string{
+	aws.RestoreStandard + " (3-5 hours; 12 for Deep Archive)",
+	aws.RestoreBulk + " (5-12 hours; 48 for Deep Archive, cheapest)",
+	aws.RestoreExpedited + " (1-5 minutes; not for Deep Archive)",
+}
+
+// showRestorePrompt asks which tier to restore archived objects with
+func (m *Model) showRestorePrompt(objs []aws.S3Object) {
+	m.pendingRestoreObjects = objs
+
+	target := objs[0].Key
+	if len(objs) > 1 {
+		target = fmt.Sprintf("%d objects", len(objs))
+	}
+	m.showPrompt = true
+	m.promptType = "restore-tier"
+	m.promptText = fmt.Sprintf("Restore %s from the archive with:", target)
+	m.promptOptions = restoreTiers
+	m.promptOption = 0
+	m.promptInput = restoreTiers[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+func (m *Model) showRestoreDaysPrompt() {
+	m.showPrompt = true
+	m.promptType = "restore-days"
+	m.promptDefault = "7"
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Keep the restored copy for how many days?"
+}
+
+// Headers the inspector can edit
+const (
+	editContentType  = "Content-Type"
+	editCacheControl = "Cache-Control"
+	editMetadata     = "User metadata"
+)
+
+// handleVersionsKey moves through the version view and undeletes objects
+// from it
+func (m Model) handleVersionsKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	versions := m.versions.Versions
+	switch msg.String() {
+	case "esc", "enter", "V", "q", "backspace":
+		m.versions = nil
+	case "up", "k":
+		m.versionCursor = max(m.versionCursor-1, 0)
+	case "down", "j":
+		m.versionCursor = max(min(m.versionCursor+1, len(versions)-1), 0)
+	case "home", "g":
+		m.versionCursor = 0
+	case "end", "G":
+		m.versionCursor = max(len(versions)-1, 0)
+	case "u":
+		if len(versions) == 0 {
+			return m, nil
+		}
+		v := versions[m.versionCursor]
+		if !v.DeleteMarker || !v.IsLatest {
+			m.errorMsg = "Undeleting: only a delete marker that's the newest version hides an object"
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.statusMsg = "Undeleting " + v.Key + "..."
+		return m, m.deleteVersion(m.versions.Bucket, v)
+	case "D", "x":
+		if len(versions) > 0 {
+			m.showDeleteVersionPrompt(versions[m.versionCursor])
+		}
+	}
+	return m, nil
+}
+
+// showDeleteVersionPrompt asks for the start of v's version ID before
+// deleting it for good, so a version can't be lost to a stray y
+func (m *Model) showDeleteVersionPrompt(v aws.ObjectVersion) {
+	what := "version"
+	if v.DeleteMarker {
+		what = "delete marker"
+	}
+	m.showPrompt = true
+	m.promptType = "delete-version"
+	m.promptText = fmt.Sprintf("Permanently delete %s %s of s3://%s/%s? It can't be recovered. Type %q to go ahead:",
+		what, v.VersionID, m.versions.Bucket, v.Key, versionAnswer(v))
+	m.promptDefault = ""
+	m.promptInput = ""
+	m.promptCursor = 0
+	m.pendingVersion = v
+}
+
+// versionAnswer is what has to be typed to delete v: the start of its ID
+func versionAnswer(v aws.ObjectVersion) string {
+	return v.VersionID[:min(8, len(v.VersionID))]
+}
+
+// showEditHeaderPrompt asks which of the inspected object's headers to edit
+func (m *Model) showEditHeaderPrompt() {
+	options := []string{editContentType, editCacheControl, editMetadata}
+
+	m.showPrompt = true
+	m.promptType = "edit-header"
+	m.promptText = fmt.Sprintf("Edit on '%s' (the object is copied onto itself):", path.Base(m.inspected.Key))
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// showEditHeaderValuePrompt asks for the new value of the pending header
+func (m *Model) showEditHeaderValuePrompt() {
+	d := m.inspected
+	m.showPrompt = true
+	m.promptType = "edit-header-value"
+	switch m.pendingHeader {
+	case editContentType:
+		m.promptDefault = d.ContentType
+		m.promptText = "Content-Type (e.g. application/json):"
+	case editCacheControl:
+		m.promptDefault = d.CacheControl
+		m.promptText = "Cache-Control (e.g. max-age=3600; - removes it):"
+	case editMetadata:
+		m.promptDefault = aws.FormatMetadata(d.Metadata)
+		m.promptText = "User metadata as key=value, key2=value2 (- removes all):"
+	}
+	if m.promptDefault == "" {
+		m.promptDefault = "-"
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+}
+
+// Date jump choices besides the past week's days
+const (
+	otherDate        = "Another date…"
+	changeDateLayout = "Change date layout…"
+)
+
+// showDateJumpPrompt offers the past week's date partitions below the
+// current prefix. The layout comes from the bookmark the prefix is under.
+func (m *Model) showDateJumpPrompt(now time.Time) {
+	if m.currentBucket == "" {
+		return
+	}
+
+	m.pendingDatePattern = bookmarks.DefaultDatePattern
+	m.pendingDateBookmark = ""
+	if m.bookmarkStore != nil {
+		if b, ok := m.bookmarkStore.Covering(m.currentBucket, m.currentPrefix); ok {
+			m.pendingDatePattern = b.DateLayout()
+			m.pendingDateBookmark = b.ID
+		}
+	}
+	m.pendingDateBase = bookmarks.DateBase(m.currentPrefix, m.pendingDatePattern)
+
+	options := make([]string, 0, 9)
+	for i := 0; i < 7; i++ {
+		day := now.AddDate(0, 0, -i)
+		name := day.Weekday().String()
+		switch i {
+		case 0:
+			name = "Today"
+		case 1:
+			name = "Yesterday"
+		}
+		options = append(options, fmt.Sprintf("%-9s  %s", name, day.Format(time.DateOnly)))
+	}
+	options = append(options, otherDate)
+	if m.pendingDateBookmark != "" {
+		options = append(options, changeDateLayout)
+	}
+
+	m.showPrompt = true
+	m.promptType = "date-jump"
+	m.promptText = fmt.Sprintf("Jump to %s%s:", m.pendingDateBase, m.pendingDatePattern)
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+func (m *Model) showDatePrompt() {
+	m.showPrompt = true
+	m.promptType = "date-jump-custom"
+	m.promptDefault = time.Now().Format(time.DateOnly)
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Date (YYYY-MM-DD, or -N for N days ago):"
+}
+
+func (m *Model) showDateLayoutPrompt() {
+	m.showPrompt = true
+	m.promptType = "date-layout"
+	m.promptDefault = m.pendingDatePattern
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = "Date layout for this bookmark (YYYY, MM and DD, e.g. dt=YYYY-MM-DD/):"
+}
+
+// showFreshnessPrompt asks how often new objects are expected under a
+// bookmark
+func (m *Model) showFreshnessPrompt(b bookmarks.Bookmark) {
+	m.pendingFreshnessBookmark = b.ID
+	m.showPrompt = true
+	m.promptType = "bookmark-freshness"
+	m.promptDefault = b.ExpectEvery
+	if m.promptDefault == "" {
+		m.promptDefault = "24h"
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Warn when %s gets nothing new for (e.g. 1h, 24h, 7d; off to stop):", b.DisplayName())
+}
+
+// parseJumpDate parses a date typed into the date jump: YYYY-MM-DD, or -N
+// for N days before now
+func parseJumpDate(s string, now time.Time) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if days, ok := strings.CutPrefix(s, "-"); ok {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or -N)", s)
+		}
+		return now.AddDate(0, 0, -n), nil
+	}
+	day, err := time.Parse(time.DateOnly, s)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q (use YYYY-MM-DD or -N)", s)
+	}
+	return day, nil
+}
+
+// jumpToDate opens the partition for day below the pending date base
+func (m *Model) jumpToDate(day time.Time) tea.Cmd {
+	prefix := m.pendingDateBase + bookmarks.DatePrefix(m.pendingDatePattern, day)
+	m.currentPrefix = prefix
+	return m.openLocation(m.currentBucket, prefix)
+}
+
+// Choices besides the saved jobs themselves: save the most recent sync or
+// folder copy as a job template, or delete one
+const (
+	saveJobOption   = "Save last sync or folder copy as a job…"
+	deleteJobOption = "Delete a saved job…"
+)
+
+// showSavedJobsPrompt lists the saved jobs to run, and offers to save the
+// most recent sync or folder copy as one
+func (m *Model) showSavedJobsPrompt() {
+	var options []string
+	if m.lastJob.Bucket != "" {
+		options = append(options, saveJobOption)
+	}
+	if m.jobStore != nil && len(m.jobStore.List()) > 0 {
+		for _, job := range m.jobStore.List() {
+			options = append(options, job.Name)
+		}
+		options = append(options, deleteJobOption)
+	}
+	if len(options) == 0 {
+		m.statusMsg = "No saved jobs; run a sync or copy a folder, then press J to save it"
+		return
+	}
+
+	m.showPrompt = true
+	m.promptType = "saved-jobs"
+	m.promptText = "Saved jobs:"
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// showDeleteJobPrompt lists the saved jobs to delete one
+func (m *Model) showDeleteJobPrompt() {
+	var options []string
+	for _, job := range m.jobStore.List() {
+		options = append(options, job.Name)
+	}
+
+	m.showPrompt = true
+	m.promptType = "delete-job"
+	m.promptText = "Delete which saved job?"
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// Choices offered when running a saved job
+const (
+	runJobHere         = "Run it here"
+	runJobInBackground = "Run it in the background (outlives stui)"
+)
+
+func (m *Model) showRunJobPrompt(job jobs.Job) {
+	options := []string{runJobHere}
+	if m.backgroundStore != nil {
+		options = append(options, runJobInBackground)
+	}
+
+	m.showPrompt = true
+	m.promptType = "run-job"
+	m.promptText = fmt.Sprintf("Run %s: %s?", job.Name, job.Describe())
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+// updateBackgroundJobs shows the latest state of background jobs, says
+// when one finishes, and keeps polling while any is running
+func (m *Model) updateBackgroundJobs(jobs []daemon.State) tea.Cmd {
+	wasRunning := make(map[string]bool)
+	for _, job := range m.transfersView.Background() {
+		wasRunning[job.ID] = job.Running()
+	}
+	running := false
+	for _, job := range jobs {
+		if job.Running() {
+			running = true
+			continue
+		}
+		if !wasRunning[job.ID] {
+			continue
+		}
+		switch job.Status {
+		case download.StatusCompleted:
+			m.statusMsg = fmt.Sprintf("Background job %s finished: %d files", job.Name, job.CompletedFiles)
+		case download.StatusFailed:
+			m.errorMsg = fmt.Sprintf("Background job %s failed; see Transfers", job.Name)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+	}
+	m.transfersView.SetBackground(jobs)
+
+	if !running || m.backgroundWatch {
+		return nil
+	}
+	m.backgroundWatch = true
+	return backgroundTick()
+}
+
+// clearBackgroundJobs forgets background jobs that have finished, and
+// deletes their logs
+func (m *Model) clearBackgroundJobs() {
+	if m.backgroundStore == nil {
+		return
+	}
+	var kept []daemon.State
+	cleared := 0
+	for _, job := range m.transfersView.Background() {
+		if job.Running() {
+			kept = append(kept, job)
+			continue
+		}
+		if err := m.backgroundStore.Remove(job.ID); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Clearing background jobs")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			kept = append(kept, job)
+			continue
+		}
+		cleared++
+	}
+	m.transfersView.SetBackground(kept)
+	if cleared > 0 {
+		m.statusMsg = fmt.Sprintf("Cleared %d finished background jobs", cleared)
+	}
+}
+
+func (m *Model) showSaveJobPrompt() {
+	name := m.lastJob.Bucket
+	if m.lastJob.Prefix != "" {
+		name = path.Base(strings.TrimSuffix(m.lastJob.Prefix, "/"))
+	}
+
+	m.showPrompt = true
+	m.promptType = "save-job"
+	m.promptDefault = name
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+	m.promptText = fmt.Sprintf("Save %s as:", m.lastJob.Describe())
+}
+
+// showJobPatternsPrompt asks for the include or exclude globs of the job
+// being saved
+func (m *Model) showJobPatternsPrompt(promptType string) {
+	m.showPrompt = true
+	m.promptType = promptType
+	if promptType == "save-job-include" {
+		m.promptText = "Only files matching (globs like *.parquet, comma separated; empty for all):"
+		m.promptDefault = strings.Join(m.pendingJob.Include, ", ")
+	} else {
+		m.promptText = "Skip files matching (globs like tmp/*, comma separated; empty for none):"
+		m.promptDefault = strings.Join(m.pendingJob.Exclude, ", ")
+	}
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+}
+
+// Overwrite policies offered when saving a job
+const (
+	overwriteChanged = "New and changed files"
+	overwriteAlways  = "Every file, even unchanged ones"
+	overwriteNever   = "Only files missing on the other side"
+)
+
+var jobOverwrites = map[string]string{
+	overwriteChanged: jobs.OverwriteChanged,
+	overwriteAlways:  jobs.OverwriteAlways,
+	overwriteNever:   jobs.OverwriteNever,
+}
+
+func (m *Model) showJobOverwritePrompt() {
+	options := []string{overwriteChanged, overwriteAlways, overwriteNever}
+
+	m.showPrompt = true
+	m.promptType = "save-job-overwrite"
+	m.promptText = "Send which files each run?"
+	m.promptOptions = options
+	m.promptOption = 0
+	m.promptInput = options[0]
+	m.promptCursor = len(m.promptInput)
+}
+
+func (m *Model) showJobConcurrencyPrompt() {
+	m.showPrompt = true
+	m.promptType = "save-job-concurrency"
+	m.promptText = "Files to transfer at once:"
+	m.promptDefault = strconv.Itoa(m.pendingJob.Workers())
+	m.promptInput = m.promptDefault
+	m.promptCursor = len(m.promptInput)
+}
+
+// showGuardrailPrompt asks whether to go ahead with op although it's over
+// a guardrail: with y, or in confirm mode by typing its object count.
+// promptType's action runs if the answer is yes.
+func (m *Model) showGuardrailPrompt(promptType, op string, exceeded *config.LimitExceeded) {
+	text := fmt.Sprintf("%s touches %s. Go ahead?", op, exceeded)
+	if !m.settings.Guardrails.TypedConfirmation() {
+		m.showConfirmPrompt(promptType, text)
+		return
+	}
+	m.showPrompt = true
+	m.promptType = promptType
+	m.promptText = fmt.Sprintf("%s touches %s. Type %d to go ahead:", op, exceeded, exceeded.Objects)
+	m.promptDefault = ""
+	m.promptInput = ""
+	m.promptCursor = 0
+	m.pendingGuardAnswer = strconv.Itoa(exceeded.Objects)
+}
+
+// guardrailConfirmed reports whether the answer to a guardrail prompt lets
+// the operation go ahead, consuming the expected answer
+func (m *Model) guardrailConfirmed(input string) bool {
+	want := m.pendingGuardAnswer
+	m.pendingGuardAnswer = ""
+	if want != "" && strings.TrimSpace(input) != want {
+		m.statusMsg = "Cancelled: the count typed didn't match"
+		return false
+	}
+	return true
+}
+
+// showConfirmPrompt asks a yes/no question; y runs promptType's action
+func (m *Model) showConfirmPrompt(promptType, text string) {
+	m.showPrompt = true
+	m.promptType = promptType
+	m.promptText = text
+	m.promptConfirm = true
+	m.promptInput = ""
+	m.promptCursor = 0
+	m.pendingGuardAnswer = "" // y is the answer, not a typed count
+}
+
+// clearPending forgets what a dismissed prompt was asking about, so a
+// later prompt can't act on it
+func (m *Model) clearPending() {
+	m.pendingDownloadObjects = nil
+	m.pendingDownloadDir = ""
+	m.pendingDownloadObject = aws.S3Object{}
+	m.pendingDownloadPath = ""
+	m.pendingDownloadStart = nil
+	m.pendingManifestKeys = nil
+	m.pendingManifestName = ""
+	m.pendingFolderMove = nil
+	m.pendingTransfer = download.QueuedJob{}
+	m.pendingJobStart = nil
+	m.pendingBookmarkBucket = ""
+	m.pendingTemplate = config.Template{}
+	m.pendingUploadPath = ""
+	m.pendingUploadKey = ""
+	m.pendingUploadClass = ""
+	m.pendingUploadSSE = aws.Encryption{}
+	m.pendingResume = nil
+	m.pendingDeleteKey = ""
+	m.pendingCopyObjects = nil
+	m.pendingColumnsLayout = ""
+	m.pendingRestoreObjects = nil
+	m.pendingRestoreTier = ""
+	m.pendingClassObjects = nil
+	m.pendingTagObjects = nil
+	m.pendingCopyFiles = nil
+	m.pendingCopyBucket = ""
+	m.pendingVerifyKey = ""
+	m.pendingMoveObject = aws.S3Object{}
+	m.pendingVersion = aws.ObjectVersion{}
+	m.pendingPresignKey = ""
+	m.pendingPresignUpload = false
+	m.pendingSnapshots = nil
+	m.pendingHeader = ""
+	m.pendingFreshnessBookmark = ""
+	m.pendingDateBase = ""
+	m.pendingDatePattern = ""
+	m.pendingDateBookmark = ""
+	m.pendingGuarded = nil
+	m.pendingGuardAnswer = ""
+	m.pendingJob = jobs.Job{}
+}
+
+func (m Model) handlePromptKey(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	// Confirm prompts take only y or n; anything else is ignored so a stray
+	// enter can't confirm by accident
+	if m.promptConfirm {
+		switch msg.String() {
+		case "y", "Y":
+			m.promptInput = "y"
+			return m.executePromptAction()
+		case "n", "N", "esc":
+			m.showPrompt = false
+			m.promptConfirm = false
+			m.clearPending()
+		}
+		return m, nil
+	}
+
+	// Select prompts only move between their options
+	if len(m.promptOptions) > 0 {
+		switch msg.Type {
+		case tea.KeyUp, tea.KeyShiftTab:
+			m.promptOption = (m.promptOption + len(m.promptOptions) - 1) % len(m.promptOptions)
+			m.promptInput = m.promptOptions[m.promptOption]
+			return m, nil
+		case tea.KeyDown, tea.KeyTab:
+			m.promptOption = (m.promptOption + 1) % len(m.promptOptions)
+			m.promptInput = m.promptOptions[m.promptOption]
+			return m, nil
+		case tea.KeyEsc, tea.KeyEnter:
+		default:
+			return m, nil
+		}
+	}
+
+	switch msg.Type {
+	case tea.KeyEsc:
+		// Naming a transfer is optional, so dismissing it starts the
+		// transfer with whatever it was given so far
+		ready := m.pendingJobStart
+		m.showPrompt = false
+		m.clearPending()
+		m.promptInput = ""
+		m.promptOptions = nil
+		m.promptRecall = 0
+		if ready != nil {
+			return m, m.submitJob(*ready)
+		}
+		return m, nil
+
+	case tea.KeyUp:
+		// Step back through earlier answers to this prompt
+		answers := m.promptHistory()
+		if m.promptRecall < len(answers) {
+			if m.promptRecall == 0 {
+				m.promptDraft = m.promptInput
+			}
+			m.promptRecall++
+			m.promptInput = answers[m.promptRecall-1]
+			m.promptCursor = len(m.promptInput)
+		}
+		return m, nil
+
+	case tea.KeyDown:
+		if m.promptRecall > 0 {
+			m.promptRecall--
+			m.promptInput = m.promptDraft
+			if m.promptRecall > 0 {
+				m.promptInput = m.promptHistory()[m.promptRecall-1]
+			}
+			m.promptCursor = len(m.promptInput)
+		}
+		return m, nil
+
+	case tea.KeyEnter:
+		return m.executePromptAction()
+
+	case tea.KeyBackspace:
+		if len(m.promptInput) > 0 && m.promptCursor > 0 {
+			m.promptInput = m.promptInput[:m.promptCursor-1] + m.promptInput[m.promptCursor:]
+			m.promptCursor--
+		}
+		return m, nil
+
+	case tea.KeyDelete:
+		if m.promptCursor < len(m.promptInput) {
+			m.promptInput = m.promptInput[:m.promptCursor] + m.promptInput[m.promptCursor+1:]
+		}
+		return m, nil
+
+	case tea.KeyLeft:
+		if m.promptCursor > 0 {
+			m.promptCursor--
+		}
+		return m, nil
+
+	case tea.KeyRight:
+		if m.promptCursor < len(m.promptInput) {
+			m.promptCursor++
+		}
+		return m, nil
+
+	case tea.KeyHome, tea.KeyCtrlA:
+		m.promptCursor = 0
+		return m, nil
+
+	case tea.KeyEnd, tea.KeyCtrlE:
+		m.promptCursor = len(m.promptInput)
+		return m, nil
+
+	case tea.KeyRunes, tea.KeySpace:
+		// Insert characters
+		m.promptInput = m.promptInput[:m.promptCursor] + string(msg.Runes) + m.promptInput[m.promptCursor:]
+		m.promptCursor += len(msg.Runes)
+		return m, nil
+	}
+
+	return m, nil
+}
+
+// startPendingDownload asks how fast to download the pending
+// multi-selection into the chosen directory, stripping root from each key
+func (m Model) startPendingDownload(root string) (tea.Model, tea.Cmd) {
+	objs := m.pendingDownloadObjects
+	localDir := m.pendingDownloadDir
+	m.pendingDownloadObjects = nil
+	m.pendingDownloadDir = ""
+	m.showDownloadSpeedPrompt(func(opts aws.DownloadOptions) tea.Cmd {
+		return m.startMultiDownload(objs, localDir, root, opts)
+	})
+	return m, nil
+}
+
+// readManifest reads the keys a manifest file lists. Lines may be s3://
+// URIs, but only for the current bucket, which the job downloads from.
+func (m Model) readManifest(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	entries, err := download.ParseManifest(f, m.currentBucket)
+	if err != nil {
+		return nil, err
+	}
+	keys := make([]string, len(entries))
+	for i, e := range entries {
+		if e.Bucket != m.currentBucket {
+			return nil, fmt.Errorf("line %d is in s3://%s, not s3://%s; use stui cp --from-manifest for several buckets", e.Line, e.Bucket, m.currentBucket)
+		}
+		keys[i] = e.Key
+	}
+	return keys, nil
+}
+
+// showDownloadSpeedPrompt offers the download presets, starting with the
+// configured one, as the last step before start runs the download
+func (m *Model) showDownloadSpeedPrompt(start func(aws.DownloadOptions) tea.Cmd) {
+	options := make([]string, len(aws.DownloadPresets))
+	selected := 0
+	for i, p := range aws.DownloadPresets {
+		options[i] = fmt.Sprintf("%s: %s", p.Name, p.DownloadOptions)
+		if p.DownloadOptions == m.downloadOpts {
+			selected = i
+		}
+	}
+
+	m.pendingDownloadStart = start
+	m.showPrompt = true
+	m.promptType = "download-speed"
+	m.promptText = "Download speed:"
+	m.promptOptions = options
+	m.promptOption = selected
+	m.promptInput = options[selected]
+	m.promptCursor = len(m.promptInput)
+}
+
+// historyPrompts are the free-text prompts whose answers are kept for
+// recall with ↑ and ↓, across sessions
+var historyPrompts = map[string]bool{
+	"download": true, "multi-download": true, "download-root": true, "download-range": true,
+	"manifest": true, "manifest-dest": true,
+	"sync": true, "sync-up": true, "upload": true, "upload-meta": true, "verify": true,
+	"bookmark": true, "bucket-bookmark": true, "copy": true, "move": true,
+	"new-folder": true, "rename-folder": true, "tag-objects": true, "date-jump-custom": true, "save-job": true,
+}
+
+// emptyAnswers are the prompts that act on an empty answer: a transfer's
+// label and note are cleared by it, and a saved job's globs left empty
+// match every file
+var emptyAnswers = map[string]bool{
+	"job-label": true, "job-note": true, "job-start-label": true, "job-start-note": true,
+	"save-job-include": true, "save-job-exclude": true,
+}
+
+// promptHistory returns the earlier answers to the open prompt, newest first
+func (m Model) promptHistory() []string {
+	if m.historyStore == nil || !historyPrompts[m.promptType] {
+		return nil
+	}
+	return m.historyStore.List(m.promptType)
+}
+
+// executePromptAction runs the action for the answered prompt, then saves
+// the answer and any download destination to the prompt history
+func (m Model) executePromptAction() (tea.Model, tea.Cmd) {
+	model, cmd := m.runPromptAction()
+	next, ok := model.(Model)
+	if !ok || len(next.historyWrites) == 0 {
+		return model, cmd
+	}
+	writes := next.historyWrites
+	next.historyWrites = nil
+	return next, tea.Batch(cmd, saveHistory(next.historyStore, writes))
+}
+
+func (m Model) runPromptAction() (tea.Model, tea.Cmd) {
+	m.showPrompt = false
+	input := m.promptInput
+	m.promptInput = ""
+	m.promptOptions = nil
+	m.promptConfirm = false
+	m.promptRecall = 0
+
+	if input == "" && !emptyAnswers[m.promptType] {
+		return m, nil
+	}
+	if m.historyStore != nil && historyPrompts[m.promptType] {
+		kind := m.promptType
+		m.historyWrites = append(m.historyWrites, func(s *history.Store) error {
+			return s.Add(kind, input)
+		})
+	}
+
+	switch m.promptType {
+	case "job-label", "job-note":
+		job := m.pendingTransfer
+		if m.promptType == "job-label" {
+			job.Label = strings.TrimSpace(input)
+		} else {
+			job.Note = strings.TrimSpace(input)
+		}
+		if m.queue == nil || !m.queue.Annotate(job.ID, job.Label, job.Note) {
+			return m, nil
+		}
+		m.transfersView.SetJobs(m.queue.Jobs())
+		// A job that already finished is recorded again under its new name
+		if job, ok := m.queue.Job(job.ID); ok && job.Status != download.StatusPending && job.Status != download.StatusInProgress {
+			return m, m.recordJob(job)
+		}
+		return m, nil
+
+	case "job-start-label":
+		ready := m.pendingJobStart
+		if ready == nil {
+			return m, nil
+		}
+		ready.job.Label = strings.TrimSpace(input)
+		if ready.job.Label == "" {
+			m.pendingJobStart = nil
+			return m, m.submitJob(*ready)
+		}
+		m.showPrompt = true
+		m.promptType = "job-start-note"
+		m.promptText = fmt.Sprintf("Note for '%s' (optional):", ready.job.Label)
+		m.promptDefault = ""
+		m.promptCursor = 0
+		return m, nil
+
+	case "job-start-note":
+		ready := m.pendingJobStart
+		m.pendingJobStart = nil
+		if ready == nil {
+			return m, nil
+		}
+		ready.job.Note = strings.TrimSpace(input)
+		return m, m.submitJob(*ready)
+
+	case "download":
+		obj, _ := m.browserView.SelectedObject()
+		localPath := input
+
+		// Make path absolute if relative
+		if !filepath.IsAbs(localPath) {
+			localPath = filepath.Clean(localPath)
+		}
+		m.rememberDestination(filepath.Dir(localPath))
+
+		// Huge objects can be sampled instead of fetched whole
+		if !obj.IsPrefix && obj.Size >= rangePromptMinSize {
+			m.pendingDownloadObject, m.pendingDownloadPath = obj, localPath
+			m.showDownloadPartPrompt(obj)
+			return m, nil
+		}
+
+		m.showDownloadSpeedPrompt(func(opts aws.DownloadOptions) tea.Cmd {
+			return m.startDownload(obj, localPath, opts)
+		})
+
+	case "download-part":
+		obj, localPath := m.pendingDownloadObject, m.pendingDownloadPath
+		switch input {
+		case headDownload:
+			m.pendingDownloadObject, m.pendingDownloadPath = aws.S3Object{}, ""
+			return m.startPendingRangeDownload(obj, localPath, aws.ByteRange{Length: rangeSampleSize})
+		case tailDownload:
+			m.pendingDownloadObject, m.pendingDownloadPath = aws.S3Object{}, ""
+			return m.startPendingRangeDownload(obj, localPath, aws.ByteRange{Length: rangeSampleSize, FromEnd: true})
+		case rangeDownload:
+			m.showDownloadRangePrompt()
+		default:
+			m.pendingDownloadObject, m.pendingDownloadPath = aws.S3Object{}, ""
+			m.showDownloadSpeedPrompt(func(opts aws.DownloadOptions) tea.Cmd {
+				return m.startDownload(obj, localPath, opts)
+			})
+		}
+
+	case "download-speed":
+		start := m.pendingDownloadStart
+		m.pendingDownloadStart = nil
+		name, _, _ := strings.Cut(input, ":")
+		opts, err := aws.ParseDownloadPreset(name)
+		if err != nil || start == nil {
+			return m, nil
+		}
+		m.activeView = ViewTransfers
+		m.browserView.ClearSelection()
+		return m, start(opts)
+
+	case "download-range":
+		obj, localPath := m.pendingDownloadObject, m.pendingDownloadPath
+		m.pendingDownloadObject, m.pendingDownloadPath = aws.S3Object{}, ""
+		r, err := aws.ParseByteRange(input)
+		if err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Downloading")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		return m.startPendingRangeDownload(obj, localPath, r)
+
+	case "move":
+		obj := m.pendingMoveObject
+		m.pendingMoveObject = aws.S3Object{}
+		return m, m.startMove(obj, input)
+
+	case "verify":
+		key := m.pendingVerifyKey
+		m.pendingVerifyKey = ""
+		m.statusMsg = fmt.Sprintf("Verifying %s against %s…", path.Base(key), input)
+		return m, m.verifyObject(key, filepath.Clean(input))
+
+	case "multi-download":
+		localPath := input
+		if !filepath.IsAbs(localPath) {
+			localPath = filepath.Clean(localPath)
+		}
+
+		m.rememberDestination(localPath)
+		m.pendingDownloadDir = localPath
+		m.showDownloadLayoutPrompt()
+
+	case "rename-folder":
+		plan := m.pendingFolderMove
+		m.pendingFolderMove = nil
+		if plan == nil || plan.Bucket != m.currentBucket {
+			return m, nil
+		}
+		to := strings.TrimPrefix(input, "/")
+		if !strings.HasSuffix(to, "/") {
+			to += "/"
+		}
+		var err error
+		switch {
+		case to == plan.From:
+			return m, nil
+		case strings.HasPrefix(to, plan.From):
+			err = errors.New("a folder can't be moved into itself")
+		default:
+			err = security.ValidFolderName(to)
+		}
+		if err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Renaming folder")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.statusMsg = fmt.Sprintf("Listing %s…", plan.From)
+		return m, m.planFolderMove(plan.From, to)
+
+	case "rename-folder-confirm":
+		plan := m.pendingFolderMove
+		m.pendingFolderMove = nil
+		if plan == nil {
+			return m, nil
+		}
+		m.activeView = ViewTransfers
+		return m, m.guarded("move", plan.Objects, m.startFolderMove(*plan))
+
+	case "manifest":
+		keys, err := m.readManifest(input)
+		if err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Reading the manifest")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.pendingManifestKeys, m.pendingManifestName = keys, filepath.Base(input)
+		m.showManifestDestPrompt()
+
+	case "manifest-dest":
+		keys, name := m.pendingManifestKeys, m.pendingManifestName
+		m.pendingManifestKeys, m.pendingManifestName = nil, ""
+		localDir := filepath.Clean(input)
+		m.rememberDestination(localDir)
+		m.showDownloadSpeedPrompt(func(opts aws.DownloadOptions) tea.Cmd {
+			return m.startManifestDownload(keys, name, localDir, opts)
+		})
+
+	case "download-layout":
+		switch input {
+		case layoutRelative:
+			return m.startPendingDownload(m.currentPrefix)
+		case layoutFullKey:
+			return m.startPendingDownload("")
+		case layoutRoot:
+			m.showDownloadRootPrompt()
+		}
+
+	case "download-root":
+		root := input
+		if !strings.HasSuffix(root, "/") {
+			root += "/"
+		}
+		for _, obj := range m.pendingDownloadObjects {
+			if !strings.HasPrefix(obj.Key, root) {
+				m.errorMsg = fmt.Sprintf("'%s' is not under '%s'", obj.Key, root)
+				m.errorTimeout = time.Now().Add(5 * time.Second)
+				m.pendingDownloadObjects = nil
+				return m, nil
+			}
+		}
+		return m.startPendingDownload(root)
+
+	case "sync":
+		localPath := input
+		if !filepath.IsAbs(localPath) {
+			localPath = filepath.Clean(localPath)
+		}
+
+		m.activeView = ViewTransfers
+
+		bucket, prefix := m.currentBucket, m.currentPrefix
+		m.lastJob = jobs.Job{Kind: jobs.KindSync, Profile: m.profile, Bucket: bucket, Prefix: prefix, LocalDir: localPath}
+		return m, m.queueSync("Sync "+localPath, download.DirectionDownload, func(ctx context.Context, mgr *download.Manager) error {
+			syncMgr := download.NewSyncManager(m.client)
+			syncMgr.SetNormalization(m.normalization)
+			syncMgr.SetSymlinkPolicy(m.symlinkPolicy)
+			return syncMgr.Sync(ctx, bucket, prefix, localPath, mgr)
+		})
+
+	case "sync-up":
+		m.pendingUploadPath = filepath.Clean(input)
+		m.showStorageClassPrompt("sync-up-class", m.currentPrefix)
+
+	case "sync-up-class":
+		m.pendingUploadClass = input
+		m.showEncryptionPrompt("sync-up-sse")
+
+	case "sync-up-sse":
+		localDir, class := m.pendingUploadPath, m.pendingUploadClass
+		m.pendingUploadPath, m.pendingUploadClass = "", ""
+
+		enc, err := aws.ParseEncryption(input)
+		if err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Syncing")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+
+		m.lastJob = jobs.Job{
+			Kind:         jobs.KindSyncUp,
+			Profile:      m.profile,
+			Bucket:       m.currentBucket,
+			Prefix:       m.currentPrefix,
+			LocalDir:     localDir,
+			StorageClass: class,
+			Encryption:   input,
+		}
+		m.activeView = ViewTransfers
+		m.statusMsg = "Comparing local files with S3..."
+		return m, m.startSyncUp(localDir, aws.ObjectAttributes{StorageClass: class, Tags: m.uploadTags, Encryption: enc})
+
+	case "presign":
+		if input == customExpiry {
+			m.showPresignExpiryPrompt()
+			return m, nil
+		}
+		key, upload := m.pendingPresignKey, m.pendingPresignUpload
+		m.pendingPresignKey, m.pendingPresignUpload = "", false
+		return m, m.presignObject(key, upload, presignExpiries[input])
+
+	case "presign-expiry":
+		key, upload := m.pendingPresignKey, m.pendingPresignUpload
+		m.pendingPresignKey, m.pendingPresignUpload = "", false
+		ttl, err := aws.ParsePresignTTL(input)
+		if err != nil {
+			m.errorMsg = err.Error()
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		return m, m.presignObject(key, upload, ttl)
+
+	case "presign-upload":
+		key := input
+		if err := security.ValidObjectKey(key); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Sharing")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if strings.HasSuffix(key, "/") {
+			m.errorMsg = "Upload links need a key for the file, not a folder"
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if !m.checkNamingPolicy(m.currentBucket, key) {
+			return m, nil
+		}
+		m.showPresignPrompt(key, true)
+
+	case "date-jump":
+		switch input {
+		case otherDate:
+			m.showDatePrompt()
+			return m, nil
+		case changeDateLayout:
+			m.showDateLayoutPrompt()
+			return m, nil
+		}
+		// Choices end with the date they stand for
+		day, err := time.Parse(time.DateOnly, input[len(input)-len(time.DateOnly):])
+		if err != nil {
+			return m, nil
+		}
+		return m, m.jumpToDate(day)
+
+	case "date-jump-custom":
+		day, err := parseJumpDate(input, time.Now())
+		if err != nil {
+			m.errorMsg = err.Error()
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		return m, m.jumpToDate(day)
+
+	case "date-layout":
+		if m.bookmarkStore == nil {
+			return m, nil
+		}
+		if err := m.bookmarkStore.SetDatePattern(m.pendingDateBookmark, input); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Setting date layout")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.statusMsg = "Date layout saved"
+		m.showDateJumpPrompt(time.Now())
+
+	case "inventory":
+		if input == takeSnapshot {
+			m.statusMsg = fmt.Sprintf("Listing s3://%s/%s for a snapshot...", m.currentBucket, m.currentPrefix)
+			return m, m.saveSnapshot(m.currentBucket, m.currentPrefix)
+		}
+		if input == deleteSnapshot {
+			m.showSnapshotDeletePrompt()
+			return m, nil
+		}
+		id, ok := m.pendingSnapshots[input]
+		if !ok {
+			return m, nil
+		}
+		for _, info := range m.inventoryStore.For(m.currentBucket, m.currentPrefix) {
+			if info.ID == id {
+				m.statusMsg = "Comparing with snapshot..."
+				return m, m.diffSnapshot(info)
+			}
+		}
+
+	case "inventory-delete":
+		id, ok := m.pendingSnapshots[input]
+		m.pendingSnapshots = nil
+		if !ok {
+			return m, nil
+		}
+		for _, info := range m.inventoryStore.For(m.currentBucket, m.currentPrefix) {
+			if info.ID == id {
+				return m, m.removeSnapshot(info)
+			}
+		}
+
+	case "restore-tier":
+		tier, _, _ := strings.Cut(input, " ")
+		m.pendingRestoreTier = tier
+		m.showRestoreDaysPrompt()
+
+	case "restore-days":
+		objs := m.pendingRestoreObjects
+		m.pendingRestoreObjects = nil
+		days, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || days < 1 || days > aws.MaxRestoreDays {
+			m.errorMsg = fmt.Sprintf("Restoring: days must be a number from 1 to %d", aws.MaxRestoreDays)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.browserView.ClearSelection()
+		m.statusMsg = "Requesting restore..."
+		return m, m.guarded("restore", objs, m.restoreObjects(objs, m.pendingRestoreTier, int32(days)))
+
+	case "settings":
+		if layout, ok := columnSettings[input]; ok {
+			m.showColumnsPrompt(layout)
+		}
+
+	case "columns":
+		var specs []string
+		if !strings.EqualFold(strings.TrimSpace(input), "default") {
+			for _, spec := range strings.Split(input, ",") {
+				if spec = strings.TrimSpace(spec); spec != "" {
+					specs = append(specs, spec)
+				}
+			}
+		}
+		if err := m.settings.SetColumns(m.pendingColumnsLayout, specs); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Saving columns")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.browserView.SetColumns(m.columnsFor(config.LayoutBrowser))
+		m.statusMsg = "Columns saved"
+
+	case "edit-header":
+		if m.inspected == nil {
+			return m, nil
+		}
+		m.pendingHeader = input
+		m.showEditHeaderValuePrompt()
+
+	case "edit-header-value":
+		d := m.inspected
+		if d == nil {
+			return m, nil
+		}
+		value := strings.TrimSpace(input)
+		if value == "-" {
+			value = ""
+		}
+		headers := aws.ObjectHeaders{
+			ContentType:  d.ContentType,
+			CacheControl: d.CacheControl,
+			Metadata:     d.Metadata,
+		}
+		switch m.pendingHeader {
+		case editContentType:
+			if value == "" {
+				m.errorMsg = "Content-Type can't be empty"
+				m.errorTimeout = time.Now().Add(5 * time.Second)
+				return m, nil
+			}
+			headers.ContentType = value
+		case editCacheControl:
+			headers.CacheControl = value
+		case editMetadata:
+			metadata, err := aws.ParseMetadata(value)
+			if err != nil {
+				m.errorMsg = security.SanitizeErrorGeneric(err, "Updating metadata")
+				m.errorTimeout = time.Now().Add(5 * time.Second)
+				return m, nil
+			}
+			headers.Metadata = metadata
+		}
+		m.statusMsg = "Updating metadata of " + path.Base(d.Key) + "..."
+		return m, m.updateObjectHeaders(d, headers)
+
+	case "bookmark-freshness":
+		if m.bookmarkStore == nil {
+			return m, nil
+		}
+		id := m.pendingFreshnessBookmark
+		every := input
+		if strings.EqualFold(every, "off") {
+			every = ""
+		}
+		if err := m.bookmarkStore.SetExpectEvery(id, every); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Setting freshness alert")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		delete(m.staleBookmarks, id)
+		m.bookmarksView.ClearFreshness(id)
+		m.bookmarksView.Refresh()
+		if every == "" {
+			m.statusMsg = "Freshness alert removed"
+			return m, nil
+		}
+		m.statusMsg = "Freshness alert saved"
+		if b, ok := m.bookmarkStore.Get(id); ok {
+			return m, m.checkBookmarkFreshness(b, b.ExpectInterval())
+		}
+
+	case "saved-jobs":
+		if input == saveJobOption {
+			m.showSaveJobPrompt()
+			return m, nil
+		}
+		if m.jobStore == nil {
+			return m, nil
+		}
+		if input == deleteJobOption {
+			m.showDeleteJobPrompt()
+			return m, nil
+		}
+		job, ok := m.jobStore.Find(input)
+		if !ok {
+			return m, nil
+		}
+		m.pendingJob = job
+		m.showRunJobPrompt(job)
+
+	case "save-job":
+		if m.jobStore == nil {
+			return m, nil
+		}
+		if _, taken := m.jobStore.Find(input); taken {
+			m.errorMsg = fmt.Sprintf("A saved job named '%s' already exists", input)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.pendingJob = m.lastJob
+		m.pendingJob.Name = input
+		m.showJobPatternsPrompt("save-job-include")
+
+	case "save-job-include", "save-job-exclude":
+		patterns, err := jobs.ParsePatterns(input)
+		if err != nil {
+			m.pendingJob = jobs.Job{}
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Saving job")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if m.promptType == "save-job-include" {
+			m.pendingJob.Include = patterns
+			m.showJobPatternsPrompt("save-job-exclude")
+			return m, nil
+		}
+		m.pendingJob.Exclude = patterns
+		m.showJobOverwritePrompt()
+
+	case "save-job-overwrite":
+		m.pendingJob.Overwrite = jobOverwrites[input]
+		m.showJobConcurrencyPrompt()
+
+	case "save-job-concurrency":
+		job := m.pendingJob
+		m.pendingJob = jobs.Job{}
+		n, err := strconv.Atoi(strings.TrimSpace(input))
+		if err != nil || n < 1 {
+			m.errorMsg = "Files to transfer at once must be a positive number"
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		job.Concurrency = n
+		if _, err := m.jobStore.Add(job); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Saving job")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		} else {
+			m.statusMsg = fmt.Sprintf("Saved job '%s'; run it with J or stui run-template", job.Name)
+		}
+
+	case "delete-job":
+		job, ok := m.jobStore.Find(input)
+		if !ok {
+			return m, nil
+		}
+		if err := m.jobStore.Remove(job.ID); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Deleting job")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		} else {
+			m.statusMsg = fmt.Sprintf("Deleted job '%s'", job.Name)
+		}
+
+	case "run-job":
+		job := m.pendingJob
+		m.pendingJob = jobs.Job{}
+		if input == runJobInBackground {
+			return m, m.startBackgroundJob(job)
+		}
+		m.activeView = ViewTransfers
+		return m, m.runSavedJob(job)
+
+	case "bookmark":
+		if m.bookmarkStore != nil {
+			_, err := m.bookmarkStore.Add(input, m.currentBucket, m.currentPrefix)
+			if err != nil {
+				m.errorMsg = security.SanitizeErrorGeneric(err, "Adding bookmark")
+				m.errorTimeout = time.Now().Add(5 * time.Second)
+			} else {
+				m.statusMsg = "Bookmark added"
+				m.bookmarksView.Refresh()
+			}
+		}
+
+	case "bucket-bookmark":
+		if m.bookmarkStore != nil && m.pendingBookmarkBucket != "" {
+			_, err := m.bookmarkStore.Add(input, m.pendingBookmarkBucket, "")
+			if err != nil {
+				m.errorMsg = security.SanitizeErrorGeneric(err, "Adding bookmark")
+				m.errorTimeout = time.Now().Add(5 * time.Second)
+			} else {
+				m.statusMsg = "Bookmark added"
+				m.bookmarksView.Refresh()
+			}
+		}
+		m.pendingBookmarkBucket = ""
+
+	case "upload":
+		localPath := filepath.Clean(input)
+		if !m.checkUploadFile(localPath) {
+			return m, nil
+		}
+
+		key := m.currentPrefix + filepath.Base(localPath)
+		if err := security.ValidObjectKey(key); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Uploading")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if !m.checkNamingPolicy(m.currentBucket, key) {
+			return m, nil
+		}
+
+		m.pendingUploadPath = localPath
+		m.pendingUploadKey = key
+		return m, m.checkUploadConflict(key)
+
+	case "guardrail":
+		run := m.pendingGuarded
+		m.pendingGuarded = nil
+		if !m.guardrailConfirmed(input) {
+			return m, nil
+		}
+		return m, run
+
+	case "delete":
+		key := m.pendingDeleteKey
+		m.pendingDeleteKey = ""
+		if !m.guardrailConfirmed(input) {
+			return m, nil
+		}
+		m.browserView.MarkDeleting(key)
+		return m, m.deleteObject(key)
+
+	case "delete-version":
+		v := m.pendingVersion
+		m.pendingVersion = aws.ObjectVersion{}
+		if m.versions == nil {
+			return m, nil
+		}
+		if strings.TrimSpace(input) != versionAnswer(v) {
+			m.statusMsg = "Cancelled: the version ID typed didn't match"
+			return m, nil
+		}
+		m.statusMsg = "Deleting version..."
+		return m, m.deleteVersion(m.versions.Bucket, v)
+
+	case "change-class":
+		objs := m.pendingClassObjects
+		m.pendingClassObjects = nil
+		if len(objs) == 0 {
+			return m, nil
+		}
+		m.browserView.ClearSelection()
+		m.statusMsg = fmt.Sprintf("Moving to %s...", input)
+		return m, m.guarded("change storage class", objs, m.startStorageClassChange(objs, input))
+
+	case "tag-objects":
+		objs := m.pendingTagObjects
+		m.pendingTagObjects = nil
+		tags, err := parseTags(input)
+		if err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Tagging")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.browserView.ClearSelection()
+		m.statusMsg = fmt.Sprintf("Tagging %d objects...", len(objs))
+		return m, m.guarded("tag", objs, m.startTagging(objs, tags))
+
+	case "copy":
+		objs := m.pendingCopyObjects
+		m.pendingCopyObjects = nil
+
+		bucket, dst, err := aws.ParseS3URI(strings.TrimSpace(input))
+		if err == nil {
+			err = security.ValidBucketName(bucket)
+		}
+		if p, _, _, arnErr := aws.ParseBucketARN(strings.TrimSpace(input)); err == nil && arnErr == nil && m.client != nil && p.ID != m.client.Partition().ID {
+			// Credentials only work in their own partition. There's no
+			// profile in demo mode.
+			err = fmt.Errorf("%s is in %s, but this profile is in %s", bucket, p.Name, m.client.Partition().Name)
+		}
+		if err == nil && len(objs) > 1 && dst != "" && !strings.HasSuffix(dst, "/") {
+			// Several objects can only go into a folder
+			dst += "/"
+		}
+		files := make([]download.CopyFile, len(objs))
+		for i, obj := range objs {
+			if err != nil {
+				break
+			}
+			files[i] = download.CopyFile{Source: obj, Key: aws.CopyDestination(obj, dst)}
+			if obj.IsPrefix && len(objs) == 1 {
+				// A lone folder is copied to the path itself
+				files[i].Key = strings.TrimSuffix(dst, "/") + "/"
+			}
+			err = security.ValidObjectKey(files[i].Key)
+		}
+		if err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Copying")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		for _, f := range files {
+			// Keys under folders are checked once the job lists them
+			if !f.Source.IsPrefix && !m.checkNamingPolicy(bucket, f.Key) {
+				return m, nil
+			}
+		}
+
+		// Another profile can write the copies, e.g. into another account
+		if profiles := m.otherProfiles(); len(profiles) > 0 {
+			m.pendingCopyFiles, m.pendingCopyBucket = files, bucket
+			m.showCopyProfilePrompt(profiles)
+			return m, nil
+		}
+
+		m.browserView.ClearSelection()
+		m.activeView = ViewTransfers
+		m.rememberFolderCopy(files, bucket)
+		return m, m.startCopy(files, bucket)
+
+	case "copy-profile":
+		files, bucket := m.pendingCopyFiles, m.pendingCopyBucket
+		m.pendingCopyFiles, m.pendingCopyBucket = nil, ""
+
+		m.browserView.ClearSelection()
+		m.activeView = ViewTransfers
+		if input == m.currentProfileOption() {
+			m.rememberFolderCopy(files, bucket)
+			return m, m.startCopy(files, bucket)
+		}
+		return m, m.startCopyAs(files, bucket, input)
+
+	case "upload-conflict":
+		switch input {
+		case overwriteUpload:
+			return m, m.findIncompleteUpload(m.pendingUploadKey)
+		case renameUpload:
+			m.showUploadRenamePrompt()
+		default:
+			m.statusMsg = fmt.Sprintf("Skipped upload of %s", path.Base(m.pendingUploadKey))
+			m.pendingUploadPath, m.pendingUploadKey = "", ""
+		}
+
+	case "upload-rename":
+		// Keep the folder the upload was headed for
+		dir := strings.TrimSuffix(m.pendingUploadKey, path.Base(m.pendingUploadKey))
+		key := dir + input
+		if err := security.ValidObjectKey(key); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Uploading")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if !m.checkNamingPolicy(m.currentBucket, key) {
+			return m, nil
+		}
+		m.pendingUploadKey = key
+		return m, m.checkUploadConflict(key)
+
+	case "upload-resume":
+		upload := m.pendingResume
+		m.pendingResume = nil
+		if input == resumeUpload {
+			localPath, key := m.pendingUploadPath, m.pendingUploadKey
+			m.pendingUploadPath, m.pendingUploadKey = "", ""
+			m.startedUploadView(localPath, key)
+			return m, m.startResumeUpload(localPath, key, upload)
+		}
+		m.showStorageClassPrompt("upload-class", m.pendingUploadKey)
+		return m, m.abortUpload(upload)
+
+	case "upload-class":
+		m.pendingUploadClass = input
+		m.showEncryptionPrompt("upload-sse")
+
+	case "upload-sse":
+		enc, err := aws.ParseEncryption(input)
+		if err != nil {
+			m.pendingUploadPath, m.pendingUploadKey, m.pendingUploadClass = "", "", ""
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Uploading")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		m.pendingUploadSSE = enc
+		m.showUploadMetadataPrompt()
+
+	case "upload-meta":
+		localPath, key := m.pendingUploadPath, m.pendingUploadKey
+		m.pendingUploadPath, m.pendingUploadKey = "", ""
+
+		attrs, err := parseObjectAttributes(input)
+		if err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Uploading")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		attrs.StorageClass = m.pendingUploadClass
+		attrs.Encryption = m.pendingUploadSSE
+		m.pendingUploadClass, m.pendingUploadSSE = "", aws.Encryption{}
+
+		m.startedUploadView(localPath, key)
+		return m, m.startUpload(localPath, key, attrs)
+
+	case "new-object-template":
+		if tmpl, ok := m.settings.FindTemplate(input); ok {
+			m.showNewObjectPrompt(tmpl)
+		}
+
+	case "new-object":
+		tmpl := m.pendingTemplate
+		m.pendingTemplate = config.Template{}
+
+		key := m.currentPrefix + input
+		if err := security.ValidObjectKey(key); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Creating object")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if !m.checkNamingPolicy(m.currentBucket, key) {
+			return m, nil
+		}
+
+		_, body := tmpl.Render(m.currentBucket, m.currentPrefix, time.Now())
+
+		// Show the object right away; nested keys appear with the refresh
+		if !strings.Contains(input, "/") {
+			m.browserView.MarkCreating(aws.S3Object{Key: key, Size: int64(len(body)), LastModified: time.Now()})
+		}
+		return m, m.createObject(key, []byte(body), tmpl.ContentType)
+
+	case "new-folder":
+		if err := security.ValidFolderName(input); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Creating folder")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		name := strings.TrimSuffix(input, "/")
+		key := m.currentPrefix + name + "/"
+		if err := security.ValidObjectKey(key); err != nil {
+			m.errorMsg = security.SanitizeErrorGeneric(err, "Creating folder")
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return m, nil
+		}
+		if !m.checkNamingPolicy(m.currentBucket, key) {
+			return m, nil
+		}
+
+		// The marker is an empty object; S3 lists it as a common prefix
+		if !strings.Contains(name, "/") {
+			m.browserView.MarkCreating(aws.S3Object{Key: key, IsPrefix: true})
+		}
+		return m, m.createObject(key, nil, "")
+	}
+
+	return m, nil
+}
+
+// checkUploadFile verifies path is a regular file that may be uploaded under
+// the symlink policy. Problems are reported in the status bar.
+func (m *Model) checkUploadFile(path string) bool {
+	info, err := os.Lstat(path)
+	if err == nil && info.Mode()&os.ModeSymlink != 0 {
+		if m.symlinkPolicy != download.SymlinkFollow {
+			m.errorMsg = fmt.Sprintf("Uploading: %s is a symbolic link (symlink policy: %s)", filepath.Base(path), m.symlinkPolicy)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+			return false
+		}
+		info, err = os.Stat(path)
+	}
+	if err != nil {
+		m.errorMsg = security.SanitizeErrorGeneric(err, "Uploading")
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return false
+	}
+	if !info.Mode().IsRegular() {
+		m.errorMsg = "Uploading: not a regular file"
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return false
+	}
+	return true
+}
+
+// checkNamingPolicy validates a key about to be written against the configured
+// naming policies. Violations are reported in the status bar; it returns false
+// if the write should be blocked.
+func (m *Model) checkNamingPolicy(bucket, key string) bool {
+	err := m.settings.CheckKey(bucket, key)
+	if err == nil {
+		return true
+	}
+
+	var violation *config.PolicyViolation
+	if errors.As(err, &violation) && !violation.Blocking() {
+		m.warningMsg = err.Error()
+		m.errorTimeout = time.Now().Add(5 * time.Second)
+		return true
+	}
+
+	m.errorMsg = err.Error()
+	m.errorTimeout = time.Now().Add(5 * time.Second)
+	return false
+}
+
+// jobEventMsg carries progress or completion of a queued transfer job
+type jobEventMsg struct {
+	event download.JobEvent
+	queue *download.Queue
+}
+
+// listenForJobs returns a command that waits for the next job event
+func (m Model) listenForJobs(q *download.Queue) tea.Cmd {
+	return func() tea.Msg {
+		return jobEventMsg{event: <-q.Events(), queue: q}
+	}
+}
+
+// finishJob reports a finished job and refreshes whatever it changed
+func (m *Model) finishJob(job download.QueuedJob) tea.Cmd {
+	final := job.Progress
+	if job.Direction == download.DirectionCopy {
+		if final.Status == download.StatusCompleted {
+			m.statusMsg = fmt.Sprintf("Copied %d objects", final.CompletedFiles)
+		} else if final.Status == download.StatusFailed {
+			m.errorMsg = "Copy failed"
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+		keys := make([]string, 0, len(final.Files))
+		for key := range final.Files {
+			keys = append(keys, key)
+		}
+		return m.refreshObjects(final.Bucket, keys)
+	}
+	if job.Direction == download.DirectionMove {
+		if final.Status == download.StatusCompleted {
+			m.statusMsg = fmt.Sprintf("Moved %d objects", final.CompletedFiles)
+		} else if final.Status == download.StatusFailed {
+			m.errorMsg = fmt.Sprintf("Moving failed for %d of %d objects; see Transfers", final.FailedFiles, final.TotalFiles)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+		// Both the old folder and the new one have changed
+		if final.Bucket == m.currentBucket {
+			return m.browserView.Refresh()
+		}
+		return nil
+	}
+	if job.Direction == download.DirectionTag {
+		if final.Status == download.StatusCompleted {
+			m.statusMsg = fmt.Sprintf("Tagged %d objects", final.CompletedFiles)
+		} else if final.Status == download.StatusFailed {
+			m.errorMsg = fmt.Sprintf("Tagging failed for %d of %d objects; see Transfers", final.FailedFiles, final.TotalFiles)
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+		return nil
+	}
+	if job.Direction == download.DirectionUpload {
+		if final.Status == download.StatusCompleted {
+			m.statusMsg = fmt.Sprintf("Uploaded %d files", final.CompletedFiles)
+			if job.Label != "" {
+				m.statusMsg = fmt.Sprintf("Uploaded %s (%d files)", job.Label, final.CompletedFiles)
+			}
+		} else if final.Status == download.StatusFailed {
+			m.errorMsg = "Upload failed"
+			if job.Label != "" {
+				m.errorMsg = "Upload failed: " + job.Label
+			}
+			m.errorTimeout = time.Now().Add(5 * time.Second)
+		}
+		// Even a failed job may have written some of its files
+		keys := make([]string, 0, len(final.Files))
+		for key := range final.Files {
+			keys = append(keys, key)
+		}
+		return m.refreshObjects(final.Bucket, keys)
+	}
+	m.localView.Reload()
+	if final.Status == download.StatusCompleted {
+		m.statusMsg = fmt.Sprintf("Downloaded %d files", final.CompletedFiles)
+		if job.Label != "" {
+			m.statusMsg = fmt.Sprintf("Downloaded %s (%d files)", job.Label, final.CompletedFiles)
+		}
+	} else if final.Status == download.StatusFailed {
+		m.errorMsg = "Download failed"
+		if job.Label != "" {
+			m.errorMsg = "Download failed: " + job.Label
 		}
+		m.errorTimeout = time.Now().Add(5 * time.Second)
 	}
+	return nil
 }
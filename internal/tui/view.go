@@ -2,13 +2,30 @@ package tui
 
 import (
 	"fmt"
+	"maps"
+	"slices"
 	"strings"
 
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/config"
+	"github.com/natevick/stui/internal/security"
+	"github.com/natevick/stui/internal/views/preview"
 )
 
 // View renders the TUI
 func (m Model) View() string {
+	// Images the preview drew stay on screen in some terminals until
+	// they're removed
+	if m.preview == nil {
+		return preview.ClearGraphics(m.graphics) + m.renderScreen()
+	}
+	return m.renderScreen()
+}
+
+// renderScreen renders the active view and whichever overlay is open
+func (m Model) renderScreen() string {
 	if m.width == 0 || m.height == 0 {
 		return "Loading..."
 	}
@@ -28,6 +45,51 @@ func (m Model) View() string {
 		return m.renderWithPrompt(sb.String())
 	}
 
+	// Preview pager
+	if m.preview != nil {
+		return m.renderPreview()
+	}
+
+	// Dashboard overlay
+	if m.dashboard != nil {
+		return m.renderDashboard()
+	}
+
+	// What's new overlay
+	if m.whatsNew != nil {
+		return m.renderWhatsNew()
+	}
+
+	// About overlay
+	if m.about != nil {
+		return m.renderAbout()
+	}
+
+	// Inspector overlay
+	if m.inspected != nil {
+		return m.renderInspector()
+	}
+
+	// Snapshot diff overlay
+	if m.inventoryDiff != nil {
+		return m.renderInventoryDiff()
+	}
+
+	// Comparison overlay
+	if m.comparison != nil {
+		return m.renderComparison()
+	}
+
+	// Version view overlay
+	if m.versions != nil {
+		return m.renderVersions()
+	}
+
+	// Shared URL overlay
+	if m.sharedURL != "" {
+		return m.renderWithURL()
+	}
+
 	// Help overlay
 	if m.showHelp {
 		return m.renderWithHelp(sb.String())
@@ -57,6 +119,7 @@ func (m Model) renderHeader() string {
 		{"Buckets", ViewBuckets, "1"},
 		{"Browser", ViewBrowser, "2"},
 		{"Bookmarks", ViewBookmarks, "3"},
+		{"Local", ViewLocal, "4"},
 	}
 
 	var tabStrings []string
@@ -70,15 +133,17 @@ func (m Model) renderHeader() string {
 		tabStrings = append(tabStrings, style.Render(fmt.Sprintf("%s [%s]", tab.name, tab.hotkey)))
 	}
 
-	// Add download tab if active
-	if m.downloadView.IsActive() || m.activeView == ViewDownload {
+	// Add transfers tab once any job has started
+	if m.transfersView.HasJobs() || m.activeView == ViewTransfers {
 		var style lipgloss.Style
-		if m.activeView == ViewDownload {
+		if m.activeView == ViewTransfers {
 			style = m.styles.ActiveTab
-		} else {
+		} else if m.transfersView.IsActive() {
 			style = m.styles.Tab.Foreground(ColorWarning)
+		} else {
+			style = m.styles.Tab
 		}
-		tabStrings = append(tabStrings, style.Render("⏬ Downloads"))
+		tabStrings = append(tabStrings, style.Render("⇅ Transfers [5]"))
 	}
 
 	tabLine := strings.Join(tabStrings, m.styles.TabSeparator.Render(" │ "))
@@ -103,10 +168,15 @@ func (m Model) renderHeader() string {
 }
 
 func (m Model) profileDisplay() string {
-	if m.profile != "" {
-		return m.profile
+	name := m.profile
+	if name == "" {
+		name = "default"
+	}
+	// Compliance policies need to see that FIPS endpoints are in use
+	if m.client != nil && m.client.UsesFIPS() {
+		name += " (FIPS)"
 	}
-	return "default"
+	return name
 }
 
 func (m Model) renderContent() string {
@@ -121,10 +191,12 @@ func (m Model) renderContent() string {
 		content = m.bucketsView.View()
 	case ViewBrowser:
 		content = m.browserView.View()
-	case ViewDownload:
-		content = m.downloadView.View()
+	case ViewTransfers:
+		content = m.transfersView.View()
 	case ViewBookmarks:
 		content = m.bookmarksView.View()
+	case ViewLocal:
+		content = m.renderCommander(contentHeight)
 	default:
 		content = "Unknown view"
 	}
@@ -137,11 +209,40 @@ func (m Model) renderContent() string {
 	return style.Render(content)
 }
 
+// renderCommander renders the S3 browser and the local filesystem side by side
+func (m Model) renderCommander(height int) string {
+	paneWidth := commanderPaneWidth(m.width)
+
+	// The browser keeps its full-width size for the Browser tab
+	s3Pane := m.browserView
+	s3Pane.SetSize(paneWidth-2, height-2)
+	s3Pane.SetColumns(m.columnsFor(config.LayoutCommander))
+
+	borderColor := func(focused bool) lipgloss.TerminalColor {
+		if focused {
+			return ColorPrimary
+		}
+		return ColorDim
+	}
+	paneStyle := lipgloss.NewStyle().
+		Width(paneWidth - 2).
+		Height(height - 2).
+		Border(lipgloss.RoundedBorder())
+
+	return lipgloss.JoinHorizontal(
+		lipgloss.Top,
+		paneStyle.BorderForeground(borderColor(!m.localFocus)).Render(s3Pane.View()),
+		paneStyle.BorderForeground(borderColor(m.localFocus)).Render(m.localView.View()),
+	)
+}
+
 func (m Model) renderStatusBar() string {
 	// Left side: status message or error
 	var leftContent string
 	if m.errorMsg != "" {
 		leftContent = m.styles.Error.Render("Error: " + m.errorMsg)
+	} else if m.warningMsg != "" {
+		leftContent = m.styles.Warning.Render("Warning: " + m.warningMsg)
 	} else if m.statusMsg != "" {
 		leftContent = m.styles.Success.Render(m.statusMsg)
 	} else {
@@ -150,6 +251,9 @@ func (m Model) renderStatusBar() string {
 
 	// Right side: key hints
 	rightContent := m.styles.Dim.Render("? help • q quit")
+	if badge := m.restoreBadge(); badge != "" {
+		rightContent = m.styles.Warning.Render(badge) + "  " + rightContent
+	}
 
 	// Calculate spacing
 	leftWidth := lipgloss.Width(leftContent)
@@ -172,16 +276,18 @@ func (m Model) renderContextualHelp() string {
 	case ViewProfiles:
 		return m.styles.Dim.Render("↑↓ navigate • enter select profile • / filter")
 	case ViewBuckets:
-		return m.styles.Dim.Render("↑↓ navigate • enter select • / filter • ←→ tabs")
+		return m.styles.Dim.Render("↑↓ navigate • enter select • b bookmark • o console • s summary • h hide • H show hidden • / filter • ←→ tabs")
 	case ViewBrowser:
-		return m.styles.Dim.Render("↑↓ navigate • space select • enter open • d download • ←→ tabs")
-	case ViewDownload:
-		if m.downloadView.IsActive() {
-			return m.styles.Dim.Render("esc cancel")
+		return m.styles.Dim.Render("↑↓ navigate • space select • enter open • d download • u upload • n new • N folder • e rename • C copy • D delete • f/F narrow • t flat • a by date • ←→ tabs")
+	case ViewTransfers:
+		if m.transfersView.IsActive() {
+			return m.styles.Dim.Render("↑↓ select • enter files • backspace list • n name • N note • esc cancel • w workers")
 		}
-		return m.styles.Dim.Render("←→ switch tabs")
+		return m.styles.Dim.Render("↑↓ select • enter files • backspace list • n name • N note • X clear background • w workers • ←→ switch tabs")
 	case ViewBookmarks:
-		return m.styles.Dim.Render("↑↓ navigate • enter go to • x delete • ←→ tabs")
+		return m.styles.Dim.Render("↑↓ navigate • enter go to • e rename • x delete • A archive • H show archived • n what's new • ←→ tabs")
+	case ViewLocal:
+		return m.styles.Dim.Render("tab switch pane • c copy to other pane • enter open • backspace up • ←→ tabs")
 	default:
 		return ""
 	}
@@ -195,22 +301,45 @@ func (m Model) renderWithPrompt(base string) string {
 		Padding(1, 2).
 		Width(50)
 
-	// Input with cursor
-	input := m.promptInput
-	cursor := "█"
-	if m.promptCursor < len(input) {
-		input = input[:m.promptCursor] + cursor + input[m.promptCursor:]
+	var field, hint string
+	if m.promptConfirm {
+		field = m.styles.PromptInput.Render("[y/n]")
+		hint = "y to confirm • n or Esc to cancel"
+	} else if len(m.promptOptions) > 0 {
+		// Select prompt: list the choices with the highlighted one marked
+		lines := make([]string, len(m.promptOptions))
+		for i, opt := range m.promptOptions {
+			if i == m.promptOption {
+				lines[i] = m.styles.PromptInput.Render("▸ " + opt)
+			} else {
+				lines[i] = m.styles.Dim.Render("  " + opt)
+			}
+		}
+		field = strings.Join(lines, "\n")
+		hint = "↑↓ choose • Enter to confirm • Esc to cancel"
 	} else {
-		input = input + cursor
+		// Input with cursor
+		input := m.promptInput
+		cursor := "█"
+		if m.promptCursor < len(input) {
+			input = input[:m.promptCursor] + cursor + input[m.promptCursor:]
+		} else {
+			input = input + cursor
+		}
+		field = m.styles.PromptInput.Render(input)
+		hint = "Enter to confirm • Esc to cancel"
+		if len(m.promptHistory()) > 0 {
+			hint = "↑↓ earlier answers • " + hint
+		}
 	}
 
 	promptContent := lipgloss.JoinVertical(
 		lipgloss.Left,
 		m.styles.Title.Render(m.promptText),
 		"",
-		m.styles.PromptInput.Render(input),
+		field,
 		"",
-		m.styles.Dim.Render("Enter to confirm • Esc to cancel"),
+		m.styles.Dim.Render(hint),
 	)
 
 	prompt := promptStyle.Render(promptContent)
@@ -227,6 +356,402 @@ func (m Model) renderWithPrompt(base string) string {
 	)
 }
 
+// renderInspector shows everything S3 reported about the inspected object
+func (m Model) renderInspector() string {
+	d := m.inspected
+	inspectorStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2).
+		Width(min(100, m.width-4))
+
+	orNone := func(s string) string {
+		if s == "" {
+			return m.styles.Dim.Render("–")
+		}
+		return s
+	}
+	row := func(label, value string) string {
+		return fmt.Sprintf("  %-20s %s", label, value)
+	}
+	pairs := func(values map[string]string) []string {
+		if len(values) == 0 {
+			return []string{m.styles.Dim.Render("  none")}
+		}
+		keys := slices.Sorted(maps.Keys(values))
+		lines := make([]string, len(keys))
+		for i, k := range keys {
+			lines[i] = row(k, values[k])
+		}
+		return lines
+	}
+
+	lines := []string{
+		m.styles.Title.Render(d.Key),
+		"",
+		row("Size", fmt.Sprintf("%s (%d bytes)", humanize.Bytes(uint64(d.Size)), d.Size)),
+		row("Last modified", d.LastModified.Local().Format("2006-01-02 15:04:05 MST")),
+		row("Storage class", d.StorageClass),
+		row("Encryption", d.Encryption),
+		row("ETag", d.ETag),
+	}
+	for _, c := range d.Checksums {
+		lines = append(lines, row(c.Algorithm, c.Value))
+	}
+	lines = append(lines,
+		row("Version ID", orNone(d.VersionID)),
+		row("Content-Type", orNone(d.ContentType)),
+		row("Cache-Control", orNone(d.CacheControl)),
+	)
+	for _, h := range []struct{ label, value string }{
+		{"Content-Encoding", d.ContentEncoding},
+		{"Content-Disposition", d.ContentDisposition},
+		{"Content-Language", d.ContentLanguage},
+		{"Expires", d.Expires},
+	} {
+		if h.value != "" {
+			lines = append(lines, row(h.label, h.value))
+		}
+	}
+
+	lines = append(lines, "", m.styles.Subtitle.Render("Metadata"))
+	lines = append(lines, pairs(d.Metadata)...)
+	lines = append(lines, "", m.styles.Subtitle.Render("Tags"))
+	if d.TagsErr != nil {
+		lines = append(lines, m.styles.Dim.Render("  unavailable: "+security.SanitizeError(d.TagsErr)))
+	} else {
+		lines = append(lines, pairs(d.Tags)...)
+	}
+	lines = append(lines, "", m.styles.Dim.Render("e edit Content-Type, Cache-Control or metadata • Esc to close"))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		inspectorStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+	)
+}
+
+// maxDiffLines bounds how many objects the snapshot diff lists per section
+const maxDiffLines = 1000
+
+// diffPageSize returns how many lines of the snapshot diff fit at once,
+// below its title and above the help line
+func (m Model) diffPageSize() int {
+	// The border and padding, the title and its gap, and the help line
+	return max(m.height-12, 3)
+}
+
+// diffMaxOffset returns how far the snapshot diff scrolls, leaving its
+// last page in view
+func (m Model) diffMaxOffset() int {
+	if m.inventoryDiff == nil {
+		return 0
+	}
+	d := m.inventoryDiff.Diff
+	n := 0
+	if d.Empty() {
+		n = 1
+	}
+	// Each section has a title, its lines, any "…and more" and a gap
+	for _, count := range []int{len(d.Added), len(d.Removed), len(d.Changed)} {
+		if count == 0 {
+			continue
+		}
+		n += 2 + min(count, maxDiffLines)
+		if count > maxDiffLines {
+			n++
+		}
+	}
+	return max(n-m.diffPageSize(), 0)
+}
+
+// renderInventoryDiff shows what was added, removed and changed under a
+// prefix since a snapshot
+func (m Model) renderInventoryDiff() string {
+	r := m.inventoryDiff
+	d := r.Diff
+	diffStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2).
+		Width(min(100, m.width-4))
+
+	header := []string{
+		m.styles.Title.Render("Changes in " + r.Snapshot.Path()),
+		m.styles.Dim.Render(fmt.Sprintf("since the snapshot of %s (%d objects)",
+			r.Snapshot.TakenAt.Local().Format("2006-01-02 15:04"), r.Snapshot.Objects)),
+		"",
+	}
+	var lines []string
+	if d.Empty() {
+		lines = append(lines, "  No objects added, removed or changed")
+	}
+
+	section := func(title string, n int, line func(i int) string) {
+		if n == 0 {
+			return
+		}
+		lines = append(lines, m.styles.Subtitle.Render(fmt.Sprintf("%s (%d)", title, n)))
+		for i := 0; i < min(n, maxDiffLines); i++ {
+			lines = append(lines, line(i))
+		}
+		if n > maxDiffLines {
+			lines = append(lines, m.styles.Dim.Render(fmt.Sprintf("  …and %d more", n-maxDiffLines)))
+		}
+		lines = append(lines, "")
+	}
+	rel := func(key string) string {
+		return strings.TrimPrefix(key, r.Snapshot.Prefix)
+	}
+	section("Added", len(d.Added), func(i int) string {
+		e := d.Added[i]
+		return fmt.Sprintf("  + %s  %s", rel(e.Key), m.styles.Dim.Render(humanize.Bytes(uint64(e.Size))))
+	})
+	section("Removed", len(d.Removed), func(i int) string {
+		e := d.Removed[i]
+		return fmt.Sprintf("  - %s  %s", rel(e.Key), m.styles.Dim.Render(humanize.Bytes(uint64(e.Size))))
+	})
+	section("Changed", len(d.Changed), func(i int) string {
+		c := d.Changed[i]
+		return fmt.Sprintf("  ~ %s  %s", rel(c.New.Key), m.styles.Dim.Render(
+			humanize.Bytes(uint64(c.Old.Size))+" → "+humanize.Bytes(uint64(c.New.Size))))
+	})
+	// Scrolled to the offset, as far as the last page
+	page := m.diffPageSize()
+	offset := min(m.inventoryDiffOffset, m.diffMaxOffset())
+	help := "Esc to close"
+	if len(lines) > page {
+		lines = lines[offset:min(offset+page, len(lines))]
+		help = "↑↓ scroll • space/b page • Esc to close"
+	}
+	lines = append(header, lines...)
+	lines = append(lines, m.styles.Dim.Render(help))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		diffStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+	)
+}
+
+// renderVersions shows the versions and delete markers under a prefix,
+// scrolled to keep the cursor in view
+func (m Model) renderVersions() string {
+	r := m.versions
+	versionsStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2).
+		Width(min(100, m.width-4))
+
+	lines := []string{
+		m.styles.Title.Render("Versions in s3://" + r.Bucket + "/" + r.Prefix),
+		"",
+	}
+	if len(r.Versions) == 0 {
+		lines = append(lines, "  No versions here; is versioning on for the bucket?")
+	}
+
+	height := max(m.height-12, 3)
+	start := min(max(m.versionCursor-height/2, 0), max(len(r.Versions)-height, 0))
+	end := min(start+height, len(r.Versions))
+	for i := start; i < end; i++ {
+		v := r.Versions[i]
+		// Name each key once, on its newest version
+		name := ""
+		if i == start || r.Versions[i-1].Key != v.Key {
+			name = strings.TrimPrefix(v.Key, r.Prefix)
+		}
+		if runes := []rune(name); len(runes) > 28 {
+			name = string(runes[:27]) + "…"
+		}
+		id := v.VersionID
+		if len(id) > 12 {
+			id = id[:12] + "…"
+		}
+		detail := humanize.Bytes(uint64(v.Size)) + " " + v.StorageClass
+		if v.DeleteMarker {
+			detail = "✗ delete marker"
+		}
+		if v.IsLatest {
+			detail += " (current)"
+		}
+		line := fmt.Sprintf("%-28s %s  %-13s %s", name,
+			v.LastModified.Local().Format("2006-01-02 15:04"), id, detail)
+		switch {
+		case i == m.versionCursor:
+			line = m.styles.SelectedItem.Render("> " + line)
+		case v.DeleteMarker:
+			line = m.styles.Error.Render("  " + line)
+		case !v.IsLatest:
+			line = m.styles.Dim.Render("  " + line)
+		default:
+			line = "  " + line
+		}
+		lines = append(lines, line)
+	}
+	if r.Truncated {
+		lines = append(lines, m.styles.Dim.Render(fmt.Sprintf("  only the first %d versions are shown", len(r.Versions))))
+	}
+
+	lines = append(lines, "")
+	switch {
+	case m.errorMsg != "":
+		lines = append(lines, m.styles.Error.Render("Error: "+m.errorMsg))
+	case m.statusMsg != "":
+		lines = append(lines, m.styles.Success.Render(m.statusMsg))
+	}
+	lines = append(lines, m.styles.Dim.Render("↑↓ move • u undelete (on a current delete marker) • D delete version for good • Esc to close"))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		versionsStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+	)
+}
+
+// renderComparison shows two objects side by side, their differences
+// highlighted, and whether S3 says they hold the same bytes
+func (m Model) renderComparison() string {
+	left, right := m.comparison.Left, m.comparison.Right
+	comparisonStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2).
+		Width(min(120, m.width-4))
+
+	// Values share what's left after the field names
+	width := max((min(120, m.width-4)-4-24)/2, 12)
+	cell := func(s string) string {
+		if s == "" {
+			s = "–"
+		}
+		if runes := []rune(s); len(runes) > width-1 {
+			s = string(runes[:width-2]) + "…"
+		}
+		return fmt.Sprintf("%-*s", width, s)
+	}
+	row := func(name, l, r string) string {
+		return fmt.Sprintf("  %-20s %s %s", name, cell(l), cell(r))
+	}
+
+	lines := []string{
+		m.styles.Title.Render("Compare"),
+		"",
+		m.styles.Subtitle.Render(row("", "s3://"+left.Bucket+"/"+left.Key, "s3://"+right.Bucket+"/"+right.Key)),
+	}
+	for _, f := range aws.CompareObjects(left, right) {
+		line := row(f.Name, f.Left, f.Right)
+		if !f.Same() {
+			line = m.styles.Warning.Render(line)
+		}
+		lines = append(lines, line)
+	}
+
+	lines = append(lines, "")
+	switch same, known, by := aws.SameContent(left, right); {
+	case !known:
+		lines = append(lines, m.styles.Warning.Render("Can't tell if the contents match: no checksum in common, and the ETags aren't MD5s"))
+	case same:
+		lines = append(lines, m.styles.Success.Render("✓ Same content (by "+by+")"))
+	default:
+		lines = append(lines, m.styles.Error.Render("✗ Different content (by "+by+")"))
+	}
+	lines = append(lines, "", m.styles.Dim.Render("Esc to close"))
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		comparisonStyle.Render(lipgloss.JoinVertical(lipgloss.Left, lines...)),
+		lipgloss.WithWhitespaceChars(" "),
+	)
+}
+
+// renderPreview shows an object's content in a pager filling the screen
+func (m Model) renderPreview() string {
+	previewStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(0, 2).
+		Width(m.width - 2)
+	return previewStyle.Render(m.preview.View())
+}
+
+// renderDashboard shows the summary of every bucket filling the screen
+func (m Model) renderDashboard() string {
+	dashboardStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(0, 2).
+		Width(m.width - 2)
+	return dashboardStyle.Render(m.dashboard.View())
+}
+
+// renderWhatsNew shows the newest objects under every bookmark filling the
+// screen
+func (m Model) renderWhatsNew() string {
+	whatsNewStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(0, 2).
+		Width(m.width - 2)
+	return whatsNewStyle.Render(m.whatsNew.View())
+}
+
+// renderAbout shows the version and diagnostics filling the screen
+func (m Model) renderAbout() string {
+	aboutStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(0, 2).
+		Width(m.width - 2)
+	return aboutStyle.Render(m.about.View())
+}
+
+// renderWithURL shows a presigned URL in full so it can be selected
+func (m Model) renderWithURL() string {
+	urlStyle := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(ColorPrimary).
+		Padding(1, 2).
+		Width(min(100, m.width-4))
+
+	hint := "Press any key to close"
+	if m.sharedCurl != "" {
+		hint = "c: copy as curl command • w: copy as PowerShell command • any other key: close"
+	}
+	content := lipgloss.JoinVertical(
+		lipgloss.Left,
+		m.styles.Title.Render("Presigned URL"),
+		"",
+		m.sharedURL,
+		"",
+		m.styles.Dim.Render(m.sharedNote),
+		"",
+		m.styles.Dim.Render(hint),
+	)
+
+	return lipgloss.Place(
+		m.width,
+		m.height,
+		lipgloss.Center,
+		lipgloss.Center,
+		urlStyle.Render(content),
+		lipgloss.WithWhitespaceChars(" "),
+	)
+}
+
 func (m Model) renderWithHelp(base string) string {
 	helpStyle := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -248,17 +773,55 @@ func (m Model) renderWithHelp(base string) string {
 		"  ←/→         Switch tabs",
 		"  Tab         Next tab",
 		"  Shift+Tab   Previous tab",
-		"  1/2/3       Jump to tab",
+		"  1/2/3/4/5   Jump to tab (5: Transfers)",
 		"",
 		m.styles.Subtitle.Render("Selection & Actions"),
 		"  Space       Select/deselect item",
 		"  d           Download selected (or current)",
-		"  s           Sync prefix to local",
+		"  s           Sync prefix to local (Buckets tab: storage summary)",
+		"  S           Sync local directory up to prefix",
+		"  J           Saved jobs (run or delete one, or save the last sync)",
 		"  b           Add bookmark",
-		"  r           Refresh",
+		"  n           New object from template",
+		"  N           New folder (empty marker key)",
+		"  u           Upload local file",
+		"  e           Rename object or bookmark, or move a folder",
+		"  m           Move object to another key (copy + delete)",
+		"  C           Copy objects to another s3:// bucket or prefix",
+		"  T           Change storage class of selected (or current)",
+		"  A           Add tags to selected (or current)",
+		"  R           Restore archived objects (Glacier, Deep Archive)",
+		"  D / x       Delete object (asks first)",
+		"  =           Verify object against a local file (size, ETag, checksums)",
+		"  M           Compare two selected objects, or mark one and press M on another",
+		"  p           Share object with a presigned download URL",
+		"  P           Share a presigned upload URL for a key you type",
+		"  i / Enter   Inspect object (headers, metadata, tags; e edits metadata)",
+		"  V           Versions and delete markers (u undeletes, D deletes a version)",
+		"  o           Open bucket, folder or object in the AWS console",
+		"  y / Y       Copy selected keys / s3:// URIs to the clipboard",
+		"  K           Download the keys listed in a manifest file",
+		"  v           Preview the object's content",
+		"  c           Copy to other pane (Local tab)",
+		"  r           Refresh (keeps filter, cursor and selections)",
 		"  /           Filter list",
+		"  f / F       Narrow listing by another term / undo last",
+		"  t           Toggle flat listing of everything under the prefix",
+		"  a           Group by date (enter on a heading folds it)",
+		"  #           Toggle listing summary (counts, size, newest, biggest)",
+		"  ' + text    Jump to the first name starting with text",
+		"  @           Jump to a date partition (YYYY/MM/DD/ or the bookmark's layout)",
+		"  L           Jump to the latest date or numbered partition",
+		"  I           Snapshot the listing, or diff it against a snapshot",
+		"  a           Set a freshness alert (Bookmarks tab)",
+		"  n           What's new under every bookmark (Bookmarks tab)",
+		"  h           Hide or unhide a bucket (Buckets tab)",
+		"  A           Archive or restore a bookmark (Bookmarks tab)",
+		"  H           Show hidden buckets / archived bookmarks",
 		"",
 		m.styles.Subtitle.Render("General"),
+		"  ,           Settings (listing columns)",
+		"  !           About stui and diagnostics (y copies a report)",
 		"  ?           Toggle this help",
 		"  Esc         Cancel / Close",
 		"  q           Quit",
@@ -279,4 +842,3 @@ func (m Model) renderWithHelp(base string) string {
 		lipgloss.WithWhitespaceForeground(lipgloss.Color("0")),
 	)
 }
-
@@ -0,0 +1,141 @@
+package about
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/mattn/go-runewidth"
+	"github.com/natevick/stui/internal/doctor"
+)
+
+// Model shows stui's version and diagnostics: the local checks at once and
+// the connectivity checks once they finish
+type Model struct {
+	sections     []doctor.Section
+	connectivity *doctor.Section // nil while it's checked
+	offset       int             // first line shown
+	width        int
+	height       int
+
+	titleStyle lipgloss.Style
+	dimStyle   lipgloss.Style
+	headStyle  lipgloss.Style
+	markers    map[doctor.Status]lipgloss.Style
+}
+
+// New creates the screen with the local checks, checking connectivity
+// until SetConnectivity is called
+func New(sections []doctor.Section) Model {
+	return Model{
+		sections:   sections,
+		titleStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		dimStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		headStyle:  lipgloss.NewStyle().Bold(true),
+		markers: map[doctor.Status]lipgloss.Style{
+			doctor.StatusInfo: lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+			doctor.StatusOK:   lipgloss.NewStyle().Foreground(lipgloss.Color("42")),
+			doctor.StatusWarn: lipgloss.NewStyle().Foreground(lipgloss.Color("214")),
+			doctor.StatusFail: lipgloss.NewStyle().Foreground(lipgloss.Color("196")),
+		},
+	}
+}
+
+// SetSize sets the view size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.clamp()
+}
+
+// SetConnectivity shows the connectivity checks
+func (m *Model) SetConnectivity(s doctor.Section) {
+	m.connectivity = &s
+	m.clamp()
+}
+
+// Checking returns true until the connectivity checks are set
+func (m Model) Checking() bool {
+	return m.connectivity == nil
+}
+
+// Sections returns every check shown, for copying as a report
+func (m Model) Sections() []doctor.Section {
+	if m.connectivity == nil {
+		return m.sections
+	}
+	return append(m.sections[:len(m.sections):len(m.sections)], *m.connectivity)
+}
+
+// Update handles messages
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "up", "k":
+		m.offset--
+	case "down", "j":
+		m.offset++
+	case "pgup", "ctrl+u":
+		m.offset -= m.rows()
+	case "pgdown", "ctrl+d":
+		m.offset += m.rows()
+	case "home", "g":
+		m.offset = 0
+	case "end", "G":
+		m.offset = len(m.lines())
+	}
+	m.clamp()
+	return m, nil
+}
+
+// clamp keeps the scroll offset within the lines
+func (m *Model) clamp() {
+	m.offset = max(min(m.offset, len(m.lines())-m.rows()), 0)
+}
+
+// rows returns how many lines fit between the title and the footer
+func (m Model) rows() int {
+	return max(m.height-3, 1)
+}
+
+// lines lays out every section, a heading then a line per check
+func (m Model) lines() []string {
+	nameWidth := 0
+	for _, s := range m.Sections() {
+		for _, c := range s.Checks {
+			nameWidth = max(nameWidth, runewidth.StringWidth(c.Name))
+		}
+	}
+
+	var lines []string
+	for _, s := range m.Sections() {
+		lines = append(lines, m.headStyle.Render(s.Title))
+		for _, c := range s.Checks {
+			detail := runewidth.Truncate(c.Detail, max(m.width-nameWidth-6, 20), "…")
+			lines = append(lines, "  "+m.markers[c.Status].Render(c.Status.Marker())+" "+
+				runewidth.FillRight(c.Name, nameWidth)+"  "+detail)
+		}
+		lines = append(lines, "")
+	}
+	if m.connectivity == nil {
+		lines = append(lines, m.headStyle.Render("Connectivity"), m.dimStyle.Render("  Checking credentials, STS and S3..."))
+	}
+	return lines
+}
+
+// View renders the view
+func (m Model) View() string {
+	title := m.titleStyle.Render("About stui") + m.dimStyle.Render("  diagnostics for bug reports")
+
+	lines := m.lines()
+	body := lines[m.offset:min(m.offset+m.rows(), len(lines))]
+	// Keep the footer at the bottom
+	for len(body) < m.rows() {
+		body = append(body, "")
+	}
+	footer := m.dimStyle.Render("↑↓ scroll • y copy report • r check again • esc close")
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(body, "\n"), footer)
+}
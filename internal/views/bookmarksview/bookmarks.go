@@ -8,16 +8,53 @@ import (
 	"github.com/charmbracelet/bubbles/list"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
 	"github.com/natevick/stui/internal/bookmarks"
+	"github.com/natevick/stui/internal/views/inlineedit"
 )
 
 // Item represents a bookmark in the list
 type Item struct {
-	bookmark bookmarks.Bookmark
+	bookmark  bookmarks.Bookmark
+	editView  string               // inline editor shown in place of the name
+	freshness *bookmarks.Freshness // last freshness check, if the bookmark has a rule
+}
+
+func (i Item) Title() string {
+	if i.editView != "" {
+		return "🔖 " + i.editView
+	}
+	if i.bookmark.Archived {
+		return "🗄️ " + i.bookmark.DisplayName() + " (archived)"
+	}
+	if i.stale() {
+		return "⚠️ " + i.bookmark.DisplayName() + " (stale)"
+	}
+	return "🔖 " + i.bookmark.DisplayName()
+}
+
+func (i Item) Description() string {
+	desc := i.bookmark.Path()
+	if i.bookmark.ExpectEvery == "" {
+		return desc
+	}
+	desc += " • expect every " + i.bookmark.ExpectEvery
+	switch f := i.freshness; {
+	case f == nil:
+	case f.Err != nil:
+		desc += " • check failed"
+	case f.Newest.IsZero():
+		desc += " • no objects"
+	default:
+		desc += " • newest " + humanize.RelTime(f.Newest, f.Checked, "ago", "from now")
+	}
+	return desc
+}
+
+func (i Item) stale() bool {
+	return i.freshness != nil && i.freshness.Stale(i.bookmark.ExpectInterval())
 }
 
-func (i Item) Title() string       { return "🔖 " + i.bookmark.DisplayName() }
-func (i Item) Description() string { return i.bookmark.Path() }
 func (i Item) FilterValue() string { return i.bookmark.DisplayName() }
 
 // Action represents an action to take
@@ -27,18 +64,33 @@ const (
 	ActionNone Action = iota
 	ActionSelect
 	ActionDelete
+	ActionRename
+	ActionSetFreshness
+	ActionWhatsNew
+	ActionArchive
 )
 
+// ActionMsg is sent when the user opens, deletes, renames, archives or
+// sets an alert on a bookmark, or asks what's new under all of them
+type ActionMsg struct {
+	Action Action
+	ID     string
+	Name   string // the new name, for ActionRename
+}
+
 // Model is the bookmarks view model
 type Model struct {
-	list       list.Model
-	bookmarks  []bookmarks.Bookmark
-	store      *bookmarks.Store
-	err        error
-	width      int
-	height     int
-	action     Action
-	selectedID string
+	list         list.Model
+	bookmarks    []bookmarks.Bookmark
+	store        *bookmarks.Store
+	err          error
+	width        int
+	height       int
+	freshness    map[string]bookmarks.Freshness // by bookmark ID
+	archived     int                            // archived bookmarks, listed only while showArchived
+	showArchived bool
+
+	edit inlineedit.Model
 }
 
 // New creates a new bookmarks view
@@ -64,6 +116,7 @@ func New() Model {
 
 	return Model{
 		list: l,
+		edit: inlineedit.New(),
 	}
 }
 
@@ -72,6 +125,7 @@ func (m *Model) SetSize(width, height int) {
 	m.width = width
 	m.height = height
 	m.list.SetSize(width, height)
+	m.edit.SetWidth(width - 8)
 }
 
 // SetStore sets the bookmark store
@@ -86,14 +140,68 @@ func (m *Model) Refresh() {
 		return
 	}
 
-	m.bookmarks = m.store.List()
+	m.bookmarks = nil
+	m.archived = 0
+	for _, b := range m.store.List() {
+		if b.Archived {
+			m.archived++
+			if !m.showArchived {
+				continue
+			}
+		}
+		m.bookmarks = append(m.bookmarks, b)
+	}
+	m.refreshListItems()
+
+	switch {
+	case m.archived == 0:
+		m.list.Title = "Bookmarks"
+	case m.showArchived:
+		m.list.Title = fmt.Sprintf("Bookmarks (showing %d archived)", m.archived)
+	default:
+		m.list.Title = fmt.Sprintf("Bookmarks (%d archived)", m.archived)
+	}
+}
+
+// refreshListItems rebuilds the list items, showing the inline editor on the
+// row being renamed
+func (m *Model) refreshListItems() {
 	items := make([]list.Item, len(m.bookmarks))
 	for i, b := range m.bookmarks {
-		items[i] = Item{bookmark: b}
+		item := Item{bookmark: b}
+		if f, ok := m.freshness[b.ID]; ok && b.ExpectEvery != "" {
+			item.freshness = &f
+		}
+		if m.edit.Active() && m.edit.Target() == b.ID {
+			item.editView = m.edit.View()
+		}
+		items[i] = item
 	}
 	m.list.SetItems(items)
 }
 
+// SetFreshness records the latest freshness check of a bookmark
+func (m *Model) SetFreshness(id string, f bookmarks.Freshness) {
+	if m.freshness == nil {
+		m.freshness = make(map[string]bookmarks.Freshness)
+	}
+	m.freshness[id] = f
+	if !m.edit.Active() {
+		m.refreshListItems()
+	}
+}
+
+// ClearFreshness forgets a bookmark's last freshness check, e.g. after its
+// rule changes
+func (m *Model) ClearFreshness(id string) {
+	delete(m.freshness, id)
+}
+
+// Editing returns true while a bookmark name is being edited inline
+func (m Model) Editing() bool {
+	return m.edit.Active()
+}
+
 // SetError sets an error state
 func (m *Model) SetError(err error) {
 	m.err = err
@@ -109,7 +217,9 @@ func (m Model) SelectedBookmark() (bookmarks.Bookmark, bool) {
 
 // Update handles messages
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
-	m.action = ActionNone
+	if m.edit.Active() {
+		return m.updateEdit(msg)
+	}
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -121,16 +231,41 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			if item, ok := m.list.SelectedItem().(Item); ok {
-				m.action = ActionSelect
-				m.selectedID = item.bookmark.ID
-				return m, nil
+				return m, action(ActionMsg{Action: ActionSelect, ID: item.bookmark.ID})
 			}
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("x", "delete"))):
 			if item, ok := m.list.SelectedItem().(Item); ok {
-				m.action = ActionDelete
-				m.selectedID = item.bookmark.ID
-				return m, nil
+				return m, action(ActionMsg{Action: ActionDelete, ID: item.bookmark.ID})
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("a"))):
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				return m, action(ActionMsg{Action: ActionSetFreshness, ID: item.bookmark.ID})
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("A"))):
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				return m, action(ActionMsg{Action: ActionArchive, ID: item.bookmark.ID})
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("H"))):
+			if m.archived > 0 || m.showArchived {
+				m.showArchived = !m.showArchived
+				m.Refresh()
+			}
+			return m, nil
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("n"))):
+			if len(m.bookmarks) > 0 {
+				return m, action(ActionMsg{Action: ActionWhatsNew})
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("e"))):
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				cmd := m.edit.Start(item.bookmark.ID, item.bookmark.DisplayName())
+				m.refreshListItems()
+				return m, cmd
 			}
 		}
 	}
@@ -140,6 +275,25 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 	return m, cmd
 }
 
+// updateEdit routes messages to the inline editor and reports a rename
+// once it's submitted
+func (m Model) updateEdit(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.edit, cmd = m.edit.Update(msg)
+
+	result, id, name := m.edit.ConsumeResult()
+	name = strings.TrimSpace(name)
+	if result == inlineedit.ResultSubmit && name != "" {
+		for _, bookmark := range m.bookmarks {
+			if bookmark.ID == id && bookmark.Name != name {
+				cmd = tea.Batch(cmd, action(ActionMsg{Action: ActionRename, ID: id, Name: name}))
+			}
+		}
+	}
+	m.refreshListItems()
+	return m, cmd
+}
+
 // View renders the view
 func (m Model) View() string {
 	if m.store == nil {
@@ -174,6 +328,16 @@ func (m Model) renderEmpty() string {
 		Foreground(lipgloss.Color("240"))
 
 	var sb strings.Builder
+	switch {
+	case m.archived == 1:
+		sb.WriteString("Your only bookmark is archived\n\n")
+	case m.archived > 1:
+		fmt.Fprintf(&sb, "All %d bookmarks are archived\n\n", m.archived)
+	}
+	if m.archived > 0 {
+		sb.WriteString("Press 'H' to show them")
+		return style.Render(sb.String())
+	}
 	sb.WriteString("No bookmarks yet\n\n")
 	sb.WriteString("Navigate to a location and press 'b' to bookmark it")
 
@@ -190,16 +354,7 @@ func (m Model) renderError() string {
 	return style.Render(fmt.Sprintf("Error: %v", m.err))
 }
 
-// Action returns the pending action
-func (m Model) Action() Action {
-	return m.action
-}
-
-// ConsumeAction clears and returns the action
-func (m *Model) ConsumeAction() (Action, string) {
-	action := m.action
-	id := m.selectedID
-	m.action = ActionNone
-	m.selectedID = ""
-	return action, id
+// action returns a command reporting msg
+func action(msg ActionMsg) tea.Cmd {
+	return func() tea.Msg { return msg }
 }
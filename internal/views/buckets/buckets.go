@@ -14,10 +14,19 @@ import (
 // Item represents a bucket in the list
 type Item struct {
 	bucket aws.Bucket
+	hidden bool // shown only while hidden buckets are
 }
 
-func (i Item) Title() string       { return i.bucket.Name }
-func (i Item) Description() string { return fmt.Sprintf("Created: %s", i.bucket.CreationDate.Format("2006-01-02")) }
+func (i Item) Title() string {
+	if i.hidden {
+		return i.bucket.Name + " (hidden)"
+	}
+	return i.bucket.Name
+}
+
+func (i Item) Description() string {
+	return fmt.Sprintf("Created: %s", i.bucket.CreationDate.Format("2006-01-02"))
+}
 func (i Item) FilterValue() string { return i.bucket.Name }
 
 // Action represents an action to take
@@ -27,19 +36,30 @@ const (
 	ActionNone Action = iota
 	ActionSelect
 	ActionBookmark
+	ActionConsole
+	ActionSummary
+	ActionHide
 )
 
+// ActionMsg is sent when the user picks a bucket, bookmarks one, opens it
+// in the AWS console, hides or unhides it, or asks for a summary of every
+// bucket
+type ActionMsg struct {
+	Action Action
+	Bucket string
+}
+
 // Model is the buckets view model
 type Model struct {
-	list           list.Model
-	buckets        []aws.Bucket
-	loading        bool
-	err            error
-	width          int
-	height         int
-	selected       string
-	action         Action
-	selectedBucket string
+	list       list.Model
+	buckets    []aws.Bucket
+	loading    bool
+	err        error
+	width      int
+	height     int
+	selected   string
+	hidden     map[string]bool // buckets left out of the list
+	showHidden bool            // list hidden buckets too
 }
 
 // New creates a new buckets view
@@ -80,12 +100,43 @@ func (m *Model) SetSize(width, height int) {
 func (m *Model) SetBuckets(buckets []aws.Bucket) {
 	m.buckets = buckets
 	m.loading = false
+	m.refreshListItems()
+}
+
+// SetHidden sets the buckets left out of the list unless hidden buckets
+// are shown
+func (m *Model) SetHidden(names []string) {
+	m.hidden = make(map[string]bool, len(names))
+	for _, name := range names {
+		m.hidden[name] = true
+	}
+	m.refreshListItems()
+}
 
-	items := make([]list.Item, len(buckets))
-	for i, b := range buckets {
-		items[i] = Item{bucket: b}
+// refreshListItems rebuilds the list items, leaving out hidden buckets
+// unless they're shown, and notes how many there are in the title
+func (m *Model) refreshListItems() {
+	items := make([]list.Item, 0, len(m.buckets))
+	hidden := 0
+	for _, b := range m.buckets {
+		if m.hidden[b.Name] {
+			hidden++
+			if !m.showHidden {
+				continue
+			}
+		}
+		items = append(items, Item{bucket: b, hidden: m.hidden[b.Name]})
 	}
 	m.list.SetItems(items)
+
+	switch {
+	case hidden == 0:
+		m.list.Title = "S3 Buckets"
+	case m.showHidden:
+		m.list.Title = fmt.Sprintf("S3 Buckets (showing %d hidden)", hidden)
+	default:
+		m.list.Title = fmt.Sprintf("S3 Buckets (%d hidden)", hidden)
+	}
 }
 
 // SetError sets an error state
@@ -109,8 +160,6 @@ func (m *Model) SelectedBucket() string {
 
 // Update handles messages
 func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
-	m.action = ActionNone
-
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		// Don't handle keys if filtering
@@ -121,17 +170,33 @@ func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
 		switch {
 		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
 			if item, ok := m.list.SelectedItem().(Item); ok {
-				m.selectedBucket = item.bucket.Name
-				m.action = ActionSelect
-				return m, nil
+				return m, action(ActionSelect, item.bucket.Name)
 			}
 
 		case key.Matches(msg, key.NewBinding(key.WithKeys("b"))):
 			if item, ok := m.list.SelectedItem().(Item); ok {
-				m.selectedBucket = item.bucket.Name
-				m.action = ActionBookmark
-				return m, nil
+				return m, action(ActionBookmark, item.bucket.Name)
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("o"))):
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				return m, action(ActionConsole, item.bucket.Name)
 			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("s"))):
+			if !m.loading && m.err == nil {
+				return m, action(ActionSummary, "")
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("h"))):
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				return m, action(ActionHide, item.bucket.Name)
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("H"))):
+			m.showHidden = !m.showHidden
+			m.refreshListItems()
+			return m, nil
 		}
 	}
 
@@ -189,11 +254,7 @@ func (m *Model) ConsumeSelection() string {
 	return s
 }
 
-// ConsumeAction clears and returns the action
-func (m *Model) ConsumeAction() (Action, string) {
-	action := m.action
-	bucket := m.selectedBucket
-	m.action = ActionNone
-	m.selectedBucket = ""
-	return action, bucket
+// action returns a command reporting action on bucket
+func action(action Action, bucket string) tea.Cmd {
+	return func() tea.Msg { return ActionMsg{Action: action, Bucket: bucket} }
 }
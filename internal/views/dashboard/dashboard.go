@@ -0,0 +1,260 @@
+package dashboard
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-runewidth"
+	"github.com/natevick/stui/internal/aws"
+)
+
+// topBuckets is how many buckets the largest and newest lists show
+const topBuckets = 10
+
+// maxBarWidth is the widest a region's bar is drawn
+const maxBarWidth = 30
+
+// ActionMsg is sent when the user opens a bucket from the dashboard
+type ActionMsg struct {
+	Bucket string
+}
+
+// line is a line of the dashboard, and the bucket it opens, if any
+type line struct {
+	text   string
+	bucket string
+}
+
+// Model is a summary of every bucket a profile can see: how many are in
+// each region, the largest and the newest
+type Model struct {
+	profile string
+	summary *aws.AccountSummary
+	err     error
+	lines   []line
+	cursor  int // line of the highlighted bucket
+	offset  int // first line shown
+	width   int
+	height  int
+
+	titleStyle    lipgloss.Style
+	headingStyle  lipgloss.Style
+	dimStyle      lipgloss.Style
+	barStyle      lipgloss.Style
+	selectedStyle lipgloss.Style
+}
+
+// New creates a dashboard for profile, loading until SetSummary is called
+func New(profile string) Model {
+	return Model{
+		profile:       profile,
+		titleStyle:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		headingStyle:  lipgloss.NewStyle().Bold(true),
+		dimStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		barStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color("39")),
+		selectedStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Background(lipgloss.Color("39")).Bold(true),
+	}
+}
+
+// SetSize sets the view size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.lines = m.layout()
+	m.scroll()
+}
+
+// SetSummary shows a summary, or why it couldn't be made
+func (m *Model) SetSummary(summary *aws.AccountSummary, err error) {
+	m.summary = summary
+	m.err = err
+	m.lines = m.layout()
+	m.cursor = m.next(-1, 1)
+	m.offset = 0
+	m.scroll()
+}
+
+// Loading returns true until a summary is set
+func (m Model) Loading() bool {
+	return m.summary == nil && m.err == nil
+}
+
+// Update handles messages
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "up", "k":
+		m.cursor = m.next(m.cursor, -1)
+	case "down", "j":
+		m.cursor = m.next(m.cursor, 1)
+	case "home", "g":
+		m.cursor = m.next(-1, 1)
+	case "end", "G":
+		m.cursor = m.next(len(m.lines), -1)
+	case "enter":
+		if m.cursor >= 0 && m.cursor < len(m.lines) {
+			bucket := m.lines[m.cursor].bucket
+			return m, func() tea.Msg { return ActionMsg{Bucket: bucket} }
+		}
+	}
+	m.scroll()
+	return m, nil
+}
+
+// next returns the next line from i in direction dir that opens a bucket,
+// or i if there's none
+func (m Model) next(i, dir int) int {
+	for j := i + dir; j >= 0 && j < len(m.lines); j += dir {
+		if m.lines[j].bucket != "" {
+			return j
+		}
+	}
+	return i
+}
+
+// scroll keeps the highlighted line on screen
+func (m *Model) scroll() {
+	rows := m.rows()
+	if m.cursor < m.offset {
+		m.offset = max(m.cursor, 0)
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// rows returns how many lines fit between the title and the footer
+func (m Model) rows() int {
+	return max(m.height-4, 1)
+}
+
+// layout lays out the summary's sections
+func (m Model) layout() []line {
+	s := m.summary
+	if s == nil {
+		return nil
+	}
+	var lines []line
+	text := func(format string, args ...any) {
+		lines = append(lines, line{text: fmt.Sprintf(format, args...)})
+	}
+
+	regions := s.Regions()
+	size, objects := s.Total()
+	measured := 0
+	for _, b := range s.Buckets {
+		if !b.MeasuredAt.IsZero() {
+			measured++
+		}
+	}
+	totals := plural(len(s.Buckets), "bucket") + " in " + plural(len(regions), "region")
+	if measured > 0 {
+		totals += fmt.Sprintf(" • %s in %s objects", humanize.Bytes(uint64(size)), humanize.Comma(objects))
+	}
+	text("%s", totals)
+	switch {
+	case s.MetricsErr != nil:
+		notice := runewidth.Truncate("metrics unavailable: "+firstLine(s.MetricsErr.Error()), max(m.width, 20), "…")
+		lines = append(lines, line{text: m.dimStyle.Render(notice)})
+	case measured < len(s.Buckets):
+		lines = append(lines, line{text: m.dimStyle.Render(fmt.Sprintf(
+			"sizes from CloudWatch's daily storage metrics; %d of %d buckets have none yet", len(s.Buckets)-measured, len(s.Buckets)))})
+	}
+
+	lines = append(lines, line{}, line{text: m.headingStyle.Render("Buckets by region")})
+	most := 0
+	regionWidth := 0
+	for _, r := range regions {
+		most = max(most, r.Buckets)
+		regionWidth = max(regionWidth, runewidth.StringWidth(r.Region))
+	}
+	for _, r := range regions {
+		bar := strings.Repeat("█", max(r.Buckets*maxBarWidth/most, 1))
+		size := ""
+		if r.Size > 0 {
+			size = m.dimStyle.Render(humanize.Bytes(uint64(r.Size)))
+		}
+		text("  %s %s %d  %s", runewidth.FillRight(r.Region, regionWidth),
+			m.barStyle.Render(runewidth.FillRight(bar, maxBarWidth)), r.Buckets, size)
+	}
+
+	largest := s.Largest(topBuckets)
+	lines = append(lines, line{}, line{text: m.headingStyle.Render("Largest buckets")})
+	if len(largest) == 0 {
+		lines = append(lines, line{text: m.dimStyle.Render("  no storage metrics")})
+	}
+	nameWidth := bucketWidth(largest)
+	for _, b := range largest {
+		lines = append(lines, line{bucket: b.Name, text: fmt.Sprintf("%s %9s %14s objects  %s",
+			runewidth.FillRight(b.Name, nameWidth), humanize.Bytes(uint64(b.Size)), humanize.Comma(b.Objects), m.dimStyle.Render(b.Region))})
+	}
+
+	newest := s.Newest(topBuckets)
+	lines = append(lines, line{}, line{text: m.headingStyle.Render("Newest buckets")})
+	nameWidth = bucketWidth(newest)
+	for _, b := range newest {
+		lines = append(lines, line{bucket: b.Name, text: fmt.Sprintf("%s created %s  %s",
+			runewidth.FillRight(b.Name, nameWidth), b.CreationDate.Format("2006-01-02"), m.dimStyle.Render(b.Region))})
+	}
+	return lines
+}
+
+// View renders the view
+func (m Model) View() string {
+	title := m.titleStyle.Render("Storage summary") + m.dimStyle.Render("  profile "+m.profile)
+
+	var body []string
+	switch {
+	case m.err != nil:
+		body = []string{"Error summarizing buckets:", "", m.err.Error()}
+	case m.summary == nil:
+		body = []string{m.dimStyle.Render("Summarizing buckets...")}
+	default:
+		for i, l := range m.lines[m.offset:min(m.offset+m.rows(), len(m.lines))] {
+			switch {
+			case m.offset+i == m.cursor:
+				body = append(body, m.selectedStyle.Render("> "+l.text))
+			case l.bucket != "":
+				body = append(body, "  "+l.text)
+			default:
+				body = append(body, l.text)
+			}
+		}
+	}
+
+	// Keep the footer at the bottom
+	for len(body) < m.rows() {
+		body = append(body, "")
+	}
+	footer := m.dimStyle.Render("↑↓ select • enter open bucket • r refresh • esc close")
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(body, "\n"), footer)
+}
+
+// bucketWidth returns the width of the longest bucket name
+func bucketWidth(buckets []aws.BucketStats) int {
+	width := 0
+	for _, b := range buckets {
+		width = max(width, runewidth.StringWidth(b.Name))
+	}
+	return width
+}
+
+// plural returns n and noun, adding an s unless n is 1
+func plural(n int, noun string) string {
+	if n == 1 {
+		return "1 " + noun
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
+
+// firstLine returns s up to its first newline
+func firstLine(s string) string {
+	first, _, _ := strings.Cut(s, "\n")
+	return first
+}
@@ -0,0 +1,110 @@
+package inlineedit
+
+import (
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Result is how an edit finished
+type Result int
+
+const (
+	ResultNone   Result = iota // still editing, or not started
+	ResultSubmit               // enter pressed
+	ResultCancel               // esc pressed
+)
+
+// Model is a one-line text field drawn in place of a list row's name while
+// the user edits it, so the rest of the list stays visible around it
+type Model struct {
+	input  textinput.Model
+	target string // ID or key of the row being edited
+	active bool
+	result Result
+}
+
+// New creates an idle inline editor
+func New() Model {
+	input := textinput.New()
+	input.Prompt = ""
+	input.CharLimit = 1024
+	return Model{input: input}
+}
+
+// SetWidth sets the width of the text field
+func (m *Model) SetWidth(width int) {
+	m.input.Width = width
+}
+
+// Start begins editing target, starting from value
+func (m *Model) Start(target, value string) tea.Cmd {
+	m.target = target
+	m.active = true
+	m.result = ResultNone
+	m.input.SetValue(value)
+	m.input.CursorEnd()
+	return m.input.Focus()
+}
+
+// Active returns true while an edit is in progress
+func (m Model) Active() bool {
+	return m.active
+}
+
+// Target returns the ID or key of the row being edited
+func (m Model) Target() string {
+	return m.target
+}
+
+// Update handles messages while an edit is in progress
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if !m.active {
+		return m, nil
+	}
+
+	if keyMsg, ok := msg.(tea.KeyMsg); ok {
+		switch keyMsg.String() {
+		case "enter":
+			m.finish(ResultSubmit)
+			return m, nil
+		case "esc":
+			m.finish(ResultCancel)
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.input, cmd = m.input.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) finish(result Result) {
+	m.active = false
+	m.result = result
+	m.input.Blur()
+}
+
+// Cancel abandons the edit without reporting a result, e.g. when the row
+// being edited goes away
+func (m *Model) Cancel() {
+	m.active = false
+	m.result = ResultNone
+	m.target = ""
+	m.input.Blur()
+}
+
+// ConsumeResult returns and clears the outcome of a finished edit
+func (m *Model) ConsumeResult() (Result, string, string) {
+	result, target, value := m.result, m.target, m.input.Value()
+	if result == ResultNone {
+		return ResultNone, "", ""
+	}
+	m.result = ResultNone
+	m.target = ""
+	return result, target, value
+}
+
+// View renders the text field
+func (m Model) View() string {
+	return m.input.View()
+}
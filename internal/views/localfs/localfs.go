@@ -0,0 +1,238 @@
+package localfs
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// Entry is a file or directory in the local filesystem
+type Entry struct {
+	Name  string
+	Path  string
+	Size  int64
+	IsDir bool
+	Info  os.FileInfo
+}
+
+// Item represents a local entry in the list
+type Item struct {
+	entry Entry
+}
+
+func (i Item) Title() string {
+	if i.entry.IsDir {
+		return "📁 " + i.entry.Name + "/"
+	}
+	return "📄 " + i.entry.Name
+}
+
+func (i Item) Description() string {
+	if i.entry.IsDir {
+		if i.entry.Name == ".." {
+			return "parent directory"
+		}
+		return "folder"
+	}
+	return fmt.Sprintf("%s  •  %s",
+		humanize.Bytes(uint64(i.entry.Size)),
+		i.entry.Info.ModTime().Format("2006-01-02 15:04"),
+	)
+}
+
+func (i Item) FilterValue() string { return i.entry.Name }
+
+// Model is the local filesystem pane
+type Model struct {
+	list    list.Model
+	dir     string
+	entries []Entry
+	focused bool
+	err     error
+	width   int
+	height  int
+}
+
+// New creates a local filesystem pane rooted at dir
+func New(dir string) Model {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(lipgloss.Color("255")).
+		Background(lipgloss.Color("78")).
+		Bold(true)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(lipgloss.Color("252")).
+		Background(lipgloss.Color("78"))
+
+	l := list.New([]list.Item{}, delegate, 0, 0)
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetShowHelp(false)
+	l.Styles.Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("78")).
+		Padding(0, 1)
+
+	if abs, err := filepath.Abs(dir); err == nil {
+		dir = abs
+	}
+
+	m := Model{
+		list: l,
+		dir:  dir,
+	}
+	m.Reload()
+	return m
+}
+
+// SetSize sets the view size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.list.SetSize(width, height-2) // Reserve space for path
+}
+
+// SetFocused marks the pane as receiving keys
+func (m *Model) SetFocused(focused bool) {
+	m.focused = focused
+}
+
+// Focused returns true if the pane receives keys
+func (m Model) Focused() bool {
+	return m.focused
+}
+
+// Filtering returns true while the filter input has focus
+func (m Model) Filtering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+// Dir returns the current directory
+func (m Model) Dir() string {
+	return m.dir
+}
+
+// Reload re-reads the current directory
+func (m *Model) Reload() {
+	dirEntries, err := os.ReadDir(m.dir)
+	if err != nil {
+		m.err = err
+		m.entries = nil
+		m.list.SetItems(nil)
+		return
+	}
+	m.err = nil
+
+	entries := make([]Entry, 0, len(dirEntries)+1)
+	if parent := filepath.Dir(m.dir); parent != m.dir {
+		if info, err := os.Stat(parent); err == nil {
+			entries = append(entries, Entry{Name: "..", Path: parent, IsDir: true, Info: info})
+		}
+	}
+
+	var children []Entry
+	for _, de := range dirEntries {
+		info, err := de.Info()
+		if err != nil {
+			continue
+		}
+		children = append(children, Entry{
+			Name:  de.Name(),
+			Path:  filepath.Join(m.dir, de.Name()),
+			Size:  info.Size(),
+			IsDir: info.IsDir(),
+			Info:  info,
+		})
+	}
+
+	// Directories first, then files, alphabetically
+	sort.Slice(children, func(i, j int) bool {
+		if children[i].IsDir != children[j].IsDir {
+			return children[i].IsDir
+		}
+		return strings.ToLower(children[i].Name) < strings.ToLower(children[j].Name)
+	})
+	entries = append(entries, children...)
+
+	m.entries = entries
+	items := make([]list.Item, len(entries))
+	for i, e := range entries {
+		items[i] = Item{entry: e}
+	}
+	m.list.SetItems(items)
+	m.list.Title = m.dir
+}
+
+// SelectedEntry returns the entry under the cursor
+func (m Model) SelectedEntry() (Entry, bool) {
+	if item, ok := m.list.SelectedItem().(Item); ok {
+		return item.entry, true
+	}
+	return Entry{}, false
+}
+
+// Update handles messages
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// Don't handle keys if filtering
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+
+		switch {
+		case key.Matches(msg, key.NewBinding(key.WithKeys("enter"))):
+			if entry, ok := m.SelectedEntry(); ok && entry.IsDir {
+				m.chdir(entry.Path)
+				return m, nil
+			}
+
+		case key.Matches(msg, key.NewBinding(key.WithKeys("backspace"))):
+			if parent := filepath.Dir(m.dir); parent != m.dir {
+				m.chdir(parent)
+				return m, nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	return m, cmd
+}
+
+func (m *Model) chdir(dir string) {
+	m.dir = dir
+	m.list.ResetFilter()
+	m.list.Select(0)
+	m.Reload()
+}
+
+// View renders the view
+func (m Model) View() string {
+	var sb strings.Builder
+
+	pathStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	if m.focused {
+		pathStyle = pathStyle.Foreground(lipgloss.Color("78")).Bold(true)
+	}
+	sb.WriteString(pathStyle.Render("💻 " + m.dir))
+	sb.WriteString("\n\n")
+
+	if m.err != nil {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Render(fmt.Sprintf("Error: %v", m.err)))
+		return sb.String()
+	}
+
+	sb.WriteString(m.list.View())
+	return sb.String()
+}
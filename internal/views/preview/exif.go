@@ -0,0 +1,254 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"strings"
+)
+
+// exifField is a line of EXIF data shown with an image
+type exifField struct {
+	name  string
+	value string
+}
+
+// EXIF tags shown, by the IFD they're in
+const (
+	tagMake        = 0x010F
+	tagModel       = 0x0110
+	tagOrientation = 0x0112
+	tagSoftware    = 0x0131
+	tagDateTime    = 0x0132
+	tagExifIFD     = 0x8769
+	tagGPSIFD      = 0x8825
+
+	tagExposureTime     = 0x829A
+	tagFNumber          = 0x829D
+	tagISO              = 0x8827
+	tagDateTimeOriginal = 0x9003
+	tagFocalLength      = 0x920A
+
+	tagLatitudeRef  = 1
+	tagLatitude     = 2
+	tagLongitudeRef = 3
+	tagLongitude    = 4
+)
+
+// orientations describes the EXIF orientations other than upright
+var orientations = map[uint32]string{
+	2: "mirrored",
+	3: "rotated 180°",
+	4: "flipped",
+	5: "mirrored, rotated 90° counterclockwise",
+	6: "rotated 90° clockwise",
+	7: "mirrored, rotated 90° clockwise",
+	8: "rotated 90° counterclockwise",
+}
+
+// readEXIF returns the camera, time taken, exposure, orientation and
+// location from a JPEG's EXIF data, or nil if it has none
+func readEXIF(data []byte) []exifField {
+	t, ok := findEXIF(data)
+	if !ok {
+		return nil
+	}
+	ifd0 := t.ifd(t.order.Uint32(t.data[4:8]))
+	exif := t.ifd(ifd0.uint(t, tagExifIFD))
+	gps := t.ifd(ifd0.uint(t, tagGPSIFD))
+
+	var fields []exifField
+	add := func(name, value string) {
+		if value != "" {
+			fields = append(fields, exifField{name, value})
+		}
+	}
+
+	camera, model := ifd0.string(t, tagMake), ifd0.string(t, tagModel)
+	if !strings.HasPrefix(model, camera) {
+		camera = strings.TrimSpace(camera + " " + model)
+	} else {
+		camera = model
+	}
+	add("Camera", camera)
+
+	taken := exif.string(t, tagDateTimeOriginal)
+	if taken == "" {
+		taken = ifd0.string(t, tagDateTime)
+	}
+	// EXIF writes dates as 2006:01:02 15:04:05
+	if date, clock, ok := strings.Cut(taken, " "); ok {
+		taken = strings.ReplaceAll(date, ":", "-") + " " + clock
+	}
+	add("Taken", taken)
+
+	var exposure []string
+	if num, den, ok := exif.rational(t, tagExposureTime, 0); ok && num > 0 {
+		if num < den {
+			exposure = append(exposure, fmt.Sprintf("1/%.0f s", float64(den)/float64(num)))
+		} else {
+			exposure = append(exposure, fmt.Sprintf("%g s", float64(num)/float64(den)))
+		}
+	}
+	if num, den, ok := exif.rational(t, tagFNumber, 0); ok {
+		exposure = append(exposure, fmt.Sprintf("f/%.1f", float64(num)/float64(den)))
+	}
+	if iso := exif.uint(t, tagISO); iso > 0 {
+		exposure = append(exposure, fmt.Sprintf("ISO %d", iso))
+	}
+	if num, den, ok := exif.rational(t, tagFocalLength, 0); ok {
+		exposure = append(exposure, fmt.Sprintf("%g mm", float64(num)/float64(den)))
+	}
+	add("Exposure", strings.Join(exposure, "  "))
+
+	add("Orientation", orientations[ifd0.uint(t, tagOrientation)])
+	add("Location", location(t, gps))
+	add("Software", ifd0.string(t, tagSoftware))
+	return fields
+}
+
+// location returns the GPS position, e.g. "51.50722° N, 0.12750° W"
+func location(t tiff, gps ifd) string {
+	coordinate := func(tag uint16) (float64, bool) {
+		var degrees float64
+		for i, unit := range []float64{1, 60, 3600} {
+			num, den, ok := gps.rational(t, tag, i)
+			if !ok {
+				return 0, false
+			}
+			degrees += float64(num) / float64(den) / unit
+		}
+		return degrees, true
+	}
+	lat, ok := coordinate(tagLatitude)
+	if !ok {
+		return ""
+	}
+	lon, ok := coordinate(tagLongitude)
+	if !ok {
+		return ""
+	}
+	return fmt.Sprintf("%.5f° %s, %.5f° %s", lat, gps.string(t, tagLatitudeRef), lon, gps.string(t, tagLongitudeRef))
+}
+
+// findEXIF returns the TIFF structure holding a JPEG's EXIF data, from its
+// APP1 segment
+func findEXIF(data []byte) (tiff, bool) {
+	if len(data) < 4 || data[0] != 0xFF || data[1] != 0xD8 {
+		return tiff{}, false
+	}
+	for i := 2; i+4 <= len(data); {
+		if data[i] != 0xFF {
+			return tiff{}, false
+		}
+		marker := data[i+1]
+		if marker == 0xDA || marker == 0xD9 {
+			return tiff{}, false // the image data, after any metadata
+		}
+		length := int(binary.BigEndian.Uint16(data[i+2 : i+4]))
+		end := i + 2 + length
+		if length < 2 || end > len(data) {
+			return tiff{}, false
+		}
+		if segment := data[i+4 : end]; marker == 0xE1 && bytes.HasPrefix(segment, []byte("Exif\x00\x00")) {
+			return newTIFF(segment[6:])
+		}
+		i = end
+	}
+	return tiff{}, false
+}
+
+// tiff is the TIFF structure EXIF data is stored in
+type tiff struct {
+	data  []byte
+	order binary.ByteOrder
+}
+
+func newTIFF(data []byte) (tiff, bool) {
+	if len(data) < 8 {
+		return tiff{}, false
+	}
+	var order binary.ByteOrder
+	switch string(data[:2]) {
+	case "II":
+		order = binary.LittleEndian
+	case "MM":
+		order = binary.BigEndian
+	default:
+		return tiff{}, false
+	}
+	if order.Uint16(data[2:4]) != 42 {
+		return tiff{}, false
+	}
+	return tiff{data: data, order: order}, true
+}
+
+// ifd is a directory of tagged values, each holding its type, count, and
+// value or the offset of its value
+type ifd map[uint16][]byte
+
+// ifd reads the directory at offset. A directory that doesn't fit is empty.
+func (t tiff) ifd(offset uint32) ifd {
+	d := make(ifd)
+	if offset == 0 || int64(offset)+2 > int64(len(t.data)) {
+		return d
+	}
+	n := int(t.order.Uint16(t.data[offset:]))
+	for i := range n {
+		start := int(offset) + 2 + i*12
+		if start+12 > len(t.data) {
+			break
+		}
+		d[t.order.Uint16(t.data[start:])] = t.data[start+2 : start+12]
+	}
+	return d
+}
+
+// value returns the bytes of a tag's value, which has the given size per
+// item, if the tag is there with that type
+func (d ifd) value(t tiff, tag uint16, typ uint16, size int) ([]byte, bool) {
+	entry, ok := d[tag]
+	if !ok || t.order.Uint16(entry) != typ {
+		return nil, false
+	}
+	length := int64(t.order.Uint32(entry[2:])) * int64(size)
+	if length <= 4 {
+		return entry[6 : 6+length], true
+	}
+	offset := int64(t.order.Uint32(entry[6:]))
+	if offset+length > int64(len(t.data)) {
+		return nil, false
+	}
+	return t.data[offset : offset+length], true
+}
+
+// string returns an ASCII value
+func (d ifd) string(t tiff, tag uint16) string {
+	v, ok := d.value(t, tag, 2, 1)
+	if !ok {
+		return ""
+	}
+	s, _, _ := strings.Cut(string(v), "\x00")
+	return strings.TrimSpace(s)
+}
+
+// uint returns a SHORT or LONG value, or 0
+func (d ifd) uint(t tiff, tag uint16) uint32 {
+	if v, ok := d.value(t, tag, 3, 2); ok && len(v) >= 2 {
+		return uint32(t.order.Uint16(v))
+	}
+	if v, ok := d.value(t, tag, 4, 4); ok && len(v) >= 4 {
+		return t.order.Uint32(v)
+	}
+	return 0
+}
+
+// rational returns the ith RATIONAL of a value
+func (d ifd) rational(t tiff, tag uint16, i int) (num, den uint32, ok bool) {
+	v, ok := d.value(t, tag, 5, 8)
+	if !ok || len(v) < (i+1)*8 {
+		return 0, 0, false
+	}
+	num, den = t.order.Uint32(v[i*8:]), t.order.Uint32(v[i*8+4:])
+	return num, den, den != 0
+}
@@ -0,0 +1,128 @@
+package preview
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+)
+
+// exifEntry is a tag written into a test IFD
+type exifEntry struct {
+	tag, typ uint16
+	count    uint32
+	data     []byte
+}
+
+// appendIFD appends a little-endian IFD holding entries, with the values
+// that don't fit in an entry after it, and returns its offset
+func appendIFD(data []byte, entries []exifEntry) ([]byte, uint32) {
+	le := binary.LittleEndian
+	offset := uint32(len(data))
+	values := offset + 2 + 12*uint32(len(entries)) + 4
+	var extra []byte
+	data = le.AppendUint16(data, uint16(len(entries)))
+	for _, e := range entries {
+		data = le.AppendUint16(data, e.tag)
+		data = le.AppendUint16(data, e.typ)
+		data = le.AppendUint32(data, e.count)
+		if len(e.data) <= 4 {
+			data = append(data, append(e.data, make([]byte, 4-len(e.data))...)...)
+			continue
+		}
+		data = le.AppendUint32(data, values+uint32(len(extra)))
+		extra = append(extra, e.data...)
+	}
+	data = le.AppendUint32(data, 0)
+	return append(data, extra...), offset
+}
+
+func ascii(tag uint16, s string) exifEntry {
+	return exifEntry{tag, 2, uint32(len(s) + 1), append([]byte(s), 0)}
+}
+
+func rationals(tag uint16, v ...uint32) exifEntry {
+	var data []byte
+	for _, n := range v {
+		data = binary.LittleEndian.AppendUint32(data, n)
+	}
+	return exifEntry{tag, 5, uint32(len(v) / 2), data}
+}
+
+func short(tag uint16, v uint16) exifEntry {
+	return exifEntry{tag, 3, 1, binary.LittleEndian.AppendUint16(nil, v)}
+}
+
+func long(tag uint16, v uint32) exifEntry {
+	return exifEntry{tag, 4, 1, binary.LittleEndian.AppendUint32(nil, v)}
+}
+
+// exifJPEG returns the start of a JPEG with EXIF data for a photo taken in
+// London
+func exifJPEG() []byte {
+	tiff := []byte{'I', 'I', 42, 0, 0, 0, 0, 0}
+	tiff, exif := appendIFD(tiff, []exifEntry{
+		rationals(tagExposureTime, 1, 250),
+		rationals(tagFNumber, 28, 10),
+		short(tagISO, 400),
+		ascii(tagDateTimeOriginal, "2024:05:01 12:00:00"),
+	})
+	tiff, gps := appendIFD(tiff, []exifEntry{
+		ascii(tagLatitudeRef, "N"),
+		rationals(tagLatitude, 51, 1, 30, 1, 26, 1),
+		ascii(tagLongitudeRef, "W"),
+		rationals(tagLongitude, 0, 1, 7, 1, 39, 1),
+	})
+	tiff, ifd0 := appendIFD(tiff, []exifEntry{
+		ascii(tagMake, "Canon"),
+		ascii(tagModel, "Canon EOS 5D"),
+		short(tagOrientation, 6),
+		long(tagExifIFD, exif),
+		long(tagGPSIFD, gps),
+	})
+	binary.LittleEndian.PutUint32(tiff[4:], ifd0)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	data := []byte{0xFF, 0xD8, 0xFF, 0xE1}
+	data = binary.BigEndian.AppendUint16(data, uint16(len(segment)+2))
+	data = append(data, segment...)
+	return append(data, 0xFF, 0xDA, 0, 2)
+}
+
+func TestReadEXIF(t *testing.T) {
+	want := []exifField{
+		{"Camera", "Canon EOS 5D"},
+		{"Taken", "2024-05-01 12:00:00"},
+		{"Exposure", "1/250 s  f/2.8  ISO 400"},
+		{"Orientation", "rotated 90° clockwise"},
+		{"Location", "51.50722° N, 0.12750° W"},
+	}
+	if got := readEXIF(exifJPEG()); !reflect.DeepEqual(got, want) {
+		t.Errorf("readEXIF() = %v, want %v", got, want)
+	}
+}
+
+func TestReadEXIFTruncated(t *testing.T) {
+	data := exifJPEG()
+	// An APP1 segment cut short is skipped rather than read past its end
+	for n := range len(data) - 4 {
+		if got := readEXIF(data[:n]); got != nil {
+			t.Errorf("readEXIF() of the first %d bytes = %v, want nil", n, got)
+		}
+	}
+}
+
+func TestReadEXIFMalformed(t *testing.T) {
+	data := exifJPEG()
+	// Corrupting any byte, such as a count or offset, must not panic
+	for i := range data {
+		for _, b := range []byte{0x00, 0x7F, 0xFF} {
+			corrupt := append([]byte(nil), data...)
+			corrupt[i] = b
+			readEXIF(corrupt)
+		}
+	}
+
+	if got := readEXIF([]byte("not a jpeg")); got != nil {
+		t.Errorf("readEXIF() of text = %v", got)
+	}
+}
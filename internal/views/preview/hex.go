@@ -0,0 +1,86 @@
+package preview
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+// hexMaxBytes is how much of the content a hex dump shows
+const hexMaxBytes = 64 << 10
+
+// hexWidth is how many bytes each line of a hex dump shows
+const hexWidth = 16
+
+// sniffBytes is how much of the content is looked at to tell binary from
+// text
+const sniffBytes = 8 << 10
+
+// isBinary reports whether data looks like binary content rather than
+// text: it has a NUL byte, or over a tenth of its start is control
+// characters or invalid UTF-8
+func isBinary(data []byte) bool {
+	data = data[:min(len(data), sniffBytes)]
+	if bytes.IndexByte(data, 0) >= 0 {
+		return true
+	}
+	var odd, total int
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 && len(data) < utf8.UTFMax {
+			break // a character cut off by the end of the sample
+		}
+		data = data[size:]
+		total++
+		switch {
+		case r == '\n', r == '\r', r == '\t', r == '\f':
+		case r == utf8.RuneError && size <= 1, unicode.IsControl(r):
+			odd++
+		}
+	}
+	return odd*10 > total
+}
+
+// hexDump lays data out like hexdump -C: the offset, sixteen bytes in hex
+// and the same bytes as ASCII, with . for the rest. A run of identical
+// lines is shown once, then *. Offsets count from base, where data starts
+// in the object.
+func hexDump(data []byte, base int64, dim lipgloss.Style) []string {
+	var lines []string
+	var prev []byte
+	repeated := false
+	for off := 0; off < len(data); off += hexWidth {
+		chunk := data[off:min(off+hexWidth, len(data))]
+		if len(chunk) == hexWidth && bytes.Equal(chunk, prev) {
+			if !repeated {
+				lines = append(lines, dim.Render("*"))
+				repeated = true
+			}
+			continue
+		}
+		prev, repeated = chunk, false
+
+		var hex, ascii strings.Builder
+		for i := range hexWidth {
+			if i == hexWidth/2 {
+				hex.WriteByte(' ')
+			}
+			if i >= len(chunk) {
+				hex.WriteString("   ")
+				continue
+			}
+			fmt.Fprintf(&hex, "%02x ", chunk[i])
+			if c := chunk[i]; c >= 0x20 && c < 0x7f {
+				ascii.WriteByte(c)
+			} else {
+				ascii.WriteByte('.')
+			}
+		}
+		lines = append(lines, fmt.Sprintf("%s  %s %s", dim.Render(fmt.Sprintf("%08x", base+int64(off))), hex.String(), dim.Render("|"+ascii.String()+"|")))
+	}
+	return lines
+}
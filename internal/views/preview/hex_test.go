@@ -0,0 +1,40 @@
+package preview
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestHexDump(t *testing.T) {
+	data := []byte("hello, world\x00\x01\x02\xff0123456789")
+	got := hexDump(data, 0x100, lipgloss.NewStyle())
+	want := []string{
+		"00000100  68 65 6c 6c 6f 2c 20 77  6f 72 6c 64 00 01 02 ff  |hello, world....|",
+		"00000110  30 31 32 33 34 35 36 37  38 39                    |0123456789|",
+	}
+	if strings.Join(got, "\n") != strings.Join(want, "\n") {
+		t.Errorf("hexDump() =\n%s\nwant\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+}
+
+func TestHexDumpRepeats(t *testing.T) {
+	data := append(bytes.Repeat([]byte{0}, 64), 'a')
+	got := hexDump(data, 0, lipgloss.NewStyle())
+	if len(got) != 3 || got[1] != "*" {
+		t.Fatalf("hexDump() = %q, want a line, * and the last line", got)
+	}
+	// The line after a run keeps its own offset
+	if !strings.HasPrefix(got[2], "00000040  61 ") {
+		t.Errorf("line after the run = %q, want offset 00000040", got[2])
+	}
+}
+
+func TestHexDumpLargeOffset(t *testing.T) {
+	got := hexDump([]byte("x"), 5<<30, lipgloss.NewStyle())
+	if len(got) != 1 || !strings.HasPrefix(got[0], "140000000  78 ") {
+		t.Errorf("hexDump() at 5GB = %q", got)
+	}
+}
@@ -0,0 +1,62 @@
+package preview
+
+import (
+	"mime"
+	"path"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/formatters"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/alecthomas/chroma/v2/styles"
+)
+
+// maxHighlightBytes is the most text that is highlighted; lexing more
+// would hold up opening the preview
+const maxHighlightBytes = 256 << 10
+
+// highlightStyle is the chroma color scheme
+const highlightStyle = "monokai"
+
+// lexerFor picks a syntax for an object from its key's extension, then its
+// Content-Type, then for a key without an extension, its content. It
+// returns nil for plain text.
+func lexerFor(key, contentType, text string) chroma.Lexer {
+	lexer := lexers.Match(path.Base(key))
+	if lexer == nil && contentType != "" {
+		if mediaType, _, err := mime.ParseMediaType(contentType); err == nil {
+			lexer = lexers.MatchMimeType(mediaType)
+		}
+	}
+	if lexer == nil && path.Ext(key) == "" {
+		lexer = lexers.Analyse(text)
+	}
+	if lexer == nil || lexer.Config().Name == "plaintext" {
+		return nil
+	}
+	return chroma.Coalesce(lexer)
+}
+
+// highlight colors text, which Text has already made safe, for the
+// terminal. Each line is formatted on its own so that every line the pager
+// cuts out carries its own colors.
+func highlight(lexer chroma.Lexer, text string) (string, bool) {
+	if len(text) > maxHighlightBytes {
+		return text, false
+	}
+	iterator, err := lexer.Tokenise(nil, text)
+	if err != nil {
+		return text, false
+	}
+	style := styles.Get(highlightStyle)
+	formatter := formatters.TTY256
+
+	var sb strings.Builder
+	sb.Grow(len(text) * 2)
+	for _, line := range chroma.SplitTokensIntoLines(iterator.Tokens()) {
+		if err := formatter.Format(&sb, style, chroma.Literator(line...)); err != nil {
+			return text, false
+		}
+	}
+	return sb.String(), true
+}
@@ -0,0 +1,326 @@
+package preview
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"image"
+	"image/color"
+	_ "image/gif" // decoders for image.Decode
+	_ "image/jpeg"
+	"image/png"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// ImageMaxBytes is the largest image a preview fetches whole to draw; of a
+// larger one only the start is fetched, for its size and EXIF data
+const ImageMaxBytes = 16 << 20
+
+// ImageMaxPixels is the largest image, by its declared width times height,
+// the preview decodes to draw
+const ImageMaxPixels = 40_000_000
+
+// Cells are assumed to be at least cellWidth by cellHeight pixels, so a
+// drawn image never overflows the preview
+const (
+	cellWidth  = 8
+	cellHeight = 16
+)
+
+// kittyChunk is how much base64 image data goes in one kitty graphics
+// escape sequence, the most the protocol allows
+const kittyChunk = 4096
+
+// Graphics is how the terminal can draw images
+type Graphics int
+
+const (
+	GraphicsNone Graphics = iota
+	GraphicsKitty
+	GraphicsSixel
+)
+
+// String names the protocol, as image_graphics does
+func (g Graphics) String() string {
+	switch g {
+	case GraphicsKitty:
+		return "kitty"
+	case GraphicsSixel:
+		return "sixel"
+	}
+	return "off"
+}
+
+// DetectGraphics returns how images can be drawn, given the image_graphics
+// setting. auto (or "") goes by the terminal stui runs in: only terminals
+// known to support a protocol get images, and none do inside tmux or
+// screen, which don't pass graphics through by default.
+func DetectGraphics(setting string) Graphics {
+	switch setting {
+	case "kitty":
+		return GraphicsKitty
+	case "sixel":
+		return GraphicsSixel
+	case "off":
+		return GraphicsNone
+	}
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	if os.Getenv("TMUX") != "" || strings.HasPrefix(term, "screen") || strings.HasPrefix(term, "tmux") {
+		return GraphicsNone
+	}
+	switch {
+	case term == "xterm-kitty", os.Getenv("KITTY_WINDOW_ID") != "",
+		term == "xterm-ghostty", program == "ghostty", program == "WezTerm":
+		return GraphicsKitty
+	case strings.Contains(term, "sixel"), term == "foot", strings.HasPrefix(term, "foot-"),
+		term == "mlterm", program == "iTerm.app":
+		return GraphicsSixel
+	}
+	return GraphicsNone
+}
+
+// ClearGraphics returns the escape sequence removing images drawn with g,
+// for when the preview closes. Sixel images need none: they're overwritten
+// like text.
+func ClearGraphics(g Graphics) string {
+	if g == GraphicsKitty {
+		return "\x1b_Ga=d,d=A,q=2\x1b\\"
+	}
+	return ""
+}
+
+// IsImage reports whether an object is an image the preview can decode, by
+// its key's extension or its Content-Type
+func IsImage(key, contentType string) bool {
+	switch strings.ToLower(path.Ext(key)) {
+	case ".png", ".jpg", ".jpeg", ".gif":
+		return true
+	}
+	switch strings.TrimSpace(strings.SplitN(contentType, ";", 2)[0]) {
+	case "image/png", "image/jpeg", "image/gif":
+		return true
+	}
+	return false
+}
+
+// imagePreview is what a preview shows of an image: its size and EXIF data,
+// and the image itself where the terminal can draw it
+type imagePreview struct {
+	format   string
+	width    int
+	height   int
+	exif     []exifField
+	img      image.Image // decoded, once it has been
+	note     string      // why it isn't drawn, if it won't be
+	graphics Graphics
+	lines    []string // laid out for the preview's size
+
+	// The image drawn to fit cols by rows cells, by a command run off the
+	// event loop
+	cols, rows         int
+	draw               string
+	drawCols, drawRows int
+}
+
+// ImageMsg carries an image decoded and scaled to fit the preview, or why
+// it couldn't be
+type ImageMsg struct {
+	Key        string
+	cols, rows int // the cells it was drawn for
+	img        image.Image
+	draw       string // the escape sequence drawing it
+	drawCols   int
+	drawRows   int
+	err        error
+}
+
+// NewImage creates a preview of an image. data is all of it, or its start
+// if the object is over ImageMaxBytes. Content that doesn't decode as an
+// image is previewed like any other. The image itself is drawn by the
+// command DrawImage returns, once the preview has a size.
+func NewImage(bucket, key, contentType string, size int64, data []byte, graphics Graphics) Model {
+	cfg, format, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return New(bucket, key, contentType, size, data)
+	}
+	m := Model{
+		bucket:     bucket,
+		key:        key,
+		size:       size,
+		data:       data,
+		viewport:   viewport.New(0, 0),
+		titleStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		dimStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+	}
+	p := &imagePreview{
+		format:   strings.ToUpper(format),
+		width:    cfg.Width,
+		height:   cfg.Height,
+		graphics: graphics,
+	}
+	if format == "jpeg" {
+		p.exif = readEXIF(data)
+	}
+	switch {
+	case graphics == GraphicsNone:
+		p.note = "not drawn: the terminal isn't known to show images (set image_graphics to kitty or sixel to try)"
+	case m.Truncated():
+		p.note = "not drawn: larger than " + humanize.Bytes(ImageMaxBytes)
+	case int64(cfg.Width)*int64(cfg.Height) > ImageMaxPixels:
+		// A small file can claim huge dimensions, and decoding allocates
+		// for all of them
+		p.note = fmt.Sprintf("not drawn: over %d megapixels", ImageMaxPixels/1_000_000)
+	case cfg.Width <= 0 || cfg.Height <= 0:
+		p.note = "not drawn: the image is empty"
+	}
+	m.image = p
+	m.summary = fmt.Sprintf("%s image", p.format)
+	return m
+}
+
+// DrawImage returns a command decoding the image and scaling it to fit the
+// preview, or nil if it isn't drawn or already fits
+func (m *Model) DrawImage() tea.Cmd {
+	p := m.image
+	if p == nil || p.note != "" || p.cols < 1 || p.rows < 1 || (p.draw != "" && p.fits()) {
+		return nil
+	}
+	key, data, img, graphics := m.key, m.data, p.img, p.graphics
+	cols, rows := p.cols, p.rows
+	w, h := p.scaledSize()
+	return func() tea.Msg {
+		msg := ImageMsg{Key: key, cols: cols, rows: rows, img: img}
+		if msg.img == nil {
+			if msg.img, _, msg.err = image.Decode(bytes.NewReader(data)); msg.err != nil {
+				return msg
+			}
+		}
+		msg.drawCols, msg.drawRows = (w+cellWidth-1)/cellWidth, (h+cellHeight-1)/cellHeight
+		scaled := scaleImage(msg.img, w, h)
+		switch graphics {
+		case GraphicsKitty:
+			msg.draw = kitty(scaled, msg.drawCols, msg.drawRows)
+		case GraphicsSixel:
+			msg.draw = sixel(scaled)
+		}
+		return msg
+	}
+}
+
+// SetImage shows an image drawn by the command DrawImage returned, unless
+// the preview has been resized since
+func (m *Model) SetImage(msg ImageMsg) {
+	p := m.image
+	if p == nil || msg.Key != m.key {
+		return
+	}
+	if msg.err != nil {
+		p.note = "not drawn: " + msg.err.Error()
+	} else {
+		p.img = msg.img // kept for redrawing at another size
+		if msg.cols != p.cols || msg.rows != p.rows {
+			return
+		}
+		p.draw, p.drawCols, p.drawRows = msg.draw, msg.drawCols, msg.drawRows
+	}
+	p.layout(m.viewport.Width, m.viewport.Height, m.dimStyle)
+}
+
+// scaledSize returns the size in pixels the image is drawn at: scaled down
+// to fit cols by rows cells, never up
+func (p *imagePreview) scaledSize() (int, int) {
+	scale := min(float64(p.cols*cellWidth)/float64(p.width), float64(p.rows*cellHeight)/float64(p.height), 1)
+	return max(int(float64(p.width)*scale), 1), max(int(float64(p.height)*scale), 1)
+}
+
+// fits reports whether the image drawn is the size it would be drawn at
+// now
+func (p *imagePreview) fits() bool {
+	w, h := p.scaledSize()
+	return p.drawCols == (w+cellWidth-1)/cellWidth && p.drawRows == (h+cellHeight-1)/cellHeight
+}
+
+// layout lays the image out in width by height cells: its size and EXIF
+// data, then the image below, scaled down to fit, once it's drawn
+func (p *imagePreview) layout(width, height int, dim lipgloss.Style) {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("%s, %d × %d pixels", p.format, p.width, p.height))}
+	for _, f := range p.exif {
+		lines = append(lines, dim.Render(fmt.Sprintf("%-12s", f.name))+Text([]byte(f.value)))
+	}
+	// A blank line, the image, and a line below it that draws it
+	p.cols, p.rows = width, height-len(lines)-2
+	switch {
+	case p.note != "":
+		lines = append(lines, "", dim.Render(p.note))
+	case p.cols < 1 || p.rows < 1:
+	case p.draw == "" || !p.fits():
+		lines = append(lines, "", dim.Render("drawing…"))
+	default:
+		lines = append(lines, "")
+		for range p.drawRows {
+			lines = append(lines, "")
+		}
+		// Drawn from below, once the lines it covers are written, and with
+		// the cursor put back so the rest of the screen isn't shifted
+		lines = append(lines, fmt.Sprintf("\x1b7\x1b[%dA%s\x1b8", p.drawRows, p.draw))
+	}
+	p.lines = lines
+}
+
+// scaleImage resizes img to w by h pixels, averaging the pixels each new
+// one covers
+func scaleImage(img image.Image, w, h int) *image.NRGBA {
+	b := img.Bounds()
+	out := image.NewNRGBA(image.Rect(0, 0, w, h))
+	for y := range h {
+		y0, y1 := b.Min.Y+y*b.Dy()/h, b.Min.Y+max((y+1)*b.Dy()/h, y*b.Dy()/h+1)
+		for x := range w {
+			x0, x1 := b.Min.X+x*b.Dx()/w, b.Min.X+max((x+1)*b.Dx()/w, x*b.Dx()/w+1)
+			var r, g, bl, a, n uint64
+			for sy := y0; sy < y1; sy++ {
+				for sx := x0; sx < x1; sx++ {
+					c := color.NRGBAModel.Convert(img.At(sx, sy)).(color.NRGBA)
+					r, g, bl, a = r+uint64(c.R), g+uint64(c.G), bl+uint64(c.B), a+uint64(c.A)
+					n++
+				}
+			}
+			out.SetNRGBA(x, y, color.NRGBA{R: uint8(r / n), G: uint8(g / n), B: uint8(bl / n), A: uint8(a / n)})
+		}
+	}
+	return out
+}
+
+// kitty returns the kitty graphics protocol sequences drawing img as PNG
+// over cols by rows cells, replacing any image drawn before
+func kitty(img image.Image, cols, rows int) string {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return ""
+	}
+	data := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	var sb strings.Builder
+	sb.WriteString(ClearGraphics(GraphicsKitty))
+	for i := 0; i < len(data); i += kittyChunk {
+		chunk := data[i:min(i+kittyChunk, len(data))]
+		more := 0
+		if i+kittyChunk < len(data) {
+			more = 1
+		}
+		if i == 0 {
+			// q=2 keeps the terminal from answering on stdin, and C=1 from
+			// moving the cursor
+			fmt.Fprintf(&sb, "\x1b_Ga=T,f=100,q=2,C=1,c=%d,r=%d,m=%d;%s\x1b\\", cols, rows, more, chunk)
+		} else {
+			fmt.Fprintf(&sb, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,166 @@
+package preview
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/alecthomas/chroma/v2"
+)
+
+// jsonIndent is the indent of each level of pretty-printed JSON
+const jsonIndent = "  "
+
+// sniffTokens is how many tokens of content without a JSON extension or
+// Content-Type must parse for it to be treated as JSON
+const sniffTokens = 20
+
+// foldLine is where a pretty-printed JSON line sits in the document
+type foldLine struct {
+	depth int    // nesting level of the line
+	end   int    // for a line opening an object or array, the line closing it; else 0
+	close string // the closing line's text, e.g. "},", shown after a fold
+}
+
+// isJSON reports whether text should be pretty-printed: it's named or
+// typed as JSON, or it starts with an object or array that parses
+func isJSON(lexer chroma.Lexer, text string) bool {
+	if lexer != nil {
+		return lexer.Config().Name == "JSON"
+	}
+	trimmed := strings.TrimLeft(text, " \n")
+	if !strings.HasPrefix(trimmed, "{") && !strings.HasPrefix(trimmed, "[") {
+		return false
+	}
+	// Only the start is checked, as a preview may end mid-document
+	dec := json.NewDecoder(strings.NewReader(trimmed))
+	for range sniffTokens {
+		if _, err := dec.Token(); err != nil {
+			return errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF)
+		}
+	}
+	return true
+}
+
+// prettyJSON indents JSON one value per line. It doesn't need valid JSON,
+// so a document cut off by the preview limit or one value per line (NDJSON)
+// still comes out readable. It also returns each line's place in the
+// document for folding.
+func prettyJSON(text string) (string, []foldLine) {
+	var (
+		lines     []string
+		folds     []foldLine
+		openers   []int // lines of the objects and arrays not yet closed
+		line      strings.Builder
+		depth     int
+		lineDepth int // depth of the line being written
+		inString  bool
+		escaped   bool
+	)
+	newline := func() {
+		lines = append(lines, line.String())
+		folds = append(folds, foldLine{depth: lineDepth})
+		line.Reset()
+	}
+	indent := func() {
+		if line.Len() == 0 {
+			line.WriteString(strings.Repeat(jsonIndent, depth))
+			lineDepth = depth
+		}
+	}
+
+	for i := 0; i < len(text); i++ {
+		c := text[i]
+		if inString {
+			line.WriteByte(c)
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case ' ', '\n':
+			// Whitespace between tokens is replaced, except the newline
+			// after a top-level value, as in NDJSON of numbers or strings
+			if c == '\n' && depth == 0 && line.Len() > 0 {
+				newline()
+			}
+		case '{', '[':
+			if depth == 0 && line.Len() > 0 {
+				newline() // the next of several top-level values
+			}
+			indent()
+			line.WriteByte(c)
+			// Keep empty objects and arrays on one line
+			if j := nextToken(text, i+1); j < len(text) && text[j] == closer(c) {
+				line.WriteByte(text[j])
+				i = j
+				continue
+			}
+			openers = append(openers, len(lines))
+			depth++
+			newline()
+		case '}', ']':
+			if line.Len() > 0 {
+				newline()
+			}
+			depth = max(depth-1, 0)
+			indent()
+			line.WriteByte(c)
+			if n := len(openers); n > 0 {
+				folds[openers[n-1]].end = len(lines)
+				openers = openers[:n-1]
+			}
+		case ',':
+			line.WriteByte(c)
+			newline()
+		case ':':
+			line.WriteString(": ")
+		default:
+			if depth == 0 && line.Len() > 0 && endsValue(line.String()) {
+				newline()
+			}
+			indent()
+			line.WriteByte(c)
+			inString = c == '"'
+		}
+	}
+	if line.Len() > 0 {
+		newline()
+	}
+	for i, f := range folds {
+		if f.end > 0 {
+			folds[i].close = strings.TrimSpace(lines[f.end])
+		}
+	}
+	return strings.Join(lines, "\n"), folds
+}
+
+// nextToken returns the index of the first non-whitespace byte from i
+func nextToken(text string, i int) int {
+	for i < len(text) && (text[i] == ' ' || text[i] == '\n') {
+		i++
+	}
+	return i
+}
+
+// closer returns the bracket that closes open
+func closer(open byte) byte {
+	if open == '{' {
+		return '}'
+	}
+	return ']'
+}
+
+// endsValue reports whether a top-level line already holds a whole value,
+// e.g. the closing bracket of the previous NDJSON record
+func endsValue(line string) bool {
+	return strings.HasSuffix(line, "}") || strings.HasSuffix(line, "]")
+}
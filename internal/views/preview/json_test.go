@@ -0,0 +1,72 @@
+package preview
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/charmbracelet/lipgloss"
+)
+
+func TestPrettyJSON(t *testing.T) {
+	tests := []struct {
+		name, in, want string
+	}{
+		{"object", `{"a":[1,2],"b":{}}`, "{\n  \"a\": [\n    1,\n    2\n  ],\n  \"b\": {}\n}"},
+		{"cut off", `{"a": [1, 2`, "{\n  \"a\": [\n    1,\n    2"},
+		{"ndjson", "{\"a\":1}\n{\"b\":2}", "{\n  \"a\": 1\n}\n{\n  \"b\": 2\n}"},
+		{"ndjson scalars", "1\n\"two\"\n3", "1\n\"two\"\n3"},
+		{"brackets in strings", `["x,\"}",{"k]":"{"}]`, "[\n  \"x,\\\"}\",\n  {\n    \"k]\": \"{\"\n  }\n]"},
+		{"empty", "", ""},
+	}
+	for _, tt := range tests {
+		if got, _ := prettyJSON(tt.in); got != tt.want {
+			t.Errorf("%s: prettyJSON(%q) = %q, want %q", tt.name, tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestPrettyJSONFolds(t *testing.T) {
+	_, folds := prettyJSON(`{"a":[1,2],"b":{}}`)
+	want := []foldLine{
+		{depth: 0, end: 6, close: "}"},
+		{depth: 1, end: 4, close: "],"},
+		{depth: 2},
+		{depth: 2},
+		{depth: 1},
+		{depth: 1},
+		{depth: 0},
+	}
+	if !reflect.DeepEqual(folds, want) {
+		t.Errorf("folds = %+v, want %+v", folds, want)
+	}
+
+	// A document cut off by the preview limit has nothing to fold
+	_, folds = prettyJSON(`{"a": [1, 2`)
+	for i, f := range folds {
+		if f.end != 0 {
+			t.Errorf("line %d of a cut-off document folds to line %d", i, f.end)
+		}
+	}
+}
+
+func TestContentFolds(t *testing.T) {
+	text, folds := prettyJSON(`{"a":{"b":[1,2]},"c":3}`)
+	m := Model{lines: strings.Split(text, "\n"), folds: folds, dimStyle: lipgloss.NewStyle()}
+
+	tests := []struct {
+		collapseAt int
+		want       string
+	}{
+		{3, text},
+		{2, "{\n  \"a\": {\n    \"b\": [ … 2 lines ]\n  },\n  \"c\": 3\n}"},
+		{1, "{\n  \"a\": { … 4 lines },\n  \"c\": 3\n}"},
+		{0, "{ … 7 lines }"},
+	}
+	for _, tt := range tests {
+		m.collapseAt = tt.collapseAt
+		if got := m.content(); got != tt.want {
+			t.Errorf("folded below level %d:\n%s\nwant:\n%s", tt.collapseAt, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,227 @@
+package preview
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/viewport"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-runewidth"
+	"github.com/parquet-go/parquet-go"
+	"github.com/parquet-go/parquet-go/format"
+)
+
+// ParquetBudget is the most of a Parquet file a preview fetches: the
+// footer, and the first pages of each column for the sample rows
+const ParquetBudget = 32 << 20
+
+// parquetSampleRows is how many rows a Parquet preview shows
+const parquetSampleRows = 20
+
+// maxCellWidth is the widest a value in the sample rows is shown
+const maxCellWidth = 24
+
+// Parquet is what a preview shows of a Parquet file
+type Parquet struct {
+	Schema    string // the schema as a Parquet message definition
+	Rows      int64
+	RowGroups int
+	CreatedBy string
+	Columns   []string   // leaf columns, as dotted paths
+	Sample    [][]string // the first rows' values, by column
+	SampleErr error      // why no rows are shown, if they aren't
+	Fetched   int64      // bytes read from the object
+	Requests  int        // range requests made
+}
+
+// IsParquet reports whether key names a Parquet file
+func IsParquet(key string) bool {
+	return strings.EqualFold(path.Ext(key), ".parquet")
+}
+
+// ReadParquet reads the schema and row count from a Parquet file's footer,
+// then the first rows. Only the parts read are fetched, so r should fetch
+// on demand, like aws.RangeReader. Failing to read the rows isn't an
+// error; it's kept in SampleErr.
+func ReadParquet(r io.ReaderAt, size int64) (*Parquet, error) {
+	f, err := parquet.OpenFile(r, size,
+		parquet.SkipPageIndex(true),
+		parquet.SkipBloomFilters(true),
+		parquet.ReadBufferSize(64<<10),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Parquet footer: %w", err)
+	}
+	p := &Parquet{
+		Schema:    f.Schema().String(),
+		Rows:      f.NumRows(),
+		RowGroups: len(f.RowGroups()),
+		CreatedBy: f.Metadata().CreatedBy,
+	}
+	var columns []parquetColumn
+	for _, path := range f.Schema().Columns() {
+		p.Columns = append(p.Columns, strings.Join(path, "."))
+		columns = append(columns, columnOf(f.Schema(), path))
+	}
+	p.Sample, p.SampleErr = sampleRows(f, columns)
+	return p, nil
+}
+
+// parquetColumn is how a leaf column's values are shown
+type parquetColumn struct {
+	repeated bool          // values are listed in brackets
+	unit     time.Duration // for timestamps, what the stored number counts
+	date     bool          // the stored number counts days
+}
+
+// columnOf returns how to show the leaf column at path, from its logical
+// type
+func columnOf(schema *parquet.Schema, path []string) parquetColumn {
+	var c parquetColumn
+	leaf, ok := schema.Lookup(path...)
+	if !ok {
+		return c
+	}
+	c.repeated = leaf.MaxRepetitionLevel > 0
+	if lt := leaf.Node.Type().LogicalType(); lt != nil {
+		switch t := lt.Value.(type) {
+		case *format.TimestampType:
+			if t.Unit.Value != nil {
+				c.unit = t.Unit.Value.Duration()
+			}
+		case *format.DateType:
+			c.date = true
+		}
+	}
+	return c
+}
+
+// format shows a value of the column
+func (c parquetColumn) format(v parquet.Value) string {
+	switch {
+	case v.IsNull():
+		return "null"
+	case c.unit > 0 && v.Kind() == parquet.Int64:
+		return time.Unix(0, 0).UTC().Add(time.Duration(v.Int64()) * c.unit).Format(time.RFC3339Nano)
+	case c.date && v.Kind() == parquet.Int32:
+		return time.Unix(0, 0).UTC().AddDate(0, 0, int(v.Int32())).Format(time.DateOnly)
+	}
+	return v.String()
+}
+
+// sampleRows reads the first rows of a file, one string per leaf column
+func sampleRows(f *parquet.File, columns []parquetColumn) ([][]string, error) {
+	var sample [][]string
+	for _, group := range f.RowGroups() {
+		rows := group.Rows()
+		buf := make([]parquet.Row, parquetSampleRows-len(sample))
+		n, err := rows.ReadRows(buf)
+		rows.Close()
+		for _, row := range buf[:n] {
+			sample = append(sample, formatRow(row, columns))
+		}
+		if err != nil && !errors.Is(err, io.EOF) {
+			return sample, err
+		}
+		if len(sample) >= parquetSampleRows {
+			break
+		}
+	}
+	return sample, nil
+}
+
+// formatRow turns a row's values into one string per leaf column
+func formatRow(row parquet.Row, columns []parquetColumn) []string {
+	values := make([][]string, len(columns))
+	for _, v := range row {
+		if c := v.Column(); c >= 0 && c < len(columns) {
+			values[c] = append(values[c], columns[c].format(v))
+		}
+	}
+	cells := make([]string, len(columns))
+	for i, vs := range values {
+		switch {
+		case columns[i].repeated:
+			// An empty list is stored as a single null
+			if len(vs) == 1 && vs[0] == "null" {
+				vs = nil
+			}
+			cells[i] = "[" + strings.Join(vs, ", ") + "]"
+		case len(vs) > 0:
+			cells[i] = vs[0]
+		}
+	}
+	return cells
+}
+
+// NewParquet creates a preview of a Parquet file's schema and first rows
+func NewParquet(bucket, key string, size int64, p *Parquet) Model {
+	m := Model{
+		bucket:     bucket,
+		key:        key,
+		size:       size,
+		viewport:   viewport.New(0, 0),
+		titleStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		dimStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+	}
+	heading := lipgloss.NewStyle().Bold(true)
+
+	rowGroups := fmt.Sprintf("%d row groups", p.RowGroups)
+	if p.RowGroups == 1 {
+		rowGroups = "1 row group"
+	}
+	lines := []string{heading.Render(fmt.Sprintf("%s rows in %s", humanize.Comma(p.Rows), rowGroups))}
+	if p.CreatedBy != "" {
+		lines = append(lines, m.dimStyle.Render("written by "+Text([]byte(p.CreatedBy))))
+	}
+	lines = append(lines, "", heading.Render("Schema"))
+	lines = append(lines, strings.Split(Text([]byte(p.Schema)), "\n")...)
+
+	lines = append(lines, "", heading.Render(fmt.Sprintf("First %d rows", len(p.Sample))))
+	if len(p.Sample) > 0 {
+		lines = append(lines, sampleTable(p.Columns, p.Sample, m.dimStyle)...)
+	}
+	if p.SampleErr != nil {
+		lines = append(lines, m.dimStyle.Render("rows not shown: "+p.SampleErr.Error()))
+	}
+
+	m.lines = lines
+	m.summary = fmt.Sprintf("Parquet • fetched %s in %d requests", humanize.Bytes(uint64(p.Fetched)), p.Requests)
+	m.viewport.SetContent(m.content())
+	return m
+}
+
+// sampleTable lays out rows of values in columns, each as wide as its
+// widest value up to maxCellWidth
+func sampleTable(columns []string, rows [][]string, dim lipgloss.Style) []string {
+	cell := func(s string) string {
+		return runewidth.Truncate(strings.ReplaceAll(Text([]byte(s)), "\n", " "), maxCellWidth, "…")
+	}
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = runewidth.StringWidth(cell(c))
+	}
+	for _, row := range rows {
+		for i, v := range row {
+			widths[i] = max(widths[i], runewidth.StringWidth(cell(v)))
+		}
+	}
+	line := func(values []string) string {
+		cells := make([]string, len(values))
+		for i, v := range values {
+			cells[i] = runewidth.FillRight(cell(v), widths[i])
+		}
+		return strings.Join(cells, " │ ")
+	}
+
+	lines := []string{dim.Render(line(columns))}
+	for _, row := range rows {
+		lines = append(lines, line(row))
+	}
+	return lines
+}
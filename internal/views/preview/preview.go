@@ -0,0 +1,343 @@
+package preview
+
+import (
+	"fmt"
+	"path"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/alecthomas/chroma/v2"
+	"github.com/alecthomas/chroma/v2/lexers"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+)
+
+// MaxBytes is how much of an object the preview fetches at once: all of a
+// smaller object, and a window of MaxBytes of a larger one, the first to
+// start with
+const MaxBytes = 1 << 20
+
+// fetchingPage is shown while the next window is fetched
+const fetchingPage = "fetching…"
+
+// tabWidth is how many columns a tab is expanded to
+const tabWidth = 4
+
+// PageMsg asks for the window of an object starting at Offset, when the
+// user pages through an object too large to fetch at once
+type PageMsg struct {
+	Bucket string
+	Key    string
+	Size   int64
+	Offset int64
+	Tail   bool // the last window, shown scrolled to the bottom
+}
+
+// Model is a scrollable pager over a window of an object's content, the
+// start unless it's paged further
+type Model struct {
+	bucket   string
+	key      string
+	size     int64 // of the whole object
+	data     []byte
+	offset   int64    // where data starts in the object
+	paging   string   // the window being fetched, or why it couldn't be
+	syntax   string   // name of the highlighted language, or "" for plain text
+	summary  string   // how the content was read, for formats decoded from parts of the object
+	lines    []string // the content as shown when nothing is folded
+	viewport viewport.Model
+	image    *imagePreview // shown instead of the lines, for images
+	hex      bool          // showing a hex dump instead of the lines
+
+	// Pretty-printed JSON can be folded: objects and arrays nested
+	// collapseAt deep or deeper show as one line
+	folds      []foldLine
+	collapseAt int
+	maxDepth   int
+
+	width  int
+	height int
+
+	titleStyle lipgloss.Style
+	dimStyle   lipgloss.Style
+}
+
+// New creates a preview of data, the first bytes of a size-byte object.
+// Code and config files are highlighted, recognized by the key's extension
+// or the object's Content-Type, and JSON is pretty-printed. Binary content
+// starts out as a hex dump.
+func New(bucket, key, contentType string, size int64, data []byte) Model {
+	m := Model{
+		bucket:     bucket,
+		key:        key,
+		size:       size,
+		data:       data,
+		hex:        isBinary(data),
+		viewport:   viewport.New(0, 0),
+		titleStyle: lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		dimStyle:   lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+	}
+	text := Text(data)
+	var lexer chroma.Lexer
+	if !m.hex {
+		lexer = lexerFor(key, contentType, text)
+	}
+	if isJSON(lexer, text) {
+		text, m.folds = prettyJSON(text)
+		for _, f := range m.folds {
+			m.maxDepth = max(m.maxDepth, f.depth)
+		}
+		m.collapseAt = m.maxDepth + 1
+		lexer = chroma.Coalesce(lexers.Get("json"))
+	}
+	m.lines = strings.Split(text, "\n")
+	if lexer != nil {
+		// Folding relies on the colored lines matching the plain ones
+		if highlighted, ok := highlight(lexer, text); ok {
+			if lines := strings.Split(highlighted, "\n"); len(lines) == len(m.lines) {
+				m.lines = lines
+				m.syntax = lexer.Config().Name
+			}
+		}
+	}
+	m.viewport.SetContent(m.content())
+	return m
+}
+
+// SetSize sets the view size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.viewport.Width = width
+	m.viewport.Height = max(height-3, 1) // title, blank line and footer
+	if m.image != nil {
+		m.image.layout(m.viewport.Width, m.viewport.Height, m.dimStyle)
+	}
+}
+
+// Key returns the previewed object's key
+func (m Model) Key() string {
+	return m.key
+}
+
+// Truncated reports whether only part of the object was fetched
+func (m Model) Truncated() bool {
+	return int64(len(m.data)) < m.size
+}
+
+// SetOffset says where in the object the previewed data starts, for a
+// window past the first
+func (m *Model) SetOffset(offset int64) {
+	m.offset = offset
+	if m.hex {
+		m.viewport.SetContent(m.content())
+	}
+}
+
+// AtTail reports whether the previewed window is the end of an object too
+// large to fetch at once
+func (m Model) AtTail() bool {
+	return m.offset > 0 && m.offset+int64(len(m.data)) >= m.size
+}
+
+// GotoBottom scrolls to the end of the content, for the tail of an object
+func (m *Model) GotoBottom() {
+	m.viewport.GotoBottom()
+}
+
+// PageFailed shows why the next window couldn't be fetched, keeping this
+// one
+func (m *Model) PageFailed(reason string) {
+	m.paging = reason
+}
+
+// page returns a command asking for the window starting at offset, unless
+// one is already being fetched. The tail is shown from its end.
+func (m *Model) page(offset int64, tail bool) tea.Cmd {
+	if m.paging == fetchingPage || !m.Truncated() || m.data == nil || offset == m.offset {
+		return nil
+	}
+	m.paging = fetchingPage
+	msg := PageMsg{Bucket: m.bucket, Key: m.key, Size: m.size, Offset: offset, Tail: tail}
+	return func() tea.Msg { return msg }
+}
+
+// Update scrolls the pager
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if m.image != nil {
+		return m, nil // laid out to fit, with nothing to scroll
+	}
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "home", "g":
+			m.viewport.GotoTop()
+			return m, nil
+		case "end", "G":
+			m.viewport.GotoBottom()
+			return m, nil
+		case "]":
+			if end := m.offset + int64(len(m.data)); end < m.size {
+				return m, m.page(end, false)
+			}
+			return m, nil
+		case "[":
+			return m, m.page(max(m.offset-MaxBytes, 0), false)
+		case "t":
+			if m.AtTail() {
+				return m, m.page(0, false)
+			}
+			return m, m.page(m.size-MaxBytes, true)
+		case "x":
+			if m.data == nil {
+				return m, nil // a Parquet summary, not the content
+			}
+			m.hex = !m.hex
+			m.viewport.SetContent(m.content())
+			m.viewport.GotoTop()
+			return m, nil
+		case "-":
+			if m.folds != nil && !m.hex && m.collapseAt > 0 {
+				m.collapseAt--
+				m.viewport.SetContent(m.content())
+			}
+			return m, nil
+		case "+", "=":
+			if m.folds != nil && !m.hex && m.collapseAt <= m.maxDepth {
+				m.collapseAt++
+				m.viewport.SetContent(m.content())
+			}
+			return m, nil
+		}
+	}
+	var cmd tea.Cmd
+	m.viewport, cmd = m.viewport.Update(msg)
+	return m, cmd
+}
+
+// View renders the pager with a title and a footer saying how much of the
+// object is shown
+func (m Model) View() string {
+	title := m.titleStyle.Render(path.Base(m.key)) + m.dimStyle.Render("  s3://"+m.bucket+"/"+m.key)
+
+	shown := humanize.Bytes(uint64(m.size))
+	switch {
+	case m.AtTail():
+		shown = fmt.Sprintf("last %s of %s", humanize.Bytes(uint64(len(m.data))), shown)
+	case m.offset > 0:
+		shown = fmt.Sprintf("%s–%s of %s", humanize.Bytes(uint64(m.offset)), humanize.Bytes(uint64(m.offset)+uint64(len(m.data))), shown)
+	case m.Truncated():
+		shown = fmt.Sprintf("first %s of %s", humanize.Bytes(uint64(len(m.data))), shown)
+	}
+	if m.syntax != "" {
+		shown += " • " + m.syntax
+	}
+	if m.summary != "" {
+		shown = humanize.Bytes(uint64(m.size)) + " • " + m.summary
+	}
+	keys := "↑↓ scroll • g/G top/bottom • esc close"
+	switch {
+	case m.hex:
+		keys = "↑↓ scroll • g/G top/bottom • x text • esc close"
+		if len(m.data) > hexMaxBytes {
+			shown += " • hex dump of the first " + humanize.Bytes(hexMaxBytes)
+		} else {
+			shown += " • hex dump"
+		}
+	case m.folds != nil:
+		keys = "↑↓ scroll • g/G top/bottom • -/+ fold • x hex • esc close"
+		if m.collapseAt <= m.maxDepth {
+			shown += fmt.Sprintf(" • folded below level %d", m.collapseAt)
+		}
+	case m.data != nil:
+		keys = "↑↓ scroll • g/G top/bottom • x hex • esc close"
+	}
+	if m.Truncated() && m.data != nil {
+		jump := "t tail"
+		if m.AtTail() {
+			jump = "t head"
+		}
+		keys = "[/] prev/next " + humanize.Bytes(MaxBytes) + " • " + jump + " • " + keys
+	}
+	if m.paging != "" {
+		shown += " • " + m.paging
+	}
+	if m.image != nil {
+		body := m.image.lines
+		for len(body) < m.viewport.Height {
+			body = append(body, "")
+		}
+		footer := m.dimStyle.Render(shown + " • esc close")
+		return lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(body, "\n"), footer)
+	}
+	footer := m.dimStyle.Render(fmt.Sprintf("%s • %d%% • %s",
+		shown, int(m.viewport.ScrollPercent()*100), keys))
+
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", m.viewport.View(), footer)
+}
+
+// content returns the lines to show, with folded objects and arrays
+// collapsed onto their opening line, or the hex dump
+func (m Model) content() string {
+	if m.hex {
+		return strings.Join(hexDump(m.data[:min(len(m.data), hexMaxBytes)], m.offset, m.dimStyle), "\n")
+	}
+	if m.folds == nil {
+		return strings.Join(m.lines, "\n")
+	}
+	var sb strings.Builder
+	for i := 0; i < len(m.lines); i++ {
+		if i > 0 {
+			sb.WriteByte('\n')
+		}
+		sb.WriteString(m.lines[i])
+		f := m.folds[i]
+		if f.end > i && f.depth >= m.collapseAt {
+			hidden := fmt.Sprintf(" … %d lines ", f.end-i-1)
+			if f.end-i-1 == 1 {
+				hidden = " … 1 line "
+			}
+			sb.WriteString(m.dimStyle.Render(hidden))
+			sb.WriteString(f.close)
+			i = f.end
+		}
+	}
+	return sb.String()
+}
+
+// Text makes data safe to show in a terminal: invalid UTF-8 and control
+// characters, which could move the cursor or change colors, become �, tabs
+// are expanded and line endings are normalized.
+func Text(data []byte) string {
+	var sb strings.Builder
+	sb.Grow(len(data))
+	col := 0
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		data = data[size:]
+		switch {
+		case r == '\n':
+			sb.WriteByte('\n')
+			col = 0
+		case r == '\r':
+			// Dropped from CRLF line endings; a lone CR starts a new line
+			if len(data) == 0 || data[0] != '\n' {
+				sb.WriteByte('\n')
+				col = 0
+			}
+		case r == '\t':
+			n := tabWidth - col%tabWidth
+			sb.WriteString(strings.Repeat(" ", n))
+			col += n
+		case r == utf8.RuneError && size <= 1, unicode.IsControl(r):
+			sb.WriteRune(utf8.RuneError)
+			col++
+		default:
+			sb.WriteRune(r)
+			col++
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,107 @@
+package preview
+
+import (
+	"bytes"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// windowAt returns a preview of the MaxBytes window at offset in a
+// size-byte object
+func windowAt(size, offset int64) Model {
+	m := New("bucket", "big.log", "text/plain", size, bytes.Repeat([]byte("log line\n"), MaxBytes/9+1)[:MaxBytes])
+	m.SetOffset(offset)
+	return m
+}
+
+// press sends a key to m and returns the page it asks for, if any
+func press(m Model, key string) (Model, *PageMsg) {
+	m, cmd := m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)})
+	if cmd == nil {
+		return m, nil
+	}
+	msg, ok := cmd().(PageMsg)
+	if !ok {
+		return m, nil
+	}
+	return m, &msg
+}
+
+func TestPaging(t *testing.T) {
+	const size = 5*MaxBytes + 10
+	tail := int64(size - MaxBytes)
+
+	tests := []struct {
+		name   string
+		offset int64
+		key    string
+		want   int64 // the offset asked for, or -1 for none
+		tail   bool
+	}{
+		{"next from the head", 0, "]", MaxBytes, false},
+		{"previous from the head", 0, "[", -1, false},
+		{"tail from the head", 0, "t", tail, true},
+		{"previous near the head", MaxBytes / 2, "[", 0, false},
+		{"previous from the middle", 3 * MaxBytes, "[", 2 * MaxBytes, false},
+		{"next from the tail", tail, "]", -1, false},
+		{"previous from the tail", tail, "[", tail - MaxBytes, false},
+		{"head from the tail", tail, "t", 0, false},
+		{"next into the tail", tail - 5, "]", tail - 5 + MaxBytes, false},
+	}
+	for _, tt := range tests {
+		_, msg := press(windowAt(size, tt.offset), tt.key)
+		switch {
+		case tt.want < 0 && msg != nil:
+			t.Errorf("%s: asked for offset %d, want nothing", tt.name, msg.Offset)
+		case tt.want >= 0 && msg == nil:
+			t.Errorf("%s: asked for nothing, want offset %d", tt.name, tt.want)
+		case msg != nil && (msg.Offset != tt.want || msg.Tail != tt.tail):
+			t.Errorf("%s: asked for offset %d (tail %v), want %d (tail %v)", tt.name, msg.Offset, msg.Tail, tt.want, tt.tail)
+		}
+	}
+}
+
+func TestPagingOnePageAtATime(t *testing.T) {
+	m, msg := press(windowAt(3*MaxBytes, 0), "]")
+	if msg == nil {
+		t.Fatal("] asked for nothing")
+	}
+	if _, again := press(m, "]"); again != nil {
+		t.Errorf("] asked for offset %d while a page was being fetched", again.Offset)
+	}
+	m.PageFailed("failed")
+	if _, retry := press(m, "]"); retry == nil {
+		t.Error("] asked for nothing after the page failed")
+	}
+}
+
+func TestPagingWholeObject(t *testing.T) {
+	m := New("bucket", "small.txt", "text/plain", 5, []byte("hello"))
+	if m.Truncated() || m.AtTail() {
+		t.Errorf("whole object: Truncated() = %v, AtTail() = %v", m.Truncated(), m.AtTail())
+	}
+	for _, key := range []string{"]", "[", "t"} {
+		if _, msg := press(m, key); msg != nil {
+			t.Errorf("%s on the whole object asked for offset %d", key, msg.Offset)
+		}
+	}
+}
+
+func TestAtTail(t *testing.T) {
+	const size = 3 * MaxBytes
+	tests := []struct {
+		offset int64
+		want   bool
+	}{
+		{0, false},
+		{MaxBytes, false},
+		{size - MaxBytes - 1, false},
+		{size - MaxBytes, true},
+	}
+	for _, tt := range tests {
+		if got := windowAt(size, tt.offset).AtTail(); got != tt.want {
+			t.Errorf("AtTail() at offset %d = %v, want %v", tt.offset, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,106 @@
+package preview
+
+import (
+	"fmt"
+	"image"
+	"image/color"
+	"strings"
+)
+
+// Sixel images are drawn in a palette of sixelReds × sixelGreens ×
+// sixelBlues colors, which every sixel terminal has room for
+const (
+	sixelReds   = 6
+	sixelGreens = 7
+	sixelBlues  = 6
+	sixelColors = sixelReds * sixelGreens * sixelBlues
+)
+
+// sixel returns the escape sequence drawing img as sixels. Pixels that are
+// mostly transparent are left undrawn.
+func sixel(img image.Image) string {
+	b := img.Bounds()
+	w, h := b.Dx(), b.Dy()
+
+	// Each pixel's palette color, or -1 if it isn't drawn
+	pixels := make([]int, w*h)
+	var used [sixelColors]bool
+	for y := range h {
+		for x := range w {
+			c := color.NRGBAModel.Convert(img.At(b.Min.X+x, b.Min.Y+y)).(color.NRGBA)
+			if c.A < 128 {
+				pixels[y*w+x] = -1
+				continue
+			}
+			i := (int(c.R)*sixelReds/256*sixelGreens+int(c.G)*sixelGreens/256)*sixelBlues + int(c.B)*sixelBlues/256
+			pixels[y*w+x] = i
+			used[i] = true
+		}
+	}
+
+	var sb strings.Builder
+	// P2=1 leaves unset pixels transparent
+	fmt.Fprintf(&sb, "\x1bP0;1;0q\"1;1;%d;%d", w, h)
+	for i, ok := range used {
+		if ok {
+			r, g, bl := i/(sixelGreens*sixelBlues), i/sixelBlues%sixelGreens, i%sixelBlues
+			fmt.Fprintf(&sb, "#%d;2;%d;%d;%d", i, r*100/(sixelReds-1), g*100/(sixelGreens-1), bl*100/(sixelBlues-1))
+		}
+	}
+
+	// Each band of six rows is drawn one color at a time, each sixel
+	// character setting the pixels of a column that have that color
+	for top := 0; top < h; top += 6 {
+		var inBand [sixelColors]bool
+		for y := top; y < min(top+6, h); y++ {
+			for _, c := range pixels[y*w : (y+1)*w] {
+				if c >= 0 {
+					inBand[c] = true
+				}
+			}
+		}
+		first := true
+		for c, ok := range inBand {
+			if !ok {
+				continue
+			}
+			if !first {
+				sb.WriteByte('$') // back to the start of the band
+			}
+			first = false
+			fmt.Fprintf(&sb, "#%d", c)
+			run, count := byte(0), 0
+			for x := range w {
+				bits := 0
+				for row := 0; row < 6 && top+row < h; row++ {
+					if pixels[(top+row)*w+x] == c {
+						bits |= 1 << row
+					}
+				}
+				ch := byte('?' + bits)
+				if ch == run {
+					count++
+					continue
+				}
+				writeSixelRun(&sb, run, count)
+				run, count = ch, 1
+			}
+			writeSixelRun(&sb, run, count)
+		}
+		sb.WriteByte('-') // the next band
+	}
+	sb.WriteString("\x1b\\")
+	return sb.String()
+}
+
+// writeSixelRun writes count repeats of a sixel character, compressed when
+// that's shorter
+func writeSixelRun(sb *strings.Builder, ch byte, count int) {
+	switch {
+	case count == 0:
+	case count > 3:
+		fmt.Fprintf(sb, "!%d%c", count, ch)
+	default:
+		sb.WriteString(strings.Repeat(string(ch), count))
+	}
+}
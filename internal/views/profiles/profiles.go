@@ -18,9 +18,22 @@ type Item struct {
 func (i Item) Title() string { return i.profile.Name }
 func (i Item) Description() string {
 	desc := fmt.Sprintf("Region: %s", i.profile.Region)
+	if p := aws.PartitionForRegion(i.profile.Region); i.profile.Region != "" && p.ID != "aws" {
+		desc += fmt.Sprintf(" (%s)", p.Name)
+	}
 	if i.profile.AccountID != "" {
 		desc += fmt.Sprintf(" | Account: %s", i.profile.AccountID)
 	}
+	switch i.profile.Kind {
+	case aws.ProfileRole:
+		if i.profile.SourceProfile != "" {
+			desc += fmt.Sprintf(" | Role via %s", i.profile.SourceProfile)
+		} else {
+			desc += " | Role"
+		}
+	case aws.ProfileProcess:
+		desc += " | credential_process"
+	}
 	return desc
 }
 func (i Item) FilterValue() string { return i.profile.Name }
@@ -131,7 +144,7 @@ func (m Model) View() string {
 			Align(lipgloss.Center, lipgloss.Center).
 			Foreground(lipgloss.Color("196"))
 
-		return style.Render("No AWS SSO profiles found in ~/.aws/config\n\nRun 'aws configure sso' to set up a profile")
+		return style.Render("No SSO, role or credential_process profiles found in ~/.aws/config\n\nRun 'aws configure sso' to set up a profile")
 	}
 
 	return m.list.View()
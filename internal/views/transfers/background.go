@@ -0,0 +1,69 @@
+package transfers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/daemon"
+	"github.com/natevick/stui/internal/download"
+)
+
+// SetBackground replaces the list of saved jobs running in background
+// processes
+func (m *Model) SetBackground(jobs []daemon.State) {
+	m.background = jobs
+}
+
+// Background returns the saved jobs running in background processes
+func (m Model) Background() []daemon.State {
+	return m.background
+}
+
+// renderBackground lists the saved jobs running, or that ran, in
+// background processes, which carry on after stui quits
+func (m Model) renderBackground() string {
+	if len(m.background) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render("Background"))
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render("keeps running after stui quits"))
+	sb.WriteString("\n")
+
+	for _, job := range m.background {
+		line := fmt.Sprintf(" %s %-12s %s", jobIcon(job.Direction, job.Sync), backgroundStatus(job), truncatePath(job.Description, m.width-60))
+		if job.TotalFiles > 0 {
+			line += fmt.Sprintf("  %d/%d files  %s / %s",
+				job.CompletedFiles, job.TotalFiles,
+				humanize.Bytes(uint64(job.Bytes)), humanize.Bytes(uint64(job.TotalBytes)))
+		}
+		if job.Running() {
+			if job.Throughput > 0 {
+				line += fmt.Sprintf("  %s/s", humanize.Bytes(uint64(job.Throughput)))
+			}
+			line += fmt.Sprintf("  pid %d", job.PID)
+		} else if job.Err != "" {
+			line += "  " + job.Err
+		}
+		sb.WriteString(statusStyle(job.Status).Render(line))
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// backgroundStatus describes a background job's state in a few characters
+func backgroundStatus(job daemon.State) string {
+	if job.Status == download.StatusInProgress && job.TotalBytes > 0 {
+		return fmt.Sprintf("%.0f%%", float64(job.Bytes)/float64(job.TotalBytes)*100)
+	}
+	return statusLabel(download.QueuedJob{Status: job.Status})
+}
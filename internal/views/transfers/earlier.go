@@ -0,0 +1,59 @@
+package transfers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/history"
+)
+
+// maxEarlier is how many transfers from earlier sessions are listed
+const maxEarlier = 5
+
+// SetEarlier sets the transfers named or noted in earlier sessions,
+// newest first
+func (m *Model) SetEarlier(jobs []history.Job) {
+	m.earlier = jobs[:min(len(jobs), maxEarlier)]
+}
+
+// renderEarlier lists the named and noted transfers from earlier sessions
+func (m Model) renderEarlier() string {
+	if len(m.earlier) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render("Earlier"))
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render("named in earlier sessions"))
+	sb.WriteString("\n")
+
+	for _, job := range m.earlier {
+		title := job.Label
+		if title == "" {
+			title = job.Name
+		}
+		line := fmt.Sprintf(" %s %-12s %s", jobIcon(job.Direction, job.Sync), statusLabel(download.QueuedJob{Status: job.Status}), truncatePath(title, m.width-60))
+		if job.TotalFiles > 0 {
+			line += fmt.Sprintf("  %d/%d files  %s", job.CompletedFiles, job.TotalFiles, humanize.Bytes(uint64(job.Bytes)))
+		}
+		line += "  " + job.FinishedAt.Local().Format("2006-01-02 15:04")
+		sb.WriteString(statusStyle(job.Status).Render(line))
+		sb.WriteString("\n")
+		if job.Note != "" {
+			sb.WriteString(lipgloss.NewStyle().
+				Foreground(lipgloss.Color("240")).
+				Render("    ✎ " + truncatePath(job.Note, m.width-10)))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,332 @@
+package transfers
+
+import (
+	"fmt"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/security"
+)
+
+// renderJob shows one job in detail, with its full file list
+func (m Model) renderJob(job download.QueuedJob) string {
+	p := job.Progress
+	var sb strings.Builder
+
+	// Title
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render(jobIcon(job.Direction, job.Sync) + " " + job.Title())
+	sb.WriteString(title)
+	sb.WriteString("\n")
+	if job.Label != "" {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Padding(0, 1).Render(job.Name))
+		sb.WriteString("\n")
+	}
+	if job.Note != "" {
+		sb.WriteString(lipgloss.NewStyle().Italic(true).Padding(0, 1).Render("✎ " + job.Note))
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+
+	// Status
+	statusStyle := lipgloss.NewStyle().Padding(0, 1)
+	progressive, noun := verbs(p)
+	switch p.Status {
+	case download.StatusPending:
+		sb.WriteString(statusStyle.Foreground(lipgloss.Color("240")).Render("⏸ Queued until another transfer finishes"))
+	case download.StatusInProgress:
+		sb.WriteString(statusStyle.Foreground(lipgloss.Color("214")).Render("⏳ " + progressive + "..."))
+	case download.StatusCompleted:
+		sb.WriteString(statusStyle.Foreground(lipgloss.Color("78")).Render("✓ " + noun + " complete"))
+	case download.StatusFailed:
+		sb.WriteString(statusStyle.Foreground(lipgloss.Color("196")).Render("✗ " + noun + " failed"))
+	case download.StatusCancelled:
+		sb.WriteString(statusStyle.Foreground(lipgloss.Color("240")).Render("⊘ " + noun + " cancelled"))
+	}
+	sb.WriteString("\n\n")
+
+	// Overall progress
+	percent := p.PercentComplete() / 100
+	sb.WriteString(lipgloss.NewStyle().Padding(0, 1).Render(m.progressBar.ViewAs(percent)))
+	sb.WriteString("\n\n")
+
+	// Stats
+	statsStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(0, 1)
+
+	stats := fmt.Sprintf("Files: %d/%d  •  %s / %s",
+		p.CompletedFiles,
+		p.TotalFiles,
+		humanize.Bytes(uint64(p.DownloadedBytes)),
+		humanize.Bytes(uint64(p.TotalBytes)),
+	)
+	if job.Direction == download.DirectionTag {
+		stats = fmt.Sprintf("Objects: %d/%d tagged  •  %d failed", p.CompletedFiles, p.TotalFiles, p.FailedFiles)
+	}
+	if p.Status == download.StatusInProgress && p.Throughput > 0 {
+		stats += fmt.Sprintf("  •  %s/s", humanize.Bytes(uint64(p.Throughput)))
+		if eta := p.ETA(); eta > 0 {
+			stats += fmt.Sprintf("  •  ETA %s", formatETA(eta))
+		}
+	}
+	sb.WriteString(statsStyle.Render(stats))
+	sb.WriteString("\n")
+
+	if p.Collisions > 0 {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Padding(0, 1).
+			Render(fmt.Sprintf("⚠ %d files had colliding local names and were saved under their full key path", p.Collisions)))
+		sb.WriteString("\n")
+	}
+
+	if p.EscapedFiles > 0 {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Padding(0, 1).
+			Render(fmt.Sprintf("⚠ %d keys contained characters not allowed in local file names and were escaped (shown as →)", p.EscapedFiles)))
+		sb.WriteString("\n")
+	}
+
+	if p.Summary != "" {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Padding(0, 1).
+			Render(p.Summary))
+		sb.WriteString("\n")
+	}
+
+	if p.MirrorFallbacks > 0 {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Padding(0, 1).
+			Render(fmt.Sprintf("⚠ %d downloads failed from a mirror and were fetched from the bucket instead", p.MirrorFallbacks)))
+		sb.WriteString("\n")
+	}
+
+	if p.StalledFiles > 0 && p.Status == download.StatusInProgress {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("214")).
+			Padding(0, 1).
+			Render(fmt.Sprintf("⚠ Stalled: %d files (no data received)", p.StalledFiles)))
+		sb.WriteString("\n")
+	}
+
+	if job.Status == download.StatusFailed && job.Err != nil {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Padding(0, 1).
+			Render(security.SanitizeError(job.Err)))
+		sb.WriteString("\n")
+	}
+
+	if p.FailedFiles > 0 {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("196")).
+			Padding(0, 1).
+			Render(fmt.Sprintf("Failed: %d files", p.FailedFiles)))
+		sb.WriteString("\n")
+	}
+
+	// Current file
+	if p.CurrentFile != "" && p.Status == download.StatusInProgress {
+		sb.WriteString("\n")
+		sb.WriteString(statsStyle.Render(fmt.Sprintf("Current: %s", truncatePath(p.CurrentFile, m.width-20))))
+	}
+
+	if m.showWorkers {
+		sb.WriteString(m.renderWorkers(p))
+	} else {
+		sb.WriteString(m.renderFiles(p))
+	}
+
+	// Help
+	sb.WriteString("\n\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(0, 1).
+		Render("↑↓ scroll files • backspace all transfers • n name • N note • esc cancel • w toggle worker diagnostics"))
+
+	return sb.String()
+}
+
+// renderWorkers shows what each worker is doing, to spot a single large
+// file occupying one worker while the rest sit idle
+func (m Model) renderWorkers(p download.Progress) string {
+	var sb strings.Builder
+	sb.WriteString("\n\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render("Workers:"))
+	sb.WriteString("\n")
+
+	if len(p.Workers) == 0 {
+		sb.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("240")).Render("  no workers running"))
+		sb.WriteString("\n")
+		return sb.String()
+	}
+
+	now := time.Now()
+	busyStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	idleStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	errStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+
+	for _, w := range p.Workers {
+		var line string
+		if w.Idle() {
+			line = idleStyle.Render(fmt.Sprintf("  #%-2d idle  (%d done)", w.ID, w.FilesDone))
+		} else {
+			line = busyStyle.Render(fmt.Sprintf("  #%-2d %s  %s/s  %s  (%d done)",
+				w.ID,
+				truncatePath(w.Key, m.width-50),
+				humanize.Bytes(uint64(w.BytesPerSec(now))),
+				humanize.Bytes(uint64(w.Downloaded)),
+				w.FilesDone,
+			))
+		}
+		if w.Source != "" {
+			line += idleStyle.Render("  via " + w.Source)
+		}
+		sb.WriteString(line)
+		sb.WriteString("\n")
+		if w.LastError != nil {
+			sb.WriteString(errStyle.Render(fmt.Sprintf("      last error: %s", truncatePath(security.SanitizeError(w.LastError), m.width-20))))
+			sb.WriteString("\n")
+		}
+	}
+	return sb.String()
+}
+
+// renderFiles lists every file in the job, sorted by key, scrolled to
+// fileOffset and cut to the space left below the summary
+func (m Model) renderFiles(p download.Progress) string {
+	if len(p.Files) == 0 {
+		return ""
+	}
+
+	keys := make([]string, 0, len(p.Files))
+	for key := range p.Files {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var sb strings.Builder
+	sb.WriteString("\n\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render(fmt.Sprintf("Files (%d):", len(keys))))
+	sb.WriteString("\n")
+
+	rows := max(m.height-18, 5)
+	offset := min(m.fileOffset, max(len(keys)-rows, 0))
+	end := min(offset+rows, len(keys))
+	for _, key := range keys[offset:end] {
+		fp := p.Files[key]
+
+		var statusIcon string
+		var style lipgloss.Style
+		switch fp.Status {
+		case download.StatusCompleted:
+			statusIcon = "✓"
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+		case download.StatusInProgress:
+			statusIcon = "⏳"
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+		case download.StatusFailed:
+			statusIcon = "✗"
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+		case download.StatusCancelled:
+			statusIcon = "⊘"
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+		default:
+			statusIcon = "○"
+			style = lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+		}
+
+		if fp.Stalled && fp.Status == download.StatusInProgress {
+			statusIcon = "⚠"
+		}
+
+		line := fmt.Sprintf("  %s %s (%s)",
+			statusIcon,
+			truncatePath(fp.Key, m.width-30),
+			humanize.Bytes(uint64(fp.Size)),
+		)
+		if fp.Status == download.StatusInProgress && fp.Parts > 1 {
+			line += fmt.Sprintf(" %.0f%% • part %d/%d",
+				float64(fp.Downloaded)/float64(fp.Size)*100, fp.PartsDone, fp.Parts)
+		}
+		if fp.Stalled && fp.Status == download.StatusInProgress {
+			line += " stalled"
+		}
+		if fp.Retries > 0 {
+			line += fmt.Sprintf(" [retry %d]", fp.Retries)
+		}
+		if fp.Escaped {
+			line += " → " + filepath.Base(fp.LocalPath)
+		}
+		if fp.Status == download.StatusFailed && fp.Error != nil {
+			line += " " + security.SanitizeError(fp.Error)
+		}
+		sb.WriteString(style.Render(line))
+		sb.WriteString("\n")
+	}
+
+	if end < len(keys) || offset > 0 {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Padding(0, 1).
+			Render(fmt.Sprintf("  %d–%d of %d", offset+1, end, len(keys))))
+	}
+	return sb.String()
+}
+
+// verbs returns the progressive and noun forms for the transfer direction
+func verbs(p download.Progress) (string, string) {
+	switch p.Direction {
+	case download.DirectionUpload:
+		return "Uploading", "Upload"
+	case download.DirectionCopy:
+		return "Copying", "Copy"
+	case download.DirectionMove:
+		return "Moving", "Move"
+	case download.DirectionTag:
+		return "Tagging", "Tagging"
+	}
+	return "Downloading", "Download"
+}
+
+// formatETA renders a duration rounded to a readable precision
+func formatETA(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return d.Round(time.Second).String()
+	case d < time.Hour:
+		return d.Round(10 * time.Second).String()
+	default:
+		return d.Round(time.Minute).String()
+	}
+}
+
+// truncatePath shortens path to maxLen bytes, keeping its end. maxLen is
+// raised to fit the ellipsis and a character on narrow terminals.
+func truncatePath(path string, maxLen int) string {
+	maxLen = max(maxLen, 4)
+	if len(path) <= maxLen {
+		return path
+	}
+	return "..." + path[len(path)-maxLen+3:]
+}
@@ -0,0 +1,339 @@
+package transfers
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/progress"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/internal/daemon"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/history"
+)
+
+// Action represents an action to take on the selected job
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionLabel
+	ActionNote
+	ActionClearBackground
+)
+
+// ActionMsg is sent when the user wants to name a job, attach a note or
+// clear finished background jobs
+type ActionMsg struct {
+	Action Action
+	Job    download.QueuedJob
+}
+
+// Model is the transfers view model. It lists every upload, download and
+// sync job, and shows one in detail when selected.
+type Model struct {
+	jobs        []download.QueuedJob
+	background  []daemon.State // saved jobs run by other stui processes
+	earlier     []history.Job  // transfers named or noted in earlier sessions
+	cursor      int            // index into jobs
+	detail      bool           // showing the selected job's files
+	fileOffset  int            // first file shown in the detail view
+	progressBar progress.Model
+	showWorkers bool
+	width       int
+	height      int
+}
+
+// New creates a new transfers view
+func New() Model {
+	p := progress.New(
+		progress.WithDefaultGradient(),
+		progress.WithWidth(40),
+	)
+
+	return Model{
+		progressBar: p,
+	}
+}
+
+// SetSize sets the view size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.progressBar.Width = width - 20
+}
+
+// SetJobs replaces the job list, keeping the cursor on the same job
+func (m *Model) SetJobs(jobs []download.QueuedJob) {
+	id, hasSelected := m.SelectedJob()
+	m.jobs = jobs
+	if hasSelected {
+		m.moveTo(id)
+	}
+	m.cursor = min(m.cursor, max(len(m.jobs)-1, 0))
+}
+
+// Follow shows the job with id in detail, e.g. one that just started
+func (m *Model) Follow(id int) {
+	m.moveTo(id)
+	m.detail = true
+}
+
+// moveTo puts the cursor on the job with id
+func (m *Model) moveTo(id int) {
+	for i, job := range m.jobs {
+		if job.ID == id {
+			if i != m.cursor {
+				m.fileOffset = 0
+			}
+			m.cursor = i
+			return
+		}
+	}
+}
+
+// SelectedJob returns the ID of the job under the cursor
+func (m Model) SelectedJob() (int, bool) {
+	if m.cursor < len(m.jobs) {
+		return m.jobs[m.cursor].ID, true
+	}
+	return 0, false
+}
+
+// HasJobs returns true once any job has been submitted, another stui is
+// running one in the background or an earlier session named one
+func (m Model) HasJobs() bool {
+	return len(m.jobs) > 0 || len(m.background) > 0 || len(m.earlier) > 0
+}
+
+// IsActive returns true if any job is running or queued
+func (m Model) IsActive() bool {
+	for _, job := range m.jobs {
+		if active(job) {
+			return true
+		}
+	}
+	return false
+}
+
+// SelectedActive returns true if the job under the cursor can be cancelled
+func (m Model) SelectedActive() bool {
+	return m.cursor < len(m.jobs) && active(m.jobs[m.cursor])
+}
+
+func active(job download.QueuedJob) bool {
+	return job.Status == download.StatusPending || job.Status == download.StatusInProgress
+}
+
+// Update handles messages
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case progress.FrameMsg:
+		progressModel, cmd := m.progressBar.Update(msg)
+		m.progressBar = progressModel.(progress.Model)
+		return m, cmd
+
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "w":
+			m.showWorkers = !m.showWorkers
+		case "up", "k":
+			if m.detail {
+				m.fileOffset = max(m.fileOffset-1, 0)
+			} else if m.cursor > 0 {
+				m.cursor--
+			}
+		case "down", "j":
+			if m.detail {
+				if m.cursor < len(m.jobs) && m.fileOffset < len(m.jobs[m.cursor].Progress.Files)-1 {
+					m.fileOffset++
+				}
+			} else if m.cursor < len(m.jobs)-1 {
+				m.cursor++
+			}
+		case "enter":
+			if m.cursor < len(m.jobs) {
+				m.detail = true
+				m.fileOffset = 0
+			}
+		case "backspace":
+			m.detail = false
+		case "n":
+			if m.cursor < len(m.jobs) {
+				return m, action(ActionMsg{Action: ActionLabel, Job: m.jobs[m.cursor]})
+			}
+		case "N":
+			if m.cursor < len(m.jobs) {
+				return m, action(ActionMsg{Action: ActionNote, Job: m.jobs[m.cursor]})
+			}
+		case "X":
+			return m, action(ActionMsg{Action: ActionClearBackground})
+		}
+	}
+	return m, nil
+}
+
+// View renders the view
+func (m Model) View() string {
+	if !m.HasJobs() {
+		return m.renderEmpty()
+	}
+	if m.detail && m.cursor < len(m.jobs) {
+		return m.renderJob(m.jobs[m.cursor])
+	}
+	return m.renderList()
+}
+
+// renderList shows one row per job, newest last
+func (m Model) renderList() string {
+	var sb strings.Builder
+
+	running, queued := 0, 0
+	for _, job := range m.jobs {
+		switch job.Status {
+		case download.StatusInProgress:
+			running++
+		case download.StatusPending:
+			queued++
+		}
+	}
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1).
+		Render("Transfers")
+	sb.WriteString(title)
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Render(fmt.Sprintf("%d running  •  %d queued  •  %d total", running, queued, len(m.jobs))))
+	sb.WriteString("\n\n")
+
+	selectedStyle := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("255")).
+		Background(lipgloss.Color("39")).
+		Bold(true)
+
+	background := m.renderBackground() + m.renderEarlier()
+	start, end := m.listWindow(strings.Count(background, "\n"))
+	for i := start; i < end; i++ {
+		job := m.jobs[i]
+		p := job.Progress
+		line := fmt.Sprintf(" %s %-12s %s", jobIcon(job.Direction, job.Sync), statusLabel(job), truncatePath(job.Title(), m.width-60))
+		if p.TotalFiles > 0 && job.Direction == download.DirectionTag {
+			line += fmt.Sprintf("  %d/%d objects", p.CompletedFiles, p.TotalFiles)
+		} else if p.TotalFiles > 0 {
+			line += fmt.Sprintf("  %d/%d files  %s / %s",
+				p.CompletedFiles, p.TotalFiles,
+				humanize.Bytes(uint64(p.DownloadedBytes)), humanize.Bytes(uint64(p.TotalBytes)))
+		}
+		if job.Status == download.StatusInProgress && p.Throughput > 0 {
+			line += fmt.Sprintf("  %s/s", humanize.Bytes(uint64(p.Throughput)))
+		}
+		if job.Note != "" {
+			line += "  ✎"
+		}
+
+		if i == m.cursor {
+			sb.WriteString(selectedStyle.Render(line))
+		} else {
+			sb.WriteString(statusStyle(job.Status).Render(line))
+		}
+		sb.WriteString("\n")
+	}
+	if start > 0 || end < len(m.jobs) {
+		sb.WriteString(lipgloss.NewStyle().
+			Foreground(lipgloss.Color("240")).
+			Padding(0, 1).
+			Render(fmt.Sprintf("  %d–%d of %d", start+1, end, len(m.jobs))))
+		sb.WriteString("\n")
+	}
+	sb.WriteString(background)
+
+	sb.WriteString("\n")
+	sb.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240")).
+		Padding(0, 1).
+		Render("↑↓ select • enter show files • n name • N note • esc cancel selected • X clear finished background jobs"))
+
+	return sb.String()
+}
+
+// listWindow returns the range of jobs that fit in the list, around the
+// cursor, given how many lines the background and earlier jobs take
+func (m Model) listWindow(backgroundLines int) (start, end int) {
+	// Title, blank line, the range shown and the help line with its gap
+	rows := max(m.height-5-backgroundLines, 3)
+	if len(m.jobs) <= rows {
+		return 0, len(m.jobs)
+	}
+	rows-- // for the range shown
+	start = min(max(m.cursor-rows/2, 0), len(m.jobs)-rows)
+	return start, start + rows
+}
+
+// jobIcon marks a job as a download, an upload, a sync, a copy, a move or
+// tagging
+func jobIcon(dir download.Direction, sync bool) string {
+	if sync {
+		return "⇅"
+	}
+	switch dir {
+	case download.DirectionUpload:
+		return "⬆"
+	case download.DirectionCopy:
+		return "⇄"
+	case download.DirectionMove:
+		return "⇢"
+	case download.DirectionTag:
+		return "⌗"
+	}
+	return "⬇"
+}
+
+// statusLabel describes a job's state in a few characters
+func statusLabel(job download.QueuedJob) string {
+	switch job.Status {
+	case download.StatusPending:
+		return "queued"
+	case download.StatusInProgress:
+		return fmt.Sprintf("%.0f%%", job.Progress.PercentComplete())
+	case download.StatusCompleted:
+		return "✓ done"
+	case download.StatusFailed:
+		return "✗ failed"
+	case download.StatusCancelled:
+		return "⊘ cancelled"
+	default:
+		return ""
+	}
+}
+
+func statusStyle(status download.Status) lipgloss.Style {
+	switch status {
+	case download.StatusInProgress:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	case download.StatusCompleted:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("78"))
+	case download.StatusFailed:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+	default:
+		return lipgloss.NewStyle().Foreground(lipgloss.Color("240"))
+	}
+}
+
+func (m Model) renderEmpty() string {
+	style := lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Foreground(lipgloss.Color("240"))
+
+	return style.Render("No transfers yet\n\nPress 'd' to download or 'u' to upload in the Browser")
+}
+
+// action returns a command reporting msg
+func action(msg ActionMsg) tea.Cmd {
+	return func() tea.Msg { return msg }
+}
@@ -0,0 +1,175 @@
+package whatsnew
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-runewidth"
+	"github.com/natevick/stui/internal/bookmarks"
+)
+
+// maxBookmarkWidth is the widest a bookmark's name is shown
+const maxBookmarkWidth = 24
+
+// Action represents an action to take
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionOpen
+	ActionPreview
+)
+
+// ActionMsg is sent when the user opens the folder of an arrival or
+// previews it
+type ActionMsg struct {
+	Action  Action
+	Arrival bookmarks.Arrival
+}
+
+// Model is a feed of the most recently modified objects under every
+// bookmark, newest first
+type Model struct {
+	arrivals []bookmarks.Arrival
+	notes    []string // bookmarks listed in part or not at all, and why
+	err      error
+	loaded   bool
+	cursor   int
+	offset   int // first arrival shown
+	width    int
+	height   int
+
+	titleStyle    lipgloss.Style
+	dimStyle      lipgloss.Style
+	selectedStyle lipgloss.Style
+}
+
+// New creates a feed, loading until SetArrivals is called
+func New() Model {
+	return Model{
+		titleStyle:    lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("39")),
+		dimStyle:      lipgloss.NewStyle().Foreground(lipgloss.Color("240")),
+		selectedStyle: lipgloss.NewStyle().Foreground(lipgloss.Color("255")).Background(lipgloss.Color("39")).Bold(true),
+	}
+}
+
+// SetSize sets the view size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.scroll()
+}
+
+// SetArrivals shows the arrivals, newest first, with notes on bookmarks
+// that couldn't be listed in full, or why none could be
+func (m *Model) SetArrivals(arrivals []bookmarks.Arrival, notes []string, err error) {
+	m.arrivals = arrivals
+	m.notes = notes
+	m.err = err
+	m.loaded = true
+	m.cursor = 0
+	m.offset = 0
+	m.scroll()
+}
+
+// Loading returns true until arrivals are set
+func (m Model) Loading() bool {
+	return !m.loaded
+}
+
+// Update handles messages
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || len(m.arrivals) == 0 {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "up", "k":
+		m.cursor = max(m.cursor-1, 0)
+	case "down", "j":
+		m.cursor = min(m.cursor+1, len(m.arrivals)-1)
+	case "pgup", "ctrl+u":
+		m.cursor = max(m.cursor-m.rows(), 0)
+	case "pgdown", "ctrl+d":
+		m.cursor = min(m.cursor+m.rows(), len(m.arrivals)-1)
+	case "home", "g":
+		m.cursor = 0
+	case "end", "G":
+		m.cursor = len(m.arrivals) - 1
+	case "enter":
+		return m, action(ActionOpen, m.arrivals[m.cursor])
+	case "v":
+		return m, action(ActionPreview, m.arrivals[m.cursor])
+	}
+	m.scroll()
+	return m, nil
+}
+
+// scroll keeps the highlighted arrival on screen
+func (m *Model) scroll() {
+	rows := m.rows()
+	if m.cursor < m.offset {
+		m.offset = m.cursor
+	}
+	if m.cursor >= m.offset+rows {
+		m.offset = m.cursor - rows + 1
+	}
+}
+
+// rows returns how many arrivals fit between the title, the notes and the
+// footer
+func (m Model) rows() int {
+	return max(m.height-4-len(m.notes), 1)
+}
+
+// View renders the view
+func (m Model) View() string {
+	title := m.titleStyle.Render("What's new") + m.dimStyle.Render("  newest objects under every bookmark")
+
+	var body []string
+	switch {
+	case m.err != nil:
+		body = []string{"Error listing bookmarks:", "", m.err.Error()}
+	case !m.loaded:
+		body = []string{m.dimStyle.Render("Listing bookmarks...")}
+	case len(m.arrivals) == 0:
+		body = []string{m.dimStyle.Render("Nothing under any bookmark")}
+	default:
+		nameWidth := 0
+		for _, a := range m.arrivals {
+			nameWidth = max(nameWidth, runewidth.StringWidth(a.Bookmark.DisplayName()))
+		}
+		nameWidth = min(nameWidth, maxBookmarkWidth)
+		end := min(m.offset+m.rows(), len(m.arrivals))
+		for i, a := range m.arrivals[m.offset:end] {
+			name := runewidth.FillRight(runewidth.Truncate(a.Bookmark.DisplayName(), nameWidth, "…"), nameWidth)
+			key := strings.TrimPrefix(a.Key, a.Bookmark.Prefix)
+			text := fmt.Sprintf("%-16s %s  %s", humanize.Time(a.LastModified), name, key)
+			size := humanize.Bytes(uint64(a.Size))
+			text = runewidth.Truncate(text, max(m.width-len(size)-5, 20), "…")
+			if m.offset+i == m.cursor {
+				body = append(body, m.selectedStyle.Render("> "+text+"  "+size))
+			} else {
+				body = append(body, "  "+text+"  "+m.dimStyle.Render(size))
+			}
+		}
+	}
+	for _, note := range m.notes {
+		body = append(body, m.dimStyle.Render(runewidth.Truncate(note, max(m.width, 20), "…")))
+	}
+
+	// Keep the footer at the bottom
+	for len(body) < m.rows()+len(m.notes) {
+		body = append(body, "")
+	}
+	footer := m.dimStyle.Render("↑↓ select • enter open folder • v preview • r refresh • esc close")
+	return lipgloss.JoinVertical(lipgloss.Left, title, "", strings.Join(body, "\n"), footer)
+}
+
+// action returns a command reporting action on an arrival
+func action(action Action, a bookmarks.Arrival) tea.Cmd {
+	return func() tea.Msg { return ActionMsg{Action: action, Arrival: a} }
+}
@@ -0,0 +1,66 @@
+package s3browser_test
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/natevick/stui/pkg/s3browser"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+// listing returns n objects under one prefix
+func listing(n int) []stui.Object {
+	objects := make([]stui.Object, n)
+	modified := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	for i := range objects {
+		objects[i] = stui.Object{
+			Key:          fmt.Sprintf("logs/2024/05/01/part-%06d.json.gz", i),
+			Size:         int64(1000 + i),
+			LastModified: modified.Add(time.Duration(i) * time.Second),
+			ETag:         "5d41402abc4b2a76b9719d911017c592",
+			StorageClass: "STANDARD",
+		}
+	}
+	return objects
+}
+
+// browser returns a browser showing n objects
+func browser(n int) s3browser.Model {
+	m := s3browser.New()
+	m.SetSize(120, 40)
+	m.SetBucket("bucket")
+	m.SetPrefix("logs/2024/05/01/")
+	m.SetObjects(listing(n))
+	return m
+}
+
+func BenchmarkSetObjects(b *testing.B) {
+	objects := listing(100_000)
+	m := s3browser.New()
+	m.SetSize(120, 40)
+	m.SetBucket("bucket")
+	m.SetPrefix("logs/2024/05/01/")
+	b.ReportAllocs()
+	for b.Loop() {
+		m.SetObjects(objects)
+	}
+}
+
+func BenchmarkToggleSelection(b *testing.B) {
+	m := browser(100_000)
+	space := tea.KeyMsg{Type: tea.KeySpace, Runes: []rune{' '}}
+	b.ReportAllocs()
+	for b.Loop() {
+		m, _ = m.Update(space)
+	}
+}
+
+func BenchmarkView(b *testing.B) {
+	m := browser(100_000)
+	b.ReportAllocs()
+	for b.Loop() {
+		_ = m.View()
+	}
+}
@@ -0,0 +1,882 @@
+package s3browser
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/charmbracelet/bubbles/paginator"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/natevick/stui/internal/views/inlineedit"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+// Item represents an S3 object in the list
+type Item struct {
+	object   stui.Object
+	name     string // key relative to the listed prefix
+	selected bool
+	pending  PendingOp
+	editView string // inline editor shown in place of the name
+
+	contentType string   // "" until looked up
+	columns     []Column // set by the delegate as the item is drawn
+}
+
+func (i Item) Title() string {
+	name := pendingTitle(i.name, i.pending)
+	if i.editView != "" {
+		name = i.editView
+	}
+	var icon string
+	if i.selected {
+		icon = "✓ "
+	} else {
+		icon = "  "
+	}
+	if i.object.IsPrefix {
+		return icon + "📁 " + name
+	}
+	return icon + fileIcon(i.contentType, i.name) + " " + name
+}
+
+func (i Item) Description() string {
+	if i.object.IsPrefix {
+		return "folder"
+	}
+	desc := i.details(i.columns)
+	if restore := i.object.RestoreLabel(); restore != "" {
+		desc += "  •  " + restore
+	}
+	return desc
+}
+
+func (i Item) FilterValue() string {
+	return i.name
+}
+
+// Action is one of stui's own commands, reported only when actions are
+// enabled with SetActionsEnabled
+type Action int
+
+const (
+	ActionNone Action = iota
+	ActionDownload
+	ActionSync
+	ActionSyncUp
+	ActionBookmark
+	ActionNewObject
+	ActionUpload
+	ActionRename
+	ActionNewFolder
+	ActionDelete
+	ActionCopy
+	ActionVerify
+	ActionMove
+	ActionPresign
+	ActionJumpToDate
+	ActionPresignUpload
+	ActionInspect
+	ActionJumpToLatest
+	ActionInventory
+	ActionStorageClass
+	ActionRestore
+	ActionVersions
+	ActionCompare
+	ActionTag
+	ActionConsole
+	ActionCopyKeys
+	ActionCopyURIs
+	ActionManifest
+	ActionRenameFolder
+	ActionPreview
+)
+
+// Model is an S3 object browser: a navigable, filterable listing of one
+// bucket with multi-select. Embed it in any Bubble Tea program.
+type Model struct {
+	ctx      context.Context // listings and lookups run under it; nil for Background
+	lister   Lister
+	actions  bool // handle stui's own keys (download, upload, rename…)
+	keys     KeyMap
+	list     list.Model
+	delegate columnDelegate
+	bucket   string
+	prefix   string
+	history  []string // prefix history for back navigation
+	objects  []stui.Object
+	loading  bool
+	err      error
+	width    int
+	height   int
+
+	// Separator keys are grouped into folders on; "" lists them all flat
+	delimiter string
+	flat      bool // list every key under the prefix, whatever the delimiter
+	loadID    int  // the latest load; listings from earlier ones are dropped
+	paging    bool // more pages of a flat listing are on the way
+
+	// Summary line under the breadcrumb
+	stats     listingStats
+	showStats bool
+
+	// Type-ahead: jump to the first name starting with what's typed
+	seeking  bool
+	seekBuf  string
+	seekMiss bool // nothing starts with seekBuf
+	seekLast time.Time
+
+	// Sections by last modified date
+	byDate    bool
+	collapsed map[dateGroup]bool
+
+	// Multi-select
+	selected map[string]bool // map of Key -> selected
+
+	// Content-Types of objects that have been on screen, for their icons
+	contentTypes map[string]string
+	typeLookups  map[string]bool // keys being looked up
+
+	// Optimistic changes awaiting S3
+	pending map[string]pendingChange
+
+	// Inline rename
+	edit inlineedit.Model
+
+	// Stacked narrowing terms, each filtering the result of the last
+	narrow      []string
+	narrowInput inlineedit.Model
+}
+
+// New creates a browser with no bucket and actions disabled
+func New() Model {
+	delegate := list.NewDefaultDelegate()
+	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.
+		Foreground(lipgloss.Color("255")).
+		Background(lipgloss.Color("39")).
+		Bold(true)
+	delegate.Styles.SelectedDesc = delegate.Styles.SelectedDesc.
+		Foreground(lipgloss.Color("252")).
+		Background(lipgloss.Color("39"))
+
+	columns := columnDelegate{DefaultDelegate: delegate, columns: DefaultColumns}
+	l := list.New([]list.Item{}, columns, 0, 0)
+	l.Title = "Objects"
+	l.SetShowStatusBar(true)
+	l.SetFilteringEnabled(true)
+	l.SetShowHelp(false)
+	l.Styles.Title = lipgloss.NewStyle().
+		Bold(true).
+		Foreground(lipgloss.Color("39")).
+		Padding(0, 1)
+
+	m := Model{
+		list:     l,
+		keys:     DefaultKeyMap(),
+		delegate: columns,
+		history:  []string{},
+		selected: make(map[string]bool),
+		pending:  make(map[string]pendingChange),
+		edit:     inlineedit.New(),
+
+		contentTypes: make(map[string]string),
+		typeLookups:  make(map[string]bool),
+
+		delimiter:   "/",
+		collapsed:   make(map[dateGroup]bool),
+		narrowInput: inlineedit.New(),
+	}
+	m.claimListKeys()
+	return m
+}
+
+// SetSize sets the view size
+func (m *Model) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.list.SetSize(width, height-2) // Reserve space for path
+	m.setPaginatorType(len(m.list.Items()))
+	m.edit.SetWidth(width - 12)
+	m.narrowInput.SetWidth(width - 12)
+}
+
+// SetBucket sets the current bucket
+func (m *Model) SetBucket(bucket string) {
+	m.bucket = bucket
+	m.prefix = ""
+	m.history = []string{}
+	m.selected = make(map[string]bool) // Clear selection
+	m.pending = make(map[string]pendingChange)
+	m.contentTypes = make(map[string]string)
+	m.typeLookups = make(map[string]bool)
+	m.edit.Cancel()
+	m.narrow = nil
+	m.narrowInput.Cancel()
+	m.flat = false
+	m.byDate = false
+	m.collapsed = make(map[dateGroup]bool)
+	m.updateTitle()
+}
+
+// SetPrefix sets the current prefix
+func (m *Model) SetPrefix(prefix string) {
+	m.prefix = prefix
+	m.updateTitle()
+}
+
+// SetObjects updates the object list
+func (m *Model) SetObjects(objects []stui.Object) {
+	m.objects = objects
+	m.recountStats()
+	m.loading = false
+	m.err = nil
+	m.selected = make(map[string]bool) // Clear selection when navigating
+	m.pending = make(map[string]pendingChange)
+	m.edit.Cancel()
+	m.narrow = nil
+	m.narrowInput.Cancel()
+	m.refreshListItems()
+}
+
+// displayName returns the object's name below the current prefix. Keys
+// listed flat keep their path below it.
+func (m *Model) displayName(obj stui.Object) string {
+	if m.listDelimiter() == "/" {
+		return obj.DisplayName()
+	}
+	if name, ok := strings.CutPrefix(obj.Key, m.prefix); ok && name != "" {
+		return name
+	}
+	return obj.DisplayName()
+}
+
+// RefreshObjects replaces the object list with a fresh listing of the same
+// location, keeping the filter, narrowing terms and date grouping, the
+// cursor on the same object, and any selections that still exist
+func (m *Model) RefreshObjects(objects []stui.Object) {
+	current, hasCurrent := m.SelectedObject()
+
+	m.objects = objects
+	m.recountStats()
+	m.loading = false
+	m.err = nil
+	selected := make(map[string]bool)
+	pending := make(map[string]pendingChange)
+	for _, obj := range objects {
+		if m.selected[obj.Key] {
+			selected[obj.Key] = true
+		}
+		if change, ok := m.pending[obj.Key]; ok {
+			pending[obj.Key] = change
+		}
+	}
+	m.selected = selected
+	m.pending = pending
+	m.refreshListItems()
+
+	if hasCurrent {
+		for i, item := range m.list.VisibleItems() {
+			if item, ok := item.(Item); ok && item.object.Key == current.Key {
+				m.list.Select(i)
+				break
+			}
+		}
+	}
+}
+
+// SetError sets an error state
+func (m *Model) SetError(err error) {
+	m.err = err
+	m.loading = false
+}
+
+// SetLoading sets the loading state
+func (m *Model) SetLoading(loading bool) {
+	m.loading = loading
+}
+
+// Bucket returns the current bucket
+func (m Model) Bucket() string {
+	return m.bucket
+}
+
+// Prefix returns the current prefix
+func (m Model) Prefix() string {
+	return m.prefix
+}
+
+// SelectedObject returns the currently selected object
+func (m Model) SelectedObject() (stui.Object, bool) {
+	if item, ok := m.list.SelectedItem().(Item); ok {
+		return item.object, true
+	}
+	return stui.Object{}, false
+}
+
+// Filtering returns true while the filter input has focus
+func (m Model) Filtering() bool {
+	return m.list.FilterState() == list.Filtering
+}
+
+// SetActionsEnabled turns on stui's own keys: d, s, S, b, n, N, u, e, m, p,
+// P, i, I, C, T, R, D, V, =, @ and L. They're reported as ActionMsg; a plain picker leaves them off.
+func (m *Model) SetActionsEnabled(enabled bool) {
+	m.actions = enabled
+	m.claimListKeys()
+}
+
+// Editing returns true while an object name, narrowing term or type-ahead
+// prefix is being typed in place
+func (m Model) Editing() bool {
+	return m.edit.Active() || m.narrowInput.Active() || m.Seeking()
+}
+
+func (m *Model) updateTitle() {
+	if m.bucket == "" {
+		m.list.Title = "Objects"
+		return
+	}
+	path := fmt.Sprintf("s3://%s/%s", m.bucket, m.prefix)
+	m.list.Title = path
+}
+
+// Update handles messages. A key that triggers one of stui's own actions
+// is reported as an ActionMsg.
+func (m Model) Update(msg tea.Msg) (Model, tea.Cmd) {
+	if loaded, ok := msg.(LoadedMsg); ok {
+		return m, m.handleLoaded(loaded)
+	}
+	if types, ok := msg.(ContentTypesMsg); ok {
+		m.handleContentTypes(types)
+		return m, nil
+	}
+
+	if m.edit.Active() {
+		return m.updateEdit(msg)
+	}
+	if m.narrowInput.Active() {
+		return m.updateNarrowInput(msg)
+	}
+
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		// Don't handle keys if filtering
+		if m.list.FilterState() == list.Filtering {
+			break
+		}
+		if m.Seeking() && m.updateSeek(msg) {
+			return m, nil
+		}
+		m.seeking = false
+
+		switch {
+		case key.Matches(msg, m.keys.Select, m.keys.Open) && m.onHeader():
+			m.toggleGroup(m.list.SelectedItem().(headerItem).group)
+			return m, nil
+
+		case key.Matches(msg, m.keys.Select):
+			// Toggle selection with spacebar
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				m.toggleSelection(item.object.Key)
+				return m, m.refreshSelectedItem()
+			}
+			return m, nil
+
+		case key.Matches(msg, m.keys.Open):
+			if item, ok := m.list.SelectedItem().(Item); ok {
+				if item.object.IsPrefix {
+					// Navigate into prefix
+					m.history = append(m.history, m.prefix)
+					m.prefix = item.object.Key
+					m.updateTitle()
+					return m, m.moved()
+				}
+				chosen := SelectMsg{Bucket: m.bucket, Objects: m.GetSelectedObjects()}
+				if len(chosen.Objects) == 0 {
+					chosen.Objects = []stui.Object{item.object}
+				}
+				return m, func() tea.Msg { return chosen }
+			}
+
+		case key.Matches(msg, m.keys.Back):
+			if len(m.history) > 0 {
+				m.prefix = m.history[len(m.history)-1]
+				m.history = m.history[:len(m.history)-1]
+				m.updateTitle()
+				return m, m.moved()
+			} else if m.prefix != "" {
+				// Go back to bucket root
+				m.prefix = ""
+				m.updateTitle()
+				return m, m.moved()
+			}
+
+		case key.Matches(msg, m.keys.Narrow):
+			// Narrow the listing by another term
+			return m, m.narrowInput.Start("", "")
+
+		case key.Matches(msg, m.keys.PopNarrow):
+			m.PopNarrowing()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Flat):
+			// Toggle between folders and every key under the prefix
+			return m, m.SetFlat(!m.flat)
+
+		case key.Matches(msg, m.keys.GroupByDate):
+			m.SetGroupByDate(!m.byDate)
+			return m, nil
+
+		case key.Matches(msg, m.keys.Seek):
+			// Type-ahead: the next characters jump to a matching name
+			m.startSeek()
+			return m, nil
+
+		case key.Matches(msg, m.keys.Stats):
+			m.SetShowStats(!m.showStats)
+			return m, nil
+
+		case m.actions:
+			if cmd, handled := m.updateAction(msg); handled {
+				return m, cmd
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	m.list, cmd = m.list.Update(msg)
+	// Scrolling may have brought objects without a known type into view
+	return m, tea.Batch(cmd, m.lookupContentTypes())
+}
+
+// updateAction handles stui's own keys, reporting whether msg was one. The
+// command it returns reports the action as an ActionMsg.
+func (m *Model) updateAction(msg tea.KeyMsg) (tea.Cmd, bool) {
+	switch {
+	case key.Matches(msg, m.keys.Download):
+		// Download selected items, or current item if none selected
+		return m.selectionAction(ActionDownload), true
+
+	case key.Matches(msg, m.keys.Sync):
+		return m.report(ActionSync, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.SyncUp):
+		return m.report(ActionSyncUp, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.Bookmark):
+		return m.report(ActionBookmark, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.NewObject):
+		return m.report(ActionNewObject, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.NewFolder):
+		return m.report(ActionNewFolder, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.Upload):
+		return m.report(ActionUpload, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.Copy):
+		// Copy selected objects, or the object under the cursor, within S3
+		return m.selectionAction(ActionCopy), true
+
+	case key.Matches(msg, m.keys.StorageClass):
+		// Move selected objects, or the one under the cursor, to another
+		// storage class
+		return m.selectionAction(ActionStorageClass), true
+
+	case key.Matches(msg, m.keys.Tag):
+		// Tag selected objects, or the one under the cursor
+		return m.selectionAction(ActionTag), true
+
+	case key.Matches(msg, m.keys.Restore):
+		// Restore selected archived objects, or the one under the cursor
+		return m.selectionAction(ActionRestore), true
+
+	case key.Matches(msg, m.keys.Delete):
+		// Delete the object under the cursor, once the host confirms
+		if item, ok := m.list.SelectedItem().(Item); ok {
+			if _, pending := m.pending[item.object.Key]; pending {
+				return nil, true
+			}
+			return m.report(ActionDelete, item.object, nil), true
+		}
+		return nil, true
+
+	case key.Matches(msg, m.keys.Move):
+		// Move the object under the cursor to any key in the bucket
+		if item, ok := m.list.SelectedItem().(Item); ok && !item.object.IsPrefix {
+			if _, pending := m.pending[item.object.Key]; pending {
+				return nil, true
+			}
+			return m.report(ActionMove, item.object, nil), true
+		}
+		return nil, true
+
+	case key.Matches(msg, m.keys.Presign):
+		// Share the object under the cursor with a presigned URL
+		return m.objectAction(ActionPresign), true
+
+	case key.Matches(msg, m.keys.Inspect):
+		// Show everything S3 has on the object under the cursor
+		return m.objectAction(ActionInspect), true
+
+	case key.Matches(msg, m.keys.Versions):
+		// List the versions and delete markers under the prefix, starting
+		// at the object under the cursor
+		var obj stui.Object
+		if item, ok := m.list.SelectedItem().(Item); ok && !item.object.IsPrefix {
+			obj = item.object
+		}
+		return m.report(ActionVersions, obj, nil), true
+
+	case key.Matches(msg, m.keys.PresignUpload):
+		// Share a link to upload a new key under the current prefix
+		return m.report(ActionPresignUpload, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.JumpToDate):
+		// Jump to a date partition below the current prefix
+		return m.report(ActionJumpToDate, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.JumpToLatest):
+		// Open the newest date or numbered partition
+		return m.report(ActionJumpToLatest, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.Console):
+		// Open the object or folder under the cursor, or else the current
+		// folder, in the AWS console
+		var obj stui.Object
+		if item, ok := m.list.SelectedItem().(Item); ok {
+			obj = item.object
+		}
+		return m.report(ActionConsole, obj, nil), true
+
+	case key.Matches(msg, m.keys.CopyKeys), key.Matches(msg, m.keys.CopyURIs):
+		// Put the keys, or s3:// URIs, of selected objects or the one under
+		// the cursor on the clipboard
+		if key.Matches(msg, m.keys.CopyURIs) {
+			return m.selectionAction(ActionCopyURIs), true
+		}
+		return m.selectionAction(ActionCopyKeys), true
+
+	case key.Matches(msg, m.keys.Preview):
+		// Show the content of the object under the cursor
+		return m.objectAction(ActionPreview), true
+
+	case key.Matches(msg, m.keys.Manifest):
+		// Download the keys a local manifest file lists from this bucket
+		return m.report(ActionManifest, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.Inventory):
+		// Snapshot the prefix's listing or diff it against a snapshot
+		return m.report(ActionInventory, stui.Object{}, nil), true
+
+	case key.Matches(msg, m.keys.Verify):
+		// Compare the object under the cursor with a local file
+		return m.objectAction(ActionVerify), true
+
+	case key.Matches(msg, m.keys.Compare):
+		// Compare two selected objects, or mark the object under the cursor
+		// to compare with another, even in another bucket
+		var selected []stui.Object
+		for _, obj := range m.GetSelectedObjects() {
+			if !obj.IsPrefix {
+				selected = append(selected, obj)
+			}
+		}
+		if len(selected) == 2 {
+			return m.report(ActionCompare, stui.Object{}, selected), true
+		}
+		return m.objectAction(ActionCompare), true
+
+	case key.Matches(msg, m.keys.Rename):
+		// Rename the object under the cursor in place, or move the folder
+		// under the cursor and everything in it
+		if item, ok := m.list.SelectedItem().(Item); ok {
+			if _, pending := m.pending[item.object.Key]; pending {
+				return nil, true
+			}
+			if item.object.IsPrefix {
+				return m.report(ActionRenameFolder, item.object, nil), true
+			}
+			cmd := m.edit.Start(item.object.Key, item.object.DisplayName())
+			m.refreshListItems()
+			return cmd, true
+		}
+	}
+	return nil, false
+}
+
+// report reports action on obj, or on objs when several are chosen, as an
+// ActionMsg from the current location
+func (m *Model) report(action Action, obj stui.Object, objs []stui.Object) tea.Cmd {
+	msg := ActionMsg{Action: action, Bucket: m.bucket, Prefix: m.prefix, Object: obj, Objects: objs}
+	return func() tea.Msg { return msg }
+}
+
+// selectionAction reports action on the selected objects, or on the one
+// under the cursor when none are selected
+func (m *Model) selectionAction(action Action) tea.Cmd {
+	if selected := m.GetSelectedObjects(); len(selected) > 0 {
+		return m.report(action, stui.Object{}, selected)
+	}
+	if item, ok := m.list.SelectedItem().(Item); ok {
+		return m.report(action, item.object, nil)
+	}
+	return nil
+}
+
+// objectAction reports action on the object under the cursor, if it isn't
+// a folder
+func (m *Model) objectAction(action Action) tea.Cmd {
+	if item, ok := m.list.SelectedItem().(Item); ok && !item.object.IsPrefix {
+		return m.report(action, item.object, nil)
+	}
+	return nil
+}
+
+// updateEdit routes messages to the inline editor and reports a rename
+// once it's submitted
+func (m Model) updateEdit(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.edit, cmd = m.edit.Update(msg)
+
+	result, key, name := m.edit.ConsumeResult()
+	name = strings.TrimSpace(name)
+	if result == inlineedit.ResultSubmit && name != "" {
+		for _, obj := range m.objects {
+			if obj.Key == key && obj.DisplayName() != name {
+				renamed := ActionMsg{Action: ActionRename, Bucket: m.bucket, Prefix: m.prefix, Object: obj, RenameTo: name}
+				cmd = tea.Batch(cmd, func() tea.Msg { return renamed })
+			}
+		}
+	}
+	m.refreshListItems()
+	return m, cmd
+}
+
+// toggleSelection toggles the selection state of an object
+func (m *Model) toggleSelection(key string) {
+	if m.selected[key] {
+		delete(m.selected, key)
+	} else {
+		m.selected[key] = true
+	}
+}
+
+// refreshSelectedItem redraws just the row under the cursor, which is all
+// a selection toggle changes; rebuilding every row is too slow for a large
+// listing. The list returns a command to refilter when a filter is applied.
+func (m *Model) refreshSelectedItem() tea.Cmd {
+	item, ok := m.list.SelectedItem().(Item)
+	if !ok {
+		return nil
+	}
+	return m.list.SetItem(m.list.GlobalIndex(), m.item(item.object))
+}
+
+// refreshListItems updates the list items with current selection state
+func (m *Model) refreshListItems() {
+	idx := m.list.Index()
+	objects := m.visibleObjects()
+	var items []list.Item
+	if m.byDate {
+		items = m.groupedItems(objects)
+	} else {
+		items = make([]list.Item, len(objects))
+		for i, obj := range objects {
+			items[i] = m.item(obj)
+		}
+	}
+	m.setPaginatorType(len(items))
+	if refilter := m.list.SetItems(items); refilter != nil {
+		// The list shows every item until the filter is rerun, so rerun it
+		// now rather than flash the unfiltered listing
+		m.list, _ = m.list.Update(refilter())
+	}
+	m.list.Select(idx) // Preserve cursor position
+}
+
+// setPaginatorType shows "page x/y" instead of a dot per page when the dots
+// wouldn't fit. The list makes the same switch itself, but only after
+// rendering every dot on each SetItems and View, which is slow for a large
+// listing.
+func (m *Model) setPaginatorType(items int) {
+	pages := items
+	if perPage := m.list.Paginator.PerPage; perPage > 0 {
+		pages = (items + perPage - 1) / perPage
+	}
+	if pages > m.width {
+		m.list.Paginator.Type = paginator.Arabic
+	} else {
+		m.list.Paginator.Type = paginator.Dots
+	}
+}
+
+// item returns the list row for obj
+func (m *Model) item(obj stui.Object) Item {
+	item := Item{object: obj, name: m.displayName(obj), selected: m.selected[obj.Key], pending: m.pending[obj.Key].op, contentType: m.contentTypes[obj.Key]}
+	if m.edit.Active() && m.edit.Target() == obj.Key {
+		item.editView = m.edit.View()
+	}
+	return item
+}
+
+// onHeader returns true when the cursor is on a date section heading
+func (m Model) onHeader() bool {
+	_, ok := m.list.SelectedItem().(headerItem)
+	return ok
+}
+
+// GetSelectedObjects returns all selected objects
+func (m Model) GetSelectedObjects() []stui.Object {
+	var objs []stui.Object
+	for _, obj := range m.objects {
+		if m.selected[obj.Key] {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// SelectionCount returns the number of selected items
+func (m Model) SelectionCount() int {
+	return len(m.selected)
+}
+
+// ClearSelection clears all selections
+func (m *Model) ClearSelection() {
+	m.selected = make(map[string]bool)
+	m.refreshListItems()
+}
+
+// View renders the view
+func (m Model) View() string {
+	if m.bucket == "" {
+		return m.renderNoBucket()
+	}
+
+	if m.loading {
+		return m.renderLoading()
+	}
+
+	if m.err != nil {
+		return m.renderError()
+	}
+
+	var sb strings.Builder
+
+	// Path breadcrumb
+	path := m.renderPath()
+	sb.WriteString(path)
+	sb.WriteString("\n")
+	if m.narrowInput.Active() {
+		sb.WriteString(narrowInputStyle.Render("⌕ ") + m.narrowInput.View())
+	} else if m.Seeking() {
+		sb.WriteString(m.renderSeek())
+	} else if m.showStats {
+		sb.WriteString(m.renderStats())
+	}
+	sb.WriteString("\n")
+
+	// List
+	sb.WriteString(m.list.View())
+
+	return sb.String()
+}
+
+func (m Model) renderPath() string {
+	style := lipgloss.NewStyle().
+		Foreground(lipgloss.Color("240"))
+
+	var path string
+	switch {
+	case m.prefix == "":
+		path = fmt.Sprintf("📦 %s", m.bucket)
+	case m.delimiter == "":
+		// Nothing to split on; the prefix is one step
+		path = fmt.Sprintf("📦 %s / %s", m.bucket, m.prefix)
+	default:
+		// Build breadcrumb
+		parts := strings.Split(strings.TrimSuffix(m.prefix, m.delimiter), m.delimiter)
+		var breadcrumbs []string
+		breadcrumbs = append(breadcrumbs, "📦 "+m.bucket)
+		for _, part := range parts {
+			if part != "" {
+				breadcrumbs = append(breadcrumbs, part)
+			}
+		}
+		path = strings.Join(breadcrumbs, " / ")
+	}
+	switch m.listDelimiter() {
+	case "/":
+	case "":
+		path += "  (flat)"
+	default:
+		path += fmt.Sprintf("  (split on %q)", m.delimiter)
+	}
+	if m.byDate {
+		path += "  (by date)"
+	}
+	if m.paging {
+		path += fmt.Sprintf("  loading… %d so far", len(m.objects))
+	}
+
+	path += m.renderNarrowing()
+
+	// Show selection count
+	if count := len(m.selected); count > 0 {
+		selStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("213")).Bold(true)
+		path += selStyle.Render(fmt.Sprintf("  [%d selected]", count))
+	}
+
+	return style.Render(path)
+}
+
+func (m Model) renderNoBucket() string {
+	style := lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Foreground(lipgloss.Color("240"))
+
+	return style.Render("Select a bucket from the Buckets view (press 1)")
+}
+
+func (m Model) renderLoading() string {
+	style := lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Align(lipgloss.Center, lipgloss.Center)
+
+	return style.Render("Loading objects...")
+}
+
+func (m Model) renderError() string {
+	style := lipgloss.NewStyle().
+		Width(m.width).
+		Height(m.height).
+		Align(lipgloss.Center, lipgloss.Center).
+		Foreground(lipgloss.Color("196"))
+
+	return style.Render(fmt.Sprintf("Error: %v", m.err))
+}
+
+// DefaultDownloadPath returns a sensible default download path
+func (m Model) DefaultDownloadPath(obj stui.Object) string {
+	if obj.IsPrefix {
+		// For prefix, use the folder name
+		name := strings.TrimSuffix(obj.Key, "/")
+		parts := strings.Split(name, "/")
+		if len(parts) > 0 {
+			return "./" + parts[len(parts)-1]
+		}
+		return "./download"
+	}
+	// For file, use the filename
+	return "./" + filepath.Base(obj.Key)
+}
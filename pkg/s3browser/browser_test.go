@@ -0,0 +1,163 @@
+package s3browser_test
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/natevick/stui/pkg/s3browser"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+// contextLister records the context each listing runs under
+type contextLister struct {
+	ctxs []context.Context
+}
+
+func (l *contextLister) ListObjects(ctx context.Context, bucket, prefix string) ([]stui.Object, error) {
+	l.ctxs = append(l.ctxs, ctx)
+	return listing(3), ctx.Err()
+}
+
+func TestBrowserListsUnderHostContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	lister := &contextLister{}
+	m := s3browser.New()
+	m.SetContext(ctx)
+	m.SetClient(lister)
+
+	cancel()
+	msg, ok := m.SetLocation("bucket", "logs/2024/05/01/")().(s3browser.LoadedMsg)
+	if !ok {
+		t.Fatal("SetLocation didn't load a listing")
+	}
+	if len(lister.ctxs) != 1 || lister.ctxs[0] != ctx {
+		t.Fatalf("listed under %v, want the host's context", lister.ctxs)
+	}
+	if msg.Err != context.Canceled {
+		t.Errorf("listing after the host cancelled: error = %v", msg.Err)
+	}
+}
+
+// typeLister is a ContentTyper that records the context of each lookup
+type typeLister struct {
+	contextLister
+	mu      sync.Mutex
+	lookups []context.Context
+}
+
+func (l *typeLister) ContentType(ctx context.Context, bucket, key string) (string, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.lookups = append(l.lookups, ctx)
+	return "application/json", nil
+}
+
+// run runs cmd and any commands it batches, returning their messages
+func run(cmd tea.Cmd) []tea.Msg {
+	if cmd == nil {
+		return nil
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		var msgs []tea.Msg
+		for _, cmd := range batch {
+			msgs = append(msgs, run(cmd)...)
+		}
+		return msgs
+	}
+	return []tea.Msg{msg}
+}
+
+func TestBrowserLooksUpTypesUnderHostContext(t *testing.T) {
+	type key struct{}
+	ctx := context.WithValue(context.Background(), key{}, "host")
+	lister := &typeLister{}
+	m := s3browser.New()
+	m.SetSize(120, 40)
+	m.SetContext(ctx)
+	m.SetClient(lister)
+
+	for _, msg := range run(m.SetLocation("bucket", "logs/2024/05/01/")) {
+		var cmd tea.Cmd
+		m, cmd = m.Update(msg)
+		run(cmd)
+	}
+	if len(lister.lookups) == 0 {
+		t.Fatal("no Content-Types were looked up")
+	}
+	for _, got := range lister.lookups {
+		if got != ctx {
+			t.Fatalf("looked up a Content-Type under %v, want the host's context", got)
+		}
+	}
+}
+
+// press sends keys to m one at a time, returning the ActionMsg the last
+// one produced, if any. The commands of the keys before it are dropped, as
+// they're only cursor blinks.
+func press(m s3browser.Model, keys ...tea.KeyMsg) (s3browser.Model, *s3browser.ActionMsg) {
+	var cmd tea.Cmd
+	for _, k := range keys {
+		m, cmd = m.Update(k)
+	}
+	for _, msg := range run(cmd) {
+		if action, ok := msg.(s3browser.ActionMsg); ok {
+			return m, &action
+		}
+	}
+	return m, nil
+}
+
+func runes(s string) tea.KeyMsg {
+	return tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(s)}
+}
+
+func TestBrowserActions(t *testing.T) {
+	objects := listing(3)
+	m := browser(3)
+	m.SetActionsEnabled(true)
+
+	m, action := press(m, runes("d"))
+	if action == nil || action.Action != s3browser.ActionDownload || action.Object.Key != objects[0].Key || len(action.Objects) != 0 {
+		t.Fatalf("d on the first object = %+v", action)
+	}
+	if action.Bucket != "bucket" || action.Prefix != "logs/2024/05/01/" {
+		t.Errorf("d reported location s3://%s/%s", action.Bucket, action.Prefix)
+	}
+
+	// Marking objects downloads them instead of the one under the cursor
+	m, action = press(m, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, tea.KeyMsg{Type: tea.KeyDown}, tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}, runes("d"))
+	if action == nil || action.Action != s3browser.ActionDownload {
+		t.Fatalf("d with marked objects = %+v", action)
+	}
+	if len(action.Objects) != 2 || action.Objects[0].Key != objects[0].Key || action.Objects[1].Key != objects[1].Key {
+		t.Errorf("d with the first two objects marked reported %+v", action.Objects)
+	}
+
+	// Renaming in place reports the object under the cursor and its new
+	// name, trimmed
+	m, _ = m.Update(tea.KeyMsg{Type: tea.KeyDown})
+	m, _ = m.Update(runes("e"))
+	if !m.Editing() {
+		t.Fatal("e didn't start renaming the object under the cursor")
+	}
+	var edit []tea.KeyMsg
+	for range len(objects[2].DisplayName()) {
+		edit = append(edit, tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	edit = append(edit, runes(" part-2.json.gz "), tea.KeyMsg{Type: tea.KeyEnter})
+	m, action = press(m, edit...)
+	if action == nil || action.Action != s3browser.ActionRename || action.Object.Key != objects[2].Key || action.RenameTo != "part-2.json.gz" {
+		t.Errorf("renaming the third object = %+v", action)
+	}
+	if m.Editing() {
+		t.Error("still editing after the rename was submitted")
+	}
+
+	// Keys don't report stui's actions until they're turned on
+	if _, action := press(browser(3), runes("d")); action != nil {
+		t.Errorf("d without actions enabled = %+v", action)
+	}
+}
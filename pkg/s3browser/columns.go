@@ -0,0 +1,92 @@
+package s3browser
+
+import (
+	"io"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/dustin/go-humanize"
+	"github.com/mattn/go-runewidth"
+)
+
+// Fields a column can show
+const (
+	ColumnSize     = "size"
+	ColumnModified = "modified"
+	ColumnClass    = "class" // storage class
+	ColumnETag     = "etag"
+	ColumnOwner    = "owner"
+	ColumnType     = "type" // Content-Type, once looked up
+)
+
+// Column is one field of the details line under each object's name
+type Column struct {
+	Field string
+	Width int // pads or truncates the value to this many cells; 0 fits it
+}
+
+// DefaultColumns are the columns shown until SetColumns is called
+var DefaultColumns = []Column{{Field: ColumnSize}, {Field: ColumnModified}, {Field: ColumnOwner}, {Field: ColumnType}}
+
+// SetColumns sets the fields of the details line and their order. Empty
+// values are left out unless the column has a width. nil restores
+// DefaultColumns.
+func (m *Model) SetColumns(columns []Column) {
+	if columns == nil {
+		columns = DefaultColumns
+	}
+	m.delegate.columns = columns
+	m.list.SetDelegate(m.delegate)
+}
+
+// columnDelegate renders items with the browser's columns, so changing
+// them doesn't rebuild the list
+type columnDelegate struct {
+	list.DefaultDelegate
+	columns []Column
+}
+
+func (d columnDelegate) Render(w io.Writer, m list.Model, index int, item list.Item) {
+	if it, ok := item.(Item); ok {
+		it.columns = d.columns
+		item = it
+	}
+	d.DefaultDelegate.Render(w, m, index, item)
+}
+
+// details renders an object's details line from columns
+func (i Item) details(columns []Column) string {
+	if columns == nil {
+		columns = DefaultColumns
+	}
+	var values []string
+	for _, col := range columns {
+		value := i.field(col.Field)
+		if col.Width > 0 {
+			value = runewidth.FillRight(runewidth.Truncate(value, col.Width, "…"), col.Width)
+		} else if value == "" {
+			continue
+		}
+		values = append(values, value)
+	}
+	return strings.Join(values, "  •  ")
+}
+
+// field returns the value of one column for the item's object
+func (i Item) field(name string) string {
+	switch name {
+	case ColumnSize:
+		return humanize.Bytes(uint64(i.object.Size))
+	case ColumnModified:
+		return i.object.LastModified.Format("2006-01-02 15:04")
+	case ColumnClass:
+		return i.object.StorageClass
+	case ColumnETag:
+		return i.object.ETag
+	case ColumnOwner:
+		return i.object.OwnerLabel()
+	case ColumnType:
+		return i.contentType
+	}
+	return ""
+}
@@ -0,0 +1,28 @@
+// Package s3browser is the S3 object browser stui uses, packaged as a Bubble
+// Tea component so other programs can embed an S3 picker.
+//
+// Give it a client and a location, forward messages to Update, and render
+// View:
+//
+//	b := s3browser.New()
+//	b.SetClient(client) // a *stui.Client, or any Lister
+//	cmd := b.SetLocation("my-bucket", "reports/")
+//
+// SetContext runs its requests under the host's context, so they stop when
+// the host cancels it.
+//
+// The user opens folders with enter, goes back with backspace, marks
+// objects with space, filters with / and narrows with f. t switches to a
+// flat listing of every key under the prefix, and a splits the listing into
+// sections by last modified date. The browser reports what happened as
+// messages:
+//
+//   - LocationMsg after moving to another prefix
+//   - SelectMsg when enter is pressed on an object
+//   - ActionMsg for stui's own keys, once SetActionsEnabled turns them on
+//
+// Listings arrive as LoadedMsg, which must reach Update even while the
+// browser isn't focused. So must ContentTypesMsg, which brings the
+// Content-Types behind each object's icon when the client is a
+// ContentTyper.
+package s3browser
@@ -0,0 +1,57 @@
+package s3browser_test
+
+import (
+	"context"
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/natevick/stui/pkg/s3browser"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+// picker lets the user choose one object and prints its key
+type picker struct {
+	browser s3browser.Model
+	load    tea.Cmd
+	chosen  string
+}
+
+func (p picker) Init() tea.Cmd {
+	return p.load
+}
+
+func (p picker) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		p.browser.SetSize(msg.Width, msg.Height)
+	case s3browser.SelectMsg:
+		p.chosen = msg.Objects[0].Key
+		return p, tea.Quit
+	}
+	var cmd tea.Cmd
+	p.browser, cmd = p.browser.Update(msg)
+	return p, cmd
+}
+
+func (p picker) View() string {
+	return p.browser.View()
+}
+
+func Example() {
+	client, err := stui.NewClient(context.Background(), stui.ClientOptions{Profile: "prod"})
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+
+	p := picker{browser: s3browser.New()}
+	p.browser.SetClient(client)
+	p.load = p.browser.SetLocation("my-bucket", "")
+
+	final, err := tea.NewProgram(p, tea.WithAltScreen()).Run()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	fmt.Println("picked", final.(picker).chosen)
+}
@@ -0,0 +1,176 @@
+package s3browser
+
+import (
+	"path"
+	"strings"
+	"sync"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// maxTypeLookups bounds how many objects one lookup asks for the
+// Content-Type of, and typeLookupWorkers how many of them at once
+const (
+	maxTypeLookups    = 25
+	typeLookupWorkers = 6
+)
+
+// ContentTypesMsg delivers the Content-Types of objects shown in the
+// browser. Hosts must route it to Update even while the browser isn't
+// focused.
+type ContentTypesMsg struct {
+	Bucket string
+	Types  map[string]string // key → Content-Type; "" when the lookup failed
+}
+
+// fileIcon returns the icon for an object, by its Content-Type when that
+// says what it is and otherwise by its name's extension
+func fileIcon(contentType, name string) string {
+	kind := kindByType(contentType)
+	if kind == "" {
+		kind = kindByExt(strings.ToLower(path.Ext(name)))
+	}
+	switch kind {
+	case "image":
+		return "🎨"
+	case "archive":
+		return "📦"
+	case "parquet":
+		return "🧱"
+	case "csv":
+		return "📊"
+	case "log":
+		return "📜"
+	case "video":
+		return "🎬"
+	case "audio":
+		return "🎵"
+	case "pdf":
+		return "📕"
+	case "text":
+		return "📝"
+	default:
+		return "📄"
+	}
+}
+
+// kindByType classifies a Content-Type, or returns "" for generic types
+// like application/octet-stream that say nothing about the content
+func kindByType(contentType string) string {
+	t, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	t = strings.TrimSpace(t)
+	switch {
+	case strings.HasPrefix(t, "image/"):
+		return "image"
+	case strings.HasPrefix(t, "video/"):
+		return "video"
+	case strings.HasPrefix(t, "audio/"):
+		return "audio"
+	case strings.Contains(t, "parquet"):
+		return "parquet"
+	case t == "text/csv" || t == "text/tab-separated-values":
+		return "csv"
+	case t == "application/pdf":
+		return "pdf"
+	case t == "application/zip", t == "application/gzip", t == "application/x-gzip",
+		t == "application/x-tar", t == "application/x-7z-compressed", t == "application/x-bzip2",
+		t == "application/x-xz", t == "application/zstd", t == "application/x-rar-compressed":
+		return "archive"
+	case t == "application/json", t == "application/x-ndjson", t == "application/xml",
+		t == "application/yaml", strings.HasPrefix(t, "text/") && t != "text/plain":
+		return "text"
+	}
+	return ""
+}
+
+// kindByExt classifies a file by its lowercased extension
+func kindByExt(ext string) string {
+	switch ext {
+	case ".png", ".jpg", ".jpeg", ".gif", ".webp", ".svg", ".bmp", ".tif", ".tiff", ".heic":
+		return "image"
+	case ".zip", ".gz", ".tgz", ".tar", ".7z", ".bz2", ".xz", ".zst", ".rar":
+		return "archive"
+	case ".parquet":
+		return "parquet"
+	case ".csv", ".tsv":
+		return "csv"
+	case ".log":
+		return "log"
+	case ".mp4", ".mov", ".mkv", ".webm", ".avi":
+		return "video"
+	case ".mp3", ".wav", ".flac", ".ogg", ".m4a":
+		return "audio"
+	case ".pdf":
+		return "pdf"
+	case ".txt", ".md", ".json", ".jsonl", ".ndjson", ".yaml", ".yml", ".xml", ".html", ".ini", ".toml":
+		return "text"
+	}
+	return ""
+}
+
+// lookupContentTypes returns a command that looks up the Content-Type of
+// the objects on the visible page that aren't known yet, or nil if there
+// are none or the Lister can't look them up
+func (m *Model) lookupContentTypes() tea.Cmd {
+	typer, ok := m.lister.(ContentTyper)
+	if !ok || m.bucket == "" {
+		return nil
+	}
+
+	items := m.list.VisibleItems()
+	start, end := m.list.Paginator.GetSliceBounds(len(items))
+	var keys []string
+	for _, listItem := range items[start:end] {
+		item, ok := listItem.(Item)
+		if !ok || item.object.IsPrefix || strings.HasSuffix(item.object.Key, "/") {
+			continue
+		}
+		key := item.object.Key
+		if _, known := m.contentTypes[key]; known || m.typeLookups[key] {
+			continue
+		}
+		m.typeLookups[key] = true
+		keys = append(keys, key)
+		if len(keys) == maxTypeLookups {
+			break
+		}
+	}
+	if len(keys) == 0 {
+		return nil
+	}
+
+	ctx, bucket := m.context(), m.bucket
+	return func() tea.Msg {
+		msg := ContentTypesMsg{Bucket: bucket, Types: make(map[string]string, len(keys))}
+		var mu sync.Mutex
+		var wg sync.WaitGroup
+		sem := make(chan struct{}, typeLookupWorkers)
+		for _, key := range keys {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func() {
+				defer wg.Done()
+				defer func() { <-sem }()
+				contentType, _ := typer.ContentType(ctx, bucket, key)
+				mu.Lock()
+				msg.Types[key] = contentType
+				mu.Unlock()
+			}()
+		}
+		wg.Wait()
+		return msg
+	}
+}
+
+// handleContentTypes records looked up Content-Types and redraws the list
+func (m *Model) handleContentTypes(msg ContentTypesMsg) {
+	for key, contentType := range msg.Types {
+		delete(m.typeLookups, key)
+		if msg.Bucket == m.bucket {
+			m.contentTypes[key] = contentType
+		}
+	}
+	if msg.Bucket == m.bucket {
+		m.refreshListItems()
+	}
+}
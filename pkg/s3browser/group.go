@@ -0,0 +1,122 @@
+package s3browser
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+// dateGroup is a section of the listing when grouped by date
+type dateGroup int
+
+const (
+	groupFolders dateGroup = iota // prefixes have no date
+	groupToday
+	groupYesterday
+	groupThisWeek
+	groupOlder
+)
+
+var dateGroupNames = [...]string{"Folders", "Today", "Yesterday", "This week", "Older"}
+
+// groupFor returns the section an object modified at t falls in. This week
+// means the seven days up to and including today.
+func groupFor(t, now time.Time) dateGroup {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	switch t = t.In(now.Location()); {
+	case !t.Before(today):
+		return groupToday
+	case !t.Before(today.AddDate(0, 0, -1)):
+		return groupYesterday
+	case !t.Before(today.AddDate(0, 0, -6)):
+		return groupThisWeek
+	}
+	return groupOlder
+}
+
+// headerItem is a section heading in the grouped listing. Selecting it
+// collapses or expands the section.
+type headerItem struct {
+	group     dateGroup
+	count     int
+	collapsed bool
+}
+
+func (h headerItem) Title() string {
+	marker := "▾"
+	if h.collapsed {
+		marker = "▸"
+	}
+	return fmt.Sprintf("%s %s", marker, dateGroupNames[h.group])
+}
+
+func (h headerItem) Description() string {
+	if h.count == 1 {
+		return "1 item"
+	}
+	return fmt.Sprintf("%d items", h.count)
+}
+
+// FilterValue is empty so headings drop out while filtering
+func (h headerItem) FilterValue() string {
+	return ""
+}
+
+// GroupedByDate returns true while the listing is split into sections by
+// last modified date
+func (m Model) GroupedByDate() bool {
+	return m.byDate
+}
+
+// SetGroupByDate splits the listing into today, yesterday, this week and
+// older sections, newest first within each, or restores the key order
+func (m *Model) SetGroupByDate(byDate bool) {
+	m.byDate = byDate
+	m.refreshListItems()
+	m.list.ResetSelected()
+}
+
+// toggleGroup collapses or expands a section
+func (m *Model) toggleGroup(group dateGroup) {
+	if m.collapsed[group] {
+		delete(m.collapsed, group)
+	} else {
+		m.collapsed[group] = true
+	}
+	m.refreshListItems()
+}
+
+// groupedItems lays objects out under a heading per date section
+func (m *Model) groupedItems(objects []stui.Object) []list.Item {
+	now := time.Now()
+	var groups [len(dateGroupNames)][]stui.Object
+	for _, obj := range objects {
+		group := groupFolders
+		if !obj.IsPrefix {
+			group = groupFor(obj.LastModified, now)
+		}
+		groups[group] = append(groups[group], obj)
+	}
+
+	var items []list.Item
+	for group, objs := range groups {
+		if len(objs) == 0 {
+			continue
+		}
+		collapsed := m.collapsed[dateGroup(group)]
+		items = append(items, headerItem{group: dateGroup(group), count: len(objs), collapsed: collapsed})
+		if collapsed {
+			continue
+		}
+		sort.SliceStable(objs, func(i, j int) bool {
+			return objs[i].LastModified.After(objs[j].LastModified)
+		})
+		for _, obj := range objs {
+			items = append(items, m.item(obj))
+		}
+	}
+	return items
+}
@@ -0,0 +1,153 @@
+package s3browser
+
+import (
+	"github.com/charmbracelet/bubbles/key"
+	"github.com/charmbracelet/bubbles/list"
+	"github.com/natevick/stui/internal/keymap"
+)
+
+// KeyMap holds the browser's key bindings. The navigation keys are always
+// live; the action keys only once SetActionsEnabled turns them on. The
+// list's own paging keys give up any key these claim.
+type KeyMap struct {
+	// Navigation
+	Select      key.Binding
+	Open        key.Binding
+	Back        key.Binding
+	Narrow      key.Binding
+	PopNarrow   key.Binding
+	Flat        key.Binding
+	GroupByDate key.Binding
+	Seek        key.Binding
+	Stats       key.Binding
+
+	// Actions
+	Download      key.Binding
+	Sync          key.Binding
+	SyncUp        key.Binding
+	Bookmark      key.Binding
+	NewObject     key.Binding
+	NewFolder     key.Binding
+	Upload        key.Binding
+	Rename        key.Binding
+	Move          key.Binding
+	Copy          key.Binding
+	StorageClass  key.Binding
+	Tag           key.Binding
+	Restore       key.Binding
+	Delete        key.Binding
+	Verify        key.Binding
+	Compare       key.Binding
+	Presign       key.Binding
+	PresignUpload key.Binding
+	Inspect       key.Binding
+	Versions      key.Binding
+	JumpToDate    key.Binding
+	JumpToLatest  key.Binding
+	Inventory     key.Binding
+	Console       key.Binding
+	CopyKeys      key.Binding
+	CopyURIs      key.Binding
+	Manifest      key.Binding
+	Preview       key.Binding
+}
+
+// DefaultKeyMap returns the browser's default key bindings
+func DefaultKeyMap() KeyMap {
+	return KeyMap{
+		Select:      key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
+		Open:        key.NewBinding(key.WithKeys("enter"), key.WithHelp("enter", "open")),
+		Back:        key.NewBinding(key.WithKeys("backspace"), key.WithHelp("backspace", "up a folder")),
+		Narrow:      key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "narrow")),
+		PopNarrow:   key.NewBinding(key.WithKeys("F"), key.WithHelp("F", "undo narrowing")),
+		Flat:        key.NewBinding(key.WithKeys("t"), key.WithHelp("t", "flat listing")),
+		GroupByDate: key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "group by date")),
+		Seek:        key.NewBinding(key.WithKeys("'"), key.WithHelp("'", "type-ahead")),
+		Stats:       key.NewBinding(key.WithKeys("#"), key.WithHelp("#", "summary")),
+
+		Download:      key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "download")),
+		Sync:          key.NewBinding(key.WithKeys("s"), key.WithHelp("s", "sync down")),
+		SyncUp:        key.NewBinding(key.WithKeys("S"), key.WithHelp("S", "sync up")),
+		Bookmark:      key.NewBinding(key.WithKeys("b"), key.WithHelp("b", "bookmark")),
+		NewObject:     key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new object")),
+		NewFolder:     key.NewBinding(key.WithKeys("N"), key.WithHelp("N", "new folder")),
+		Upload:        key.NewBinding(key.WithKeys("u"), key.WithHelp("u", "upload")),
+		Rename:        key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "rename")),
+		Move:          key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "move")),
+		Copy:          key.NewBinding(key.WithKeys("C"), key.WithHelp("C", "copy")),
+		StorageClass:  key.NewBinding(key.WithKeys("T"), key.WithHelp("T", "storage class")),
+		Tag:           key.NewBinding(key.WithKeys("A"), key.WithHelp("A", "add tags")),
+		Restore:       key.NewBinding(key.WithKeys("R"), key.WithHelp("R", "restore")),
+		Delete:        key.NewBinding(key.WithKeys("D", "x"), key.WithHelp("D", "delete")),
+		Verify:        key.NewBinding(key.WithKeys("="), key.WithHelp("=", "verify")),
+		Compare:       key.NewBinding(key.WithKeys("M"), key.WithHelp("M", "compare")),
+		Presign:       key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "share")),
+		PresignUpload: key.NewBinding(key.WithKeys("P"), key.WithHelp("P", "share upload")),
+		Inspect:       key.NewBinding(key.WithKeys("i"), key.WithHelp("i", "inspect")),
+		Versions:      key.NewBinding(key.WithKeys("V"), key.WithHelp("V", "versions")),
+		JumpToDate:    key.NewBinding(key.WithKeys("@"), key.WithHelp("@", "jump to date")),
+		JumpToLatest:  key.NewBinding(key.WithKeys("L"), key.WithHelp("L", "latest partition")),
+		Inventory:     key.NewBinding(key.WithKeys("I"), key.WithHelp("I", "snapshot")),
+		Console:       key.NewBinding(key.WithKeys("o"), key.WithHelp("o", "open in console")),
+		CopyKeys:      key.NewBinding(key.WithKeys("y"), key.WithHelp("y", "copy keys")),
+		CopyURIs:      key.NewBinding(key.WithKeys("Y"), key.WithHelp("Y", "copy s3:// URIs")),
+		Manifest:      key.NewBinding(key.WithKeys("K"), key.WithHelp("K", "download manifest")),
+		Preview:       key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "preview")),
+	}
+}
+
+// NavigationKeys returns the bindings that are always live
+func (k KeyMap) NavigationKeys() []key.Binding {
+	return []key.Binding{k.Select, k.Open, k.Back, k.Narrow, k.PopNarrow, k.Flat, k.GroupByDate, k.Seek, k.Stats}
+}
+
+// ActionKeys returns the bindings SetActionsEnabled turns on
+func (k KeyMap) ActionKeys() []key.Binding {
+	return []key.Binding{
+		k.Download, k.Sync, k.SyncUp, k.Bookmark, k.NewObject, k.NewFolder, k.Upload,
+		k.Rename, k.Move, k.Copy, k.StorageClass, k.Tag, k.Restore, k.Delete, k.Verify,
+		k.Compare, k.Presign, k.PresignUpload, k.Inspect, k.Versions, k.JumpToDate, k.JumpToLatest, k.Inventory, k.Console,
+		k.CopyKeys, k.CopyURIs, k.Manifest, k.Preview,
+	}
+}
+
+// ShortHelp returns the bindings for a short help view
+func (k KeyMap) ShortHelp() []key.Binding {
+	return []key.Binding{k.Select, k.Open, k.Back, k.Narrow}
+}
+
+// FullHelp returns the bindings for an expanded help view
+func (k KeyMap) FullHelp() [][]key.Binding {
+	return [][]key.Binding{k.NavigationKeys(), k.ActionKeys()}
+}
+
+// SetKeyMap replaces the browser's key bindings
+func (m *Model) SetKeyMap(keys KeyMap) {
+	m.keys = keys
+	m.claimListKeys()
+}
+
+// KeyMap returns the browser's key bindings
+func (m Model) KeyMap() KeyMap {
+	return m.keys
+}
+
+// claimListKeys takes the keys the browser handles, like d for download,
+// away from the list's cursor and paging bindings, so no key means two
+// things and the list's help only shows keys that reach it
+func (m *Model) claimListKeys() {
+	var claimed keymap.Registry
+	claimed.Add("browser", m.keys.NavigationKeys()...)
+	if m.actions {
+		claimed.Add("actions", m.keys.ActionKeys()...)
+	}
+	keys := claimed.Claimed()
+
+	defaults := list.DefaultKeyMap()
+	m.list.KeyMap.CursorUp = keymap.Without(defaults.CursorUp, keys...)
+	m.list.KeyMap.CursorDown = keymap.Without(defaults.CursorDown, keys...)
+	m.list.KeyMap.PrevPage = keymap.Without(defaults.PrevPage, keys...)
+	m.list.KeyMap.NextPage = keymap.Without(defaults.NextPage, keys...)
+	m.list.KeyMap.GoToStart = keymap.Without(defaults.GoToStart, keys...)
+	m.list.KeyMap.GoToEnd = keymap.Without(defaults.GoToEnd, keys...)
+}
@@ -0,0 +1,229 @@
+package s3browser
+
+import (
+	"context"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+// Lister lists the objects and common prefixes directly under a prefix.
+// *stui.Client satisfies it.
+type Lister interface {
+	ListObjects(ctx context.Context, bucket, prefix string) ([]stui.Object, error)
+}
+
+// DelimitedLister is a Lister that can group keys on a delimiter other than
+// "/", or not at all. *stui.Client satisfies it.
+type DelimitedLister interface {
+	Lister
+	ListObjectsDelimited(ctx context.Context, bucket, prefix, delimiter string) ([]stui.Object, error)
+}
+
+// PagedLister is a Lister that can list one page at a time, so a flat
+// listing of a large prefix shows up as it streams in. *stui.Client
+// satisfies it.
+type PagedLister interface {
+	Lister
+	ListObjectsPage(ctx context.Context, bucket, prefix, delimiter, token string) ([]stui.Object, string, error)
+}
+
+// ContentTyper is a Lister that can look up an object's Content-Type, so
+// the browser can show an icon for what each visible object holds.
+// *stui.Client satisfies it.
+type ContentTyper interface {
+	Lister
+	ContentType(ctx context.Context, bucket, key string) (string, error)
+}
+
+// LoadedMsg delivers a listing requested by the browser. Hosts must route
+// it to Update even while the browser isn't focused.
+type LoadedMsg struct {
+	Bucket  string
+	Prefix  string
+	Objects []stui.Object
+	Refresh bool // reloaded in place, keeping cursor and selections
+	Err     error
+
+	id        int    // load this is part of; older loads are dropped
+	next      string // continuation token when more pages follow
+	continued bool   // a later page, appended to the ones before
+}
+
+// ActionMsg is sent when the user presses one of stui's own keys, which
+// SetActionsEnabled turns on. Object is the object under the cursor and
+// Objects the marked ones, for the actions that take them.
+type ActionMsg struct {
+	Action   Action
+	Bucket   string
+	Prefix   string
+	Object   stui.Object
+	Objects  []stui.Object
+	RenameTo string // the new name, for ActionRename
+}
+
+// LocationMsg is sent after the user opens a folder or goes back up
+type LocationMsg struct {
+	Bucket string
+	Prefix string
+}
+
+// SelectMsg is sent when the user presses enter on an object: the selected
+// objects if any are marked, otherwise the one under the cursor
+type SelectMsg struct {
+	Bucket  string
+	Objects []stui.Object
+}
+
+// SetClient sets where listings come from. Without one the browser only
+// shows objects handed to SetObjects.
+func (m *Model) SetClient(lister Lister) {
+	m.lister = lister
+}
+
+// SetContext sets the context listings and Content-Type lookups run under,
+// so that cancelling it, e.g. when the host shuts down, stops them. It's
+// context.Background by default.
+func (m *Model) SetContext(ctx context.Context) {
+	m.ctx = ctx
+}
+
+// context returns the context listings and lookups run under
+func (m Model) context() context.Context {
+	if m.ctx == nil {
+		return context.Background()
+	}
+	return m.ctx
+}
+
+// SetDelimiter sets the delimiter keys are grouped into folders on, "/" by
+// default, or "" to list every key under the prefix. It applies from the
+// next load; a Lister that isn't a DelimitedLister always groups on "/".
+func (m *Model) SetDelimiter(delimiter string) {
+	m.delimiter = delimiter
+}
+
+// Delimiter returns the delimiter keys are grouped on
+func (m Model) Delimiter() string {
+	return m.delimiter
+}
+
+// SetLocation moves to prefix in bucket and loads it
+func (m *Model) SetLocation(bucket, prefix string) tea.Cmd {
+	m.SetBucket(bucket)
+	m.SetPrefix(prefix)
+	return m.Reload()
+}
+
+// Reload loads the current location from scratch
+func (m *Model) Reload() tea.Cmd {
+	if m.lister == nil || m.bucket == "" {
+		return nil
+	}
+	m.loading = true
+	return m.load(false)
+}
+
+// Flat returns true while every key under the prefix is listed, ignoring
+// the delimiter
+func (m Model) Flat() bool {
+	return m.flat
+}
+
+// SetFlat switches between the folder view and a flat listing of every key
+// under the prefix, and reloads
+func (m *Model) SetFlat(flat bool) tea.Cmd {
+	m.flat = flat
+	return m.Reload()
+}
+
+// listDelimiter returns the delimiter the current view lists with
+func (m *Model) listDelimiter() string {
+	if m.flat {
+		return ""
+	}
+	return m.delimiter
+}
+
+// Refresh reloads the current location in place, keeping the cursor,
+// selections and pending changes, e.g. after the host writes keys under it
+func (m *Model) Refresh() tea.Cmd {
+	if m.lister == nil || m.bucket == "" {
+		return nil
+	}
+	return m.load(true)
+}
+
+// load starts listing the current location, superseding any earlier load
+func (m *Model) load(refresh bool) tea.Cmd {
+	m.loadID++
+	m.paging = false
+	ctx, lister, bucket, prefix, delimiter := m.context(), m.lister, m.bucket, m.prefix, m.listDelimiter()
+	msg := LoadedMsg{Bucket: bucket, Prefix: prefix, Refresh: refresh, id: m.loadID}
+
+	// Flat listings can be huge, so show them page by page
+	if pl, ok := lister.(PagedLister); ok && m.flat {
+		m.paging = true
+		return loadPage(ctx, pl, msg, delimiter, "")
+	}
+	return func() tea.Msg {
+		if dl, ok := lister.(DelimitedLister); ok && delimiter != "/" {
+			msg.Objects, msg.Err = dl.ListObjectsDelimited(ctx, bucket, prefix, delimiter)
+		} else {
+			msg.Objects, msg.Err = lister.ListObjects(ctx, bucket, prefix)
+		}
+		return msg
+	}
+}
+
+// loadPage returns a command that lists the page of msg's location at token
+func loadPage(ctx context.Context, lister PagedLister, msg LoadedMsg, delimiter, token string) tea.Cmd {
+	return func() tea.Msg {
+		msg.Objects, msg.next, msg.Err = lister.ListObjectsPage(ctx, msg.Bucket, msg.Prefix, delimiter, token)
+		return msg
+	}
+}
+
+// IsCurrent reports whether msg is for the location the browser shows,
+// rather than one it has since moved away from or reloaded
+func (m Model) IsCurrent(msg LoadedMsg) bool {
+	return msg.Bucket == m.bucket && msg.Prefix == m.prefix && msg.id == m.loadID
+}
+
+// handleLoaded applies a listing for the current location, returning a
+// command for its next page if there is one
+func (m *Model) handleLoaded(msg LoadedMsg) tea.Cmd {
+	if !m.IsCurrent(msg) {
+		return nil
+	}
+	switch {
+	case msg.Err != nil && (msg.Refresh || msg.continued):
+		// Keep showing what we have; it's stale or partial, not gone
+	case msg.Err != nil:
+		m.SetError(msg.Err)
+	case msg.continued:
+		m.objects = append(m.objects, msg.Objects...)
+		m.stats.add(msg.Objects)
+		m.refreshListItems()
+	case msg.Refresh:
+		m.RefreshObjects(msg.Objects)
+	default:
+		m.SetObjects(msg.Objects)
+	}
+	lookup := m.lookupContentTypes()
+
+	pl, ok := m.lister.(PagedLister)
+	if msg.Err != nil || msg.next == "" || !ok {
+		m.paging = false
+		return lookup
+	}
+	token := msg.next
+	msg.Objects, msg.next, msg.continued = nil, "", true
+	return tea.Batch(lookup, loadPage(m.context(), pl, msg, m.listDelimiter(), token))
+}
+
+// moved reloads after navigation and tells the host where the browser is
+func (m *Model) moved() tea.Cmd {
+	location := LocationMsg{Bucket: m.bucket, Prefix: m.prefix}
+	return tea.Batch(m.Reload(), func() tea.Msg { return location })
+}
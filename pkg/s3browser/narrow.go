@@ -0,0 +1,111 @@
+package s3browser
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/list"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/natevick/stui/internal/views/inlineedit"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+var (
+	narrowStyle      = lipgloss.NewStyle().Foreground(lipgloss.Color("214"))
+	narrowInputStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("214")).Bold(true)
+)
+
+// matchesNarrowing reports whether obj, listed as name, passes every
+// narrowing term. Terms match fuzzily like the list filter; a term starting
+// with "!" excludes names containing the rest of it. "owner:" terms match
+// the owner's name or canonical ID instead, and also combine with "!".
+func matchesNarrowing(obj stui.Object, name string, terms []string) bool {
+	for _, term := range terms {
+		exclude, negated := strings.CutPrefix(term, "!")
+		if owner, ok := strings.CutPrefix(exclude, ownerTermPrefix); ok {
+			// Folders have no owner, so owner terms leave them be
+			if owner != "" && !obj.IsPrefix && matchesOwner(obj, owner) == negated {
+				return false
+			}
+			continue
+		}
+		if negated {
+			if exclude != "" && strings.Contains(strings.ToLower(name), strings.ToLower(exclude)) {
+				return false
+			}
+			continue
+		}
+		if len(list.DefaultFilter(term, []string{name})) == 0 {
+			return false
+		}
+	}
+	return true
+}
+
+// ownerTermPrefix starts a narrowing term that matches owners
+const ownerTermPrefix = "owner:"
+
+// matchesOwner reports whether obj's owner name or canonical ID contains
+// owner, ignoring case
+func matchesOwner(obj stui.Object, owner string) bool {
+	owner = strings.ToLower(owner)
+	return strings.Contains(strings.ToLower(obj.Owner), owner) ||
+		strings.Contains(strings.ToLower(obj.OwnerID), owner)
+}
+
+// visibleObjects returns the objects that pass the narrowing terms
+func (m *Model) visibleObjects() []stui.Object {
+	if len(m.narrow) == 0 {
+		return m.objects
+	}
+	var objs []stui.Object
+	for _, obj := range m.objects {
+		if matchesNarrowing(obj, m.displayName(obj), m.narrow) {
+			objs = append(objs, obj)
+		}
+	}
+	return objs
+}
+
+// Narrowing returns the stacked narrowing terms, oldest first
+func (m Model) Narrowing() []string {
+	return m.narrow
+}
+
+// PushNarrowing narrows the listing further to names matching term
+func (m *Model) PushNarrowing(term string) {
+	m.narrow = append(m.narrow, term)
+	m.refreshListItems()
+	m.list.ResetSelected()
+}
+
+// PopNarrowing undoes the most recent narrowing term
+func (m *Model) PopNarrowing() {
+	if len(m.narrow) == 0 {
+		return
+	}
+	m.narrow = m.narrow[:len(m.narrow)-1]
+	m.refreshListItems()
+	m.list.ResetSelected()
+}
+
+// updateNarrowInput routes messages to the narrowing input and pushes the
+// term once it's submitted
+func (m Model) updateNarrowInput(msg tea.Msg) (Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.narrowInput, cmd = m.narrowInput.Update(msg)
+
+	result, _, term := m.narrowInput.ConsumeResult()
+	if result == inlineedit.ResultSubmit && strings.TrimSpace(term) != "" {
+		m.PushNarrowing(strings.TrimSpace(term))
+	}
+	return m, cmd
+}
+
+// renderNarrowing shows the narrowing chain for the breadcrumb
+func (m Model) renderNarrowing() string {
+	if len(m.narrow) == 0 {
+		return ""
+	}
+	return narrowStyle.Render("  ⌕ " + strings.Join(m.narrow, " › "))
+}
@@ -0,0 +1,37 @@
+package s3browser
+
+import (
+	"testing"
+
+	"github.com/natevick/stui/pkg/stui"
+)
+
+func TestMatchesNarrowingOwners(t *testing.T) {
+	alice := stui.Object{Key: "logs/a.csv", Owner: "Alice", OwnerID: "79a59df900b949e55d96a1e698fbaced"}
+	unnamed := stui.Object{Key: "logs/b.csv", OwnerID: "e55d96a1e698fbaced79a59df900b949"}
+	folder := stui.Object{Key: "logs/old/", IsPrefix: true}
+
+	tests := []struct {
+		name  string
+		obj   stui.Object
+		terms []string
+		want  bool
+	}{
+		{"owner name", alice, []string{"owner:alice"}, true},
+		{"owner name, other case", alice, []string{"owner:ALI"}, true},
+		{"canonical ID", unnamed, []string{"owner:e55d96"}, true},
+		{"another owner", alice, []string{"owner:bob"}, false},
+		{"excluded owner", alice, []string{"!owner:alice"}, false},
+		{"excluding another owner", alice, []string{"!owner:bob"}, true},
+		{"empty owner term", alice, []string{"owner:"}, true},
+		{"folders have no owner", folder, []string{"owner:bob"}, true},
+		{"folders aren't excluded", folder, []string{"!owner:alice"}, true},
+		{"owner and name", alice, []string{"owner:alice", "a.csv"}, true},
+		{"owner but not name", alice, []string{"owner:alice", "!csv"}, false},
+	}
+	for _, tt := range tests {
+		if got := matchesNarrowing(tt.obj, tt.obj.Key, tt.terms); got != tt.want {
+			t.Errorf("%s: matchesNarrowing(%q, %q) = %v, want %v", tt.name, tt.obj.Key, tt.terms, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,154 @@
+package s3browser
+
+import (
+	"sort"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+// PendingOp is a change shown in the list before S3 has confirmed it
+type PendingOp int
+
+const (
+	PendingNone PendingOp = iota
+	PendingCreate
+	PendingDelete
+	PendingRename
+)
+
+// pendingChange records how to undo an optimistic change
+type pendingChange struct {
+	op       PendingOp
+	original stui.Object // object before a rename
+}
+
+var (
+	deletingStyle = lipgloss.NewStyle().Strikethrough(true).Faint(true)
+	pendingStyle  = lipgloss.NewStyle().Italic(true).Faint(true)
+)
+
+// pendingTitle decorates an item's name while its change is in flight
+func pendingTitle(name string, op PendingOp) string {
+	switch op {
+	case PendingDelete:
+		return deletingStyle.Render(name)
+	case PendingCreate, PendingRename:
+		return name + pendingStyle.Render("  saving…")
+	default:
+		return name
+	}
+}
+
+// MarkCreating shows obj in the list before its upload has finished
+func (m *Model) MarkCreating(obj stui.Object) {
+	for _, existing := range m.objects {
+		if existing.Key == obj.Key {
+			// Overwriting an existing object; the row is already there
+			m.pending[obj.Key] = pendingChange{op: PendingCreate, original: existing}
+			m.refreshListItems()
+			return
+		}
+	}
+
+	m.objects = append(m.objects, obj)
+	sortObjects(m.objects)
+	m.stats.add([]stui.Object{obj})
+	m.pending[obj.Key] = pendingChange{op: PendingCreate}
+	m.refreshListItems()
+}
+
+// MarkDeleting strikes key through until its delete is confirmed
+func (m *Model) MarkDeleting(key string) {
+	for _, obj := range m.objects {
+		if obj.Key == key {
+			m.pending[key] = pendingChange{op: PendingDelete, original: obj}
+			m.refreshListItems()
+			return
+		}
+	}
+}
+
+// MarkRenaming shows oldKey under newKey until the rename is confirmed
+func (m *Model) MarkRenaming(oldKey, newKey string) {
+	for i, obj := range m.objects {
+		if obj.Key == oldKey {
+			m.objects[i].Key = newKey
+			m.pending[newKey] = pendingChange{op: PendingRename, original: obj}
+			if m.selected[oldKey] {
+				delete(m.selected, oldKey)
+				m.selected[newKey] = true
+			}
+			sortObjects(m.objects)
+			m.recountStats()
+			m.refreshListItems()
+			return
+		}
+	}
+}
+
+// ConfirmChange makes the pending change to key permanent
+func (m *Model) ConfirmChange(key string) {
+	change, ok := m.pending[key]
+	if !ok {
+		return
+	}
+	delete(m.pending, key)
+	if change.op == PendingDelete {
+		m.removeObject(key)
+		delete(m.selected, key)
+		m.recountStats()
+	}
+	m.refreshListItems()
+}
+
+// RollbackChange undoes the pending change to key after its request failed
+func (m *Model) RollbackChange(key string) {
+	change, ok := m.pending[key]
+	if !ok {
+		return
+	}
+	delete(m.pending, key)
+
+	switch change.op {
+	case PendingCreate:
+		if change.original.Key == "" {
+			m.removeObject(key)
+			delete(m.selected, key)
+		}
+	case PendingRename:
+		for i, obj := range m.objects {
+			if obj.Key == key {
+				m.objects[i] = change.original
+				break
+			}
+		}
+		if m.selected[key] {
+			delete(m.selected, key)
+			m.selected[change.original.Key] = true
+		}
+		sortObjects(m.objects)
+	}
+	m.recountStats()
+	m.refreshListItems()
+}
+
+// removeObject drops key from the object list
+func (m *Model) removeObject(key string) {
+	for i, obj := range m.objects {
+		if obj.Key == key {
+			m.objects = append(m.objects[:i], m.objects[i+1:]...)
+			return
+		}
+	}
+}
+
+// sortObjects orders folders first, then by key, like a ListObjects page
+func sortObjects(objects []stui.Object) {
+	sort.SliceStable(objects, func(i, j int) bool {
+		if objects[i].IsPrefix != objects[j].IsPrefix {
+			return objects[i].IsPrefix
+		}
+		return objects[i].Key < objects[j].Key
+	})
+}
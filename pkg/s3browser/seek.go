@@ -0,0 +1,80 @@
+package s3browser
+
+import (
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// seekTimeout is how long type-ahead waits for the next character before
+// the typed prefix is forgotten
+const seekTimeout = 1500 * time.Millisecond
+
+var (
+	seekStyle        = lipgloss.NewStyle().Foreground(lipgloss.Color("39")).Bold(true)
+	seekNoMatchStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("196"))
+)
+
+// Seeking returns true while type-ahead is collecting characters
+func (m Model) Seeking() bool {
+	return m.seeking && time.Since(m.seekLast) < seekTimeout
+}
+
+// startSeek begins type-ahead with an empty prefix
+func (m *Model) startSeek() {
+	m.seeking = true
+	m.seekBuf = ""
+	m.seekMiss = false
+	m.seekLast = time.Now()
+}
+
+// updateSeek handles a key while type-ahead is active, reporting whether
+// it was consumed. Keys other than characters, backspace and esc end
+// type-ahead and are handled as usual, so enter opens the item found.
+func (m *Model) updateSeek(msg tea.KeyMsg) bool {
+	switch msg.Type {
+	case tea.KeyRunes, tea.KeySpace:
+		m.seekBuf += string(msg.Runes)
+	case tea.KeyBackspace:
+		if m.seekBuf != "" {
+			runes := []rune(m.seekBuf)
+			m.seekBuf = string(runes[:len(runes)-1])
+		}
+	case tea.KeyEsc:
+		m.seeking = false
+		return true
+	default:
+		m.seeking = false
+		return false
+	}
+	m.seekLast = time.Now()
+	m.seekMiss = !m.seekTo(m.seekBuf)
+	return true
+}
+
+// seekTo moves the cursor to the first item whose name starts with prefix,
+// ignoring case, and reports whether there was one
+func (m *Model) seekTo(prefix string) bool {
+	if prefix == "" {
+		return true
+	}
+	prefix = strings.ToLower(prefix)
+	for i, item := range m.list.Items() {
+		if item, ok := item.(Item); ok && strings.HasPrefix(strings.ToLower(item.name), prefix) {
+			m.list.Select(i)
+			return true
+		}
+	}
+	return false
+}
+
+// renderSeek shows the characters typed so far
+func (m Model) renderSeek() string {
+	line := seekStyle.Render("→ " + m.seekBuf + "▏")
+	if m.seekMiss {
+		line += seekNoMatchStyle.Render("  no match")
+	}
+	return line
+}
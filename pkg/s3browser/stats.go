@@ -0,0 +1,78 @@
+package s3browser
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/dustin/go-humanize"
+	"github.com/natevick/stui/pkg/stui"
+)
+
+var statsStyle = lipgloss.NewStyle().Foreground(lipgloss.Color("245"))
+
+// listingStats summarizes the loaded listing
+type listingStats struct {
+	files   int
+	folders int
+	bytes   int64
+	newest  time.Time
+	biggest stui.Object
+}
+
+// add counts objects into the summary, so flat listings can be summed up a
+// page at a time
+func (s *listingStats) add(objects []stui.Object) {
+	for _, obj := range objects {
+		if obj.IsPrefix {
+			s.folders++
+			continue
+		}
+		s.files++
+		s.bytes += obj.Size
+		if obj.LastModified.After(s.newest) {
+			s.newest = obj.LastModified
+		}
+		if s.files == 1 || obj.Size > s.biggest.Size {
+			s.biggest = obj
+		}
+	}
+}
+
+// recountStats summarizes the listing from scratch after it changes
+func (m *Model) recountStats() {
+	m.stats = listingStats{}
+	m.stats.add(m.objects)
+}
+
+// ShowingStats returns true while the summary line is shown
+func (m Model) ShowingStats() bool {
+	return m.showStats
+}
+
+// SetShowStats shows or hides the summary line under the breadcrumb
+func (m *Model) SetShowStats(show bool) {
+	m.showStats = show
+}
+
+// renderStats shows the summary line
+func (m Model) renderStats() string {
+	s := m.stats
+	parts := []string{
+		fmt.Sprintf("%d files", s.files),
+		fmt.Sprintf("%d folders", s.folders),
+		humanize.Bytes(uint64(s.bytes)),
+	}
+	if s.files > 0 {
+		parts = append(parts,
+			"newest "+s.newest.Local().Format("2006-01-02 15:04"),
+			fmt.Sprintf("biggest %s (%s)", m.displayName(s.biggest), humanize.Bytes(uint64(s.biggest.Size))),
+		)
+	}
+	line := strings.Join(parts, " • ")
+	if m.paging {
+		line += " so far"
+	}
+	return statsStyle.Render("Σ " + line)
+}
@@ -0,0 +1,15 @@
+package stui
+
+import "github.com/natevick/stui/internal/bookmarks"
+
+// Bookmark is a saved S3 location
+type Bookmark = bookmarks.Bookmark
+
+// BookmarkStore reads and writes the bookmarks the TUI shows
+type BookmarkStore = bookmarks.Store
+
+// OpenBookmarks loads the bookmark store from stui's config directory,
+// ~/.config/stui/bookmarks.json
+func OpenBookmarks() (*BookmarkStore, error) {
+	return bookmarks.NewStore()
+}
@@ -0,0 +1,86 @@
+package stui
+
+import (
+	"context"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// Object is an object or common prefix ("folder") in a listing
+type Object = aws.S3Object
+
+// Bucket is an S3 bucket
+type Bucket = aws.Bucket
+
+// ClientOptions selects the AWS credentials and region a Client uses
+type ClientOptions struct {
+	Profile string // shared config profile; empty uses the default chain
+	Region  string // empty uses the profile's region
+	FIPS    bool   // FIPS endpoints for S3 and STS where the region has them
+}
+
+// Client talks to S3 with one set of credentials
+type Client struct {
+	aws *aws.Client
+}
+
+// NewClient loads the AWS configuration for opts and creates a client
+func NewClient(ctx context.Context, opts ClientOptions) (*Client, error) {
+	client, err := aws.NewClient(ctx, opts.Profile, opts.Region, aws.EndpointOptions{FIPS: opts.FIPS})
+	if err != nil {
+		return nil, err
+	}
+	return &Client{aws: client}, nil
+}
+
+// Region returns the region requests are sent to
+func (c *Client) Region() string {
+	return c.aws.Region
+}
+
+// ForBucket returns a client for the region bucket lives in, or c itself if
+// it's already there. Multipart uploads and some downloads fail when sent
+// to the wrong region.
+func (c *Client) ForBucket(ctx context.Context, bucket string) (*Client, error) {
+	region, err := c.aws.GetBucketRegion(ctx, bucket)
+	if err != nil || region == c.aws.Region {
+		return c, err
+	}
+	client, err := c.aws.WithRegion(ctx, region)
+	if err != nil {
+		return nil, err
+	}
+	return &Client{aws: client}, nil
+}
+
+// ListBuckets returns the buckets the credentials can see
+func (c *Client) ListBuckets(ctx context.Context) ([]Bucket, error) {
+	return c.aws.ListBuckets(ctx)
+}
+
+// ListObjects returns the objects and common prefixes directly under prefix
+func (c *Client) ListObjects(ctx context.Context, bucket, prefix string) ([]Object, error) {
+	return c.aws.ListObjects(ctx, bucket, prefix)
+}
+
+// ListObjectsDelimited lists prefix, grouping keys into common prefixes on
+// delimiter instead of "/". An empty delimiter lists every key under prefix.
+func (c *Client) ListObjectsDelimited(ctx context.Context, bucket, prefix, delimiter string) ([]Object, error) {
+	return c.aws.ListObjectsDelimited(ctx, bucket, prefix, delimiter)
+}
+
+// ListObjectsPage lists one page of prefix starting at token, "" for the
+// first page. It returns the token for the next page, or "" after the last.
+func (c *Client) ListObjectsPage(ctx context.Context, bucket, prefix, delimiter, token string) ([]Object, string, error) {
+	return c.aws.ListObjectsPage(ctx, bucket, prefix, delimiter, token)
+}
+
+// ContentType returns an object's Content-Type
+func (c *Client) ContentType(ctx context.Context, bucket, key string) (string, error) {
+	return c.aws.ContentType(ctx, bucket, key)
+}
+
+// ListAllObjects returns every object under prefix, recursively
+func (c *Client) ListAllObjects(ctx context.Context, bucket, prefix string) ([]Object, error) {
+	return c.aws.ListAllObjects(ctx, bucket, prefix)
+}
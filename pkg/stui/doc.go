@@ -0,0 +1,27 @@
+// Package stui exposes stui's S3 transfer engine for use from other Go
+// programs: listing buckets and objects, parallel downloads and uploads,
+// one-way sync, and the bookmark store the TUI uses.
+//
+// Everything under internal/ may change between releases; this package is
+// the supported surface. Its functions and option types are stable across
+// minor versions, but the types re-exported here as aliases, like Object,
+// Progress and Bookmark, are stui's own and gain or change fields and
+// methods along with it.
+//
+// A minimal download:
+//
+//	client, err := stui.NewClient(ctx, stui.ClientOptions{Profile: "prod"})
+//	if err != nil {
+//		return err
+//	}
+//	err = client.DownloadPrefix(ctx, "my-bucket", "logs/2024/", "./logs", stui.TransferOptions{
+//		Workers:    8,
+//		OnProgress: func(p stui.Progress) { fmt.Printf("%.0f%%\n", p.PercentComplete()) },
+//	})
+//
+// A transfer that runs to the end with some of its files failed returns a
+// *FilesFailedError; the errors of the files themselves are in its Progress.
+//
+// Credentials come from the standard AWS configuration, including SSO
+// profiles after `aws sso login`.
+package stui
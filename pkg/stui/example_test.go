@@ -0,0 +1,33 @@
+package stui_test
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	"github.com/natevick/stui/pkg/stui"
+)
+
+func ExampleClient_DownloadPrefix() {
+	ctx := context.Background()
+
+	client, err := stui.NewClient(ctx, stui.ClientOptions{Profile: "prod"})
+	if err != nil {
+		log.Fatal(err)
+	}
+	// Multipart transfers must use the bucket's own region
+	client, err = client.ForBucket(ctx, "my-bucket")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	err = client.DownloadPrefix(ctx, "my-bucket", "logs/2024/", "./logs", stui.TransferOptions{
+		Workers: 8,
+		OnProgress: func(p stui.Progress) {
+			fmt.Printf("\r%d/%d files", p.CompletedFiles, p.TotalFiles)
+		},
+	})
+	if err != nil {
+		log.Fatal(err)
+	}
+}
@@ -0,0 +1,141 @@
+package stui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/natevick/stui/internal/aws"
+	"github.com/natevick/stui/internal/download"
+	"github.com/natevick/stui/internal/security"
+)
+
+// Progress is a snapshot of a running transfer
+type Progress = download.Progress
+
+// FileProgress is the progress of one file within a transfer
+type FileProgress = download.FileProgress
+
+// Status is the state of a transfer or a file within it
+type Status = download.Status
+
+// Transfer and file states
+const (
+	StatusPending    = download.StatusPending
+	StatusInProgress = download.StatusInProgress
+	StatusCompleted  = download.StatusCompleted
+	StatusFailed     = download.StatusFailed
+	StatusCancelled  = download.StatusCancelled
+)
+
+// TransferOptions tunes a transfer. The zero value uses stui's defaults.
+type TransferOptions struct {
+	Workers      int           // files transferred in parallel (default 5)
+	StallTimeout time.Duration // flag files receiving no bytes this long (default 30s)
+	RetryStalled bool          // restart stalled files automatically
+	PartSize     int64         // multipart upload or ranged download part size in bytes (default 10 MiB)
+	Concurrency  int           // parts transferred in parallel per file (default 5)
+
+	// Tags are applied to every object an upload writes. Uploads with tags
+	// S3 would refuse fail before anything is sent.
+	Tags map[string]string
+
+	// OnProgress is called from the transfer's goroutines as it advances
+	OnProgress func(Progress)
+}
+
+// FilesFailedError is returned by a transfer that ran to the end with
+// some of its files failed. The files' own errors are in its progress.
+type FilesFailedError struct {
+	Failed int // files that failed
+	Total  int // files in the transfer
+}
+
+func (e *FilesFailedError) Error() string {
+	return fmt.Sprintf("%d of %d files failed", e.Failed, e.Total)
+}
+
+// manager creates a download manager configured by opts
+func (c *Client) manager(opts TransferOptions) *download.Manager {
+	mgr := download.NewManager(c.aws, opts.Workers)
+	mgr.SetStallPolicy(opts.StallTimeout, opts.RetryStalled)
+	mgr.SetUploadOptions(aws.UploadOptions{PartSize: opts.PartSize, Concurrency: opts.Concurrency})
+	mgr.SetDownloadOptions(aws.DownloadOptions{PartSize: opts.PartSize, Concurrency: opts.Concurrency})
+	if opts.OnProgress != nil {
+		mgr.SetProgressCallback(opts.OnProgress)
+	}
+	return mgr
+}
+
+// run runs a transfer on a manager configured by opts, turning files that
+// failed into a FilesFailedError
+func (c *Client) run(opts TransferOptions, transfer func(*download.Manager) error) error {
+	mgr := c.manager(opts)
+	if err := transfer(mgr); err != nil {
+		return err
+	}
+	if p := mgr.GetProgress(); p.FailedFiles > 0 {
+		return &FilesFailedError{Failed: p.FailedFiles, Total: p.TotalFiles}
+	}
+	return nil
+}
+
+// Download copies one object to localPath
+func (c *Client) Download(ctx context.Context, bucket, key, localPath string, opts TransferOptions) error {
+	return c.run(opts, func(mgr *download.Manager) error {
+		return mgr.DownloadFile(ctx, bucket, key, localPath)
+	})
+}
+
+// DownloadPrefix copies every object under prefix into localDir, keeping
+// the key layout below prefix
+func (c *Client) DownloadPrefix(ctx context.Context, bucket, prefix, localDir string, opts TransferOptions) error {
+	return c.run(opts, func(mgr *download.Manager) error {
+		return mgr.DownloadPrefix(ctx, bucket, prefix, localDir)
+	})
+}
+
+// DownloadObjects copies the given objects into localDir. root is stripped
+// from each key to form its local path; "" mirrors the full key.
+func (c *Client) DownloadObjects(ctx context.Context, bucket string, objects []Object, root, localDir string, opts TransferOptions) error {
+	return c.run(opts, func(mgr *download.Manager) error {
+		return mgr.DownloadMultiple(ctx, bucket, objects, root, localDir)
+	})
+}
+
+// Upload copies a local file to key, as a multipart upload if it's larger
+// than one part
+func (c *Client) Upload(ctx context.Context, bucket, key, localPath string, opts TransferOptions) error {
+	if err := security.ValidTags(opts.Tags); err != nil {
+		return err
+	}
+	return c.run(opts, func(mgr *download.Manager) error {
+		return mgr.UploadFile(ctx, bucket, key, localPath, aws.ObjectAttributes{Tags: opts.Tags})
+	})
+}
+
+// Sync downloads the objects under prefix that are missing or differ from
+// localDir. Nothing is deleted on either side.
+func (c *Client) Sync(ctx context.Context, bucket, prefix, localDir string, opts TransferOptions) error {
+	return c.run(opts, func(mgr *download.Manager) error {
+		return download.NewSyncManager(c.aws).Sync(ctx, bucket, prefix, localDir, mgr)
+	})
+}
+
+// SyncUp uploads the files in localDir that are missing or differ under
+// prefix. Nothing is deleted on either side.
+func (c *Client) SyncUp(ctx context.Context, localDir, bucket, prefix string, opts TransferOptions) error {
+	if err := security.ValidTags(opts.Tags); err != nil {
+		return err
+	}
+	result, err := download.NewSyncManager(c.aws).CompareLocal(ctx, localDir, bucket, prefix)
+	if err != nil {
+		return err
+	}
+	if len(result.ToUpload) == 0 {
+		return nil
+	}
+	return c.run(opts, func(mgr *download.Manager) error {
+		return mgr.UploadMultiple(ctx, bucket, result.ToUpload, aws.ObjectAttributes{Tags: opts.Tags}, result.Symlinks.String())
+	})
+}
@@ -0,0 +1,114 @@
+package stui
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"maps"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	sdkaws "github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/s3"
+
+	"github.com/natevick/stui/internal/aws"
+)
+
+// testClient serves objects from memory as the bucket "bucket". Keys in
+// denied are listed but refuse to download.
+func testClient(t *testing.T, objects map[string]string, denied map[string]bool) *Client {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Query().Get("list-type") == "2" {
+			prefix := r.URL.Query().Get("prefix")
+			var contents strings.Builder
+			keys := slices.Sorted(maps.Keys(objects))
+			for _, key := range keys {
+				if data := objects[key]; strings.HasPrefix(key, prefix) {
+					contents.WriteString("<Contents><Key>" + key + "</Key><Size>" +
+						strconv.Itoa(len(data)) + "</Size><ETag>&quot;x&quot;</ETag></Contents>")
+				}
+			}
+			w.Header().Set("Content-Type", "application/xml")
+			w.Write([]byte(`<?xml version="1.0" encoding="UTF-8"?><ListBucketResult><Name>bucket</Name><Prefix>` +
+				prefix + `</Prefix><IsTruncated>false</IsTruncated>` + contents.String() + `</ListBucketResult>`))
+			return
+		}
+		key := strings.TrimPrefix(r.URL.Path, "/bucket/")
+		data, ok := objects[key]
+		if !ok || denied[key] {
+			w.Header().Set("Content-Type", "application/xml")
+			w.WriteHeader(http.StatusForbidden)
+			if r.Method != http.MethodHead {
+				w.Write([]byte(`<Error><Code>AccessDenied</Code><Message>Access Denied</Message></Error>`))
+			}
+			return
+		}
+		w.Header().Set("ETag", `"x"`)
+		http.ServeContent(w, r, "", time.Time{}, bytes.NewReader([]byte(data)))
+	}))
+	t.Cleanup(server.Close)
+
+	return &Client{aws: &aws.Client{S3: s3.New(s3.Options{
+		Region:       "us-east-1",
+		BaseEndpoint: sdkaws.String(server.URL),
+		UsePathStyle: true,
+		Credentials:  sdkaws.AnonymousCredentials{},
+	}), Region: "us-east-1"}}
+}
+
+func TestDownloadPrefixPartlyFailed(t *testing.T) {
+	client := testClient(t, map[string]string{"logs/a.txt": "alpha", "logs/b.txt": "bravo"}, map[string]bool{"logs/b.txt": true})
+	dir := t.TempDir()
+
+	err := client.DownloadPrefix(context.Background(), "bucket", "logs/", dir, TransferOptions{Workers: 2})
+	var failed *FilesFailedError
+	if !errors.As(err, &failed) {
+		t.Fatalf("DownloadPrefix() error = %v, want a *FilesFailedError", err)
+	}
+	if failed.Failed != 1 || failed.Total != 2 {
+		t.Errorf("%d of %d files failed, want 1 of 2", failed.Failed, failed.Total)
+	}
+	if got, err := os.ReadFile(filepath.Join(dir, "a.txt")); err != nil || string(got) != "alpha" {
+		t.Errorf("a.txt = %q, %v; want the file that didn't fail", got, err)
+	}
+}
+
+func TestDownloadPrefixSucceeded(t *testing.T) {
+	client := testClient(t, map[string]string{"logs/a.txt": "alpha", "logs/b.txt": "bravo"}, nil)
+	if err := client.DownloadPrefix(context.Background(), "bucket", "logs/", t.TempDir(), TransferOptions{}); err != nil {
+		t.Errorf("DownloadPrefix() error = %v", err)
+	}
+}
+
+func TestDownloadFailed(t *testing.T) {
+	client := testClient(t, map[string]string{"logs/a.txt": "alpha"}, map[string]bool{"logs/a.txt": true})
+
+	// A transfer of one file returns that file's own error
+	err := client.Download(context.Background(), "bucket", "logs/a.txt", filepath.Join(t.TempDir(), "a.txt"), TransferOptions{})
+	var failed *FilesFailedError
+	if err == nil || errors.As(err, &failed) {
+		t.Errorf("Download() error = %v, want the download's error", err)
+	}
+}
+
+func TestUploadInvalidTags(t *testing.T) {
+	client := testClient(t, nil, nil)
+	path := filepath.Join(t.TempDir(), "a.txt")
+	if err := os.WriteFile(path, []byte("alpha"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	err := client.Upload(context.Background(), "bucket", "logs/a.txt", path, TransferOptions{
+		Tags: map[string]string{"": "no key"},
+	})
+	if err == nil {
+		t.Error("Upload() accepted a tag with an empty key")
+	}
+}